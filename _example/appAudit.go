@@ -0,0 +1,79 @@
+package main
+
+/*
+app第一次监听前执行启动审计，输出路由注册问题的Warning日志，日志fields包含kind、method、path和route：
+duplicate-middleware    处理链中重复注册的同一个中间件，相同构造函数创建的不同中间件不视为重复
+duplicate-route         相同方法和路径重复注册的路由
+shadowed-route          路由路径被其他路由匹配，例如变量名称不同的同位置变量、通配符被变量通配符遮蔽
+unreachable-route       路由路径无法匹配，返回404或405
+
+审计级别依次读取配置audit和环境变量EUDORE_AUDIT，值为warning时输出日志，
+值为fatal时存在问题Listen返回错误并结束app，用于在CI中检查路由注册；
+未设置时仅在development模式审计，默认production模式不审计。
+*/
+
+import (
+	"fmt"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	// CI中使用fatal级别，存在审计问题时启动失败。
+	ci := newAuditApp("fatal")
+	fmt.Println("ci listen error:", ci.Listen(":8088"))
+	fmt.Println("ci run error:", ci.Run())
+
+	app := newAuditApp("warning")
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/api/v1").Do().CheckStatus(200).CheckBodyString("v1 new")
+	client.NewRequest("GET", "/v1/user:logout").Do().CheckStatus(200).CheckBodyString("login")
+	client.NewRequest("GET", "/files/{name}").Do().CheckStatus(404)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
+
+func newAuditApp(level string) *eudore.App {
+	app := eudore.NewApp()
+	app.Set("audit", level)
+	recoverFunc := middleware.NewRecoverFunc()
+	app.AddMiddleware("global", recoverFunc)
+	app.AddMiddleware("global", recoverFunc)
+	logger := middleware.NewLoggerFunc(app, "route")
+	app.AddMiddleware(logger)
+	app.AddMiddleware(logger)
+	// 不同配置的相同中间件不视为重复。
+	app.AddMiddleware("/api", middleware.NewLoggerFunc(app, "route", "query"))
+
+	app.GetFunc("/api/v1", func(ctx eudore.Context) {
+		ctx.WriteString("v1")
+	})
+	app.GetFunc("/api/v1", func(ctx eudore.Context) {
+		ctx.WriteString("v1 new")
+	})
+	// 同位置变量名称不同，logout路由被login路由遮蔽。
+	app.GetFunc("/v1/user:login", func(ctx eudore.Context) {
+		ctx.WriteString("login")
+	})
+	app.GetFunc("/v1/user:logout", func(ctx eudore.Context) {
+		ctx.WriteString("logout")
+	})
+	app.GetFunc("/user/:id", func(ctx eudore.Context) {
+		ctx.WriteString("user " + ctx.GetParam("id"))
+	})
+	app.GetFunc("/user/*path", func(ctx eudore.Context) {
+		ctx.WriteString("user path " + ctx.GetParam("path"))
+	})
+	app.GetFunc("/user/:id/*", func(ctx eudore.Context) {
+		ctx.WriteString("user " + ctx.GetParam("id") + " " + ctx.GetParam("*"))
+	})
+	// 使用了其他框架的变量语法，路由路径无法匹配。
+	app.GetFunc("/files/{name}", func(ctx eudore.Context) {
+		ctx.WriteString("file " + ctx.GetParam("name"))
+	})
+	return app
+}
@@ -0,0 +1,59 @@
+package main
+
+/*
+app.Cache定义应用共享缓存，默认使用内存实现，可以使用redis.NewCache替换为Redis实现多实例共享。
+
+ctx.Cache()返回app.Cache，Idempotency、OIDC等中间件可以使用同一个缓存作为存储。
+*/
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	// app.Options(redis.NewCache(redis.NewClient("127.0.0.1:6379", "", 0), "eudore:"))
+	app.AddMiddleware(middleware.NewIdempotencyFunc(app.Cache))
+
+	var count int
+	app.GetFunc("/user/:id", func(ctx eudore.Context) error {
+		body, err := eudore.CacheRemember(ctx.Cache(), "user:"+ctx.GetParam("id"), time.Minute, func() ([]byte, error) {
+			count++
+			return []byte(fmt.Sprintf("user %s load %d", ctx.GetParam("id"), count)), nil
+		})
+		if err != nil {
+			return err
+		}
+		_, err = ctx.Write(body)
+		return err
+	})
+	app.DeleteFunc("/user/:id", func(ctx eudore.Context) error {
+		return ctx.Cache().Delete("user:" + ctx.GetParam("id"))
+	})
+	app.PostFunc("/order", func(ctx eudore.Context) {
+		count++
+		ctx.WriteString(fmt.Sprintf("order %d", count))
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/user/1").Do().CheckStatus(200).CheckBodyString("user 1 load 1")
+	client.NewRequest("GET", "/user/1").Do().CheckStatus(200).CheckBodyString("user 1 load 1")
+	client.NewRequest("DELETE", "/user/1").Do().CheckStatus(200)
+	client.NewRequest("GET", "/user/1").Do().CheckStatus(200).CheckBodyString("user 1 load 2")
+	client.NewRequest("POST", "/order").WithHeaderValue(eudore.HeaderIdempotencyKey, "k1").Do().CheckStatus(200).CheckBodyString("order 3")
+	client.NewRequest("POST", "/order").WithHeaderValue(eudore.HeaderIdempotencyKey, "k1").Do().CheckStatus(200).CheckBodyString("order 3")
+
+	ok, _ := app.Cache.Add("lock", []byte("1"), 100*time.Millisecond)
+	fmt.Println(ok)
+	ok, _ = app.Cache.Add("lock", []byte("1"), 100*time.Millisecond)
+	fmt.Println(ok)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
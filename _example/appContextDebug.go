@@ -0,0 +1,69 @@
+package main
+
+/*
+ContextDebug 定义Context池调试模式，使用app.Options设置。
+
+请求结束后Context不再放回池中而是标记为已释放，之后使用Response、Logger、GetContext或ctx.WithField返回的Logout会输出带调用栈的Warning日志，
+用于发现处理函数在请求结束后保留Context或Logout，不要在生产环境使用。
+app.Logger为LoggerStd时记录日志条目取出和放回数量，条目输出后放回，继续使用条目会输出Warning日志。
+*/
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.Options(eudore.NewContextDebug(app))
+	app.GetFunc("/eudore/debug/context", app.ContextDebug.HandleHTTP)
+	app.GetFunc("/leak", func(ctx eudore.Context) {
+		// 错误用法: 在协程中保留Context。
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			ctx.Info("async done")
+			ctx.WriteString("async")
+		}()
+		ctx.WriteString("leak")
+	})
+	app.GetFunc("/fields", func(ctx eudore.Context) {
+		// 错误用法: 在协程中保留ctx.WithField返回的Logout。
+		log := ctx.WithField("path", ctx.Path())
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			log.Info("async done")
+		}()
+		// 错误用法: 日志条目输出后放回，不能再次使用。
+		entry := app.WithField("path", ctx.Path())
+		entry.Info("first")
+		entry.Info("second")
+		ctx.WriteString("fields")
+	})
+	app.GetFunc("/safe", func(ctx eudore.Context) {
+		// 正确用法: 复制需要的数据后再启动协程。
+		log := app.WithField("path", ctx.Path())
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			log.Info("async done")
+		}()
+		ctx.WriteString("safe")
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/leak").Do().CheckStatus(200).CheckBodyString("leak")
+	client.NewRequest("GET", "/fields").Do().CheckStatus(200).CheckBodyString("fields")
+	client.NewRequest("GET", "/safe").Do().CheckStatus(200).CheckBodyString("safe")
+	time.Sleep(100 * time.Millisecond)
+	if misuses := atomic.LoadInt64(&app.ContextDebug.Misuses); misuses != 5 {
+		fmt.Println("Check context debug misuses", misuses)
+	}
+	client.NewRequest("GET", "/eudore/debug/context").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().Out()
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
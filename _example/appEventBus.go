@@ -0,0 +1,63 @@
+package main
+
+/*
+app.On订阅事件，app.Emit发布事件，topic使用'.'分隔，'*'匹配一段，最后一段为'#'时匹配剩余任意段。
+*/
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+type eventUser struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func main() {
+	app := eudore.NewApp()
+	app.On("user.created", func(ctx context.Context, event *eudore.Event) error {
+		var u eventUser
+		err := event.Bind(&u)
+		fmt.Println("sync", event.Topic, u.Name, err)
+		return err
+	})
+	app.On("user.*", func(ctx context.Context, event *eudore.Event) error {
+		fmt.Println("async", event.Topic, event.GetString("name"))
+		return nil
+	}, eudore.EventAsync)
+	app.On("user.#", func(ctx context.Context, event *eudore.Event) error {
+		if event.Topic == "user.deleted.soft" {
+			panic("event panic")
+		}
+		return nil
+	})
+	cancel := app.On("#", func(ctx context.Context, event *eudore.Event) error {
+		return errors.New("all error")
+	})
+	fmt.Println(app.Emit("order.created", nil))
+	cancel()
+	fmt.Println(app.Emit("order.created", nil))
+
+	app.PostFunc("/user", func(ctx eudore.Context) error {
+		var u eventUser
+		ctx.Bind(&u)
+		return app.Emit("user.created", u)
+	})
+	app.DeleteFunc("/user", func(ctx eudore.Context) error {
+		return app.Emit("user.deleted.soft", map[string]interface{}{"name": "eudore"})
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("POST", "/user").WithBodyJSONValue("name", "eudore").Do().CheckStatus(200)
+	client.NewRequest("DELETE", "/user").Do().CheckStatus(500)
+	fmt.Println(app.Emit("user.created", map[string]interface{}{"name": "map", "age": 1}))
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,67 @@
+package main
+
+/*
+app.Features定义功能开关，可以从配置或远程加载，处理函数使用ctx.Feature检查功能是否开启。
+
+Percent在1-99之间时按UID参数或RequestID灰度开启，相同key总是得到相同结果。
+app.Features.HandleHTTP实现管理接口，运行时修改开关。
+*/
+
+import (
+	"fmt"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.Set("features", map[string]interface{}{
+		"new-checkout": map[string]interface{}{"enabled": true, "percent": 30},
+		"dark-mode":    map[string]interface{}{"enabled": false},
+	})
+	err := app.Features.Load(app.Get("features"))
+	if err != nil {
+		panic(err)
+	}
+	// 远程加载
+	// app.Every(time.Minute, func(context.Context) error {
+	// 	resp, err := http.Get("http://127.0.0.1:8500/v1/kv/features?raw")
+	// 	...
+	// 	return app.Features.Load(body)
+	// })
+
+	app.AnyFunc("/admin/features/:name", app.Features.HandleHTTP)
+	app.GetFunc("/checkout", func(ctx eudore.Context) {
+		ctx.SetParam(eudore.ParamUID, ctx.GetQuery("uid"))
+		if ctx.Feature("new-checkout") {
+			ctx.WriteString("new")
+		} else {
+			ctx.WriteString("old")
+		}
+	})
+	app.GetFunc("/theme", func(ctx eudore.Context) {
+		ctx.WriteString(fmt.Sprint(ctx.Feature("dark-mode")))
+	})
+
+	client := httptest.NewClient(app)
+	var count int
+	for i := 0; i < 100; i++ {
+		resp := client.NewRequest("GET", fmt.Sprintf("/checkout?uid=%d", i)).Do()
+		if resp.Body.String() == "new" {
+			count++
+		}
+	}
+	fmt.Println("new checkout users:", count)
+	client.NewRequest("GET", "/checkout?uid=1").Do().Out()
+	client.NewRequest("GET", "/checkout?uid=1").Do().Out()
+
+	client.NewRequest("GET", "/theme").Do().CheckBodyString("false")
+	client.NewRequest("PUT", "/admin/features/dark-mode").WithBodyJSONValue("enabled", true).Do().CheckStatus(200)
+	client.NewRequest("GET", "/theme").Do().CheckBodyString("true")
+	client.NewRequest("GET", "/admin/features/list").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().Out()
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
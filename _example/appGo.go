@@ -0,0 +1,55 @@
+package main
+
+/*
+app.Go 在新协程执行后台函数，捕捉panic并输出带栈信息的错误日志，避免panic使进程崩溃，Run方法会等待协程结束。
+
+options:
+string           =>    协程名称，默认为函数名称
+int              =>    panic或返回错误后的最大重启次数，-1不限制
+time.Duration    =>    重启间隔，每次加倍最大1分钟
+*/
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	app := eudore.NewApp()
+	var runs int32
+	app.Go(func(ctx context.Context) error {
+		if atomic.AddInt32(&runs, 1) < 3 {
+			panic("worker panic")
+		}
+		<-ctx.Done()
+		return nil
+	}, "worker", 5, 50*time.Millisecond)
+	app.Go(func(context.Context) error {
+		return errors.New("sync failed")
+	}, "sync")
+
+	app.GetFunc("/async", func(ctx eudore.Context) {
+		// 处理函数启动的后台任务不使用请求context。
+		app.Go(func(context.Context) error {
+			var m map[string]int
+			m["panic"]++
+			return nil
+		}, "async")
+		ctx.WriteString("ok")
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/async").Do().CheckStatus(200).CheckBodyString("ok")
+	time.Sleep(200 * time.Millisecond)
+	fmt.Println("worker runs:", atomic.LoadInt32(&runs))
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,47 @@
+package main
+
+/*
+NewLoggerStd等构造函数初始化失败时会panic，NewLoggerStd2返回错误。
+
+eudore.NewAppInitOption 将返回错误的构造函数结果转换成app选项，
+初始化错误不会中断加载，app.Options收集全部组件初始化错误，
+在启动前合并报告并结束app，Listen返回错误，便于在流水线中一次看到全部配置问题。
+*/
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/eudore/eudore"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.Options(
+		eudore.NewAppInitOption(eudore.NewLoggerStd2(&eudore.LoggerStdConfig{
+			Std:  true,
+			Path: "/dev/null/app.log",
+		})),
+		eudore.NewAppInitOption(newInitDatabase("")),
+	)
+
+	app.GetFunc("/", func(ctx eudore.Context) {
+		ctx.WriteString("hello")
+	})
+
+	err := app.Listen(":8088")
+	fmt.Println("listen error:", err)
+	// app.CancelFunc()
+	fmt.Println("run error:", app.Run())
+}
+
+type initDatabase struct {
+	dsn string
+}
+
+func newInitDatabase(dsn string) (*initDatabase, error) {
+	if dsn == "" {
+		return nil, errors.New("database dsn is empty")
+	}
+	return &initDatabase{dsn}, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+/*
+app.Mode返回app运行模式，依次读取配置mode和环境变量EUDORE_MODE，值为development、test或production，未设置时为production。
+
+app.Dev仅在开发模式执行函数，用于注册dump、pprof等调试路由和中间件，生产环境不会注册；
+middleware.WhenMode在每次请求时判断模式，可以和NewWhenFunc组合按模式执行中间件。
+*/
+
+import (
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	// 未设置mode时为production，不注册调试路由。
+	app := newModeApp("")
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/hello").Do().CheckStatus(200).CheckHeader("X-Eudore-Mode", "")
+	client.NewRequest("GET", "/debug/config").Do().CheckStatus(404)
+	app.CancelFunc()
+	app.Run()
+
+	app = newModeApp(eudore.ModeTest)
+	client = httptest.NewClient(app)
+	client.NewRequest("GET", "/hello").Do().CheckStatus(200).CheckHeader("X-Eudore-Mode", eudore.ModeTest)
+	client.NewRequest("GET", "/debug/config").Do().CheckStatus(404)
+	app.CancelFunc()
+	app.Run()
+
+	app = newModeApp("dev")
+	client = httptest.NewClient(app)
+	client.NewRequest("GET", "/hello").Do().CheckStatus(200).CheckHeader("X-Eudore-Mode", eudore.ModeDevelopment)
+	client.NewRequest("GET", "/debug/config").Do().CheckStatus(200)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
+
+func newModeApp(mode string) *eudore.App {
+	app := eudore.NewApp()
+	app.Set("mode", mode)
+	app.Info("app mode:", app.Mode())
+	app.AddMiddleware(middleware.NewLoggerFunc(app, "route"))
+	app.AddMiddleware(middleware.NewWhenFunc(middleware.WhenMode(app, eudore.ModeDevelopment, eudore.ModeTest), func(ctx eudore.Context) {
+		ctx.SetHeader("X-Eudore-Mode", app.Mode())
+	}))
+	app.Dev(func() {
+		app.GetFunc("/debug/config", func(ctx eudore.Context) interface{} {
+			return app.Config
+		})
+	})
+	app.GetFunc("/hello", func(ctx eudore.Context) {
+		ctx.WriteString("hello eudore")
+	})
+	return app
+}
@@ -0,0 +1,56 @@
+package main
+
+/*
+app.Mount 将一个完整的子App挂载到路径前缀，请求路径去除前缀后交给子App处理，
+子App使用独立的全局中间件、路由、Binder、Renderer和Logger，请求日志输出mount字段，子App的panic不会影响父App。
+
+组路由挂载使用group.AnyFunc("/admin/*", sub.ServeMount)，子App可以使用ctx.GetContext().Value(eudore.MountContextKey)获取挂载前缀。
+*/
+
+import (
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware("global", middleware.NewLoggerFunc(app))
+	app.GetFunc("/", func(ctx eudore.Context) {
+		ctx.WriteString("parent")
+	})
+
+	user := eudore.NewApp()
+	user.AddMiddleware("global", middleware.NewLoggerFunc(user), middleware.NewRequestIDFunc(nil))
+	user.GetFunc("/", func(ctx eudore.Context) {
+		ctx.WriteString("user index")
+	})
+	user.GetFunc("/info/:id", func(ctx eudore.Context) {
+		ctx.Info("mount prefix:", ctx.GetContext().Value(eudore.MountContextKey))
+		ctx.WriteString("user " + ctx.GetParam("id") + " path " + ctx.Path())
+	})
+	user.GetFunc("/panic", func(ctx eudore.Context) {
+		panic("user module panic")
+	})
+	app.Mount("/user", user)
+
+	admin := eudore.NewApp()
+	admin.GetFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteString("admin " + ctx.Path())
+	})
+	app.Group("/v1").AnyFunc("/admin/*", admin.ServeMount)
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/").Do().CheckStatus(200).CheckBodyString("parent")
+	client.NewRequest("GET", "/user").Do().CheckStatus(200).CheckBodyString("user index")
+	client.NewRequest("GET", "/user/").Do().CheckStatus(200).CheckBodyString("user index")
+	client.NewRequest("GET", "/user/info/3").Do().CheckStatus(200).CheckBodyString("user 3 path /info/3")
+	client.NewRequest("GET", "/user/panic").Do().CheckStatus(500)
+	client.NewRequest("GET", "/user/none").Do().CheckStatus(404)
+	client.NewRequest("GET", "/v1/admin/users").Do().CheckStatus(200).CheckBodyString("admin /users")
+	client.NewRequest("GET", "/").Do().CheckStatus(200).CheckBodyString("parent")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
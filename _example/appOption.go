@@ -0,0 +1,55 @@
+package main
+
+/*
+eudore.NewApp 支持AppOption函数选项，选项按组件、配置文件、中间件、监听的阶段顺序执行，与传入顺序无关。
+
+WithLogger        =>    设置Logger
+WithConfig        =>    设置Config
+WithConfigFile    =>    使用ConfigParseJSON加载第一个存在的json配置文件
+WithMiddleware    =>    使用app.AddMiddleware添加中间件
+WithListen        =>    在app.Run时监听http端口
+
+重复的WithLogger、WithConfig、WithConfigFile选项和重复的监听地址作为组件初始化错误收集，在启动前合并报告。
+*/
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	file, _ := os.Create("appOption.json")
+	file.WriteString(`{"name":"eudore"}`)
+	file.Close()
+	defer os.Remove(file.Name())
+
+	app := eudore.NewApp(
+		eudore.WithListen(":8088"),
+		eudore.WithMiddleware("global", middleware.NewRequestIDFunc(nil)),
+		eudore.WithMiddleware(middleware.NewRecoverFunc()),
+		eudore.WithConfigFile("app.json", "appOption.json"),
+		eudore.WithConfig(eudore.NewConfigMap(nil)),
+		eudore.WithLogger(eudore.NewLoggerStd(nil)),
+	)
+	app.GetFunc("/name", func(ctx eudore.Context) interface{} {
+		return app.Get("name")
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/name").Do().CheckStatus(200).CheckBodyContainString("eudore")
+
+	// 冲突的选项在启动前合并报告。
+	app2 := eudore.NewApp(
+		eudore.WithListen(":8089"),
+		eudore.WithListen(":8089"),
+		eudore.WithConfigFile(),
+	)
+	fmt.Println("app2 listen error:", app2.Listen(":8089"))
+
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,48 @@
+package main
+
+/*
+app.AddPreflight 添加启动前检查函数，在第一个监听开始接收请求前按添加顺序执行，
+检查失败时合并全部错误结束app，Listen返回错误，不会在异常状态下提供服务。
+
+options:
+string           =>    检查名称，默认为函数名称
+time.Duration    =>    检查超时时间，默认30秒
+*/
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddPreflight(func(context.Context) error {
+		app.Cache.Set("config", []byte("warm"), 0)
+		return nil
+	}, "warmup-cache")
+	app.AddPreflight(func(ctx context.Context) error {
+		// 模拟数据库连接超时。
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return nil
+		}
+	}, "ping-db", 100*time.Millisecond)
+	app.AddPreflight(func(context.Context) error {
+		return errors.New("migration version 3 failed")
+	}, "migrate")
+
+	app.GetFunc("/", func(ctx eudore.Context) {
+		ctx.WriteString("hello")
+	})
+
+	err := app.Listen(":8088")
+	fmt.Println("listen error:", err)
+	// app.CancelFunc()
+	fmt.Println("run error:", app.Run())
+}
@@ -0,0 +1,80 @@
+package main
+
+/*
+eudore.NewProxyBalancer 创建反向代理负载均衡，用于代理有状态的上游服务。
+
+存在粘性会话Cookie时使用Cookie记录的上游，否则Hash返回非空key时使用一致性哈希选择上游，否则轮询。
+Drain摘除上游后不再分配新请求，已有粘性会话继续访问，Upstreams返回的Active为0后可以下线上游。
+
+options:
+func(eudore.Context) string    =>    一致性哈希key函数，NewProxyHashCookie、NewProxyHashHeader、NewProxyHashIP
+string                         =>    粘性会话Cookie名称
+*/
+
+import (
+	"fmt"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	var backends []*eudore.App
+	for _, addr := range []string{":8089", ":8090"} {
+		addr := addr
+		backend := eudore.NewApp()
+		backend.AnyFunc("/*", func(ctx eudore.Context) {
+			ctx.WriteString("backend " + addr)
+		})
+		backend.Listen(addr)
+		backends = append(backends, backend)
+	}
+
+	hash, _ := eudore.NewProxyBalancer([]string{"http://localhost:8089", "http://localhost:8090"},
+		eudore.NewProxyHashHeader("X-User"))
+	sticky, _ := eudore.NewProxyBalancer([]string{"http://localhost:8089", "http://localhost:8090"}, "_upstream")
+
+	app := eudore.NewApp()
+	app.AnyFunc("/hash/*", hash.HandleHTTP)
+	app.AnyFunc("/sticky/*", sticky.HandleHTTP)
+	app.AnyFunc("/rr/*", "proxy:http://localhost:8089,http://localhost:8090")
+
+	client := httptest.NewClient(app)
+	// 相同key始终转发到相同上游。
+	body := client.NewRequest("GET", "/hash/1").WithHeaderValue("X-User", "eudore").Do().CheckStatus(200).Body.String()
+	for i := 0; i < 5; i++ {
+		client.NewRequest("GET", "/hash/1").WithHeaderValue("X-User", "eudore").Do().CheckStatus(200).CheckBodyString(body)
+	}
+	// 轮询
+	client.NewRequest("GET", "/rr/1").Do().CheckStatus(200).CheckBodyString("backend :8090")
+	client.NewRequest("GET", "/rr/1").Do().CheckStatus(200).CheckBodyString("backend :8089")
+
+	// 粘性会话，摘除后已有会话继续访问。
+	body = client.NewRequest("GET", "/sticky/1").Do().CheckStatus(200).Body.String()
+	target := "http://localhost:8089"
+	if body == "backend :8090" {
+		target = "http://localhost:8090"
+	}
+	sticky.Drain(target, true)
+	client.NewRequest("GET", "/sticky/1").Do().CheckStatus(200).CheckBodyString(body)
+	client2 := httptest.NewClient(app)
+	for i := 0; i < 3; i++ {
+		resp := client2.NewRequest("GET", "/sticky/1").Do().CheckStatus(200)
+		if resp.Body.String() == body {
+			fmt.Println("Check drain upstream receive new session")
+		}
+	}
+	fmt.Printf("%+v\n", sticky.Upstreams())
+	sticky.Drain("http://localhost:8089", true)
+	sticky.Drain("http://localhost:8090", true)
+	httptest.NewClient(app).NewRequest("GET", "/sticky/1").Do().CheckStatus(503)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+	// 关闭上游app释放监听端口。
+	for _, backend := range backends {
+		backend.CancelFunc()
+		backend.Run()
+	}
+}
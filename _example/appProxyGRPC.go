@@ -0,0 +1,65 @@
+//go:build go1.24
+// +build go1.24
+
+package main
+
+/*
+eudore.NewProxyHandler 的target协议为h2c时使用明文http2连接上游，为grpc、grpcs时透传gRPC请求，
+响应立即刷新并转发grpc-status等trailer，上游不可用时返回502和gRPC状态码14(Unavailable)。
+
+负载均衡、网关和proxy处理函数名称同样支持这些协议，例如proxy:grpc://localhost:8089。
+客户端使用gRPC访问时需要使用ListenTLS开启h2。
+*/
+
+import (
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	// 仅支持明文http2的gRPC上游。
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	backend := &http.Server{
+		Protocols: protocols,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+			w.Header().Set(eudore.HeaderContentType, "application/grpc")
+			body, _ := io.ReadAll(r.Body)
+			w.Write(body)
+			w.Header().Set("Grpc-Status", "0")
+			w.Header().Set("Grpc-Message", r.Proto)
+		}),
+	}
+	// 使用随机端口监听上游，结束时关闭上游和监听。
+	ln, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		panic(err)
+	}
+	go backend.Serve(ln)
+	defer backend.Close()
+	upstream := ln.Addr().String()
+
+	app := eudore.NewApp()
+	app.AnyFunc("/helloworld.Greeter/*", "proxy:grpc://"+upstream)
+	app.AnyFunc("/h2c/*", "proxy:h2c://"+upstream)
+	app.AnyFunc("/unavailable/*", "proxy:grpc://localhost:8091")
+
+	client := httptest.NewClient(app)
+	client.NewRequest("POST", "/helloworld.Greeter/SayHello").
+		WithHeaderValue(eudore.HeaderContentType, "application/grpc").
+		WithHeaderValue(eudore.HeaderTE, "trailers").
+		WithBodyString("\x00\x00\x00\x00\x07\n\x05world").Do().
+		CheckStatus(200).CheckBodyString("\x00\x00\x00\x00\x07\n\x05world").
+		CheckHeader("Grpc-Status", "0", "Grpc-Message", "HTTP/2.0")
+	client.NewRequest("GET", "/h2c/1").Do().CheckStatus(200).CheckHeader("Grpc-Message", "HTTP/2.0")
+	client.NewRequest("POST", "/unavailable/SayHello").Do().CheckStatus(502).CheckHeader("Grpc-Status", "14")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
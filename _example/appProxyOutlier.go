@@ -0,0 +1,67 @@
+package main
+
+/*
+eudore.ProxyBalancer 被动健康检查统计上游连续的连接失败、超时和5xx响应，客户端取消的请求不计入，
+达到Consecutive次后驱逐上游，驱逐时间从EjectionTime开始每次翻倍，最大MaxEjectionTime，
+驱逐结束后在SlowStart时间内逐步增加流量，Upstreams返回驱逐状态和累计驱逐次数。
+
+不会驱逐最后一个可用上游。
+*/
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	backend := eudore.NewApp()
+	backend.AnyFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteString("backend ok")
+	})
+	backend.Listen(":8089")
+	backend2 := eudore.NewApp()
+	backend2.AnyFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteHeader(eudore.StatusInternalServerError)
+	})
+	backend2.Listen(":8090")
+
+	balancer, _ := eudore.NewProxyBalancer([]string{"http://localhost:8089", "http://localhost:8090"})
+	balancer.Consecutive = 3
+	balancer.EjectionTime = 200 * time.Millisecond
+	balancer.SlowStart = 0
+
+	app := eudore.NewApp()
+	app.AnyFunc("/*", balancer.HandleHTTP)
+	app.GetFunc("/upstreams", func(eudore.Context) interface{} {
+		return balancer.Upstreams()
+	})
+
+	client := httptest.NewClient(app)
+	for i := 0; i < 6; i++ {
+		client.NewRequest("GET", "/1").Do()
+	}
+	// 8090被驱逐，请求全部转发到8089。
+	for i := 0; i < 4; i++ {
+		client.NewRequest("GET", "/1").Do().CheckStatus(200).CheckBodyString("backend ok")
+	}
+	fmt.Printf("%+v\n", balancer.Upstreams())
+
+	// 驱逐结束后再次失败，驱逐时间翻倍。
+	time.Sleep(250 * time.Millisecond)
+	for i := 0; i < 6; i++ {
+		client.NewRequest("GET", "/1").Do()
+	}
+	for _, upstream := range balancer.Upstreams() {
+		if upstream.Target == "http://localhost:8090" && (!upstream.Ejected || upstream.Ejections != 2) {
+			fmt.Println("Check upstream not ejected twice")
+		}
+	}
+	client.NewRequest("GET", "/upstreams").Do().CheckStatus(200).Out()
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
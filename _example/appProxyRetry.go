@@ -0,0 +1,94 @@
+package main
+
+/*
+eudore.ProxyRetry 定义请求重试和对冲策略，实现http.RoundTripper，
+可以作为http.Client的Transport，或者作为NewProxyHandler、NewProxyBalancer的选项。
+
+只重试幂等方法或存在Idempotency-Key Header的请求，连接错误或响应状态码为502、503、504时重试，
+每次重试和对冲消耗一个预算令牌，每个请求增加Budget个令牌，令牌不足时不重试。
+HedgeDelay大于0时，请求超过HedgeDelay未响应时向其他上游发送对冲请求，使用先返回的成功响应。
+ProxyBalancer使用时重试和对冲请求使用其他上游的地址和Transport发送并记录上游请求和失败，Metrics返回重试和对冲指标。
+*/
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	backend := eudore.NewApp()
+	backend.AnyFunc("/*", func(ctx eudore.Context) {
+		switch path.Base(ctx.Path()) {
+		case "flaky":
+			ctx.WriteHeader(eudore.StatusServiceUnavailable)
+		case "slow":
+			time.Sleep(300 * time.Millisecond)
+			ctx.WriteString("slow")
+		}
+	})
+	backend.Listen(":8089")
+	backend2 := eudore.NewApp()
+	backend2.AnyFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteString("backend2")
+	})
+	backend2.Listen(":8090")
+
+	retry := &eudore.ProxyRetry{HedgeDelay: 50 * time.Millisecond}
+	balancer, _ := eudore.NewProxyBalancer([]string{"http://localhost:8089", "http://localhost:8090"}, retry)
+	single := &eudore.ProxyRetry{Backoff: time.Millisecond}
+	h, _ := eudore.NewProxyHandler("http://localhost:8089", single)
+
+	app := eudore.NewApp()
+	app.AnyFunc("/balancer/*", balancer.HandleHTTP)
+	app.AnyFunc("/single/*", h)
+	app.GetFunc("/metrics", func(eudore.Context) interface{} {
+		return map[string]interface{}{
+			"balancer": retry.Metrics(),
+			"single":   single.Metrics(),
+		}
+	})
+
+	client := httptest.NewClient(app)
+	// 失败时重试其他上游，慢请求对冲到其他上游。
+	for i := 0; i < 4; i++ {
+		client.NewRequest("GET", "/balancer/flaky").Do().CheckStatus(200)
+	}
+	for i := 0; i < 2; i++ {
+		client.NewRequest("GET", "/balancer/slow").Do().CheckStatus(200).CheckBodyString("backend2")
+	}
+	metrics := retry.Metrics()
+	if metrics.Retries+metrics.Hedges == 0 || metrics.HedgeWins == 0 {
+		fmt.Println("Check balancer not retry or hedge", metrics)
+	}
+
+	// 单个上游重试3次，POST请求不重试。
+	client.NewRequest("GET", "/single/flaky").Do().CheckStatus(503)
+	client.NewRequest("POST", "/single/flaky").Do().CheckStatus(503)
+	client.NewRequest("POST", "/single/flaky").WithHeaderValue("Idempotency-Key", "1").Do().CheckStatus(503)
+	if single.Metrics().Retries != 4 {
+		fmt.Println("Check single retries", single.Metrics())
+	}
+	client.NewRequest("GET", "/metrics").Do().CheckStatus(200).Out()
+
+	// http.Client使用
+	httpclient := &http.Client{Transport: &eudore.ProxyRetry{
+		Next: func(*http.Request, int) string {
+			return "localhost:8090"
+		},
+	}}
+	resp, err := httpclient.Get("http://localhost:8089/flaky")
+	if err != nil || resp.StatusCode != 200 {
+		fmt.Println("Check http client retry", err)
+	} else {
+		resp.Body.Close()
+	}
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
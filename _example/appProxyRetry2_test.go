@@ -0,0 +1,195 @@
+package eudore_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	nethttptest "net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func TestProxyRetryBalancerUpstream(t *testing.T) {
+	flaky := nethttptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(eudore.StatusServiceUnavailable)
+	}))
+	defer flaky.Close()
+	backend := nethttptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer backend.Close()
+
+	retry := &eudore.ProxyRetry{Backoff: time.Millisecond}
+	balancer, err := eudore.NewProxyBalancer([]string{flaky.URL + "/v1", backend.URL + "/v2"}, retry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retry.Next != nil {
+		t.Error("NewProxyBalancer modify ProxyRetry.Next")
+	}
+	balancer.Consecutive = 2
+	app := eudore.NewApp()
+	app.AnyFunc("/*", balancer.HandleHTTP)
+
+	client := httptest.NewClient(app)
+	for i := 0; i < 6; i++ {
+		resp := client.NewRequest("GET", "/api").Do()
+		if resp.Code != 200 || resp.Body.String() != "/v2/api" {
+			t.Errorf("retry upstream response %d %s", resp.Code, resp.Body.String())
+		}
+	}
+
+	metrics := retry.Metrics()
+	if metrics.Requests != 6 || metrics.Retries != 2 {
+		t.Errorf("retry metrics %#v", metrics)
+	}
+	var requests uint64
+	for _, upstream := range balancer.Upstreams() {
+		requests += upstream.Requests
+		if upstream.Active != 0 {
+			t.Errorf("upstream %s active %d", upstream.Target, upstream.Active)
+		}
+		if strings.HasPrefix(upstream.Target, flaky.URL) && upstream.Ejections != 1 {
+			t.Errorf("flaky upstream not ejected: %#v", upstream)
+		}
+	}
+	if requests != metrics.Requests+metrics.Retries {
+		t.Errorf("upstream requests %d not include retries", requests)
+	}
+}
+
+func TestProxyRetryBudget(t *testing.T) {
+	flaky := nethttptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(eudore.StatusServiceUnavailable)
+	}))
+	defer flaky.Close()
+
+	retry := &eudore.ProxyRetry{Attempts: 2, Backoff: time.Millisecond}
+	client := &http.Client{Transport: retry}
+	for i := 0; i < 20; i++ {
+		resp, err := client.Get(flaky.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	// 初始10个令牌，每个请求增加0.2个令牌，每次重试消耗1个令牌。
+	metrics := retry.Metrics()
+	if metrics.Requests != 20 || metrics.Retries != 13 || metrics.Exhausted != 7 {
+		t.Errorf("retry budget metrics %#v", metrics)
+	}
+
+	resp, err := client.Post(flaky.URL, eudore.MimeTextPlain, strings.NewReader("post"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if retry.Metrics().Retries != 13 {
+		t.Error("retry not idempotent request")
+	}
+}
+
+func TestProxyRetryHedgeCancel(t *testing.T) {
+	canceled := make(chan struct{}, 1)
+	slow := nethttptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			canceled <- struct{}{}
+		case <-time.After(2 * time.Second):
+			w.Write([]byte("slow"))
+		}
+	}))
+	defer slow.Close()
+	fast := nethttptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("fast"))
+	}))
+	defer fast.Close()
+
+	retry := &eudore.ProxyRetry{
+		HedgeDelay: 20 * time.Millisecond,
+		Next: func(*http.Request, int) string {
+			return strings.TrimPrefix(fast.URL, "http://")
+		},
+	}
+	client := &http.Client{Transport: retry}
+	resp, err := client.Get(slow.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "fast" {
+		t.Errorf("hedge response %s", body)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("hedge not cancel slow request")
+	}
+
+	metrics := retry.Metrics()
+	if metrics.Requests != 1 || metrics.Hedges != 1 || metrics.HedgeWins != 1 {
+		t.Errorf("hedge metrics %#v", metrics)
+	}
+}
+
+func TestProxyBalancerClientCancel(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			w.WriteHeader(eudore.StatusInternalServerError)
+			return
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	})
+	upstream1 := nethttptest.NewServer(handler)
+	defer upstream1.Close()
+	upstream2 := nethttptest.NewServer(handler)
+	defer upstream2.Close()
+
+	balancer, err := eudore.NewProxyBalancer([]string{upstream1.URL, upstream2.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	balancer.Consecutive = 1
+	app := eudore.NewApp()
+	app.AnyFunc("/*", balancer.HandleHTTP)
+	server := nethttptest.NewServer(app)
+	defer server.Close()
+
+	// 客户端取消的请求不计为上游失败。
+	for i := 0; i < 4; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/slow", nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		cancel()
+	}
+	time.Sleep(50 * time.Millisecond)
+	for _, upstream := range balancer.Upstreams() {
+		if upstream.Ejections != 0 || upstream.Failures != 0 {
+			t.Errorf("client cancel count upstream failure: %#v", upstream)
+		}
+	}
+
+	resp, err := http.Get(server.URL + "/error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	var ejections uint64
+	for _, upstream := range balancer.Upstreams() {
+		ejections += upstream.Ejections
+	}
+	if ejections != 1 {
+		t.Errorf("upstream 5xx not eject upstream: %d", ejections)
+	}
+}
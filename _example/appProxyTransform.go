@@ -0,0 +1,93 @@
+package main
+
+/*
+eudore.ProxyTransform 定义反向代理请求或响应body转换，NewProxyHandler和NewProxyBalancer使用*ProxyTransform选项设置，
+Stream流式转换body，Buffer缓冲完整body后转换，body超过MaxSize时Passthrough为true原样转发，否则请求返回413、响应返回502。
+
+NewProxyTransform使用"name:arg"创建转换，名称前缀为request.时转换请求body，RegisterProxyTransform注册自定义转换:
+json-set:{"source":"gateway"}           =>    json对象设置属性
+json-del:password,user.token            =>    json对象删除属性，使用.分隔嵌套属性
+replace:http://localhost:8089 /api      =>    流式替换字符串，用于重写响应中的上游地址
+template:{{replace .Body "</body>" ""}} =>    使用text/template重写html
+*/
+
+import (
+	"io"
+	"strings"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	backend := eudore.NewApp()
+	backend.GetFunc("/json", func(ctx eudore.Context) {
+		ctx.SetHeader(eudore.HeaderContentType, eudore.MimeApplicationJSON)
+		ctx.WriteString(`{"name":"eudore","password":"secret","user":{"id":1,"token":"t"}}`)
+	})
+	backend.GetFunc("/html", func(ctx eudore.Context) {
+		ctx.SetHeader(eudore.HeaderContentType, eudore.MimeTextHTMLCharsetUtf8)
+		ctx.WriteString("<html><body><a href=\"http://localhost:8089/json\">json</a></body></html>")
+	})
+	backend.GetFunc("/links", func(ctx eudore.Context) {
+		ctx.SetHeader(eudore.HeaderContentType, eudore.MimeTextPlain)
+		ctx.WriteString(strings.Repeat("http://localhost:8089/links\n", 1000))
+	})
+	backend.PostFunc("/echo", func(ctx eudore.Context) {
+		ctx.SetHeader(eudore.HeaderContentType, eudore.MimeApplicationJSON)
+		io.Copy(ctx, ctx)
+	})
+	backend.Listen(":8089")
+
+	newTransforms := func(strs ...string) []interface{} {
+		var transforms []interface{}
+		for _, str := range strs {
+			t, err := eudore.NewProxyTransform(str)
+			if err != nil {
+				panic(err)
+			}
+			transforms = append(transforms, t)
+		}
+		return transforms
+	}
+	limit, _ := eudore.NewProxyTransform("json-set:{}")
+	limit.MaxSize = 16
+	passthrough, _ := eudore.NewProxyTransform("json-set:{}")
+	passthrough.MaxSize = 16
+	passthrough.Passthrough = true
+
+	app := eudore.NewApp()
+	h, _ := eudore.NewProxyHandler("http://localhost:8089", newTransforms(
+		`json-del:password,user.token`,
+		`json-set:{"source":"gateway"}`,
+		`request.json-del:role`,
+		`replace:http://localhost:8089 /api`,
+		`template:{{replace .Body "</body>" "<footer>eudore</footer></body>"}}`,
+	)...)
+	app.AnyFunc("/api/*", middleware.NewRewriteFunc(map[string]string{"/api/*": "/$0"}), h)
+	h, _ = eudore.NewProxyHandler("http://localhost:8089", limit)
+	app.AnyFunc("/limit/*", middleware.NewRewriteFunc(map[string]string{"/limit/*": "/$0"}), h)
+	h, _ = eudore.NewProxyHandler("http://localhost:8089", passthrough)
+	app.AnyFunc("/passthrough/*", middleware.NewRewriteFunc(map[string]string{"/passthrough/*": "/$0"}), h)
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/api/json").Do().CheckStatus(200).
+		CheckBodyString(`{"name":"eudore","source":"gateway","user":{"id":1}}`)
+	client.NewRequest("GET", "/api/html").Do().CheckStatus(200).
+		CheckBodyString("<html><body><a href=\"/api/json\">json</a><footer>eudore</footer></body></html>")
+	client.NewRequest("GET", "/api/links").Do().CheckStatus(200).
+		CheckBodyString(strings.Repeat("/api/links\n", 1000))
+	client.NewRequest("POST", "/api/echo").WithHeaderValue(eudore.HeaderContentType, eudore.MimeApplicationJSON).
+		WithBodyString(`{"name":"eudore","role":"admin"}`).Do().CheckStatus(200).
+		CheckBodyString(`{"name":"eudore","source":"gateway"}`)
+	client.NewRequest("POST", "/api/echo").WithHeaderValue(eudore.HeaderContentType, eudore.MimeApplicationJSON).
+		WithBodyString(`[1]`).Do().CheckStatus(400)
+	client.NewRequest("GET", "/limit/json").Do().CheckStatus(502)
+	client.NewRequest("GET", "/passthrough/json").Do().CheckStatus(200).
+		CheckBodyString(`{"name":"eudore","password":"secret","user":{"id":1,"token":"t"}}`)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
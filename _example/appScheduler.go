@@ -0,0 +1,63 @@
+package main
+
+/*
+app.Cron和app.Every添加后台任务，任务panic会被捕捉，app结束时停止调度并等待执行中的任务结束。
+*/
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	var count, panics int32
+	app := eudore.NewApp()
+	app.Every(20*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&count, 1)
+		return nil
+	}, "count")
+	// 任务执行时间大于间隔，默认跳过本次执行
+	app.Every(10*time.Millisecond, func(ctx context.Context) error {
+		select {
+		case <-time.After(35 * time.Millisecond):
+		case <-ctx.Done():
+		}
+		return errors.New("slow job error")
+	}, "slow")
+	app.Every(10*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&panics, 1)
+		panic("job panic")
+	}, "panic", eudore.SchedulerOverlapAllow)
+	app.Cron("*/5 * * * *", func(ctx context.Context) error {
+		return nil
+	}, "cron", eudore.SchedulerOverlapQueue)
+	app.Cron("@daily", func(ctx context.Context) error {
+		return nil
+	})
+
+	// 错误的参数
+	fmt.Println(app.Cron("* * * *", nil))
+	fmt.Println(app.Cron("60 * * * *", nil))
+	fmt.Println(app.Cron("0 0 30 2 *", nil))
+	fmt.Println(app.Every(0, nil))
+
+	app.GetFunc("/eudore/debug/jobs", app.Scheduler.HandleHTTP)
+	time.Sleep(100 * time.Millisecond)
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/eudore/debug/jobs").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(200).CheckBodyContainString("count", "slow", "nextrun").Out()
+
+	for _, job := range app.Scheduler.Jobs() {
+		fmt.Println(job.Name, job.Spec, job.Runs > 0, job.Skips > 0, job.LastError)
+	}
+	fmt.Println(atomic.LoadInt32(&count) > 2, atomic.LoadInt32(&panics) > 2)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
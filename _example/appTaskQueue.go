@@ -0,0 +1,74 @@
+package main
+
+/*
+app.Enqueue异步执行任务，失败任务指数退避重试，超过最大重试次数交给DeadLetter处理。
+TaskQueue.Backend可以替换为Redis等持久化存储。
+*/
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	var sends, fails int32
+	dead := make(chan *eudore.Task, 4)
+	app := eudore.NewApp()
+	app.TaskQueue = eudore.NewTaskQueue(app.Context, app.Logger)
+	app.TaskQueue.Workers = 2
+	app.TaskQueue.MaxRetry = 2
+	app.TaskQueue.Backoff = 10 * time.Millisecond
+	app.TaskQueue.DeadLetter = func(task *eudore.Task) {
+		dead <- task
+	}
+	app.AddTaskHandler("email", func(ctx context.Context, task *eudore.Task) error {
+		atomic.AddInt32(&sends, 1)
+		return nil
+	})
+	app.AddTaskHandler("flaky", func(ctx context.Context, task *eudore.Task) error {
+		// 第一次执行失败，重试成功
+		if task.Attempts == 0 {
+			return errors.New("flaky error")
+		}
+		atomic.AddInt32(&sends, 1)
+		return nil
+	})
+
+	app.PostFunc("/email", func(ctx eudore.Context) error {
+		return app.Enqueue(&eudore.Task{Name: "email", Payload: ctx.GetQuery("to")})
+	})
+	app.PostFunc("/flaky", func(ctx eudore.Context) error {
+		return app.Enqueue(&eudore.Task{Name: "flaky"})
+	})
+	app.PostFunc("/fail", func(ctx eudore.Context) error {
+		return app.Enqueue(&eudore.Task{Name: "fail", Func: func(context.Context, *eudore.Task) error {
+			atomic.AddInt32(&fails, 1)
+			panic("task panic")
+		}})
+	})
+	app.PostFunc("/none", func(ctx eudore.Context) error {
+		return app.Enqueue(&eudore.Task{Name: "none"})
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("POST", "/email?to=eudore").Do().CheckStatus(200)
+	client.NewRequest("POST", "/flaky").Do().CheckStatus(200)
+	client.NewRequest("POST", "/fail").Do().CheckStatus(200)
+	client.NewRequest("POST", "/none").Do().CheckStatus(200)
+
+	for i := 0; i < 2; i++ {
+		task := <-dead
+		fmt.Println("dead task:", task.Name, task.Attempts, task.Error)
+	}
+	fmt.Println(atomic.LoadInt32(&sends), atomic.LoadInt32(&fails))
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
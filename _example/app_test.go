@@ -11,6 +11,8 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -152,3 +154,116 @@ func createKey() {
 	pem.Encode(keyOut, &pem.Block{Type: "RAS PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(pk)})
 	keyOut.Close()
 }
+
+func TestTaskQueueCancel(t *testing.T) {
+	app := eudore.NewApp()
+	ctx, cancel := context.WithCancel(context.Background())
+	q := eudore.NewTaskQueue(ctx, app)
+	var count int32
+	q.AddHandler("count", func(context.Context, *eudore.Task) error {
+		atomic.AddInt32(&count, 1)
+		return nil
+	})
+	for i := 0; i < 100; i++ {
+		q.Enqueue(&eudore.Task{Name: "count"})
+	}
+	cancel()
+	q.Wait()
+	// Wait返回后不再执行任务。
+	n := atomic.LoadInt32(&count)
+	q.Enqueue(&eudore.Task{Name: "count"})
+	time.Sleep(20 * time.Millisecond)
+	if atomic.LoadInt32(&count) != n {
+		t.Errorf("task run after wait: %d %d", n, count)
+	}
+}
+
+func TestSchedulerWait(t *testing.T) {
+	app := eudore.NewApp()
+	s := eudore.NewScheduler(context.Background(), app)
+	var count int32
+	s.Every(time.Millisecond, func(context.Context) error {
+		atomic.AddInt32(&count, 1)
+		return nil
+	}, eudore.SchedulerOverlapAllow)
+	time.Sleep(20 * time.Millisecond)
+	s.Wait()
+	// Wait返回后不再触发任务。
+	n := atomic.LoadInt32(&count)
+	time.Sleep(20 * time.Millisecond)
+	if atomic.LoadInt32(&count) != n {
+		t.Errorf("scheduler trigger job after wait: %d %d", n, count)
+	}
+}
+
+func TestEventBusWait(t *testing.T) {
+	app := eudore.NewApp()
+	bus := eudore.NewEventBus(context.Background(), app)
+	var count int32
+	bus.On("user.*", func(context.Context, *eudore.Event) error {
+		atomic.AddInt32(&count, 1)
+		return nil
+	}, eudore.EventAsync)
+	go func() {
+		for bus.Emit("user.created", nil) == nil {
+		}
+	}()
+	time.Sleep(10 * time.Millisecond)
+	bus.Wait()
+	// Wait返回后拒绝发布事件。
+	n := atomic.LoadInt32(&count)
+	if err := bus.Emit("user.created", nil); err != eudore.ErrEventBusClosed {
+		t.Errorf("emit after wait error: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if atomic.LoadInt32(&count) != n {
+		t.Errorf("event handler run after wait: %d %d", n, count)
+	}
+}
+
+func TestAppAuditOptIn(t *testing.T) {
+	newApp := func(level string) *eudore.App {
+		app := eudore.NewApp()
+		app.Set("audit", level)
+		app.GetFunc("/audit", eudore.HandlerEmpty)
+		app.GetFunc("/audit", eudore.HandlerEmpty)
+		return app
+	}
+
+	// 未设置审计级别时production模式不审计。
+	app := newApp("")
+	if err := app.Listen("localhost:0"); err != nil {
+		t.Errorf("audit run by default: %v", err)
+	}
+	app.CancelFunc()
+	app.Run()
+
+	app = newApp("fatal")
+	if err := app.Listen("localhost:0"); err == nil {
+		t.Error("audit fatal level not return error")
+	}
+	app.Run()
+}
+
+func TestAppAuditDuplicateMiddleware(t *testing.T) {
+	newLogger := func(name string) eudore.HandlerFunc {
+		return func(ctx eudore.Context) {
+			ctx.Debug(name)
+		}
+	}
+	logger := newLogger("logger")
+	router := eudore.NewRouterStd(eudore.NewRouterCoreRadix())
+	router.AddMiddleware(logger, newLogger("access"), logger)
+	router.GetFunc("/audit", eudore.HandlerEmpty)
+
+	var kinds []string
+	for _, audit := range router.(interface{ Audit() []eudore.RouterAudit }).Audit() {
+		kinds = append(kinds, audit.Kind)
+		if !strings.Contains(audit.Message, "2 times") {
+			t.Errorf("duplicate middleware count: %s", audit.Message)
+		}
+	}
+	if len(kinds) != 1 || kinds[0] != eudore.RouterAuditDuplicateMiddleware {
+		t.Errorf("duplicate middleware audits %v", kinds)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+/*
+audit记录审计日志，使用哈希链防篡改，Verify函数校验审计日志文件。
+
+记录异步写入每个Sink，每个Sink使用独立的队列和哈希链，Close方法等待写入完成。
+*/
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/audit"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	path := "audit/audit.log"
+	defer os.RemoveAll("audit")
+	file, err := audit.NewSinkFile(path)
+	if err != nil {
+		panic(err)
+	}
+	var records []*audit.Record
+	auditor := audit.NewAuditor(file, audit.SinkFunc(func(r *audit.Record) error {
+		records = append(records, r)
+		return nil
+	}))
+
+	app := eudore.NewApp()
+	app.AddMiddleware(func(ctx eudore.Context) {
+		ctx.SetParam(eudore.ParamUNAME, ctx.GetHeader("X-User"))
+	})
+	app.AddMiddleware(audit.NewMiddleware(auditor))
+	app.PutFunc("/user/:id action=user:update", func(ctx eudore.Context) {
+		audit.SetDiff(ctx, map[string]interface{}{
+			"before": map[string]string{"name": "eudore"},
+			"after":  map[string]string{"name": string(ctx.Body())},
+		})
+	})
+	app.DeleteFunc("/user/:id action=user:delete", func(ctx eudore.Context) {
+		ctx.WriteHeader(403)
+	})
+	app.AnyFunc("/* action=hello", eudore.HandlerEmpty)
+
+	client := httptest.NewClient(app)
+	client.NewRequest("PUT", "/user/1").WithHeaderValue("X-User", "admin").WithBodyString("eudore2").Do().CheckStatus(200)
+	client.NewRequest("DELETE", "/user/1").WithHeaderValue("X-User", "guest").Do().CheckStatus(403)
+	client.NewRequest("GET", "/user/1").Do().CheckStatus(200)
+	client.NewRequest("POST", "/hello").Do().CheckStatus(200)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+	// 等待异步写入完成
+	auditor.Close()
+
+	// 重新打开文件继续哈希链
+	file, _ = audit.NewSinkFile(path)
+	auditor = audit.NewAuditor(file)
+	auditor.Record(&audit.Record{Subject: "system", Action: "restart", Result: "ok"}, nil)
+	auditor.Close()
+
+	body, _ := ioutil.ReadFile(path)
+	fmt.Println(len(records), records[0].Subject, records[1].Result, string(records[0].Diff))
+	fmt.Println(audit.Verify(bytes.NewReader(body)))
+	fmt.Println(audit.Verify(bytes.NewReader(bytes.Replace(body, []byte(`"result":"403"`), []byte(`"result":"200"`), 1))))
+}
@@ -0,0 +1,71 @@
+package main
+
+/*
+batch组件接收multipart/mixed或json数组格式的批量请求，每个子请求在进程内使用App的路由和中间件处理，然后合并全部子请求的响应返回。
+
+子请求未设置时复制批量请求的Authorization和Cookie，同样经过认证等中间件；不允许嵌套批量请求，默认最多100个子请求。
+*/
+
+import (
+	"strings"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/batch"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewLoggerFunc(app))
+	app.AddMiddleware("/api/", middleware.NewBasicAuthFunc(map[string]string{"eudore": "hello"}))
+	app.PostFunc("/batch", batch.NewBatch(app).HandleHTTP)
+	app.GetFunc("/api/user/:id", func(ctx eudore.Context) interface{} {
+		return map[string]interface{}{"id": ctx.GetParam("id"), "batch": batch.GetRequest(ctx) != nil}
+	})
+	app.PostFunc("/api/echo", func(ctx eudore.Context) {
+		ctx.SetHeader(eudore.HeaderContentType, eudore.MimeApplicationJSON)
+		ctx.Write(ctx.Body())
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("POST", "/batch").
+		WithHeaderValue(eudore.HeaderAuthorization, "Basic ZXVkb3JlOmhlbGxv").
+		WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).
+		WithBodyJSON([]map[string]interface{}{
+			{"id": "1", "method": "GET", "path": "/api/user/1", "headers": map[string]string{"Accept": "application/json"}},
+			{"id": "2", "method": "POST", "path": "/api/echo", "body": map[string]string{"name": "eudore"}},
+			{"id": "3", "method": "POST", "path": "/api/echo", "body": "text body"},
+			{"id": "4", "method": "GET", "path": "/api/user/4", "headers": map[string]string{"Authorization": "Basic eA=="}},
+			{"id": "5", "method": "POST", "path": "/batch"},
+		}).Do().CheckStatus(200).
+		CheckBodyContainString(`"id":"1","status":200`, `"body":{"batch":true,"id":"1"}`, `"body":{"name":"eudore"}`, `"body":"text body"`, `"id":"4","status":401`, `"id":"5","status":400`)
+
+	body := strings.Join([]string{
+		"--batch_1",
+		"Content-Type: application/http",
+		"Content-ID: <item1>",
+		"",
+		"GET /api/user/1 HTTP/1.1",
+		"Accept: application/json",
+		"",
+		"--batch_1",
+		"Content-Type: application/http",
+		"Content-ID: <item2>",
+		"",
+		"POST /api/echo HTTP/1.1",
+		"Content-Length: 5",
+		"",
+		"hello",
+		"--batch_1--",
+	}, "\r\n")
+	client.NewRequest("POST", "/batch").
+		WithHeaderValue(eudore.HeaderContentType, "multipart/mixed; boundary=batch_1").
+		WithHeaderValue(eudore.HeaderAuthorization, "Basic ZXVkb3JlOmhlbGxv").
+		WithBodyString(body).Do().CheckStatus(200).
+		CheckBodyContainString("Content-Id: response-item1", "HTTP/1.1 200 OK", `{"batch":true,"id":"1"}`, "response-item2", "hello")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
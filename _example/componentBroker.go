@@ -0,0 +1,110 @@
+package main
+
+/*
+broker订阅NATS、Redis消息，消息转换成POST请求使用App的路由和中间件处理。
+
+例子中使用本地模拟的Redis和NATS服务端发布消息。
+*/
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/broker"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewLoggerFunc(app), middleware.NewRecoverFunc())
+	orders := make(chan string, 1)
+	app.PostFunc("/broker/orders/:event", func(ctx eudore.Context) {
+		ctx.Infof("order %s: %s", ctx.GetParam("event"), ctx.Body())
+		orders <- broker.GetMessage(ctx).Topic
+	})
+	app.PostFunc("/broker/orders/panic", func(ctx eudore.Context) {
+		panic("order panic")
+	})
+	app.PostFunc("/broker/rpc/upper", func(ctx eudore.Context) {
+		ctx.WriteString(strings.ToUpper(string(ctx.Body())))
+	})
+
+	replys := make(chan string, 1)
+	redisAddr := runRedisServer("orders.created", "order-1")
+	natsAddr := runNATSServer(replys, "orders.panic", "rpc.upper")
+
+	dispatcher := broker.NewDispatcher(app)
+	go dispatcher.Run(app, broker.NewRedisSubscriber(redisAddr, ""), "orders.created")
+	go dispatcher.Run(app, broker.NewNATSSubscriber(natsAddr), "orders.*", "rpc.>")
+	for i := 0; i < 2; i++ {
+		select {
+		case topic := <-orders:
+			fmt.Println("redis message:", topic)
+		case reply := <-replys:
+			fmt.Println("nats reply:", reply)
+		case <-time.After(3 * time.Second):
+			fmt.Println("broker timeout")
+		}
+	}
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
+
+// runRedisServer 函数启动一个模拟Redis服务端，收到SUBSCRIBE后发布一条消息。
+func runRedisServer(channel, data string) string {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if strings.TrimSpace(line) == "SUBSCRIBE" {
+				fmt.Fprintf(conn, "*3\r\n$9\r\nsubscribe\r\n$%d\r\n%s\r\n:1\r\n", len(channel), channel)
+				fmt.Fprintf(conn, "*3\r\n$7\r\nmessage\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(channel), channel, len(data), data)
+			}
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// runNATSServer 函数启动一个模拟NATS服务端，收到PING后发布消息，收到PUB后写入replys。
+func runNATSServer(replys chan string, subjects ...string) string {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		fmt.Fprint(conn, "INFO {\"server_id\":\"eudore\"}\r\n")
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case line == "PING\r\n":
+				fmt.Fprint(conn, "PONG\r\n")
+				for i, subject := range subjects {
+					fmt.Fprintf(conn, "MSG %s %d _INBOX.%d 5\r\nhello\r\n", subject, i+1, i)
+				}
+			case strings.HasPrefix(line, "PUB _INBOX.1 "):
+				data, _ := reader.ReadString('\n')
+				replys <- strings.TrimSpace(data)
+			}
+		}
+	}()
+	return ln.Addr().String()
+}
@@ -0,0 +1,105 @@
+package main
+
+/*
+database封装database/sql，记录查询指标、慢查询日志和追踪，app结束时关闭连接池。
+
+例子使用一个模拟驱动，实际使用时导入对应数据库驱动。
+*/
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/database"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	sql.Register("example", exampleDriver{})
+	app := eudore.NewApp()
+	db, err := database.NewDB(app, map[string]interface{}{
+		"driver":       "example",
+		"dsn":          "example",
+		"maxopenconns": 4,
+		"slowquery":    20 * time.Millisecond,
+	})
+	if err != nil {
+		panic(err)
+	}
+	db.Tracer = func(ctx context.Context, query string) (context.Context, func(error)) {
+		app.Debug("span start:", query)
+		return ctx, func(err error) {
+			app.Debug("span end:", query, err)
+		}
+	}
+
+	app.GetFunc("/health/database", db.HandleHealth)
+	app.GetFunc("/user/:name", func(ctx eudore.Context) error {
+		var name string
+		err := db.QueryRowContext(ctx.GetContext(), "SELECT name FROM users WHERE name=?", ctx.GetParam("name")).Scan(&name)
+		if err != nil {
+			return err
+		}
+		ctx.WriteString(name)
+		return nil
+	})
+	app.GetFunc("/slow", func(ctx eudore.Context) error {
+		_, err := db.ExecContext(ctx.GetContext(), "SELECT sleep(30)")
+		return err
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/user/eudore").Do().CheckStatus(200).CheckBodyString("eudore")
+	client.NewRequest("GET", "/user/error").Do().CheckStatus(500)
+	client.NewRequest("GET", "/slow").Do().CheckStatus(200)
+	client.NewRequest("GET", "/health/database").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(200).CheckBodyContainString(`"queries":3`, `"errors":1`, `"slowqueries":1`)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
+
+// exampleDriver 定义一个模拟数据库驱动，查询返回参数值，参数为error时返回错误，sleep语句等待30ms。
+type exampleDriver struct{}
+type exampleConn struct{}
+type exampleStmt struct{ query string }
+type exampleRows struct{ values []driver.Value }
+
+func (exampleDriver) Open(string) (driver.Conn, error) { return exampleConn{}, nil }
+
+func (exampleConn) Prepare(query string) (driver.Stmt, error) { return exampleStmt{query}, nil }
+func (exampleConn) Close() error                              { return nil }
+func (exampleConn) Begin() (driver.Tx, error)                 { return exampleConn{}, nil }
+func (exampleConn) Commit() error                             { return nil }
+func (exampleConn) Rollback() error                           { return nil }
+
+func (stmt exampleStmt) Close() error  { return nil }
+func (stmt exampleStmt) NumInput() int { return -1 }
+func (stmt exampleStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if strings.Contains(stmt.query, "sleep") {
+		time.Sleep(30 * time.Millisecond)
+	}
+	return driver.RowsAffected(1), nil
+}
+func (stmt exampleStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if len(args) > 0 && args[0] == "error" {
+		return nil, errors.New("example query error")
+	}
+	return &exampleRows{args}, nil
+}
+
+func (rows *exampleRows) Columns() []string { return []string{"name"} }
+func (rows *exampleRows) Close() error      { return nil }
+func (rows *exampleRows) Next(dest []driver.Value) error {
+	if len(rows.values) == 0 {
+		return io.EOF
+	}
+	dest[0], rows.values = rows.values[0], rows.values[1:]
+	return nil
+}
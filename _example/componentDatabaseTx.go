@@ -0,0 +1,92 @@
+package main
+
+/*
+事务中间件每个请求开启一个事务，2xx响应提交事务，错误响应或panic回滚事务，路由参数tx=false时不开启事务。
+事务在响应首次写入body前结束，提交失败时响应500，处理函数写入的body不会返回给客户端。
+*/
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/database"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+var txlogs []string
+var txcommitError bool
+
+func main() {
+	sql.Register("exampletx", txExampleDriver{})
+	app := eudore.NewApp()
+	db, err := database.NewDB(app, &database.Config{Driver: "exampletx"})
+	if err != nil {
+		panic(err)
+	}
+
+	app.AddMiddleware(middleware.NewRecoverFunc(), database.NewTxFunc(db))
+	app.PostFunc("/user", func(ctx eudore.Context) error {
+		_, err := database.GetTx(ctx).ExecContext(ctx.GetContext(), "INSERT INTO users(name) VALUES(?)", ctx.Body())
+		return err
+	})
+	app.PostFunc("/user/error", func(ctx eudore.Context) error {
+		return errors.New("user error")
+	})
+	app.PostFunc("/user/panic", func(ctx eudore.Context) {
+		panic("user panic")
+	})
+	app.PostFunc("/user/commit", func(ctx eudore.Context) {
+		txcommitError = true
+		ctx.WriteString("created")
+	})
+	app.GetFunc("/user tx=false", func(ctx eudore.Context) {
+		ctx.WriteString(fmt.Sprint(database.GetTx(ctx) == nil))
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("POST", "/user").WithBodyString("eudore").Do().CheckStatus(200)
+	client.NewRequest("POST", "/user/error").Do().CheckStatus(500)
+	client.NewRequest("POST", "/user/panic").Do().CheckStatus(500)
+	client.NewRequest("POST", "/user/commit").Do().CheckStatus(500).CheckBodyString("")
+	client.NewRequest("GET", "/user").Do().CheckStatus(200).CheckBodyString("true")
+	fmt.Println(txlogs)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
+
+// txExampleDriver 定义一个模拟数据库驱动，记录事务提交和回滚。
+type txExampleDriver struct{}
+type txExampleConn struct{}
+type txExampleStmt struct{}
+
+func (txExampleDriver) Open(string) (driver.Conn, error) { return txExampleConn{}, nil }
+
+func (txExampleConn) Prepare(string) (driver.Stmt, error) { return txExampleStmt{}, nil }
+func (txExampleConn) Close() error                        { return nil }
+func (txExampleConn) Begin() (driver.Tx, error)           { return txExampleConn{}, nil }
+func (txExampleConn) Commit() error {
+	if txcommitError {
+		txcommitError = false
+		txlogs = append(txlogs, "commit error")
+		return errors.New("commit error")
+	}
+	txlogs = append(txlogs, "commit")
+	return nil
+}
+func (txExampleConn) Rollback() error {
+	txlogs = append(txlogs, "rollback")
+	return nil
+}
+
+func (txExampleStmt) Close() error                               { return nil }
+func (txExampleStmt) NumInput() int                              { return -1 }
+func (txExampleStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (txExampleStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not support query")
+}
@@ -0,0 +1,66 @@
+package main
+
+/*
+discovery启动时将实例注册到Consul或etcd，使用TTL心跳保持注册，app结束时注销实例。
+
+例子中使用本地模拟的Consul和etcd服务端输出收到的请求。
+*/
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/discovery"
+)
+
+func main() {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/v3/lease/grant":
+			w.Write([]byte(`{"ID":"7587"}`))
+		case "/v3/lease/keepalive":
+			w.Write([]byte(`{"result":{"ID":"7587","TTL":"1"}}`))
+		case "/v1/agent/service/register":
+			fmt.Println("consul register:", string(body))
+		}
+	}))
+	defer server.Close()
+
+	app := eudore.NewApp()
+	app.Set("discovery", map[string]interface{}{
+		"name":   "eudore",
+		"port":   8088,
+		"tags":   []string{"v1", "api"},
+		"health": "http://127.0.0.1:8088/health",
+		"ttl":    time.Second,
+	})
+	app.GetFunc("/health", eudore.HandlerEmpty)
+	app.Listen(":8088")
+
+	_, err := discovery.Register(app, discovery.NewConsul(server.URL, ""), app.Get("discovery"))
+	if err != nil {
+		panic(err)
+	}
+	_, err = discovery.Register(app, discovery.NewEtcd(server.URL, "/services"), app.Get("discovery"))
+	if err != nil {
+		panic(err)
+	}
+
+	time.Sleep(400 * time.Millisecond)
+	// app.CancelFunc()
+	app.Run()
+	mu.Lock()
+	fmt.Println(paths)
+	mu.Unlock()
+}
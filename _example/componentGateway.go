@@ -0,0 +1,64 @@
+package main
+
+/*
+gateway实现轻量API网关，路由表的每条路由依次执行限流、Basic认证、中间件链、路径重写，最后转发到上游。
+
+路由表可以使用LoadConfig从app配置加载、LoadFile从json文件加载，
+Watch在文件修改后热更新，InjectRoutes注册后台接口查看和替换路由表，加载失败时保留当前路由表。
+*/
+
+import (
+	"os"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/gateway"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	backend := eudore.NewApp()
+	backend.AnyFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteString("backend " + ctx.Method() + " " + ctx.Path())
+	})
+	backend.Listen(":8089")
+
+	file, _ := os.Create("gateway.json")
+	file.WriteString(`{"routes": [
+	{"path": "/api/users/*", "upstream": "http://localhost:8089", "rewrite": {"/api/users/*": "/v2/users/$0"}},
+	{"path": "/api/admin/*", "upstream": "http://localhost:8089", "auth": {"admin": "secret"}},
+	{"path": "/api/orders/*", "method": "GET", "upstream": "http://localhost:8089", "limit": {"speed": 1, "max": 1},
+		"middleware": ["requestid"]}
+]}`)
+	file.Close()
+	defer os.Remove("gateway.json")
+
+	app := eudore.NewApp()
+	gw := gateway.NewGateway(app)
+	app.Options(gw.LoadFile("gateway.json"))
+	gw.Watch("gateway.json", time.Second)
+	gw.InjectRoutes(app.Group("/eudore/debug"))
+	app.AnyFunc("/*", gw.HandleHTTP)
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/api/users/1").Do().CheckStatus(200).CheckBodyString("backend GET /v2/users/1")
+	client.NewRequest("GET", "/api/admin/1").Do().CheckStatus(401)
+	client.NewRequest("GET", "/api/admin/1").WithHeaderValue(eudore.HeaderAuthorization, "Basic YWRtaW46c2VjcmV0").Do().CheckStatus(200)
+	client.NewRequest("GET", "/api/orders/1").Do().CheckStatus(200)
+	client.NewRequest("GET", "/api/orders/1").Do().CheckStatus(200)
+	client.NewRequest("GET", "/api/orders/1").Do().CheckStatus(429)
+	client.NewRequest("POST", "/api/orders/1").Do().CheckStatus(404)
+	client.NewRequest("GET", "/eudore/debug/gateway/routes").Do().CheckStatus(200)
+
+	// 加载失败时保留当前路由表。
+	client.NewRequest("PUT", "/eudore/debug/gateway/routes").WithHeaderValue(eudore.HeaderContentType, eudore.MimeApplicationJSON).
+		WithBodyString(`[{"path": "/api/*", "upstream": "jwt:secret"}]`).Do().CheckStatus(500)
+	client.NewRequest("GET", "/api/users/1").Do().CheckStatus(200)
+	client.NewRequest("PUT", "/eudore/debug/gateway/routes").WithHeaderValue(eudore.HeaderContentType, eudore.MimeApplicationJSON).
+		WithBodyString(`[{"path": "/api/*", "upstream": "http://localhost:8089"}]`).Do().CheckStatus(200)
+	client.NewRequest("GET", "/api/admin/1").Do().CheckStatus(200).CheckBodyString("backend GET /api/admin/1")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
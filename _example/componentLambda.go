@@ -0,0 +1,55 @@
+package main
+
+/*
+lambda组件将AWS Lambda(API Gateway REST/HTTP API、ALB)或兼容格式的函数计算事件转换成请求，使用App的路由和中间件处理。
+
+Handler实现aws-lambda-go的lambda.Handler接口，相同的应用代码可以作为server或函数运行：
+
+	if os.Getenv("AWS_LAMBDA_RUNTIME_API") != "" {
+		awslambda.StartHandler(lambda.NewHandler(app))
+		return
+	}
+	app.Listen(":8088")
+*/
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/lambda"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewLoggerFunc(app, "route"))
+	app.GetFunc("/hello/:name", func(ctx eudore.Context) {
+		ctx.SetCookieValue("name", ctx.GetParam("name"), 3600)
+		ctx.WriteString("hello " + ctx.GetParam("name") + " " + ctx.GetQuery("lang") + " " + ctx.GetCookie("session"))
+	})
+	app.PostFunc("/echo", func(ctx eudore.Context) {
+		event := lambda.GetRequest(ctx)
+		ctx.SetHeader(eudore.HeaderContentType, "application/octet-stream")
+		ctx.SetHeader("X-Stage", event.RequestContext.Stage)
+		ctx.Write(ctx.Body())
+	})
+
+	handler := lambda.NewHandler(app)
+	events := []string{
+		// API Gateway REST API
+		`{"httpMethod":"GET","path":"/hello/eudore","multiValueHeaders":{"Cookie":["session=s1"]},"queryStringParameters":{"lang":"go"},"requestContext":{"requestId":"r1","stage":"prod","identity":{"sourceIp":"10.0.0.1"}}}`,
+		// API Gateway HTTP API
+		`{"version":"2.0","rawPath":"/hello/eudore","rawQueryString":"lang=go","cookies":["session=s2"],"headers":{"host":"api.example.com"},"requestContext":{"requestId":"r2","http":{"method":"GET","sourceIp":"10.0.0.2"}}}`,
+		// ALB
+		`{"httpMethod":"POST","path":"/echo","headers":{"content-type":"text/plain"},"body":"ZXVkb3Jl","isBase64Encoded":true,"requestContext":{"stage":"alb","elb":{"targetGroupArn":"arn:aws:elasticloadbalancing:tg"}}}`,
+	}
+	for _, event := range events {
+		resp, err := handler.Invoke(context.Background(), []byte(event))
+		fmt.Println(string(resp), err)
+	}
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
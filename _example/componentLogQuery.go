@@ -0,0 +1,48 @@
+package main
+
+/*
+logquery读取LoggerStd输出的json lines日志文件，按级别、属性、消息和时间范围过滤，用于在服务器上快速排查问题。
+
+Reader.Pattern使用glob匹配切割后的多个日志文件，按文件修改时间顺序读取。
+HandleHTTP方法的请求参数：
+level 日志级别，可以使用逗号分隔多个
+field key=value格式的属性条件，可以重复
+message 消息包含的字符串
+since、until 时间范围，可以使用RFC3339、日期、TimeFormat格式或者1h等时长
+limit 返回最后limit条日志，默认100
+*/
+
+import (
+	"os"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/component/logquery"
+)
+
+func main() {
+	defer os.RemoveAll("logger")
+	app := eudore.NewApp(eudore.NewLoggerStd(map[string]interface{}{
+		"path": "logger/app-yyyy-MM-dd-index.log",
+	}))
+	app.GetFunc("/err/:id", func(ctx eudore.Context) {
+		ctx.WithField("id", ctx.GetParam("id")).Error("database error")
+		ctx.Warning("retry")
+	})
+	// 查询前刷新日志缓冲
+	app.GetFunc("/admin/logs", func(ctx eudore.Context) {
+		app.Logger.Sync()
+	}, logquery.NewReader("logger/app-*.log").HandleHTTP)
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/err/1").Do().CheckStatus(200)
+	client.NewRequest("GET", "/err/2").Do().CheckStatus(200)
+	client.NewRequest("GET", "/admin/logs?level=warning,error&limit=3").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(200).Out()
+	client.NewRequest("GET", "/admin/logs?level=error&field=id=2&since=1h").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(200).Out()
+	client.NewRequest("GET", "/admin/logs?message=database&until=2099-01-02").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(200).Out()
+	client.NewRequest("GET", "/admin/logs?since=yesterday").Do().CheckStatus(400)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,105 @@
+package main
+
+/*
+openapi组件加载json格式的OpenAPI 3文档，使用文档定义的参数和body schema校验请求，校验失败时返回结构化的400错误。
+
+Strict模式下同时校验响应状态码和json响应body，响应不符合文档时记录错误日志并返回500，通常在开发环境中开启。
+*/
+
+import (
+	"strings"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/component/openapi"
+	"github.com/eudore/eudore/middleware"
+)
+
+const document = `{
+	"openapi": "3.0.3",
+	"servers": [{"url": "http://localhost:8088/api/v1"}],
+	"paths": {
+		"/users": {
+			"get": {
+				"parameters": [
+					{"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 100}},
+					{"name": "role", "in": "query", "schema": {"type": "string", "enum": ["admin", "user"]}}
+				],
+				"responses": {"200": {"description": "ok"}}
+			},
+			"post": {
+				"requestBody": {
+					"required": true,
+					"content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}
+				},
+				"responses": {"201": {"description": "created", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}}}
+			}
+		},
+		"/users/{id}": {
+			"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+			"get": {
+				"parameters": [{"name": "X-Tenant", "in": "header", "required": true, "schema": {"type": "string"}}],
+				"responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}}}
+			}
+		}
+	},
+	"components": {
+		"schemas": {
+			"User": {
+				"type": "object",
+				"required": ["name", "email"],
+				"additionalProperties": false,
+				"properties": {
+					"id": {"type": "integer"},
+					"name": {"type": "string", "minLength": 2, "maxLength": 32},
+					"email": {"type": "string", "format": "email"},
+					"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 3}
+				}
+			}
+		}
+	}
+}`
+
+func main() {
+	doc, err := openapi.LoadDocument(strings.NewReader(document))
+	if err != nil {
+		panic(err)
+	}
+	validator := openapi.NewValidator(doc)
+	validator.Strict = true
+
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewLoggerFunc(app))
+	app.AddMiddleware(validator.HandleHTTP)
+	app.GetFunc("/api/v1/users", func(ctx eudore.Context) interface{} {
+		return []string{"eudore"}
+	})
+	app.PostFunc("/api/v1/users", func(ctx eudore.Context) {
+		ctx.WriteHeader(eudore.StatusCreated)
+		ctx.SetHeader(eudore.HeaderContentType, eudore.MimeApplicationJSON)
+		ctx.Write(ctx.Body())
+	})
+	app.GetFunc("/api/v1/users/:id", func(ctx eudore.Context) interface{} {
+		// 响应缺少文档定义的必填属性email。
+		return map[string]interface{}{"id": 1, "name": "eudore"}
+	})
+
+	client := httptest.NewClient(app).AddHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON)
+	client.NewRequest("GET", "/api/v1/users?limit=10&role=admin").Do().CheckStatus(200)
+	client.NewRequest("GET", "/api/v1/users?limit=1000&role=guest").Do().CheckStatus(400).
+		CheckBodyContainString(`"name":"limit"`, `"name":"role"`)
+	client.NewRequest("GET", "/api/v1/users?limit=ten").Do().CheckStatus(400).
+		CheckBodyContainString("value 'ten' must be integer")
+	client.NewRequest("POST", "/api/v1/users").WithBodyJSON(map[string]interface{}{"name": "eudore", "email": "eudore@example.com"}).Do().CheckStatus(201)
+	client.NewRequest("POST", "/api/v1/users").WithBodyJSON(map[string]interface{}{"name": "e", "email": "eudore", "age": 18}).Do().CheckStatus(400).
+		CheckBodyContainString(`"name":"/name"`, `"name":"/email"`, `"name":"/age"`)
+	client.NewRequest("POST", "/api/v1/users").Do().CheckStatus(400).CheckBodyContainString("request body is required")
+	client.NewRequest("GET", "/api/v1/users/abc").Do().CheckStatus(400).
+		CheckBodyContainString(`"in":"path"`, `"in":"header"`)
+	client.NewRequest("GET", "/api/v1/users/1").WithHeaderValue("X-Tenant", "eudore").Do().CheckStatus(500).
+		CheckBodyContainString(`"in":"response"`, `"name":"/email"`)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,63 @@
+package main
+
+/*
+openapi.Contract在开发和测试环境中使用路由注册的Go类型或schema检查2xx json响应，响应结构变化时记录错误日志，Strict模式下返回500。
+
+Go类型使用json tag作为属性名，没有omitempty的属性为必填属性，且不允许额外属性。
+*/
+
+import (
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/component/openapi"
+	"github.com/eudore/eudore/middleware"
+)
+
+type contractUser struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	Email string   `json:"email,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+func main() {
+	contract := openapi.NewContract()
+	contract.Strict = true
+	contract.Register("GET", "/users/:id", contractUser{})
+	contract.Register("GET", "/users", []contractUser{})
+	contract.Register("GET", "/status", &openapi.Schema{
+		Type:     "object",
+		Required: []string{"status"},
+		Properties: map[string]*openapi.Schema{
+			"status": {Type: "string", Enum: []interface{}{"ok", "degraded"}},
+		},
+	})
+
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewLoggerFunc(app))
+	app.AddMiddleware(contract.HandleHTTP)
+	app.GetFunc("/users", func(ctx eudore.Context) interface{} {
+		return []contractUser{{ID: 1, Name: "eudore"}}
+	})
+	app.GetFunc("/users/:id", func(ctx eudore.Context) interface{} {
+		if ctx.GetParam("id") == "1" {
+			return contractUser{ID: 1, Name: "eudore"}
+		}
+		// 响应结构变化，name改为username。
+		return map[string]interface{}{"id": 2, "username": "eudore"}
+	})
+	app.GetFunc("/status", func(ctx eudore.Context) interface{} {
+		return map[string]interface{}{"status": "down"}
+	})
+
+	client := httptest.NewClient(app).AddHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON)
+	client.NewRequest("GET", "/users").Do().CheckStatus(200)
+	client.NewRequest("GET", "/users/1").Do().CheckStatus(200)
+	client.NewRequest("GET", "/users/2").Do().CheckStatus(500).
+		CheckBodyContainString(`"name":"/name"`, `"name":"/username"`)
+	client.NewRequest("GET", "/status").Do().CheckStatus(500).CheckBodyContainString(`"name":"/status"`)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
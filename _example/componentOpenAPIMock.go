@@ -0,0 +1,87 @@
+package main
+
+/*
+openapi.Mock使用OpenAPI文档或注册的示例响应模拟未实现的api，用于前端在后端开发期间调试。
+
+注册为404处理后，已经实现的路由使用真实处理函数，未实现的路由返回模拟响应；
+请求可以使用Prefer: code=404, example=name选择响应状态码和命名示例，示例不存在时使用schema生成。
+*/
+
+import (
+	"strings"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/component/openapi"
+	"github.com/eudore/eudore/middleware"
+)
+
+const mockDocument = `{
+	"openapi": "3.0.3",
+	"paths": {
+		"/users/{id}": {
+			"get": {
+				"responses": {
+					"200": {"content": {"application/json": {"examples": {
+						"admin": {"value": {"id": 1, "name": "admin", "role": "admin"}},
+						"guest": {"value": {"id": 2, "name": "guest", "role": "guest"}}
+					}}}},
+					"404": {"content": {"application/json": {"example": {"error": "user not found"}}}}
+				}
+			}
+		},
+		"/orders": {
+			"get": {
+				"responses": {"200": {"content": {"application/json": {"schema": {
+					"type": "array",
+					"items": {"$ref": "#/components/schemas/Order"}
+				}}}}}
+			}
+		}
+	},
+	"components": {
+		"schemas": {
+			"Order": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "integer", "minimum": 1},
+					"status": {"type": "string", "enum": ["paid", "shipped"]},
+					"created": {"type": "string", "format": "date-time"}
+				}
+			}
+		}
+	}
+}`
+
+func main() {
+	doc, err := openapi.LoadDocument(strings.NewReader(mockDocument))
+	if err != nil {
+		panic(err)
+	}
+	mock := openapi.NewMock(doc)
+	mock.Register("GET", "/products/:id", 200, map[string]interface{}{"id": 1, "name": "eudore"})
+
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewLoggerFunc(app))
+	app.AddHandler("404", "", mock.HandleHTTP)
+	app.GetFunc("/users/me", func(ctx eudore.Context) interface{} {
+		return "real handler"
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/users/1").Do().CheckStatus(200).CheckHeader("X-Eudore-Mock", "true").
+		CheckBodyContainString(`"name":"admin"`)
+	client.NewRequest("GET", "/users/2").WithHeaderValue("Prefer", "example=guest").Do().CheckStatus(200).
+		CheckBodyContainString(`"name":"guest"`)
+	client.NewRequest("GET", "/users/3").WithHeaderValue("Prefer", "code=404").Do().CheckStatus(404).
+		CheckBodyContainString("user not found")
+	client.NewRequest("GET", "/products/1").Do().CheckStatus(200).CheckBodyContainString(`"name":"eudore"`)
+	client.NewRequest("GET", "/orders").Do().CheckStatus(200).
+		CheckBodyContainString(`"status":"paid"`, `"id":1`, `"created":"2006-01-02T15:04:05Z"`)
+	client.NewRequest("GET", "/users/me").Do().CheckStatus(200).CheckBodyContainString("real handler")
+	client.NewRequest("GET", "/unknown").Do().CheckStatus(404)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,74 @@
+package main
+
+/*
+password实现argon2id、bcrypt密码hash，校验旧算法或旧参数的hash时返回新hash用于升级保存；
+Throttle按账号+IP记录登录失败次数，达到限制后锁定一段时间。
+*/
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/component/password"
+)
+
+func main() {
+	app := eudore.NewApp()
+	// 模拟用户数据，eudore使用旧的bcrypt hash。
+	legacy, _ := (&password.Bcrypt{Cost: 4}).Hash("eudore-pass")
+	hash, _ := password.Hash("admin-pass")
+	var lock sync.Mutex
+	users := map[string]string{"eudore": legacy, "admin": hash}
+	app.Info("admin hash:", hash)
+
+	throttle := password.NewThrottle(app.Cache)
+	throttle.MaxAttempts = 3
+	app.PostFunc("/login", func(ctx eudore.Context) {
+		name := ctx.GetQuery("name")
+		if !throttle.Check(ctx, name) {
+			return
+		}
+		lock.Lock()
+		hash := users[name]
+		lock.Unlock()
+		newhash, err := password.Verify(hash, ctx.GetQuery("password"))
+		if err != nil {
+			throttle.Failure(name, ctx.RealIP())
+			ctx.WriteHeader(eudore.StatusUnauthorized)
+			ctx.Fatal(err)
+			return
+		}
+		throttle.Success(name, ctx.RealIP())
+		if newhash != "" {
+			lock.Lock()
+			users[name] = newhash
+			lock.Unlock()
+			ctx.Info("upgrade password hash:", name)
+		}
+		ctx.WriteString("login " + name)
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("POST", "/login?name=admin&password=admin-pass").Do().CheckStatus(200)
+	client.NewRequest("POST", "/login?name=eudore&password=eudore-pass").Do().CheckStatus(200)
+	if !strings.HasPrefix(users["eudore"], "$argon2id$") {
+		panic("password hash not upgrade: " + users["eudore"])
+	}
+	client.NewRequest("POST", "/login?name=eudore&password=eudore-pass").Do().CheckStatus(200)
+
+	// 连续失败3次后锁定，其他账号不受影响。
+	for i := 0; i < 3; i++ {
+		client.NewRequest("POST", "/login?name=eudore&password=123456").Do().CheckStatus(401)
+	}
+	client.NewRequest("POST", "/login?name=eudore&password=eudore-pass").Do().CheckStatus(429).CheckHeader(eudore.HeaderRetryAfter, "900")
+	client.NewRequest("POST", "/login?name=admin&password=admin-pass").Do().CheckStatus(200)
+
+	_, err := (&password.Bcrypt{Cost: 4}).Hash(strings.Repeat("x", 73))
+	app.Info("bcrypt 73 bytes:", err)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
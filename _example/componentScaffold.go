@@ -0,0 +1,53 @@
+package main
+
+/*
+scaffold组件使用json格式的描述生成控制器、带bind和validate tag的请求响应结构体和路由注册代码，生成的控制器使用内存保存数据。
+
+命令行工具可以使用go:generate调用，输出文件已经存在时默认不会覆盖：
+//go:generate go run github.com/eudore/eudore/component/scaffold/cmd/scaffold -spec scaffold.json -out scaffold_gen.go
+*/
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/scaffold"
+)
+
+const scaffoldSpec = `{
+	"package": "api",
+	"resources": [
+		{
+			"name": "User",
+			"path": "users",
+			"fields": [
+				{"name": "Name", "type": "string", "validate": "nozero"},
+				{"name": "Age", "type": "int", "validate": "min:0,max:150"},
+				{"name": "CreatedAt", "type": "time"}
+			]
+		},
+		{
+			"name": "Tag",
+			"actions": ["list", "create"],
+			"fields": [{"name": "Label", "type": "string", "json": "label", "validate": "len:<32"}]
+		}
+	]
+}`
+
+func main() {
+	app := eudore.NewApp()
+	spec, err := scaffold.LoadSpec(strings.NewReader(scaffoldSpec))
+	if err != nil {
+		app.Error(err)
+	}
+	data, err := scaffold.Generate(spec)
+	if err != nil {
+		app.Error(err)
+	}
+	fmt.Println(string(data))
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
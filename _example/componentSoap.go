@@ -0,0 +1,68 @@
+package main
+
+/*
+soap.Server实现SOAP 1.1和1.2服务端，注册为路由处理函数后可以使用App的中间件。
+
+Operation使用Body第一个元素的本地名称或SOAPAction选择，返回值使用encoding/xml序列化；
+返回soap.Fault时生成对应Code的Fault响应，其他错误使用Server Code，GET请求携带wsdl参数时返回WSDL文件。
+*/
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	fpath "path/filepath"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/component/soap"
+	"github.com/eudore/eudore/middleware"
+)
+
+type getPriceRequest struct {
+	XMLName xml.Name `xml:"GetPrice"`
+	Item    string   `xml:"Item"`
+}
+
+type getPriceResponse struct {
+	XMLName xml.Name `xml:"urn:stock GetPriceResponse"`
+	Price   float64  `xml:"Price"`
+}
+
+func main() {
+	wsdl := fpath.Join(os.TempDir(), "eudore-stock.wsdl")
+	ioutil.WriteFile(wsdl, []byte(`<?xml version="1.0"?><definitions xmlns="http://schemas.xmlsoap.org/wsdl/" name="Stock"></definitions>`), 0644)
+	defer os.Remove(wsdl)
+
+	srv := soap.NewServer(wsdl)
+	srv.AddOperation("GetPrice", func(ctx eudore.Context, req *soap.Request) (interface{}, error) {
+		var data getPriceRequest
+		if err := req.Decode(&data); err != nil {
+			return nil, &soap.Fault{Code: soap.FaultClient, String: err.Error()}
+		}
+		if data.Item != "eudore" {
+			return nil, &soap.Fault{Code: soap.FaultClient, String: "item not found: " + data.Item}
+		}
+		return &getPriceResponse{Price: 34.5}, nil
+	})
+
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewLoggerFunc(app))
+	app.AnyFunc("/stock", srv.HandleHTTP)
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/stock?wsdl").Do().CheckStatus(200).CheckBodyContainString(`name="Stock"`).Out()
+	client.NewRequest("POST", "/stock").WithHeaderValue(eudore.HeaderContentType, eudore.MimeTextXMLCharsetUtf8).
+		WithHeaderValue(soap.HeaderSOAPAction, `"urn:stock/GetPrice"`).
+		WithBodyString(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><GetPrice><Item>eudore</Item></GetPrice></soap:Body></soap:Envelope>`).Do().
+		CheckStatus(200).CheckBodyContainString(`<GetPriceResponse xmlns="urn:stock"><Price>34.5</Price></GetPriceResponse>`).Out()
+	client.NewRequest("POST", "/stock").WithHeaderValue(eudore.HeaderContentType, soap.MimeApplicationSOAPXML).
+		WithBodyString(`<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><GetPrice><Item>go</Item></GetPrice></env:Body></env:Envelope>`).Do().
+		CheckStatus(400).CheckBodyContainString(`<soap:Value>soap:Sender</soap:Value>`, "item not found: go").Out()
+	client.NewRequest("POST", "/stock").WithHeaderValue(eudore.HeaderContentType, eudore.MimeTextXML).
+		WithBodyString(`<Envelope/>`).Do().CheckStatus(500).CheckBodyContainString(`<faultcode>soap:Client</faultcode>`).Out()
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,74 @@
+package main
+
+/*
+upload组件实现tus 1.0.0协议的断点续传上传，上传数据使用可替换的Storage保存，内置文件存储和内存存储。
+
+客户端使用POST创建上传获得Location，使用HEAD查询已经上传的偏移，使用PATCH从偏移继续写入数据，
+上传完成后调用OnComplete，完成的上传使用GET下载。
+
+ctx.WriteContent使用任意io.ReadSeeker返回内容，支持Range、If-Range断点续传下载。
+*/
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/component/upload"
+)
+
+func main() {
+	app := eudore.NewApp()
+	up := upload.NewUpload(upload.NewStorageMemory(), app.Group("/files"))
+	up.MaxSize = 1 << 20
+	up.OnComplete = func(ctx eudore.Context, info *upload.Info) error {
+		ctx.Info("upload complete:", info.ID, info.Metadata["filename"], info.Size)
+		return nil
+	}
+
+	modtime := time.Now()
+	app.GetFunc("/report", func(ctx eudore.Context) {
+		ctx.WriteContent("report.txt", modtime, strings.NewReader("0123456789"))
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("OPTIONS", "/files/").Do().CheckStatus(204).CheckHeader("Tus-Version", "1.0.0")
+	client.NewRequest("POST", "/files/").WithHeaderValue("Upload-Length", "10").Do().CheckStatus(412)
+	resp := client.NewRequest("POST", "/files/").
+		WithHeaderValue("Tus-Resumable", "1.0.0").
+		WithHeaderValue("Upload-Length", "10").
+		WithHeaderValue("Upload-Metadata", "filename aGVsbG8udHh0").
+		Do().CheckStatus(201)
+	location := resp.Header().Get(eudore.HeaderLocation)
+
+	patch := func(offset, body string) *httptest.ResponseWriterTest {
+		return client.NewRequest("PATCH", location).
+			WithHeaderValue("Tus-Resumable", "1.0.0").
+			WithHeaderValue("Upload-Offset", offset).
+			WithHeaderValue(eudore.HeaderContentType, "application/offset+octet-stream").
+			WithBodyString(body).Do()
+	}
+	patch("0", "hello").CheckStatus(204).CheckHeader("Upload-Offset", "5")
+	patch("0", "hello").CheckStatus(409)
+	client.NewRequest("GET", location).Do().CheckStatus(409)
+	client.NewRequest("HEAD", location).WithHeaderValue("Tus-Resumable", "1.0.0").Do().
+		CheckStatus(200).CheckHeader("Upload-Offset", "5", "Upload-Length", "10")
+	patch("5", "world").CheckStatus(204).CheckHeader("Upload-Offset", "10")
+	client.NewRequest("GET", location).WithHeaderValue("Range", "bytes=5-").Do().CheckStatus(206).CheckBodyString("world")
+
+	client.NewRequest("GET", "/report").Do().CheckStatus(200).CheckBodyString("0123456789")
+	client.NewRequest("GET", "/report").WithHeaderValue("Range", "bytes=2-4").Do().
+		CheckStatus(206).CheckHeader(eudore.HeaderContentRange, "bytes 2-4/10").CheckBodyString("234")
+	client.NewRequest("GET", "/report").WithHeaderValue("Range", "bytes=2-4").
+		WithHeaderValue("If-Range", modtime.Add(-time.Hour).UTC().Format(http.TimeFormat)).Do().
+		CheckStatus(200).CheckBodyString("0123456789")
+
+	client.NewRequest("DELETE", location).WithHeaderValue("Tus-Resumable", "1.0.0").Do().CheckStatus(204)
+	client.NewRequest("HEAD", location).WithHeaderValue("Tus-Resumable", "1.0.0").Do().CheckStatus(404)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,67 @@
+package main
+
+/*
+webhook组件实现出站webhook投递，注册带密钥的接收端点，事件放入任务队列后异步投递到匹配的端点。
+
+请求body为json格式的事件，使用X-Webhook-Signature header传递签名，接收端使用webhook.Verify函数验证签名和时间戳；
+投递失败时使用指数退避和随机抖动重试，每次投递后调用OnDelivery持久化投递状态。
+
+InjectRoutes注入后台管理路由:
+GET /webhook/endpoints
+POST /webhook/endpoints
+DELETE /webhook/endpoints/:id
+GET /webhook/deliveries
+POST /webhook/deliveries/:id/redeliver
+*/
+
+import (
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/eudore/eudore"
+	eudorehttptest "github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/component/webhook"
+)
+
+func main() {
+	app := eudore.NewApp()
+	var fails int32
+	app.PostFunc("/receiver/:name", func(ctx eudore.Context) {
+		if !webhook.Verify("secret", ctx.Request().Header, ctx.Body(), 5*time.Minute) {
+			ctx.WriteHeader(eudore.StatusUnauthorized)
+			return
+		}
+		// 模拟第一次接收失败。
+		if ctx.GetParam("name") == "flaky" && atomic.AddInt32(&fails, 1) == 1 {
+			ctx.WriteHeader(eudore.StatusServiceUnavailable)
+			return
+		}
+		ctx.Info("receive webhook:", ctx.GetHeader(webhook.HeaderXWebhookEvent), string(ctx.Body()))
+	})
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	hooks := webhook.NewManager(app, app.Logger)
+	hooks.Queue.Backoff = 100 * time.Millisecond
+	hooks.OnDelivery = func(d *webhook.Delivery) {
+		app.Debugf("delivery %s %s %s attempts %d", d.ID, d.URL, d.Status, d.Attempts)
+	}
+	hooks.InjectRoutes(app.Group("/admin"))
+	hooks.AddEndpoint(&webhook.Endpoint{URL: server.URL + "/receiver/order", Secret: "secret", Events: []string{"order.*"}})
+	hooks.AddEndpoint(&webhook.Endpoint{URL: server.URL + "/receiver/flaky", Secret: "secret"})
+	hooks.AddEndpoint(&webhook.Endpoint{URL: server.URL + "/receiver/bad", Secret: "bad", Events: []string{"user.*"}})
+
+	hooks.Emit("order.created", map[string]interface{}{"id": 1, "amount": 100})
+	hooks.Emit("user.created", map[string]interface{}{"id": 2})
+
+	client := eudorehttptest.NewClient(app)
+	client.NewRequest("POST", "/admin/webhook/endpoints").WithBodyJSONValue("url", server.URL+"/receiver/new").Do().CheckStatus(200)
+	client.NewRequest("GET", "/admin/webhook/endpoints").Do().CheckStatus(200).CheckBodyContainString("/receiver/new")
+	time.Sleep(500 * time.Millisecond)
+	client.NewRequest("GET", "/admin/webhook/deliveries").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(200).CheckBodyContainString(`"status":"success"`, `"statuscode":401`)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,61 @@
+package main
+
+/*
+ctx.Attachment使用指定的下载文件名称返回内容，设置Content-Disposition和根据扩展名推断的Content-Type，
+非ascii文件名称使用RFC 5987编码的filename*，io.ReadSeeker支持Range断点续传。
+
+ctx.SendFile返回文件，文件不存在时设置状态码404；路由参数download-rate设置每个请求的下载速度，单位字节每秒。
+*/
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	fpath "path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	file := fpath.Join(os.TempDir(), "eudore-attachment.txt")
+	ioutil.WriteFile(file, []byte("0123456789"), 0644)
+	defer os.Remove(file)
+
+	app := eudore.NewApp()
+	app.GetFunc("/report", func(ctx eudore.Context) error {
+		return ctx.Attachment("报表 2020.csv", strings.NewReader("id,name\n1,eudore\n"))
+	})
+	app.GetFunc("/stream", func(ctx eudore.Context) error {
+		return ctx.Attachment("data.bin", bytes.NewBufferString("stream"))
+	})
+	app.GetFunc("/file", func(ctx eudore.Context) error {
+		return ctx.SendFile(file, "")
+	})
+	app.GetFunc("/missing", func(ctx eudore.Context) error {
+		return ctx.SendFile("not-found.txt", "")
+	})
+	app.GetFunc("/slow download-rate=20", func(ctx eudore.Context) error {
+		return ctx.Attachment("slow.txt", strings.NewReader("0123456789"))
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/report").Do().CheckStatus(200).
+		CheckHeader(eudore.HeaderContentDisposition, `attachment; filename="__ 2020.csv"; filename*=UTF-8''%E6%8A%A5%E8%A1%A8%202020.csv`).Out()
+	client.NewRequest("GET", "/stream").Do().CheckStatus(200).
+		CheckHeader(eudore.HeaderContentType, eudore.MimeApplicationOctetStream, eudore.HeaderContentDisposition, `attachment; filename="data.bin"`).CheckBodyString("stream").Out()
+	client.NewRequest("GET", "/file").WithHeaderValue(eudore.HeaderRange, "bytes=2-5").Do().CheckStatus(206).
+		CheckHeader(eudore.HeaderContentDisposition, `attachment; filename="eudore-attachment.txt"`).CheckBodyString("2345").Out()
+	client.NewRequest("GET", "/missing").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(404).Out()
+	now := time.Now()
+	client.NewRequest("GET", "/slow").Do().CheckStatus(200).CheckBodyString("0123456789")
+	if time.Since(now) < 400*time.Millisecond {
+		panic("download rate not limited")
+	}
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
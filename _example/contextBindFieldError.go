@@ -0,0 +1,52 @@
+package main
+
+/*
+Bind请求值无法转换成字段类型时返回eudore.FieldErrors，包含每个失败字段的名称、期望类型、请求值和消息，
+Validate校验失败返回相同结构的FieldErrors，额外包含校验规则。
+
+ctx.Fatal参数为FieldErrors时响应状态码400，默认错误响应fields为全部字段错误。
+*/
+
+import (
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+type searchRequest struct {
+	Keyword string        `alias:"keyword" validate:"nozero"`
+	Page    int           `alias:"page"`
+	Size    int           `alias:"size" validate:"max:100"`
+	Ids     []int         `alias:"ids"`
+	Timeout time.Duration `alias:"timeout"`
+	Enable  *bool         `alias:"enable"`
+}
+
+func main() {
+	app := eudore.NewApp()
+	app.AnyFunc("/search valid=1", func(ctx eudore.Context) error {
+		var req searchRequest
+		err := ctx.Bind(&req)
+		if err != nil {
+			return err
+		}
+		return ctx.Render(req)
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/search?keyword=eudore&page=1&size=10&ids=1&timeout=3s&enable=true").Do().CheckStatus(200)
+	client.NewRequest("GET", "/search?keyword=eudore&page=one&size=10&timeout=3x&enable=yes").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(400).
+		CheckBodyContainString(`"field":"enable","type":"*bool","value":"yes"`, `"field":"page","type":"int","value":"one"`, `"field":"timeout"`).Out()
+	client.NewRequest("POST", "/search").WithHeaderValue(eudore.HeaderContentType, eudore.MimeApplicationForm).WithBodyString("keyword=eudore&ids=1&ids=b").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(400).
+		CheckBodyContainString(`"field":"ids","type":"[]int","value":"b"`)
+	client.NewRequest("POST", "/search").WithBodyJSON(map[string]interface{}{"keyword": "eudore", "page": "1"}).WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(400).
+		CheckBodyContainString(`"field":"page","type":"int","value":"string"`).Out()
+	// 校验错误使用相同结构
+	client.NewRequest("GET", "/search?keyword=eudore&size=1000").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(400).
+		CheckBodyContainString(`"field":"Size","type":"int","value":"1000","rule":"max:100"`).Out()
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
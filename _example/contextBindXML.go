@@ -0,0 +1,56 @@
+package main
+
+/*
+BindXML和RenderXML的结构体使用encoding/xml相同的tag规则，支持属性、命名空间和CDATA。
+
+使用map[string]interface{}时元素名称保留命名空间前缀，属性使用@前缀，文本使用#text，CDATA文本使用#cdata，
+可以原样转发soap等包含命名空间前缀的xml；application/soap+xml等+xml类型的请求也使用BindXML。
+*/
+
+import (
+	"encoding/xml"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+type xmlBook struct {
+	XMLName xml.Name `xml:"urn:books book"`
+	ID      string   `xml:"id,attr"`
+	Title   string   `xml:"title"`
+	Summary struct {
+		Text string `xml:",cdata"`
+	} `xml:"summary"`
+}
+
+func main() {
+	app := eudore.NewApp()
+	app.PostFunc("/book", func(ctx eudore.Context) interface{} {
+		var book xmlBook
+		ctx.Bind(&book)
+		return &book
+	})
+	app.PostFunc("/soap", func(ctx eudore.Context) interface{} {
+		data := make(map[string]interface{})
+		ctx.Bind(data)
+		return data
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("POST", "/book").WithHeaderValue(eudore.HeaderContentType, eudore.MimeApplicationXML).
+		WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationXML).
+		WithBodyString(`<book xmlns="urn:books" id="1"><title>eudore</title><summary><![CDATA[<b>go</b> web]]></summary></book>`).Do().
+		CheckStatus(200).CheckBodyString(`<book xmlns="urn:books" id="1"><title>eudore</title><summary><![CDATA[<b>go</b> web]]></summary></book>`).Out()
+	client.NewRequest("POST", "/soap").WithHeaderValue(eudore.HeaderContentType, "application/soap+xml; charset=utf-8").
+		WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationXML).
+		WithBodyString(`<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope"><soap:Body><m:Price xmlns:m="urn:stock" unit="usd">34.5</m:Price></soap:Body></soap:Envelope>`).Do().
+		CheckStatus(200).CheckBodyString(`<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope"><soap:Body><m:Price unit="usd" xmlns:m="urn:stock">34.5</m:Price></soap:Body></soap:Envelope>`).Out()
+	client.NewRequest("POST", "/soap").WithHeaderValue(eudore.HeaderContentType, eudore.MimeTextXML).
+		WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).
+		WithBodyString(`<?xml version="1.0" encoding="ISO-8859-1"?><note lang="fr">caf`+"\xe9"+`</note>`).Do().
+		CheckStatus(200).CheckBodyContainString(`"@lang":"fr"`, `"#text":"café"`).Out()
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
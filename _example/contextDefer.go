@@ -0,0 +1,68 @@
+package main
+
+/*
+ctx.Defer注册请求结束后执行的清理函数，在全部处理函数执行完成后按注册的相反顺序执行，处理函数panic时也会执行，
+用于删除临时文件、完成指标记录和释放请求占用的资源。
+
+ctx.SetValue和ctx.GetValue读写请求范围的值，请求结束后清空，与WithContext相比不会创建新的context.Context。
+*/
+
+import (
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+type deferStartKey struct{}
+
+func main() {
+	var inflight, cleanups int64
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewRecoverFunc())
+	app.AddMiddleware(func(ctx eudore.Context) {
+		atomic.AddInt64(&inflight, 1)
+		ctx.SetValue(deferStartKey{}, time.Now())
+		ctx.Defer(func() {
+			atomic.AddInt64(&inflight, -1)
+			atomic.AddInt64(&cleanups, 1)
+			ctx.Debugf("request %s cost %s", ctx.Path(), time.Since(ctx.GetValue(deferStartKey{}).(time.Time)))
+		})
+	})
+	app.PostFunc("/upload", func(ctx eudore.Context) error {
+		file, err := ioutil.TempFile("", "eudore-upload-")
+		if err != nil {
+			return err
+		}
+		ctx.Defer(func() {
+			file.Close()
+			os.Remove(file.Name())
+			ctx.Info("remove temp file", file.Name())
+		})
+		_, err = file.Write(ctx.Body())
+		return err
+	})
+	app.GetFunc("/panic", func(ctx eudore.Context) {
+		ctx.Defer(func() {
+			ctx.Info("defer run after panic")
+		})
+		panic("handler panic")
+	})
+	app.GetFunc("/stats", func(ctx eudore.Context) interface{} {
+		return map[string]int64{"inflight": atomic.LoadInt64(&inflight), "cleanups": atomic.LoadInt64(&cleanups)}
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("POST", "/upload").WithBodyString("file data").Do().CheckStatus(200)
+	client.NewRequest("GET", "/panic").Do().CheckStatus(500)
+	client.NewRequest("GET", "/stats").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().
+		CheckStatus(200).CheckBodyContainString(`"cleanups":2`, `"inflight":1`)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
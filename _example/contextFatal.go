@@ -0,0 +1,42 @@
+package main
+
+/*
+ctx.Fatal写入错误日志，设置响应状态码500并结束请求上下文处理，处理函数返回后渲染一次错误响应。
+
+请求中Error、Errorf、Fatal和Fatalf方法的错误消息会被收集，存在多个错误消息时默认错误响应的errors为全部消息；
+修改eudore.DefaultContextFatalFunc可以自定义错误响应，参数errs为全部错误消息，返回nil时不渲染。
+*/
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AnyFunc("/fatal", func(ctx eudore.Context) {
+		ctx.Error("cache miss")
+		// 多次调用Fatal只渲染一次错误响应
+		ctx.Fatal(errors.New("name is empty"))
+		ctx.WithField("field", "age").Fatal("age is invalid")
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/fatal").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(500).CheckBodyContainString(`"error":"name is empty"`, `"errors":["cache miss","name is empty","age is invalid"]`).Out()
+
+	// 自定义错误响应
+	eudore.DefaultContextFatalFunc = func(ctx eudore.Context, errs []string) interface{} {
+		return map[string]interface{}{
+			"code":    ctx.Response().Status(),
+			"message": strings.Join(errs, "; "),
+		}
+	}
+	client.NewRequest("GET", "/fatal").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(500).CheckBodyContainString(`"message":"cache miss; name is empty; age is invalid"`).Out()
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,62 @@
+//go:build go1.18
+// +build go1.18
+
+package main
+
+/*
+go1.18以上版本可以使用eudore.NewContextKey创建类型化的上下文key，使用Get、Set方法读写值，避免字符串key和interface{}类型断言。
+
+值保存在ctx.GetContext()中，http.Handler可以使用key.From(r.Context())读取。
+*/
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+type contextUser struct {
+	ID   int
+	Name string
+}
+
+var (
+	keyUser    = eudore.NewContextKey[*contextUser]("user")
+	keyTenant  = eudore.NewContextKey[string]("tenant")
+	keyTimeout = eudore.NewContextKey[int]("timeout")
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware(func(ctx eudore.Context) {
+		if ctx.GetHeader(eudore.HeaderAuthorization) != "" {
+			keyUser.Set(ctx, &contextUser{ID: 1, Name: "eudore"})
+		}
+		keyTenant.Set(ctx, ctx.GetHeader("X-Tenant"))
+	})
+	app.GetFunc("/user", func(ctx eudore.Context) interface{} {
+		user, ok := keyUser.Get(ctx)
+		if !ok {
+			ctx.WriteHeader(eudore.StatusUnauthorized)
+			return "anonymous"
+		}
+		return fmt.Sprintf("%s@%s timeout=%d", user.Name, keyTenant.Value(ctx), keyTimeout.Value(ctx))
+	})
+	app.GetFunc("/std", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant, _ := keyTenant.From(r.Context())
+		w.Write([]byte("tenant " + tenant))
+	}))
+	app.Info(eudore.ListContextKeys())
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/user").WithHeaderValue(eudore.HeaderAuthorization, "token").WithHeaderValue("X-Tenant", "t1").Do().
+		CheckStatus(200).CheckBodyContainString("eudore@t1 timeout=0")
+	client.NewRequest("GET", "/user").Do().CheckStatus(401)
+	client.NewRequest("GET", "/std").WithHeaderValue("X-Tenant", "t2").Do().CheckStatus(200).CheckBodyString("tenant t2")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,47 @@
+package main
+
+/*
+ctx.WithField返回的Logout使用池化的日志条目，输出一次日志后条目放回池中，
+在处理函数创建的协程中使用会在请求结束后与其他请求竞争。
+
+NewLogoutDetached函数复制ctx.Logger()和fields的日志属性，创建一个脱离请求的Logout，
+每次输出复制日志条目，可以安全的传递给协程并重复使用。
+*/
+
+import (
+	"sync"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewRequestIDFunc(nil))
+
+	var wg sync.WaitGroup
+	app.PostFunc("/order/:id", func(ctx eudore.Context) {
+		log := eudore.NewLogoutDetached(ctx, eudore.Fields{"order": ctx.GetParam("id")})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// 请求已经结束，继续使用脱离请求的Logout。
+			time.Sleep(20 * time.Millisecond)
+			log.Info("send order email")
+			log.WithField("retry", 1).Warning("send order sms failed")
+			log.Info("order notify done")
+		}()
+		ctx.WriteString("accepted")
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("POST", "/order/1").Do().CheckStatus(200)
+	client.NewRequest("POST", "/order/2").Do().CheckStatus(200)
+	wg.Wait()
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
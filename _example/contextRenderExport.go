@@ -0,0 +1,61 @@
+package main
+
+/*
+eudore.NewRenderExport创建导出报表的Renderer，路径后缀为.csv、.xlsx或Accept为对应类型时使用RenderCSV、RenderXLSX，否则使用原Renderer。
+
+数据可以是切片、数组或chan，逐行写入响应；结构体元素的标题行使用tag csv、json名称或字段名称。
+*/
+
+import (
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+type reportRow struct {
+	ID       int       `json:"id"`
+	Name     string    `csv:"name"`
+	Amount   float64   `json:"amount"`
+	Paid     bool      `csv:"paid"`
+	Created  time.Time `csv:"created"`
+	Password string    `json:"-"`
+}
+
+func main() {
+	app := eudore.NewApp()
+	app.Renderer = eudore.NewRenderExport(app.Renderer)
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	report := func(ctx eudore.Context) interface{} {
+		return []reportRow{
+			{1, "eudore", 10.5, true, created, "secret"},
+			{2, "a,\"b\"", 0, false, created, "secret"},
+		}
+	}
+	app.GetFunc("/report", report)
+	app.GetFunc("/report.csv", report)
+	app.GetFunc("/report.xlsx", report)
+	app.GetFunc("/stream", func(ctx eudore.Context) interface{} {
+		ch := make(chan map[string]interface{})
+		go func() {
+			for i := 0; i < 3; i++ {
+				ch <- map[string]interface{}{"index": i, "name": "row"}
+			}
+			close(ch)
+		}()
+		return ch
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/report").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(200).
+		CheckHeader(eudore.HeaderContentType, eudore.MimeApplicationJSONUtf8).Out()
+	client.NewRequest("GET", "/report.csv").Do().CheckStatus(200).CheckHeader(eudore.HeaderContentType, eudore.MimeTextCSVUtf8).
+		CheckBodyString("id,name,amount,paid,created\n1,eudore,10.5,true,2020-01-02T03:04:05Z\n2,\"a,\"\"b\"\"\",0,false,2020-01-02T03:04:05Z\n").Out()
+	client.NewRequest("GET", "/report.xlsx").Do().CheckStatus(200).CheckHeader(eudore.HeaderContentType, eudore.MimeApplicationXLSX)
+	client.NewRequest("GET", "/stream").WithHeaderValue(eudore.HeaderAccept, eudore.MimeTextCSV).Do().CheckStatus(200).
+		CheckBodyString("index,name\n0,row\n1,row\n2,row\n").Out()
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,63 @@
+package main
+
+/*
+eudore.NewRenderFilter创建按调用者角色过滤结构体字段的Renderer，同一个响应类型可以安全的用于多个权限级别。
+
+字段tag render指定允许访问的角色，多个角色使用|分隔，调用者没有任一角色时省略字段；
+追加mask选项时字符串字段使用eudore.DefaultRenderFilterMask代替，其他类型字段为null。
+第二个参数返回调用者角色，为空时使用路由参数ROLE的逗号分隔值。
+*/
+
+import (
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+type renderUser struct {
+	renderModel
+	Name    string         `json:"name"`
+	Email   string         `json:"email" render:"admin|support,mask"`
+	Salary  int            `json:"salary,omitempty" render:"admin"`
+	Friends []renderFriend `json:"friends"`
+}
+
+type renderModel struct {
+	ID        int       `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type renderFriend struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone" render:"admin,mask"`
+}
+
+func main() {
+	app := eudore.NewApp()
+	app.Renderer = eudore.NewRenderFilter(app.Renderer, nil)
+	app.AddMiddleware(func(ctx eudore.Context) {
+		ctx.SetParam(eudore.ParamRole, ctx.GetHeader("X-Role"))
+	})
+	app.GetFunc("/user", func(ctx eudore.Context) interface{} {
+		return &renderUser{
+			renderModel: renderModel{ID: 1, CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+			Name:        "eudore",
+			Email:       "eudore@example.com",
+			Salary:      1000,
+			Friends:     []renderFriend{{Name: "go", Phone: "123456"}},
+		}
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/user").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().
+		CheckBodyString(`{"createdAt":"2020-01-01T00:00:00Z","email":"***","friends":[{"name":"go","phone":"***"}],"id":1,"name":"eudore"}` + "\n").Out()
+	client.NewRequest("GET", "/user").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).WithHeaderValue("X-Role", "support").Do().
+		CheckBodyContainString(`"email":"eudore@example.com"`, `"phone":"***"`).Out()
+	client.NewRequest("GET", "/user").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).WithHeaderValue("X-Role", "user,admin").Do().
+		CheckBodyContainString(`"salary":1000`, `"phone":"123456"`).Out()
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
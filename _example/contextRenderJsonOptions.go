@@ -0,0 +1,53 @@
+package main
+
+/*
+eudore.NewRenderJSON使用选项创建json Renderer，eudore.NewRenderAccept根据Accept header选择注册的Renderer。
+
+RenderJSONConfig定义全局选项：
+Indent 缩进字符串
+PrettyQuery 请求参数名称，请求参数值为1或true时使用缩进格式化
+NoEscapeHTML 不转义html字符<>&
+EmptyArray 空切片输出[]代替null
+
+路由参数render使用逗号分隔的pretty、noescape、emptyarray选项按路由启用选项。
+*/
+
+import (
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+type renderPage struct {
+	Title string   `json:"title"`
+	Items []string `json:"items"`
+}
+
+func main() {
+	app := eudore.NewApp()
+	app.Renderer = eudore.NewRenderAccept(map[string]eudore.Renderer{
+		eudore.MimeApplicationJSON: eudore.NewRenderJSON(&eudore.RenderJSONConfig{PrettyQuery: "pretty"}),
+		eudore.MimeApplicationXML:  eudore.RenderXML,
+		eudore.MimeTextPlain:       eudore.RenderText,
+	})
+	page := &renderPage{Title: "<eudore>"}
+	app.GetFunc("/page", func(ctx eudore.Context) interface{} {
+		return page
+	})
+	app.GetFunc("/page/raw render=noescape,emptyarray", func(ctx eudore.Context) interface{} {
+		return page
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/page").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().
+		CheckBodyString(`{"title":"\u003ceudore\u003e","items":null}` + "\n")
+	client.NewRequest("GET", "/page?pretty=1").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().
+		CheckBodyString("{\n\t\"title\": \"\\u003ceudore\\u003e\",\n\t\"items\": null\n}\n").Out()
+	client.NewRequest("GET", "/page/raw").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().
+		CheckBodyString(`{"title":"<eudore>","items":[]}` + "\n").Out()
+	client.NewRequest("GET", "/page/raw").WithHeaderValue(eudore.HeaderAccept, "application/xml;q=0.9").Do().
+		CheckBodyContainString("<Title>&lt;eudore&gt;</Title>").Out()
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,30 @@
+package main
+
+/*
+eudore.NewRenderJSONP创建JSONP Renderer，用于兼容旧的浏览器跨域集成，需要显式启用。
+
+请求参数callback非空时输出javascript回调，回调函数名称需要是使用.分隔的javascript标识符，否则响应400。
+*/
+
+import (
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.Renderer = eudore.NewRenderJSONP(app.Renderer, "callback")
+	app.GetFunc("/user", func(ctx eudore.Context) interface{} {
+		return map[string]interface{}{"name": "eudore"}
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/user?callback=jQuery.cb_1").Do().CheckStatus(200).
+		CheckHeader(eudore.HeaderContentType, eudore.MimeTextJavascriptUtf8).CheckBodyString(`/**/jQuery.cb_1({"name":"eudore"});`).Out()
+	client.NewRequest("GET", "/user?callback=alert(1)").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(400).Out()
+	client.NewRequest("GET", "/user").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(200).Out()
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
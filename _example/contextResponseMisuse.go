@@ -0,0 +1,62 @@
+package main
+
+/*
+ResponseWriter在写入状态码后再次写入状态码、劫持连接后写入数据时忽略操作并输出Warning日志，日志caller字段为调用的方法；
+开发模式下日志stack字段为调用栈，用于定位错误写入响应的处理函数。
+
+劫持连接后Write和WriteString返回http.ErrHijacked，写入状态码后WriteInformational返回eudore.ErrResponseWriterHeaderWritten。
+*/
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.Set("mode", "dev")
+	app.GetFunc("/header", func(ctx eudore.Context) {
+		ctx.WriteHeader(201)
+		ctx.WriteString("created")
+		// 状态码已经写入，忽略并输出Warning日志。
+		ctx.WriteHeader(500)
+		ctx.Debug("status:", ctx.Response().Status())
+	})
+	app.GetFunc("/informational", func(ctx eudore.Context) {
+		ctx.WriteString("hello")
+		err := ctx.Response().(eudore.ResponseInformational).WriteInformational(103, http.Header{"Link": {"</style.css>; rel=preload; as=style"}})
+		if err != eudore.ErrResponseWriterHeaderWritten {
+			ctx.Error("write informational error:", err)
+		}
+	})
+	app.GetFunc("/hijack", func(ctx eudore.Context) {
+		conn, buf, err := ctx.Response().Hijack()
+		if err != nil {
+			ctx.Fatal(err)
+			return
+		}
+		buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: example\r\n\r\n")
+		buf.Flush()
+		conn.Close()
+
+		// 连接已经劫持，写入返回http.ErrHijacked。
+		err = ctx.WriteString("after hijack")
+		if err != http.ErrHijacked {
+			ctx.Error("write after hijack error:", err)
+		}
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/header").Do().CheckStatus(201).CheckBodyString("created")
+	client.NewRequest("GET", "/informational").Do().CheckStatus(200).CheckBodyString("hello")
+	client.NewRequest("GET", "/hijack").WithWebsocket(func(conn net.Conn) {
+		conn.Close()
+	}).Do().CheckStatus(101)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,79 @@
+package main
+
+/*
+ctx.TLS方法返回请求tls连接状态，非tls请求返回nil。
+
+tls.ConnectionState包含协商的Version、CipherSuite、ServerName(SNI)、NegotiatedProtocol(ALPN)和PeerCertificates，
+处理函数和中间件可以根据传输安全属性处理请求，例如拒绝低版本tls或读取客户端证书信息。
+*/
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	app := eudore.NewApp()
+	// 仅允许tls1.2及以上版本访问。
+	app.AddMiddleware(func(ctx eudore.Context) {
+		state := ctx.TLS()
+		if state == nil || state.Version < tls.VersionTLS12 {
+			ctx.WriteHeader(eudore.StatusForbidden)
+			ctx.Fatal("require tls1.2 or later")
+			ctx.End()
+		}
+	})
+	app.AnyFunc("/*", func(ctx eudore.Context) {
+		state := ctx.TLS()
+		var peers []string
+		for _, cert := range state.PeerCertificates {
+			peers = append(peers, cert.Subject.CommonName)
+		}
+		ctx.Render(map[string]interface{}{
+			"version":  fmt.Sprintf("%#04x", state.Version),
+			"cipher":   fmt.Sprintf("%#04x", state.CipherSuite),
+			"sni":      state.ServerName,
+			"alpn":     state.NegotiatedProtocol,
+			"peers":    peers,
+			"resumed":  state.DidResume,
+			"complete": state.HandshakeComplete,
+		})
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/").Do().CheckStatus(403)
+	client.NewRequest("GET", "/").WithTLS().Do().CheckStatus(200)
+
+	ln, err := (&eudore.ServerListenConfig{
+		Addr:  "127.0.0.1:8088",
+		HTTPS: true,
+	}).Listen()
+	if err != nil {
+		app.Error(err)
+		app.CancelFunc()
+	} else {
+		app.Serve(ln)
+		tp := &http.Transport{
+			TLSClientConfig: &tls.Config{
+				ServerName:         "localhost",
+				InsecureSkipVerify: true,
+			},
+		}
+		req, _ := http.NewRequest("GET", "https://127.0.0.1:8088/", nil)
+		req.Header.Set(eudore.HeaderAccept, eudore.MimeApplicationJSON)
+		resp, err := (&http.Client{Transport: tp}).Do(req)
+		if err == nil {
+			body, _ := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			fmt.Println(string(body))
+		}
+	}
+
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,45 @@
+package main
+
+/*
+ctx.WriteEarlyHints方法写入103 Early Hints响应，提示客户端在最终响应前预加载资源，需要go1.19及以上版本。
+ctx.SetTrailer方法设置响应trailer，可以在写入body后设置，例如gRPC-web的grpc-status，响应设置Content-Length时无法写入trailer。
+
+ResponseWriter实现可选接口eudore.ResponseInformational，使用类型断言调用WriteInformational方法写入1xx响应，header仅用于1xx响应不影响最终响应。
+*/
+
+import (
+	"net/http"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.GetFunc("/", func(ctx eudore.Context) {
+		ctx.WriteEarlyHints("</css/app.css>; rel=preload; as=style", "</js/app.js>; rel=preload; as=script")
+		ctx.SetHeader(eudore.HeaderContentType, eudore.MimeTextHTMLCharsetUtf8)
+		ctx.WriteString("<link rel=stylesheet href=/css/app.css><script src=/js/app.js></script>")
+	})
+	app.GetFunc("/grpc", func(ctx eudore.Context) {
+		ctx.SetHeader(eudore.HeaderTrailer, "Grpc-Status, Grpc-Message")
+		ctx.WriteString("data")
+		ctx.SetTrailer("Grpc-Status", "0")
+		ctx.SetTrailer("Grpc-Message", "OK")
+	})
+	app.GetFunc("/info", func(ctx eudore.Context) {
+		w := ctx.Response().(eudore.ResponseInformational)
+		w.WriteInformational(eudore.StatusProcessing, nil)
+		w.WriteInformational(eudore.StatusEarlyHints, http.Header{eudore.HeaderLink: {"</img/logo.png>; rel=preload; as=image"}})
+		ctx.WriteString("info")
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/").Do().CheckStatus(200).CheckHeader(eudore.HeaderLink, "")
+	client.NewRequest("GET", "/grpc").Do().CheckStatus(200).CheckHeader(http.TrailerPrefix+"Grpc-Status", "0").CheckBodyString("data")
+	client.NewRequest("GET", "/info").Do().CheckStatus(200).CheckBodyString("info")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
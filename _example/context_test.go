@@ -2,11 +2,16 @@ package eudore_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/eudore/eudore"
 	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
 	"net/http"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestContext2(t *testing.T) {
@@ -84,3 +89,212 @@ func TestReadWriteError2(t *testing.T) {
 	app.CancelFunc()
 	app.Run()
 }
+
+type fastUser struct {
+	ID   int
+	Name string
+}
+
+// AppendJSON 方法模拟代码生成的json编码。
+func (u fastUser) AppendJSON(b []byte) []byte {
+	b = append(b, `{"ID":`...)
+	b = strconv.AppendInt(b, int64(u.ID), 10)
+	b = append(b, `,"Name":`...)
+	b = eudore.AppendJSONString(b, u.Name)
+	return append(b, '}')
+}
+
+func TestContextWriteJSONFast2(t *testing.T) {
+	data := map[string]string{
+		"name":  "eudore",
+		"html":  "<a href=\"/?a=1&b=2\">link</a>",
+		"ctrl":  "line1\nline2\t\r\x00\x1f\\",
+		"utf8":  "\u4e2d\u6587 \u2028 \u2029 \xff",
+		"empty": "",
+	}
+	app := eudore.NewApp()
+	app.AnyFunc("/map", func(ctx eudore.Context) {
+		ctx.WriteJSON(data)
+	})
+	app.AnyFunc("/user", func(ctx eudore.Context) {
+		ctx.WriteJSON(fastUser{1, "eudore <fast>"})
+	})
+
+	client := httptest.NewClient(app)
+	want, _ := json.Marshal(data)
+	client.NewRequest("GET", "/map").Do().CheckStatus(200).CheckHeader(eudore.HeaderContentType, eudore.MimeApplicationJSONUtf8).CheckBodyString(string(want) + "\n")
+	want, _ = json.Marshal(struct {
+		ID   int
+		Name string
+	}{1, "eudore <fast>"})
+	client.NewRequest("GET", "/user").Do().CheckStatus(200).CheckBodyString(string(want) + "\n")
+
+	app.CancelFunc()
+	app.Run()
+}
+
+type discardResponse struct {
+	header http.Header
+}
+
+func (w *discardResponse) Header() http.Header               { return w.header }
+func (w *discardResponse) Write(b []byte) (int, error)       { return len(b), nil }
+func (w *discardResponse) WriteString(s string) (int, error) { return len(s), nil }
+func (w *discardResponse) WriteHeader(int)                   {}
+func (w *discardResponse) reset() {
+	for k := range w.header {
+		delete(w.header, k)
+	}
+}
+
+func benchmarkContextWrite(b *testing.B, fn eudore.HandlerFunc) {
+	app := eudore.NewApp()
+	app.AnyFunc("/", fn)
+	w := &discardResponse{header: make(http.Header)}
+	r, _ := http.NewRequest("GET", "/", nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.reset()
+		app.ServeHTTP(w, r)
+	}
+}
+
+func BenchmarkContextWriteBytes(b *testing.B) {
+	benchmarkContextWrite(b, func(ctx eudore.Context) {
+		ctx.Write([]byte("hello eudore"))
+	})
+}
+
+func BenchmarkContextWriteString(b *testing.B) {
+	benchmarkContextWrite(b, func(ctx eudore.Context) {
+		ctx.WriteString("hello eudore")
+	})
+}
+
+func BenchmarkContextWriteJSONMapString(b *testing.B) {
+	data := map[string]string{"name": "eudore", "version": "v1"}
+	benchmarkContextWrite(b, func(ctx eudore.Context) {
+		ctx.WriteJSON(data)
+	})
+}
+
+func BenchmarkContextWriteJSONMapInterface(b *testing.B) {
+	data := map[string]interface{}{"name": "eudore", "version": "v1"}
+	benchmarkContextWrite(b, func(ctx eudore.Context) {
+		ctx.WriteJSON(data)
+	})
+}
+
+func BenchmarkContextWriteJSONAppender(b *testing.B) {
+	data := fastUser{1, "eudore"}
+	benchmarkContextWrite(b, func(ctx eudore.Context) {
+		ctx.WriteJSON(data)
+	})
+}
+
+func BenchmarkContextWriteJSONStruct(b *testing.B) {
+	data := struct {
+		ID   int
+		Name string
+	}{1, "eudore"}
+	benchmarkContextWrite(b, func(ctx eudore.Context) {
+		ctx.WriteJSON(data)
+	})
+}
+
+type renderFilterAdmin struct {
+	Name   string `json:"name"`
+	Secret string `json:"secret" render:"admin"`
+}
+
+func TestRenderFilterInterface2(t *testing.T) {
+	var out interface{}
+	renderer := eudore.NewRenderFilter(func(ctx eudore.Context, data interface{}) error {
+		out = data
+		return nil
+	}, func(eudore.Context) []string { return []string{"user"} })
+
+	user := renderFilterAdmin{Name: "eudore", Secret: "secret"}
+	datas := []interface{}{
+		map[string]interface{}{"user": user},
+		map[string]interface{}{"users": []interface{}{&user}},
+		struct {
+			Data interface{} `json:"data"`
+		}{user},
+		[]interface{}{map[string]interface{}{"user": &user}},
+	}
+	wants := []string{
+		`{"user":{"name":"eudore"}}`,
+		`{"users":[{"name":"eudore"}]}`,
+		`{"data":{"name":"eudore"}}`,
+		`[{"user":{"name":"eudore"}}]`,
+	}
+	for i, data := range datas {
+		renderer(nil, data)
+		body, _ := json.Marshal(out)
+		if string(body) != wants[i] {
+			t.Errorf("render filter wrapped data %d: %s, want %s", i, body, wants[i])
+		}
+	}
+
+	// 不包含render tag的接口数据保持原样
+	renderer(nil, map[string]interface{}{"name": "eudore", "age": 1})
+	body, _ := json.Marshal(out)
+	if string(body) != `{"age":1,"name":"eudore"}` {
+		t.Errorf("render filter plain data: %s", body)
+	}
+}
+
+func TestBindJSONFieldErrors2(t *testing.T) {
+	type bindUser struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		Age   int    `json:"age"`
+		Admin bool   `json:"admin"`
+	}
+	app := eudore.NewApp()
+	app.AnyFunc("/*", func(ctx eudore.Context) {
+		var user bindUser
+		ctx.Fatal(ctx.Bind(&user))
+	})
+
+	client := httptest.NewClient(app)
+	resp := client.NewRequest("POST", "/").WithBodyJSON(map[string]interface{}{
+		"id": "1", "name": "eudore", "age": "18", "admin": 1,
+	}).Do()
+	body := resp.Body.String()
+	if resp.Code != eudore.StatusBadRequest || !strings.Contains(body, "'admin'") ||
+		!strings.Contains(body, "'age'") || !strings.Contains(body, "'id'") {
+		t.Errorf("bind json field errors response %d %s", resp.Code, body)
+	}
+
+	var user bindUser
+	err := eudore.BindJSON(nil, strings.NewReader(`{"id":"1","name":2,"age":18,"admin":"true"}`), &user)
+	errs, ok := err.(eudore.FieldErrors)
+	if !ok || len(errs) != 3 {
+		t.Fatalf("bind json not return all field errors: %v", err)
+	}
+	for i, field := range []string{"admin", "id", "name"} {
+		if errs[i].Field != field {
+			t.Errorf("bind json field error %d: %s, want %s", i, errs[i].Field, field)
+		}
+	}
+}
+
+func TestContextWriteStringWrapper2(t *testing.T) {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewIdempotencyFunc(time.Hour))
+	app.AnyFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteString("hello")
+	})
+
+	// 中间件包装的ResponseWriter嵌入bytes.Buffer，WriteString需要写入到响应。
+	client := httptest.NewClient(app)
+	for i := 0; i < 2; i++ {
+		resp := client.NewRequest("POST", "/").WithHeaderValue(eudore.HeaderIdempotencyKey, "k1").Do()
+		if body := resp.Body.String(); body != "hello" {
+			t.Errorf("write string through wrapper %d: %q", i, body)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+//go:build go1.18
+// +build go1.18
+
+package main
+
+/*
+go1.18以上版本可以使用泛型处理函数，绑定并校验请求后调用函数，然后渲染响应，类型在编译时检查，请求时不使用反射。
+
+HandlerJSON使用json渲染响应，HandlerRender使用ctx.Render根据Accept选择渲染器，HandlerBind只绑定校验请求。
+*/
+
+import (
+	"errors"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+type genericUserRequest struct {
+	Name string `json:"name" url:"name" validate:"nozero"`
+	Age  int    `json:"age" url:"age" validate:"min:0,max:150"`
+}
+
+type genericUserResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func main() {
+	app := eudore.NewApp()
+	app.PostFunc("/users", eudore.HandlerJSON(func(ctx eudore.Context, req genericUserRequest) (*genericUserResponse, error) {
+		if req.Name == "root" {
+			ctx.WriteHeader(eudore.StatusForbidden)
+			return nil, errors.New("user root is reserved")
+		}
+		return &genericUserResponse{ID: 1, Name: req.Name}, nil
+	}))
+	app.GetFunc("/users/search", eudore.HandlerRender(func(ctx eudore.Context, req genericUserRequest) ([]genericUserResponse, error) {
+		return []genericUserResponse{{ID: 1, Name: req.Name}}, nil
+	}))
+	app.PutFunc("/users/:id", eudore.HandlerBind(func(ctx eudore.Context, req map[string]interface{}) error {
+		ctx.WriteHeader(eudore.StatusNoContent)
+		return nil
+	}))
+
+	client := httptest.NewClient(app)
+	client.NewRequest("POST", "/users").WithBodyJSON(genericUserRequest{Name: "eudore", Age: 3}).Do().
+		CheckStatus(200).CheckHeader(eudore.HeaderContentType, eudore.MimeApplicationJSONUtf8).CheckBodyJSON(genericUserResponse{ID: 1, Name: "eudore"})
+	client.NewRequest("POST", "/users").WithBodyJSON(genericUserRequest{Age: 3}).Do().CheckStatus(400)
+	client.NewRequest("POST", "/users").WithBodyJSON(genericUserRequest{Name: "root"}).Do().CheckStatus(403)
+	client.NewRequest("GET", "/users/search?name=eudore").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().
+		CheckStatus(200).CheckBodyContainString(`"name":"eudore"`)
+	client.NewRequest("PUT", "/users/1").WithBodyJSON(map[string]interface{}{"name": "eudore"}).Do().CheckStatus(204)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
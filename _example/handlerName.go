@@ -0,0 +1,60 @@
+package main
+
+/*
+路由处理函数为字符串时，使用eudore.NewHandlerName按"name:arg"格式创建处理函数，
+路由可以从配置或数据库读取后注册，用于实现完全由配置驱动的网关。
+
+默认名称:
+static:dir         =>    静态文件目录
+proxy:url          =>    反向代理
+redirect:url       =>    302重定向
+status:code        =>    返回状态码
+
+eudore.RegisterHandlerName 注册自定义名称。
+*/
+
+import (
+	"fmt"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	eudore.RegisterHandlerName("text", func(arg string) (eudore.HandlerFunc, error) {
+		return func(ctx eudore.Context) {
+			ctx.WriteString(arg)
+		}, nil
+	})
+
+	backend := eudore.NewApp()
+	backend.AnyFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteString("backend " + ctx.Path())
+	})
+	backend.Listen(":8089")
+
+	// 模拟从配置读取的路由。
+	routes := map[string]string{
+		"/api/*":        "proxy:http://localhost:8089",
+		"/static/*path": "static:.",
+		"/old":          "redirect:/new",
+		"/new":          "text:new page",
+		"/health":       "status:204",
+	}
+	app := eudore.NewApp()
+	for path, handler := range routes {
+		app.AnyFunc(path, handler)
+	}
+	fmt.Println(app.AddHandler("ANY", "/err", "jwt:secret"))
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/api/v1").Do().CheckStatus(200).CheckBodyString("backend /api/v1")
+	client.NewRequest("GET", "/static/README.md").Do().CheckStatus(200)
+	client.NewRequest("GET", "/old").Do().CheckStatus(302).CheckHeader(eudore.HeaderLocation, "/new")
+	client.NewRequest("GET", "/new").Do().CheckStatus(200).CheckBodyString("new page")
+	client.NewRequest("GET", "/health").Do().CheckStatus(204)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
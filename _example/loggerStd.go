@@ -5,6 +5,7 @@ std 是否输出到os.Stdout标准输出。
 Path 输出到文件的路径，如果path为空，std会设置成true
 Level 日志输出级别，可以使用SetLevel来修改。
 TimeFormat 日志时间格式化格式
+FlushInterval 周期将缓冲的日志写入输出流，未使用app.Run周期Sync时设置，关闭Writer前调用Close停止。
 
 LoggerStd的配置，可以使用*LoggerStdConfig或者map类型。
 
@@ -13,6 +14,7 @@ type LoggerStdConfig struct {
 	Path       string      `alias:"path"`
 	Level      LoggerLevel `alias:"level"`
 	TimeFormat string      `alias:"timeformat"`
+	FlushInterval time.Duration `alias:"flushinterval"`
 }
 */
 
@@ -0,0 +1,48 @@
+package eudore_test
+
+import (
+	"bytes"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// loggerWriterFlush 记录Flush调用次数。
+type loggerWriterFlush struct {
+	bytes.Buffer
+	flushes int32
+}
+
+func (w *loggerWriterFlush) Sync() error {
+	return nil
+}
+
+func (w *loggerWriterFlush) Flush() error {
+	atomic.AddInt32(&w.flushes, 1)
+	return nil
+}
+
+func TestLoggerStdFlushClose(t *testing.T) {
+	w := &loggerWriterFlush{}
+	log := eudore.NewLoggerStd(&eudore.LoggerStdConfig{Writer: w, FlushInterval: time.Millisecond})
+	log.Info("flush")
+	time.Sleep(20 * time.Millisecond)
+	if atomic.LoadInt32(&w.flushes) == 0 {
+		t.Error("logger not flush")
+	}
+
+	closer, ok := log.(io.Closer)
+	if !ok {
+		t.Fatal("loggerStd not implement io.Closer")
+	}
+	closer.Close()
+	closer.Close()
+	n := atomic.LoadInt32(&w.flushes)
+	time.Sleep(20 * time.Millisecond)
+	if atomic.LoadInt32(&w.flushes) != n {
+		t.Errorf("logger flush after close: %d %d", n, w.flushes)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+/*
+LoggerStdConfig.Fingerprint为Error和Fatal级别日志添加fingerprint属性，用于日志聚合系统对相同错误分组和告警去重。
+
+fingerprint使用eudore.GetErrorFingerprint函数计算，为消息和栈顶函数名称的哈希，消息中的数字替换为0，忽略文件行号；
+日志存在stack属性时使用stack属性，例如Recover中间件记录的panic栈，否则使用调用日志的栈；已经设置fingerprint属性时不覆盖。
+*/
+
+import (
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp(eudore.NewLoggerStd(map[string]interface{}{
+		"std":         true,
+		"fingerprint": true,
+	}))
+	app.AddMiddleware(middleware.NewRecoverFunc())
+	app.GetFunc("/user/:id", func(ctx eudore.Context) {
+		// 两次请求的fingerprint相同
+		ctx.Errorf("user %s not found", ctx.GetParam("id"))
+	})
+	app.GetFunc("/panic/:id", func(ctx eudore.Context) {
+		panic("panic id " + ctx.GetParam("id"))
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/user/1").Do().CheckStatus(200)
+	client.NewRequest("GET", "/user/2").Do().CheckStatus(200)
+	client.NewRequest("GET", "/panic/1").Do().CheckStatus(500)
+	client.NewRequest("GET", "/panic/2").Do().CheckStatus(500)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
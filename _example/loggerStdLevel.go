@@ -0,0 +1,46 @@
+package main
+
+/*
+LoggerStdConfig.Levels指定级别日志的独立输出文件，每个文件使用独立的切割配置。
+
+Level 日志级别大于等于Level时写入Path。
+Exclusive 为true时匹配的日志不再写入默认输出。
+
+type LoggerStdLevelConfig struct {
+	Level     LoggerLevel `alias:"level"`
+	Path      string      `alias:"path"`
+	MaxSize   uint64      `alias:"maxsize"`
+	Link      string      `alias:"link"`
+	Exclusive bool        `alias:"exclusive"`
+}
+*/
+
+import (
+	"os"
+
+	"github.com/eudore/eudore"
+)
+
+func main() {
+	defer os.RemoveAll("logger")
+	app := eudore.NewApp(eudore.NewLoggerStd(&eudore.LoggerStdConfig{
+		Path: "logger/app.log",
+		Levels: []eudore.LoggerStdLevelConfig{
+			// Warning以上级别日志仅写入warning.log，app.log只保留Debug和Info日志。
+			{Level: eudore.LogWarning, Path: "logger/warning.log", Exclusive: true},
+			// Error和Fatal日志同时写入error文件，按1k切割。
+			{Level: eudore.LogError, Path: "logger/error-index.log", Link: "logger/error.log", MaxSize: 1 << 10},
+		},
+	}))
+
+	app.Debug("debug")
+	app.Info("info")
+	app.Warning("warning")
+	for i := 0; i < 20; i++ {
+		app.WithField("index", i).Error("error")
+	}
+	app.Sync()
+
+	app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,48 @@
+package main
+
+/*
+LoggerStdConfig.Rotate指定按时间切割的策略，与文件名称中的日期格式无关。
+
+Period 切割周期，可以为hourly、daily、weekly，为空时每小时检查文件名称中日期格式的变化。
+Offset 切割时间相对周期开始的偏移，例如daily周期使用4h在每天04:00切割。
+Location 切割时间和文件名称日期使用的时区名称，默认使用本地时区。
+
+文件名称包含日期格式时使用周期开始时间格式化名称，包含index时使用新的索引，
+否则将当前文件重命名为带周期开始时间的名称，例如app.log切割为app-2006-01-02.log。
+*/
+
+import (
+	"os"
+
+	"github.com/eudore/eudore"
+)
+
+func main() {
+	defer os.RemoveAll("logger")
+	app := eudore.NewApp(eudore.NewLoggerStd(map[string]interface{}{
+		"path": "logger/app.log",
+		"rotate": map[string]interface{}{
+			// 每天上海时区零点切割
+			"period":   "daily",
+			"location": "Asia/Shanghai",
+		},
+		"levels": []map[string]interface{}{
+			{
+				"level": eudore.LogError,
+				"path":  "logger/error-yyyy-MM-dd.log",
+				"rotate": map[string]interface{}{
+					// 每周一04:00切割
+					"period": "weekly",
+					"offset": "4h",
+				},
+			},
+		},
+	}))
+
+	app.Info("info")
+	app.Error("error")
+	app.Sync()
+
+	app.CancelFunc()
+	app.Run()
+}
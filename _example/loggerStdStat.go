@@ -0,0 +1,29 @@
+package main
+
+/*
+LoggerStd使用LoggerStat统计各级别日志条目数量、写入字节数、文件切割次数和写入失败次数，
+使用eudore.Get(app.Logger, "stat")获取统计，HandleHTTP方法返回统计用于监控系统采集，对Error日志数量突增告警。
+*/
+
+import (
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	app := eudore.NewApp(eudore.NewLoggerStd(nil))
+	stat := eudore.Get(app.Logger, "stat").(*eudore.LoggerStat)
+	app.GetFunc("/logger/stat", stat.HandleHTTP)
+	app.GetFunc("/err", func(ctx eudore.Context) {
+		ctx.Error("database error")
+		ctx.Warning("retry")
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/err").Do().CheckStatus(200)
+	client.NewRequest("GET", "/logger/stat").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(200).Out()
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
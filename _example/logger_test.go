@@ -1,10 +1,15 @@
 package eudore_test
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"errors"
+	"net/http/httptest"
 	"os"
 	"runtime"
+	"strconv"
+	"sync"
 	"testing"
 
 	"bou.ke/monkey"
@@ -287,3 +292,136 @@ func BenchmarkLogerStd(b *testing.B) {
 	log.Sync()
 	os.Remove("t2.log")
 }
+
+// loggerWriterBuffer 使用go test -race运行并发日志测试。
+type loggerWriterBuffer struct {
+	bytes.Buffer
+}
+
+func (w *loggerWriterBuffer) Sync() error {
+	return nil
+}
+
+func TestLoggerStdRaceFields(t *testing.T) {
+	w := &loggerWriterBuffer{}
+	log := eudore.NewLoggerStd(&eudore.LoggerStdConfig{Writer: w})
+	shared := log.WithField("app", "race").WithFields(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := strconv.Itoa(i)
+			for n := 0; n < 200; n++ {
+				shared.WithField("goroutine", id).WithField("index", n).Info(id)
+				shared.WithFields(eudore.Fields{"goroutine": id}).Warning(id)
+			}
+		}(i)
+	}
+	wg.Wait()
+	log.Sync()
+
+	scanner := bufio.NewScanner(&w.Buffer)
+	lines := 0
+	for scanner.Scan() {
+		lines++
+		var data struct {
+			Fields  map[string]interface{} `json:"fields"`
+			Message string                 `json:"message"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &data); err != nil {
+			t.Fatalf("invalid log line %s: %v", scanner.Text(), err)
+		}
+		if data.Fields["app"] != "race" || data.Fields["goroutine"] != data.Message {
+			t.Fatalf("interleaved log fields: %s", scanner.Text())
+		}
+	}
+	if lines != 16*200*2 {
+		t.Fatalf("log lines %d, want %d", lines, 16*200*2)
+	}
+}
+
+func TestLoggerStdRaceSetLevel(t *testing.T) {
+	log := eudore.NewLoggerStd(&eudore.LoggerStdConfig{Writer: &loggerWriterBuffer{}})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 200; n++ {
+				log.WithField("n", n).Debug("debug")
+				log.Info("info")
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for n := 0; n < 200; n++ {
+				log.SetLevel(eudore.LoggerLevel((i + n) % 4))
+			}
+		}(i)
+	}
+	wg.Wait()
+	log.Sync()
+}
+
+func TestLoggerStdRaceContext(t *testing.T) {
+	app := eudore.NewApp(eudore.NewLoggerStd(&eudore.LoggerStdConfig{Writer: &loggerWriterBuffer{}}))
+	var wg sync.WaitGroup
+	app.AnyFunc("/*", func(ctx eudore.Context) {
+		log := eudore.NewLogoutDetached(ctx, eudore.Fields{"path": ctx.Path()})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 10; n++ {
+				log.WithField("n", n).Info("detached")
+			}
+		}()
+		ctx.WithField("path", ctx.Path()).Info("request")
+	})
+	var reqs sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		reqs.Add(1)
+		go func(i int) {
+			defer reqs.Done()
+			for n := 0; n < 20; n++ {
+				app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/"+strconv.Itoa(i), nil))
+			}
+		}(i)
+	}
+	reqs.Wait()
+	wg.Wait()
+	app.CancelFunc()
+}
+
+func TestLoggerStdFingerprintFields(t *testing.T) {
+	w := &loggerWriterBuffer{}
+	log := eudore.NewLoggerStd(&eudore.LoggerStdConfig{Writer: w, Fingerprint: true})
+	for i := 0; i < 2; i++ {
+		log.Errorf("user %d not found", i+100)
+	}
+	log.Errorf("user %d deleted", 1)
+	log.WithField("stack", []string{"main.go:10 main.handler"}).Error("panic")
+	log.WithField("stack", []string{"main.go:20 main.handler"}).Error("panic")
+	log.WithField("fingerprint", "custom").Error("custom")
+	log.Warning("warning")
+	log.Sync()
+
+	var prints []string
+	scanner := bufio.NewScanner(&w.Buffer)
+	for scanner.Scan() {
+		var data struct {
+			Fields map[string]interface{} `json:"fields"`
+		}
+		json.Unmarshal(scanner.Bytes(), &data)
+		print, _ := data.Fields["fingerprint"].(string)
+		prints = append(prints, print)
+	}
+	if len(prints) != 7 || prints[0] != prints[1] || prints[0] == prints[2] || prints[3] != prints[4] ||
+		prints[5] != "custom" || prints[6] != "" {
+		t.Fatalf("invalid fingerprints %v", prints)
+	}
+	if eudore.GetErrorFingerprint("a", nil) == eudore.GetErrorFingerprint("b", nil) {
+		t.Fatal("same fingerprint for different message")
+	}
+}
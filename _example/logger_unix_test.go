@@ -0,0 +1,46 @@
+//go:build !windows
+// +build !windows
+
+package eudore_test
+
+import (
+	"os"
+	fpath "path/filepath"
+	"testing"
+
+	"github.com/eudore/eudore"
+)
+
+func TestLoggerStdPathUnix(t *testing.T) {
+	defer os.RemoveAll("logger-unix")
+	log := eudore.NewLoggerStd(&eudore.LoggerStdConfig{
+		Path: "logger-unix/2006dir/app-yyyy-MM-dd.log",
+		Link: "logger-unix/link/app.log",
+	})
+	log.Info("hello")
+	log.Sync()
+
+	stat, err := os.Stat("logger-unix/2006dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Mode().Perm()&0700 != 0700 {
+		t.Fatalf("log dir mode %s, want 0755", stat.Mode().Perm())
+	}
+	files, _ := fpath.Glob("logger-unix/2006dir/app-*.log")
+	if len(files) != 1 {
+		t.Fatalf("log files %v", files)
+	}
+
+	link, err := os.Lstat("logger-unix/link/app.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("link %s is not symlink", link.Mode())
+	}
+	target, _ := os.Readlink("logger-unix/link/app.log")
+	if !fpath.IsAbs(target) || fpath.Base(target) != fpath.Base(files[0]) {
+		t.Fatalf("link target %s, want %s", target, files[0])
+	}
+}
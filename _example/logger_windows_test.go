@@ -0,0 +1,38 @@
+//go:build windows
+// +build windows
+
+package eudore_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eudore/eudore"
+)
+
+func TestLoggerStdPathWindows(t *testing.T) {
+	defer os.RemoveAll("logger-windows")
+	// 路径使用/分隔符，创建文件时转换为\。
+	log := eudore.NewLoggerStd(&eudore.LoggerStdConfig{
+		Path: "logger-windows/2006dir/app-yyyy-MM-dd.log",
+		Link: "logger-windows/link/app.log",
+	})
+	log.Info("hello")
+	log.Sync()
+
+	files, _ := filepath.Glob(`logger-windows\2006dir\app-*.log`)
+	if len(files) != 1 {
+		t.Fatalf("log files %v", files)
+	}
+
+	// 没有创建软连接权限时使用硬链接，硬链接也失败时跳过。
+	if _, err := os.Lstat(`logger-windows\link\app.log`); err == nil {
+		body, _ := ioutil.ReadFile(`logger-windows\link\app.log`)
+		if !strings.Contains(string(body), "hello") {
+			t.Fatalf("link content %s", body)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+/*
+注册路由时和处理函数一起传入eudore.RouterDoc记录路由文档，RouterDoc不会创建处理函数；
+middleware.NewAPIDocFunc使用RouterStd.Routes读取路由文档生成html页面，注册到任意路径访问，Accept为json时返回分组数据。
+
+type RouterDoc struct {
+	Summary     string      `json:"summary,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Tags        []string    `json:"tags,omitempty"`
+	Request     interface{} `json:"request,omitempty"`
+	Response    interface{} `json:"response,omitempty"`
+}
+*/
+
+import (
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+type apidocUser struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func main() {
+	app := eudore.NewApp()
+	app.GetFunc("/docs", middleware.NewAPIDocFunc(app.Router))
+
+	api := app.Group("/api/v1")
+	api.GetFunc("/users", eudore.RouterDoc{
+		Summary:  "用户列表",
+		Tags:     []string{"user"},
+		Response: []apidocUser{{1, "eudore", "eudore@example.com"}},
+	}, func(ctx eudore.Context) interface{} {
+		return []apidocUser{{1, "eudore", "eudore@example.com"}}
+	})
+	api.PostFunc("/users", &eudore.RouterDoc{
+		Summary:     "创建用户",
+		Description: "name和email为必填属性，创建成功返回201。",
+		Tags:        []string{"user", "admin"},
+		Request:     apidocUser{Name: "eudore", Email: "eudore@example.com"},
+		Response:    `{"id":2,"name":"eudore","email":"eudore@example.com"}`,
+	}, func(ctx eudore.Context) {
+		ctx.WriteHeader(eudore.StatusCreated)
+	})
+	api.GetFunc("/health", eudore.RouterDoc{Summary: "健康检查", Response: "ok"}, func(ctx eudore.Context) {
+		ctx.WriteString("ok")
+	})
+	// 没有文档的路由不显示。
+	api.GetFunc("/internal", func(ctx eudore.Context) {
+		ctx.WriteString("internal")
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/api/v1/users").Do().CheckStatus(200)
+	client.NewRequest("POST", "/api/v1/users").Do().CheckStatus(201)
+	client.NewRequest("GET", "/docs").Do().CheckStatus(200).CheckHeader(eudore.HeaderContentType, eudore.MimeTextHTMLCharsetUtf8).
+		CheckBodyContainString(`<h2 id="user">user</h2>`, "/api/v1/users", "创建用户", "&#34;email&#34;: &#34;eudore@example.com&#34;", `<h2 id="default">default</h2>`)
+	client.NewRequest("GET", "/docs").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(200).
+		CheckBodyContainString(`"tag":"admin"`, `"summary":"健康检查"`).Out()
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,75 @@
+package main
+
+/*
+apikey组件实现API Key和客户端证书凭证管理，Storage可以使用内存、json文件或数据库保存，仅保存密钥的sha256哈希；
+middleware.NewApiKeyFunc使用Store校验X-Api-Key Header，路由参数scope设置需要的授权范围。
+
+Store方法:
+Create(ctx, name, scopes, ttl)                  创建API Key，返回只显示一次的明文密钥
+AddCertificate(ctx, name, cert, scopes, ttl)    使用客户端证书sha256指纹注册凭证，请求没有API Key时使用TLS客户端证书认证
+Rotate(ctx, id, grace)                          轮换密钥，旧密钥在grace时间后过期
+Revoke(ctx, id)                                 吊销凭证
+*/
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/apikey"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	defer os.RemoveAll("apikey")
+	storage, err := apikey.NewStorageFile("apikey/keys.json")
+	if err != nil {
+		panic(err)
+	}
+	store := apikey.NewStore(storage)
+	store.Prefix = "sk_"
+	ctx := context.Background()
+	reader, _, _ := store.Create(ctx, "order-reader", []string{"orders:read"}, time.Hour)
+	admin, _, _ := store.Create(ctx, "admin", []string{"*"}, 0)
+	old, oldkey, _ := store.Create(ctx, "report", []string{"orders:read"}, 0)
+	rotated, _, _ := store.Rotate(ctx, oldkey.ID, time.Minute)
+	revoked, revokedkey, _ := store.Create(ctx, "revoked", []string{"*"}, 0)
+	store.Revoke(ctx, revokedkey.ID)
+	expired, _, _ := store.Create(ctx, "expired", []string{"*"}, time.Millisecond)
+	time.Sleep(time.Millisecond * 2)
+
+	app := eudore.NewApp()
+	api := app.Group("/api")
+	api.AddMiddleware(middleware.NewApiKeyFunc(store))
+	api.GetFunc("/orders scope=orders:read", func(ctx eudore.Context) {
+		ctx.WriteString("orders for " + ctx.GetParam("apikey"))
+	})
+	api.DeleteFunc("/orders/:id scope=orders:read,orders:write", func(ctx eudore.Context) {
+		ctx.WriteString("delete order " + ctx.GetParam("id"))
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/api/orders").Do().CheckStatus(401).CheckHeader(eudore.HeaderWWWAuthenticate, "ApiKey")
+	client.NewRequest("GET", "/api/orders").WithHeaderValue(middleware.HeaderXApiKey, reader).Do().CheckStatus(200).CheckBodyString("orders for order-reader")
+	client.NewRequest("DELETE", "/api/orders/1").WithHeaderValue(middleware.HeaderXApiKey, reader).Do().CheckStatus(403)
+	client.NewRequest("DELETE", "/api/orders/1").WithHeaderValue(middleware.HeaderXApiKey, admin).Do().CheckStatus(200)
+	// 轮换后新旧密钥在宽限期内都有效。
+	client.NewRequest("GET", "/api/orders").WithHeaderValue(middleware.HeaderXApiKey, old).Do().CheckStatus(200)
+	client.NewRequest("GET", "/api/orders").WithHeaderValue(middleware.HeaderXApiKey, rotated).Do().CheckStatus(200)
+	client.NewRequest("GET", "/api/orders").WithHeaderValue(middleware.HeaderXApiKey, revoked).Do().CheckStatus(401).CheckBodyContainString("revoked")
+	client.NewRequest("GET", "/api/orders").WithHeaderValue(middleware.HeaderXApiKey, expired).Do().CheckStatus(401).CheckBodyContainString("expired")
+	client.NewRequest("GET", "/api/orders").WithHeaderValue(middleware.HeaderXApiKey, reader+"x").Do().CheckStatus(401).CheckBodyContainString("invalid")
+
+	// 重新加载文件存储。
+	storage, _ = apikey.NewStorageFile("apikey/keys.json")
+	keys, _ := storage.List(ctx)
+	for _, key := range keys {
+		app.Infof("apikey %s %s scopes: %v revoked: %v rotated: %s", key.ID, key.Name, key.Scopes, key.Revoked, key.RotatedTo)
+	}
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,45 @@
+package main
+
+/*
+Bandwidth使用令牌桶限制响应写入速度，单位字节每秒，保护下载类接口的出口带宽。
+
+默认按连接限制，可以使用key函数按ip或用户限制；路由参数bandwidth可以设置组路由或路由的速度，bandwidth=0表示不限制。
+*/
+
+import (
+	"strings"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewLoggerFunc(app, "route"))
+	app.AddMiddleware(middleware.NewBandwidthFunc(10000, app.Context, func(ctx eudore.Context) string {
+		return ctx.RealIP()
+	}))
+	app.GetFunc("/download", func(ctx eudore.Context) {
+		ctx.WriteString(strings.Repeat("a", 3000))
+	})
+	app.GetFunc("/api bandwidth=0", func(ctx eudore.Context) {
+		ctx.WriteString(strings.Repeat("a", 3000))
+	})
+	files := app.Group("/files bandwidth=2000")
+	files.GetFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteString(strings.Repeat("a", 600))
+	})
+
+	client := httptest.NewClient(app)
+	for _, path := range []string{"/download", "/api", "/files/a.txt"} {
+		now := time.Now()
+		client.NewRequest("GET", path).Do().CheckStatus(200)
+		app.Info(path, "duration", time.Since(now).Truncate(10*time.Millisecond))
+	}
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,49 @@
+package main
+
+/*
+Buffer缓冲不超过指定大小的响应，处理结束后设置Content-Length一次写入。
+
+缓冲期间可以修改状态码，写入大于等于400的状态码时丢弃已经缓冲的body，使后续错误可以返回完整的错误响应；
+超过缓冲大小或调用Flush后切换为流式写入，路由参数buffer可以设置组路由或路由的缓冲大小，buffer=0表示不缓冲。
+*/
+
+import (
+	"strings"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewLoggerFunc(app, "route"))
+	app.AddMiddleware(middleware.NewBufferFunc(4096))
+	app.GetFunc("/list", func(ctx eudore.Context) {
+		ctx.WriteString("item1\n")
+		ctx.WriteString("item2\n")
+		// 写入部分数据后出现错误，缓冲的数据被丢弃。
+		if ctx.GetQuery("err") != "" {
+			ctx.WriteHeader(eudore.StatusInternalServerError)
+			ctx.WriteString("query item3 error")
+		}
+	})
+	app.GetFunc("/large", func(ctx eudore.Context) {
+		ctx.WriteString(strings.Repeat("a", 5000))
+	})
+	api := app.Group("/stream buffer=0")
+	api.GetFunc("/events", func(ctx eudore.Context) {
+		ctx.WriteString("data: 1\n\n")
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/list").Do().CheckStatus(200).CheckHeader(eudore.HeaderContentLength, "12").CheckBodyString("item1\nitem2\n")
+	client.NewRequest("GET", "/list?err=1").Do().CheckStatus(500).CheckBodyString("query item3 error")
+	// 超过缓冲大小后流式写入，不设置Content-Length。
+	client.NewRequest("GET", "/large").Do().CheckStatus(200).CheckHeader(eudore.HeaderContentLength, "")
+	client.NewRequest("GET", "/stream/events").Do().CheckStatus(200).CheckHeader(eudore.HeaderContentLength, "").CheckBodyString("data: 1\n\n")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,70 @@
+package main
+
+/*
+NewCacheFunc缓存GET请求的200响应，按响应Vary Header列出的请求Header区分缓存，响应设置X-Cache Header为HIT或MISS。
+
+缓存时间优先使用响应Cache-Control的s-maxage和max-age，no-store、no-cache、private和设置Set-Cookie的响应不缓存，
+没有Cache-Control缓存时间的响应默认不缓存，请求携带Authorization或Cookie时只缓存public或s-maxage的响应。
+
+eudore.NewCacheLRU创建按字节数限制的LRU内存缓存，分片加锁，Stats方法返回命中、未命中和淘汰次数，用于没有Redis的单实例部署。
+*/
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	lru := eudore.NewCacheLRU(64<<10, 4)
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewCacheFunc(lru, 10*time.Second))
+	var count int
+	app.GetFunc("/hello", func(ctx eudore.Context) {
+		count++
+		ctx.SetHeader(eudore.HeaderCacheControl, "public, max-age=60")
+		ctx.SetHeader(eudore.HeaderVary, eudore.HeaderAcceptLanguage)
+		if strings.HasPrefix(ctx.GetHeader(eudore.HeaderAcceptLanguage), "zh") {
+			ctx.WriteString("你好")
+			return
+		}
+		ctx.WriteString("hello")
+	})
+	app.GetFunc("/user", func(ctx eudore.Context) {
+		ctx.SetHeader(eudore.HeaderCacheControl, "private")
+		ctx.WriteString("user")
+	})
+	app.GetFunc("/large/:id", func(ctx eudore.Context) {
+		ctx.WriteString(strings.Repeat(ctx.GetParam("id"), 4<<10))
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/hello").Do().CheckStatus(200).CheckHeader(middleware.HeaderXCache, "MISS").CheckBodyString("hello")
+	client.NewRequest("GET", "/hello").Do().CheckStatus(200).CheckHeader(middleware.HeaderXCache, "HIT").CheckBodyString("hello")
+	client.NewRequest("GET", "/hello").WithHeaderValue(eudore.HeaderAcceptLanguage, "zh-CN").Do().CheckHeader(middleware.HeaderXCache, "MISS").CheckBodyString("你好")
+	client.NewRequest("GET", "/hello").WithHeaderValue(eudore.HeaderAcceptLanguage, "zh-CN").Do().CheckHeader(middleware.HeaderXCache, "HIT").CheckBodyString("你好")
+	client.NewRequest("GET", "/hello").WithHeaderValue(eudore.HeaderCacheControl, "no-cache").Do().CheckHeader(middleware.HeaderXCache, "MISS")
+	client.NewRequest("GET", "/user").Do().CheckStatus(200)
+	client.NewRequest("GET", "/user").Do().CheckStatus(200).CheckHeader(middleware.HeaderXCache, "MISS")
+	if count != 3 {
+		panic(fmt.Sprintf("hello handler count %d", count))
+	}
+
+	// 超出分片容量后淘汰最久未使用的响应
+	for i := 0; i < 40; i++ {
+		client.NewRequest("GET", fmt.Sprintf("/large/%d", i%10)).Do().CheckStatus(200)
+	}
+	stats := lru.Stats()
+	fmt.Printf("lru stats: %#v\n", stats)
+	if stats.Evictions == 0 || stats.Bytes > 64<<10 {
+		panic("lru not evict")
+	}
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,87 @@
+package eudore_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func TestMiddlewareCacheDefaultTTL(t *testing.T) {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewCacheFunc(eudore.NewCacheLRU(64<<10, 4)))
+	app.GetFunc("/none", func(ctx eudore.Context) {
+		ctx.WriteString("none")
+	})
+	app.GetFunc("/maxage", func(ctx eudore.Context) {
+		ctx.SetHeader(eudore.HeaderCacheControl, "max-age=60")
+		ctx.WriteString("maxage")
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/none").Do()
+	if cache := client.NewRequest("GET", "/none").Do().HeaderMap.Get(middleware.HeaderXCache); cache != "MISS" {
+		t.Errorf("response without Cache-Control cached by default: %s", cache)
+	}
+	client.NewRequest("GET", "/maxage").Do()
+	if cache := client.NewRequest("GET", "/maxage").Do().HeaderMap.Get(middleware.HeaderXCache); cache != "HIT" {
+		t.Errorf("response with max-age not cached: %s", cache)
+	}
+
+	app = eudore.NewApp()
+	app.AddMiddleware(middleware.NewCacheFunc(eudore.NewCacheLRU(64<<10, 4), time.Minute))
+	app.GetFunc("/none", func(ctx eudore.Context) {
+		ctx.WriteString("none")
+	})
+	client = httptest.NewClient(app)
+	client.NewRequest("GET", "/none").Do()
+	if cache := client.NewRequest("GET", "/none").Do().HeaderMap.Get(middleware.HeaderXCache); cache != "HIT" {
+		t.Errorf("response without Cache-Control not cached with ttl option: %s", cache)
+	}
+}
+
+func TestMiddlewareCacheCredential(t *testing.T) {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewCacheFunc(eudore.NewCacheLRU(64<<10, 4), time.Minute))
+	app.GetFunc("/user", func(ctx eudore.Context) {
+		ctx.SetHeader(eudore.HeaderCacheControl, "max-age=60")
+		ctx.WriteString("user " + ctx.GetHeader(eudore.HeaderAuthorization) + ctx.GetHeader(eudore.HeaderCookie))
+	})
+	app.GetFunc("/public", func(ctx eudore.Context) {
+		ctx.SetHeader(eudore.HeaderCacheControl, "public, max-age=60")
+		ctx.WriteString("public")
+	})
+	app.GetFunc("/smaxage", func(ctx eudore.Context) {
+		ctx.SetHeader(eudore.HeaderCacheControl, "s-maxage=60")
+		ctx.WriteString("smaxage")
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/user").WithHeaderValue(eudore.HeaderAuthorization, "Bearer alice").Do()
+	resp := client.NewRequest("GET", "/user").WithHeaderValue(eudore.HeaderAuthorization, "Bearer bob").Do()
+	if body := resp.Body.String(); body != "user Bearer bob" {
+		t.Errorf("authorization response shared: %s", body)
+	}
+	client.NewRequest("GET", "/user").WithHeaderValue(eudore.HeaderCookie, "sid=alice").Do()
+	resp = client.NewRequest("GET", "/user").WithHeaderValue(eudore.HeaderCookie, "sid=bob").Do()
+	if body := resp.Body.String(); body != "user sid=bob" {
+		t.Errorf("cookie response shared: %s", body)
+	}
+
+	// 匿名请求缓存的非public响应不返回给携带凭据的请求
+	client.NewRequest("GET", "/user").Do()
+	resp = client.NewRequest("GET", "/user").WithHeaderValue(eudore.HeaderAuthorization, "Bearer bob").Do()
+	if cache := resp.HeaderMap.Get(middleware.HeaderXCache); cache != "MISS" {
+		t.Errorf("anonymous response returned to authorization request: %s", cache)
+	}
+
+	for _, path := range []string{"/public", "/smaxage"} {
+		client.NewRequest("GET", path).WithHeaderValue(eudore.HeaderAuthorization, "Bearer alice").Do()
+		resp = client.NewRequest("GET", path).WithHeaderValue(eudore.HeaderAuthorization, "Bearer bob").Do()
+		if cache := resp.HeaderMap.Get(middleware.HeaderXCache); cache != "HIT" {
+			t.Errorf("shared response %s not cached: %s", path, cache)
+		}
+	}
+}
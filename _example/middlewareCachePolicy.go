@@ -0,0 +1,71 @@
+package main
+
+/*
+CachePolicy声明响应缓存策略，统一设置Cache-Control、Expires和Vary Header。
+
+NewCachePolicyFunc使用路由参数cache选择策略，没有cache参数时使用名称为空字符串的策略；
+CachePolicy.HandleHTTP可以单独给路由或路由组添加策略。
+
+策略开启ETag时使用NewETagFunc计算GET响应的弱ETag，If-None-Match匹配时返回304，
+错误响应和处理函数设置了Cache-Control时不使用策略。
+*/
+
+import (
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewCachePolicyFunc(map[string]*middleware.CachePolicy{
+		"":       {NoStore: true},
+		"static": {Public: true, MaxAge: time.Hour, SMaxAge: 24 * time.Hour, Immutable: true},
+		"page": {Public: true, MaxAge: time.Minute, StaleWhileRevalidate: 10 * time.Minute,
+			Vary: []string{eudore.HeaderAcceptLanguage}, ETag: true},
+		"user": {Private: true, NoCache: true, ETag: true},
+	}))
+	app.GetFunc("/static/* cache=static", func(ctx eudore.Context) {
+		ctx.WriteString("static file " + ctx.GetParam("*"))
+	})
+	app.GetFunc("/page cache=page", func(ctx eudore.Context) {
+		ctx.WriteString("page content")
+	})
+	app.GetFunc("/user cache=user", func(ctx eudore.Context) {
+		ctx.WriteString("user " + ctx.GetQuery("name"))
+	})
+	app.GetFunc("/error cache=static", func(ctx eudore.Context) {
+		ctx.Fatal("server error")
+	})
+	app.GetFunc("/custom cache=static", func(ctx eudore.Context) {
+		ctx.SetHeader(eudore.HeaderCacheControl, "max-age=5")
+		ctx.WriteString("custom")
+	})
+	app.GetFunc("/api", func(ctx eudore.Context) {
+		ctx.WriteString("api")
+	})
+	// 单独给路由添加策略
+	app.GetFunc("/feed", (&middleware.CachePolicy{Public: true, MaxAge: 5 * time.Minute}).HandleHTTP, func(ctx eudore.Context) {
+		ctx.WriteString("feed")
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/static/app.js").Do().CheckStatus(200).
+		CheckHeader(eudore.HeaderCacheControl, "public, max-age=3600, s-maxage=86400, immutable")
+	resp := client.NewRequest("GET", "/page").Do().CheckStatus(200).
+		CheckHeader(eudore.HeaderCacheControl, "public, max-age=60, stale-while-revalidate=600", eudore.HeaderVary, eudore.HeaderAcceptLanguage)
+	etag := resp.Header().Get(eudore.HeaderETag)
+	client.NewRequest("GET", "/page").WithHeaderValue(eudore.HeaderIfNoneMatch, etag).Do().CheckStatus(304).
+		CheckHeader(eudore.HeaderETag, etag, eudore.HeaderCacheControl, "public, max-age=60, stale-while-revalidate=600").CheckBodyString("")
+	client.NewRequest("GET", "/user?name=eudore").Do().CheckStatus(200).CheckHeader(eudore.HeaderCacheControl, "private, no-cache", eudore.HeaderExpires, "0")
+	client.NewRequest("GET", "/error").Do().CheckStatus(500).CheckHeader(eudore.HeaderCacheControl, "")
+	client.NewRequest("GET", "/custom").Do().CheckStatus(200).CheckHeader(eudore.HeaderCacheControl, "max-age=5")
+	client.NewRequest("GET", "/api").Do().CheckStatus(200).CheckHeader(eudore.HeaderCacheControl, "no-store")
+	client.NewRequest("GET", "/feed").Do().CheckStatus(200).CheckHeader(eudore.HeaderCacheControl, "public, max-age=300")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,51 @@
+package main
+
+/*
+Canary按权重、Header或Cookie粘性将请求分配给stable或canary处理函数，并统计每个版本的请求数、错误数和耗时。
+
+router参数注入GET /canary查看指标，PUT /canary/weight/:weight运行时修改canary权重。
+*/
+
+import (
+	"fmt"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	canary := middleware.NewCanary(20, nil, eudore.HandlerFuncs{func(ctx eudore.Context) {
+		ctx.WriteString("v2")
+	}})
+	app.AddMiddleware(middleware.NewLoggerFunc(app, "canary"))
+	app.AddMiddleware("/api", canary.NewCanaryFunc(app.Group("/eudore/debug")))
+	app.GetFunc("/api/*", func(ctx eudore.Context) {
+		ctx.WriteString("v1")
+	})
+
+	// 没有cookie的客户端按权重随机
+	count := map[string]int{}
+	for i := 0; i < 100; i++ {
+		body := httptest.NewClient(app).NewRequest("GET", "/api/user").Do().Body.String()
+		count[body]++
+	}
+	fmt.Println(count)
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/api/user").WithHeaderValue("X-Canary", "canary").Do().CheckBodyString("v2")
+	client.NewRequest("GET", "/api/user").WithHeaderValue("X-Canary", "stable").Do().CheckBodyString("v1")
+	// cookie粘性
+	body := client.NewRequest("GET", "/api/user").Do().Body.String()
+	client.NewRequest("GET", "/api/user").Do().CheckBodyString(body)
+	client.NewRequest("GET", "/api/user").Do().CheckBodyString(body)
+
+	client.NewRequest("PUT", "/eudore/debug/canary/weight/100").Do().CheckStatus(200)
+	httptest.NewClient(app).NewRequest("GET", "/api/user").Do().CheckBodyString("v2")
+	client.NewRequest("GET", "/eudore/debug/canary").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().Out()
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
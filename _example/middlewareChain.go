@@ -0,0 +1,69 @@
+package main
+
+/*
+middleware.NewChainFuncs 使用配置声明中间件链，按名称从注册的构造函数创建中间件，
+运维调整中间件顺序和开关时修改配置即可，不需要重新编译。
+
+声明格式:
+"recover"                                    =>    名称
+"gzip:{level:5}"                             =>    名称和json配置，key可以不加引号
+{"name": "rate", "speed": 10, "max": 20}     =>    包含name属性的对象
+{"name": "timing", "disable": true}          =>    disable为true时跳过
+
+middleware.RegisterChain 注册自定义中间件构造函数，例如jwt。
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	middleware.RegisterChain("version", func(_ *eudore.App, options map[string]interface{}) (eudore.HandlerFunc, error) {
+		version := eudore.GetString(options["version"])
+		if version == "" {
+			return nil, fmt.Errorf("version is empty")
+		}
+		return func(ctx eudore.Context) {
+			ctx.SetHeader("X-Version", version)
+		}, nil
+	})
+
+	// 模拟从json配置文件读取的中间件声明。
+	var conf interface{}
+	json.Unmarshal([]byte(`[
+		"recover",
+		"requestid",
+		"gzip:{level:5}",
+		"version:{\"version\":\"v1.2\"}",
+		{"name": "basicauth", "names": {"eudore": "hello"}},
+		{"name": "timing", "disable": true}
+	]`), &conf)
+
+	app := eudore.NewApp()
+	app.Set("middleware", conf)
+	hs, err := middleware.NewChainFuncs(app, app.Get("middleware"))
+	if err != nil {
+		panic(err)
+	}
+	app.AddMiddleware(hs)
+	app.GetFunc("/*", eudore.HandlerEmpty)
+
+	// 全部声明的错误合并返回。
+	_, err = middleware.NewChainFuncs(app, []string{"jwt", "gzip:{level", "version"})
+	fmt.Println(err)
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/1").Do().CheckStatus(401)
+	client.NewRequest("GET", "/1").WithHeaderValue(eudore.HeaderAuthorization, "Basic ZXVkb3JlOmhlbGxv").
+		WithHeaderValue(eudore.HeaderAcceptEncoding, "gzip").Do().
+		CheckStatus(200).CheckHeader("X-Version", "v1.2", eudore.HeaderContentEncoding, "gzip")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
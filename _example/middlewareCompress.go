@@ -0,0 +1,53 @@
+package main
+
+/*
+Compress根据Accept-Encoding的q值选择压缩编码，q值相同时按优先顺序选择，每种编码使用sync.Pool复用编码器。
+
+默认支持gzip和deflate，zstd和br可以使用第三方库添加，例如：
+
+	middleware.NewCompressFunc(map[string]middleware.CompressFunc{
+		"zstd": func(level int) middleware.Compressor {
+			w, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+			return w
+		},
+		"br": func(level int) middleware.Compressor {
+			return brotli.NewWriterLevel(nil, level)
+		},
+	}, map[string]int{"zstd": 3, "br": 4, "gzip": 6})
+*/
+
+import (
+	"strings"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewLoggerFunc(app, "route"))
+	app.AddMiddleware(middleware.NewCompressFunc(map[string]int{"gzip": 6, "deflate": 9}))
+	app.GetFunc("/data", func(ctx eudore.Context) {
+		ctx.WriteString(strings.Repeat("eudore ", 100))
+	})
+	app.GetFunc("/empty", func(ctx eudore.Context) {
+		ctx.WriteHeader(eudore.StatusNoContent)
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/data").WithHeaderValue(eudore.HeaderAcceptEncoding, "gzip, deflate, br").Do().
+		CheckStatus(200).CheckHeader(eudore.HeaderContentEncoding, "gzip", eudore.HeaderVary, eudore.HeaderAcceptEncoding)
+	client.NewRequest("GET", "/data").WithHeaderValue(eudore.HeaderAcceptEncoding, "br;q=1.0, gzip;q=0.5, deflate;q=0.8").Do().
+		CheckStatus(200).CheckHeader(eudore.HeaderContentEncoding, "deflate")
+	client.NewRequest("GET", "/data").WithHeaderValue(eudore.HeaderAcceptEncoding, "*;q=0.1, gzip;q=0").Do().
+		CheckStatus(200).CheckHeader(eudore.HeaderContentEncoding, "deflate")
+	client.NewRequest("GET", "/data").WithHeaderValue(eudore.HeaderAcceptEncoding, "identity").Do().
+		CheckStatus(200).CheckHeader(eudore.HeaderContentEncoding, "").CheckBodyContainString("eudore eudore")
+	client.NewRequest("GET", "/empty").WithHeaderValue(eudore.HeaderAcceptEncoding, "gzip").Do().
+		CheckStatus(204).CheckHeader(eudore.HeaderContentEncoding, "").CheckBodyString("")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,82 @@
+package main
+
+/*
+ConnGuard实现连接级别防护，包装监听限制每个IP的并发连接数，ConnState钩子区分读取请求超时和keep-alive超时。
+
+客户端触发限制(超过并发连接数、读取请求header超时、请求返回429)达到MaxStrikes次后临时封禁IP，
+封禁写入黑名单，黑名单中间件同时拒绝该IP的请求，拒绝的连接延迟Tarpit时间后关闭。
+
+读取请求header超时时间使用ServerStdConfig.ReadHeaderTimeout设置。
+
+客户端ip使用连接地址，部署在反向代理后需要设置TrustedProxies，才会从X-Forwarded-For读取客户端ip。
+*/
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	black := middleware.NewBlack(nil)
+	guard := middleware.NewConnGuard(black)
+	guard.MaxStrikes = 3
+	guard.BanTime = time.Second
+	guard.Tarpit = 100 * time.Millisecond
+	guard.Print = app.Info
+	eudore.Set(app.Server, "", eudore.ServerStdConfig{
+		ReadTimeout:       eudore.TimeDuration(time.Second),
+		ReadHeaderTimeout: eudore.TimeDuration(200 * time.Millisecond),
+		ConnState:         guard.ConnState,
+	})
+	app.AddMiddleware(guard.NewConnGuardFunc(), black.HandleHTTP)
+	app.AnyFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteString("hello eudore")
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	app.Serve(guard.Listener(ln))
+	addr := ln.Addr().String()
+
+	get := func() string {
+		client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: time.Second}
+		resp, err := client.Get("http://" + addr + "/")
+		if err != nil {
+			return "error"
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return string(body)
+	}
+	fmt.Println("before:", get())
+
+	// 模拟慢速攻击，缓慢发送请求header，读取header超时后连接关闭记录触发。
+	for i := 0; i < 3; i++ {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			panic(err)
+		}
+		conn.Write([]byte("GET / HTTP/1.1\r\nHost: eudore\r\n"))
+		ioutil.ReadAll(conn)
+		conn.Close()
+	}
+	if _, ok := guard.Bans()["127.0.0.1"]; !ok || !black.Deny("127.0.0.1") {
+		panic("conn guard not ban ip 127.0.0.1")
+	}
+	fmt.Println("banned:", get())
+
+	time.Sleep(1200 * time.Millisecond)
+	fmt.Println("after ban expired:", get(), black.Deny("127.0.0.1"))
+
+	// app.CancelFunc()
+	app.Run()
+}
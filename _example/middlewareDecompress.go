@@ -0,0 +1,66 @@
+package main
+
+/*
+Decompress根据Content-Encoding解压gzip、deflate请求body，Bind读取解压后的数据，可以使用options添加zstd等解压函数。
+
+解压后超过size字节或解压比超过ratio时读取返回错误并设置状态码413，防止解压炸弹；不支持的Content-Encoding返回415。
+*/
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"strings"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewLoggerFunc(app, "route"))
+	app.AddMiddleware(middleware.NewDecompressFunc(1<<20, 100))
+	app.PostFunc("/user", func(ctx eudore.Context) (interface{}, error) {
+		var user struct {
+			Name string `json:"name"`
+		}
+		err := ctx.Bind(&user)
+		return user, err
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("POST", "/user").WithHeaderValue(eudore.HeaderContentType, eudore.MimeApplicationJSON).
+		WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).WithHeaderValue(eudore.HeaderContentEncoding, "gzip").
+		WithBodyBytes(compressGzip(`{"name":"eudore"}`)).Do().CheckStatus(200).CheckBodyContainString(`{"name":"eudore"}`).Out()
+	client.NewRequest("POST", "/user").WithHeaderValue(eudore.HeaderContentType, eudore.MimeApplicationJSON).
+		WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).WithHeaderValue(eudore.HeaderContentEncoding, "deflate").
+		WithBodyBytes(compressDeflate(`{"name":"deflate"}`)).Do().CheckStatus(200).CheckBodyContainString(`{"name":"deflate"}`).Out()
+	// 2MB的空格压缩后只有几KB，超过解压比和大小限制。
+	client.NewRequest("POST", "/user").WithHeaderValue(eudore.HeaderContentType, eudore.MimeApplicationJSON).
+		WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).WithHeaderValue(eudore.HeaderContentEncoding, "gzip").
+		WithBodyBytes(compressGzip(`{"name":"bomb"` + strings.Repeat(" ", 2<<20) + `}`)).Do().CheckStatus(413).Out()
+	client.NewRequest("POST", "/user").WithHeaderValue(eudore.HeaderContentType, eudore.MimeApplicationJSON).
+		WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).WithHeaderValue(eudore.HeaderContentEncoding, "zstd").
+		WithBodyString(`{}`).Do().CheckStatus(415).Out()
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
+
+func compressGzip(s string) []byte {
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	w.Write([]byte(s))
+	w.Close()
+	return buf.Bytes()
+}
+
+func compressDeflate(s string) []byte {
+	buf := &bytes.Buffer{}
+	w := zlib.NewWriter(buf)
+	w.Write([]byte(s))
+	w.Close()
+	return buf.Bytes()
+}
@@ -0,0 +1,42 @@
+package main
+
+/*
+Expect在客户端发送Expect: 100-continue时检查请求，拒绝超过大小或不接受的请求，客户端不会发送body。
+
+路由参数expect可以设置组路由或路由的最大Content-Length，检查通过后立即写入100 Continue。
+处理函数也可以使用ctx.AcceptContinue方法显式接受body，拒绝时不读取body直接写入错误状态码。
+*/
+
+import (
+	"strings"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewLoggerFunc(app, "route"))
+	app.AddMiddleware(middleware.NewExpectFunc(1<<20, func(ctx eudore.Context) bool {
+		return ctx.GetHeader("X-Token") != ""
+	}))
+	app.PutFunc("/upload/* expect=16", func(ctx eudore.Context) {
+		ctx.Write(ctx.Body())
+	})
+	app.PutFunc("/files/*", func(ctx eudore.Context) {
+		ctx.Write(ctx.Body())
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("PUT", "/upload/1").WithHeaderValue("X-Token", "1").WithHeaderValue(eudore.HeaderExpect, "100-continue").WithBodyString("0123456789").Do().CheckStatus(200).CheckBodyString("0123456789")
+	client.NewRequest("PUT", "/upload/2").WithHeaderValue("X-Token", "1").WithHeaderValue(eudore.HeaderExpect, "100-continue").WithBodyString(strings.Repeat("0123456789", 4)).Do().CheckStatus(413).CheckHeader(eudore.HeaderConnection, "close")
+	client.NewRequest("PUT", "/files/1").WithHeaderValue("X-Token", "1").WithHeaderValue(eudore.HeaderExpect, "100-continue").WithBodyString(strings.Repeat("0123456789", 4)).Do().CheckStatus(200)
+	client.NewRequest("PUT", "/files/2").WithHeaderValue(eudore.HeaderExpect, "100-continue").WithBodyString("0123456789").Do().CheckStatus(417)
+	// 没有Expect的请求不检查。
+	client.NewRequest("PUT", "/files/3").WithBodyString("0123456789").Do().CheckStatus(200)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,61 @@
+package eudore_test
+
+import (
+	"testing"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func TestMiddlewareConnGuardRealIP(t *testing.T) {
+	guard := middleware.NewConnGuard(nil)
+	guard.MaxStrikes = 1
+	app := eudore.NewApp()
+	app.AddMiddleware(guard.NewConnGuardFunc())
+	app.AnyFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteHeader(eudore.StatusTooManyRequests)
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/").WithRemoteAddr("192.0.2.10:1234").WithHeaderValue(eudore.HeaderXForwardedFor, "198.51.100.1").Do()
+	bans := guard.Bans()
+	if _, ok := bans["198.51.100.1"]; ok {
+		t.Errorf("untrusted X-Forwarded-For ip banned: %v", bans)
+	}
+	if _, ok := bans["192.0.2.10"]; !ok {
+		t.Errorf("remote ip not banned: %v", bans)
+	}
+
+	guard.TrustedProxies = []string{"10.0.0.0/8", "192.0.2.20"}
+	client.NewRequest("GET", "/").WithRemoteAddr("192.0.2.20:1234").WithHeaderValue(eudore.HeaderXForwardedFor, "203.0.113.1, 198.51.100.2, 10.0.0.1").Do()
+	bans = guard.Bans()
+	if _, ok := bans["198.51.100.2"]; !ok {
+		t.Errorf("ip behind trusted proxies not banned: %v", bans)
+	}
+	if _, ok := bans["203.0.113.1"]; ok {
+		t.Errorf("client supplied X-Forwarded-For ip banned: %v", bans)
+	}
+}
+
+func TestMiddlewareHoneypotRealIP(t *testing.T) {
+	honeypot := middleware.NewHoneypot(nil)
+	app := eudore.NewApp()
+	app.AddMiddleware(honeypot.NewHoneypotFunc())
+	app.AnyFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteString("hello eudore")
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/.env").WithRemoteAddr("192.0.2.10:1234").WithHeaderValue(eudore.HeaderXForwardedFor, "198.51.100.1").Do()
+	if honeypot.Guard.Banned("198.51.100.1") {
+		t.Error("spoofed X-Forwarded-For ip banned")
+	}
+	resp := client.NewRequest("GET", "/").WithRemoteAddr("192.0.2.10:1234").WithHeaderValue(eudore.HeaderXForwardedFor, "198.51.100.2").Do()
+	if resp.Code != eudore.StatusForbidden {
+		t.Errorf("banned remote ip rotate X-Forwarded-For status %d", resp.Code)
+	}
+	if body := resp.Body.String(); body != "honeypot deny your ip" {
+		t.Errorf("deny body echo client ip: %s", body)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+/*
+Honeypot实现蜜罐和爬虫防护，请求评分累计达到阈值后使用ConnGuard封禁IP，封禁同时写入黑名单。
+
+访问蜜罐路径(页面隐藏链接、常见扫描路径)直接达到阈值，其他请求根据User-Agent、Accept和404响应评分。
+
+开启挑战后客户端需要执行js写入签名cookie，pow方式需要计算sha256前导零达到Difficulty位的nonce。
+*/
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	guard := middleware.NewConnGuard(middleware.NewBlack(map[string]bool{"10.0.0.0/8": true}))
+	guard.Print = app.Info
+	honeypot := middleware.NewHoneypot(guard)
+	honeypot.Paths = append(honeypot.Paths, "/hidden-link")
+	honeypot.Threshold = 20
+	honeypot.BanTime = time.Minute
+	honeypot.Challenge = middleware.HoneypotChallengePoW
+	honeypot.Difficulty = 8
+	app.AddMiddleware(honeypot.NewHoneypotFunc())
+	app.GetFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteString("hello eudore")
+	})
+
+	client := httptest.NewClient(app).AddHeaderValue(eudore.HeaderUserAgent, "Mozilla/5.0 (X11; Linux x86_64) Firefox/118.0").
+		AddHeaderValue(eudore.HeaderAccept, eudore.MimeTextHTML)
+	// 首次访问返回挑战页面，浏览器执行js计算nonce后写入cookie。
+	resp := client.NewRequest("GET", "/").WithRemoteAddr("192.0.2.1:1234").Do().CheckStatus(403).CheckBodyContainString("crypto.subtle")
+	token := regexp.MustCompile(`var c="([^"]+)"`).FindStringSubmatch(resp.Body.String())[1]
+	cookie := token + "." + solve(token, honeypot.Difficulty)
+	client.NewRequest("GET", "/").WithRemoteAddr("192.0.2.1:1234").WithHeaderValue(eudore.HeaderCookie, "_honeypot="+cookie).
+		Do().CheckStatus(200).CheckBodyString("hello eudore")
+	// 挑战cookie绑定ip
+	client.NewRequest("GET", "/").WithRemoteAddr("192.0.2.2:1234").WithHeaderValue(eudore.HeaderCookie, "_honeypot="+cookie).Do().CheckStatus(403)
+
+	// 访问蜜罐路径后封禁ip
+	client.NewRequest("GET", "/hidden-link").WithRemoteAddr("192.0.2.3:1234").Do().CheckStatus(404)
+	client.NewRequest("GET", "/").WithRemoteAddr("192.0.2.3:1234").Do().CheckStatus(403).CheckBodyContainString("deny")
+	fmt.Println("banned:", guard.Bans(), guard.Black.Deny("192.0.2.3"))
+	// 白名单不检查
+	client.NewRequest("GET", "/.env").WithRemoteAddr("10.0.0.1:1234").Do().CheckStatus(200)
+	client.NewRequest("GET", "/").WithRemoteAddr("10.0.0.1:1234").Do().CheckStatus(200)
+
+	// 爬虫和缺少Header的请求累计评分后封禁
+	bot := httptest.NewClient(app)
+	for i := 0; i < 2; i++ {
+		bot.NewRequest("GET", "/").WithRemoteAddr("192.0.2.4:1234").Do().CheckStatus(403)
+	}
+	client.NewRequest("GET", "/").WithRemoteAddr("192.0.2.4:1234").Do().CheckStatus(403).CheckBodyContainString("deny")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
+
+// solve 函数计算工作量证明，和挑战页面js逻辑相同。
+func solve(token string, difficulty int) string {
+	for n := 0; ; n++ {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s%d", token, n)))
+		zero := 0
+		for _, c := range sum {
+			if c != 0 {
+				for c&0x80 == 0 {
+					zero++
+					c <<= 1
+				}
+				break
+			}
+			zero += 8
+		}
+		if zero >= difficulty {
+			return fmt.Sprint(n)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	var count int32
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewIdempotencyFunc(time.Hour))
+	app.PostFunc("/order", func(ctx eudore.Context) {
+		ctx.SetHeader("X-Order", fmt.Sprint(atomic.AddInt32(&count, 1)))
+		ctx.WriteHeader(201)
+		ctx.WriteString("create order")
+	})
+	app.PostFunc("/slow", func(ctx eudore.Context) {
+		time.Sleep(time.Millisecond * 50)
+	})
+	app.PostFunc("/error", func(ctx eudore.Context) {
+		ctx.WriteHeader(500)
+	})
+	app.AnyFunc("/*", eudore.HandlerEmpty)
+
+	client := httptest.NewClient(app)
+	client.NewRequest("POST", "/order").WithHeaderValue(eudore.HeaderIdempotencyKey, "k1").Do().CheckStatus(201).CheckHeader("X-Order", "1")
+	// 重试返回保存的响应
+	client.NewRequest("POST", "/order").WithHeaderValue(eudore.HeaderIdempotencyKey, "k1").Do().CheckStatus(201).CheckHeader("X-Order", "1").CheckBodyString("create order")
+	client.NewRequest("POST", "/order").WithHeaderValue(eudore.HeaderIdempotencyKey, "k2").Do().CheckStatus(201).CheckHeader("X-Order", "2")
+	// 未设置key不处理
+	client.NewRequest("POST", "/order").Do().CheckStatus(201).CheckHeader("X-Order", "3")
+	client.NewRequest("GET", "/order").WithHeaderValue(eudore.HeaderIdempotencyKey, "k1").Do().CheckStatus(200)
+
+	// 请求处理中返回409
+	go client.NewRequest("POST", "/slow").WithHeaderValue(eudore.HeaderIdempotencyKey, "k3").Do()
+	time.Sleep(time.Millisecond * 10)
+	client.NewRequest("POST", "/slow").WithHeaderValue(eudore.HeaderIdempotencyKey, "k3").Do().CheckStatus(409)
+	time.Sleep(time.Millisecond * 50)
+	client.NewRequest("POST", "/slow").WithHeaderValue(eudore.HeaderIdempotencyKey, "k3").Do().CheckStatus(200)
+
+	// 5xx响应不保存
+	client.NewRequest("POST", "/error").WithHeaderValue(eudore.HeaderIdempotencyKey, "k4").Do().CheckStatus(500)
+	client.NewRequest("POST", "/error").WithHeaderValue(eudore.HeaderIdempotencyKey, "k4").Do().CheckStatus(500)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,53 @@
+package main
+
+/*
+维护模式开启后非白名单路由返回503维护响应，健康检查路径和maintenance=allow的路由允许访问。
+
+维护模式可以使用管理接口、信号或配置切换。
+*/
+
+import (
+	"syscall"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	maintenance := middleware.NewMaintenance()
+	maintenance.RetryAfter = time.Minute
+	maintenance.WatchSignal(app, app, syscall.SIGUSR2)
+	maintenance.WatchFunc(app, 10*time.Millisecond, func() bool {
+		return eudore.GetBool(app.Get("maintenance"))
+	})
+	app.AddMiddleware(maintenance.NewMaintenanceFunc(app.Group("/eudore/debug")))
+	app.GetFunc("/health", eudore.HandlerEmpty)
+	app.GetFunc("/status maintenance=allow", eudore.HandlerEmpty)
+	app.AnyFunc("/*", eudore.HandlerEmpty)
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/").Do().CheckStatus(200)
+	client.NewRequest("PUT", "/eudore/debug/maintenance/on").Do().CheckStatus(200)
+	client.NewRequest("GET", "/").Do().CheckStatus(503).CheckHeader(eudore.HeaderRetryAfter, "60").Out()
+	client.NewRequest("GET", "/health").Do().CheckStatus(200)
+	client.NewRequest("GET", "/status").Do().CheckStatus(200)
+	client.NewRequest("GET", "/eudore/debug/maintenance").Do().CheckStatus(200).CheckBodyContainString("true")
+	client.NewRequest("PUT", "/eudore/debug/maintenance/off").Do().CheckStatus(200)
+	client.NewRequest("GET", "/").Do().CheckStatus(200)
+
+	// 配置切换
+	app.Set("maintenance", true)
+	time.Sleep(50 * time.Millisecond)
+	client.NewRequest("GET", "/").Do().CheckStatus(503)
+	// 信号切换
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	time.Sleep(50 * time.Millisecond)
+	client.NewRequest("GET", "/").Do().CheckStatus(200)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,53 @@
+package main
+
+/*
+请求镜像按百分比将请求和body异步复制到另一个upstream，用于测试新版本服务，镜像响应会被忽略。
+*/
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/eudore/eudore"
+	eudorehttptest "github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	var mu sync.Mutex
+	var mirrors []string
+	shadow := eudore.NewApp()
+	shadow.AnyFunc("/*", func(ctx eudore.Context) {
+		mu.Lock()
+		mirrors = append(mirrors, fmt.Sprintf("%s %s %s %s", ctx.Method(), ctx.Request().RequestURI, ctx.Body(), ctx.GetHeader(middleware.HeaderXMirror)))
+		mu.Unlock()
+		// 镜像服务的延迟不影响主请求
+		time.Sleep(100 * time.Millisecond)
+		ctx.WriteHeader(500)
+	})
+	server := httptest.NewServer(shadow)
+	defer server.Close()
+
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewMirrorFunc(server.URL, 50))
+	app.AnyFunc("/*", func(ctx eudore.Context) {
+		ctx.Write(ctx.Body())
+	})
+
+	client := eudorehttptest.NewClient(app)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		client.NewRequest("POST", fmt.Sprintf("/order?id=%d", i)).WithBodyString(fmt.Sprintf("order-%d", i)).Do().CheckStatus(200).CheckBodyString(fmt.Sprintf("order-%d", i))
+	}
+	fmt.Println("primary duration less than 100ms:", time.Since(start) < 100*time.Millisecond)
+	time.Sleep(200 * time.Millisecond)
+	mu.Lock()
+	fmt.Println(mirrors)
+	mu.Unlock()
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
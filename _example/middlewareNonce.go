@@ -0,0 +1,47 @@
+package main
+
+/*
+Nonce拒绝重放请求，请求使用X-Nonce Header传递唯一nonce，X-Nonce-Timestamp Header传递unix秒时间戳。
+
+时间戳超出时间窗口的请求拒绝，nonce保存两倍时间窗口，使用eudore.Cache保存时多实例共享nonce。
+*/
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	api := app.Group("/api")
+	api.AddMiddleware(middleware.NewNonceFunc(app.Cache, time.Minute, func(ctx eudore.Context) string {
+		return ctx.GetHeader("X-Client")
+	}))
+	api.PostFunc("/transfer", func(ctx eudore.Context) {
+		ctx.WriteString("transfer ok")
+	})
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	old := strconv.FormatInt(time.Now().Add(-2*time.Minute).Unix(), 10)
+	client := httptest.NewClient(app)
+	client.NewRequest("POST", "/api/transfer").Do().CheckStatus(400)
+	client.NewRequest("POST", "/api/transfer").WithHeaderValue(middleware.HeaderXNonce, "n1").Do().CheckStatus(400)
+	client.NewRequest("POST", "/api/transfer").WithHeaderValue(middleware.HeaderXNonce, "n1").WithHeaderValue(middleware.HeaderXNonceTimestamp, now).
+		Do().CheckStatus(200).CheckBodyString("transfer ok")
+	// 重放请求
+	client.NewRequest("POST", "/api/transfer").WithHeaderValue(middleware.HeaderXNonce, "n1").WithHeaderValue(middleware.HeaderXNonceTimestamp, now).
+		Do().CheckStatus(409)
+	// 其他客户端作用域可以使用相同nonce
+	client.NewRequest("POST", "/api/transfer").WithHeaderValue(middleware.HeaderXNonce, "n1").WithHeaderValue(middleware.HeaderXNonceTimestamp, now).
+		WithHeaderValue("X-Client", "mobile").Do().CheckStatus(200)
+	client.NewRequest("POST", "/api/transfer").WithHeaderValue(middleware.HeaderXNonce, "n2").WithHeaderValue(middleware.HeaderXNonceTimestamp, old).
+		Do().CheckStatus(400).CheckBodyContainString("expired")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
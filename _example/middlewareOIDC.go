@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	nethttptest "net/http/httptest"
+	"net/url"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	// 模拟OIDC身份提供者
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("code") != "code" || r.PostFormValue("code_verifier") == "" {
+			w.WriteHeader(400)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "token", "token_type": "Bearer"})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(401)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"sub": "1", "name": "eudore"})
+	})
+	srv := nethttptest.NewServer(mux)
+	defer srv.Close()
+
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewOIDCFunc(app.Group("/auth"), &middleware.OIDCProvider{
+		Name:        "local",
+		ClientID:    "eudore",
+		AuthURL:     srv.URL + "/auth",
+		TokenURL:    srv.URL + "/token",
+		UserinfoURL: srv.URL + "/userinfo",
+		RedirectURL: "http://localhost:8088/auth/oidc/callback/local",
+	}))
+	app.AnyFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteJSON(middleware.GetOIDCClaims(ctx))
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("POST", "/user").Do().CheckStatus(401)
+	resp := client.NewRequest("GET", "/user").Do().CheckStatus(302)
+	location, _ := url.Parse(resp.Header().Get(eudore.HeaderLocation))
+	query := location.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("client_id") != "eudore" {
+		panic("oidc auth url invalid: " + location.String())
+	}
+
+	client.NewRequest("GET", "/auth/oidc/callback/local?code=code&state=none").Do().CheckStatus(400)
+	// state绑定发起登录的浏览器，其他浏览器使用state回调返回400
+	httptest.NewClient(app).NewRequest("GET", "/auth/oidc/callback/local?code=code&state="+query.Get("state")).Do().CheckStatus(400)
+	client.NewRequest("GET", "/auth/oidc/callback/local?code=code&state="+query.Get("state")).Do().CheckStatus(302).CheckHeader(eudore.HeaderLocation, "/user")
+	client.NewRequest("GET", "/user").Do().CheckStatus(200).CheckBodyContainString("eudore")
+	client.NewRequest("GET", "/auth/oidc/logout").Do().CheckStatus(200)
+	client.NewRequest("GET", "/user").Do().CheckStatus(302)
+	client.NewRequest("GET", "/auth/oidc/login/local?redirect=/index").Do().CheckStatus(302)
+	client.NewRequest("GET", "/auth/oidc/login/none").Do().CheckStatus(404)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,77 @@
+package eudore_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	nethttptest "net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func TestMiddlewareOIDCSessionTOTPState(t *testing.T) {
+	secret, err := middleware.NewTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oidc := middleware.NewOIDC()
+	app := eudore.NewApp()
+	app.AddMiddleware(oidc.NewOIDCFunc(app.Group("/auth")))
+	app.Group("/admin").AddMiddleware(middleware.NewTOTPFunc(app, oidc.Store, func(context.Context, string) (string, error) {
+		return secret, nil
+	}))
+	app.AnyFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteString("user " + middleware.GetOIDCClaims(ctx)["sub"].(string))
+	})
+
+	// TOTP在共享Store中保存的状态不能作为OIDC会话。
+	oidc.Store.Set("totp-step:alice", map[string]interface{}{"step": float64(1), "sub": "alice"}, time.Hour)
+	oidc.Store.Set("totp-fail:alice", map[string]interface{}{"count": float64(1), "sub": "alice"}, time.Hour)
+	client := httptest.NewClient(app)
+	for _, id := range []string{"totp-step:alice", "totp-fail:alice"} {
+		resp := client.NewRequest("POST", "/user").WithHeaderValue(eudore.HeaderCookie, "_oidc="+id).Do()
+		if resp.Code != eudore.StatusUnauthorized {
+			t.Errorf("oidc session %s status %d", id, resp.Code)
+		}
+		resp = client.NewRequest("POST", "/totp/verify").WithHeaderValue(eudore.HeaderCookie, "_oidc="+id).Do()
+		if resp.Code != eudore.StatusUnauthorized {
+			t.Errorf("totp session %s status %d", id, resp.Code)
+		}
+	}
+}
+
+func TestMiddlewareOIDCLoginRedirect(t *testing.T) {
+	srv := nethttptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "token", "sub": "alice"})
+	}))
+	defer srv.Close()
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewOIDCFunc(app, &middleware.OIDCProvider{
+		Name:        "local",
+		AuthURL:     srv.URL + "/auth",
+		TokenURL:    srv.URL + "/token",
+		UserinfoURL: srv.URL + "/userinfo",
+	}))
+
+	for redirect, location := range map[string]string{
+		"/index?page=1":    "/index?page=1",
+		"/\\evil.com":      "/",
+		"/\\/evil.com":     "/",
+		"//evil.com":       "/",
+		"https://evil.com": "/",
+		"evil.com":         "/",
+	} {
+		client := httptest.NewClient(app)
+		resp := client.NewRequest("GET", "/oidc/login/local?redirect="+url.QueryEscape(redirect)).Do()
+		auth, _ := url.Parse(resp.Header().Get(eudore.HeaderLocation))
+		resp = client.NewRequest("GET", "/oidc/callback/local?code=code&state="+auth.Query().Get("state")).Do()
+		if resp.Code != eudore.StatusFound || resp.Header().Get(eudore.HeaderLocation) != location {
+			t.Errorf("oidc login redirect %s: %d %s, want %s", redirect, resp.Code, resp.Header().Get(eudore.HeaderLocation), location)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewQueueFunc(1, 1, time.Millisecond*50))
+	app.AnyFunc("/sleep", func(ctx eudore.Context) {
+		time.Sleep(time.Millisecond * 100)
+	})
+	app.AnyFunc("/*", eudore.HandlerEmpty)
+
+	client := httptest.NewClient(app)
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.NewRequest("GET", "/sleep").Do()
+		}()
+		time.Sleep(time.Millisecond * 10)
+	}
+	// 其他路由不受影响
+	client.NewRequest("GET", "/index").Do().CheckStatus(200)
+	// 处理中1个、排队中1个，队列已满
+	client.NewRequest("GET", "/sleep").Do().CheckStatus(503).CheckHeader(eudore.HeaderRetryAfter, "1")
+	wg.Wait()
+	client.NewRequest("GET", "/sleep").Do().CheckStatus(200)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+
+	middlewareQueue2()
+}
+
+func middlewareQueue2() {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewQueueFunc(1, 10, time.Millisecond*20, func(ctx eudore.Context) string {
+		return ctx.RealIP()
+	}))
+	app.AnyFunc("/sleep", func(ctx eudore.Context) {
+		time.Sleep(time.Millisecond * 100)
+	})
+	app.AnyFunc("/*", eudore.HandlerEmpty)
+
+	client := httptest.NewClient(app)
+	go client.NewRequest("GET", "/sleep").Do()
+	time.Sleep(time.Millisecond * 10)
+	// 排队超时
+	client.NewRequest("GET", "/index").Do().CheckStatus(503).CheckHeader(eudore.HeaderRetryAfter, "1")
+	time.Sleep(time.Millisecond * 100)
+	client.NewRequest("GET", "/index").Do().CheckStatus(200)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,65 @@
+package main
+
+/*
+Quota统计滚动窗口内的请求数和响应字节数，默认依次按API Key(apikey参数)、UID参数、经过检查函数校验的租户和IP统计。
+
+请求设置X-RateLimit-Limit、X-RateLimit-Remaining、X-RateLimit-Reset Header，
+字节配额设置X-Quota-Bytes-Limit、X-Quota-Bytes-Remaining Header，超出配额返回429并设置Retry-After Header。
+
+使用租户时配额读取租户配置quota.requests和quota.bytes，后台接口查看和重置用量。
+*/
+
+import (
+	"strings"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.Set("tenant.acme.quota.requests", 5)
+	admin := app.Group("/admin")
+	api := app.Group("/api")
+	tenants := map[string]bool{"default": true, "acme": true, "globex": true}
+	api.AddMiddleware(
+		// 租户需要使用检查函数校验，未校验的租户由客户端决定，不用于配额统计
+		middleware.NewTenantFunc("default", app.Config, func(name string) bool { return tenants[name] }),
+		middleware.NewQuotaFunc(3, 1000, time.Minute, admin),
+	)
+	api.GetFunc("/data", func(ctx eudore.Context) {
+		ctx.WriteString("data")
+	})
+	api.GetFunc("/download", func(ctx eudore.Context) {
+		ctx.WriteString(strings.Repeat("0", 600))
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/api/data").Do().CheckStatus(200).CheckHeader(middleware.HeaderXRateLimitLimit, "3", middleware.HeaderXRateLimitRemaining, "2")
+	client.NewRequest("GET", "/api/data").Do().CheckStatus(200).CheckHeader(middleware.HeaderXRateLimitRemaining, "1")
+	client.NewRequest("GET", "/api/data").Do().CheckStatus(200).CheckHeader(middleware.HeaderXRateLimitRemaining, "0")
+	client.NewRequest("GET", "/api/data").Do().CheckStatus(429)
+	// acme租户配置覆盖请求配额
+	for i := 0; i < 5; i++ {
+		client.NewRequest("GET", "/api/data").WithHeaderValue(middleware.HeaderXTenantID, "acme").Do().CheckStatus(200)
+	}
+	client.NewRequest("GET", "/api/data").WithHeaderValue(middleware.HeaderXTenantID, "acme").Do().CheckStatus(429)
+	client.NewRequest("GET", "/api/data").WithHeaderValue(middleware.HeaderXTenantID, "initech").Do().CheckStatus(404)
+	// 字节配额
+	client.NewRequest("GET", "/api/download").WithHeaderValue(middleware.HeaderXTenantID, "globex").Do().CheckStatus(200)
+	client.NewRequest("GET", "/api/download").WithHeaderValue(middleware.HeaderXTenantID, "globex").Do().CheckStatus(200).
+		CheckHeader(middleware.HeaderXQuotaBytesRemain, "400")
+	client.NewRequest("GET", "/api/data").WithHeaderValue(middleware.HeaderXTenantID, "globex").Do().CheckStatus(429)
+
+	// 后台查看和重置用量
+	client.NewRequest("GET", "/admin/quota/data").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().
+		CheckStatus(200).CheckBodyContainString(`"tenant:default"`, `"tenant:acme"`).Out()
+	client.NewRequest("DELETE", "/admin/quota/key/tenant:default").Do().CheckStatus(200)
+	client.NewRequest("GET", "/api/data").Do().CheckStatus(200).CheckHeader(middleware.HeaderXRateLimitRemaining, "2")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -29,6 +29,7 @@ func main() {
 	middlewareRate3()
 	middlewareRate4()
 	middlewareRate5()
+	middlewareRate6()
 }
 
 func middlewareRate2() {
@@ -127,3 +128,25 @@ func middlewareRate5() {
 	// app.CancelFunc()
 	app.Run()
 }
+
+func middlewareRate6() {
+	// 多个实例共享限流存储，分布式部署使用redis.NewRateStore(redis.NewClient("127.0.0.1:6379", "", 0), "rate:")。
+	store := middleware.NewRateStoreMemory()
+	app1 := eudore.NewApp()
+	app1.AnyFunc("/*", middleware.NewRateFunc(1, 3, store), eudore.HandlerEmpty)
+	app := eudore.NewApp()
+	app.AnyFunc("/*", middleware.NewRateFunc(1, 3, store), eudore.HandlerEmpty)
+
+	client1 := httptest.NewClient(app1)
+	client := httptest.NewClient(app)
+	client1.NewRequest("PUT", "/").Do().CheckStatus(200)
+	client.NewRequest("PUT", "/").Do().CheckStatus(200)
+	client1.NewRequest("PUT", "/").Do().CheckStatus(200)
+	client.NewRequest("PUT", "/").Do()
+	client1.NewRequest("PUT", "/").Do().CheckStatus(429)
+
+	app1.CancelFunc()
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,51 @@
+package main
+
+/*
+RewriteRules按规则重写请求，需要注册全局中间件在路由匹配前执行。
+
+Path为正则表达式，Target使用$1、${name}引用捕获组，还可以修改请求Header、请求参数和添加响应Header，规则按顺序执行。
+*/
+
+import (
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware("global", middleware.NewRewriteRulesFunc([]*middleware.RewriteRule{
+		{
+			Path:           `^/api/v1/users/(\d+)/orders/(?P<order>\d+)$`,
+			Target:         "/api/v3/user/$1/order/${order}",
+			RenameQuery:    map[string]string{"uid": "id"},
+			ResponseHeader: map[string]string{"Deprecation": "true"},
+			Last:           true,
+		},
+		{
+			Path:         `^/legacy/(.*)$`,
+			Target:       "/api/v3/$1",
+			Header:       map[string]string{"X-Legacy": "1"},
+			RenameHeader: map[string]string{"X-Token": "Authorization"},
+			DelQuery:     []string{"debug"},
+			Query:        map[string]string{"version": "3"},
+		},
+		{
+			DelHeader: []string{"X-Internal"},
+		},
+	}))
+	app.AddMiddleware(middleware.NewLoggerFunc(app))
+	app.AnyFunc("/api/v3/*", func(ctx eudore.Context) {
+		ctx.WriteString(ctx.Path() + "?" + ctx.Request().URL.RawQuery)
+		ctx.WriteString(" legacy=" + ctx.GetHeader("X-Legacy") + " auth=" + ctx.GetHeader("Authorization") + " internal=" + ctx.GetHeader("X-Internal"))
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/api/v1/users/12/orders/34?uid=5").Do().CheckStatus(200).CheckHeader("Deprecation", "true").CheckBodyString("/api/v3/user/12/order/34?id=5 legacy= auth= internal=")
+	client.NewRequest("GET", "/legacy/user?debug=1").WithHeaderValue("X-Token", "tk").WithHeaderValue("X-Internal", "1").Do().CheckStatus(200).CheckBodyString("/api/v3/user?version=3 legacy=1 auth=tk internal=")
+	client.NewRequest("GET", "/api/v3/info").WithHeaderValue("X-Internal", "1").Do().CheckStatus(200).CheckBodyString("/api/v3/info? legacy= auth= internal=")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	secrets := map[string]string{"partner": "secret"}
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewSignatureFunc(func(client string) string {
+		return secrets[client]
+	}))
+	app.AnyFunc("/*", func(ctx eudore.Context) {
+		ctx.Write(ctx.Body())
+	})
+
+	client := httptest.NewClient(app)
+	now := fmt.Sprint(time.Now().Unix())
+	client.NewRequest("POST", "/hook?id=1").WithHeaders(newSignatureHeader("POST", "/hook?id=1", now, "n1", "body", "secret")).WithBodyString("body").Do().CheckStatus(200).CheckBodyString("body")
+	// nonce重放
+	client.NewRequest("POST", "/hook?id=1").WithHeaders(newSignatureHeader("POST", "/hook?id=1", now, "n1", "body", "secret")).WithBodyString("body").Do().CheckStatus(401)
+	// body被修改
+	client.NewRequest("POST", "/hook?id=1").WithHeaders(newSignatureHeader("POST", "/hook?id=1", now, "n2", "body", "secret")).WithBodyString("body2").Do().CheckStatus(401)
+	// 密钥错误
+	client.NewRequest("POST", "/hook?id=1").WithHeaders(newSignatureHeader("POST", "/hook?id=1", now, "n3", "body", "secret2")).WithBodyString("body").Do().CheckStatus(401)
+	// 时间戳过期
+	old := fmt.Sprint(time.Now().Add(-time.Hour).Unix())
+	client.NewRequest("POST", "/hook?id=1").WithHeaders(newSignatureHeader("POST", "/hook?id=1", old, "n4", "body", "secret")).WithBodyString("body").Do().CheckStatus(401)
+	// client无效
+	client.NewRequest("POST", "/hook?id=1").WithBodyString("body").Do().CheckStatus(401)
+	client.NewRequest("POST", "/hook?id=1").WithHeaderValue(middleware.HeaderXSignatureClient, "none").WithBodyString("body").Do().CheckStatus(401)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
+
+func newSignatureHeader(method, uri, timestamp, nonce, body, secret string) map[string][]string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(method + "\n" + uri + "\n" + timestamp + "\n" + nonce + "\n" + body))
+	return map[string][]string{
+		middleware.HeaderXSignatureClient:    {"partner"},
+		middleware.HeaderXSignatureTimestamp: {timestamp},
+		middleware.HeaderXSignatureNonce:     {nonce},
+		middleware.HeaderXSignature:          {hex.EncodeToString(h.Sum(nil))},
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+/*
+SignedURL生成有时效的签名URL，用于下载和上传接口临时授权，签名包含方法、path、过期时间和其他query参数。
+
+密钥每次请求从配置读取，第一个密钥用于签名，全部密钥用于校验；
+轮换时在配置前面添加新密钥，旧密钥签名的URL过期后从配置删除旧密钥。
+*/
+
+import (
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.Set("signedurl.secrets", []string{"secret-v1"})
+	signer := middleware.NewSignedURL(func() []string {
+		return eudore.GetStrings(app.Get("signedurl.secrets"))
+	})
+
+	files := app.Group("/files")
+	files.AddMiddleware(signer.NewSignedURLFunc())
+	files.GetFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteString("download " + ctx.GetParam("*"))
+	})
+	files.PutFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteString("upload " + ctx.GetParam("*"))
+	})
+	app.PostFunc("/sign", func(ctx eudore.Context) (interface{}, error) {
+		return signer.Sign(ctx.GetQuery("method"), "/files/"+ctx.GetQuery("name"), time.Minute)
+	})
+
+	download, _ := signer.Sign("GET", "/files/report.pdf?disposition=inline", time.Minute)
+	upload, _ := signer.Sign("PUT", "/files/avatar.png", time.Minute)
+	expired, _ := signer.Sign("GET", "/files/report.pdf", -time.Second)
+	app.Info("signed url:", download)
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", download).Do().CheckStatus(200).CheckBodyString("download report.pdf")
+	client.NewRequest("PUT", upload).Do().CheckStatus(200).CheckBodyString("upload avatar.png")
+	client.NewRequest("GET", "/files/report.pdf").Do().CheckStatus(403)
+	// 签名不能用于其他方法、path或参数。
+	client.NewRequest("GET", upload).Do().CheckStatus(403)
+	client.NewRequest("GET", download+"&disposition=attachment").Do().CheckStatus(403)
+	client.NewRequest("GET", expired).Do().CheckStatus(403).CheckBodyContainString("expired")
+
+	// 轮换密钥，旧密钥签名的URL仍然有效，删除旧密钥后无效。
+	app.Set("signedurl.secrets", []string{"secret-v2", "secret-v1"})
+	client.NewRequest("GET", download).Do().CheckStatus(200)
+	app.Set("signedurl.secrets", []string{"secret-v2"})
+	client.NewRequest("GET", download).Do().CheckStatus(403)
+	client.NewRequest("POST", "/sign?method=GET&name=report.pdf").Do().CheckStatus(200)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -22,6 +22,7 @@ func main() {
 	})
 	app.AnyFunc("/*", func(ctx eudore.Context) {
 		time.Sleep(time.Second / 3)
+		ctx.SetHeader("X-Single-Flight", "true")
 		ctx.WriteString("hello eudore")
 	})
 
@@ -30,7 +31,7 @@ func main() {
 	wg.Add(10)
 	for i := 0; i < 10; i++ {
 		go func(i int) {
-			client.NewRequest("GET", "/?c="+fmt.Sprint(i)).Do().CheckBodyString("hello eudore")
+			client.NewRequest("GET", "/?c="+fmt.Sprint(i%2)).Do().CheckHeader("X-Single-Flight", "true").CheckBodyString("hello eudore")
 			wg.Done()
 		}(i)
 	}
@@ -42,4 +43,38 @@ func main() {
 	app.Listen(":8088")
 	// app.CancelFunc()
 	app.Run()
+
+	middlewareSingleFlight2()
+}
+
+func middlewareSingleFlight2() {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewSingleFlightFunc(func(ctx eudore.Context) string {
+		return ctx.Path()
+	}))
+	app.AnyFunc("/*", func(ctx eudore.Context) {
+		time.Sleep(time.Second / 10)
+		ctx.WriteString("hello " + ctx.GetQuery("name"))
+	})
+	app.AnyFunc("/panic", func(ctx eudore.Context) {
+		time.Sleep(time.Second / 10)
+		panic("single flight panic")
+	})
+	app.AddMiddleware("global", middleware.NewRecoverFunc())
+
+	client := httptest.NewClient(app)
+	wg := sync.WaitGroup{}
+	wg.Add(4)
+	for i := 0; i < 4; i++ {
+		go func(i int) {
+			client.NewRequest("GET", "/panic").Do().CheckStatus(500)
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+	client.NewRequest("GET", "/?name=eudore").Do().CheckBodyString("hello eudore")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
 }
@@ -0,0 +1,38 @@
+package main
+
+/*
+Slow检测慢请求，请求处理时间超过阈值时输出Warning日志，包含路由、耗时和状态码，
+bool选项为true时在请求处理到达阈值时采样处理协程的栈，用于定位生产环境的延迟来源。
+*/
+
+import (
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewSlowFunc(100*time.Millisecond, true))
+	app.GetFunc("/fast", func(ctx eudore.Context) {
+		ctx.WriteString("fast")
+	})
+	app.GetFunc("/slow/:id", func(ctx eudore.Context) {
+		querySlowDatabase()
+		ctx.WriteString("slow")
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/fast").Do().CheckStatus(200)
+	client.NewRequest("GET", "/slow/1").Do().CheckStatus(200)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
+
+func querySlowDatabase() {
+	time.Sleep(200 * time.Millisecond)
+}
@@ -0,0 +1,93 @@
+package main
+
+/*
+TOTP实现RFC 6238基于时间的一次性密码，用于敏感路由组的二次验证。
+
+NewTOTPSecret()                          生成base32密钥
+NewTOTPURI(issuer, account, secret)      生成otpauth配置URI，生成二维码后使用身份验证器扫描
+GetTOTPCode(secret, time)                计算验证码
+VerifyTOTPCode(secret, code, time, skew) 校验验证码
+
+NewTOTPFunc使用OIDC的会话存储和会话Cookie，注入POST /totp/verify验证路由，
+验证通过后当前会话在Expires时间内可以访问敏感路由组，用户连续验证失败MaxAttempts次后锁定Lockout时间。
+*/
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	// RFC 6238测试向量，ascii密钥"12345678901234567890"在59秒的验证码。
+	code, _ := middleware.GetTOTPCode("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", time.Unix(59, 0))
+	if code != "287082" {
+		panic("totp code invalid: " + code)
+	}
+
+	secret, err := middleware.NewTOTPSecret()
+	if err != nil {
+		panic(err)
+	}
+	secrets := map[string]string{"alice": secret}
+	app := eudore.NewApp()
+	app.Info(middleware.NewTOTPURI("eudore", "alice", secrets["alice"]))
+
+	// 模拟OIDC登录建立的会话，实际使用OIDC.Store，会话id为32字节随机数的base64url编码。
+	alice, bob := newTOTPSessionID("alice"), newTOTPSessionID("bob")
+	store := middleware.NewOIDCSessionStoreMemory()
+	store.Set(alice, map[string]interface{}{"sub": "alice"}, time.Hour)
+	store.Set(bob, map[string]interface{}{"sub": "bob"}, time.Hour)
+
+	admin := app.Group("/admin")
+	admin.AddMiddleware(middleware.NewTOTPFunc(app, store, func(_ context.Context, user string) (string, error) {
+		return secrets[user], nil
+	}))
+	admin.GetFunc("/users", func(ctx eudore.Context) {
+		ctx.WriteString("admin users")
+	})
+	app.GetFunc("/profile", func(ctx eudore.Context) {
+		ctx.WriteString("profile")
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/admin/users").Do().CheckStatus(401)
+	client.NewRequest("GET", "/profile").WithHeaderValue(eudore.HeaderCookie, "_oidc="+alice).Do().CheckStatus(200)
+	client.NewRequest("GET", "/admin/users").WithHeaderValue(eudore.HeaderCookie, "_oidc="+alice).Do().CheckStatus(403).CheckBodyContainString("required")
+	client.NewRequest("POST", "/totp/verify").WithBodyJSONValue("code", "000000").WithHeaderValue(eudore.HeaderCookie, "_oidc="+alice).Do().CheckStatus(403)
+
+	code, _ = middleware.GetTOTPCode(secrets["alice"], time.Now())
+	client.NewRequest("POST", "/totp/verify").WithBodyJSONValue("code", code).WithHeaderValue(eudore.HeaderCookie, "_oidc="+alice).Do().CheckStatus(200)
+	client.NewRequest("GET", "/admin/users").WithHeaderValue(eudore.HeaderCookie, "_oidc="+alice).Do().CheckStatus(200).CheckBodyString("admin users")
+	// 验证码只能使用一次。
+	client.NewRequest("POST", "/totp/verify").WithBodyJSONValue("code", code).WithHeaderValue(eudore.HeaderCookie, "_oidc="+alice).Do().CheckStatus(403).CheckBodyContainString("invalid")
+
+	// 连续失败5次后锁定用户，正确的验证码也返回429。
+	for i := 0; i < 3; i++ {
+		client.NewRequest("POST", "/totp/verify").WithBodyJSONValue("code", "000000").WithHeaderValue(eudore.HeaderCookie, "_oidc="+alice).Do().CheckStatus(403)
+	}
+	client.NewRequest("POST", "/totp/verify").WithBodyJSONValue("code", "000000").WithHeaderValue(eudore.HeaderCookie, "_oidc="+alice).Do().CheckStatus(429)
+	code, _ = middleware.GetTOTPCode(secrets["alice"], time.Now().Add(30*time.Second))
+	client.NewRequest("POST", "/totp/verify").WithBodyJSONValue("code", code).WithHeaderValue(eudore.HeaderCookie, "_oidc="+alice).Do().CheckStatus(429)
+
+	// bob未启用二次验证。
+	client.NewRequest("POST", "/totp/verify").WithBodyJSONValue("code", code).WithHeaderValue(eudore.HeaderCookie, "_oidc="+bob).Do().CheckStatus(403).CheckBodyContainString("enrolled")
+	client.NewRequest("GET", "/admin/users").WithHeaderValue(eudore.HeaderCookie, "_oidc="+bob).Do().CheckStatus(403)
+	// Cookie无法读取Store中的二次验证状态。
+	client.NewRequest("GET", "/admin/users").WithHeaderValue(eudore.HeaderCookie, "_oidc=totp-step:alice").Do().CheckStatus(401)
+	client.NewRequest("GET", "/admin/users").WithHeaderValue(eudore.HeaderCookie, "_oidc=totp:"+alice).Do().CheckStatus(401)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
+
+func newTOTPSessionID(name string) string {
+	id := sha256.Sum256([]byte(name))
+	return base64.RawURLEncoding.EncodeToString(id[:])
+}
@@ -0,0 +1,62 @@
+package main
+
+/*
+Tenant实现多租户解析，依次使用子域名、X-Tenant-Id Header、路由参数解析租户，租户保存在请求Context中。
+
+中间件设置tenant参数和日志tenant属性，NewLoggerFunc指定tenant参数后访问日志按租户输出。
+
+租户配置从Config读取tenant.<name>.<key>，不存在时读取<key>，Tenant.NewRateFunc按租户限流，限流参数读取租户配置。
+*/
+
+import (
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.Set("title", "eudore")
+	app.Set("tenant.acme.title", "Acme Corp")
+	app.Set("tenant.acme.rate.speed", 1)
+	app.Set("tenant.acme.rate.max", 2)
+
+	tenants := map[string]bool{"acme": true, "globex": true}
+	tenant := middleware.NewTenant(
+		middleware.NewTenantSubdomain("example.com"),
+		middleware.NewTenantHeader(middleware.HeaderXTenantID),
+		middleware.NewTenantParam("tenant"),
+		func(name string) bool { return tenants[name] },
+		app.Config,
+	)
+	app.AddMiddleware(
+		middleware.NewLoggerFunc(app, "tenant"),
+		tenant.HandleHTTP,
+		tenant.NewRateFunc(10, 100, app.Context, time.Minute),
+	)
+	app.GetFunc("/t/:tenant/*", handleTitle)
+	app.GetFunc("/*", handleTitle)
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/").Do().CheckStatus(400)
+	client.NewRequest("GET", "/").WithHeaderValue("Host", "globex.example.com").Do().CheckStatus(200).CheckBodyString("globex: eudore")
+	client.NewRequest("GET", "/").WithHeaderValue(middleware.HeaderXTenantID, "initech").Do().CheckStatus(404)
+	client.NewRequest("GET", "/t/globex/index").Do().CheckStatus(200).CheckBodyString("globex: eudore")
+	// acme租户配置覆盖标题和限流参数，每分钟产生1个令牌，令牌桶容量为2
+	client.NewRequest("GET", "/").WithHeaderValue(middleware.HeaderXTenantID, "acme").Do().CheckStatus(200).CheckBodyString("acme: Acme Corp")
+	client.NewRequest("GET", "/").WithHeaderValue(middleware.HeaderXTenantID, "acme").Do().CheckStatus(200)
+	client.NewRequest("GET", "/").WithHeaderValue(middleware.HeaderXTenantID, "acme").Do().CheckStatus(200)
+	client.NewRequest("GET", "/").WithHeaderValue(middleware.HeaderXTenantID, "acme").Do().CheckStatus(429)
+	client.NewRequest("GET", "/").WithHeaderValue(middleware.HeaderXTenantID, "globex").Do().CheckStatus(200)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
+
+func handleTitle(ctx eudore.Context) {
+	ctx.Info("tenant request")
+	ctx.WriteString(middleware.GetTenant(ctx) + ": " + eudore.GetString(middleware.GetTenantValue(ctx, "title")))
+}
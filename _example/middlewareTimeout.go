@@ -1,11 +1,13 @@
 package main
 
 /*
-Eudore.Context实现并发安全需要对几乎所有操作加锁，成本太大使用http.TimeoutHandler仅对全局进行超时限制。
+Timeout设置请求超时时间，超时时间按路由参数timeout、组路由参数timeout、全局timeout的顺序生效，timeout=0表示不限制，
+运维可以通过路由参数调整热点接口的超时时间，路由参数可以在/eudore/debug/router/data查看。
+
+超时后取消请求context，如果未写入响应返回503，并丢弃处理函数后续的写入。
 */
 
 import (
-	"net/http"
 	"time"
 
 	"github.com/eudore/eudore"
@@ -15,42 +17,34 @@ import (
 
 func main() {
 	app := eudore.NewApp()
-	app.SetHandler(http.TimeoutHandler(app, 3*time.Second/10, ""))
-	app.AddMiddleware(
-		middleware.NewLoggerFunc(app, "route"),
-		middleware.NewRecoverFunc(),
-		// middleware.NewTimeoutFunc(3*time.Second/10),
-	)
-	app.AnyFunc("/*", func(ctx eudore.ContextData) {
-		time.Sleep(time.Duration(ctx.GetParamInt64("*")) * time.Second / 10)
-		ctx.WriteString("hello")
-		ctx.WriteString("eudore")
-	})
-	app.AnyFunc("/h/*", func(ctx eudore.ContextData) {
-		time.Sleep(time.Duration(ctx.GetParamInt64("*")) * time.Second / 10)
-		ctx.SetHeader("X-MetaData", "timeout")
-		ctx.WriteHeader(200)
-		ctx.WriteString("hello")
-		ctx.WriteString("eudore")
-	})
-	app.AnyFunc("/panic", func(ctx eudore.Context) {
-		time.Sleep(time.Second / 10)
-		var n int
-		ctx.Debug(11 / n)
-	})
-
-	client := httptest.NewClient(http.TimeoutHandler(app, 3*time.Second/10, ""))
-	client.NewRequest("PUT", "/1").Do().CheckStatus(200)
-	client.NewRequest("PUT", "/2").Do().CheckStatus(200)
-	client.NewRequest("PUT", "/4").Do().CheckStatus(503)
-	client.NewRequest("PUT", "/5").Do().CheckStatus(503)
-	client.NewRequest("PUT", "/h/1").Do().CheckStatus(200)
-	client.NewRequest("PUT", "/h/2").Do().CheckStatus(200)
-	client.NewRequest("PUT", "/h/4").Do().CheckStatus(503)
-	client.NewRequest("PUT", "/h/5").Do().CheckStatus(503)
-	client.NewRequest("PUT", "/panic").Do().CheckStatus(503)
+	app.AddMiddleware(middleware.NewLoggerFunc(app, "route", "timeout"))
+	app.AddMiddleware(middleware.NewTimeoutFunc(300 * time.Millisecond))
+
+	app.GetFunc("/sleep/:ms", sleep)
+	api := app.Group("/api timeout=100ms")
+	api.GetFunc("/sleep/:ms", sleep)
+	api.GetFunc("/export/:ms timeout=500ms", sleep)
+	api.GetFunc("/stream/:ms timeout=0", sleep)
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/sleep/50").Do().CheckStatus(200).CheckBodyString("done")
+	client.NewRequest("GET", "/sleep/400").Do().CheckStatus(503)
+	client.NewRequest("GET", "/api/sleep/50").Do().CheckStatus(200)
+	client.NewRequest("GET", "/api/sleep/200").Do().CheckStatus(503)
+	client.NewRequest("GET", "/api/export/400").Do().CheckStatus(200).CheckHeader("X-Sleep", "400")
+	client.NewRequest("GET", "/api/stream/600").Do().CheckStatus(200)
 
 	app.Listen(":8088")
 	// app.CancelFunc()
 	app.Run()
 }
+
+func sleep(ctx eudore.Context) {
+	ctx.SetHeader("X-Sleep", ctx.GetParam("ms"))
+	select {
+	case <-time.After(time.Duration(eudore.GetStringInt(ctx.GetParam("ms"))) * time.Millisecond):
+		ctx.WriteString("done")
+	case <-ctx.GetContext().Done():
+		ctx.WriteString("cancel")
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+/*
+Timing记录后续每个中间件和路由处理函数自身的执行耗时，用于定位请求链中增加延迟的处理函数，
+耗时在响应首次写入时设置到Server-Timing header，浏览器开发者工具的Timing面板可以直接查看；
+请求处理完成后设置路由参数timing，访问日志使用NewLoggerFunc(app, "timing")输出。
+*/
+
+import (
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewLoggerFunc(app, "route", "timing"))
+	app.AddMiddleware(middleware.NewTimingFunc())
+	app.AddMiddleware(func(ctx eudore.Context) {
+		time.Sleep(20 * time.Millisecond)
+	})
+	app.GetFunc("/user/:id", func(ctx eudore.Context) {
+		time.Sleep(50 * time.Millisecond)
+		ctx.WriteString("user " + ctx.GetParam("id"))
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/user/1").Do().CheckStatus(200).OutHeader()
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
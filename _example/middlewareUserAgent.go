@@ -0,0 +1,49 @@
+package main
+
+/*
+UserAgent解析请求User-Agent的浏览器、操作系统和设备类型，解析结果按User-Agent缓存。
+
+处理函数使用middleware.GetUserAgent获取解析结果用于内容适配，
+中间件同时设置browser、os、device参数，NewLoggerFunc指定参数名称后输出到访问日志。
+*/
+
+import (
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware(
+		middleware.NewUserAgentFunc(),
+		middleware.NewLoggerFunc(app, "browser", "os", "device"),
+	)
+	app.GetFunc("/*", func(ctx eudore.Context) interface{} {
+		ua := middleware.GetUserAgent(ctx)
+		if ua.Device == middleware.DeviceMobile {
+			return "mobile page " + ua.Browser
+		}
+		return ua
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/").WithHeaderValue(eudore.HeaderUserAgent,
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Safari/537.36 Edg/118.0.2088.46",
+	).WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(200).
+		CheckBodyContainString(`"browser":"Edge"`, `"os":"Windows"`, `"osVersion":"10"`, `"device":"desktop"`)
+	client.NewRequest("GET", "/").WithHeaderValue(eudore.HeaderUserAgent,
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 16_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.6 Mobile/15E148 Safari/604.1",
+	).Do().CheckStatus(200).CheckBodyContainString("mobile page Safari")
+	client.NewRequest("GET", "/").WithHeaderValue(eudore.HeaderUserAgent,
+		"Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/116.0.0.0 Safari/537.36",
+	).WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(200).CheckBodyContainString(`"device":"tablet"`)
+	client.NewRequest("GET", "/").WithHeaderValue(eudore.HeaderUserAgent,
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+	).WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(200).
+		CheckBodyContainString(`"browser":"Googlebot"`, `"browserVersion":"2.1"`, `"device":"bot"`)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,63 @@
+package main
+
+/*
+eudore与net/http双向兼容：
+
+- http.Handler、http.HandlerFunc和func(http.ResponseWriter, *http.Request)可以直接注册为eudore处理函数，
+  使用eudore.GetRequestContext(r)获取eudore.Context，eudore.GetRequestParam(r, key)获取路由参数，go1.22及以上版本也可以使用r.PathValue(key)。
+- func(http.Handler) http.Handler类型的net/http中间件(negroni、alice等)可以直接注册为eudore中间件，
+  中间件修改的请求和响应在后续处理函数中生效，未调用next时结束请求处理；其他命名类型需要转换成func(http.Handler) http.Handler。
+- eudore.App实现http.Handler接口，可以挂载到其他路由中使用。
+*/
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+type ctxKey struct{}
+
+func main() {
+	app := eudore.NewApp()
+	// net/http中间件：设置header、context值并校验token。
+	app.AddMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Middleware", "nethttp")
+			if r.Header.Get("X-Token") == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ctxKey{}, r.Header.Get("X-Token"))))
+		})
+	})
+	app.GetFunc("/users/:id", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := eudore.GetRequestContext(r)
+		w.Write([]byte("user " + eudore.GetRequestParam(r, "id") + " route " + ctx.GetParam(eudore.ParamRoute)))
+	}))
+	app.GetFunc("/token", func(ctx eudore.Context) {
+		ctx.WriteString(ctx.GetContext().Value(ctxKey{}).(string))
+	})
+
+	// App挂载到net/http ServeMux。
+	mux := http.NewServeMux()
+	mux.Handle("/api/", http.StripPrefix("/api", app))
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/users/1").Do().CheckStatus(401).CheckHeader("X-Middleware", "nethttp")
+	client.NewRequest("GET", "/users/1").WithHeaderValue("X-Token", "t1").Do().CheckStatus(200).CheckBodyString("user 1 route /users/:id")
+	client.NewRequest("GET", "/token").WithHeaderValue("X-Token", "t1").Do().CheckStatus(200).CheckBodyString("t1")
+
+	client = httptest.NewClient(mux)
+	client.NewRequest("GET", "/api/users/2").WithHeaderValue("X-Token", "t1").Do().CheckStatus(200).CheckBodyString("user 2 route /users/:id")
+	client.NewRequest("GET", "/health").Do().CheckStatus(200).CheckBodyString("ok")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,59 @@
+package main
+
+/*
+Abac使用请求属性表达式定义规则，规则在加载时编译，可以从配置中加载。
+Pbac策略也可以使用expr条件。
+*/
+
+import (
+	"fmt"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/component/ram"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	abac := ram.NewAbac()
+	err := abac.AddRuleStringJSON(`[
+		{"name":"deny-ip","effect":false,"expression":"ip in ['10.0.0.1', '10.0.0.2']"},
+		{"name":"admin","effect":true,"action":["*"],"expression":"header.X-Role == 'admin'"},
+		{"name":"tenant","effect":true,"action":["tenant:*"],"expression":"method == 'GET' && param.tenant == header.X-Tenant"},
+		{"name":"level","effect":true,"action":["level"],"expression":"header.X-Level >= 3 && !(path matches '^/level/private')"}
+	]`)
+	if err != nil {
+		panic(err)
+	}
+	// 编译错误在加载时返回
+	fmt.Println(abac.AddRule(&ram.AbacRule{Name: "err", Expression: "method == "}))
+	fmt.Println(abac.AddRule(&ram.AbacRule{Name: "err", Expression: "unknown == 1"}))
+
+	pbac := ram.NewPbac()
+	pbac.AddPolicyStringJSON(1, `{"version":"1","description":"expr","statement":[{"effect":true,"action":["*"],"resource":["*"],"conditions":{"expr":"query.key == 'eudore'"}}]}`)
+	pbac.BindPolicy(0, 0, 1)
+	// 策略的expr条件编译错误在加载时返回
+	fmt.Println(pbac.AddPolicyStringJSON(2, `{"version":"1","statement":[{"effect":false,"action":["*"],"resource":["*"],"conditions":{"expr":"method =="}}]}`))
+
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewLoggerFunc(app, "action", "ram", "policy"))
+	app.AddMiddleware(ram.NewMiddleware(abac, pbac))
+	app.GetFunc("/tenant/:tenant action=tenant:get", eudore.HandlerEmpty)
+	app.GetFunc("/level/* action=level", eudore.HandlerEmpty)
+	app.AnyFunc("/* action=hello", eudore.HandlerEmpty)
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/hello").Do().CheckStatus(403)
+	client.NewRequest("GET", "/hello").WithHeaderValue("X-Role", "admin").Do().CheckStatus(200)
+	client.NewRequest("GET", "/hello").WithHeaderValue("X-Role", "admin").WithHeaderValue(eudore.HeaderXForwardedFor, "10.0.0.1").Do().CheckStatus(403)
+	client.NewRequest("GET", "/tenant/t1").WithHeaderValue("X-Tenant", "t1").Do().CheckStatus(200)
+	client.NewRequest("GET", "/tenant/t1").WithHeaderValue("X-Tenant", "t2").Do().CheckStatus(403)
+	client.NewRequest("GET", "/level/public").WithHeaderValue("X-Level", "3").Do().CheckStatus(200)
+	client.NewRequest("GET", "/level/public").WithHeaderValue("X-Level", "2").Do().CheckStatus(403)
+	client.NewRequest("GET", "/level/private").WithHeaderValue("X-Level", "5").Do().CheckStatus(403)
+	client.NewRequest("GET", "/hello?key=eudore").Do().CheckStatus(200)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
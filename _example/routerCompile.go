@@ -0,0 +1,45 @@
+package main
+
+/*
+RouterStd.Compile 将注册路由器的中间件展开合并到每个路由的处理链并按注册顺序重新注册，
+使路由注册后添加的中间件也会生效，返回全部路由的完整处理链用于查看。
+
+需要在app开始处理请求前调用，Group后上级路由器添加的中间件不会被组路由器继承。
+*/
+
+import (
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.GetFunc("/*", compileHandler)
+	api := app.Group("/api")
+	api.GetFunc("/user", compileHandler)
+
+	// 路由注册后添加的中间件，编译前不会生效。
+	app.AddMiddleware(func(ctx eudore.Context) {
+		ctx.WriteString("global ")
+	})
+	api.AddMiddleware(func(ctx eudore.Context) {
+		ctx.WriteString("api ")
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/api/user").Do().CheckBodyString("handler")
+
+	for _, route := range app.Router.(*eudore.RouterStd).Compile() {
+		app.Info(route.Method, route.Path, route.HandlerNames)
+	}
+	client.NewRequest("GET", "/").Do().CheckBodyString("global handler")
+	client.NewRequest("GET", "/api/user").Do().CheckBodyString("api handler")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
+
+func compileHandler(ctx eudore.Context) {
+	ctx.WriteString("handler")
+}
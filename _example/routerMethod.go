@@ -0,0 +1,45 @@
+package main
+
+/*
+路由器可以注册eudore.RouterCustomMethods定义的自定义方法，例如PURGE、REPORT、MKCOL，用于WebDAV和缓存清除等场景；
+未定义的方法注册时返回错误，避免方法名称拼写错误。
+
+Any方法默认注册RouterAllMethod定义的方法，未注册的请求方法返回405；
+设置eudore.RouterAnyMethod = true后，RouterAllMethod以外的方法未匹配时使用Any方法注册的路由匹配。
+*/
+
+import (
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	eudore.RouterAnyMethod = true
+	eudore.RouterCustomMethods = []string{"PURGE", "PROPFIND", "MKCOL"}
+	app := eudore.NewApp()
+	app.AddHandler("PURGE", "/cache/*", func(ctx eudore.Context) {
+		ctx.WriteString("purge " + ctx.GetParam("*"))
+	})
+	app.AddHandler("PROPFIND,MKCOL", "/dav/*", func(ctx eudore.Context) {
+		ctx.WriteString(ctx.Method() + " " + ctx.GetParam("*"))
+	})
+	if err := app.AddHandler("GTE", "/index", eudore.HandlerEmpty); err == nil {
+		panic("register undefined method GTE")
+	}
+	app.AnyFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteString("any " + ctx.Method())
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("PURGE", "/cache/index.html").Do().CheckStatus(200).CheckBodyString("purge index.html")
+	client.NewRequest("MKCOL", "/dav/docs").Do().CheckStatus(200).CheckBodyString("MKCOL docs")
+	client.NewRequest("PROPFIND", "/dav/").Do().CheckStatus(200).CheckBodyString("PROPFIND ")
+	client.NewRequest("GET", "/dav/docs").Do().CheckStatus(200).CheckBodyString("any GET")
+	client.NewRequest("PURGE", "/index").Do().CheckStatus(200).CheckBodyString("any PURGE")
+	client.NewRequest("REPORT", "/index").Do().CheckStatus(200).CheckBodyString("any REPORT")
+	client.NewRequest("OPTIONS", "/index").Do().CheckStatus(200).CheckBodyString("any OPTIONS")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,57 @@
+package main
+
+/*
+Router.AddMiddleware 在路径参数后可以指定int类型的优先级，优先级高的中间件先执行，相同优先级按添加顺序执行，默认优先级为0。
+
+middleware.NewWhenFunc 按条件执行中间件，可以组合路径、方法、Header条件。
+
+RouterStd.GetMiddlewares 返回路径实际生效的中间件链。
+*/
+
+import (
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware("/api/", func(ctx eudore.Context) {
+		ctx.WriteString("api ")
+	})
+	// 后添加但优先级更高，在/api/中间件之前执行。
+	app.AddMiddleware(10, func(ctx eudore.Context) {
+		ctx.WriteString("first ")
+	})
+	app.AddMiddleware("/api/", -10, func(ctx eudore.Context) {
+		ctx.WriteString("last ")
+	})
+	// 仅对写请求且携带X-Debug的请求执行。
+	app.AddMiddleware(middleware.NewWhenFunc(middleware.WhenAll(
+		middleware.WhenMethod("POST", "PUT", "DELETE"),
+		middleware.WhenHeader("X-Debug"),
+		middleware.WhenNot(middleware.WhenPath("/api/public/*")),
+	), func(ctx eudore.Context) {
+		ctx.WriteString("debug ")
+	}))
+	app.AnyFunc("/*", handler)
+	// 中间件在注册路由时按路由路径匹配。
+	app.AnyFunc("/api/*", handler)
+
+	app.Info("api middlewares:", app.Router.(*eudore.RouterStd).GetMiddlewares("/api/user"))
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/").Do().CheckBodyString("first handler")
+	client.NewRequest("GET", "/api/user").Do().CheckBodyString("first api last handler")
+	client.NewRequest("POST", "/api/user").Do().CheckBodyString("first api last handler")
+	client.NewRequest("POST", "/api/user").WithHeaderValue("X-Debug", "1").Do().CheckBodyString("first api debug last handler")
+	client.NewRequest("POST", "/api/public/user").WithHeaderValue("X-Debug", "1").Do().CheckBodyString("first api last handler")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
+
+func handler(ctx eudore.Context) {
+	ctx.WriteString("handler")
+}
@@ -0,0 +1,54 @@
+package main
+
+/*
+组路由可以注册404和405处理，请求未匹配路由时按路径前缀使用最近组路由的处理，未注册的组路由使用上级组路由或全局处理。
+
+405处理用于不支持的请求方法，组路由的404和405处理会使用组路由的中间件和参数。
+*/
+
+import (
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddHandler("404", "", func(ctx eudore.Context) {
+		ctx.SetHeader(eudore.HeaderContentType, eudore.MimeTextHTMLCharsetUtf8)
+		ctx.WriteHeader(eudore.StatusNotFound)
+		ctx.WriteString("<h1>404 page not found</h1>")
+	})
+	app.GetFunc("/", func(ctx eudore.Context) {
+		ctx.WriteString("index")
+	})
+
+	api := app.Group("/api")
+	api.AddHandler("404", "", func(ctx eudore.Context) {
+		ctx.WriteHeader(eudore.StatusNotFound)
+		ctx.Render(map[string]interface{}{"code": 404, "path": ctx.Path(), "route": ctx.GetParam(eudore.ParamRoute)})
+	})
+	api.AddHandler("405", "", func(ctx eudore.Context) {
+		ctx.WriteHeader(eudore.StatusMethodNotAllowed)
+		ctx.Render(map[string]interface{}{"code": 405, "method": ctx.Method()})
+	})
+	api.GetFunc("/user", func(ctx eudore.Context) {
+		ctx.WriteString("user")
+	})
+	// 没有注册404处理的组路由使用上级/api的处理。
+	app.Group("/api/v1").GetFunc("/user", func(ctx eudore.Context) {
+		ctx.WriteString("v1 user")
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/none").Do().CheckStatus(404).CheckBodyContainString("<h1>")
+	client.NewRequest("GET", "/apis").Do().CheckStatus(404).CheckBodyContainString("<h1>")
+	client.NewRequest("GET", "/api").Do().CheckStatus(404).CheckBodyContainString(`"code":404`)
+	client.NewRequest("GET", "/api/none").WithHeaderValue(eudore.HeaderAccept, eudore.MimeApplicationJSON).Do().CheckStatus(404).CheckBodyContainString(`"code":404`)
+	client.NewRequest("GET", "/api/v1/none").Do().CheckStatus(404).CheckBodyContainString(`"path":"/api/v1/none"`)
+	client.NewRequest("LOCK", "/api/user").Do().CheckStatus(405).CheckBodyContainString(`"method":"LOCK"`)
+	client.NewRequest("LOCK", "/user").Do().CheckStatus(405).CheckBodyContainString("405 method not allowed")
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
@@ -0,0 +1,92 @@
+package main
+
+/*
+路由路径中空格后的key=value参数会添加到路由参数中，通用中间件可以读取路由参数实现按路由声明式配置。
+
+Params和ContextData提供类型转换方法：
+GetString GetStrings GetBool GetInt GetInt64 GetFloat64 GetDuration
+*/
+
+import (
+	"context"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/component/httptest"
+	"github.com/eudore/eudore/middleware"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AddMiddleware(middleware.NewLoggerFunc(app, "route", "role", "timeout", "cache"))
+	app.AddMiddleware(newRoleFunc(), newTimeoutFunc(), newCacheFunc())
+
+	app.GetFunc("/index", func(ctx eudore.Context) {
+		ctx.WriteString("index")
+	})
+	app.GetFunc("/admin role=admin,editor timeout=5s cache=30s", func(ctx eudore.Context) {
+		deadline, _ := ctx.GetContext().Deadline()
+		ctx.WriteString("admin timeout " + time.Until(deadline).Round(time.Second).String())
+	})
+	app.GetFunc("/slow timeout=1", func(ctx eudore.Context) {
+		select {
+		case <-ctx.GetContext().Done():
+			ctx.WriteHeader(eudore.StatusServiceUnavailable)
+			ctx.WriteString("timeout")
+		case <-time.After(3 * time.Second):
+			ctx.WriteString("slow")
+		}
+	})
+
+	client := httptest.NewClient(app)
+	client.NewRequest("GET", "/index").Do().CheckStatus(200).CheckHeader(eudore.HeaderCacheControl, "")
+	client.NewRequest("GET", "/admin").Do().CheckStatus(403)
+	client.NewRequest("GET", "/admin").WithHeaderValue("X-Role", "editor").Do().CheckStatus(200).CheckHeader(eudore.HeaderCacheControl, "max-age=30").CheckBodyString("admin timeout 5s")
+	client.NewRequest("GET", "/slow").Do().CheckStatus(503)
+
+	app.Listen(":8088")
+	// app.CancelFunc()
+	app.Run()
+}
+
+// newRoleFunc 函数检查路由参数role允许的角色。
+func newRoleFunc() eudore.HandlerFunc {
+	return func(ctx eudore.Context) {
+		roles := ctx.Params().GetStrings("role")
+		if roles == nil {
+			return
+		}
+		role := ctx.GetHeader("X-Role")
+		for _, r := range roles {
+			if r == role {
+				return
+			}
+		}
+		ctx.WriteHeader(eudore.StatusForbidden)
+		ctx.Fatal("role not allow")
+		ctx.End()
+	}
+}
+
+// newTimeoutFunc 函数按路由参数timeout设置请求超时。
+func newTimeoutFunc() eudore.HandlerFunc {
+	return func(ctx eudore.Context) {
+		timeout := ctx.Params().GetDuration("timeout")
+		if timeout > 0 {
+			c, cancel := context.WithTimeout(ctx.GetContext(), timeout)
+			defer cancel()
+			ctx.WithContext(c)
+		}
+		ctx.Next()
+	}
+}
+
+// newCacheFunc 函数按路由参数cache设置Cache-Control。
+func newCacheFunc() eudore.HandlerFunc {
+	return func(ctx eudore.Context) {
+		cache := eudore.ContextData{Context: ctx}.GetParamDuration("cache")
+		if cache > 0 {
+			ctx.SetHeader(eudore.HeaderCacheControl, "max-age="+eudore.GetString(int(cache/time.Second)))
+		}
+	}
+}
@@ -96,3 +96,223 @@ func TestRouter2(t *testing.T) {
 	app.CancelFunc()
 	app.Run()
 }
+
+func TestRouterParamsOverride(t *testing.T) {
+	for name, router := range map[string]eudore.Router{"radix": eudore.NewRouterRadix(), "full": eudore.NewRouterFull()} {
+		app := eudore.NewApp(router)
+		handler := func(ctx eudore.Context) {
+			ctx.WriteString(ctx.GetParam("version") + " " + ctx.GetParam("scope"))
+		}
+		api := app.Group("/api version=v1 scope=api")
+		api.GetFunc("/user", handler)
+		// 下级组路由和路由的同名参数覆盖上级参数。
+		api.Group("/v2 version=v2").GetFunc("/user", handler)
+		api.GetFunc("/v3/user version=v3", handler)
+
+		client := httptest.NewClient(app)
+		for path, body := range map[string]string{
+			"/api/user":    "v1 api",
+			"/api/v2/user": "v2 api",
+			"/api/v3/user": "v3 api",
+		} {
+			resp := client.NewRequest("GET", path).Do()
+			if resp.Body.String() != body {
+				t.Errorf("%s params %s: %s, want %s", name, path, resp.Body.String(), body)
+			}
+		}
+	}
+}
+
+func TestRouterGroupFallback(t *testing.T) {
+	newHandler := func(name string, code int) eudore.HandlerFunc {
+		return func(ctx eudore.Context) {
+			ctx.WriteHeader(code)
+			ctx.WriteString(name + " " + ctx.GetParam(eudore.ParamRoute))
+		}
+	}
+	for name, router := range map[string]eudore.Router{"radix": eudore.NewRouterRadix(), "full": eudore.NewRouterFull()} {
+		app := eudore.NewApp(router)
+		app.AddHandler("404", "", newHandler("global", 404))
+		app.AddHandler("404", "/api", newHandler("api", 404))
+		app.AddHandler("404", "/api/v1", newHandler("v1", 404))
+		app.AddHandler("405", "/api", newHandler("api", 405))
+		app.GetFunc("/api/user", eudore.HandlerEmpty)
+
+		client := httptest.NewClient(app)
+		for _, c := range []struct {
+			method, path, body string
+			code               int
+		}{
+			{"GET", "/none", "global 404", 404},
+			// 前缀按路径段匹配，/apis不匹配/api。
+			{"GET", "/apis", "global 404", 404},
+			{"GET", "/api", "api 404", 404},
+			{"GET", "/api/none", "api 404", 404},
+			// 最长前缀优先匹配。
+			{"GET", "/api/v1/none", "v1 404", 404},
+			{"GET", "/api/v10", "api 404", 404},
+			{"LOCK", "/api/user", "api 405", 405},
+		} {
+			resp := client.NewRequest(c.method, c.path).Do()
+			if resp.Code != c.code || resp.Body.String() != c.body {
+				t.Errorf("%s fallback %s %s: %d %s, want %d %s", name, c.method, c.path, resp.Code, resp.Body.String(), c.code, c.body)
+			}
+		}
+
+		// 未注册组路由405处理的路径使用全局405处理，响应Allow Header。
+		resp := client.NewRequest("LOCK", "/user").Do()
+		if resp.Code != 405 || resp.HeaderMap.Get(eudore.HeaderAllow) == "" {
+			t.Errorf("%s global 405: %d Allow '%s'", name, resp.Code, resp.HeaderMap.Get(eudore.HeaderAllow))
+		}
+	}
+}
+
+func TestRouterCustomMethod(t *testing.T) {
+	defer func(anymethod bool, methods []string) {
+		eudore.RouterAnyMethod = anymethod
+		eudore.RouterCustomMethods = methods
+	}(eudore.RouterAnyMethod, eudore.RouterCustomMethods)
+	eudore.RouterCustomMethods = []string{"PURGE", "PROPFIND", "VERSION-CONTROL"}
+	for _, anymethod := range []bool{false, true} {
+		eudore.RouterAnyMethod = anymethod
+		for name, newRouter := range map[string]func() eudore.Router{"radix": eudore.NewRouterRadix, "full": eudore.NewRouterFull} {
+			app := eudore.NewApp(newRouter())
+			app.AddHandler("PURGE", "/cache/*", func(ctx eudore.Context) {
+				ctx.WriteString("purge " + ctx.GetParam("*"))
+			})
+			app.AddHandler("PROPFIND,VERSION-CONTROL", "/dav/*", func(ctx eudore.Context) {
+				ctx.WriteString(ctx.Method())
+			})
+			// 未定义在RouterCustomMethods的方法注册返回错误。
+			for _, method := range []string{"GTE", "REPORT"} {
+				if err := app.AddHandler(method, "/lower", eudore.HandlerEmpty); err == nil {
+					t.Errorf("%s register undefined method %s", name, method)
+				}
+			}
+			app.AnyFunc("/*", func(ctx eudore.Context) {
+				ctx.WriteString("any")
+			})
+
+			// 开启RouterAnyMethod后RouterAllMethod以外的方法未匹配时使用Any路由，Any路由未匹配时仍然返回405。
+			code404, body404 := 404, "404 page not found\n"
+			code405, body405 := 405, "405 method not allowed\n"
+			codeOptions, bodyOptions := 404, "404 page not found\n"
+			if anymethod {
+				code404, body404 = 200, "any"
+				code405, body405 = 200, "any"
+				codeOptions, bodyOptions = 200, "any"
+			}
+			client := httptest.NewClient(app)
+			for _, c := range []struct {
+				method, path, body string
+				code               int
+			}{
+				{"PURGE", "/cache/index.html", "purge index.html", 200},
+				{"PROPFIND", "/dav/docs", "PROPFIND", 200},
+				{"VERSION-CONTROL", "/dav/docs", "VERSION-CONTROL", 200},
+				{"GET", "/dav/docs", "any", 200},
+				{"PURGE", "/index", body404, code404},
+				{"REPORT", "/index", body405, code405},
+				{"GTE", "/lower", body405, code405},
+				{"OPTIONS", "/index", bodyOptions, codeOptions},
+			} {
+				resp := client.NewRequest(c.method, c.path).Do()
+				if resp.Code != c.code || resp.Body.String() != c.body {
+					t.Errorf("%s anymethod %t %s %s: %d %q, want %d %q", name, anymethod, c.method, c.path, resp.Code, resp.Body.String(), c.code, c.body)
+				}
+			}
+
+			// Any路由未匹配时仍然使用组路由注册的405处理。
+			app = eudore.NewApp(newRouter())
+			api := app.Group("/api")
+			api.AddHandler("405", "", func(ctx eudore.Context) {
+				ctx.WriteHeader(eudore.StatusMethodNotAllowed)
+				ctx.WriteString("api 405")
+			})
+			api.AnyFunc("/user", func(ctx eudore.Context) {
+				ctx.WriteString("user " + ctx.Method())
+			})
+			client = httptest.NewClient(app)
+			body := "api 405"
+			if anymethod {
+				body = "user PURGE"
+			}
+			for path, body := range map[string]string{"/api/user": body, "/api/info": "api 405"} {
+				resp := client.NewRequest("PURGE", path).Do()
+				if resp.Body.String() != body {
+					t.Errorf("%s anymethod %t PURGE %s: %q, want %q", name, anymethod, path, resp.Body.String(), body)
+				}
+			}
+		}
+	}
+}
+
+func TestRouterStdCompile(t *testing.T) {
+	newHandler := func(name string) eudore.HandlerFunc {
+		return func(ctx eudore.Context) {
+			ctx.WriteString(name)
+		}
+	}
+	app := eudore.NewApp()
+	app.GetFunc("/*", newHandler("index"))
+	api := app.Group("/api")
+	api.GetFunc("/user", newHandler("user"))
+	app.AddMiddleware(newHandler("global "))
+	api.AddMiddleware(newHandler("api "))
+
+	// 编译前路由注册后添加的中间件不生效。
+	client := httptest.NewClient(app)
+	if body := client.NewRequest("GET", "/api/user").Do().Body.String(); body != "user" {
+		t.Errorf("route before compile: %s", body)
+	}
+
+	routes := app.Router.(*eudore.RouterStd).Compile()
+	if len(routes) != 2 || len(routes[0].Handlers) != 2 || len(routes[1].Handlers) != 2 ||
+		len(routes[1].HandlerNames) != 2 {
+		t.Fatalf("compile routes: %v", routes)
+	}
+	for path, body := range map[string]string{
+		"/":         "global index",
+		"/api/user": "api user",
+	} {
+		if resp := client.NewRequest("GET", path).Do(); resp.Body.String() != body {
+			t.Errorf("route %s after compile: %s, want %s", path, resp.Body.String(), body)
+		}
+	}
+}
+
+func TestRouterMiddlewarePriorityOrder(t *testing.T) {
+	newHandler := func(name string) eudore.HandlerFunc {
+		return func(ctx eudore.Context) {
+			ctx.WriteString(name + " ")
+		}
+	}
+	app := eudore.NewApp()
+	app.AddMiddleware("/api/", newHandler("api"))
+	app.AddMiddleware(10, newHandler("first"))
+	app.AddMiddleware("/api/", -10, newHandler("last"))
+	app.AddMiddleware("/api/", 10, newHandler("second"))
+	app.AddMiddleware(middleware.NewWhenFunc(middleware.WhenAll(
+		middleware.WhenMethod("POST"),
+		middleware.WhenNot(middleware.WhenPath("/api/public/*")),
+	), newHandler("when")))
+	app.AnyFunc("/*", newHandler("index"))
+	app.AnyFunc("/api/*", newHandler("api"))
+
+	if hs := app.Router.(*eudore.RouterStd).GetMiddlewares("/api/user"); len(hs) != 5 {
+		t.Errorf("api middlewares: %v", hs)
+	}
+	client := httptest.NewClient(app)
+	for _, c := range []struct {
+		method, path, body string
+	}{
+		{"GET", "/", "first index "},
+		{"GET", "/api/user", "first second api last api "},
+		{"POST", "/api/user", "first second api when last api "},
+		{"POST", "/api/public/user", "first second api last api "},
+	} {
+		if body := client.NewRequest(c.method, c.path).Do().Body.String(); body != c.body {
+			t.Errorf("middleware %s %s: %s, want %s", c.method, c.path, body, c.body)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+/*
+ServerConnState使用http.Server.ConnState钩子记录连接状态计数，HandleHTTP方法返回累计计数和当前各状态连接数量。
+
+用于诊断连接泄漏和keep-alive行为，NewServerConnState和AddHook可以添加额外的连接状态钩子。
+*/
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+
+	"github.com/eudore/eudore"
+)
+
+func main() {
+	app := eudore.NewApp()
+	conns := eudore.NewServerConnState(func(conn net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			fmt.Println("conn closed:", conn.RemoteAddr())
+		}
+	})
+	eudore.Set(app.Server, "", eudore.ServerStdConfig{
+		ConnState: conns.ConnState,
+	})
+	app.GetFunc("/eudore/debug/conns", conns.HandleHTTP)
+	app.AnyFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteString("hello eudore")
+	})
+	app.Listen("127.0.0.1:8088")
+
+	client := &http.Client{Transport: &http.Transport{}}
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get("http://127.0.0.1:8088/")
+		if err == nil {
+			ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+	}
+	req, _ := http.NewRequest("GET", "http://127.0.0.1:8088/eudore/debug/conns", nil)
+	req.Header.Set(eudore.HeaderAccept, eudore.MimeApplicationJSON)
+	resp, err := client.Do(req)
+	if err == nil {
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		// 3个请求复用同一个keep-alive连接。
+		fmt.Println(string(body))
+	}
+	client.CloseIdleConnections()
+
+	// app.CancelFunc()
+	app.Run()
+}
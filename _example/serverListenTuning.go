@@ -0,0 +1,66 @@
+package main
+
+/*
+ServerListenConfig可以为每个监听设置tcp和server参数，可以使用ConvertTo从map或json配置创建。
+
+KeepAlive设置tcp keep-alive周期，负数关闭；DisableNoDelay关闭TCP_NODELAY；
+Server设置当前监听使用的ServerStdConfig，覆盖app.Server的非零配置，DisableKeepAlives关闭http keep-alive。
+*/
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/eudore/eudore"
+)
+
+func main() {
+	app := eudore.NewApp()
+	app.AnyFunc("/*", func(ctx eudore.Context) {
+		ctx.WriteString("hello eudore")
+	})
+
+	// 内网监听保持长连接。
+	internal := &eudore.ServerListenConfig{
+		Addr:      "127.0.0.1:8089",
+		KeepAlive: eudore.TimeDuration(30e9),
+		Server: &eudore.ServerStdConfig{
+			IdleTimeout: eudore.TimeDuration(300e9),
+		},
+	}
+	// 公网监听使用map配置，限制header读取时间和大小，关闭http keep-alive。
+	public := &eudore.ServerListenConfig{}
+	eudore.ConvertTo(map[string]interface{}{
+		"addr":           "127.0.0.1:8088",
+		"disablenodelay": true,
+		"server": map[string]interface{}{
+			"readheadertimeout": "2s",
+			"maxheaderbytes":    8192,
+			"disablekeepalives": true,
+		},
+	}, public)
+
+	for _, conf := range []*eudore.ServerListenConfig{internal, public} {
+		ln, err := conf.Listen()
+		if err != nil {
+			app.Error(err)
+			continue
+		}
+		app.Serve(ln)
+	}
+
+	for _, addr := range []string{"127.0.0.1:8088", "127.0.0.1:8089"} {
+		resp, err := http.Get("http://" + addr + "/")
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		fmt.Println(addr, resp.Close, string(body))
+	}
+
+	// app.CancelFunc()
+	app.Run()
+}
@@ -4,8 +4,14 @@ package eudore // import "github.com/eudore/eudore"
 
 import (
 	"context"
+	"fmt"
 	"net"
 	"net/http"
+	"os"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -25,9 +31,38 @@ type App struct {
 	Validater          `alias:"validater"`
 	GetWarp            `alias:"getwarp"`
 	HandlerFuncs       `alias:"handlerfuncs"`
-	ContextPool        sync.Pool `alias:"contextpool"`
-	CancelError        error     `alias:"cancelerror"`
+	ContextPool        sync.Pool     `alias:"contextpool"`
+	CancelError        error         `alias:"cancelerror"`
+	Scheduler          *Scheduler    `alias:"scheduler"`
+	TaskQueue          *TaskQueue    `alias:"taskqueue"`
+	EventBus           *EventBus     `alias:"eventbus"`
+	Cache              Cache         `alias:"cache"`
+	Features           *Features     `alias:"features"`
+	ContextDebug       *ContextDebug `alias:"contextdebug"`
 	cancelMutex        sync.Mutex
+	goWaitGroup        sync.WaitGroup
+	preflights         []appPreflight
+	preflightOnce      sync.Once
+	preflightError     error
+	initErrors         []error
+	listens            []string
+}
+
+// appInitError 定义组件初始化错误，由NewAppInitOption函数创建。
+type appInitError struct {
+	err error
+}
+
+// Error 方法返回组件初始化错误描述。
+func (err *appInitError) Error() string {
+	return fmt.Sprintf(ErrFormatAppInit, err.err)
+}
+
+// appPreflight 定义启动前检查函数。
+type appPreflight struct {
+	name    string
+	timeout time.Duration
+	fn      func(context.Context) error
 }
 
 // NewApp function creates an App object.
@@ -42,6 +77,8 @@ func NewApp(options ...interface{}) *App {
 		Binder:    BindDefault,
 		Renderer:  RenderDefault,
 		Validater: DefaultValidater,
+		Cache:     NewCacheMemory(),
+		Features:  NewFeatures(),
 	}
 	app.Context, app.CancelFunc = context.WithCancel(context.WithValue(context.Background(), AppContextKey, app))
 	app.Server.SetHandler(app)
@@ -55,12 +92,120 @@ func NewApp(options ...interface{}) *App {
 	return app
 }
 
-// Options method loads the app component. When the option type is context.Context, Logger, Config, Server, Router, Binder, Renderer, Validater, the app property will be set,
+// 定义AppOption执行阶段，NewApp按阶段顺序执行选项。
+const (
+	appOptionStageComponent = iota
+	appOptionStageConfig
+	appOptionStageMiddleware
+	appOptionStageListen
+)
+
+// AppOption defines a functional option of NewApp, the options are executed in the order of component, config file, middleware and listen.
+//
+// AppOption 定义NewApp函数选项，选项按组件、配置文件、中间件、监听的阶段顺序执行，与传入顺序无关；
+// 选项冲突或执行失败时作为组件初始化错误收集，在启动前合并报告。
+type AppOption struct {
+	stage int
+	name  string
+	fn    func(*App) error
+}
+
+// WithLogger function creates an AppOption that sets the app Logger.
+//
+// WithLogger 函数创建设置app Logger的选项，最先执行。
+func WithLogger(log Logger) AppOption {
+	return AppOption{appOptionStageComponent, "logger", func(app *App) error {
+		if log == nil {
+			return fmt.Errorf("WithLogger logger is nil")
+		}
+		app.Options(log)
+		return nil
+	}}
+}
+
+// WithConfig function creates an AppOption that sets the app Config.
+//
+// WithConfig 函数创建设置app Config的选项，在WithConfigFile前执行。
+func WithConfig(conf Config) AppOption {
+	return AppOption{appOptionStageComponent, "config", func(app *App) error {
+		if conf == nil {
+			return fmt.Errorf("WithConfig config is nil")
+		}
+		app.Options(conf)
+		return nil
+	}}
+}
+
+// WithConfigFile function creates an AppOption that loads the first existing json config file.
+//
+// WithConfigFile 函数创建加载json配置文件的选项，使用ConfigParseJSON加载第一个存在的文件，在中间件前执行。
+func WithConfigFile(paths ...string) AppOption {
+	return AppOption{appOptionStageConfig, "configfile", func(app *App) error {
+		if len(paths) == 0 {
+			return fmt.Errorf("WithConfigFile paths is empty")
+		}
+		app.Config.Set("config", paths)
+		return ConfigParseJSON(app.Config)
+	}}
+}
+
+// WithMiddleware function creates an AppOption that adds middleware using app.AddMiddleware, multiple options are executed in order.
+//
+// WithMiddleware 函数创建使用app.AddMiddleware添加中间件的选项，多个WithMiddleware按传入顺序执行。
+func WithMiddleware(hs ...interface{}) AppOption {
+	return AppOption{appOptionStageMiddleware, "", func(app *App) error {
+		if len(hs) == 0 {
+			return fmt.Errorf("WithMiddleware handlers is empty")
+		}
+		return app.AddMiddleware(hs...)
+	}}
+}
+
+// WithListen function creates an AppOption that listens to an http port when app.Run is called.
+//
+// WithListen 函数创建监听http端口的选项，在app.Run时监听，注册路由和启动前检查后才开始接收请求。
+func WithListen(addr string) AppOption {
+	return AppOption{appOptionStageListen, "", func(app *App) error {
+		for _, listen := range app.listens {
+			if listen == addr {
+				return fmt.Errorf("WithListen address %s is duplicate", addr)
+			}
+		}
+		app.listens = append(app.listens, addr)
+		return nil
+	}}
+}
+
+// optionsApply 方法按阶段顺序执行AppOption，同一批选项中组件和配置文件选项不允许重复。
+func (app *App) optionsApply(options []AppOption) {
+	sort.SliceStable(options, func(i, j int) bool {
+		return options[i].stage < options[j].stage
+	})
+	names := make(map[string]bool)
+	for _, option := range options {
+		var err error
+		if names[option.name] {
+			err = fmt.Errorf("option %s is duplicate", option.name)
+		} else {
+			names[option.name] = option.name != ""
+			err = option.fn(app)
+		}
+		if err != nil {
+			app.Options(&appInitError{err})
+		}
+	}
+}
+
+// Options method loads the app component. When the option type is context.Context, Logger, Config, Server, Router, Binder, Renderer, Validater, Cache, *ContextDebug, the app property will be set,
 // and the print property of the component will be set. If the type is error, it will be the app end error Return to the Run method.
+// Component init errors created by NewAppInitOption are collected and reported together before startup.
 //
-// Options 方法加载app组件，option类型为context.Context、Logger、Config、Server、Router、Binder、Renderer、Validater时会设置app属性，
-// 并设置组件的print属性，如果类型为error将作为app结束错误返回给Run方法。
+// Options 方法加载app组件，option类型为context.Context、Logger、Config、Server、Router、Binder、Renderer、Validater、Cache、*ContextDebug时会设置app属性，
+// 并设置组件的print属性，如果类型为error将作为app结束错误返回给Run方法；
+// NewAppInitOption函数创建的组件初始化错误会被收集，在启动前合并报告；
+// AppOption在其他选项加载后按阶段顺序执行。
 func (app *App) Options(options ...interface{}) {
+	var appOptions []AppOption
 	for _, i := range options {
 		if i == nil {
 			continue
@@ -94,24 +239,42 @@ func (app *App) Options(options ...interface{}) {
 			app.Renderer = val
 		case Validater:
 			app.Validater = val
+		case Cache:
+			app.Cache = val
+		case *ContextDebug:
+			app.ContextDebug = val
+			if log, ok := app.Logger.(contextDebugLogger); ok {
+				log.setContextDebug(val)
+			}
+		case AppOption:
+			appOptions = append(appOptions, val)
+		case *appInitError:
+			app.Error(val.Error())
+			app.cancelMutex.Lock()
+			app.initErrors = append(app.initErrors, val)
+			app.cancelMutex.Unlock()
 		case error:
 			app.Error("eudore app cannel context on handler error: " + val.Error())
 			app.CancelFunc()
 			app.cancelMutex.Lock()
-			defer app.cancelMutex.Unlock()
 			// 记录第一个错误
 			if app.CancelError == nil {
 				app.CancelError = val
 			}
+			app.cancelMutex.Unlock()
 		default:
 			app.Logger.Warningf("eudore app invalid option: %v", i)
 		}
 	}
+	if appOptions != nil {
+		app.optionsApply(appOptions)
+	}
 }
 
 // Run method starts the App and blocks and waits for the end of the App, and periodically calls app.Logger.Sync() to output the log.
 //
-// Run 方法启动App阻塞等待App结束，并周期调用app.Logger.Sync()将日志输出。
+// Run 方法启动App阻塞等待App结束，并周期调用app.Logger.Sync()将日志输出，
+// 启动时监听WithListen选项设置的地址。
 func (app *App) Run() error {
 	ticker := time.NewTicker(time.Millisecond * 80)
 	defer ticker.Stop()
@@ -121,15 +284,172 @@ func (app *App) Run() error {
 		}
 	}()
 
+	for _, addr := range app.listens {
+		app.Listen(addr)
+	}
+	app.cancelMutex.Lock()
+	initErrors := len(app.initErrors)
+	app.cancelMutex.Unlock()
+	if initErrors > 0 {
+		app.preflight()
+	}
+
 	<-app.Done()
 	time.Sleep(time.Millisecond * 100)
 	app.Shutdown(context.Background())
+	if app.Scheduler != nil {
+		app.Scheduler.Wait()
+	}
+	if app.TaskQueue != nil {
+		app.TaskQueue.Wait()
+	}
+	if app.EventBus != nil {
+		app.EventBus.Wait()
+	}
+	app.goWaitGroup.Wait()
 	time.Sleep(time.Millisecond * 100)
 	app.cancelMutex.Lock()
 	defer app.cancelMutex.Unlock()
 	return app.CancelError
 }
 
+// Cron method uses app.Scheduler to add a cron job, the job is stopped when the app ends, and Run waits for the running job to finish.
+//
+// Cron 方法使用app.Scheduler添加一个cron任务，app结束时停止任务，Run方法会等待执行中的任务结束。
+func (app *App) Cron(spec string, fn func(context.Context) error, options ...interface{}) error {
+	err := app.getScheduler().Cron(spec, fn, options...)
+	if err != nil {
+		app.Error(err)
+	}
+	return err
+}
+
+// Every method uses app.Scheduler to add a job that runs every interval.
+//
+// Every 方法使用app.Scheduler添加一个固定间隔执行的任务。
+func (app *App) Every(interval time.Duration, fn func(context.Context) error, options ...interface{}) error {
+	err := app.getScheduler().Every(interval, fn, options...)
+	if err != nil {
+		app.Error(err)
+	}
+	return err
+}
+
+func (app *App) getScheduler() *Scheduler {
+	app.cancelMutex.Lock()
+	defer app.cancelMutex.Unlock()
+	if app.Scheduler == nil {
+		app.Scheduler = NewScheduler(app.Context, app.Logger)
+	}
+	return app.Scheduler
+}
+
+// Enqueue method uses app.TaskQueue to execute a task asynchronously, and Run waits for the running task to finish.
+//
+// Enqueue 方法使用app.TaskQueue异步执行一个任务，Run方法会等待执行中的任务结束。
+func (app *App) Enqueue(task *Task) error {
+	return app.getTaskQueue().Enqueue(task)
+}
+
+// AddTaskHandler method registers the handler of the named task in app.TaskQueue.
+//
+// AddTaskHandler 方法在app.TaskQueue注册名称对应的任务处理函数。
+func (app *App) AddTaskHandler(name string, fn TaskHandler) {
+	app.getTaskQueue().AddHandler(name, fn)
+}
+
+func (app *App) getTaskQueue() *TaskQueue {
+	app.cancelMutex.Lock()
+	defer app.cancelMutex.Unlock()
+	if app.TaskQueue == nil {
+		app.TaskQueue = NewTaskQueue(app.Context, app.Logger)
+	}
+	return app.TaskQueue
+}
+
+// On method uses app.EventBus to subscribe to the topic pattern, and returns the function to cancel the subscription.
+//
+// On 方法使用app.EventBus订阅topic模式，返回取消订阅的函数。
+func (app *App) On(pattern string, fn EventHandler, options ...interface{}) func() {
+	return app.getEventBus().On(pattern, fn, options...)
+}
+
+// Emit method uses app.EventBus to publish an event.
+//
+// Emit 方法使用app.EventBus发布一个事件。
+func (app *App) Emit(topic string, payload interface{}) error {
+	return app.getEventBus().Emit(topic, payload)
+}
+
+func (app *App) getEventBus() *EventBus {
+	app.cancelMutex.Lock()
+	defer app.cancelMutex.Unlock()
+	if app.EventBus == nil {
+		app.EventBus = NewEventBus(app.Context, app.Logger)
+	}
+	return app.EventBus
+}
+
+// Go method runs fn in a new goroutine, recovers panic and outputs the error log with stack, and Run waits for the goroutine to finish.
+//
+// If the option type is string, set the name, if it is int, set the maximum number of restarts after panic or error (-1 is unlimited),
+// and if it is time.Duration, set the restart interval, the default is 1 second and doubles each time up to 1 minute.
+//
+// Go 方法在新协程执行fn，捕捉panic并输出带栈信息的错误日志，Run方法会等待协程结束。
+//
+// options类型为string时设置名称，类型为int时设置panic或返回错误后的最大重启次数(-1不限制)，
+// 类型为time.Duration时设置重启间隔，默认1秒每次加倍最大1分钟，app结束后不再重启。
+func (app *App) Go(fn func(context.Context) error, options ...interface{}) {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	var restart int
+	backoff := time.Second
+	for _, i := range options {
+		switch val := i.(type) {
+		case string:
+			name = val
+		case int:
+			restart = val
+		case time.Duration:
+			backoff = val
+		}
+	}
+
+	app.goWaitGroup.Add(1)
+	go func() {
+		defer app.goWaitGroup.Done()
+		for i := 1; ; i++ {
+			err := app.goRun(name, fn)
+			if err == nil || app.Err() != nil || (restart >= 0 && i > restart) {
+				return
+			}
+			app.Logger.WithField("name", name).WithField("restart", i).Warningf("app goroutine restart after %s", backoff)
+			select {
+			case <-time.After(backoff):
+			case <-app.Done():
+				return
+			}
+			if backoff *= 2; backoff > time.Minute {
+				backoff = time.Minute
+			}
+		}
+	}()
+}
+
+// goRun 方法执行协程函数，捕捉panic转换成错误。
+func (app *App) goRun(name string, fn func(context.Context) error) (err error) {
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = fmt.Errorf("app goroutine panic: %v", rerr)
+			app.Logger.WithField("name", name).WithField("stack", GetPanicStack(4)).Error(err)
+		}
+	}()
+	err = fn(app.Context)
+	if err != nil {
+		app.Logger.WithField("name", name).Error(err)
+	}
+	return err
+}
+
 // serveContext Implement the request context function.
 // serveContext 实现处理请求上下文函数。
 func (app *App) serveContext(ctx Context) {
@@ -148,12 +468,85 @@ func (app *App) serveContext(ctx Context) {
 // 创建并初始化一个Context，然后设置app.HandlerFuncs为Context的处理者处理全局中间件链，
 // 在app.HandlerFuncs最后一次处理时，调用了app.serveContext方法，使用app.Router匹配出这个请求的路由中间件和路由处理函数进行二次请求处理。
 func (app *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	app.serveHTTP(w, r, nil)
+}
+
+// serveHTTP 方法处理http请求，fields不为空时设置为Context日志的基础字段。
+func (app *App) serveHTTP(w http.ResponseWriter, r *http.Request, fields Fields) {
 	ctx := app.ContextPool.Get().(Context)
+	if app.ContextDebug != nil {
+		app.ContextDebug.checkout()
+	}
 	ctx.Reset(r.Context(), w, r)
+	if fields != nil {
+		ctx.SetLogger(ctx.Logger().WithFields(fields))
+	}
 	ctx.SetHandler(-1, app.HandlerFuncs)
+	app.handleContext(ctx)
+	if app.ContextDebug != nil {
+		// 调试模式下不回收Context，标记为已释放用于检测误用。
+		app.ContextDebug.release(ctx)
+		return
+	}
+	app.ContextPool.Put(ctx)
+}
+
+// handleContext 方法执行请求处理函数，然后执行ctx.Defer注册的清理函数，处理函数panic时也会执行清理函数。
+func (app *App) handleContext(ctx Context) {
+	if r, ok := ctx.(contextReleaser); ok {
+		defer r.release()
+	}
 	ctx.Next()
 	ctx.End()
-	app.ContextPool.Put(ctx)
+}
+
+// Mount method mounts a complete sub App at the path prefix, the request path is stripped of the prefix and handled by sub.ServeMount.
+//
+// The sub App uses its own global middleware, Router, Binder, Renderer and Logger, and the request log outputs the mount field;
+// panic in the sub App is recovered and returns 500 without affecting the parent App.
+//
+// Mount 方法将一个完整的子App挂载到路径前缀，请求路径去除前缀后交给sub.ServeMount处理，用于模块化单体应用和复用app模块。
+//
+// 子App使用独立的全局中间件、路由、Binder、Renderer和Logger，请求日志输出mount字段；
+// 子App处理时的panic会被捕捉并返回500，不会影响父App，组路由挂载使用group.AnyFunc("/admin/*", sub.ServeMount)。
+func (app *App) Mount(prefix string, sub *App) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	app.Router.AnyFunc(prefix, sub.ServeMount)
+	app.Router.AnyFunc(prefix+"/*", sub.ServeMount)
+}
+
+// ServeMount method uses the path matched by the route wildcard as the request path of the sub App,
+// and the stripped prefix can be obtained from the request context using MountContextKey.
+//
+// ServeMount 方法使用路由通配符匹配的路径作为子App的请求路径，去除的前缀可以在请求context中使用MountContextKey获取。
+func (app *App) ServeMount(ctx Context) {
+	r := ctx.Request()
+	path := "/" + ctx.GetParam("*")
+	prefix := strings.TrimSuffix(r.URL.Path, path)
+	if path == "/" && !strings.HasSuffix(r.URL.Path, "/") {
+		prefix = r.URL.Path
+	}
+
+	u := *r.URL
+	u.Path, u.RawPath = path, ""
+	req := r.WithContext(context.WithValue(ctx.GetContext(), MountContextKey, prefix))
+	req.URL = &u
+	req.RequestURI = u.RequestURI()
+
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			app.Logger.WithFields(Fields{
+				"mount": prefix,
+				"path":  path,
+				"stack": GetPanicStack(4),
+			}).Errorf("app mount panic: %v", rerr)
+			if ctx.Response().Size() == 0 {
+				ctx.WriteHeader(StatusInternalServerError)
+			}
+		}
+		ctx.End()
+	}()
+	app.serveHTTP(ctx.Response(), req, Fields{"mount": prefix})
 }
 
 // AddMiddleware If the first parameter of the AddMiddleware method is the string "global",
@@ -176,10 +569,168 @@ func (app *App) AddMiddleware(hs ...interface{}) error {
 	return app.Router.AddMiddleware(hs...)
 }
 
+// Mode method returns the running mode of the app, which is read from config mode and then environment variable EUDORE_MODE,
+// dev/prod are abbreviations, and the default is production.
+//
+// Mode 方法返回app运行模式，依次读取配置mode和环境变量EUDORE_MODE，值为development、test或production，
+// 可以使用dev、prod缩写，未设置时为production，避免开发功能在生产环境意外开启。
+func (app *App) Mode() string {
+	mode := strings.ToLower(GetString(app.Config.Get("mode")))
+	if mode == "" {
+		mode = strings.ToLower(os.Getenv(EnvEudoreMode))
+	}
+	switch mode {
+	case "dev", "develop", ModeDevelopment:
+		return ModeDevelopment
+	case ModeTest:
+		return ModeTest
+	}
+	return ModeProduction
+}
+
+// IsMode method checks whether the app is running in any of the modes.
+//
+// IsMode 方法检查app是否运行在任意一个模式。
+func (app *App) IsMode(modes ...string) bool {
+	mode := app.Mode()
+	for _, i := range modes {
+		if i == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// Dev method executes fn only in development mode, for example registering dump, pprof routes or middleware.
+//
+// Dev 方法仅在development模式执行fn，例如注册dump、pprof路由或中间件，生产环境不会注册这些处理函数。
+func (app *App) Dev(fn func()) {
+	if app.IsMode(ModeDevelopment) {
+		fn()
+	}
+}
+
+// NewAppInitOption function converts the result of an error-returning constructor into an app option,
+// for example app.Options(eudore.NewAppInitOption(eudore.NewLoggerStd2(conf))).
+//
+// NewAppInitOption 函数将返回错误的组件构造函数结果转换成app选项，err为空时返回组件，
+// 否则返回初始化错误，app.Options收集全部初始化错误，在启动前合并报告并结束app，不启动监听。
+func NewAppInitOption(component interface{}, err error) interface{} {
+	if err != nil {
+		return &appInitError{err}
+	}
+	return component
+}
+
+// AddPreflight method adds a check function that is executed before the first listener starts accepting,
+// such as verifying db connectivity, running migrations or priming caches.
+//
+// If the option type is string, set the name, and if it is time.Duration, set the timeout, the default is 30 seconds.
+//
+// AddPreflight 方法添加一个启动前检查函数，在第一个监听开始接收请求前按添加顺序执行，例如检查数据库连接、执行迁移、预热缓存。
+//
+// options类型为string时设置名称，类型为time.Duration时设置超时时间，默认30秒；
+// 检查失败时合并全部错误结束app，不启动监听。
+func (app *App) AddPreflight(fn func(context.Context) error, options ...interface{}) {
+	check := appPreflight{
+		name:    runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name(),
+		timeout: 30 * time.Second,
+		fn:      fn,
+	}
+	for _, i := range options {
+		switch val := i.(type) {
+		case string:
+			check.name = val
+		case time.Duration:
+			check.timeout = val
+		}
+	}
+	app.cancelMutex.Lock()
+	app.preflights = append(app.preflights, check)
+	app.cancelMutex.Unlock()
+}
+
+// preflight 方法执行一次启动审计和全部启动前检查，返回合并的错误。
+func (app *App) preflight() error {
+	app.preflightOnce.Do(func() {
+		app.cancelMutex.Lock()
+		checks := app.preflights
+		initErrors := app.initErrors
+		app.cancelMutex.Unlock()
+		var errs muliterror
+		errs.HandleError(initErrors...)
+		errs.HandleError(app.audit())
+		for _, check := range checks {
+			start := time.Now()
+			ctx, cancel := context.WithTimeout(app.Context, check.timeout)
+			err := check.fn(ctx)
+			cancel()
+			log := app.Logger.WithField("name", check.name).WithField("duration", time.Since(start).String())
+			if err != nil {
+				log.Error("app preflight failed:", err)
+				errs.HandleError(fmt.Errorf("preflight %s: %v", check.name, err))
+			} else {
+				log.Info("app preflight succeeded")
+			}
+		}
+		app.preflightError = errs.GetError()
+		if app.preflightError != nil {
+			app.Options(app.preflightError)
+		}
+	})
+	return app.preflightError
+}
+
+// routerAuditer 定义Router实现路由审计的接口。
+type routerAuditer interface {
+	Audit() []RouterAudit
+}
+
+// audit 方法在启动前审计路由和全局中间件并输出Warning日志，审计级别依次读取配置audit和环境变量EUDORE_AUDIT，
+// 值为warning时输出日志，值为fatal时存在问题返回错误使app启动失败，用于在CI中检查路由注册；
+// 未设置时仅在development模式输出日志，其他值不审计。
+func (app *App) audit() error {
+	level := strings.ToLower(GetString(app.Config.Get("audit")))
+	if level == "" {
+		level = strings.ToLower(os.Getenv(EnvEudoreAudit))
+	}
+	if level == "" && app.IsMode(ModeDevelopment) {
+		level = "warning"
+	}
+	if level != "warning" && level != "fatal" {
+		return nil
+	}
+
+	audits := auditDuplicateHandlers(app.HandlerFuncs[:len(app.HandlerFuncs)-1])
+	for i := range audits {
+		audits[i].Path = "global"
+	}
+	if auditer, ok := app.Router.(routerAuditer); ok {
+		audits = append(audits, auditer.Audit()...)
+	}
+	for _, audit := range audits {
+		fields := Fields{"kind": audit.Kind, "path": audit.Path}
+		if audit.Method != "" {
+			fields["method"] = audit.Method
+		}
+		if audit.Route != "" {
+			fields["route"] = audit.Route
+		}
+		app.Logger.WithFields(fields).Warning("app startup audit: " + audit.Message)
+	}
+	if level == "fatal" && len(audits) > 0 {
+		return fmt.Errorf(ErrFormatAppAudit, len(audits))
+	}
+	return nil
+}
+
 // Listen method listens to an http port.
 //
 // Listen 方法监听一个http端口。
 func (app *App) Listen(addr string) error {
+	if err := app.preflight(); err != nil {
+		return err
+	}
 	conf := ServerListenConfig{
 		Addr: addr,
 	}
@@ -197,6 +748,9 @@ func (app *App) Listen(addr string) error {
 //
 // ListenTLS 方法监听一个https端口，如果默认开启h2。
 func (app *App) ListenTLS(addr, key, cert string) error {
+	if err := app.preflight(); err != nil {
+		return err
+	}
 	conf := ServerListenConfig{
 		Addr:     addr,
 		HTTPS:    true,
@@ -214,10 +768,14 @@ func (app *App) ListenTLS(addr, key, cert string) error {
 	return nil
 }
 
-// Serve method starts a Server monitor non-blocking, and uses the app to process the monitor and return an error.
+// Serve method starts a Server monitor non-blocking, and uses the app to process the monitor and return an error, the listener is closed if the preflight fails.
 //
-// Serve 方法非阻塞启动一个Server监听，并使用app处理监听结束返回错误。
+// Serve 方法非阻塞启动一个Server监听，并使用app处理监听结束返回错误，启动前检查失败时关闭监听。
 func (app *App) Serve(ln net.Listener) {
+	if app.preflight() != nil {
+		ln.Close()
+		return
+	}
 	go func() {
 		app.Options(app.Server.Serve(ln))
 	}()
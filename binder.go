@@ -1,12 +1,15 @@
 package eudore
 
 import (
+	"bytes"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/url"
+	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -26,12 +29,43 @@ Binder对象用于请求数据反序列化，
 // Binder 定义Bind函数处理请求。
 type Binder func(Context, io.Reader, interface{}) error
 
+// FieldError defines a bind or validate error of a field.
+//
+// FieldError 定义一个字段的绑定或校验错误。
+type FieldError struct {
+	Field   string `json:"field"`
+	Type    string `json:"type,omitempty"`
+	Value   string `json:"value,omitempty"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+// FieldErrors defines multiple field errors, Bind type conversion failure and Validate check failure return FieldErrors.
+//
+// FieldErrors 定义多个字段错误，Bind类型转换失败和Validate校验失败返回FieldErrors，Context.Fatal使用FieldErrors时响应400并返回全部字段错误。
+type FieldErrors []*FieldError
+
+// Error 方法返回字段错误消息。
+func (err *FieldError) Error() string {
+	return err.Message
+}
+
+// Error 方法返回全部字段错误消息，使用分号分隔。
+func (errs FieldErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
 // BindDefault 函数实现默认Binder。
 func BindDefault(ctx Context, r io.Reader, i interface{}) error {
 	if ctx.Method() == MethodGet || ctx.Method() == MethodHead {
 		return BindURL(ctx, r, i)
 	}
-	switch strings.SplitN(ctx.GetHeader(HeaderContentType), ";", 2)[0] {
+	contentType := strings.TrimSpace(strings.SplitN(ctx.GetHeader(HeaderContentType), ";", 2)[0])
+	switch contentType {
 	case MimeApplicationJSON:
 		return BindJSON(ctx, r, i)
 	case MimeApplicationForm:
@@ -41,6 +75,10 @@ func BindDefault(ctx Context, r io.Reader, i interface{}) error {
 	case MimeTextXML, MimeApplicationXML:
 		return BindXML(ctx, r, i)
 	default:
+		// application/soap+xml等xml类型
+		if strings.HasSuffix(contentType, "+xml") {
+			return BindXML(ctx, r, i)
+		}
 		err := fmt.Errorf(ErrFormatBindDefaultNotSupportContentType, ctx.GetHeader(HeaderContentType))
 		ctx.Error(err)
 		return err
@@ -49,16 +87,21 @@ func BindDefault(ctx Context, r io.Reader, i interface{}) error {
 
 // BindURL 函数使用url参数实现bind。
 func BindURL(ctx Context, _ io.Reader, i interface{}) error {
-	for key, vals := range ctx.Querys() {
+	querys := ctx.Querys()
+	for key, vals := range querys {
 		SetWithTags(i, key, vals[0], DefaultConvertURLTags)
 	}
-	return nil
+	return checkBindValues(i, querys, DefaultConvertURLTags)
 }
 
 // BindForm 函数使用form格式body实现bind。
 func BindForm(ctx Context, _ io.Reader, i interface{}) error {
 	ConvertToWithTags(ctx.FormFiles(), i, DefaultConvertFormTags)
-	return ConvertToWithTags(ctx.FormValues(), i, DefaultConvertFormTags)
+	err := ConvertToWithTags(ctx.FormValues(), i, DefaultConvertFormTags)
+	if err != nil {
+		return err
+	}
+	return checkBindValues(i, ctx.FormValues(), DefaultConvertFormTags)
 }
 
 // BindURLBody 函数使用url格式body实现bind，body读取限制32kb。
@@ -71,17 +114,166 @@ func BindURLBody(_ Context, r io.Reader, i interface{}) error {
 	if err != nil {
 		return err
 	}
-	return ConvertToWithTags(uri, i, DefaultConvertURLTags)
+	err = ConvertToWithTags(uri, i, DefaultConvertURLTags)
+	if err != nil {
+		return err
+	}
+	return checkBindValues(i, uri, DefaultConvertURLTags)
 }
 
-// BindJSON 函数使用json格式body实现bind。
+// BindJSON 函数使用json格式body实现bind，类型错误返回全部属性的FieldErrors。
 func BindJSON(_ Context, r io.Reader, i interface{}) error {
-	return json.NewDecoder(r).Decode(i)
+	var body bytes.Buffer
+	err := json.NewDecoder(io.TeeReader(r, &body)).Decode(i)
+	if e, ok := err.(*json.UnmarshalTypeError); ok {
+		return getBindJSONErrors(body.Bytes(), i, e)
+	}
+	return err
+}
+
+// getBindJSONErrors 函数将json对象的每个属性单独解析到新对象，返回按字段排序的全部类型错误。
+//
+// json.Decoder只返回第一个类型错误，嵌套对象内只返回第一个类型错误。
+func getBindJSONErrors(body []byte, i interface{}, e *json.UnmarshalTypeError) error {
+	var fields map[string]json.RawMessage
+	iType := reflect.TypeOf(i)
+	if iType.Kind() != reflect.Ptr || json.Unmarshal(body, &fields) != nil {
+		return FieldErrors{newBindJSONError(e)}
+	}
+	var errs FieldErrors
+	for key, val := range fields {
+		data, _ := json.Marshal(map[string]json.RawMessage{key: val})
+		err := json.Unmarshal(data, reflect.New(iType.Elem()).Interface())
+		if e, ok := err.(*json.UnmarshalTypeError); ok {
+			errs = append(errs, newBindJSONError(e))
+		}
+	}
+	if len(errs) == 0 {
+		return FieldErrors{newBindJSONError(e)}
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Field < errs[j].Field
+	})
+	return errs
 }
 
-// BindXML 函数使用xml格式body实现bind。
+func newBindJSONError(e *json.UnmarshalTypeError) *FieldError {
+	return &FieldError{
+		Field:   e.Field,
+		Type:    e.Type.String(),
+		Value:   e.Value,
+		Message: fmt.Sprintf(ErrFormatBindFieldConvert, e.Field, e.Value, e.Type.String()),
+	}
+}
+
+// BindXML 函数使用xml格式body实现bind，支持utf-8、iso-8859-1和us-ascii编码。
+//
+// 结构体使用encoding/xml相同的tag规则，支持属性、命名空间和CDATA；
+// map[string]interface{}保存根元素，元素名称保留命名空间前缀，例如soap:Envelope，
+// 属性使用@加属性名称，包含xmlns声明，文本和CDATA使用#text，没有属性和子元素的元素值为字符串，重复元素值为[]interface{}。
 func BindXML(_ Context, r io.Reader, i interface{}) error {
-	return xml.NewDecoder(r).Decode(i)
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = newXMLCharsetReader
+	switch val := i.(type) {
+	case map[string]interface{}:
+		return bindXMLMap(decoder, val)
+	case *map[string]interface{}:
+		if *val == nil {
+			*val = make(map[string]interface{})
+		}
+		return bindXMLMap(decoder, *val)
+	}
+	return decoder.Decode(i)
+}
+
+// newXMLCharsetReader 函数将iso-8859-1和us-ascii编码转换成utf-8。
+func newXMLCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8":
+		return input, nil
+	case "iso-8859-1", "iso_8859-1", "latin1", "us-ascii", "ascii":
+		body, err := ioutil.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		runes := make([]rune, len(body))
+		for i, b := range body {
+			runes[i] = rune(b)
+		}
+		return strings.NewReader(string(runes)), nil
+	}
+	return nil, fmt.Errorf(ErrFormatBindXMLCharset, charset)
+}
+
+func bindXMLMap(decoder *xml.Decoder, data map[string]interface{}) error {
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			return err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			val, err := bindXMLElement(decoder, start)
+			if err != nil {
+				return err
+			}
+			data[getXMLName(start.Name)] = val
+			return nil
+		}
+	}
+}
+
+// bindXMLElement 函数使用RawToken读取元素，保留命名空间前缀。
+func bindXMLElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	node := make(map[string]interface{})
+	for _, attr := range start.Attr {
+		node["@"+getXMLName(attr.Name)] = attr.Value
+	}
+	var text []byte
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			val, err := bindXMLElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			name := getXMLName(t.Name)
+			switch prev := node[name].(type) {
+			case nil:
+				node[name] = val
+			case []interface{}:
+				node[name] = append(prev, val)
+			default:
+				node[name] = []interface{}{prev, val}
+			}
+		case xml.CharData:
+			text = append(text, t...)
+		case xml.EndElement:
+			if t.Name != start.Name {
+				return nil, &xml.SyntaxError{Msg: "element <" + getXMLName(start.Name) + "> closed by </" + getXMLName(t.Name) + ">"}
+			}
+			if len(node) == 0 {
+				return string(text), nil
+			}
+			if len(strings.TrimSpace(string(text))) > 0 {
+				node["#text"] = string(text)
+			}
+			return node, nil
+		}
+	}
+}
+
+func getXMLName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
 }
 
 // BindHeader 函数实现使用header数据bind。
@@ -104,8 +296,71 @@ func NewBinderHeader(fn Binder) Binder {
 func NewBinderURL(fn Binder) Binder {
 	return func(ctx Context, r io.Reader, i interface{}) error {
 		if ctx.Method() != MethodGet && ctx.Method() != MethodHead {
-			BindURL(ctx, r, i)
+			errURL := BindURL(ctx, r, i)
+			err := fn(ctx, r, i)
+			if err == nil {
+				err = errURL
+			}
+			return err
 		}
 		return fn(ctx, r, i)
 	}
 }
+
+// checkBindValues 函数检查请求值能否转换成绑定字段的类型，返回按字段排序的FieldErrors。
+//
+// 只检查数值、bool、time.Time和它们的指针、切片类型，字段不存在时忽略。
+func checkBindValues(i interface{}, values map[string][]string, tags []string) error {
+	var errs FieldErrors
+	for key, vals := range values {
+		field, err := GetWithTags(i, key, tags)
+		if err != nil || field == nil || len(vals) == 0 {
+			continue
+		}
+		iType := reflect.TypeOf(field)
+		elemType := iType
+		if elemType.Kind() == reflect.Slice {
+			// []byte和[]rune直接使用字符串转换
+			elemType = elemType.Elem()
+			if elemType.Kind() == reflect.Uint8 || elemType.Kind() == reflect.Int32 {
+				continue
+			}
+		} else {
+			vals = vals[:1]
+		}
+		for elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		if !checkBindType(elemType) {
+			continue
+		}
+		for _, val := range vals {
+			if setWithString(reflect.New(elemType).Elem(), val) != nil {
+				errs = append(errs, &FieldError{
+					Field:   key,
+					Type:    iType.String(),
+					Value:   val,
+					Message: fmt.Sprintf(ErrFormatBindFieldConvert, key, val, iType.String()),
+				})
+				break
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Field < errs[j].Field
+	})
+	return errs
+}
+
+func checkBindType(iType reflect.Type) bool {
+	switch iType.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return iType == typeTimeTime
+}
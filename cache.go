@@ -0,0 +1,289 @@
+package eudore
+
+import (
+	"container/list"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache defines a key-value cache with ttl, app.Cache is shared by middleware and handlers.
+//
+// Cache 定义带有过期时间的键值缓存，app.Cache由中间件和处理函数共享使用。
+//
+// ttl为0表示不过期，默认使用内存实现，多实例部署时可以使用component/redis实现共享缓存。
+type Cache interface {
+	// Get 方法获取key对应的值，key不存在或过期返回ErrCacheMiss。
+	Get(string) ([]byte, error)
+	Set(string, []byte, time.Duration) error
+	// Add 方法在key不存在时设置值并返回true，key已经存在时返回false。
+	Add(string, []byte, time.Duration) (bool, error)
+	Delete(string) error
+}
+
+// ErrCacheMiss 定义缓存key不存在的错误。
+var ErrCacheMiss = errors.New("cache miss")
+
+// CacheRemember function gets the value of key, if the key does not exist, calls fn and saves the result.
+//
+// CacheRemember 函数获取key的值，如果key不存在调用fn并保存结果，fn返回错误时不保存。
+func CacheRemember(cache Cache, key string, ttl time.Duration, fn func() ([]byte, error)) ([]byte, error) {
+	val, err := cache.Get(key)
+	if err != ErrCacheMiss {
+		return val, err
+	}
+	val, err = fn()
+	if err != nil {
+		return nil, err
+	}
+	return val, cache.Set(key, val, ttl)
+}
+
+// cacheMemory 定义基于map的内存缓存。
+type cacheMemory struct {
+	sync.RWMutex
+	data  map[string]cacheMemoryItem
+	clean time.Time
+}
+
+type cacheMemoryItem struct {
+	value  []byte
+	expire time.Time
+}
+
+// NewCacheMemory 函数创建一个内存缓存，写入时每分钟清理一次过期数据。
+func NewCacheMemory() Cache {
+	return &cacheMemory{
+		data:  make(map[string]cacheMemoryItem),
+		clean: time.Now(),
+	}
+}
+
+// Get 方法获取key对应的值。
+func (c *cacheMemory) Get(key string) ([]byte, error) {
+	c.RLock()
+	item, ok := c.data[key]
+	c.RUnlock()
+	if !ok || item.expired(time.Now()) {
+		return nil, ErrCacheMiss
+	}
+	return item.value, nil
+}
+
+// Set 方法设置key对应的值。
+func (c *cacheMemory) Set(key string, val []byte, ttl time.Duration) error {
+	c.Lock()
+	defer c.Unlock()
+	c.set(key, val, ttl)
+	return nil
+}
+
+// Add 方法在key不存在时设置值。
+func (c *cacheMemory) Add(key string, val []byte, ttl time.Duration) (bool, error) {
+	c.Lock()
+	defer c.Unlock()
+	item, ok := c.data[key]
+	if ok && !item.expired(time.Now()) {
+		return false, nil
+	}
+	c.set(key, val, ttl)
+	return true, nil
+}
+
+// Delete 方法删除key。
+func (c *cacheMemory) Delete(key string) error {
+	c.Lock()
+	delete(c.data, key)
+	c.Unlock()
+	return nil
+}
+
+func (c *cacheMemory) set(key string, val []byte, ttl time.Duration) {
+	now := time.Now()
+	if now.Sub(c.clean) > time.Minute {
+		c.clean = now
+		for k, item := range c.data {
+			if item.expired(now) {
+				delete(c.data, k)
+			}
+		}
+	}
+	item := cacheMemoryItem{value: val}
+	if ttl > 0 {
+		item.expire = now.Add(ttl)
+	}
+	c.data[key] = item
+}
+
+func (item cacheMemoryItem) expired(now time.Time) bool {
+	return !item.expire.IsZero() && now.After(item.expire)
+}
+
+// CacheLRU defines an in-memory LRU cache bounded by bytes, keys are distributed to shards with independent locks.
+//
+// CacheLRU 定义按字节数限制的内存LRU缓存，key分布到多个分片分别加锁，超出分片容量时淘汰最久未使用的数据。
+//
+// 用于没有Redis的单实例部署，Stats方法返回命中、未命中和淘汰次数。
+type CacheLRU struct {
+	hits      int64
+	misses    int64
+	evictions int64
+	shards    []*cacheLRUShard
+}
+
+// CacheStats 定义缓存统计数据。
+type CacheStats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"`
+	Items     int64 `json:"items"`
+	Bytes     int64 `json:"bytes"`
+}
+
+type cacheLRUShard struct {
+	sync.Mutex
+	list  *list.List
+	items map[string]*list.Element
+	size  int64
+	bytes int64
+}
+
+type cacheLRUItem struct {
+	key    string
+	value  []byte
+	expire time.Time
+}
+
+// NewCacheLRU 函数创建一个LRU内存缓存，size为最大字节数(key和value长度之和)，shards为分片数量，默认16。
+//
+// 每个分片容量为size/shards，超过分片容量的值不保存。
+func NewCacheLRU(size int64, shards int) *CacheLRU {
+	if shards <= 0 {
+		shards = 16
+	}
+	c := &CacheLRU{shards: make([]*cacheLRUShard, shards)}
+	for i := range c.shards {
+		c.shards[i] = &cacheLRUShard{
+			list:  list.New(),
+			items: make(map[string]*list.Element),
+			size:  size / int64(shards),
+		}
+	}
+	return c
+}
+
+// Get 方法获取key对应的值，并将key移动到最近使用。
+func (c *CacheLRU) Get(key string) ([]byte, error) {
+	s := c.shard(key)
+	s.Lock()
+	elem, ok := s.items[key]
+	if ok && elem.Value.(*cacheLRUItem).expired(time.Now()) {
+		s.remove(elem)
+		ok = false
+	}
+	if !ok {
+		s.Unlock()
+		atomic.AddInt64(&c.misses, 1)
+		return nil, ErrCacheMiss
+	}
+	s.list.MoveToFront(elem)
+	val := elem.Value.(*cacheLRUItem).value
+	s.Unlock()
+	atomic.AddInt64(&c.hits, 1)
+	return val, nil
+}
+
+// Set 方法设置key对应的值。
+func (c *CacheLRU) Set(key string, val []byte, ttl time.Duration) error {
+	s := c.shard(key)
+	s.Lock()
+	c.set(s, key, val, ttl)
+	s.Unlock()
+	return nil
+}
+
+// Add 方法在key不存在时设置值。
+func (c *CacheLRU) Add(key string, val []byte, ttl time.Duration) (bool, error) {
+	s := c.shard(key)
+	s.Lock()
+	defer s.Unlock()
+	elem, ok := s.items[key]
+	if ok && !elem.Value.(*cacheLRUItem).expired(time.Now()) {
+		return false, nil
+	}
+	c.set(s, key, val, ttl)
+	return true, nil
+}
+
+// Delete 方法删除key。
+func (c *CacheLRU) Delete(key string) error {
+	s := c.shard(key)
+	s.Lock()
+	elem, ok := s.items[key]
+	if ok {
+		s.remove(elem)
+	}
+	s.Unlock()
+	return nil
+}
+
+// Stats 方法返回缓存统计数据。
+func (c *CacheLRU) Stats() CacheStats {
+	stats := CacheStats{
+		Hits:      atomic.LoadInt64(&c.hits),
+		Misses:    atomic.LoadInt64(&c.misses),
+		Evictions: atomic.LoadInt64(&c.evictions),
+	}
+	for _, s := range c.shards {
+		s.Lock()
+		stats.Items += int64(len(s.items))
+		stats.Bytes += s.bytes
+		s.Unlock()
+	}
+	return stats
+}
+
+func (c *CacheLRU) shard(key string) *cacheLRUShard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return c.shards[h.Sum32()%uint32(len(c.shards))]
+}
+
+// set 方法保存值，容量不足时从最久未使用的数据开始淘汰，淘汰未过期数据时记录淘汰次数。
+func (c *CacheLRU) set(s *cacheLRUShard, key string, val []byte, ttl time.Duration) {
+	if elem, ok := s.items[key]; ok {
+		s.remove(elem)
+	}
+	size := int64(len(key) + len(val))
+	if size > s.size {
+		return
+	}
+	now := time.Now()
+	for s.bytes+size > s.size {
+		elem := s.list.Back()
+		if !elem.Value.(*cacheLRUItem).expired(now) {
+			atomic.AddInt64(&c.evictions, 1)
+		}
+		s.remove(elem)
+	}
+
+	item := &cacheLRUItem{key: key, value: val}
+	if ttl > 0 {
+		item.expire = now.Add(ttl)
+	}
+	s.items[key] = s.list.PushFront(item)
+	s.bytes += size
+}
+
+func (s *cacheLRUShard) remove(elem *list.Element) {
+	item := elem.Value.(*cacheLRUItem)
+	s.list.Remove(elem)
+	delete(s.items, item.key)
+	s.bytes -= int64(len(item.key) + len(item.value))
+}
+
+func (item *cacheLRUItem) expired(now time.Time) bool {
+	return !item.expire.IsZero() && now.After(item.expire)
+}
@@ -0,0 +1,229 @@
+// Package apikey 实现API Key和客户端证书凭证管理，用于服务之间的简单认证。
+//
+// Store创建的密钥只返回一次明文，Storage仅保存sha256哈希；Key支持授权范围、过期时间、吊销和轮换，
+// 轮换后旧密钥在宽限期内仍然有效，客户端证书使用sha256指纹注册。
+//
+// Store实现middleware.ApiKeyStore和middleware.ApiKeyCertificateStore接口，配合middleware.NewApiKeyFunc使用。
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Key 定义一个API Key或客户端证书凭证，Hash为密钥或证书指纹的sha256，不保存明文密钥。
+type Key struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Hash      string    `json:"hash"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	// ExpiresAt 为零值时不过期。
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+	Revoked   bool      `json:"revoked,omitempty"`
+	// Certificate 表示凭证是否为客户端证书。
+	Certificate bool `json:"certificate,omitempty"`
+	// RotatedTo 为轮换后新密钥的ID。
+	RotatedTo string `json:"rotatedTo,omitempty"`
+}
+
+// Store 定义凭证管理，使用Storage保存Key。
+type Store struct {
+	Storage Storage
+	// Prefix 定义生成密钥的前缀，例如"sk_"，用于日志和代码扫描识别密钥。
+	Prefix string
+}
+
+// 定义凭证错误。
+var (
+	ErrKeyNotFound = errors.New("apikey not found")
+	ErrKeyInvalid  = errors.New("apikey is invalid")
+	ErrKeyExpired  = errors.New("apikey is expired")
+	ErrKeyRevoked  = errors.New("apikey is revoked")
+)
+
+// NewStore 函数创建一个凭证管理，storage为空时使用内存存储。
+func NewStore(storage Storage) *Store {
+	if storage == nil {
+		storage = NewStorageMemory()
+	}
+	return &Store{Storage: storage}
+}
+
+// Create 方法创建一个API Key，返回只显示一次的明文密钥，ttl为0时不过期。
+//
+// 明文密钥格式为Prefix+ID+"."+secret。
+func (s *Store) Create(ctx context.Context, name string, scopes []string, ttl time.Duration) (string, *Key, error) {
+	id, secret := newRandom(8), newRandom(32)
+	key := &Key{
+		ID:        hex.EncodeToString(id),
+		Name:      name,
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	}
+	token := key.ID + "." + base64.RawURLEncoding.EncodeToString(secret)
+	key.Hash = getHash(token)
+	if ttl > 0 {
+		key.ExpiresAt = key.CreatedAt.Add(ttl)
+	}
+	err := s.Storage.Save(ctx, key)
+	if err != nil {
+		return "", nil, err
+	}
+	return s.Prefix + token, key, nil
+}
+
+// AddCertificate 方法使用客户端证书的sha256指纹注册凭证，证书过期时间早于ttl时使用证书过期时间。
+func (s *Store) AddCertificate(ctx context.Context, name string, cert *x509.Certificate, scopes []string, ttl time.Duration) (*Key, error) {
+	fingerprint := getCertificateFingerprint(cert)
+	key := &Key{
+		ID:          "cert-" + fingerprint[:16],
+		Name:        name,
+		Hash:        getHash(fingerprint),
+		Scopes:      scopes,
+		CreatedAt:   time.Now(),
+		ExpiresAt:   cert.NotAfter,
+		Certificate: true,
+	}
+	if ttl > 0 && key.CreatedAt.Add(ttl).Before(key.ExpiresAt) {
+		key.ExpiresAt = key.CreatedAt.Add(ttl)
+	}
+	return key, s.Storage.Save(ctx, key)
+}
+
+// Verify 方法校验明文密钥，返回有效的Key。
+func (s *Store) Verify(ctx context.Context, token string) (*Key, error) {
+	token = strings.TrimPrefix(token, s.Prefix)
+	pos := strings.IndexByte(token, '.')
+	if pos < 1 {
+		return nil, ErrKeyInvalid
+	}
+	return s.verify(ctx, token[:pos], token)
+}
+
+// VerifyCertificate 方法校验客户端证书，返回有效的Key。
+func (s *Store) VerifyCertificate(ctx context.Context, cert *x509.Certificate) (*Key, error) {
+	fingerprint := getCertificateFingerprint(cert)
+	return s.verify(ctx, "cert-"+fingerprint[:16], fingerprint)
+}
+
+func (s *Store) verify(ctx context.Context, id, secret string) (*Key, error) {
+	key, err := s.Storage.Get(ctx, id)
+	if err == ErrKeyNotFound {
+		return nil, ErrKeyInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(getHash(secret))) != 1 {
+		return nil, ErrKeyInvalid
+	}
+	if key.Revoked {
+		return nil, ErrKeyRevoked
+	}
+	if !key.ExpiresAt.IsZero() && time.Now().After(key.ExpiresAt) {
+		return nil, ErrKeyExpired
+	}
+	return key, nil
+}
+
+// VerifyApiKey 方法实现middleware.ApiKeyStore接口，返回Key名称和授权范围。
+func (s *Store) VerifyApiKey(ctx context.Context, token string) (string, []string, error) {
+	key, err := s.Verify(ctx, token)
+	if err != nil {
+		return "", nil, err
+	}
+	return key.Name, key.Scopes, nil
+}
+
+// VerifyApiKeyCertificate 方法实现middleware.ApiKeyCertificateStore接口，返回Key名称和授权范围。
+func (s *Store) VerifyApiKeyCertificate(ctx context.Context, cert *x509.Certificate) (string, []string, error) {
+	key, err := s.VerifyCertificate(ctx, cert)
+	if err != nil {
+		return "", nil, err
+	}
+	return key.Name, key.Scopes, nil
+}
+
+// Rotate 方法轮换API Key，创建相同名称、授权范围和有效时长的新密钥，旧密钥在grace时间后过期，grace为0时立即吊销旧密钥。
+func (s *Store) Rotate(ctx context.Context, id string, grace time.Duration) (string, *Key, error) {
+	old, err := s.Storage.Get(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+	if old.Certificate {
+		return "", nil, ErrKeyInvalid
+	}
+	var ttl time.Duration
+	if !old.ExpiresAt.IsZero() {
+		ttl = old.ExpiresAt.Sub(old.CreatedAt)
+	}
+	token, key, err := s.Create(ctx, old.Name, old.Scopes, ttl)
+	if err != nil {
+		return "", nil, err
+	}
+
+	old.RotatedTo = key.ID
+	if grace > 0 {
+		expires := time.Now().Add(grace)
+		if old.ExpiresAt.IsZero() || expires.Before(old.ExpiresAt) {
+			old.ExpiresAt = expires
+		}
+	} else {
+		old.Revoked = true
+	}
+	return token, key, s.Storage.Save(ctx, old)
+}
+
+// Revoke 方法吊销一个凭证。
+func (s *Store) Revoke(ctx context.Context, id string) error {
+	key, err := s.Storage.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	key.Revoked = true
+	return s.Storage.Save(ctx, key)
+}
+
+// HasScope 方法检查Key是否拥有全部授权范围，授权范围"*"表示拥有全部授权。
+func (key *Key) HasScope(scopes ...string) bool {
+	for _, scope := range scopes {
+		if !hasScope(key.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, i := range scopes {
+		if i == scope || i == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func newRandom(n int) []byte {
+	b := make([]byte, n)
+	rand.Read(b)
+	return b
+}
+
+func getHash(str string) string {
+	h := sha256.Sum256([]byte(str))
+	return hex.EncodeToString(h[:])
+}
+
+func getCertificateFingerprint(cert *x509.Certificate) string {
+	h := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(h[:])
+}
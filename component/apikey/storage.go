@@ -0,0 +1,229 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+type (
+	// Storage 定义凭证存储，Save方法创建或更新Key，Get方法Key不存在时返回ErrKeyNotFound。
+	//
+	// 数据库存储可以使用NewStorageSQL，或者实现该接口使用其他存储。
+	Storage interface {
+		Get(context.Context, string) (*Key, error)
+		Save(context.Context, *Key) error
+		Delete(context.Context, string) error
+		List(context.Context) ([]*Key, error)
+	}
+	// storageMemory 使用内存保存Key。
+	storageMemory struct {
+		sync.RWMutex
+		keys map[string]*Key
+	}
+	// storageFile 使用内存保存Key，每次修改后写入json文件。
+	storageFile struct {
+		*storageMemory
+		path  string
+		write sync.Mutex
+	}
+	// StorageSQL 定义数据库存储，Key使用json格式保存在data列。
+	//
+	// 默认语句使用'?'占位符，其他数据库可以修改语句，例如PostgreSQL使用$1、$2。
+	StorageSQL struct {
+		DB          *sql.DB
+		QueryGet    string
+		QueryInsert string
+		QueryUpdate string
+		QueryDelete string
+		QueryList   string
+	}
+)
+
+// NewStorageMemory 函数创建一个内存存储，用于测试或单实例服务。
+func NewStorageMemory() Storage {
+	return &storageMemory{keys: make(map[string]*Key)}
+}
+
+func (s *storageMemory) Get(_ context.Context, id string) (*Key, error) {
+	s.RLock()
+	defer s.RUnlock()
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	val := *key
+	return &val, nil
+}
+
+func (s *storageMemory) Save(_ context.Context, key *Key) error {
+	s.Lock()
+	defer s.Unlock()
+	val := *key
+	s.keys[key.ID] = &val
+	return nil
+}
+
+func (s *storageMemory) Delete(_ context.Context, id string) error {
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.keys[id]; !ok {
+		return ErrKeyNotFound
+	}
+	delete(s.keys, id)
+	return nil
+}
+
+// List 方法按创建时间顺序返回全部Key。
+func (s *storageMemory) List(_ context.Context) ([]*Key, error) {
+	s.RLock()
+	defer s.RUnlock()
+	keys := make([]*Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		val := *key
+		keys = append(keys, &val)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// NewStorageFile 函数创建一个json文件存储，文件存在时读取全部Key，每次修改后重写文件。
+func NewStorageFile(path string) (Storage, error) {
+	s := &storageFile{
+		storageMemory: &storageMemory{keys: make(map[string]*Key)},
+		path:          path,
+	}
+	body, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, os.MkdirAll(filepath.Dir(path), 0755)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var keys []*Key
+	err = json.Unmarshal(body, &keys)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		s.keys[key.ID] = key
+	}
+	return s, nil
+}
+
+func (s *storageFile) Save(ctx context.Context, key *Key) error {
+	s.storageMemory.Save(ctx, key)
+	return s.save(ctx)
+}
+
+func (s *storageFile) Delete(ctx context.Context, id string) error {
+	err := s.storageMemory.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.save(ctx)
+}
+
+// save 方法写入临时文件后重命名，避免写入中断时损坏文件。
+func (s *storageFile) save(ctx context.Context) error {
+	s.write.Lock()
+	defer s.write.Unlock()
+	keys, _ := s.List(ctx)
+	body, err := json.MarshalIndent(keys, "", "\t")
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(s.path+".tmp", body, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(s.path+".tmp", s.path)
+}
+
+// NewStorageSQL 函数创建一个数据库存储，table需要包含id和data两列，例如:
+//
+// CREATE TABLE apikey(id VARCHAR(64) PRIMARY KEY, data TEXT NOT NULL)
+func NewStorageSQL(db *sql.DB, table string) *StorageSQL {
+	return &StorageSQL{
+		DB:          db,
+		QueryGet:    "SELECT data FROM " + table + " WHERE id=?",
+		QueryInsert: "INSERT INTO " + table + "(id,data) VALUES(?,?)",
+		QueryUpdate: "UPDATE " + table + " SET data=? WHERE id=?",
+		QueryDelete: "DELETE FROM " + table + " WHERE id=?",
+		QueryList:   "SELECT data FROM " + table,
+	}
+}
+
+// Get 方法读取一个Key。
+func (s *StorageSQL) Get(ctx context.Context, id string) (*Key, error) {
+	var data string
+	err := s.DB.QueryRowContext(ctx, s.QueryGet, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	key := new(Key)
+	return key, json.Unmarshal([]byte(data), key)
+}
+
+// Save 方法更新Key，不存在时插入。
+func (s *StorageSQL) Save(ctx context.Context, key *Key) error {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	result, err := s.DB.ExecContext(ctx, s.QueryUpdate, string(data), key.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n > 0 {
+		return nil
+	}
+	_, err = s.DB.ExecContext(ctx, s.QueryInsert, key.ID, string(data))
+	return err
+}
+
+// Delete 方法删除一个Key。
+func (s *StorageSQL) Delete(ctx context.Context, id string) error {
+	result, err := s.DB.ExecContext(ctx, s.QueryDelete, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// List 方法返回全部Key。
+func (s *StorageSQL) List(ctx context.Context) ([]*Key, error) {
+	rows, err := s.DB.QueryContext(ctx, s.QueryList)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var keys []*Key
+	for rows.Next() {
+		var data string
+		err = rows.Scan(&data)
+		if err != nil {
+			return nil, err
+		}
+		key := new(Key)
+		err = json.Unmarshal([]byte(data), key)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
@@ -0,0 +1,248 @@
+// Package audit 实现审计日志，记录主体、行为、资源、结果和变更内容。
+//
+// 审计记录使用哈希链防篡改，每条记录的Hash由上一条记录的Hash和当前记录内容计算，
+// 修改或删除任意一条记录都会导致后续记录校验失败。
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+type (
+	// Auditor 定义审计日志记录者，将记录异步写入全部Sink。
+	//
+	// 每个Sink使用独立的队列和写入协程，并保存各自的哈希链头，写入失败的记录不会推进该Sink的哈希链。
+	Auditor struct {
+		sync.Mutex
+		// Timeout 为每次写入Sink的超时时间，默认10秒，Sink实现WriteContext方法时生效。
+		Timeout time.Duration
+		// Print 输出异步写入Sink的错误，默认不输出。
+		Print  func(...interface{})
+		sinks  []*auditSink
+		wg     sync.WaitGroup
+		closed bool
+	}
+	// Record 定义一条审计记录。
+	Record struct {
+		Time      time.Time       `json:"time"`
+		Subject   string          `json:"subject"`
+		Action    string          `json:"action"`
+		Resource  string          `json:"resource"`
+		Result    string          `json:"result"`
+		IP        string          `json:"ip,omitempty"`
+		RequestID string          `json:"requestid,omitempty"`
+		Diff      json.RawMessage `json:"diff,omitempty"`
+		Prev      string          `json:"prev"`
+		Hash      string          `json:"hash"`
+	}
+	// Sink 定义审计记录的输出目标。
+	Sink interface {
+		Write(*Record) error
+	}
+	// SinkFunc 定义函数类型的Sink。
+	SinkFunc func(*Record) error
+	// lastHasher 定义可以返回已保存最后一条记录Hash的Sink，用于重启后继续哈希链。
+	lastHasher interface {
+		LastHash() string
+	}
+	// contextWriter 定义支持超时取消写入的Sink。
+	contextWriter interface {
+		WriteContext(context.Context, *Record) error
+	}
+	// auditSink 定义一个Sink的写入队列和哈希链头。
+	auditSink struct {
+		Sink
+		queue chan *Record
+		last  string
+	}
+	auditDiffKey struct{}
+)
+
+// 定义审计错误。
+var (
+	ErrAuditChainBroken = errors.New("audit hash chain broken")
+	ErrAuditorClosed    = errors.New("auditor is closed")
+)
+
+// NewAuditor 函数创建一个审计日志记录者，每个Sink启动一个写入协程，使用Close方法等待写入完成。
+//
+// 如果Sink实现LastHash方法，该Sink的哈希链从返回值继续。
+func NewAuditor(sinks ...Sink) *Auditor {
+	a := &Auditor{Timeout: 10 * time.Second}
+	for _, sink := range sinks {
+		s := &auditSink{Sink: sink, queue: make(chan *Record, 1024)}
+		if h, ok := sink.(lastHasher); ok {
+			s.last = h.LastHash()
+		}
+		a.sinks = append(a.sinks, s)
+		a.wg.Add(1)
+		go a.run(s)
+	}
+	return a
+}
+
+// Write 方法实现Sink接口。
+func (fn SinkFunc) Write(r *Record) error {
+	return fn(r)
+}
+
+// Record 方法将记录放入全部Sink的写入队列，diff会使用json序列化，队列已满的Sink丢弃记录并返回错误。
+func (a *Auditor) Record(r *Record, diff interface{}) error {
+	if diff != nil {
+		body, err := json.Marshal(diff)
+		if err != nil {
+			return err
+		}
+		r.Diff = body
+	}
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+
+	a.Lock()
+	defer a.Unlock()
+	if a.closed {
+		return ErrAuditorClosed
+	}
+	var errs []string
+	for i, s := range a.sinks {
+		record := *r
+		select {
+		case s.queue <- &record:
+		default:
+			errs = append(errs, fmt.Sprintf("sink %d queue is full", i))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("audit write error: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// Close 方法停止接收记录，等待队列中的记录写入完成。
+func (a *Auditor) Close() error {
+	a.Lock()
+	if !a.closed {
+		a.closed = true
+		for _, s := range a.sinks {
+			close(s.queue)
+		}
+	}
+	a.Unlock()
+	a.wg.Wait()
+	return nil
+}
+
+// run 方法按顺序写入一个Sink的记录，使用该Sink的哈希链头计算记录哈希。
+func (a *Auditor) run(s *auditSink) {
+	defer a.wg.Done()
+	for r := range s.queue {
+		r.Prev = s.last
+		r.Hash = r.computeHash()
+		err := a.write(s, r)
+		if err == nil {
+			s.last = r.Hash
+		} else if a.Print != nil {
+			a.Print("audit write error:", err)
+		}
+	}
+}
+
+func (a *Auditor) write(s *auditSink, r *Record) error {
+	w, ok := s.Sink.(contextWriter)
+	if !ok || a.Timeout <= 0 {
+		return s.Write(r)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), a.Timeout)
+	defer cancel()
+	return w.WriteContext(ctx, r)
+}
+
+// computeHash 方法计算上一条Hash和记录内容的sha256值。
+func (r Record) computeHash() string {
+	r.Hash = ""
+	body, _ := json.Marshal(r)
+	h := sha256.Sum256(body)
+	return hex.EncodeToString(h[:])
+}
+
+// Verify 函数校验json lines格式审计记录的哈希链，返回校验的记录数量。
+func Verify(reader io.Reader) (int, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var last string
+	var n int
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var r Record
+		err := json.Unmarshal(line, &r)
+		if err != nil {
+			return n, fmt.Errorf("audit line %d: %v", n+1, err)
+		}
+		if (n > 0 && r.Prev != last) || r.Hash != r.computeHash() {
+			return n, fmt.Errorf("audit line %d: %v", n+1, ErrAuditChainBroken)
+		}
+		last = r.Hash
+		n++
+	}
+	return n, scanner.Err()
+}
+
+// NewMiddleware 函数创建一个审计日志中间件，记录非GET、HEAD、OPTIONS且具有action参数的请求。
+//
+// 主体使用UNAME或UID参数，资源使用请求路径，结果使用响应状态码，处理函数使用SetDiff函数设置变更内容。
+func NewMiddleware(a *Auditor) eudore.HandlerFunc {
+	return func(ctx eudore.Context) {
+		switch ctx.Method() {
+		case eudore.MethodGet, eudore.MethodHead, eudore.MethodOptions:
+			return
+		}
+		action := ctx.GetParam(eudore.ParamAction)
+		if action == "" {
+			return
+		}
+
+		diff := new(interface{})
+		ctx.WithContext(context.WithValue(ctx.GetContext(), auditDiffKey{}, diff))
+		ctx.Next()
+
+		subject := ctx.GetParam(eudore.ParamUNAME)
+		if subject == "" {
+			subject = ctx.GetParam(eudore.ParamUID)
+		}
+		err := a.Record(&Record{
+			Subject:   subject,
+			Action:    action,
+			Resource:  ctx.Path(),
+			Result:    fmt.Sprint(ctx.Response().Status()),
+			IP:        ctx.RealIP(),
+			RequestID: ctx.RequestID(),
+		}, *diff)
+		if err != nil {
+			ctx.Error(err)
+		}
+	}
+}
+
+// SetDiff 函数设置当前请求审计记录的变更内容，例如map[string]interface{}{"before": old, "after": new}。
+func SetDiff(ctx eudore.Context, diff interface{}) {
+	p, ok := ctx.GetContext().Value(auditDiffKey{}).(*interface{})
+	if ok {
+		*p = diff
+	}
+}
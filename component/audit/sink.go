@@ -0,0 +1,142 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// sinkFile 定义json lines文件输出。
+type sinkFile struct {
+	sync.Mutex
+	file *os.File
+	last string
+}
+
+// NewSinkFile 函数创建一个json lines文件输出，文件已存在时读取最后一条记录Hash。
+func NewSinkFile(path string) (Sink, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	sink := &sinkFile{file: file}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var r Record
+		if json.Unmarshal(scanner.Bytes(), &r) == nil && r.Hash != "" {
+			sink.last = r.Hash
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return sink, nil
+}
+
+// Write 方法写入一行记录。
+func (s *sinkFile) Write(r *Record) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	s.Lock()
+	defer s.Unlock()
+	_, err = s.file.Write(append(body, '\n'))
+	if err == nil {
+		s.last = r.Hash
+	}
+	return err
+}
+
+// LastHash 方法返回文件最后一条记录的Hash。
+func (s *sinkFile) LastHash() string {
+	s.Lock()
+	defer s.Unlock()
+	return s.last
+}
+
+// Close 方法关闭文件。
+func (s *sinkFile) Close() error {
+	return s.file.Close()
+}
+
+// sinkSQL 定义数据库输出。
+type sinkSQL struct {
+	db    *sql.DB
+	query string
+}
+
+// NewSinkSQL 函数创建一个数据库输出，query是插入语句，参数依次为:
+// time, subject, action, resource, result, ip, requestid, diff, prev, hash。
+//
+// 例如: INSERT INTO audit(time,subject,action,resource,result,ip,requestid,diff,prev,hash) VALUES(?,?,?,?,?,?,?,?,?,?)
+func NewSinkSQL(db *sql.DB, query string) Sink {
+	return &sinkSQL{db: db, query: query}
+}
+
+// Write 方法插入一条记录。
+func (s *sinkSQL) Write(r *Record) error {
+	return s.WriteContext(context.Background(), r)
+}
+
+// WriteContext 方法使用ctx插入一条记录。
+func (s *sinkSQL) WriteContext(ctx context.Context, r *Record) error {
+	_, err := s.db.ExecContext(ctx, s.query, r.Time, r.Subject, r.Action, r.Resource, r.Result, r.IP, r.RequestID, string(r.Diff), r.Prev, r.Hash)
+	return err
+}
+
+// sinkWebhook 定义webhook输出。
+type sinkWebhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewSinkWebhook 函数创建一个webhook输出，使用POST方法发送json记录，client为空使用超时10秒的http.Client。
+func NewSinkWebhook(url string, client *http.Client) Sink {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &sinkWebhook{url: url, client: client}
+}
+
+// Write 方法发送一条记录，响应状态码非2xx返回错误。
+func (s *sinkWebhook) Write(r *Record) error {
+	return s.WriteContext(context.Background(), r)
+}
+
+// WriteContext 方法使用ctx发送一条记录。
+func (s *sinkWebhook) WriteContext(ctx context.Context, r *Record) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("audit webhook %s response status %d", s.url, resp.StatusCode)
+	}
+	return nil
+}
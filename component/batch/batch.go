@@ -0,0 +1,279 @@
+// Package batch 实现批量请求处理，接收multipart/mixed或json数组格式的批量请求，
+// 每个子请求在进程内使用App的路由和中间件处理，子请求同样经过认证等中间件，然后合并全部子请求的响应返回。
+//
+// multipart/mixed格式兼容OData和Google批量请求，每个part的Content-Type为application/http，内容为一个http请求，
+// 响应的每个part为对应的http响应，请求part的Content-ID会设置到响应part。
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/eudore/eudore"
+)
+
+type (
+	// Batch 定义批量请求处理者。
+	Batch struct {
+		Handler http.Handler
+		// MaxRequests 定义一次批量请求最多的子请求数量。
+		MaxRequests int
+		// Headers 定义子请求未设置时从批量请求复制的header，默认复制认证使用的Authorization和Cookie。
+		Headers []string
+	}
+	// Request 定义json格式的子请求，body为json时直接使用json值，否则为字符串。
+	Request struct {
+		ID      string            `json:"id,omitempty"`
+		Method  string            `json:"method"`
+		Path    string            `json:"path"`
+		Headers map[string]string `json:"headers,omitempty"`
+		Body    json.RawMessage   `json:"body,omitempty"`
+	}
+	// Response 定义json格式的子响应，响应为json时body为json值，否则为字符串。
+	Response struct {
+		ID      string            `json:"id,omitempty"`
+		Status  int               `json:"status"`
+		Headers map[string]string `json:"headers,omitempty"`
+		Body    json.RawMessage   `json:"body,omitempty"`
+	}
+	batchKey struct{}
+)
+
+const (
+	mimeApplicationHTTP = "application/http"
+	mimeMultipartMixed  = "multipart/mixed"
+)
+
+// 定义批量请求处理的错误。
+var (
+	ErrBatchNested      = errors.New("batch request can not contain batch request")
+	ErrBatchTooMany     = errors.New("batch request contains too many requests")
+	ErrBatchContentType = errors.New("batch request Content-Type must be multipart/mixed or application/json")
+	ErrResponseHijack   = errors.New("batch response not support Hijack method")
+)
+
+// NewBatch 函数使用App创建一个批量请求处理者，使用app.PostFunc("/batch", batch.NewBatch(app).HandleHTTP)注册路由。
+func NewBatch(app *eudore.App) *Batch {
+	return &Batch{
+		Handler:     app,
+		MaxRequests: 100,
+		Headers:     []string{eudore.HeaderAuthorization, eudore.HeaderCookie},
+	}
+}
+
+// HandleHTTP 方法根据Content-Type处理multipart/mixed或json数组格式的批量请求，子请求按顺序处理，不允许嵌套批量请求。
+func (b *Batch) HandleHTTP(ctx eudore.Context) {
+	if ctx.GetContext().Value(batchKey{}) != nil {
+		writeError(ctx, eudore.StatusBadRequest, ErrBatchNested)
+		return
+	}
+	mediatype, params, _ := mime.ParseMediaType(ctx.GetHeader(eudore.HeaderContentType))
+	switch mediatype {
+	case mimeMultipartMixed:
+		b.handleMultipart(ctx, params["boundary"])
+	case eudore.MimeApplicationJSON:
+		b.handleJSON(ctx)
+	default:
+		writeError(ctx, eudore.StatusUnsupportedMediaType, ErrBatchContentType)
+	}
+}
+
+func (b *Batch) handleJSON(ctx eudore.Context) {
+	var reqs []Request
+	err := json.NewDecoder(ctx).Decode(&reqs)
+	if err != nil {
+		writeError(ctx, eudore.StatusBadRequest, err)
+		return
+	}
+	if b.MaxRequests > 0 && len(reqs) > b.MaxRequests {
+		writeError(ctx, eudore.StatusRequestEntityTooLarge, ErrBatchTooMany)
+		return
+	}
+
+	resps := make([]Response, len(reqs))
+	for i, req := range reqs {
+		resps[i].ID = req.ID
+		r, err := req.newRequest()
+		if err != nil {
+			resps[i].Status = eudore.StatusBadRequest
+			resps[i].Body, _ = json.Marshal(err.Error())
+			continue
+		}
+		w := b.serve(ctx, r)
+		resps[i].Status = w.code
+		resps[i].Headers = make(map[string]string, len(w.header))
+		for k := range w.header {
+			resps[i].Headers[k] = w.header.Get(k)
+		}
+		if w.buffer.Len() > 0 {
+			if strings.HasPrefix(w.header.Get(eudore.HeaderContentType), eudore.MimeApplicationJSON) && json.Valid(w.buffer.Bytes()) {
+				resps[i].Body = w.buffer.Bytes()
+			} else {
+				resps[i].Body, _ = json.Marshal(w.buffer.String())
+			}
+		}
+	}
+	ctx.SetHeader(eudore.HeaderContentType, eudore.MimeApplicationJSONUtf8)
+	json.NewEncoder(ctx).Encode(resps)
+}
+
+func (b *Batch) handleMultipart(ctx eudore.Context, boundary string) {
+	if boundary == "" {
+		writeError(ctx, eudore.StatusBadRequest, http.ErrMissingBoundary)
+		return
+	}
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	mr := multipart.NewReader(ctx, boundary)
+	for i := 0; ; i++ {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writeError(ctx, eudore.StatusBadRequest, err)
+			return
+		}
+		if b.MaxRequests > 0 && i >= b.MaxRequests {
+			writeError(ctx, eudore.StatusRequestEntityTooLarge, ErrBatchTooMany)
+			return
+		}
+
+		header := textproto.MIMEHeader{eudore.HeaderContentType: {mimeApplicationHTTP}}
+		if id := part.Header.Get("Content-ID"); id != "" {
+			header.Set("Content-ID", "response-"+strings.Trim(id, "<>"))
+		}
+		pw, _ := mw.CreatePart(header)
+		data, err := ioutil.ReadAll(part)
+		if err != nil {
+			writeError(ctx, eudore.StatusBadRequest, err)
+			return
+		}
+		// 无body的请求part可能缺少header结束的空行。
+		if !bytes.Contains(data, []byte("\r\n\r\n")) && !bytes.Contains(data, []byte("\n\n")) {
+			data = append(data, "\r\n\r\n"...)
+		}
+		r, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(data)))
+		if err != nil {
+			fmt.Fprintf(pw, "HTTP/1.1 400 Bad Request\r\nContent-Type: text/plain\r\n\r\n%s", err.Error())
+			continue
+		}
+		r.RequestURI = ""
+		w := b.serve(ctx, r)
+		resp := &http.Response{
+			StatusCode:    w.code,
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        w.header,
+			ContentLength: int64(w.buffer.Len()),
+			Body:          ioutil.NopCloser(&w.buffer),
+		}
+		resp.Write(pw)
+	}
+	mw.Close()
+	ctx.SetHeader(eudore.HeaderContentType, mimeMultipartMixed+"; boundary="+mw.Boundary())
+	ctx.Write(buf.Bytes())
+}
+
+// serve 方法使用批量请求的context、远程地址和认证header处理子请求。
+func (b *Batch) serve(ctx eudore.Context, r *http.Request) *responseWriter {
+	parent := ctx.Request()
+	r = r.WithContext(context.WithValue(ctx.GetContext(), batchKey{}, parent))
+	r.RemoteAddr = parent.RemoteAddr
+	r.TLS = parent.TLS
+	if r.Host == "" {
+		r.Host = parent.Host
+	}
+	for _, key := range b.Headers {
+		if r.Header.Get(key) == "" && parent.Header.Get(key) != "" {
+			r.Header[key] = parent.Header[key]
+		}
+	}
+	if r.Header.Get(eudore.HeaderXRequestID) == "" && ctx.GetHeader(eudore.HeaderXRequestID) != "" {
+		r.Header.Set(eudore.HeaderXRequestID, ctx.GetHeader(eudore.HeaderXRequestID))
+	}
+
+	w := &responseWriter{header: make(http.Header), code: eudore.StatusOK}
+	b.Handler.ServeHTTP(w, r)
+	return w
+}
+
+// newRequest 方法将json子请求转换成http请求，json字符串body使用字符串内容。
+func (req *Request) newRequest() (*http.Request, error) {
+	body := []byte(req.Body)
+	var str string
+	if json.Unmarshal(body, &str) == nil {
+		body = []byte(str)
+	}
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = eudore.MethodGet
+	}
+	r, err := http.NewRequest(method, req.Path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range req.Headers {
+		r.Header.Set(k, v)
+	}
+	if len(body) > 0 && str == "" && r.Header.Get(eudore.HeaderContentType) == "" {
+		r.Header.Set(eudore.HeaderContentType, eudore.MimeApplicationJSON)
+	}
+	return r, nil
+}
+
+// GetRequest 函数获取子请求所属的批量请求，非批量子请求返回nil。
+func GetRequest(ctx eudore.Context) *http.Request {
+	r, _ := ctx.GetContext().Value(batchKey{}).(*http.Request)
+	return r
+}
+
+func writeError(ctx eudore.Context, code int, err error) {
+	ctx.WriteHeader(code)
+	ctx.Fatal(err)
+	ctx.End()
+}
+
+// responseWriter 定义子请求的响应，记录状态码、header和响应内容。
+type responseWriter struct {
+	header http.Header
+	code   int
+	wrote  bool
+	buffer bytes.Buffer
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.wrote = true
+	return w.buffer.Write(p)
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wrote && code >= 200 {
+		w.code = code
+		w.wrote = true
+	}
+}
+
+func (w *responseWriter) Flush() {
+	// Do nothing because batch response is written after all requests.
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, ErrResponseHijack
+}
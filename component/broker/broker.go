@@ -0,0 +1,146 @@
+// Package broker 实现消息队列订阅适配，将订阅的消息转换成请求使用eudore.App的路由和中间件处理。
+//
+// 消息topic转换成路由路径，例如topic为orders.created的消息会使用POST /broker/orders/created路由处理，
+// 处理函数使用ctx.Body()读取消息内容，使用GetMessage函数获取原始消息，响应内容会作为请求-响应模式的回复。
+package broker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+type (
+	// Message 定义一条订阅的消息，Reply非空时表示消息需要回复。
+	Message struct {
+		Topic  string
+		Data   []byte
+		Header http.Header
+		Reply  func([]byte) error
+	}
+	// Subscriber 定义消息订阅者，Subscribe方法阻塞处理消息直到ctx结束或连接错误。
+	Subscriber interface {
+		Subscribe(context.Context, []string, func(*Message)) error
+	}
+	// SubscriberFunc 定义函数类型的Subscriber，用于适配其他客户端库。
+	SubscriberFunc func(context.Context, []string, func(*Message)) error
+	// Dispatcher 定义消息分发者，将消息转换成请求交给Handler处理。
+	Dispatcher struct {
+		Handler http.Handler
+		Logger  eudore.Logout
+		Prefix  string
+		// Subscribe返回错误后重新订阅的等待时间。
+		Backoff time.Duration
+	}
+	messageKey struct{}
+)
+
+// ErrResponseHijack 定义消息响应不支持Hijack的错误。
+var ErrResponseHijack = errors.New("broker response not support Hijack method")
+
+// Subscribe 方法实现Subscriber接口。
+func (fn SubscriberFunc) Subscribe(ctx context.Context, topics []string, handler func(*Message)) error {
+	return fn(ctx, topics, handler)
+}
+
+// NewDispatcher 函数使用App创建一个消息分发者，路由前缀默认为/broker。
+func NewDispatcher(app *eudore.App) *Dispatcher {
+	return &Dispatcher{
+		Handler: app,
+		Logger:  app.Logger,
+		Prefix:  "/broker",
+		Backoff: time.Second,
+	}
+}
+
+// Run 方法使用Subscriber订阅topics并分发消息，订阅错误时等待Backoff后重新订阅，直到ctx结束。
+func (d *Dispatcher) Run(ctx context.Context, sub Subscriber, topics ...string) error {
+	for {
+		err := sub.Subscribe(ctx, topics, d.HandleMessage)
+		if ctx.Err() != nil {
+			return nil
+		}
+		d.Logger.WithField("topics", topics).Error("broker subscribe error:", err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(d.Backoff):
+		}
+	}
+}
+
+// HandleMessage 方法将消息转换成POST请求交给Handler处理，捕捉处理panic并输出日志。
+func (d *Dispatcher) HandleMessage(msg *Message) {
+	start := time.Now()
+	w := &responseWriter{header: make(http.Header), code: 200}
+	log := d.Logger.WithField("topic", msg.Topic).WithFields(nil)
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			log.Errorf("broker handle message panic: %v", rerr)
+			return
+		}
+		if w.code >= 400 {
+			log.WithFields(eudore.Fields{"status": w.code, "duration": time.Since(start).String()}).Error("broker handle message error:", strings.TrimSpace(w.buffer.String()))
+		} else {
+			log.WithFields(eudore.Fields{"status": w.code, "duration": time.Since(start).String()}).Debug("broker handle message")
+		}
+		if msg.Reply != nil {
+			err := msg.Reply(w.buffer.Bytes())
+			if err != nil {
+				log.Error("broker reply message error:", err)
+			}
+		}
+	}()
+
+	req, err := http.NewRequest(eudore.MethodPost, d.Prefix+"/"+strings.Replace(msg.Topic, ".", "/", -1), bytes.NewReader(msg.Data))
+	if err != nil {
+		log.Error("broker create request error:", err)
+		return
+	}
+	for k, v := range msg.Header {
+		req.Header[k] = v
+	}
+	req.RemoteAddr = "broker:0"
+	req.Header.Set("X-Broker-Topic", msg.Topic)
+	d.Handler.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), messageKey{}, msg)))
+}
+
+// GetMessage 函数获取请求对应的原始消息，非消息请求返回nil。
+func GetMessage(ctx eudore.Context) *Message {
+	msg, _ := ctx.GetContext().Value(messageKey{}).(*Message)
+	return msg
+}
+
+// responseWriter 定义消息处理的响应，记录状态码和响应内容。
+type responseWriter struct {
+	header http.Header
+	code   int
+	buffer bytes.Buffer
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	return w.buffer.Write(p)
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	w.code = code
+}
+
+func (w *responseWriter) Flush() {
+	// Do nothing because message response not support flush.
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, ErrResponseHijack
+}
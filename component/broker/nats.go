@@ -0,0 +1,127 @@
+package broker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NATSSubscriber 定义NATS订阅者，使用NATS文本协议直接连接服务端，Queue非空时使用队列组订阅。
+type NATSSubscriber struct {
+	Addr        string
+	User        string
+	Pass        string
+	Token       string
+	Queue       string
+	DialTimeout time.Duration
+}
+
+// NewNATSSubscriber 函数创建一个NATS订阅者。
+func NewNATSSubscriber(addr string) *NATSSubscriber {
+	return &NATSSubscriber{
+		Addr:        addr,
+		DialTimeout: 5 * time.Second,
+	}
+}
+
+// Subscribe 方法订阅subjects，按顺序处理收到的消息，消息带有reply时响应内容会发布到reply，ctx结束时关闭连接。
+func (s *NATSSubscriber) Subscribe(ctx context.Context, topics []string, fn func(*Message)) error {
+	conn, err := net.DialTimeout("tcp", s.Addr, s.DialTimeout)
+	if err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("nats invalid info: %q", line)
+	}
+
+	var mu sync.Mutex
+	write := func(data string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		_, err := io.WriteString(conn, data)
+		return err
+	}
+	connect, _ := json.Marshal(map[string]interface{}{
+		"verbose":    false,
+		"pedantic":   false,
+		"name":       "eudore",
+		"user":       s.User,
+		"pass":       s.Pass,
+		"auth_token": s.Token,
+	})
+	cmds := "CONNECT " + string(connect) + "\r\n"
+	for i, topic := range topics {
+		if s.Queue != "" {
+			cmds += fmt.Sprintf("SUB %s %s %d\r\n", topic, s.Queue, i+1)
+		} else {
+			cmds += fmt.Sprintf("SUB %s %d\r\n", topic, i+1)
+		}
+	}
+	err = write(cmds + "PING\r\n")
+	if err != nil {
+		return err
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(line, "MSG "):
+			// MSG <subject> <sid> [reply-to] <#bytes>
+			args := strings.Fields(line[4:])
+			if len(args) != 3 && len(args) != 4 {
+				return fmt.Errorf("nats invalid msg: %q", line)
+			}
+			n, err := strconv.Atoi(args[len(args)-1])
+			if err != nil {
+				return fmt.Errorf("nats invalid msg: %q", line)
+			}
+			data := make([]byte, n+2)
+			_, err = io.ReadFull(reader, data)
+			if err != nil {
+				return err
+			}
+			msg := &Message{Topic: args[0], Data: data[:n]}
+			if len(args) == 4 {
+				reply := args[2]
+				msg.Reply = func(body []byte) error {
+					return write(fmt.Sprintf("PUB %s %d\r\n%s\r\n", reply, len(body), body))
+				}
+			}
+			fn(msg)
+		case line == "PING":
+			err = write("PONG\r\n")
+			if err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New("nats " + strings.TrimSpace(line[1:]))
+		}
+	}
+}
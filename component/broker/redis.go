@@ -0,0 +1,66 @@
+package broker
+
+import (
+	"context"
+
+	"github.com/eudore/eudore/component/redis"
+)
+
+// RedisSubscriber 定义Redis Pub/Sub订阅者，Pattern为true时使用PSUBSCRIBE订阅。
+type RedisSubscriber struct {
+	*redis.Client
+	Pattern bool
+}
+
+// NewRedisSubscriber 函数创建一个Redis订阅者。
+func NewRedisSubscriber(addr, password string) *RedisSubscriber {
+	return &RedisSubscriber{Client: redis.NewClient(addr, password, 0)}
+}
+
+// Subscribe 方法订阅channels，按顺序处理收到的消息，ctx结束时关闭连接。
+func (s *RedisSubscriber) Subscribe(ctx context.Context, topics []string, fn func(*Message)) error {
+	conn, reader, err := s.Dial()
+	if err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+
+	cmd := "SUBSCRIBE"
+	if s.Pattern {
+		cmd = "PSUBSCRIBE"
+	}
+	args := make([]interface{}, len(topics))
+	for i := range topics {
+		args[i] = topics[i]
+	}
+	_, err = redis.WriteCommand(conn, cmd, args...)
+	if err != nil {
+		return err
+	}
+	for {
+		reply, err := redis.ReadReply(reader)
+		if err != nil {
+			return err
+		}
+		vals, ok := reply.([]interface{})
+		if !ok || len(vals) < 3 {
+			continue
+		}
+		switch redis.String(vals[0]) {
+		case "message":
+			fn(&Message{Topic: redis.String(vals[1]), Data: []byte(redis.String(vals[2]))})
+		case "pmessage":
+			if len(vals) == 4 {
+				fn(&Message{Topic: redis.String(vals[2]), Data: []byte(redis.String(vals[3]))})
+			}
+		}
+	}
+}
@@ -0,0 +1,223 @@
+// Package database 封装database/sql，实现配置创建连接池、健康检查、慢查询日志、查询追踪和指标统计，App结束时关闭连接池。
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+type (
+	// Config 定义数据库配置。
+	Config struct {
+		Driver          string        `alias:"driver" json:"driver" description:"database/sql driver name."`
+		DSN             string        `alias:"dsn" json:"dsn" description:"database/sql data source name."`
+		MaxOpenConns    int           `alias:"maxopenconns" json:"maxopenconns" description:"Maximum number of open connections."`
+		MaxIdleConns    int           `alias:"maxidleconns" json:"maxidleconns" description:"Maximum number of idle connections."`
+		ConnMaxLifetime time.Duration `alias:"connmaxlifetime" json:"connmaxlifetime" description:"Maximum amount of time a connection may be reused."`
+		SlowQuery       time.Duration `alias:"slowquery" json:"slowquery" description:"Slow query log threshold, default: 200ms."`
+		PingTimeout     time.Duration `alias:"pingtimeout" json:"pingtimeout" description:"Health check ping timeout, default: 3s."`
+	}
+	// DB 定义数据库连接池，Exec、Query、QueryRow和事务内查询都会记录指标、慢查询日志和追踪。
+	DB struct {
+		*sql.DB
+		Config
+		Logger eudore.Logout
+		// Tracer 在每次查询开始时调用，返回查询结束时调用的函数，用于对接追踪系统创建span。
+		Tracer      TraceFunc
+		queries     int64
+		errors      int64
+		slowQueries int64
+		duration    int64
+	}
+	// Tx 定义数据库事务，事务内查询与DB记录相同的指标。
+	Tx struct {
+		*sql.Tx
+		db *DB
+	}
+	// TraceFunc 定义查询追踪函数。
+	TraceFunc func(context.Context, string) (context.Context, func(error))
+	// Stats 定义数据库统计指标。
+	Stats struct {
+		sql.DBStats
+		Queries     int64  `json:"queries"`
+		Errors      int64  `json:"errors"`
+		SlowQueries int64  `json:"slowqueries"`
+		QueryTime   string `json:"querytime"`
+	}
+)
+
+// NewDB 函数使用*Config或map创建数据库连接池，app结束时关闭连接池。
+func NewDB(app *eudore.App, arg interface{}) (*DB, error) {
+	db := &DB{
+		Config: Config{
+			SlowQuery:   200 * time.Millisecond,
+			PingTimeout: 3 * time.Second,
+		},
+		Logger: app.Logger,
+	}
+	eudore.ConvertTo(arg, &db.Config)
+	sqldb, err := sql.Open(db.Config.Driver, db.DSN)
+	if err != nil {
+		return nil, err
+	}
+	sqldb.SetMaxOpenConns(db.MaxOpenConns)
+	sqldb.SetMaxIdleConns(db.MaxIdleConns)
+	sqldb.SetConnMaxLifetime(db.ConnMaxLifetime)
+	db.DB = sqldb
+	go func() {
+		<-app.Done()
+		// sql.DB.Close会等待已经开始的查询结束。
+		err := db.Close()
+		if err != nil {
+			db.Logger.Error("database close error:", err)
+		}
+	}()
+	return db, nil
+}
+
+// ExecContext 方法执行一条语句。
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, end := db.trace(ctx, query)
+	result, err := db.DB.ExecContext(ctx, query, args...)
+	end(err)
+	return result, err
+}
+
+// Exec 方法执行一条语句。
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.ExecContext(context.Background(), query, args...)
+}
+
+// QueryContext 方法执行一条查询。
+func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, end := db.trace(ctx, query)
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	end(err)
+	return rows, err
+}
+
+// Query 方法执行一条查询。
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.QueryContext(context.Background(), query, args...)
+}
+
+// QueryRowContext 方法执行一条查询并返回一行。
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, end := db.trace(ctx, query)
+	row := db.DB.QueryRowContext(ctx, query, args...)
+	end(row.Err())
+	return row
+}
+
+// QueryRow 方法执行一条查询并返回一行。
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.QueryRowContext(context.Background(), query, args...)
+}
+
+// BeginTx 方法开始一个事务。
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, db: db}, nil
+}
+
+// Begin 方法开始一个事务。
+func (db *DB) Begin() (*Tx, error) {
+	return db.BeginTx(context.Background(), nil)
+}
+
+// Stats 方法返回连接池和查询的统计指标。
+func (db *DB) Stats() Stats {
+	return Stats{
+		DBStats:     db.DB.Stats(),
+		Queries:     atomic.LoadInt64(&db.queries),
+		Errors:      atomic.LoadInt64(&db.errors),
+		SlowQueries: atomic.LoadInt64(&db.slowQueries),
+		QueryTime:   time.Duration(atomic.LoadInt64(&db.duration)).String(),
+	}
+}
+
+// HandleHealth 方法实现健康检查处理函数，Ping失败时返回503，使用app.GetFunc("/health/database", db.HandleHealth)注册。
+func (db *DB) HandleHealth(ctx eudore.Context) {
+	c, cancel := context.WithTimeout(ctx.GetContext(), db.PingTimeout)
+	defer cancel()
+	status := "ok"
+	err := db.PingContext(c)
+	if err != nil {
+		status = err.Error()
+		ctx.WriteHeader(eudore.StatusServiceUnavailable)
+	}
+	ctx.Render(map[string]interface{}{
+		"status": status,
+		"stats":  db.Stats(),
+	})
+}
+
+// trace 方法开始记录一次查询，返回查询结束时调用的函数。
+func (db *DB) trace(ctx context.Context, query string) (context.Context, func(error)) {
+	start := time.Now()
+	var end func(error)
+	if db.Tracer != nil {
+		ctx, end = db.Tracer(ctx, query)
+	}
+	return ctx, func(err error) {
+		duration := time.Since(start)
+		atomic.AddInt64(&db.queries, 1)
+		atomic.AddInt64(&db.duration, int64(duration))
+		if err != nil && err != sql.ErrNoRows {
+			atomic.AddInt64(&db.errors, 1)
+			db.Logger.WithFields(eudore.Fields{"query": query, "duration": duration.String()}).Error("database query error:", err)
+		} else if db.SlowQuery > 0 && duration >= db.SlowQuery {
+			atomic.AddInt64(&db.slowQueries, 1)
+			db.Logger.WithFields(eudore.Fields{"query": query, "duration": duration.String()}).Warning("database slow query")
+		}
+		if end != nil {
+			end(err)
+		}
+	}
+}
+
+// ExecContext 方法在事务内执行一条语句。
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, end := tx.db.trace(ctx, query)
+	result, err := tx.Tx.ExecContext(ctx, query, args...)
+	end(err)
+	return result, err
+}
+
+// Exec 方法在事务内执行一条语句。
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.ExecContext(context.Background(), query, args...)
+}
+
+// QueryContext 方法在事务内执行一条查询。
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, end := tx.db.trace(ctx, query)
+	rows, err := tx.Tx.QueryContext(ctx, query, args...)
+	end(err)
+	return rows, err
+}
+
+// Query 方法在事务内执行一条查询。
+func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.QueryContext(context.Background(), query, args...)
+}
+
+// QueryRowContext 方法在事务内执行一条查询并返回一行。
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, end := tx.db.trace(ctx, query)
+	row := tx.Tx.QueryRowContext(ctx, query, args...)
+	end(row.Err())
+	return row
+}
+
+// QueryRow 方法在事务内执行一条查询并返回一行。
+func (tx *Tx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return tx.QueryRowContext(context.Background(), query, args...)
+}
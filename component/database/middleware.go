@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"io"
+
+	"github.com/eudore/eudore"
+)
+
+type txKey struct{}
+
+// NewTxFunc 函数创建事务中间件，每个请求开启一个事务保存到请求上下文，使用GetTx函数获取。
+//
+// 响应首次写入body或处理结束时结束事务，响应状态码为2xx且请求上下文没有错误时提交事务，否则回滚事务；
+// 提交失败时响应500并丢弃处理函数写入的body，处理函数panic时回滚事务后继续panic。
+//
+// 路由参数tx=false时不开启事务，例如：app.GetFunc("/users tx=false", handler)。
+func NewTxFunc(db *DB, opts ...*sql.TxOptions) eudore.HandlerFunc {
+	var opt *sql.TxOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return func(ctx eudore.Context) {
+		if ctx.GetParam("tx") == "false" {
+			return
+		}
+		tx, err := db.BeginTx(ctx.GetContext(), opt)
+		if err != nil {
+			ctx.WriteHeader(eudore.StatusServiceUnavailable)
+			ctx.Fatal(err)
+			ctx.End()
+			return
+		}
+		ctx.WithContext(context.WithValue(ctx.GetContext(), txKey{}, tx))
+		w := &txResponse{ResponseWriter: ctx.Response(), ctx: ctx, tx: tx}
+		ctx.SetResponse(w)
+
+		defer func() {
+			ctx.SetResponse(w.ResponseWriter)
+			if rerr := recover(); rerr != nil {
+				tx.Rollback()
+				panic(rerr)
+			}
+			w.finish()
+		}()
+		ctx.Next()
+	}
+}
+
+// txResponse 定义在响应首次写入body前结束事务的ResponseWriter。
+type txResponse struct {
+	eudore.ResponseWriter
+	ctx  eudore.Context
+	tx   *Tx
+	done bool
+	err  error
+}
+
+// finish 方法结束事务，提交失败时响应500并返回提交错误。
+func (w *txResponse) finish() error {
+	if w.done {
+		return w.err
+	}
+	w.done = true
+	status := w.ResponseWriter.Status()
+	if status < 200 || status > 299 || w.ctx.Err() != nil {
+		err := w.tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			w.ctx.Error("database rollback error:", err)
+		}
+		return nil
+	}
+	err := w.tx.Commit()
+	if err != nil && err != sql.ErrTxDone {
+		w.ctx.Error("database commit error:", err)
+		w.err = err
+		w.ResponseWriter.WriteHeader(eudore.StatusInternalServerError)
+	}
+	return w.err
+}
+
+// Write 方法实现ResponseWriter中的Write方法。
+func (w *txResponse) Write(data []byte) (int, error) {
+	if err := w.finish(); err != nil {
+		return 0, err
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 方法实现io.StringWriter接口。
+func (w *txResponse) WriteString(data string) (int, error) {
+	if err := w.finish(); err != nil {
+		return 0, err
+	}
+	return io.WriteString(w.ResponseWriter, data)
+}
+
+// Flush 方法实现ResponseWriter中的Flush方法。
+func (w *txResponse) Flush() {
+	if w.finish() == nil {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// GetTx 函数获取请求上下文中的事务，没有开启事务时返回nil。
+func GetTx(ctx eudore.Context) *Tx {
+	tx, _ := ctx.GetContext().Value(txKey{}).(*Tx)
+	return tx
+}
@@ -0,0 +1,85 @@
+package discovery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// consul 定义Consul注册中心，使用Agent HTTP API。
+type consul struct {
+	addr   string
+	token  string
+	client *http.Client
+}
+
+// NewConsul 函数创建Consul注册中心，addr为Agent地址，例如http://127.0.0.1:8500。
+//
+// 实例注册TTL检查，Health非空时同时注册HTTP检查。
+func NewConsul(addr, token string) Registry {
+	return &consul{
+		addr:   addr,
+		token:  token,
+		client: &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+// Register 方法注册服务实例。
+func (c *consul) Register(ins *Instance) error {
+	checks := []map[string]interface{}{{
+		"CheckID":                        "service:" + ins.ID + ":ttl",
+		"TTL":                            ins.TTL.String(),
+		"DeregisterCriticalServiceAfter": (ins.TTL * 6).String(),
+	}}
+	if ins.Health != "" {
+		checks = append(checks, map[string]interface{}{
+			"CheckID":  "service:" + ins.ID + ":http",
+			"HTTP":     ins.Health,
+			"Interval": ins.TTL.String(),
+		})
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"ID":      ins.ID,
+		"Name":    ins.Name,
+		"Address": ins.Address,
+		"Port":    ins.Port,
+		"Tags":    ins.Tags,
+		"Meta":    ins.Meta,
+		"Checks":  checks,
+	})
+	return c.do("/v1/agent/service/register", bytes.NewReader(body))
+}
+
+// Heartbeat 方法更新TTL检查状态。
+func (c *consul) Heartbeat(ins *Instance) error {
+	return c.do("/v1/agent/check/pass/service:"+ins.ID+":ttl", nil)
+}
+
+// Deregister 方法注销服务实例。
+func (c *consul) Deregister(ins *Instance) error {
+	return c.do("/v1/agent/service/deregister/"+ins.ID, nil)
+}
+
+func (c *consul) do(path string, body io.Reader) error {
+	req, err := http.NewRequest(http.MethodPut, c.addr+path, body)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("consul %s status %d: %s", path, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Package discovery 实现服务注册，启动时将实例注册到Consul或etcd，使用TTL心跳保持注册，App结束时注销实例。
+package discovery
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+type (
+	// Registry 定义服务注册中心。
+	Registry interface {
+		Register(*Instance) error
+		Heartbeat(*Instance) error
+		Deregister(*Instance) error
+	}
+	// Instance 定义注册的服务实例，Health为健康检查地址。
+	Instance struct {
+		ID      string            `alias:"id" json:"id"`
+		Name    string            `alias:"name" json:"name"`
+		Address string            `alias:"address" json:"address"`
+		Port    int               `alias:"port" json:"port"`
+		Tags    []string          `alias:"tags" json:"tags"`
+		Meta    map[string]string `alias:"meta" json:"meta"`
+		Health  string            `alias:"health" json:"health"`
+		TTL     time.Duration     `alias:"ttl" json:"ttl"`
+	}
+)
+
+// Register 函数使用*Instance或map创建实例并注册，每TTL/3发送一次心跳，心跳失败时重新注册，app结束时注销实例。
+//
+// ID默认为name-hostname-port，TTL默认10秒。
+func Register(app *eudore.App, reg Registry, arg interface{}) (*Instance, error) {
+	ins := &Instance{TTL: 10 * time.Second}
+	eudore.ConvertTo(arg, ins)
+	if ins.ID == "" {
+		hostname, _ := os.Hostname()
+		ins.ID = fmt.Sprintf("%s-%s-%d", ins.Name, hostname, ins.Port)
+	}
+	err := reg.Register(ins)
+	if err != nil {
+		return nil, err
+	}
+
+	log := app.WithField("service", ins.ID).WithFields(nil)
+	log.Info("discovery register instance")
+	go func() {
+		ticker := time.NewTicker(ins.TTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				err := reg.Heartbeat(ins)
+				if err == nil {
+					continue
+				}
+				log.Warning("discovery heartbeat error:", err)
+				err = reg.Register(ins)
+				if err != nil {
+					log.Error("discovery register error:", err)
+				}
+			case <-app.Done():
+				// app.Run在关闭Server前等待100ms，先注销实例让负载均衡停止转发请求。
+				err := reg.Deregister(ins)
+				if err != nil {
+					log.Error("discovery deregister error:", err)
+				} else {
+					log.Info("discovery deregister instance")
+				}
+				return
+			}
+		}
+	}()
+	return ins, nil
+}
@@ -0,0 +1,105 @@
+package discovery
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// etcd 定义etcd注册中心，使用v3 JSON gateway，实例信息保存在prefix/name/id，绑定TTL租约。
+type etcd struct {
+	sync.Mutex
+	addr   string
+	prefix string
+	client *http.Client
+	leases map[string]string
+}
+
+// NewEtcd 函数创建etcd注册中心，addr为etcd地址，例如http://127.0.0.1:2379，prefix为key前缀，例如/services。
+func NewEtcd(addr, prefix string) Registry {
+	return &etcd{
+		addr:   addr,
+		prefix: prefix,
+		client: &http.Client{Timeout: 3 * time.Second},
+		leases: make(map[string]string),
+	}
+}
+
+// Register 方法创建租约并写入实例信息。
+func (e *etcd) Register(ins *Instance) error {
+	var lease struct {
+		ID string `json:"ID"`
+	}
+	err := e.do("/v3/lease/grant", map[string]interface{}{"TTL": int64(ins.TTL / time.Second)}, &lease)
+	if err != nil {
+		return err
+	}
+	value, _ := json.Marshal(ins)
+	err = e.do("/v3/kv/put", map[string]interface{}{
+		"key":   base64.StdEncoding.EncodeToString([]byte(e.prefix + "/" + ins.Name + "/" + ins.ID)),
+		"value": base64.StdEncoding.EncodeToString(value),
+		"lease": lease.ID,
+	}, nil)
+	if err != nil {
+		return err
+	}
+	e.Lock()
+	e.leases[ins.ID] = lease.ID
+	e.Unlock()
+	return nil
+}
+
+// Heartbeat 方法续约租约，租约过期时返回错误。
+func (e *etcd) Heartbeat(ins *Instance) error {
+	var resp struct {
+		Result struct {
+			TTL string `json:"TTL"`
+		} `json:"result"`
+	}
+	err := e.do("/v3/lease/keepalive", map[string]interface{}{"ID": e.getLease(ins)}, &resp)
+	if err != nil {
+		return err
+	}
+	if resp.Result.TTL == "" || resp.Result.TTL == "0" {
+		return fmt.Errorf("etcd lease %s expired", e.getLease(ins))
+	}
+	return nil
+}
+
+// Deregister 方法撤销租约，删除实例信息。
+func (e *etcd) Deregister(ins *Instance) error {
+	id := e.getLease(ins)
+	e.Lock()
+	delete(e.leases, ins.ID)
+	e.Unlock()
+	return e.do("/v3/lease/revoke", map[string]interface{}{"ID": id}, nil)
+}
+
+func (e *etcd) getLease(ins *Instance) string {
+	e.Lock()
+	defer e.Unlock()
+	return e.leases[ins.ID]
+}
+
+func (e *etcd) do(path string, data, result interface{}) error {
+	body, _ := json.Marshal(data)
+	resp, err := e.client.Post(e.addr+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("etcd %s status %d: %s", path, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	if result != nil {
+		return json.NewDecoder(resp.Body).Decode(result)
+	}
+	return nil
+}
@@ -0,0 +1,341 @@
+// Package gateway 实现轻量API网关，使用路由表将请求转发给上游服务，路由表可以从配置加载并热更新。
+//
+// 每条路由依次执行限流、Basic认证、中间件链、路径重写，最后转发到上游，
+// 加载路由表时创建新的路由器，全部路由创建成功后原子替换，失败时保留当前路由表。
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/middleware"
+)
+
+type (
+	// Gateway 定义API网关，HandleHTTP方法使用当前路由表匹配请求。
+	Gateway struct {
+		sync.Mutex
+		app       *eudore.App
+		table     atomic.Value
+		routes    []*Route
+		balancers map[string]*eudore.ProxyBalancer
+		draining  map[string]bool
+		cancel    context.CancelFunc
+	}
+	// Route 定义一条网关路由。
+	Route struct {
+		// Path 为路由路径，支持eudore路由参数，例如/api/users/*。
+		Path string `json:"path"`
+		// Method 为路由方法，可以使用逗号分隔多个，默认ANY。
+		Method string `json:"method,omitempty"`
+		// Upstream 为上游地址，包含://时创建反向代理，逗号分隔多个上游时负载均衡，否则使用eudore.NewHandlerName创建，例如static:/var/www。
+		// 上游协议可以使用h2c、grpc、grpcs连接http2和gRPC上游。
+		Upstream string `json:"upstream"`
+		// Hash 为多个上游的一致性哈希key，格式为ip、header:name或cookie:name，为空时轮询。
+		Hash string `json:"hash,omitempty"`
+		// Sticky 为多个上游的粘性会话Cookie名称。
+		Sticky string `json:"sticky,omitempty"`
+		// Transform 为反向代理的请求和响应body转换，格式同eudore.NewProxyTransform，例如json-del:password。
+		Transform []string `json:"transform,omitempty"`
+		// Rewrite 为转发前的路径重写规则，格式同middleware.NewRewriteFunc。
+		Rewrite map[string]string `json:"rewrite,omitempty"`
+		// Auth 为Basic认证的用户名和密码。
+		Auth map[string]string `json:"auth,omitempty"`
+		// Limit 为按IP的限流配置。
+		Limit *Limit `json:"limit,omitempty"`
+		// Middleware 为中间件声明，格式同middleware.NewChainFuncs。
+		Middleware []interface{} `json:"middleware,omitempty"`
+	}
+	// Limit 定义路由限流，每秒增加Speed个令牌，最多Max个。
+	Limit struct {
+		Speed int64 `json:"speed"`
+		Max   int64 `json:"max"`
+	}
+)
+
+// NewGateway 函数创建一个API网关，初始路由表为空。
+func NewGateway(app *eudore.App) *Gateway {
+	gw := &Gateway{app: app, draining: make(map[string]bool)}
+	gw.table.Store(eudore.NewRouterRadix())
+	return gw
+}
+
+// HandleHTTP 方法使用当前路由表匹配请求并执行路由处理函数，未匹配返回404。
+func (gw *Gateway) HandleHTTP(ctx eudore.Context) {
+	router := gw.table.Load().(eudore.Router)
+	ctx.SetHandler(-1, router.Match(ctx.Method(), ctx.Path(), ctx.Params()))
+	ctx.Next()
+}
+
+// Routes 方法返回当前路由表。
+func (gw *Gateway) Routes() []*Route {
+	gw.Lock()
+	defer gw.Unlock()
+	return gw.routes
+}
+
+// Load 方法加载路由表，全部路由创建成功后替换当前路由表，失败时返回合并的错误并保留当前路由表。
+func (gw *Gateway) Load(routes []*Route) error {
+	ctx, cancel := context.WithCancel(gw.app)
+	router := eudore.NewRouterRadix()
+	balancers := make(map[string]*eudore.ProxyBalancer)
+	var errs []string
+	for i, route := range routes {
+		hs, balancer, err := gw.newHandlers(ctx, route)
+		if balancer != nil {
+			balancers[route.getMethod()+" "+route.Path] = balancer
+		}
+		if err == nil {
+			err = router.AddHandler(route.getMethod(), route.Path, hs)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("route %d %s %v", i, route.Path, err))
+		}
+	}
+	if errs != nil {
+		cancel()
+		return fmt.Errorf("gateway load error: %s", strings.Join(errs, "; "))
+	}
+
+	gw.Lock()
+	if gw.cancel != nil {
+		gw.cancel()
+	}
+	for _, balancer := range balancers {
+		for target, drain := range gw.draining {
+			balancer.Drain(target, drain)
+		}
+	}
+	gw.routes, gw.balancers, gw.cancel = routes, balancers, cancel
+	gw.table.Store(router)
+	gw.Unlock()
+	gw.app.Infof("gateway load %d routes", len(routes))
+	return nil
+}
+
+// Drain 方法设置全部负载均衡路由中的上游是否摘除，摘除状态在重新加载路由表后保留。
+func (gw *Gateway) Drain(target string, drain bool) {
+	gw.Lock()
+	defer gw.Unlock()
+	if drain {
+		gw.draining[target] = true
+	} else {
+		delete(gw.draining, target)
+	}
+	for _, balancer := range gw.balancers {
+		balancer.Drain(target, drain)
+	}
+}
+
+// Upstreams 方法返回全部负载均衡路由的上游状态，key为路由方法和路径。
+func (gw *Gateway) Upstreams() map[string][]eudore.ProxyUpstream {
+	gw.Lock()
+	defer gw.Unlock()
+	data := make(map[string][]eudore.ProxyUpstream, len(gw.balancers))
+	for key, balancer := range gw.balancers {
+		data[key] = balancer.Upstreams()
+	}
+	return data
+}
+
+// LoadConfig 方法从app配置key读取路由表并加载，配置为路由数组。
+func (gw *Gateway) LoadConfig(key string) error {
+	var routes []*Route
+	err := convertRoutes(gw.app.Get(key), &routes)
+	if err != nil {
+		return fmt.Errorf("gateway config %s error: %v", key, err)
+	}
+	return gw.Load(routes)
+}
+
+// LoadFile 方法从json文件读取路由表并加载，文件内容为路由数组或包含routes属性的对象。
+func (gw *Gateway) LoadFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var routes []*Route
+	err = json.Unmarshal(data, &routes)
+	if err != nil {
+		var conf struct {
+			Routes []*Route `json:"routes"`
+		}
+		if json.Unmarshal(data, &conf) != nil {
+			return fmt.Errorf("gateway file %s error: %v", path, err)
+		}
+		routes = conf.Routes
+	}
+	return gw.Load(routes)
+}
+
+// Watch 方法每interval时间检查一次文件修改时间，文件修改后重新加载路由表，加载失败时输出错误日志并保留当前路由表。
+//
+// Watch 方法使用app.Go运行，app结束时退出。
+func (gw *Gateway) Watch(path string, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	var modtime time.Time
+	if stat, err := os.Stat(path); err == nil {
+		modtime = stat.ModTime()
+	}
+	gw.app.Go(func(ctx context.Context) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				stat, err := os.Stat(path)
+				if err != nil || !stat.ModTime().After(modtime) {
+					continue
+				}
+				modtime = stat.ModTime()
+				err = gw.LoadFile(path)
+				if err != nil {
+					gw.app.Error(err)
+				}
+			}
+		}
+	}, "gateway-watch")
+}
+
+// InjectRoutes 方法将网关后台接口注入到路由器中，用于查看和替换路由表。
+func (gw *Gateway) InjectRoutes(router eudore.Router) {
+	router.GetFunc("/gateway/routes", func(eudore.Context) interface{} {
+		return gw.Routes()
+	})
+	router.PutFunc("/gateway/routes", func(ctx eudore.Context) error {
+		var routes []*Route
+		err := ctx.Bind(&routes)
+		if err != nil {
+			return err
+		}
+		ctx.Infof("%s update gateway %d routes", ctx.RealIP(), len(routes))
+		return gw.Load(routes)
+	})
+	router.GetFunc("/gateway/upstreams", func(eudore.Context) interface{} {
+		return gw.Upstreams()
+	})
+	router.PutFunc("/gateway/drain", func(ctx eudore.Context) {
+		drain := ctx.GetQuery("drain") != "false"
+		ctx.Infof("%s drain gateway upstream %s %t", ctx.RealIP(), ctx.GetQuery("target"), drain)
+		gw.Drain(ctx.GetQuery("target"), drain)
+	})
+}
+
+// newHandlers 方法按限流、认证、中间件链、路径重写、上游的顺序创建路由处理函数，
+// 多个上游时返回创建的负载均衡。
+func (gw *Gateway) newHandlers(ctx context.Context, route *Route) (eudore.HandlerFuncs, *eudore.ProxyBalancer, error) {
+	if route.Path == "" {
+		return nil, nil, fmt.Errorf("path is empty")
+	}
+	var hs eudore.HandlerFuncs
+	if route.Limit != nil {
+		if route.Limit.Speed <= 0 || route.Limit.Max <= 0 {
+			return nil, nil, fmt.Errorf("limit speed and max must be greater than 0")
+		}
+		hs = append(hs, middleware.NewRateFunc(route.Limit.Speed, route.Limit.Max, ctx))
+	}
+	if len(route.Auth) > 0 {
+		hs = append(hs, middleware.NewBasicAuthFunc(route.Auth))
+	}
+	if len(route.Middleware) > 0 {
+		chain, err := middleware.NewChainFuncs(gw.app, route.Middleware)
+		if err != nil {
+			return nil, nil, err
+		}
+		hs = append(hs, chain...)
+	}
+	if len(route.Rewrite) > 0 {
+		hs = append(hs, middleware.NewRewriteFunc(route.Rewrite))
+	}
+
+	options := make([]interface{}, 0, len(route.Transform)+2)
+	for _, str := range route.Transform {
+		transform, err := eudore.NewProxyTransform(str)
+		if err != nil {
+			return nil, nil, err
+		}
+		options = append(options, transform)
+	}
+	if len(options) > 0 && !strings.Contains(route.Upstream, "://") {
+		return nil, nil, fmt.Errorf("transform upstream %s is not proxy", route.Upstream)
+	}
+
+	if route.isBalance() {
+		hash, err := newHashFunc(route.Hash)
+		if err != nil {
+			return nil, nil, err
+		}
+		options = append(options, hash, route.Sticky)
+		balancer, err := eudore.NewProxyBalancer(strings.Split(route.Upstream, ","), options...)
+		if err != nil {
+			return nil, nil, err
+		}
+		return append(hs, balancer.HandleHTTP), balancer, nil
+	}
+
+	if strings.Contains(route.Upstream, "://") {
+		h, err := eudore.NewProxyHandler(route.Upstream, options...)
+		if err != nil {
+			return nil, nil, err
+		}
+		eudore.SetHandlerFuncName(h, "proxy:"+route.Upstream)
+		return append(hs, h), nil, nil
+	}
+	h, err := eudore.NewHandlerName(route.Upstream)
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(hs, h), nil, nil
+}
+
+// isBalance 方法返回路由是否使用负载均衡转发到多个上游。
+func (route *Route) isBalance() bool {
+	return strings.Contains(route.Upstream, "://") && strings.Contains(route.Upstream, ",")
+}
+
+// newHashFunc 函数解析一致性哈希key配置，格式为ip、header:name或cookie:name。
+func newHashFunc(hash string) (func(eudore.Context) string, error) {
+	pos := strings.IndexByte(hash, ':')
+	switch {
+	case hash == "":
+		return nil, nil
+	case hash == "ip":
+		return eudore.NewProxyHashIP(), nil
+	case pos != -1 && hash[:pos] == "header":
+		return eudore.NewProxyHashHeader(hash[pos+1:]), nil
+	case pos != -1 && hash[:pos] == "cookie":
+		return eudore.NewProxyHashCookie(hash[pos+1:]), nil
+	}
+	return nil, fmt.Errorf("invalid hash %s", hash)
+}
+
+func (route *Route) getMethod() string {
+	if route.Method == "" {
+		return eudore.MethodAny
+	}
+	return strings.ToUpper(route.Method)
+}
+
+// convertRoutes 函数使用json转换配置中的路由数组。
+func convertRoutes(conf interface{}, routes *[]*Route) error {
+	if conf == nil {
+		return fmt.Errorf("routes is empty")
+	}
+	data, err := json.Marshal(conf)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, routes)
+}
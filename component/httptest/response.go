@@ -104,8 +104,10 @@ func (rw *ResponseWriterTest) Write(buf []byte) (int, error) {
 
 // WriteHeader sets rw.Code. After it is called, changing rw.Header
 // will not affect rw.HeaderMap.
+//
+// 1xx informational responses are ignored, except 101 Switching Protocols.
 func (rw *ResponseWriterTest) WriteHeader(code int) {
-	if rw.wroteHeader {
+	if rw.wroteHeader || (code >= 100 && code <= 199 && code != http.StatusSwitchingProtocols) {
 		return
 	}
 	rw.Code = code
@@ -184,8 +186,8 @@ func (rw *ResponseWriterTest) CheckStatus(status ...int) *ResponseWriterTest {
 // CheckHeader 方法检查多个header的值
 func (rw *ResponseWriterTest) CheckHeader(h ...string) *ResponseWriterTest {
 	for i := 0; i < len(h)/2; i++ {
-		if rw.HeaderMap.Get(h[i]) != h[i+1] {
-			rw.Client.Printf("CheckHeader response header %s value is %s,not is %s", h[i], rw.HeaderMap.Get(h[i]), h[i+1])
+		if rw.HeaderMap.Get(h[i*2]) != h[i*2+1] {
+			rw.Client.Printf("CheckHeader response header %s value is %s,not is %s", h[i*2], rw.HeaderMap.Get(h[i*2]), h[i*2+1])
 		}
 	}
 	return rw
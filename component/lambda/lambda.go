@@ -0,0 +1,271 @@
+// Package lambda 实现Serverless适配，将AWS Lambda(API Gateway REST/HTTP API、ALB)或兼容格式的函数计算事件转换成请求，
+// 使用eudore.App的路由和中间件处理，并将响应转换成对应格式的事件响应，相同的应用代码可以作为server或函数运行。
+//
+// Handler实现aws-lambda-go的lambda.Handler接口，使用lambda.StartHandler(lambda.NewHandler(app))启动，本包不依赖aws-lambda-go。
+package lambda
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+type (
+	// Request 定义函数事件请求，兼容API Gateway REST API(v1)、HTTP API(v2)和ALB事件格式。
+	Request struct {
+		Version                         string              `json:"version,omitempty"`
+		Resource                        string              `json:"resource,omitempty"`
+		Path                            string              `json:"path,omitempty"`
+		HTTPMethod                      string              `json:"httpMethod,omitempty"`
+		RawPath                         string              `json:"rawPath,omitempty"`
+		RawQueryString                  string              `json:"rawQueryString,omitempty"`
+		Cookies                         []string            `json:"cookies,omitempty"`
+		Headers                         map[string]string   `json:"headers,omitempty"`
+		MultiValueHeaders               map[string][]string `json:"multiValueHeaders,omitempty"`
+		QueryStringParameters           map[string]string   `json:"queryStringParameters,omitempty"`
+		MultiValueQueryStringParameters map[string][]string `json:"multiValueQueryStringParameters,omitempty"`
+		PathParameters                  map[string]string   `json:"pathParameters,omitempty"`
+		StageVariables                  map[string]string   `json:"stageVariables,omitempty"`
+		RequestContext                  RequestContext      `json:"requestContext"`
+		Body                            string              `json:"body,omitempty"`
+		IsBase64Encoded                 bool                `json:"isBase64Encoded"`
+	}
+	// RequestContext 定义事件请求上下文，不同事件格式使用不同的属性。
+	RequestContext struct {
+		RequestID  string `json:"requestId,omitempty"`
+		Stage      string `json:"stage,omitempty"`
+		DomainName string `json:"domainName,omitempty"`
+		Identity   struct {
+			SourceIP string `json:"sourceIp,omitempty"`
+		} `json:"identity"`
+		HTTP struct {
+			Method   string `json:"method,omitempty"`
+			Path     string `json:"path,omitempty"`
+			Protocol string `json:"protocol,omitempty"`
+			SourceIP string `json:"sourceIp,omitempty"`
+		} `json:"http"`
+		ELB struct {
+			TargetGroupArn string `json:"targetGroupArn,omitempty"`
+		} `json:"elb"`
+	}
+	// Response 定义函数事件响应，根据请求事件格式设置Headers、MultiValueHeaders或Cookies。
+	Response struct {
+		StatusCode        int                 `json:"statusCode"`
+		StatusDescription string              `json:"statusDescription,omitempty"`
+		Headers           map[string]string   `json:"headers,omitempty"`
+		MultiValueHeaders map[string][]string `json:"multiValueHeaders,omitempty"`
+		Cookies           []string            `json:"cookies,omitempty"`
+		Body              string              `json:"body"`
+		IsBase64Encoded   bool                `json:"isBase64Encoded"`
+	}
+	// Handler 定义函数事件处理者，将事件转换成请求交给Handler处理。
+	Handler struct {
+		Handler http.Handler
+		Logger  eudore.Logout
+		// TextTypes 定义响应body不使用base64编码的Content-Type前缀。
+		TextTypes []string
+	}
+	requestKey struct{}
+)
+
+// ErrResponseHijack 定义函数响应不支持Hijack的错误。
+var ErrResponseHijack = errors.New("lambda response not support Hijack method")
+
+// NewHandler 函数使用App创建一个函数事件处理者。
+func NewHandler(app *eudore.App) *Handler {
+	return &Handler{
+		Handler: app,
+		Logger:  app.Logger,
+		TextTypes: []string{
+			"text/", eudore.MimeApplicationJSON, eudore.MimeApplicationXML,
+			"application/javascript", eudore.MimeApplicationForm,
+		},
+	}
+}
+
+// Invoke 方法实现aws-lambda-go的lambda.Handler接口，解析json事件并返回json响应。
+func (h *Handler) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	req := new(Request)
+	err := json.Unmarshal(payload, req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.HandleEvent(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(resp)
+}
+
+// HandleEvent 方法将事件转换成请求处理，并将响应转换成对应事件格式的响应。
+func (h *Handler) HandleEvent(ctx context.Context, event *Request) (*Response, error) {
+	start := time.Now()
+	r, err := event.newRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	w := &responseWriter{header: make(http.Header), code: 200}
+	h.Handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestKey{}, event)))
+	h.Logger.WithFields(eudore.Fields{
+		"method":   r.Method,
+		"path":     r.URL.Path,
+		"status":   w.code,
+		"duration": time.Since(start).String(),
+	}).Debug("lambda handle event")
+	return h.newResponse(event, w), nil
+}
+
+// GetRequest 函数获取请求对应的原始事件，非函数事件请求返回nil。
+func GetRequest(ctx eudore.Context) *Request {
+	event, _ := ctx.GetContext().Value(requestKey{}).(*Request)
+	return event
+}
+
+// newRequest 方法将事件转换成http请求。
+func (event *Request) newRequest(ctx context.Context) (*http.Request, error) {
+	method, path, query := event.HTTPMethod, event.Path, event.RawQueryString
+	if event.Version == "2.0" {
+		method, path = event.RequestContext.HTTP.Method, event.RawPath
+	} else {
+		values := make(url.Values)
+		for k, v := range event.QueryStringParameters {
+			values.Set(k, v)
+		}
+		for k, v := range event.MultiValueQueryStringParameters {
+			values[k] = v
+		}
+		query = values.Encode()
+	}
+	if query != "" {
+		path += "?" + query
+	}
+
+	body := []byte(event.Body)
+	if event.IsBase64Encoded {
+		var err error
+		body, err = base64.StdEncoding.DecodeString(event.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	r, err := http.NewRequest(method, path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range event.Headers {
+		r.Header.Set(k, v)
+	}
+	for k, v := range event.MultiValueHeaders {
+		r.Header[http.CanonicalHeaderKey(k)] = v
+	}
+	if len(event.Cookies) > 0 {
+		r.Header.Set(eudore.HeaderCookie, strings.Join(event.Cookies, "; "))
+	}
+	r.Host = r.Header.Get(eudore.HeaderHost)
+	if r.Host == "" {
+		r.Host = event.RequestContext.DomainName
+	}
+	r.RemoteAddr = event.RequestContext.HTTP.SourceIP
+	if r.RemoteAddr == "" {
+		r.RemoteAddr = event.RequestContext.Identity.SourceIP
+	}
+	if r.Header.Get(eudore.HeaderXRequestID) == "" && event.RequestContext.RequestID != "" {
+		r.Header.Set(eudore.HeaderXRequestID, event.RequestContext.RequestID)
+	}
+	r.RequestURI = path
+	return r.WithContext(ctx), nil
+}
+
+// newResponse 方法将响应转换成事件格式的响应，v2事件使用Headers和Cookies，ALB事件额外设置StatusDescription。
+func (h *Handler) newResponse(event *Request, w *responseWriter) *Response {
+	resp := &Response{StatusCode: w.code}
+	switch {
+	case event.Version == "2.0":
+		resp.Headers = make(map[string]string, len(w.header))
+		for k, v := range w.header {
+			if k == eudore.HeaderSetCookie {
+				resp.Cookies = v
+				continue
+			}
+			resp.Headers[k] = strings.Join(v, ",")
+		}
+	case event.MultiValueHeaders == nil && event.RequestContext.ELB.TargetGroupArn != "":
+		resp.Headers = make(map[string]string, len(w.header))
+		for k, v := range w.header {
+			resp.Headers[k] = v[0]
+		}
+	default:
+		resp.MultiValueHeaders = w.header
+	}
+	if event.RequestContext.ELB.TargetGroupArn != "" {
+		resp.StatusDescription = strconv.Itoa(w.code) + " " + http.StatusText(w.code)
+	}
+
+	if h.isText(w.header) {
+		resp.Body = w.buffer.String()
+	} else {
+		resp.Body = base64.StdEncoding.EncodeToString(w.buffer.Bytes())
+		resp.IsBase64Encoded = true
+	}
+	return resp
+}
+
+// isText 方法判断响应body是否为文本，压缩的响应和其他类型使用base64编码。
+func (h *Handler) isText(header http.Header) bool {
+	if header.Get(eudore.HeaderContentEncoding) != "" {
+		return false
+	}
+	contentType := header.Get(eudore.HeaderContentType)
+	if contentType == "" {
+		return true
+	}
+	for _, i := range h.TextTypes {
+		if strings.HasPrefix(contentType, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// responseWriter 定义函数事件处理的响应，记录状态码、header和响应内容。
+type responseWriter struct {
+	header http.Header
+	code   int
+	wrote  bool
+	buffer bytes.Buffer
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.wrote = true
+	return w.buffer.Write(p)
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wrote && code >= 200 {
+		w.code = code
+		w.wrote = true
+	}
+}
+
+func (w *responseWriter) Flush() {
+	// Do nothing because event response not support flush.
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, ErrResponseHijack
+}
@@ -0,0 +1,260 @@
+// Package logquery 实现读取和过滤eudore.LoggerStd输出的json lines日志，用于在服务器上快速排查问题。
+//
+// Reader使用glob匹配切割后的多个日志文件，按修改时间顺序读取，使用级别、属性、消息和时间范围过滤，返回最后Limit条日志；
+// HandleHTTP方法使用请求参数查询，可以注册为后台路由。
+package logquery
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// Entry 定义一条日志。
+type Entry struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+	Message string                 `json:"message,omitempty"`
+	File    string                 `json:"file"`
+}
+
+// Query 定义日志查询条件，条件为空时不过滤。
+type Query struct {
+	// Levels 日志级别名称，例如ERROR、WARNING。
+	Levels []string
+	// Fields 属性值等于指定值，值使用fmt.Sprint格式化后比较。
+	Fields map[string]string
+	// Message 消息包含的字符串。
+	Message string
+	// Since、Until 日志时间范围。
+	Since time.Time
+	Until time.Time
+	// Limit 返回最后Limit条日志，默认100。
+	Limit int
+}
+
+// Reader 定义日志读取器。
+type Reader struct {
+	// Pattern 日志文件glob，例如logger/app-*.log。
+	Pattern string
+	// TimeFormat 日志时间格式，与LoggerStdConfig.TimeFormat相同。
+	TimeFormat string
+	// Location 日志时间的时区，默认本地时区。
+	Location *time.Location
+}
+
+// NewReader 函数创建一个日志读取器，pattern为日志文件glob。
+func NewReader(pattern string) *Reader {
+	return &Reader{
+		Pattern:    pattern,
+		TimeFormat: "2006-01-02 15:04:05",
+		Location:   time.Local,
+	}
+}
+
+type logLine struct {
+	Time    string                 `json:"time"`
+	Level   string                 `json:"level"`
+	Fields  map[string]interface{} `json:"fields"`
+	Message string                 `json:"message"`
+}
+
+// Search 方法读取全部匹配的日志文件，返回满足查询条件的最后Limit条日志，无法解析的行被忽略。
+func (r *Reader) Search(q *Query) ([]*Entry, error) {
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+	files, err := r.files(q.Since)
+	if err != nil {
+		return nil, err
+	}
+	var entries []*Entry
+	for _, file := range files {
+		err = r.searchFile(file, q, func(entry *Entry) {
+			entries = append(entries, entry)
+			if len(entries) > q.Limit*2 {
+				entries = append(entries[:0:0], entries[len(entries)-q.Limit:]...)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(entries) > q.Limit {
+		entries = entries[len(entries)-q.Limit:]
+	}
+	return entries, nil
+}
+
+// files 方法返回按修改时间排序的日志文件，忽略修改时间早于since的文件。
+func (r *Reader) files(since time.Time) ([]string, error) {
+	names, err := filepath.Glob(r.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	times := make(map[string]time.Time, len(names))
+	files := names[:0]
+	for _, name := range names {
+		stat, err := os.Stat(name)
+		if err != nil || stat.IsDir() || stat.ModTime().Before(since) {
+			continue
+		}
+		times[name] = stat.ModTime()
+		files = append(files, name)
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return times[files[i]].Before(times[files[j]])
+	})
+	return files, nil
+}
+
+func (r *Reader) searchFile(name string, q *Query, fn func(*Entry)) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for scanner.Scan() {
+		var line logLine
+		if json.Unmarshal(scanner.Bytes(), &line) != nil {
+			continue
+		}
+		entry := &Entry{
+			Level:   normalizeLevel(line.Level),
+			Fields:  line.Fields,
+			Message: line.Message,
+			File:    name,
+		}
+		entry.Time, _ = time.ParseInLocation(r.TimeFormat, line.Time, r.Location)
+		if q.match(entry) {
+			fn(entry)
+		}
+	}
+	return scanner.Err()
+}
+
+// match 方法检查日志是否满足查询条件。
+func (q *Query) match(entry *Entry) bool {
+	if len(q.Levels) > 0 {
+		var ok bool
+		for _, level := range q.Levels {
+			ok = ok || normalizeLevel(level) == entry.Level
+		}
+		if !ok {
+			return false
+		}
+	}
+	if !q.Since.IsZero() && (entry.Time.IsZero() || entry.Time.Before(q.Since)) {
+		return false
+	}
+	if !q.Until.IsZero() && (entry.Time.IsZero() || entry.Time.After(q.Until)) {
+		return false
+	}
+	if q.Message != "" && !strings.Contains(entry.Message, q.Message) {
+		return false
+	}
+	for key, val := range q.Fields {
+		v, ok := entry.Fields[key]
+		if !ok || fmt.Sprint(v) != val {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeLevel 函数转换级别名称为大写，兼容LoggerStd输出的WARIRNG。
+func normalizeLevel(level string) string {
+	level = strings.ToUpper(strings.TrimSpace(level))
+	if level == "WARIRNG" {
+		return "WARNING"
+	}
+	return level
+}
+
+// HandleHTTP 方法使用请求参数查询日志。
+//
+// 参数level为级别，可以使用逗号分隔多个；field为key=value格式的属性条件，可以重复；message为消息包含的字符串；
+// since、until为RFC3339、日期或TimeFormat格式的时间，也可以使用1h等时长表示当前时间之前；limit为返回数量。
+func (r *Reader) HandleHTTP(ctx eudore.Context) {
+	q, err := r.parseQuery(ctx.Querys())
+	if err == nil {
+		var entries []*Entry
+		entries, err = r.Search(q)
+		if err == nil {
+			ctx.Render(entries)
+			return
+		}
+	}
+	if ctx.Response().Status() < 400 {
+		ctx.WriteHeader(eudore.StatusBadRequest)
+	}
+	ctx.Fatal(err)
+}
+
+func (r *Reader) parseQuery(vals url.Values) (*Query, error) {
+	q := &Query{Message: vals.Get("message")}
+	for _, level := range vals["level"] {
+		for _, l := range strings.Split(level, ",") {
+			if l != "" {
+				q.Levels = append(q.Levels, l)
+			}
+		}
+	}
+	for _, field := range vals["field"] {
+		pos := strings.IndexByte(field, '=')
+		if pos == -1 {
+			return nil, fmt.Errorf("logquery field '%s' must be key=value", field)
+		}
+		if q.Fields == nil {
+			q.Fields = make(map[string]string)
+		}
+		q.Fields[field[:pos]] = field[pos+1:]
+	}
+	var err error
+	if q.Since, err = r.parseTime(vals.Get("since")); err != nil {
+		return nil, err
+	}
+	if q.Until, err = r.parseTime(vals.Get("until")); err != nil {
+		return nil, err
+	}
+	if limit := vals.Get("limit"); limit != "" {
+		if q.Limit, err = strconv.Atoi(limit); err != nil {
+			return nil, fmt.Errorf("logquery limit '%s' is invalid: %v", limit, err)
+		}
+	}
+	return q, nil
+}
+
+// parseTime 方法解析RFC3339、日期、TimeFormat格式的时间或当前时间之前的时长。
+func (r *Reader) parseTime(str string) (time.Time, error) {
+	if str == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(str); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, str); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", str, r.Location); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(r.TimeFormat, str, r.Location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("logquery time '%s' is invalid, use RFC3339, '%s', date or duration", str, r.TimeFormat)
+	}
+	return t, nil
+}
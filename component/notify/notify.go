@@ -42,11 +42,20 @@ type Notify struct {
 	lastProcess context.CancelFunc
 }
 
-// NewNotify 函数创建一个Notify对象。
+// NewNotify 函数创建一个Notify对象，创建文件监听失败时panic。
 func NewNotify(app *eudore.App) *Notify {
+	n, err := NewNotify2(app)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// NewNotify2 函数创建一个Notify对象，创建文件监听失败时返回错误。
+func NewNotify2(app *eudore.App) (*Notify, error) {
 	if app.Config.Get("component.notify.disable") != nil {
 		app.Info("notify is disable")
-		return nil
+		return nil, nil
 	}
 	var (
 		buildCmd = getArgs(app.Config.Get("component.notify.buildcmd"))
@@ -56,7 +65,7 @@ func NewNotify(app *eudore.App) *Notify {
 
 	if len(buildCmd) == 0 {
 		app.Info("notify build command is empty.")
-		return nil
+		return nil, nil
 	}
 
 	if len(startCmd) == 0 {
@@ -65,7 +74,7 @@ func NewNotify(app *eudore.App) *Notify {
 
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	return &Notify{
@@ -74,7 +83,7 @@ func NewNotify(app *eudore.App) *Notify {
 		buildCmd: buildCmd,
 		startCmd: startCmd,
 		watchDir: watchDir,
-	}
+	}, nil
 }
 
 // IsRun 方法返回Notify是否可以启动。
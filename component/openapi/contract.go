@@ -0,0 +1,164 @@
+package openapi
+
+import (
+	"encoding/json"
+	"mime"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// Contract 定义响应契约检查，在开发和测试环境中使用路由注册的Go类型或schema检查2xx json响应，
+// 响应结构变化时记录错误日志，Strict模式下返回500，用于发现意外的不兼容api变化。
+type Contract struct {
+	// Strict 定义响应不符合契约时是否返回500，否则仅记录错误日志。
+	Strict    bool
+	schemas   sync.Map
+	validator *Validator
+}
+
+// NewContract 函数创建一个响应契约检查，使用app.AddMiddleware(contract.HandleHTTP)注册中间件。
+func NewContract() *Contract {
+	return &Contract{validator: &Validator{Document: &Document{}}}
+}
+
+// Register 方法注册路由的响应契约，route为注册路由使用的路径，例如/users/:id，
+// val为*Schema或者Go类型的值，Go类型使用SchemaOf生成schema。
+func (c *Contract) Register(method, route string, val interface{}) {
+	schema, ok := val.(*Schema)
+	if !ok {
+		schema = SchemaOf(val)
+	}
+	c.schemas.Store(method+" "+route, schema)
+}
+
+// HandleHTTP 方法缓冲已注册契约的路由响应，响应为2xx json时使用契约检查。
+func (c *Contract) HandleHTTP(ctx eudore.Context) {
+	val, ok := c.schemas.Load(ctx.Method() + " " + ctx.GetParam(eudore.ParamRoute))
+	if !ok {
+		return
+	}
+
+	w := &responseWriter{ResponseWriter: ctx.Response()}
+	ctx.SetResponse(w)
+	ctx.Next()
+	ctx.SetResponse(w.ResponseWriter)
+	errs := c.check(val.(*Schema), w)
+	if len(errs) > 0 {
+		ctx.WithField("errors", errs).Error("openapi response contract drift")
+		if c.Strict {
+			writeError(ctx, eudore.StatusInternalServerError, "response contract drift", errs)
+			return
+		}
+	}
+	if w.code != 0 {
+		w.ResponseWriter.WriteHeader(w.code)
+	}
+	w.ResponseWriter.Write(w.buffer.Bytes())
+}
+
+func (c *Contract) check(schema *Schema, w *responseWriter) []ValidationError {
+	if w.code != 0 && (w.code < 200 || w.code > 299) {
+		return nil
+	}
+	mediatype, _, _ := mime.ParseMediaType(w.Header().Get(eudore.HeaderContentType))
+	if !isJSON(mediatype) {
+		return nil
+	}
+	var val interface{}
+	if err := json.Unmarshal(w.buffer.Bytes(), &val); err != nil {
+		return []ValidationError{{In: "response", Message: "invalid json: " + err.Error()}}
+	}
+	var errs []ValidationError
+	c.validator.validate(schema, val, "response", "", &errs)
+	return errs
+}
+
+var typeTime = reflect.TypeOf(time.Time{})
+
+// SchemaOf 函数使用Go值的类型生成json schema，结构体使用json tag作为属性名，
+// 没有omitempty的属性为必填属性，且不允许额外属性，指针、切片和map类型允许null。
+func SchemaOf(val interface{}) *Schema {
+	return schemaOf(reflect.TypeOf(val), make(map[reflect.Type]bool))
+}
+
+func schemaOf(t reflect.Type, visited map[reflect.Type]bool) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+	if t == typeTime {
+		return &Schema{Type: "string", Format: "date-time", Nullable: nullable}
+	}
+	if t.Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()) {
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean", Nullable: nullable}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Nullable: nullable}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Nullable: nullable}
+	case reflect.String:
+		return &Schema{Type: "string", Nullable: nullable}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Nullable: true}
+		}
+		return &Schema{Type: "array", Nullable: true, Items: schemaOf(t.Elem(), visited)}
+	case reflect.Map:
+		return &Schema{Type: "object", Nullable: true, AdditionalProperties: schemaOf(t.Elem(), visited)}
+	case reflect.Struct:
+		// 递归类型只检查第一层结构。
+		if visited[t] {
+			return &Schema{Type: "object", Nullable: nullable}
+		}
+		visited[t] = true
+		defer delete(visited, t)
+		s := &Schema{Type: "object", Nullable: nullable, Properties: make(map[string]*Schema), NoAdditional: true}
+		schemaFields(s, t, visited)
+		return s
+	}
+	return &Schema{}
+}
+
+func schemaFields(s *Schema, t reflect.Type, visited map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (field.PkgPath != "" && !field.Anonymous) {
+			continue
+		}
+		name, opts := tag, ""
+		if pos := strings.IndexByte(tag, ','); pos != -1 {
+			name, opts = tag[:pos], tag[pos:]
+		}
+		ft := field.Type
+		if field.Anonymous && name == "" {
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				schemaFields(s, ft, visited)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		s.Properties[name] = schemaOf(field.Type, visited)
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
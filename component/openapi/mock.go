@@ -0,0 +1,256 @@
+package openapi
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// Mock 定义模拟服务，使用OpenAPI文档或注册的示例响应模拟未实现的api，用于前端在后端开发期间调试。
+//
+// 响应默认使用最小的2xx状态码，请求可以使用Prefer: code=404, example=name选择响应状态码和命名示例；
+// 示例不存在时使用schema的example、default、enum或类型生成示例。
+type Mock struct {
+	Document *Document
+	// Delay 定义模拟响应的延迟。
+	Delay     time.Duration
+	validator *Validator
+}
+
+// NewMock 函数创建一个模拟服务，doc为空时仅使用Register注册的示例。
+//
+// 使用app.AddHandler("404", "", mock.HandleHTTP)注册为404处理，已经实现的路由使用真实处理函数，未实现的路由返回模拟响应。
+func NewMock(doc *Document) *Mock {
+	if doc == nil {
+		doc = &Document{OpenAPI: "3.0.3"}
+	}
+	return &Mock{Document: doc, validator: NewValidator(doc)}
+}
+
+// Register 方法注册一个路由的示例响应，路径可以使用eudore格式的:name参数或OpenAPI格式的{name}参数，
+// 路径不包含文档服务地址的路径前缀。
+func (m *Mock) Register(method, path string, code int, example interface{}) {
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, ":") {
+			segments[i] = "{" + seg[1:] + "}"
+		}
+	}
+	path = strings.Join(segments, "/")
+
+	if m.Document.Paths == nil {
+		m.Document.Paths = make(map[string]*PathItem)
+	}
+	item, ok := m.Document.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		m.Document.Paths[path] = item
+	}
+	op := item.operations()[strings.ToUpper(method)]
+	if op == nil {
+		op = &Operation{}
+		item.setOperation(strings.ToUpper(method), op)
+	}
+	if op.Responses == nil {
+		op.Responses = make(map[string]*Response)
+	}
+	op.Responses[strconv.Itoa(code)] = &Response{Content: map[string]*MediaType{
+		eudore.MimeApplicationJSON: {Example: example},
+	}}
+	m.validator = NewValidator(m.Document)
+}
+
+// HandleHTTP 方法返回请求匹配操作的示例响应，未匹配时返回404。
+func (m *Mock) HandleHTTP(ctx eudore.Context) {
+	r, _ := m.validator.match(ctx.Method(), ctx.Path())
+	if r == nil {
+		eudore.HandlerRouter404(ctx)
+		return
+	}
+	prefer := parsePrefer(ctx.GetHeader("Prefer"))
+	code, resp := m.response(r.operation, prefer["code"])
+	if code == 0 {
+		eudore.HandlerRouter404(ctx)
+		return
+	}
+	if m.Delay > 0 {
+		select {
+		case <-time.After(m.Delay):
+		case <-ctx.GetContext().Done():
+			return
+		}
+	}
+
+	ctx.SetHeader("X-Eudore-Mock", "true")
+	mediatype, content := m.content(resp)
+	if content == nil {
+		ctx.WriteHeader(code)
+		return
+	}
+	example := m.example(content, prefer["example"])
+	ctx.SetHeader(eudore.HeaderContentType, mediatype)
+	ctx.WriteHeader(code)
+	if str, ok := example.(string); ok && !isJSON(mediatype) {
+		ctx.WriteString(str)
+		return
+	}
+	json.NewEncoder(ctx).Encode(example)
+}
+
+// response 方法选择响应状态码，默认使用最小的2xx状态码。
+func (m *Mock) response(op *Operation, prefer string) (int, *Response) {
+	keys := make([]string, 0, len(op.Responses))
+	for key := range op.Responses {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if (prefer == "" && key[0] == '2') || key == prefer {
+			return statusCode(key), m.Document.response(op.Responses[key])
+		}
+	}
+	if resp, ok := op.Responses["default"]; ok && prefer == "" {
+		return eudore.StatusOK, m.Document.response(resp)
+	}
+	return 0, nil
+}
+
+// content 方法选择响应媒体类型，优先使用json。
+func (m *Mock) content(resp *Response) (string, *MediaType) {
+	if resp == nil || len(resp.Content) == 0 {
+		return "", nil
+	}
+	if content, ok := resp.Content[eudore.MimeApplicationJSON]; ok {
+		return eudore.MimeApplicationJSON, content
+	}
+	keys := make([]string, 0, len(resp.Content))
+	for key := range resp.Content {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys[0], resp.Content[keys[0]]
+}
+
+// example 方法选择示例，没有示例时使用schema生成。
+func (m *Mock) example(content *MediaType, name string) interface{} {
+	if e, ok := content.Examples[name]; ok && e != nil {
+		return e.Value
+	}
+	if content.Example != nil {
+		return content.Example
+	}
+	keys := make([]string, 0, len(content.Examples))
+	for key := range content.Examples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if content.Examples[key] != nil {
+			return content.Examples[key].Value
+		}
+	}
+	return m.exampleOf(content.Schema, 0)
+}
+
+// exampleOf 方法使用schema生成示例值。
+func (m *Mock) exampleOf(s *Schema, depth int) interface{} {
+	s = m.Document.schema(s)
+	if s == nil || depth > 8 {
+		return nil
+	}
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case len(s.OneOf) > 0:
+		return m.exampleOf(s.OneOf[0], depth+1)
+	case len(s.AnyOf) > 0:
+		return m.exampleOf(s.AnyOf[0], depth+1)
+	}
+
+	switch s.Type {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return "2006-01-02T15:04:05Z"
+		case "date":
+			return "2006-01-02"
+		case "email":
+			return "user@example.com"
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		}
+		return "string"
+	case "integer", "number":
+		if s.Minimum != nil {
+			return *s.Minimum
+		}
+		return 0
+	case "boolean":
+		return true
+	case "array":
+		return []interface{}{m.exampleOf(s.Items, depth+1)}
+	}
+
+	obj := make(map[string]interface{})
+	for _, sub := range s.AllOf {
+		if val, ok := m.exampleOf(sub, depth+1).(map[string]interface{}); ok {
+			for k, v := range val {
+				obj[k] = v
+			}
+		}
+	}
+	for name, prop := range s.Properties {
+		obj[name] = m.exampleOf(prop, depth+1)
+	}
+	return obj
+}
+
+// setOperation 方法设置路径方法的操作。
+func (item *PathItem) setOperation(method string, op *Operation) {
+	switch method {
+	case "GET":
+		item.Get = op
+	case "PUT":
+		item.Put = op
+	case "POST":
+		item.Post = op
+	case "DELETE":
+		item.Delete = op
+	case "OPTIONS":
+		item.Options = op
+	case "HEAD":
+		item.Head = op
+	case "PATCH":
+		item.Patch = op
+	case "TRACE":
+		item.Trace = op
+	}
+}
+
+// parsePrefer 函数解析Prefer header，例如code=404, example=empty。
+func parsePrefer(str string) map[string]string {
+	prefer := make(map[string]string)
+	for _, pair := range strings.Split(str, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) == 2 {
+			prefer[kv[0]] = strings.Trim(kv[1], `"`)
+		}
+	}
+	return prefer
+}
+
+func statusCode(key string) int {
+	if strings.HasSuffix(key, "XX") {
+		return (int(key[0]) - '0') * 100
+	}
+	code, _ := strconv.Atoi(key)
+	return code
+}
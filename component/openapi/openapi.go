@@ -0,0 +1,224 @@
+// Package openapi 实现运行时OpenAPI 3请求校验，加载json格式的OpenAPI文档，
+// 使用文档定义的路径参数、query、header和json body的schema校验请求，校验失败时返回结构化的400错误。
+//
+// 开启Strict模式后同时校验响应状态码和json响应body，响应不符合文档时记录错误日志并返回500，通常在开发环境中使用。
+//
+// Contract不需要文档，使用路由注册的Go类型或schema检查响应，在测试中发现响应结构的意外变化。
+//
+// Mock使用文档或注册的示例响应模拟未实现的api，示例不存在时使用schema生成。
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+type (
+	// Document 定义OpenAPI文档，仅包含请求校验使用的部分。
+	Document struct {
+		OpenAPI    string               `json:"openapi"`
+		Servers    []Server             `json:"servers,omitempty"`
+		Paths      map[string]*PathItem `json:"paths"`
+		Components Components           `json:"components,omitempty"`
+	}
+	// Server 定义服务地址，地址的路径作为请求路径前缀。
+	Server struct {
+		URL string `json:"url"`
+	}
+	// Components 定义可以使用$ref引用的对象。
+	Components struct {
+		Schemas       map[string]*Schema      `json:"schemas,omitempty"`
+		Parameters    map[string]*Parameter   `json:"parameters,omitempty"`
+		RequestBodies map[string]*RequestBody `json:"requestBodies,omitempty"`
+		Responses     map[string]*Response    `json:"responses,omitempty"`
+	}
+	// PathItem 定义一个路径的全部操作，Parameters为全部操作共用的参数。
+	PathItem struct {
+		Parameters []*Parameter `json:"parameters,omitempty"`
+		Get        *Operation   `json:"get,omitempty"`
+		Put        *Operation   `json:"put,omitempty"`
+		Post       *Operation   `json:"post,omitempty"`
+		Delete     *Operation   `json:"delete,omitempty"`
+		Options    *Operation   `json:"options,omitempty"`
+		Head       *Operation   `json:"head,omitempty"`
+		Patch      *Operation   `json:"patch,omitempty"`
+		Trace      *Operation   `json:"trace,omitempty"`
+	}
+	// Operation 定义一个路径方法的操作。
+	Operation struct {
+		OperationID string               `json:"operationId,omitempty"`
+		Parameters  []*Parameter         `json:"parameters,omitempty"`
+		RequestBody *RequestBody         `json:"requestBody,omitempty"`
+		Responses   map[string]*Response `json:"responses,omitempty"`
+	}
+	// Parameter 定义一个参数，In为path、query、header或cookie。
+	Parameter struct {
+		Ref      string  `json:"$ref,omitempty"`
+		Name     string  `json:"name,omitempty"`
+		In       string  `json:"in,omitempty"`
+		Required bool    `json:"required,omitempty"`
+		Schema   *Schema `json:"schema,omitempty"`
+	}
+	// RequestBody 定义请求body，Content的key为媒体类型。
+	RequestBody struct {
+		Ref      string                `json:"$ref,omitempty"`
+		Required bool                  `json:"required,omitempty"`
+		Content  map[string]*MediaType `json:"content,omitempty"`
+	}
+	// Response 定义一个状态码的响应。
+	Response struct {
+		Ref     string                `json:"$ref,omitempty"`
+		Content map[string]*MediaType `json:"content,omitempty"`
+	}
+	// MediaType 定义一个媒体类型的schema和示例。
+	MediaType struct {
+		Schema   *Schema             `json:"schema,omitempty"`
+		Example  interface{}         `json:"example,omitempty"`
+		Examples map[string]*Example `json:"examples,omitempty"`
+	}
+	// Example 定义一个命名示例。
+	Example struct {
+		Summary string      `json:"summary,omitempty"`
+		Value   interface{} `json:"value,omitempty"`
+	}
+	// Schema 定义OpenAPI 3.0的schema对象，AdditionalProperties为false时不允许未定义的属性。
+	Schema struct {
+		Ref                  string             `json:"$ref,omitempty"`
+		Type                 string             `json:"type,omitempty"`
+		Format               string             `json:"format,omitempty"`
+		Nullable             bool               `json:"nullable,omitempty"`
+		Enum                 []interface{}      `json:"enum,omitempty"`
+		Properties           map[string]*Schema `json:"properties,omitempty"`
+		Required             []string           `json:"required,omitempty"`
+		AdditionalProperties *Schema            `json:"-"`
+		NoAdditional         bool               `json:"-"`
+		Items                *Schema            `json:"items,omitempty"`
+		AllOf                []*Schema          `json:"allOf,omitempty"`
+		AnyOf                []*Schema          `json:"anyOf,omitempty"`
+		OneOf                []*Schema          `json:"oneOf,omitempty"`
+		Minimum              *float64           `json:"minimum,omitempty"`
+		Maximum              *float64           `json:"maximum,omitempty"`
+		ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+		ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+		MinLength            int                `json:"minLength,omitempty"`
+		MaxLength            *int               `json:"maxLength,omitempty"`
+		Pattern              string             `json:"pattern,omitempty"`
+		MinItems             int                `json:"minItems,omitempty"`
+		MaxItems             *int               `json:"maxItems,omitempty"`
+		Default              interface{}        `json:"default,omitempty"`
+		Example              interface{}        `json:"example,omitempty"`
+		pattern              *regexp.Regexp
+	}
+)
+
+// LoadDocument 函数读取json格式的OpenAPI文档，不支持yaml格式。
+func LoadDocument(r io.Reader) (*Document, error) {
+	doc := new(Document)
+	err := json.NewDecoder(r).Decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("openapi load document error: %v", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("openapi load document error: unsupported openapi version '%s'", doc.OpenAPI)
+	}
+	return doc, nil
+}
+
+// LoadFile 函数读取json格式的OpenAPI文档文件。
+func LoadFile(name string) (*Document, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadDocument(file)
+}
+
+// UnmarshalJSON 方法解析schema，additionalProperties可以是bool或schema，pattern会预先编译。
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	type schema Schema
+	var val struct {
+		*schema
+		AdditionalProperties json.RawMessage `json:"additionalProperties,omitempty"`
+	}
+	val.schema = (*schema)(s)
+	err := json.Unmarshal(data, &val)
+	if err != nil {
+		return err
+	}
+	switch string(val.AdditionalProperties) {
+	case "", "true":
+	case "false":
+		s.NoAdditional = true
+	default:
+		s.AdditionalProperties = new(Schema)
+		err = json.Unmarshal(val.AdditionalProperties, s.AdditionalProperties)
+		if err != nil {
+			return err
+		}
+	}
+	if s.Pattern != "" {
+		s.pattern, err = regexp.Compile(s.Pattern)
+	}
+	return err
+}
+
+// basePath 方法返回第一个服务地址的路径作为请求路径前缀。
+func (doc *Document) basePath() string {
+	if len(doc.Servers) == 0 {
+		return ""
+	}
+	u, err := url.Parse(doc.Servers[0].URL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// schema 方法解析schema的$ref引用。
+func (doc *Document) schema(s *Schema) *Schema {
+	for i := 0; s != nil && s.Ref != "" && i < 32; i++ {
+		s = doc.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+func (doc *Document) parameter(p *Parameter) *Parameter {
+	if p != nil && p.Ref != "" {
+		return doc.Components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]
+	}
+	return p
+}
+
+func (doc *Document) requestBody(b *RequestBody) *RequestBody {
+	if b != nil && b.Ref != "" {
+		return doc.Components.RequestBodies[strings.TrimPrefix(b.Ref, "#/components/requestBodies/")]
+	}
+	return b
+}
+
+func (doc *Document) response(r *Response) *Response {
+	if r != nil && r.Ref != "" {
+		return doc.Components.Responses[strings.TrimPrefix(r.Ref, "#/components/responses/")]
+	}
+	return r
+}
+
+// operations 方法返回路径的全部方法和操作。
+func (item *PathItem) operations() map[string]*Operation {
+	ops := map[string]*Operation{
+		"GET": item.Get, "PUT": item.Put, "POST": item.Post, "DELETE": item.Delete,
+		"OPTIONS": item.Options, "HEAD": item.Head, "PATCH": item.Patch, "TRACE": item.Trace,
+	}
+	for method, op := range ops {
+		if op == nil {
+			delete(ops, method)
+		}
+	}
+	return ops
+}
@@ -0,0 +1,529 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net"
+	"net/textproto"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+type (
+	// Validator 定义OpenAPI校验者，文档未定义的请求路径和方法不会校验。
+	Validator struct {
+		Document *Document
+		// BasePath 定义请求路径前缀，默认使用文档第一个服务地址的路径。
+		BasePath string
+		// Strict 定义是否校验响应，响应不符合文档时记录错误日志并返回500。
+		Strict bool
+		routes map[string][]*route
+	}
+	// Error 定义校验失败返回的结构化错误。
+	Error struct {
+		Status  int               `json:"status"`
+		Message string            `json:"message"`
+		Errors  []ValidationError `json:"errors"`
+	}
+	// ValidationError 定义一个校验错误，In为path、query、header、cookie、body或response，
+	// body的Name为json pointer格式的字段路径。
+	ValidationError struct {
+		In      string `json:"in"`
+		Name    string `json:"name,omitempty"`
+		Message string `json:"message"`
+	}
+	route struct {
+		segments  []string
+		params    int
+		operation *Operation
+		parameter []*Parameter
+	}
+	responseWriter struct {
+		eudore.ResponseWriter
+		buffer bytes.Buffer
+		code   int
+	}
+)
+
+// NewValidator 函数使用OpenAPI文档创建一个校验者，使用app.AddMiddleware(openapi.NewValidator(doc).HandleHTTP)注册中间件。
+func NewValidator(doc *Document) *Validator {
+	v := &Validator{
+		Document: doc,
+		BasePath: doc.basePath(),
+		routes:   make(map[string][]*route),
+	}
+	for path, item := range doc.Paths {
+		for method, op := range item.operations() {
+			r := &route{segments: strings.Split(strings.Trim(path, "/"), "/"), operation: op}
+			for _, seg := range r.segments {
+				if isTemplate(seg) {
+					r.params++
+				}
+			}
+			// 操作参数覆盖路径同名参数。
+			names := make(map[string]bool)
+			for _, p := range op.Parameters {
+				if p = doc.parameter(p); p != nil {
+					names[p.In+":"+p.Name] = true
+					r.parameter = append(r.parameter, p)
+				}
+			}
+			for _, p := range item.Parameters {
+				if p = doc.parameter(p); p != nil && !names[p.In+":"+p.Name] {
+					r.parameter = append(r.parameter, p)
+				}
+			}
+			v.routes[method] = append(v.routes[method], r)
+		}
+	}
+	// 优先匹配参数少的路径，例如/users/me优先于/users/{id}。
+	for _, routes := range v.routes {
+		sort.SliceStable(routes, func(i, j int) bool {
+			return routes[i].params < routes[j].params
+		})
+	}
+	return v
+}
+
+// HandleHTTP 方法校验请求，校验失败时返回400和结构化错误，Strict模式下同时校验响应。
+func (v *Validator) HandleHTTP(ctx eudore.Context) {
+	r, params := v.match(ctx.Method(), ctx.Path())
+	if r == nil {
+		return
+	}
+	errs := v.validateRequest(ctx, r, params)
+	if len(errs) > 0 {
+		writeError(ctx, eudore.StatusBadRequest, "request validation failed", errs)
+		return
+	}
+	if !v.Strict {
+		return
+	}
+
+	w := &responseWriter{ResponseWriter: ctx.Response()}
+	ctx.SetResponse(w)
+	ctx.Next()
+	ctx.SetResponse(w.ResponseWriter)
+	errs = v.validateResponse(r.operation, w)
+	if len(errs) > 0 {
+		ctx.WithField("errors", errs).Error("openapi response validation failed")
+		writeError(ctx, eudore.StatusInternalServerError, "response validation failed", errs)
+		return
+	}
+	if w.code != 0 {
+		w.ResponseWriter.WriteHeader(w.code)
+	}
+	w.ResponseWriter.Write(w.buffer.Bytes())
+}
+
+// match 方法匹配请求路径对应的操作，返回路径参数。
+func (v *Validator) match(method, path string) (*route, map[string]string) {
+	if !strings.HasPrefix(path, v.BasePath) {
+		return nil, nil
+	}
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(path, v.BasePath), "/"), "/")
+	for _, r := range v.routes[method] {
+		if len(r.segments) != len(segments) {
+			continue
+		}
+		params := make(map[string]string)
+		for i, seg := range r.segments {
+			switch {
+			case isTemplate(seg) && segments[i] != "":
+				params[seg[1:len(seg)-1]] = segments[i]
+			case seg != segments[i]:
+				params = nil
+			}
+			if params == nil {
+				break
+			}
+		}
+		if params != nil {
+			return r, params
+		}
+	}
+	return nil, nil
+}
+
+func (v *Validator) validateRequest(ctx eudore.Context, r *route, params map[string]string) []ValidationError {
+	var errs []ValidationError
+	querys := ctx.Querys()
+	for _, p := range r.parameter {
+		var vals []string
+		switch p.In {
+		case "path":
+			if val, ok := params[p.Name]; ok {
+				vals = []string{val}
+			}
+		case "query":
+			vals = querys[p.Name]
+		case "header":
+			vals = ctx.Request().Header[textproto.CanonicalMIMEHeaderKey(p.Name)]
+		case "cookie":
+			if val := ctx.GetCookie(p.Name); val != "" {
+				vals = []string{val}
+			}
+		}
+		if len(vals) == 0 {
+			if p.Required {
+				errs = append(errs, ValidationError{p.In, p.Name, "required parameter is missing"})
+			}
+			continue
+		}
+		schema := v.Document.schema(p.Schema)
+		val, err := parseParam(v.Document, schema, vals)
+		if err != nil {
+			errs = append(errs, ValidationError{p.In, p.Name, err.Error()})
+			continue
+		}
+		v.validate(schema, val, p.In, p.Name, &errs)
+	}
+
+	body := v.Document.requestBody(r.operation.RequestBody)
+	if body == nil {
+		return errs
+	}
+	data := ctx.Body()
+	if len(data) == 0 {
+		if body.Required {
+			errs = append(errs, ValidationError{In: "body", Message: "request body is required"})
+		}
+		return errs
+	}
+	mediatype, _, _ := mime.ParseMediaType(ctx.GetHeader(eudore.HeaderContentType))
+	content, ok := findContent(body.Content, mediatype)
+	if !ok {
+		return append(errs, ValidationError{In: "body", Message: fmt.Sprintf("unsupported Content-Type '%s'", mediatype)})
+	}
+	if content != nil && content.Schema != nil && isJSON(mediatype) {
+		var val interface{}
+		if err := json.Unmarshal(data, &val); err != nil {
+			return append(errs, ValidationError{In: "body", Message: "invalid json: " + err.Error()})
+		}
+		v.validate(v.Document.schema(content.Schema), val, "body", "", &errs)
+	}
+	return errs
+}
+
+// validateResponse 方法校验响应状态码是否定义和json响应body。
+func (v *Validator) validateResponse(op *Operation, w *responseWriter) []ValidationError {
+	code := w.code
+	if code == 0 {
+		code = eudore.StatusOK
+	}
+	status := strconv.Itoa(code)
+	resp, ok := op.Responses[status]
+	if !ok {
+		resp, ok = op.Responses[status[:1]+"XX"]
+	}
+	if !ok {
+		resp, ok = op.Responses["default"]
+	}
+	if !ok {
+		return []ValidationError{{In: "response", Name: status, Message: "response status is not documented"}}
+	}
+	resp = v.Document.response(resp)
+	if resp == nil || len(resp.Content) == 0 || w.buffer.Len() == 0 {
+		return nil
+	}
+
+	mediatype, _, _ := mime.ParseMediaType(w.Header().Get(eudore.HeaderContentType))
+	content, ok := findContent(resp.Content, mediatype)
+	if !ok {
+		return []ValidationError{{In: "response", Name: status, Message: fmt.Sprintf("undocumented Content-Type '%s'", mediatype)}}
+	}
+	if content == nil || content.Schema == nil || !isJSON(mediatype) {
+		return nil
+	}
+	var val interface{}
+	if err := json.Unmarshal(w.buffer.Bytes(), &val); err != nil {
+		return []ValidationError{{In: "response", Message: "invalid json: " + err.Error()}}
+	}
+	var errs []ValidationError
+	v.validate(v.Document.schema(content.Schema), val, "response", "", &errs)
+	return errs
+}
+
+// validate 方法使用schema校验json值，错误追加到errs。
+func (v *Validator) validate(s *Schema, val interface{}, in, name string, errs *[]ValidationError) {
+	if s == nil {
+		return
+	}
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, ValidationError{in, name, fmt.Sprintf(format, args...)})
+	}
+	if val == nil {
+		if !s.Nullable && s.Type != "" {
+			fail("value must not be null")
+		}
+		return
+	}
+
+	for _, sub := range s.AllOf {
+		v.validate(v.Document.schema(sub), val, in, name, errs)
+	}
+	if len(s.AnyOf) > 0 && v.count(s.AnyOf, val) == 0 {
+		fail("value does not match any schema in anyOf")
+	}
+	if len(s.OneOf) > 0 {
+		if n := v.count(s.OneOf, val); n != 1 {
+			fail("value must match exactly one schema in oneOf, matched %d", n)
+		}
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, val) {
+		fail("value must be one of %v", s.Enum)
+	}
+
+	switch s.Type {
+	case "string":
+		str, ok := val.(string)
+		if !ok {
+			fail("value must be string")
+			return
+		}
+		length := len([]rune(str))
+		if length < s.MinLength {
+			fail("length must be at least %d", s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("length must be at most %d", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(str) {
+			fail("value must match pattern '%s'", s.Pattern)
+		}
+		if err := checkFormat(s.Format, str); err != nil {
+			fail("%s", err.Error())
+		}
+	case "integer", "number":
+		num, ok := val.(float64)
+		if !ok {
+			fail("value must be %s", s.Type)
+			return
+		}
+		if s.Type == "integer" && num != math.Trunc(num) {
+			fail("value must be integer")
+		}
+		if s.Minimum != nil && (num < *s.Minimum || s.ExclusiveMinimum && num == *s.Minimum) {
+			fail("value must be greater than %s%v", exclusive(s.ExclusiveMinimum), *s.Minimum)
+		}
+		if s.Maximum != nil && (num > *s.Maximum || s.ExclusiveMaximum && num == *s.Maximum) {
+			fail("value must be less than %s%v", exclusive(s.ExclusiveMaximum), *s.Maximum)
+		}
+	case "boolean":
+		if _, ok := val.(bool); !ok {
+			fail("value must be boolean")
+		}
+	case "array":
+		items, ok := val.([]interface{})
+		if !ok {
+			fail("value must be array")
+			return
+		}
+		if len(items) < s.MinItems {
+			fail("array must contain at least %d items", s.MinItems)
+		}
+		if s.MaxItems != nil && len(items) > *s.MaxItems {
+			fail("array must contain at most %d items", *s.MaxItems)
+		}
+		for i, item := range items {
+			v.validate(v.Document.schema(s.Items), item, in, name+"/"+strconv.Itoa(i), errs)
+		}
+	case "object":
+		obj, ok := val.(map[string]interface{})
+		if !ok {
+			fail("value must be object")
+			return
+		}
+		v.validateObject(s, obj, in, name, errs)
+	default:
+		if obj, ok := val.(map[string]interface{}); ok && (s.Properties != nil || s.Required != nil) {
+			v.validateObject(s, obj, in, name, errs)
+		}
+	}
+}
+
+func (v *Validator) validateObject(s *Schema, obj map[string]interface{}, in, name string, errs *[]ValidationError) {
+	for _, key := range s.Required {
+		if _, ok := obj[key]; !ok {
+			*errs = append(*errs, ValidationError{in, name + "/" + key, "required property is missing"})
+		}
+	}
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		prop, ok := s.Properties[key]
+		switch {
+		case ok:
+			v.validate(v.Document.schema(prop), obj[key], in, name+"/"+key, errs)
+		case s.AdditionalProperties != nil:
+			v.validate(v.Document.schema(s.AdditionalProperties), obj[key], in, name+"/"+key, errs)
+		case s.NoAdditional:
+			*errs = append(*errs, ValidationError{in, name + "/" + key, "additional property is not allowed"})
+		}
+	}
+}
+
+// count 方法返回值匹配的schema数量。
+func (v *Validator) count(schemas []*Schema, val interface{}) int {
+	n := 0
+	for _, s := range schemas {
+		var errs []ValidationError
+		v.validate(v.Document.schema(s), val, "", "", &errs)
+		if len(errs) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// parseParam 函数将字符串参数按照schema类型转换成json值，array类型使用多个值或逗号分隔的值。
+func parseParam(doc *Document, s *Schema, vals []string) (interface{}, error) {
+	if s == nil {
+		return vals[0], nil
+	}
+	if s.Type == "array" {
+		if len(vals) == 1 {
+			vals = strings.Split(vals[0], ",")
+		}
+		items := make([]interface{}, len(vals))
+		for i := range vals {
+			item, err := parseParam(doc, doc.schema(s.Items), vals[i:i+1])
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+
+	switch s.Type {
+	case "integer", "number":
+		num, err := strconv.ParseFloat(vals[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("value '%s' must be %s", vals[0], s.Type)
+		}
+		return num, nil
+	case "boolean":
+		b, err := strconv.ParseBool(vals[0])
+		if err != nil {
+			return nil, fmt.Errorf("value '%s' must be boolean", vals[0])
+		}
+		return b, nil
+	}
+	return vals[0], nil
+}
+
+// checkFormat 函数校验常用的字符串格式，未知格式不校验。
+func checkFormat(format, str string) error {
+	var err error
+	switch format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, str)
+	case "date":
+		_, err = time.Parse("2006-01-02", str)
+	case "email":
+		if i := strings.LastIndexByte(str, '@'); i < 1 || i == len(str)-1 {
+			err = fmt.Errorf("invalid email")
+		}
+	case "uuid":
+		if len(str) != 36 || strings.Count(str, "-") != 4 {
+			err = fmt.Errorf("invalid uuid")
+		}
+	case "ipv4":
+		if ip := net.ParseIP(str); ip == nil || ip.To4() == nil {
+			err = fmt.Errorf("invalid ipv4")
+		}
+	case "ipv6":
+		if ip := net.ParseIP(str); ip == nil || ip.To4() != nil {
+			err = fmt.Errorf("invalid ipv6")
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("value must be %s format", format)
+	}
+	return nil
+}
+
+// findContent 函数查找媒体类型对应的定义，支持application/*和*/*通配。
+func findContent(contents map[string]*MediaType, mediatype string) (*MediaType, bool) {
+	if len(contents) == 0 {
+		return nil, true
+	}
+	if content, ok := contents[mediatype]; ok {
+		return content, true
+	}
+	if i := strings.IndexByte(mediatype, '/'); i != -1 {
+		if content, ok := contents[mediatype[:i]+"/*"]; ok {
+			return content, true
+		}
+	}
+	content, ok := contents["*/*"]
+	return content, ok
+}
+
+func isJSON(mediatype string) bool {
+	return mediatype == eudore.MimeApplicationJSON || strings.HasSuffix(mediatype, "+json")
+}
+
+func isTemplate(seg string) bool {
+	return len(seg) > 2 && seg[0] == '{' && seg[len(seg)-1] == '}'
+}
+
+func inEnum(enum []interface{}, val interface{}) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(e, val) {
+			return true
+		}
+	}
+	return false
+}
+
+func exclusive(b bool) string {
+	if b {
+		return ""
+	}
+	return "or equal to "
+}
+
+func writeError(ctx eudore.Context, code int, msg string, errs []ValidationError) {
+	ctx.WriteHeader(code)
+	ctx.Render(Error{Status: code, Message: msg, Errors: errs})
+	ctx.End()
+}
+
+// WriteHeader 方法记录响应状态码，在校验后写入。
+func (w *responseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+// Write 方法缓冲响应body，在校验后写入。
+func (w *responseWriter) Write(data []byte) (int, error) {
+	return w.buffer.Write(data)
+}
+
+// Flush 方法不刷新缓冲，校验需要完整的响应。
+func (w *responseWriter) Flush() {}
+
+func (w *responseWriter) Status() int {
+	if w.code != 0 {
+		return w.code
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *responseWriter) Size() int {
+	return w.buffer.Len()
+}
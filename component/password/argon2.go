@@ -0,0 +1,88 @@
+package password
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2id 定义argon2id(RFC 9106)算法，hash使用PHC格式:
+//
+// $argon2id$v=19$m=19456,t=2,p=1$salt$hash
+type Argon2id struct {
+	// Memory 为使用的内存，单位KiB。
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+	KeyLen  uint32
+	SaltLen uint32
+}
+
+// NewArgon2id 函数使用OWASP推荐参数创建argon2id算法，内存19MiB、迭代2次、并行1。
+func NewArgon2id() *Argon2id {
+	return &Argon2id{
+		Memory:  19 * 1024,
+		Time:    2,
+		Threads: 1,
+		KeyLen:  32,
+		SaltLen: 16,
+	}
+}
+
+// Hash 方法使用随机salt计算密码hash。
+func (h *Argon2id) Hash(password string) (string, error) {
+	salt, err := newSalt(int(h.SaltLen))
+	if err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Time, h.Memory, h.Threads, h.KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, h.Memory, h.Time, h.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify 方法校验密码，hash格式无效返回ErrHashInvalid。
+func (h *Argon2id) Verify(hash, password string) (bool, error) {
+	params, salt, key, err := parseArgon2id(hash)
+	if err != nil {
+		return false, err
+	}
+	other := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// Support 方法判断hash是否为argon2id格式。
+func (h *Argon2id) Support(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+// NeedsRehash 方法判断hash参数是否和当前参数不同。
+func (h *Argon2id) NeedsRehash(hash string) bool {
+	params, salt, key, err := parseArgon2id(hash)
+	return err != nil || params.Memory != h.Memory || params.Time != h.Time ||
+		params.Threads != h.Threads || uint32(len(key)) != h.KeyLen || uint32(len(salt)) != h.SaltLen
+}
+
+func parseArgon2id(hash string) (*Argon2id, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" || parts[2] != fmt.Sprintf("v=%d", argon2.Version) {
+		return nil, nil, nil, ErrHashInvalid
+	}
+	params := &Argon2id{}
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads)
+	if err != nil || params.Time == 0 || params.Threads == 0 {
+		return nil, nil, nil, ErrHashInvalid
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, ErrHashInvalid
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) < 4 {
+		return nil, nil, nil, ErrHashInvalid
+	}
+	return params, salt, key, nil
+}
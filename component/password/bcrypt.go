@@ -0,0 +1,57 @@
+package password
+
+import (
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Bcrypt 定义bcrypt算法，hash使用$2a$格式，校验兼容$2b$和$2y$格式。
+//
+// bcrypt最多使用密码的72字节，Hash方法密码超过72字节返回ErrPasswordTooLong。
+type Bcrypt struct {
+	Cost int
+}
+
+const bcryptMaxLen = 72
+
+// NewBcrypt 函数创建bcrypt算法，默认cost为12。
+func NewBcrypt() *Bcrypt {
+	return &Bcrypt{Cost: 12}
+}
+
+// Hash 方法使用随机salt计算密码hash。
+func (h *Bcrypt) Hash(password string) (string, error) {
+	if len(password) > bcryptMaxLen {
+		return "", ErrPasswordTooLong
+	}
+	if h.Cost < bcrypt.MinCost || h.Cost > bcrypt.MaxCost {
+		return "", ErrHashInvalid
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	return string(hash), err
+}
+
+// Verify 方法校验密码，hash格式无效返回ErrHashInvalid。
+func (h *Bcrypt) Verify(hash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	switch err {
+	case nil:
+		return true, nil
+	case bcrypt.ErrMismatchedHashAndPassword:
+		return false, nil
+	default:
+		return false, ErrHashInvalid
+	}
+}
+
+// Support 方法判断hash是否为bcrypt格式。
+func (h *Bcrypt) Support(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// NeedsRehash 方法判断hash的cost是否和当前cost不同。
+func (h *Bcrypt) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.Cost
+}
@@ -0,0 +1,90 @@
+// Package password 实现密码hash和登录尝试限制，argon2id和bcrypt算法使用golang.org/x/crypto实现。
+//
+// Manager使用当前算法计算新密码hash，校验时兼容旧算法，密码正确并且hash使用旧算法或旧参数时返回新hash用于升级保存；
+// 默认使用argon2id算法，并兼容bcrypt算法。
+//
+// Throttle按账号+IP记录登录失败次数，失败次数达到限制后锁定一段时间，使用eudore.Cache时多实例共享计数。
+package password
+
+import (
+	"crypto/rand"
+	"errors"
+)
+
+// Hasher 定义一种密码hash算法。
+type Hasher interface {
+	// Hash 方法使用随机salt计算密码hash。
+	Hash(string) (string, error)
+	// Verify 方法校验hash和密码是否匹配。
+	Verify(string, string) (bool, error)
+	// Support 方法判断hash是否为该算法格式。
+	Support(string) bool
+	// NeedsRehash 方法判断hash参数是否和当前参数不同，需要重新计算hash。
+	NeedsRehash(string) bool
+}
+
+// Manager 定义密码hash管理，Hasher为当前算法，Hashers为兼容校验的旧算法。
+type Manager struct {
+	Hasher  Hasher
+	Hashers []Hasher
+}
+
+// 定义密码错误。
+var (
+	ErrPasswordMismatch = errors.New("password mismatch")
+	ErrPasswordTooLong  = errors.New("password is too long")
+	ErrHashInvalid      = errors.New("password hash is invalid")
+	ErrHashUnknown      = errors.New("password hash algorithm unknown")
+)
+
+// DefaultManager 定义默认密码hash管理，使用argon2id算法并兼容bcrypt算法。
+var DefaultManager = NewManager(NewArgon2id(), NewBcrypt())
+
+// NewManager 函数创建密码hash管理，hasher为当前算法，legacy为兼容校验的旧算法。
+func NewManager(hasher Hasher, legacy ...Hasher) *Manager {
+	return &Manager{Hasher: hasher, Hashers: legacy}
+}
+
+// Hash 函数使用DefaultManager计算密码hash。
+func Hash(password string) (string, error) {
+	return DefaultManager.Hash(password)
+}
+
+// Verify 函数使用DefaultManager校验密码。
+func Verify(hash, password string) (string, error) {
+	return DefaultManager.Verify(hash, password)
+}
+
+// Hash 方法使用当前算法计算密码hash。
+func (m *Manager) Hash(password string) (string, error) {
+	return m.Hasher.Hash(password)
+}
+
+// Verify 方法校验密码，密码错误返回ErrPasswordMismatch，hash格式不属于任何算法返回ErrHashUnknown。
+//
+// 密码正确并且hash使用旧算法或旧参数时，返回使用当前算法计算的新hash，调用者需要保存新hash完成升级。
+func (m *Manager) Verify(hash, password string) (string, error) {
+	for _, hasher := range append([]Hasher{m.Hasher}, m.Hashers...) {
+		if !hasher.Support(hash) {
+			continue
+		}
+		ok, err := hasher.Verify(hash, password)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", ErrPasswordMismatch
+		}
+		if hasher != m.Hasher || m.Hasher.NeedsRehash(hash) {
+			return m.Hasher.Hash(password)
+		}
+		return "", nil
+	}
+	return "", ErrHashUnknown
+}
+
+func newSalt(n int) ([]byte, error) {
+	salt := make([]byte, n)
+	_, err := rand.Read(salt)
+	return salt, err
+}
@@ -0,0 +1,120 @@
+package password
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// Throttle 定义登录尝试限制，按账号+IP记录失败次数，同时限制单个IP对全部账号的失败次数。
+//
+// 失败次数在第一次失败后Window时间内累计，达到限制后锁定Lockout时间，登录成功后清除账号+IP的失败次数。
+type Throttle struct {
+	sync.Mutex
+	Cache eudore.Cache
+	// MaxAttempts 为账号+IP允许的失败次数，默认5。
+	MaxAttempts int
+	// MaxIPAttempts 为单个IP允许的失败次数，默认100，为0时不限制。
+	MaxIPAttempts int
+	// Window 为失败次数的累计时间，默认15分钟。
+	Window time.Duration
+	// Lockout 为达到失败次数后的锁定时间，默认15分钟。
+	Lockout time.Duration
+}
+
+// throttleState 定义一个key的失败次数。
+type throttleState struct {
+	Count int       `json:"count"`
+	Reset time.Time `json:"reset"`
+	Until time.Time `json:"until,omitempty"`
+}
+
+// NewThrottle 函数创建登录尝试限制，cache为空时使用内存缓存，使用共享缓存时多实例共享失败次数。
+func NewThrottle(cache eudore.Cache) *Throttle {
+	if cache == nil {
+		cache = eudore.NewCacheMemory()
+	}
+	return &Throttle{
+		Cache:         cache,
+		MaxAttempts:   5,
+		MaxIPAttempts: 100,
+		Window:        15 * time.Minute,
+		Lockout:       15 * time.Minute,
+	}
+}
+
+// Allow 方法检查账号+IP是否允许尝试登录，锁定时返回剩余锁定时间。
+func (t *Throttle) Allow(account, ip string) (bool, time.Duration) {
+	now := time.Now()
+	var wait time.Duration
+	for _, key := range t.getKeys(account, ip) {
+		state := t.getState(key)
+		if state.Until.After(now) && state.Until.Sub(now) > wait {
+			wait = state.Until.Sub(now)
+		}
+	}
+	return wait == 0, wait
+}
+
+// Failure 方法记录一次登录失败，达到失败次数时返回锁定时间。
+func (t *Throttle) Failure(account, ip string) time.Duration {
+	t.Lock()
+	defer t.Unlock()
+	now := time.Now()
+	var wait time.Duration
+	for i, key := range t.getKeys(account, ip) {
+		state := t.getState(key)
+		if now.After(state.Reset) {
+			state = &throttleState{Reset: now.Add(t.Window)}
+		}
+		state.Count++
+		max := t.MaxAttempts
+		if i == 1 {
+			max = t.MaxIPAttempts
+		}
+		if state.Count >= max {
+			state.Until = now.Add(t.Lockout)
+			state.Reset = state.Until
+			wait = t.Lockout
+		}
+		body, _ := json.Marshal(state)
+		t.Cache.Set(key, body, state.Reset.Sub(now))
+	}
+	return wait
+}
+
+// Success 方法在登录成功后清除账号+IP的失败次数，IP的失败次数不清除。
+func (t *Throttle) Success(account, ip string) {
+	t.Cache.Delete(t.getKeys(account, ip)[0])
+}
+
+// Check 方法使用请求的RealIP检查账号是否允许尝试登录，锁定时写入429状态码和Retry-After Header。
+func (t *Throttle) Check(ctx eudore.Context, account string) bool {
+	ok, wait := t.Allow(account, ctx.RealIP())
+	if !ok {
+		ctx.SetHeader(eudore.HeaderRetryAfter, strconv.Itoa(int(wait/time.Second)+1))
+		ctx.WriteHeader(eudore.StatusTooManyRequests)
+		ctx.Fatal("login attempts exceeded for " + account)
+	}
+	return ok
+}
+
+func (t *Throttle) getKeys(account, ip string) []string {
+	keys := []string{"login:" + account + "|" + ip}
+	if t.MaxIPAttempts > 0 {
+		keys = append(keys, "login-ip:"+ip)
+	}
+	return keys
+}
+
+func (t *Throttle) getState(key string) *throttleState {
+	state := &throttleState{}
+	body, err := t.Cache.Get(key)
+	if err == nil {
+		json.Unmarshal(body, state)
+	}
+	return state
+}
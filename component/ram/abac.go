@@ -0,0 +1,100 @@
+package ram
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/eudore/eudore"
+)
+
+type (
+	// Abac 定义ABAC鉴权对象，使用请求属性表达式规则鉴权。
+	Abac struct {
+		sync.RWMutex
+		Rules []*AbacRule
+	}
+	// AbacRule 定义一条ABAC规则，Action为空匹配全部action。
+	AbacRule struct {
+		Name       string      `json:"name"`
+		Effect     bool        `json:"effect"`
+		Action     []string    `json:"action"`
+		Expression string      `json:"expression"`
+		expr       *Expression `json:"-"`
+	}
+)
+
+var _ Handler = (*Abac)(nil)
+
+// NewAbac 函数创建一个*Abac对象。
+func NewAbac() *Abac {
+	return &Abac{}
+}
+
+// Name 方法返回abac name。
+func (a *Abac) Name() string {
+	return "abac"
+}
+
+// Match 方法实现ram.Handler接口，按顺序匹配第一条命中的规则。
+func (a *Abac) Match(id int, action string, ctx eudore.Context) (bool, bool) {
+	a.RLock()
+	defer a.RUnlock()
+	for _, rule := range a.Rules {
+		if rule.MatchAction(action) && rule.expr.Match(ctx) {
+			ctx.SetParam("policy", rule.Name)
+			return rule.Effect, true
+		}
+	}
+	return false, false
+}
+
+// MatchAction 方法匹配规则的action。
+func (rule *AbacRule) MatchAction(action string) bool {
+	if len(rule.Action) == 0 {
+		return true
+	}
+	for _, i := range rule.Action {
+		if matchStar(action, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddRule 方法编译规则表达式并添加规则。
+func (a *Abac) AddRule(rules ...*AbacRule) error {
+	for _, rule := range rules {
+		expr, err := CompileExpression(rule.Expression)
+		if err != nil {
+			return err
+		}
+		rule.expr = expr
+	}
+	a.Lock()
+	a.Rules = append(a.Rules, rules...)
+	a.Unlock()
+	return nil
+}
+
+// AddRuleStringJSON 方法添加JSON数组字符串格式的多条规则。
+func (a *Abac) AddRuleStringJSON(str string) error {
+	var rules []*AbacRule
+	err := json.Unmarshal([]byte(str), &rules)
+	if err != nil {
+		return err
+	}
+	return a.AddRule(rules...)
+}
+
+// DeleteRule 方法删除指定名称的规则。
+func (a *Abac) DeleteRule(name string) {
+	a.Lock()
+	defer a.Unlock()
+	rules := a.Rules[:0]
+	for _, rule := range a.Rules {
+		if rule.Name != name {
+			rules = append(rules, rule)
+		}
+	}
+	a.Rules = rules
+}
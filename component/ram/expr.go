@@ -0,0 +1,494 @@
+package ram
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/eudore/eudore"
+	"github.com/eudore/eudore/middleware"
+)
+
+/*
+Expression 实现一个简单的请求属性表达式，在加载时编译，例如:
+	method == "GET" && param.tenant == claim.tenant
+	header.X-Level >= 3 || ip in ["127.0.0.1", "10.0.0.1"]
+	!(path matches "^/admin/")
+
+属性:
+	method path host ip action uid uname
+	param.NAME header.NAME query.NAME cookie.NAME claim.NAME[.NAME]
+
+运算符优先级从低到高: || && ! (== != < <= > >= in matches)
+*/
+
+// Expression 定义一个编译后的表达式。
+type Expression struct {
+	Text string
+	eval exprFunc
+}
+
+type exprFunc func(eudore.Context) interface{}
+
+// conditionExpr 定义表达式条件，err为表达式编译错误。
+type conditionExpr struct {
+	*Expression
+	err error
+}
+
+var exprAttributes = map[string]func(eudore.Context, string) interface{}{
+	"param": func(ctx eudore.Context, key string) interface{} {
+		return ctx.GetParam(key)
+	},
+	"header": func(ctx eudore.Context, key string) interface{} {
+		return ctx.GetHeader(key)
+	},
+	"query": func(ctx eudore.Context, key string) interface{} {
+		return ctx.GetQuery(key)
+	},
+	"cookie": func(ctx eudore.Context, key string) interface{} {
+		return ctx.GetCookie(key)
+	},
+	"claim": func(ctx eudore.Context, key string) interface{} {
+		var val interface{} = middleware.GetOIDCClaims(ctx)
+		for _, name := range strings.Split(key, ".") {
+			data, ok := val.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			val = data[name]
+		}
+		return val
+	},
+}
+
+var exprVariables = map[string]func(eudore.Context) interface{}{
+	"method": func(ctx eudore.Context) interface{} {
+		return ctx.Method()
+	},
+	"path": func(ctx eudore.Context) interface{} {
+		return ctx.Path()
+	},
+	"host": func(ctx eudore.Context) interface{} {
+		return ctx.Host()
+	},
+	"ip": func(ctx eudore.Context) interface{} {
+		return ctx.RealIP()
+	},
+	"action": func(ctx eudore.Context) interface{} {
+		return ctx.GetParam(eudore.ParamAction)
+	},
+	"uid": func(ctx eudore.Context) interface{} {
+		return ctx.GetParam(eudore.ParamUID)
+	},
+	"uname": func(ctx eudore.Context) interface{} {
+		return ctx.GetParam(eudore.ParamUNAME)
+	},
+}
+
+// RegisterExprAttribute 函数注册一个带前缀的表达式属性，例如claim.NAME。
+func RegisterExprAttribute(prefix string, fn func(eudore.Context, string) interface{}) {
+	exprAttributes[prefix] = fn
+}
+
+// RegisterExprVariable 函数注册一个表达式变量，例如method。
+func RegisterExprVariable(name string, fn func(eudore.Context) interface{}) {
+	exprVariables[name] = fn
+}
+
+// CompileExpression 函数编译一个表达式。
+func CompileExpression(text string) (*Expression, error) {
+	p := &exprParser{text: text}
+	p.next()
+	fn, err := p.parseOr()
+	if err == nil {
+		err = p.err
+	}
+	if err == nil && p.tok.kind != exprTokenEOF {
+		err = p.errorf("unexpected %q", p.tok.val)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Expression{Text: text, eval: fn}, nil
+}
+
+// Match 方法执行表达式，返回结果是否为true。
+func (e *Expression) Match(ctx eudore.Context) bool {
+	b, ok := e.eval(ctx).(bool)
+	return ok && b
+}
+
+// String 方法返回表达式原文。
+func (e *Expression) String() string {
+	return e.Text
+}
+
+// NewConditionExpr 方法创建一个表达式条件。
+//
+// 表达式编译失败时解析策略返回错误；直接使用时Deny语句的条件总是匹配，Allow语句的条件永远不匹配。
+func NewConditionExpr(i interface{}) Condition {
+	text := eudore.GetString(i)
+	expr, err := CompileExpression(text)
+	if err != nil {
+		expr = &Expression{Text: text, eval: func(eudore.Context) interface{} {
+			return false
+		}}
+	}
+	return conditionExpr{expr, err}
+}
+
+// conditionErr 方法返回表达式编译错误。
+func (cond conditionExpr) conditionErr() error {
+	if cond.err != nil {
+		return fmt.Errorf("ram condition expr %q error: %v", cond.Text, cond.err)
+	}
+	return nil
+}
+
+// Name 方法返回条件名称。
+func (cond conditionExpr) Name() string {
+	return "expr"
+}
+
+// MarshalJSON 方法实现表达式条件的序列化。
+func (cond conditionExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cond.Text)
+}
+
+const (
+	exprTokenEOF = iota
+	exprTokenIdent
+	exprTokenString
+	exprTokenNumber
+	exprTokenOperator
+)
+
+type exprToken struct {
+	kind int
+	val  string
+	pos  int
+}
+
+// exprParser 定义表达式的词法和语法分析器，使用递归下降将表达式编译为闭包。
+type exprParser struct {
+	text string
+	pos  int
+	tok  exprToken
+	err  error
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("expression %q at %d: %s", p.text, p.tok.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *exprParser) next() {
+	for p.pos < len(p.text) && (p.text[p.pos] == ' ' || p.text[p.pos] == '\t' || p.text[p.pos] == '\n') {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.text) {
+		p.tok = exprToken{exprTokenEOF, "", start}
+		return
+	}
+	c := p.text[p.pos]
+	switch {
+	case c == '"' || c == '\'':
+		p.pos++
+		for p.pos < len(p.text) && p.text[p.pos] != c {
+			if p.text[p.pos] == '\\' {
+				p.pos++
+			}
+			p.pos++
+		}
+		if p.pos >= len(p.text) {
+			p.err = fmt.Errorf("expression %q at %d: unterminated string", p.text, start)
+			p.tok = exprToken{exprTokenEOF, "", start}
+			return
+		}
+		p.pos++
+		val := p.text[start:p.pos]
+		if c == '\'' {
+			val = `"` + strings.Replace(val[1:len(val)-1], `"`, `\"`, -1) + `"`
+		}
+		str, err := strconv.Unquote(val)
+		if err != nil {
+			p.err = fmt.Errorf("expression %q at %d: %v", p.text, start, err)
+		}
+		p.tok = exprToken{exprTokenString, str, start}
+	case c >= '0' && c <= '9' || c == '-' && p.pos+1 < len(p.text) && p.text[p.pos+1] >= '0' && p.text[p.pos+1] <= '9':
+		p.pos++
+		for p.pos < len(p.text) && (p.text[p.pos] >= '0' && p.text[p.pos] <= '9' || p.text[p.pos] == '.') {
+			p.pos++
+		}
+		p.tok = exprToken{exprTokenNumber, p.text[start:p.pos], start}
+	case isExprIdent(c):
+		for p.pos < len(p.text) && (isExprIdent(p.text[p.pos]) || p.text[p.pos] >= '0' && p.text[p.pos] <= '9' || p.text[p.pos] == '.' || p.text[p.pos] == '-') {
+			p.pos++
+		}
+		p.tok = exprToken{exprTokenIdent, p.text[start:p.pos], start}
+	default:
+		for _, op := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ","} {
+			if strings.HasPrefix(p.text[p.pos:], op) {
+				p.pos += len(op)
+				p.tok = exprToken{exprTokenOperator, op, start}
+				return
+			}
+		}
+		p.err = fmt.Errorf("expression %q at %d: invalid character %q", p.text, start, c)
+		p.tok = exprToken{exprTokenEOF, "", start}
+	}
+}
+
+func isExprIdent(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
+
+func (p *exprParser) isOperator(op string) bool {
+	return p.tok.kind == exprTokenOperator && p.tok.val == op
+}
+
+func (p *exprParser) parseOr() (exprFunc, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.isOperator("||") {
+		p.next()
+		var right exprFunc
+		right, err = p.parseAnd()
+		l := left
+		left = func(ctx eudore.Context) interface{} {
+			return exprBool(l(ctx)) || exprBool(right(ctx))
+		}
+	}
+	return left, err
+}
+
+func (p *exprParser) parseAnd() (exprFunc, error) {
+	left, err := p.parseNot()
+	for err == nil && p.isOperator("&&") {
+		p.next()
+		var right exprFunc
+		right, err = p.parseNot()
+		l := left
+		left = func(ctx eudore.Context) interface{} {
+			return exprBool(l(ctx)) && exprBool(right(ctx))
+		}
+	}
+	return left, err
+}
+
+func (p *exprParser) parseNot() (exprFunc, error) {
+	if p.isOperator("!") {
+		p.next()
+		fn, err := p.parseNot()
+		return func(ctx eudore.Context) interface{} {
+			return !exprBool(fn(ctx))
+		}, err
+	}
+	return p.parseCompare()
+}
+
+func (p *exprParser) parseCompare() (exprFunc, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	op := p.tok.val
+	switch {
+	case p.tok.kind == exprTokenOperator && (op == "==" || op == "!=" || op == "<" || op == "<=" || op == ">" || op == ">="):
+		p.next()
+		right, err := p.parsePrimary()
+		return func(ctx eudore.Context) interface{} {
+			return exprCompare(op, left(ctx), right(ctx))
+		}, err
+	case p.tok.kind == exprTokenIdent && op == "in":
+		p.next()
+		right, err := p.parsePrimary()
+		return func(ctx eudore.Context) interface{} {
+			val := left(ctx)
+			list, _ := right(ctx).([]interface{})
+			for _, i := range list {
+				if exprCompare("==", val, i) {
+					return true
+				}
+			}
+			return false
+		}, err
+	case p.tok.kind == exprTokenIdent && op == "matches":
+		p.next()
+		if p.tok.kind != exprTokenString {
+			return nil, p.errorf("matches requires a string pattern")
+		}
+		re, err := regexp.Compile(p.tok.val)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		p.next()
+		return func(ctx eudore.Context) interface{} {
+			return re.MatchString(exprString(left(ctx)))
+		}, nil
+	}
+	return left, nil
+}
+
+func (p *exprParser) parsePrimary() (exprFunc, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	tok := p.tok
+	switch tok.kind {
+	case exprTokenString:
+		p.next()
+		return exprConst(tok.val), nil
+	case exprTokenNumber:
+		p.next()
+		num, err := strconv.ParseFloat(tok.val, 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", tok.val)
+		}
+		return exprConst(num), nil
+	case exprTokenIdent:
+		fn, err := p.parseIdent(tok.val)
+		p.next()
+		return fn, err
+	case exprTokenOperator:
+		switch tok.val {
+		case "(":
+			p.next()
+			fn, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.isOperator(")") {
+				return nil, p.errorf("expected ')'")
+			}
+			p.next()
+			return fn, nil
+		case "[":
+			return p.parseList()
+		}
+	case exprTokenEOF:
+		return nil, p.errorf("unexpected end of expression")
+	}
+	return nil, p.errorf("unexpected %q", tok.val)
+}
+
+func (p *exprParser) parseList() (exprFunc, error) {
+	p.next()
+	var items []exprFunc
+	for !p.isOperator("]") {
+		fn, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, fn)
+		if p.isOperator(",") {
+			p.next()
+		} else if !p.isOperator("]") {
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+	p.next()
+	return func(ctx eudore.Context) interface{} {
+		list := make([]interface{}, len(items))
+		for i, fn := range items {
+			list[i] = fn(ctx)
+		}
+		return list
+	}, nil
+}
+
+func (p *exprParser) parseIdent(name string) (exprFunc, error) {
+	switch name {
+	case "true":
+		return exprConst(true), nil
+	case "false":
+		return exprConst(false), nil
+	case "null":
+		return exprConst(nil), nil
+	}
+	if fn, ok := exprVariables[name]; ok {
+		return fn, nil
+	}
+	pos := strings.IndexByte(name, '.')
+	if pos != -1 {
+		fn, ok := exprAttributes[name[:pos]]
+		key := name[pos+1:]
+		if ok && key != "" {
+			return func(ctx eudore.Context) interface{} {
+				return fn(ctx, key)
+			}, nil
+		}
+	}
+	return nil, p.errorf("undefined attribute %q", name)
+}
+
+func exprConst(val interface{}) exprFunc {
+	return func(eudore.Context) interface{} {
+		return val
+	}
+}
+
+func exprBool(val interface{}) bool {
+	b, ok := val.(bool)
+	return ok && b
+}
+
+func exprString(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func exprNumber(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		num, err := strconv.ParseFloat(v, 64)
+		return num, err == nil
+	}
+	return 0, false
+}
+
+// exprCompare 函数比较两个值，如果两个值都是数字使用数字比较，否则使用字符串比较。
+func exprCompare(op string, left, right interface{}) bool {
+	var cmp int
+	ln, lok := exprNumber(left)
+	rn, rok := exprNumber(right)
+	if lok && rok {
+		switch {
+		case ln < rn:
+			cmp = -1
+		case ln > rn:
+			cmp = 1
+		}
+	} else {
+		cmp = strings.Compare(exprString(left), exprString(right))
+	}
+	switch op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
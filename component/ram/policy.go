@@ -29,6 +29,10 @@ type (
 		Name() string
 		Match(ctx eudore.Context) bool
 	}
+	// conditionErrer 定义可以返回创建错误的条件。
+	conditionErrer interface {
+		conditionErr() error
+	}
 	// conditionOr 定义Or条件。
 	conditionOr struct {
 		Conditions []Condition `json:"conditions,omitempty"`
@@ -64,9 +68,10 @@ func init() {
 	conditionnews["sourceip"] = NewConditionSourceIP
 	conditionnews["time"] = NewConditionTime
 	conditionnews["method"] = NewConditionMethod
+	conditionnews["expr"] = NewConditionExpr
 }
 
-// RegisterCondition 方法支持一个条件构造函数，默认存在or、and、sourceip、time、method。
+// RegisterCondition 方法支持一个条件构造函数，默认存在or、and、sourceip、time、method、expr。
 func RegisterCondition(name string, cond func(interface{}) Condition) {
 	conditionnews[name] = cond
 }
@@ -98,11 +103,14 @@ func (stat Statement) MatchResource(resource string) bool {
 	return false
 }
 
-// MatchCondition 方法匹配描述的条件。
+// MatchCondition 方法匹配描述的条件，条件无效时Deny语句总是匹配，Allow语句不匹配。
 func (stat Statement) MatchCondition(ctx eudore.Context) bool {
 	if stat.Conditions == nil {
 		return true
 	}
+	if getConditionError(stat.Conditions) != nil {
+		return !stat.Effect
+	}
 	return stat.Conditions.Match(ctx)
 }
 
@@ -124,6 +132,29 @@ func (stat *Statement) UnmarshalJSON(body []byte) error {
 	conds := NewConditions(data.Conditions)
 	if len(conds) > 0 {
 		stat.Conditions = conditionAnd{conds}
+		return getConditionError(stat.Conditions)
+	}
+	return nil
+}
+
+// getConditionError 函数递归检查or、and条件，返回第一个条件创建错误。
+func getConditionError(cond Condition) error {
+	switch val := cond.(type) {
+	case conditionErrer:
+		return val.conditionErr()
+	case conditionAnd:
+		return getConditionsError(val.Conditions)
+	case *conditionOr:
+		return getConditionsError(val.Conditions)
+	}
+	return nil
+}
+
+func getConditionsError(conds []Condition) error {
+	for _, cond := range conds {
+		if err := getConditionError(cond); err != nil {
+			return err
+		}
 	}
 	return nil
 }
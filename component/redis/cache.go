@@ -0,0 +1,56 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// cache 定义基于Redis的eudore.Cache。
+type cache struct {
+	client *Client
+	prefix string
+}
+
+// NewCache 函数使用Redis客户端创建eudore.Cache，key会添加prefix前缀，多个实例共享缓存数据。
+func NewCache(client *Client, prefix string) eudore.Cache {
+	return &cache{client: client, prefix: prefix}
+}
+
+// Get 方法获取key对应的值，key不存在返回eudore.ErrCacheMiss。
+func (c *cache) Get(key string) ([]byte, error) {
+	reply, err := c.client.Do("GET", c.prefix+key)
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, eudore.ErrCacheMiss
+	}
+	return []byte(String(reply)), nil
+}
+
+// Set 方法设置key对应的值，ttl为0表示不过期。
+func (c *cache) Set(key string, val []byte, ttl time.Duration) error {
+	args := []interface{}{c.prefix + key, val}
+	if ttl > 0 {
+		args = append(args, "PX", ttl.Nanoseconds()/int64(time.Millisecond))
+	}
+	_, err := c.client.Do("SET", args...)
+	return err
+}
+
+// Add 方法使用SET NX在key不存在时设置值。
+func (c *cache) Add(key string, val []byte, ttl time.Duration) (bool, error) {
+	args := []interface{}{c.prefix + key, val, "NX"}
+	if ttl > 0 {
+		args = append(args, "PX", ttl.Nanoseconds()/int64(time.Millisecond))
+	}
+	reply, err := c.client.Do("SET", args...)
+	return reply != nil, err
+}
+
+// Delete 方法删除key。
+func (c *cache) Delete(key string) error {
+	_, err := c.client.Do("DEL", c.prefix+key)
+	return err
+}
@@ -0,0 +1,60 @@
+package redis
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+
+	"github.com/eudore/eudore/middleware"
+)
+
+// rateScript 使用Redis服务端时间执行与内存限流相同的令牌桶算法，时间单位为微秒。
+const rateScript = `redis.replicate_commands()
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local speed = tonumber(ARGV[1])
+local max = tonumber(ARGV[2])
+local last = tonumber(redis.call('GET', KEYS[1]) or (now - max))
+if last >= now then
+	return 0
+end
+last = last + speed
+if last < now - max then
+	last = now - max
+end
+local ttl = math.ceil((last + max - now) / 1000)
+if ttl < 1 then
+	ttl = 1
+end
+redis.call('SET', KEYS[1], string.format('%d', last), 'PX', ttl)
+return 1`
+
+// rateStore 定义基于Redis的middleware.RateStore。
+type rateStore struct {
+	client *Client
+	prefix string
+	sha    string
+}
+
+// NewRateStore 函数使用Redis客户端创建middleware.RateStore，使用Lua脚本原子计算令牌，多实例共享限流计数。
+func NewRateStore(client *Client, prefix string) middleware.RateStore {
+	sum := sha1.Sum([]byte(rateScript))
+	return &rateStore{
+		client: client,
+		prefix: prefix,
+		sha:    hex.EncodeToString(sum[:]),
+	}
+}
+
+// Allow 方法执行限流脚本，脚本未加载时使用EVAL加载。
+func (s *rateStore) Allow(key string, speed, max int64) (bool, error) {
+	speed, max = speed/1000, max/1000
+	reply, err := s.client.Do("EVALSHA", s.sha, 1, s.prefix+key, speed, max)
+	if err != nil && strings.HasPrefix(err.Error(), "NOSCRIPT") {
+		reply, err = s.client.Do("EVAL", rateScript, 1, s.prefix+key, speed, max)
+	}
+	if err != nil {
+		return false, err
+	}
+	return reply == int64(1), nil
+}
@@ -0,0 +1,224 @@
+// Package redis 实现一个简单的Redis RESP协议客户端和基于Redis的eudore.Cache。
+package redis
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Client 定义Redis客户端，使用连接池执行命令。
+type Client struct {
+	Addr        string
+	Password    string
+	DB          int
+	DialTimeout time.Duration
+	pool        chan *conn
+}
+
+type conn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// Error 定义Redis返回的错误响应。
+type Error string
+
+// Error 方法实现error接口。
+func (err Error) Error() string {
+	return string(err)
+}
+
+// NewClient 函数创建一个Redis客户端，最多保持size个空闲连接，size默认为8。
+func NewClient(addr, password string, db int, size ...int) *Client {
+	n := 8
+	if len(size) > 0 && size[0] > 0 {
+		n = size[0]
+	}
+	return &Client{
+		Addr:        addr,
+		Password:    password,
+		DB:          db,
+		DialTimeout: 5 * time.Second,
+		pool:        make(chan *conn, n),
+	}
+}
+
+// Do 方法执行一个命令，参数类型为[]byte或使用fmt.Sprint转换成字符串，
+// 返回string、int64、[]byte、[]interface{}或nil，Redis错误响应返回Error。
+func (c *Client) Do(cmd string, args ...interface{}) (interface{}, error) {
+	cn, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	_, err = WriteCommand(cn, cmd, args...)
+	if err != nil {
+		cn.Close()
+		return nil, err
+	}
+	reply, err := ReadReply(cn.reader)
+	if _, ok := err.(Error); err != nil && !ok {
+		cn.Close()
+		return nil, err
+	}
+	c.put(cn)
+	return reply, err
+}
+
+// Close 方法关闭全部空闲连接。
+func (c *Client) Close() error {
+	for {
+		select {
+		case cn := <-c.pool:
+			cn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// Dial 方法创建一个新连接，执行AUTH和SELECT命令，返回连接和对应的读取缓冲。
+func (c *Client) Dial() (net.Conn, *bufio.Reader, error) {
+	nc, err := net.DialTimeout("tcp", c.Addr, c.DialTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader := bufio.NewReader(nc)
+	if c.Password != "" {
+		err = dialCommand(nc, reader, "AUTH", c.Password)
+	}
+	if err == nil && c.DB != 0 {
+		err = dialCommand(nc, reader, "SELECT", c.DB)
+	}
+	if err != nil {
+		nc.Close()
+		return nil, nil, err
+	}
+	return nc, reader, nil
+}
+
+func dialCommand(w io.Writer, r *bufio.Reader, cmd string, args ...interface{}) error {
+	_, err := WriteCommand(w, cmd, args...)
+	if err != nil {
+		return err
+	}
+	reply, err := ReadReply(r)
+	if err != nil {
+		return err
+	}
+	if reply != "OK" {
+		return fmt.Errorf("redis %s reply: %v", cmd, reply)
+	}
+	return nil
+}
+
+func (c *Client) get() (*conn, error) {
+	select {
+	case cn := <-c.pool:
+		return cn, nil
+	default:
+		nc, reader, err := c.Dial()
+		if err != nil {
+			return nil, err
+		}
+		return &conn{Conn: nc, reader: reader}, nil
+	}
+}
+
+func (c *Client) put(cn *conn) {
+	select {
+	case c.pool <- cn:
+	default:
+		cn.Close()
+	}
+}
+
+// WriteCommand 函数使用RESP数组格式写入一个命令。
+func WriteCommand(w io.Writer, cmd string, args ...interface{}) (int, error) {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)+1), 10)
+	buf = append(buf, '\r', '\n')
+	buf = appendBulk(buf, []byte(cmd))
+	for _, arg := range args {
+		switch val := arg.(type) {
+		case []byte:
+			buf = appendBulk(buf, val)
+		case string:
+			buf = appendBulk(buf, []byte(val))
+		default:
+			buf = appendBulk(buf, []byte(fmt.Sprint(val)))
+		}
+	}
+	return w.Write(buf)
+}
+
+func appendBulk(buf, data []byte) []byte {
+	buf = append(buf, '$')
+	buf = strconv.AppendInt(buf, int64(len(data)), 10)
+	buf = append(buf, '\r', '\n')
+	buf = append(buf, data...)
+	return append(buf, '\r', '\n')
+}
+
+// ReadReply 函数读取一个RESP响应，返回string、int64、[]byte、[]interface{}或nil，错误响应返回Error，数组内的错误响应作为Error元素。
+func ReadReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis invalid reply line: %q", line)
+	}
+	body := line[1 : len(line)-2]
+	switch line[0] {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, Error(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		data := make([]byte, n+2)
+		_, err = io.ReadFull(r, data)
+		if err != nil {
+			return nil, err
+		}
+		return data[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		vals := make([]interface{}, n)
+		for i := range vals {
+			vals[i], err = ReadReply(r)
+			if e, ok := err.(Error); ok {
+				// 数组内的错误响应作为元素返回，保证读取完整个响应。
+				vals[i] = e
+			} else if err != nil {
+				return nil, err
+			}
+		}
+		return vals, nil
+	}
+	return nil, fmt.Errorf("redis invalid reply type: %q", line)
+}
+
+// String 函数将响应转换成字符串，[]byte直接转换。
+func String(reply interface{}) string {
+	if b, ok := reply.([]byte); ok {
+		return string(b)
+	}
+	if reply == nil {
+		return ""
+	}
+	return fmt.Sprint(reply)
+}
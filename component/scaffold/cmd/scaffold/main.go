@@ -0,0 +1,60 @@
+// Command scaffold 使用json格式的描述生成eudore CRUD代码，可以使用go:generate调用：
+//
+//	//go:generate go run github.com/eudore/eudore/component/scaffold/cmd/scaffold -spec user.json -out user_scaffold.go
+//
+// 输出文件已经存在时默认不会覆盖，避免覆盖已经修改的脚手架代码。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/eudore/eudore/component/scaffold"
+)
+
+func main() {
+	specfile := flag.String("spec", "scaffold.json", "json spec file")
+	outfile := flag.String("out", "", "output go file, empty writes to stdout")
+	pkg := flag.String("package", "", "override spec package name, default uses $GOPACKAGE in go:generate")
+	force := flag.Bool("force", false, "overwrite output file if exists")
+	flag.Parse()
+
+	err := run(*specfile, *outfile, *pkg, *force)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "scaffold:", err)
+		os.Exit(1)
+	}
+}
+
+func run(specfile, outfile, pkg string, force bool) error {
+	file, err := os.Open(specfile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	spec, err := scaffold.LoadSpec(file)
+	if err != nil {
+		return err
+	}
+	if pkg == "" {
+		pkg = os.Getenv("GOPACKAGE")
+	}
+	if pkg != "" {
+		spec.Package = pkg
+	}
+
+	data, err := scaffold.Generate(spec)
+	if err != nil {
+		return err
+	}
+	if outfile == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if _, err := os.Stat(outfile); err == nil && !force {
+		return fmt.Errorf("output file %s already exists, use -force to overwrite", outfile)
+	}
+	return ioutil.WriteFile(outfile, data, 0644)
+}
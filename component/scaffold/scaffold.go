@@ -0,0 +1,395 @@
+// Package scaffold 实现CRUD代码脚手架，使用json格式的简单描述生成控制器、带bind和validate tag的请求响应结构体和路由注册代码。
+//
+// 生成的控制器实现Inject方法注册RESTful路由，使用内存保存数据，可以直接运行调试，然后替换成数据库等实际实现。
+package scaffold
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+type (
+	// Spec 定义脚手架描述。
+	Spec struct {
+		Package   string      `json:"package"`
+		Resources []*Resource `json:"resources"`
+	}
+	// Resource 定义一个资源，Path为控制器路由组，默认为小写名称；
+	// Actions为生成的操作，可以为list、get、create、update、delete，默认生成全部操作。
+	Resource struct {
+		Name    string   `json:"name"`
+		Path    string   `json:"path,omitempty"`
+		Fields  []*Field `json:"fields"`
+		Actions []string `json:"actions,omitempty"`
+	}
+	// Field 定义资源的一个属性，Type可以为string、int、int64、float64、bool、time，
+	// Validate为eudore.Validater使用的规则，例如nozero、min:1、len:<32。
+	Field struct {
+		Name     string `json:"name"`
+		Type     string `json:"type"`
+		JSON     string `json:"json,omitempty"`
+		Validate string `json:"validate,omitempty"`
+	}
+)
+
+var fieldTypes = map[string]string{
+	"string":  "string",
+	"int":     "int",
+	"int64":   "int64",
+	"float64": "float64",
+	"bool":    "bool",
+	"time":    "time.Time",
+}
+
+var actions = []string{"list", "get", "create", "update", "delete"}
+
+// LoadSpec 函数读取json格式的脚手架描述，并检查和补全默认值。
+func LoadSpec(r io.Reader) (*Spec, error) {
+	spec := new(Spec)
+	err := json.NewDecoder(r).Decode(spec)
+	if err != nil {
+		return nil, fmt.Errorf("scaffold load spec error: %v", err)
+	}
+	return spec, spec.init()
+}
+
+// Generate 函数使用描述生成go代码，生成的代码使用gofmt格式化。
+func Generate(spec *Spec) ([]byte, error) {
+	err := spec.init()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = scaffoldTemplate.Execute(&buf, spec)
+	if err != nil {
+		return nil, err
+	}
+	data, err := format.Source(buf.Bytes())
+	if err != nil {
+		return buf.Bytes(), fmt.Errorf("scaffold format source error: %v", err)
+	}
+	return data, nil
+}
+
+// init 方法检查描述并补全默认值。
+func (spec *Spec) init() error {
+	if spec.Package == "" {
+		spec.Package = "main"
+	}
+	if len(spec.Resources) == 0 {
+		return fmt.Errorf("scaffold spec has no resources")
+	}
+	for _, res := range spec.Resources {
+		if !isIdent(res.Name) || !unicode.IsUpper(rune(res.Name[0])) {
+			return fmt.Errorf("scaffold resource name '%s' must be exported go identifier", res.Name)
+		}
+		if res.Path == "" {
+			res.Path = strings.ToLower(res.Name)
+		}
+		res.Path = strings.Trim(res.Path, "/")
+		if len(res.Actions) == 0 {
+			res.Actions = actions
+		}
+		for _, action := range res.Actions {
+			if !contains(actions, action) {
+				return fmt.Errorf("scaffold resource '%s' has invalid action '%s'", res.Name, action)
+			}
+		}
+		for _, field := range res.Fields {
+			if !isIdent(field.Name) || !unicode.IsUpper(rune(field.Name[0])) || field.Name == "ID" {
+				return fmt.Errorf("scaffold resource '%s' has invalid field name '%s'", res.Name, field.Name)
+			}
+			if _, ok := fieldTypes[field.Type]; !ok {
+				return fmt.Errorf("scaffold resource '%s' field '%s' has invalid type '%s'", res.Name, field.Name, field.Type)
+			}
+			if field.JSON == "" {
+				field.JSON = snakeName(field.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// HasTime 方法返回是否需要导入time包。
+func (spec *Spec) HasTime() bool {
+	for _, res := range spec.Resources {
+		for _, field := range res.Fields {
+			if field.Type == "time" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasAction 方法返回是否有资源生成指定操作。
+func (spec *Spec) HasAction(action string) bool {
+	for _, res := range spec.Resources {
+		if res.Has(action) {
+			return true
+		}
+	}
+	return false
+}
+
+// Has 方法返回资源是否生成指定操作。
+func (res *Resource) Has(action string) bool {
+	return contains(res.Actions, action)
+}
+
+// GoType 方法返回属性的go类型。
+func (field *Field) GoType() string {
+	return fieldTypes[field.Type]
+}
+
+// Tag 方法返回属性的结构体tag，withValidate为true时附加validate规则。
+func (field *Field) Tag(withValidate bool) string {
+	tag := fmt.Sprintf(`json:"%s"`, field.JSON)
+	if withValidate && field.Validate != "" {
+		tag += fmt.Sprintf(` validate:"%s"`, field.Validate)
+	}
+	return "`" + tag + "`"
+}
+
+func contains(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
+func isIdent(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if !unicode.IsLetter(c) && c != '_' && (i == 0 || !unicode.IsDigit(c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// snakeName 函数将驼峰名称转换成下划线名称，例如CreatedAt转换成created_at。
+func snakeName(name string) string {
+	var buf bytes.Buffer
+	for i, c := range name {
+		if unicode.IsUpper(c) {
+			if i > 0 && !unicode.IsUpper(rune(name[i-1])) {
+				buf.WriteByte('_')
+			}
+			c = unicode.ToLower(c)
+		}
+		buf.WriteRune(c)
+	}
+	return buf.String()
+}
+
+var scaffoldTemplate = template.Must(template.New("scaffold").Parse(`// Code scaffolded by eudore scaffold, edit it as needed.
+
+package {{.Package}}
+
+import (
+	"errors"
+{{- if .HasAction "list"}}
+	"sort"
+{{- end}}
+	"strconv"
+	"sync"
+{{- if .HasTime}}
+	"time"
+{{- end}}
+
+	"github.com/eudore/eudore"
+)
+
+// RegisterControllers 函数注册全部脚手架生成的控制器。
+func RegisterControllers(router eudore.Router) error {
+	return router.AddController(
+{{- range .Resources}}
+		New{{.Name}}Controller(),
+{{- end}}
+	)
+}
+{{range .Resources}}
+// {{.Name}} 定义{{.Name}}资源。
+type {{.Name}} struct {
+	ID int ` + "`" + `json:"id"` + "`" + `
+{{- range .Fields}}
+	{{.Name}} {{.GoType}} {{.Tag false}}
+{{- end}}
+}
+
+// {{.Name}}Request 定义创建和更新{{.Name}}的请求。
+type {{.Name}}Request struct {
+{{- range .Fields}}
+	{{.Name}} {{.GoType}} {{.Tag true}}
+{{- end}}
+}
+
+// {{.Name}}ListRequest 定义{{.Name}}列表分页请求。
+type {{.Name}}ListRequest struct {
+	Page int ` + "`" + `json:"page" url:"page" validate:"min:0"` + "`" + `
+	Size int ` + "`" + `json:"size" url:"size" validate:"min:0,max:100"` + "`" + `
+}
+
+// {{.Name}}ListResponse 定义{{.Name}}列表分页响应。
+type {{.Name}}ListResponse struct {
+	Total int ` + "`" + `json:"total"` + "`" + `
+	Data []*{{.Name}} ` + "`" + `json:"data"` + "`" + `
+}
+
+// {{.Name}}Controller 定义{{.Name}}控制器，使用内存保存数据。
+type {{.Name}}Controller struct {
+	eudore.ControllerInstance
+	mu   sync.RWMutex
+	next int
+	data map[int]*{{.Name}}
+}
+
+// New{{.Name}}Controller 函数创建{{.Name}}控制器。
+func New{{.Name}}Controller() *{{.Name}}Controller {
+	return &{{.Name}}Controller{data: make(map[int]*{{.Name}})}
+}
+
+// Inject 方法注册{{.Name}}控制器路由到/{{.Path}}。
+func (ctl *{{.Name}}Controller) Inject(_ eudore.Controller, router eudore.Router) error {
+	router = router.Group("/{{.Path}}")
+{{- if .Has "list"}}
+	router.GetFunc("", ctl.List)
+{{- end}}
+{{- if .Has "get"}}
+	router.GetFunc("/:id", ctl.Get)
+{{- end}}
+{{- if .Has "create"}}
+	router.PostFunc("", ctl.Create)
+{{- end}}
+{{- if .Has "update"}}
+	router.PutFunc("/:id", ctl.Update)
+{{- end}}
+{{- if .Has "delete"}}
+	router.DeleteFunc("/:id", ctl.Delete)
+{{- end}}
+	return nil
+}
+{{if .Has "list"}}
+// List 方法分页查询{{.Name}}列表。
+func (ctl *{{.Name}}Controller) List(ctx eudore.Context) (interface{}, error) {
+	var req {{.Name}}ListRequest
+	err := bindValidate(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Size == 0 {
+		req.Size = 20
+	}
+
+	ctl.mu.RLock()
+	defer ctl.mu.RUnlock()
+	ids := make([]int, 0, len(ctl.data))
+	for id := range ctl.data {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	resp := &{{.Name}}ListResponse{Total: len(ids), Data: []*{{.Name}}{}}
+	for i := req.Page * req.Size; i < len(ids) && i < (req.Page+1)*req.Size; i++ {
+		resp.Data = append(resp.Data, ctl.data[ids[i]])
+	}
+	return resp, nil
+}
+{{end}}{{if .Has "get"}}
+// Get 方法查询一个{{.Name}}。
+func (ctl *{{.Name}}Controller) Get(ctx eudore.Context) (interface{}, error) {
+	ctl.mu.RLock()
+	defer ctl.mu.RUnlock()
+	return ctl.get(ctx)
+}
+{{end}}{{if .Has "create"}}
+// Create 方法创建一个{{.Name}}。
+func (ctl *{{.Name}}Controller) Create(ctx eudore.Context) (interface{}, error) {
+	var req {{.Name}}Request
+	err := bindValidate(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctl.mu.Lock()
+	defer ctl.mu.Unlock()
+	ctl.next++
+	data := &{{.Name}}{ID: ctl.next}
+	ctl.set(data, &req)
+	ctl.data[data.ID] = data
+	ctx.WriteHeader(eudore.StatusCreated)
+	return data, nil
+}
+{{end}}{{if .Has "update"}}
+// Update 方法更新一个{{.Name}}。
+func (ctl *{{.Name}}Controller) Update(ctx eudore.Context) (interface{}, error) {
+	var req {{.Name}}Request
+	err := bindValidate(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctl.mu.Lock()
+	defer ctl.mu.Unlock()
+	data, err := ctl.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctl.set(data, &req)
+	return data, nil
+}
+{{end}}{{if .Has "delete"}}
+// Delete 方法删除一个{{.Name}}。
+func (ctl *{{.Name}}Controller) Delete(ctx eudore.Context) error {
+	ctl.mu.Lock()
+	defer ctl.mu.Unlock()
+	data, err := ctl.get(ctx)
+	if err != nil {
+		return err
+	}
+	delete(ctl.data, data.ID)
+	ctx.WriteHeader(eudore.StatusNoContent)
+	return nil
+}
+{{end}}
+func (ctl *{{.Name}}Controller) get(ctx eudore.Context) (*{{.Name}}, error) {
+	id, _ := strconv.Atoi(ctx.GetParam("id"))
+	data, ok := ctl.data[id]
+	if !ok {
+		ctx.WriteHeader(eudore.StatusNotFound)
+		return nil, errNotFound
+	}
+	return data, nil
+}
+
+func (ctl *{{.Name}}Controller) set(data *{{.Name}}, req *{{.Name}}Request) {
+{{- range .Fields}}
+	data.{{.Name}} = req.{{.Name}}
+{{- end}}
+}
+{{end}}
+// errNotFound 定义资源不存在的错误。
+var errNotFound = errors.New("resource not found")
+
+// bindValidate 函数绑定并校验请求，失败时响应400。
+func bindValidate(ctx eudore.Context, i interface{}) error {
+	err := ctx.Bind(i)
+	if err == nil {
+		err = ctx.Validate(i)
+	}
+	if err != nil {
+		ctx.WriteHeader(eudore.StatusBadRequest)
+	}
+	return err
+}
+`))
@@ -0,0 +1,283 @@
+// Package soap 实现SOAP 1.1和1.2服务端适配，用于在同一个App中使用路由和中间件服务旧的SOAP调用方。
+//
+// Server解析请求Envelope，使用Body的第一个元素名称或SOAPAction选择Operation处理，响应使用请求相同的SOAP版本；
+// Operation返回错误时生成Fault响应，GET请求携带wsdl参数时返回WSDL文件。
+package soap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/eudore/eudore"
+)
+
+// 定义SOAP Envelope命名空间。
+const (
+	NamespaceSOAP11 = "http://schemas.xmlsoap.org/soap/envelope/"
+	NamespaceSOAP12 = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// 定义Fault Code，使用SOAP 1.1名称，SOAP 1.2响应时转换成Sender和Receiver。
+const (
+	FaultClient          = "Client"
+	FaultServer          = "Server"
+	FaultVersionMismatch = "VersionMismatch"
+	FaultMustUnderstand  = "MustUnderstand"
+)
+
+// 定义SOAP 1.1使用的Action Header和SOAP 1.2使用的Content-Type。
+const (
+	HeaderSOAPAction                  = "SOAPAction"
+	MimeApplicationSOAPXML            = "application/soap+xml"
+	MimeApplicationSOAPXMLCharsetUtf8 = MimeApplicationSOAPXML + "; " + eudore.MimeCharsetUtf8
+)
+
+type (
+	// Server 定义SOAP服务端，保存Operation和WSDL文件路径。
+	Server struct {
+		sync.RWMutex
+		// WSDL 定义WSDL文件路径，为空时不响应wsdl请求。
+		WSDL       string
+		operations map[string]Operation
+	}
+	// Operation 定义SOAP操作处理函数，返回值使用encoding/xml序列化后放入响应Body。
+	Operation func(eudore.Context, *Request) (interface{}, error)
+	// Request 定义一个SOAP请求。
+	Request struct {
+		// Version 为SOAP版本，值为1.1或1.2。
+		Version string
+		// Action 为SOAPAction Header或Content-Type的action参数。
+		Action string
+		// Name 为Body第一个元素名称。
+		Name   xml.Name
+		Header []byte
+		Body   []byte
+	}
+	// Fault 定义SOAP Fault，Operation返回Fault时使用其Code和Detail，其他错误使用Server Code。
+	Fault struct {
+		Code   string
+		String string
+		Actor  string
+		// Detail 使用encoding/xml序列化后放入detail元素。
+		Detail interface{}
+	}
+	envelope struct {
+		XMLName xml.Name
+		Header  *innerXML `xml:"Header"`
+		Body    *innerXML `xml:"Body"`
+	}
+	innerXML struct {
+		Content []byte `xml:",innerxml"`
+	}
+)
+
+// 定义SOAP错误。
+var (
+	ErrInvalidEnvelope = errors.New("soap request body is not a valid envelope")
+	ErrEmptyBody       = errors.New("soap envelope body is empty")
+)
+
+// NewServer 函数创建一个SOAP服务端，wsdl为WSDL文件路径。
+func NewServer(wsdl string) *Server {
+	return &Server{
+		WSDL:       wsdl,
+		operations: make(map[string]Operation),
+	}
+}
+
+// AddOperation 方法添加一个Operation，name为Body第一个元素的本地名称或SOAPAction。
+func (srv *Server) AddOperation(name string, fn Operation) {
+	srv.Lock()
+	srv.operations[name] = fn
+	srv.Unlock()
+}
+
+// Decode 方法使用encoding/xml解析Body的第一个元素。
+func (r *Request) Decode(i interface{}) error {
+	return xml.Unmarshal(r.Body, i)
+}
+
+// DecodeHeader 方法使用encoding/xml解析Header的内容，Header需要是一个元素。
+func (r *Request) DecodeHeader(i interface{}) error {
+	if len(bytes.TrimSpace(r.Header)) == 0 {
+		return nil
+	}
+	return xml.Unmarshal(r.Header, i)
+}
+
+// Error 方法实现error接口。
+func (f *Fault) Error() string {
+	return fmt.Sprintf("soap fault %s: %s", f.Code, f.String)
+}
+
+// HandleHTTP 方法处理SOAP请求，GET请求携带wsdl参数时返回WSDL文件。
+func (srv *Server) HandleHTTP(ctx eudore.Context) {
+	if ctx.Method() == eudore.MethodGet {
+		if _, ok := ctx.Querys()["wsdl"]; ok && srv.WSDL != "" {
+			ctx.SetHeader(eudore.HeaderContentType, eudore.MimeTextXMLCharsetUtf8)
+			ctx.WriteFile(srv.WSDL)
+			return
+		}
+		ctx.WriteHeader(eudore.StatusMethodNotAllowed)
+		return
+	}
+
+	req, err := srv.parseRequest(ctx)
+	if err != nil {
+		srv.writeFault(ctx, req.Version, &Fault{Code: FaultClient, String: err.Error()})
+		return
+	}
+	srv.RLock()
+	fn, ok := srv.operations[req.Name.Local]
+	if !ok && req.Action != "" {
+		fn, ok = srv.operations[req.Action]
+	}
+	srv.RUnlock()
+	if !ok {
+		srv.writeFault(ctx, req.Version, &Fault{Code: FaultClient, String: "soap operation not found: " + req.Name.Local})
+		return
+	}
+
+	data, err := fn(ctx, req)
+	if err != nil {
+		fault, ok := err.(*Fault)
+		if !ok {
+			fault = &Fault{Code: FaultServer, String: err.Error()}
+		}
+		ctx.Error(err)
+		srv.writeFault(ctx, req.Version, fault)
+		return
+	}
+	var body []byte
+	if data != nil {
+		body, err = xml.Marshal(data)
+		if err != nil {
+			ctx.Error(err)
+			srv.writeFault(ctx, req.Version, &Fault{Code: FaultServer, String: err.Error()})
+			return
+		}
+	}
+	srv.writeEnvelope(ctx, req.Version, eudore.StatusOK, body)
+}
+
+func (srv *Server) parseRequest(ctx eudore.Context) (*Request, error) {
+	req := &Request{Version: "1.1"}
+	contentType := ctx.GetHeader(eudore.HeaderContentType)
+	if strings.HasPrefix(contentType, MimeApplicationSOAPXML) {
+		req.Version = "1.2"
+		req.Action = getContentTypeParam(contentType, "action")
+	} else {
+		req.Action = strings.Trim(ctx.GetHeader(HeaderSOAPAction), `"`)
+	}
+
+	var env envelope
+	if eudore.BindXML(ctx, bytes.NewReader(ctx.Body()), &env) != nil || env.XMLName.Local != "Envelope" {
+		return req, ErrInvalidEnvelope
+	}
+	switch env.XMLName.Space {
+	case NamespaceSOAP11:
+		req.Version = "1.1"
+	case NamespaceSOAP12:
+		req.Version = "1.2"
+	default:
+		return req, ErrInvalidEnvelope
+	}
+	if env.Header != nil {
+		req.Header = env.Header.Content
+	}
+	if env.Body == nil {
+		return req, ErrEmptyBody
+	}
+
+	// 读取Body的第一个元素名称用于选择Operation。
+	decoder := xml.NewDecoder(bytes.NewReader(env.Body.Content))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return req, ErrEmptyBody
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			req.Name = start.Name
+			break
+		}
+	}
+	req.Body = env.Body.Content
+	return req, nil
+}
+
+func getContentTypeParam(contentType, key string) string {
+	for _, param := range strings.Split(contentType, ";")[1:] {
+		pos := strings.IndexByte(param, '=')
+		if pos != -1 && strings.TrimSpace(param[:pos]) == key {
+			return strings.Trim(strings.TrimSpace(param[pos+1:]), `"`)
+		}
+	}
+	return ""
+}
+
+// writeFault 方法写入Fault响应，SOAP 1.1使用500状态码，SOAP 1.2的Sender错误使用400状态码。
+func (srv *Server) writeFault(ctx eudore.Context, version string, fault *Fault) {
+	buf := &bytes.Buffer{}
+	status := eudore.StatusInternalServerError
+	if version == "1.2" {
+		code := fault.Code
+		switch code {
+		case FaultClient:
+			code = "Sender"
+			status = eudore.StatusBadRequest
+		case FaultServer:
+			code = "Receiver"
+		}
+		buf.WriteString("<soap:Fault><soap:Code><soap:Value>soap:" + code + "</soap:Value></soap:Code>")
+		buf.WriteString(`<soap:Reason><soap:Text xml:lang="en">`)
+		xml.EscapeText(buf, []byte(fault.String))
+		buf.WriteString("</soap:Text></soap:Reason>")
+		if fault.Actor != "" {
+			buf.WriteString("<soap:Role>")
+			xml.EscapeText(buf, []byte(fault.Actor))
+			buf.WriteString("</soap:Role>")
+		}
+		writeFaultDetail(buf, "soap:Detail", fault.Detail)
+	} else {
+		buf.WriteString("<soap:Fault><faultcode>soap:" + fault.Code + "</faultcode><faultstring>")
+		xml.EscapeText(buf, []byte(fault.String))
+		buf.WriteString("</faultstring>")
+		if fault.Actor != "" {
+			buf.WriteString("<faultactor>")
+			xml.EscapeText(buf, []byte(fault.Actor))
+			buf.WriteString("</faultactor>")
+		}
+		writeFaultDetail(buf, "detail", fault.Detail)
+	}
+	buf.WriteString("</soap:Fault>")
+	srv.writeEnvelope(ctx, version, status, buf.Bytes())
+}
+
+func writeFaultDetail(buf *bytes.Buffer, name string, detail interface{}) {
+	if detail == nil {
+		return
+	}
+	body, err := xml.Marshal(detail)
+	if err != nil {
+		return
+	}
+	buf.WriteString("<" + name + ">")
+	buf.Write(body)
+	buf.WriteString("</" + name + ">")
+}
+
+func (srv *Server) writeEnvelope(ctx eudore.Context, version string, status int, body []byte) {
+	namespace, contentType := NamespaceSOAP11, eudore.MimeTextXMLCharsetUtf8
+	if version == "1.2" {
+		namespace, contentType = NamespaceSOAP12, MimeApplicationSOAPXMLCharsetUtf8
+	}
+	ctx.SetHeader(eudore.HeaderContentType, contentType)
+	ctx.WriteHeader(status)
+	ctx.WriteString(xml.Header + `<soap:Envelope xmlns:soap="` + namespace + `"><soap:Body>`)
+	ctx.Write(body)
+	ctx.WriteString("</soap:Body></soap:Envelope>")
+}
@@ -0,0 +1,187 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type (
+	// Storage 定义上传数据存储，Write方法从offset写入数据并保存新的偏移，返回写入的长度。
+	Storage interface {
+		Create(context.Context, *Info) error
+		Get(context.Context, string) (*Info, error)
+		Write(context.Context, string, int64, io.Reader) (int64, error)
+		Open(context.Context, string) (File, error)
+		Delete(context.Context, string) error
+	}
+	// File 定义读取上传数据的文件，用于Range下载。
+	File interface {
+		io.Reader
+		io.Seeker
+		io.Closer
+	}
+	// storageFile 使用目录保存上传数据和json格式的上传信息。
+	storageFile struct {
+		dir string
+	}
+	// storageMemory 使用内存保存上传数据。
+	storageMemory struct {
+		sync.RWMutex
+		infos map[string]*Info
+		datas map[string][]byte
+	}
+	memoryFile struct {
+		*bytes.Reader
+	}
+)
+
+// NewStorageFile 函数创建一个文件存储，上传数据保存为dir/id，上传信息保存为dir/id.info。
+func NewStorageFile(dir string) (Storage, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	return &storageFile{dir: dir}, nil
+}
+
+func (s *storageFile) Create(_ context.Context, info *Info) error {
+	file, err := os.OpenFile(filepath.Join(s.dir, info.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return s.save(info)
+}
+
+func (s *storageFile) Get(_ context.Context, id string) (*Info, error) {
+	body, err := ioutil.ReadFile(filepath.Join(s.dir, id+".info"))
+	if os.IsNotExist(err) {
+		return nil, ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	info := new(Info)
+	err = json.Unmarshal(body, info)
+	return info, err
+}
+
+// Write 方法从offset写入数据，写入中断时保存已经写入的偏移，用于客户端继续上传。
+func (s *storageFile) Write(ctx context.Context, id string, offset int64, r io.Reader) (int64, error) {
+	info, err := s.Get(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	file, err := os.OpenFile(filepath.Join(s.dir, id), os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(file, r)
+	if n > 0 {
+		info.Offset = offset + n
+		if serr := s.save(info); serr != nil && err == nil {
+			err = serr
+		}
+	}
+	return n, err
+}
+
+func (s *storageFile) Open(_ context.Context, id string) (File, error) {
+	return os.Open(filepath.Join(s.dir, id))
+}
+
+func (s *storageFile) Delete(_ context.Context, id string) error {
+	os.Remove(filepath.Join(s.dir, id+".info"))
+	err := os.Remove(filepath.Join(s.dir, id))
+	if os.IsNotExist(err) {
+		return ErrUploadNotFound
+	}
+	return err
+}
+
+func (s *storageFile) save(info *Info) error {
+	body, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(s.dir, info.ID+".info"), body, 0644)
+}
+
+// NewStorageMemory 函数创建一个内存存储，用于测试或小文件上传。
+func NewStorageMemory() Storage {
+	return &storageMemory{
+		infos: make(map[string]*Info),
+		datas: make(map[string][]byte),
+	}
+}
+
+func (s *storageMemory) Create(_ context.Context, info *Info) error {
+	s.Lock()
+	defer s.Unlock()
+	val := *info
+	s.infos[info.ID] = &val
+	s.datas[info.ID] = make([]byte, 0, info.Size)
+	return nil
+}
+
+func (s *storageMemory) Get(_ context.Context, id string) (*Info, error) {
+	s.RLock()
+	defer s.RUnlock()
+	info, ok := s.infos[id]
+	if !ok {
+		return nil, ErrUploadNotFound
+	}
+	val := *info
+	return &val, nil
+}
+
+func (s *storageMemory) Write(_ context.Context, id string, offset int64, r io.Reader) (int64, error) {
+	// 读取数据时不持有锁，避免慢客户端阻塞其他上传。
+	body, err := ioutil.ReadAll(r)
+	s.Lock()
+	defer s.Unlock()
+	info, ok := s.infos[id]
+	if !ok {
+		return 0, ErrUploadNotFound
+	}
+	s.datas[id] = append(s.datas[id][:offset], body...)
+	info.Offset = offset + int64(len(body))
+	return int64(len(body)), err
+}
+
+func (s *storageMemory) Open(_ context.Context, id string) (File, error) {
+	s.RLock()
+	defer s.RUnlock()
+	data, ok := s.datas[id]
+	if !ok {
+		return nil, ErrUploadNotFound
+	}
+	return memoryFile{bytes.NewReader(data)}, nil
+}
+
+func (s *storageMemory) Delete(_ context.Context, id string) error {
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.infos[id]; !ok {
+		return ErrUploadNotFound
+	}
+	delete(s.infos, id)
+	delete(s.datas, id)
+	return nil
+}
+
+func (memoryFile) Close() error {
+	return nil
+}
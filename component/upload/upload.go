@@ -0,0 +1,301 @@
+// Package upload 实现断点续传上传，兼容tus 1.0.0协议的core、creation、termination扩展，上传数据使用可替换的Storage保存。
+//
+// 客户端使用POST创建上传获得Location，使用HEAD查询已经上传的偏移，使用PATCH从偏移继续写入数据，
+// 上传完成后调用OnComplete，完成的上传可以使用GET下载，下载支持Range断点续传。
+package upload
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// TusVersion 定义支持的tus协议版本。
+const TusVersion = "1.0.0"
+
+type (
+	// Upload 定义断点续传上传处理者。
+	Upload struct {
+		Storage Storage
+		// MaxSize 定义单个上传的最大长度，0表示不限制。
+		MaxSize int64
+		// OnComplete 定义上传完成后的回调，返回错误时响应500。
+		OnComplete func(eudore.Context, *Info) error
+		locks      sync.Map
+	}
+	// Info 定义一个上传的信息。
+	Info struct {
+		ID       string            `json:"id"`
+		Size     int64             `json:"size"`
+		Offset   int64             `json:"offset"`
+		Metadata map[string]string `json:"metadata,omitempty"`
+		Time     time.Time         `json:"time"`
+	}
+)
+
+// 定义上传处理的错误。
+var (
+	ErrUploadNotFound = errors.New("upload not found")
+	ErrUploadLocked   = errors.New("upload is locked by another request")
+)
+
+// NewUpload 函数使用Storage创建一个上传处理者，router不为空时注入上传路由。
+func NewUpload(storage Storage, router eudore.Router) *Upload {
+	u := &Upload{Storage: storage}
+	if router != nil {
+		u.InjectRoutes(router)
+	}
+	return u
+}
+
+// InjectRoutes 方法将上传路由注入到路由器中，通常使用组路由，例如upload.InjectRoutes(app.Group("/files"))。
+func (u *Upload) InjectRoutes(router eudore.Router) {
+	router.AddHandler(eudore.MethodOptions, "/", u.HandleOptions)
+	router.PostFunc("/", u.HandleCreate)
+	router.HeadFunc("/:id", u.HandleHead)
+	router.PatchFunc("/:id", u.HandlePatch)
+	router.DeleteFunc("/:id", u.HandleDelete)
+	router.GetFunc("/:id", u.HandleGet)
+}
+
+// HandleOptions 方法返回服务端支持的协议版本、扩展和最大长度。
+func (u *Upload) HandleOptions(ctx eudore.Context) {
+	h := ctx.Response().Header()
+	h.Set("Tus-Resumable", TusVersion)
+	h.Set("Tus-Version", TusVersion)
+	h.Set("Tus-Extension", "creation,termination")
+	if u.MaxSize > 0 {
+		h.Set("Tus-Max-Size", strconv.FormatInt(u.MaxSize, 10))
+	}
+	ctx.WriteHeader(eudore.StatusNoContent)
+}
+
+// HandleCreate 方法使用Upload-Length和Upload-Metadata创建一个上传，返回201和上传地址。
+func (u *Upload) HandleCreate(ctx eudore.Context) {
+	if !u.checkResumable(ctx) {
+		return
+	}
+	size, err := strconv.ParseInt(ctx.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || size < 0 {
+		writeError(ctx, eudore.StatusBadRequest, "invalid Upload-Length header")
+		return
+	}
+	if u.MaxSize > 0 && size > u.MaxSize {
+		writeError(ctx, eudore.StatusRequestEntityTooLarge, "upload size exceeds Tus-Max-Size")
+		return
+	}
+
+	info := &Info{
+		ID:       newID(),
+		Size:     size,
+		Metadata: parseMetadata(ctx.GetHeader("Upload-Metadata")),
+		Time:     time.Now(),
+	}
+	err = u.Storage.Create(ctx.GetContext(), info)
+	if err != nil {
+		writeError(ctx, eudore.StatusInternalServerError, err.Error())
+		return
+	}
+	if size == 0 && !u.complete(ctx, info) {
+		return
+	}
+	ctx.SetHeader(eudore.HeaderLocation, strings.TrimSuffix(ctx.Path(), "/")+"/"+info.ID)
+	ctx.WriteHeader(eudore.StatusCreated)
+}
+
+// HandleHead 方法返回上传的偏移、长度和元数据。
+func (u *Upload) HandleHead(ctx eudore.Context) {
+	if !u.checkResumable(ctx) {
+		return
+	}
+	info, ok := u.getInfo(ctx)
+	if !ok {
+		return
+	}
+	h := ctx.Response().Header()
+	h.Set("Upload-Offset", strconv.FormatInt(info.Offset, 10))
+	h.Set("Upload-Length", strconv.FormatInt(info.Size, 10))
+	if len(info.Metadata) > 0 {
+		h.Set("Upload-Metadata", formatMetadata(info.Metadata))
+	}
+	h.Set(eudore.HeaderCacheControl, "no-store")
+	ctx.WriteHeader(eudore.StatusOK)
+}
+
+// HandlePatch 方法从Upload-Offset继续写入上传数据，偏移不一致时返回409，同一上传同时只允许一个请求写入。
+func (u *Upload) HandlePatch(ctx eudore.Context) {
+	if !u.checkResumable(ctx) {
+		return
+	}
+	if ctx.GetHeader(eudore.HeaderContentType) != "application/offset+octet-stream" {
+		writeError(ctx, eudore.StatusUnsupportedMediaType, "Content-Type must be application/offset+octet-stream")
+		return
+	}
+	id := ctx.GetParam("id")
+	if _, loaded := u.locks.LoadOrStore(id, struct{}{}); loaded {
+		writeError(ctx, eudore.StatusConflict, ErrUploadLocked.Error())
+		return
+	}
+	defer u.locks.Delete(id)
+
+	info, ok := u.getInfo(ctx)
+	if !ok {
+		return
+	}
+	offset, err := strconv.ParseInt(ctx.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset != info.Offset {
+		writeError(ctx, eudore.StatusConflict, "Upload-Offset does not match the current offset")
+		return
+	}
+
+	n, err := u.Storage.Write(ctx.GetContext(), id, offset, io.LimitReader(ctx.Request().Body, info.Size-offset))
+	info.Offset += n
+	if err != nil && n == 0 {
+		writeError(ctx, eudore.StatusInternalServerError, err.Error())
+		return
+	}
+	ctx.SetHeader("Upload-Offset", strconv.FormatInt(info.Offset, 10))
+	if info.Offset == info.Size && !u.complete(ctx, info) {
+		return
+	}
+	ctx.WriteHeader(eudore.StatusNoContent)
+}
+
+// HandleDelete 方法删除一个上传。
+func (u *Upload) HandleDelete(ctx eudore.Context) {
+	if !u.checkResumable(ctx) {
+		return
+	}
+	if _, ok := u.getInfo(ctx); !ok {
+		return
+	}
+	err := u.Storage.Delete(ctx.GetContext(), ctx.GetParam("id"))
+	if err != nil {
+		writeError(ctx, eudore.StatusInternalServerError, err.Error())
+		return
+	}
+	ctx.WriteHeader(eudore.StatusNoContent)
+}
+
+// HandleGet 方法下载已经完成的上传，支持Range断点续传，未完成的上传返回409。
+func (u *Upload) HandleGet(ctx eudore.Context) {
+	info, ok := u.getInfo(ctx)
+	if !ok {
+		return
+	}
+	if info.Offset != info.Size {
+		writeError(ctx, eudore.StatusConflict, "upload is not complete")
+		return
+	}
+	file, err := u.Storage.Open(ctx.GetContext(), info.ID)
+	if err != nil {
+		writeError(ctx, eudore.StatusInternalServerError, err.Error())
+		return
+	}
+	defer file.Close()
+	ctx.WriteContent(info.Metadata["filename"], info.Time, file)
+}
+
+// checkResumable 方法检查请求的Tus-Resumable版本，不支持时返回412。
+func (u *Upload) checkResumable(ctx eudore.Context) bool {
+	ctx.SetHeader("Tus-Resumable", TusVersion)
+	if ctx.GetHeader("Tus-Resumable") != TusVersion {
+		ctx.SetHeader("Tus-Version", TusVersion)
+		writeError(ctx, eudore.StatusPreconditionFailed, "unsupported Tus-Resumable version")
+		return false
+	}
+	return true
+}
+
+// getInfo 方法获取路由参数id对应的上传信息，不存在时返回404。
+func (u *Upload) getInfo(ctx eudore.Context) (*Info, bool) {
+	id := ctx.GetParam("id")
+	if !checkID(id) {
+		writeError(ctx, eudore.StatusNotFound, ErrUploadNotFound.Error())
+		return nil, false
+	}
+	info, err := u.Storage.Get(ctx.GetContext(), id)
+	if err != nil {
+		code := eudore.StatusInternalServerError
+		if err == ErrUploadNotFound {
+			code = eudore.StatusNotFound
+		}
+		writeError(ctx, code, err.Error())
+		return nil, false
+	}
+	return info, true
+}
+
+// complete 方法在上传完成后调用OnComplete，返回错误时响应500。
+func (u *Upload) complete(ctx eudore.Context, info *Info) bool {
+	if u.OnComplete == nil {
+		return true
+	}
+	err := u.OnComplete(ctx, info)
+	if err != nil {
+		writeError(ctx, eudore.StatusInternalServerError, "upload complete error: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeError(ctx eudore.Context, code int, msg string) {
+	ctx.WriteHeader(code)
+	ctx.Fatal(msg)
+	ctx.End()
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// checkID 函数检查上传id是否为小写十六进制字符串，避免Storage使用id时路径穿越。
+func checkID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, c := range id {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseMetadata 函数解析Upload-Metadata header，格式为逗号分隔的key和base64编码的value。
+func parseMetadata(str string) map[string]string {
+	if str == "" {
+		return nil
+	}
+	meta := make(map[string]string)
+	for _, pair := range strings.Split(str, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), " ", 2)
+		if kv[0] == "" {
+			continue
+		}
+		var val []byte
+		if len(kv) == 2 {
+			val, _ = base64.StdEncoding.DecodeString(kv[1])
+		}
+		meta[kv[0]] = string(val)
+	}
+	return meta
+}
+
+func formatMetadata(meta map[string]string) string {
+	pairs := make([]string, 0, len(meta))
+	for k, v := range meta {
+		pairs = append(pairs, k+" "+base64.StdEncoding.EncodeToString([]byte(v)))
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,392 @@
+// Package webhook 实现出站webhook投递，注册带密钥的接收端点，事件放入任务队列后异步投递到匹配的端点。
+//
+// 请求body为json格式的Event，使用X-Webhook-Signature header传递HMAC-SHA256签名，
+// 投递失败时使用指数退避和随机抖动重试，每次投递后调用OnDelivery持久化投递状态，并保存最近的投递记录用于后台查看。
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// 定义webhook请求使用的Header。
+const (
+	HeaderXWebhookID        = "X-Webhook-Id"
+	HeaderXWebhookEvent     = "X-Webhook-Event"
+	HeaderXWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderXWebhookSignature = "X-Webhook-Signature"
+)
+
+// 定义投递状态。
+const (
+	StatusPending  = "pending"
+	StatusSuccess  = "success"
+	StatusRetrying = "retrying"
+	StatusFailed   = "failed"
+)
+
+type (
+	// Manager 定义webhook管理者，保存端点并使用任务队列投递事件。
+	Manager struct {
+		sync.RWMutex
+		Queue  *eudore.TaskQueue
+		Client *http.Client
+		// OnDelivery 定义投递状态持久化钩子，创建投递和每次投递尝试后调用。
+		OnDelivery func(*Delivery)
+		// Recent 定义保存最近投递记录的数量。
+		Recent     int
+		endpoints  map[string]*Endpoint
+		deliveries []*Delivery
+	}
+	// Endpoint 定义webhook接收端点，Events为事件类型匹配模式，例如order.*，为空时接收全部事件。
+	Endpoint struct {
+		ID       string   `json:"id"`
+		URL      string   `json:"url"`
+		Secret   string   `json:"secret,omitempty"`
+		Events   []string `json:"events,omitempty"`
+		Disabled bool     `json:"disabled"`
+	}
+	// Event 定义一个webhook事件，作为请求body发送。
+	Event struct {
+		ID   string      `json:"id"`
+		Type string      `json:"type"`
+		Time time.Time   `json:"time"`
+		Data interface{} `json:"data"`
+	}
+	// Delivery 定义一个事件到一个端点的投递记录。
+	Delivery struct {
+		ID         string    `json:"id"`
+		EventID    string    `json:"eventid"`
+		EventType  string    `json:"eventtype"`
+		EndpointID string    `json:"endpointid"`
+		URL        string    `json:"url"`
+		Status     string    `json:"status"`
+		Attempts   int       `json:"attempts"`
+		StatusCode int       `json:"statuscode,omitempty"`
+		Error      string    `json:"error,omitempty"`
+		Duration   string    `json:"duration,omitempty"`
+		Time       time.Time `json:"time"`
+		body       []byte
+		secret     string
+	}
+)
+
+// 定义webhook错误。
+var (
+	ErrEndpointNotFound = errors.New("webhook endpoint not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+var webhookid uint64
+
+// NewManager 函数创建一个webhook管理者，任务队列在ctx结束时停止，默认最多重试5次，重试间隔从10秒开始加倍并增加随机抖动。
+func NewManager(ctx context.Context, log eudore.Logout) *Manager {
+	queue := eudore.NewTaskQueue(ctx, log)
+	queue.MaxRetry = 5
+	queue.Backoff = 10 * time.Second
+	queue.MaxBackoff = time.Hour
+	queue.Jitter = 0.2
+	m := &Manager{
+		Queue:     queue,
+		Client:    &http.Client{Timeout: 10 * time.Second},
+		Recent:    100,
+		endpoints: make(map[string]*Endpoint),
+	}
+	queue.DeadLetter = m.deadLetter
+	return m
+}
+
+// AddEndpoint 方法添加或更新一个端点，ID为空时生成ID。
+func (m *Manager) AddEndpoint(endpoint *Endpoint) {
+	if endpoint.ID == "" {
+		endpoint.ID = newID()
+	}
+	m.Lock()
+	m.endpoints[endpoint.ID] = endpoint
+	m.Unlock()
+}
+
+// DeleteEndpoint 方法删除一个端点，已经放入队列的投递不受影响。
+func (m *Manager) DeleteEndpoint(id string) error {
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.endpoints[id]; !ok {
+		return ErrEndpointNotFound
+	}
+	delete(m.endpoints, id)
+	return nil
+}
+
+// Endpoints 方法返回全部端点，返回的端点不包含Secret。
+func (m *Manager) Endpoints() []Endpoint {
+	m.RLock()
+	defer m.RUnlock()
+	endpoints := make([]Endpoint, 0, len(m.endpoints))
+	for _, endpoint := range m.endpoints {
+		val := *endpoint
+		val.Secret = ""
+		endpoints = append(endpoints, val)
+	}
+	return endpoints
+}
+
+// Emit 方法创建一个事件，并为每个匹配的端点创建投递放入队列，返回事件id。
+func (m *Manager) Emit(eventType string, data interface{}) (string, error) {
+	event := &Event{ID: newID(), Type: eventType, Time: time.Now(), Data: data}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+
+	m.RLock()
+	var deliveries []*Delivery
+	for _, endpoint := range m.endpoints {
+		if endpoint.Disabled || !matchEvent(endpoint.Events, eventType) {
+			continue
+		}
+		deliveries = append(deliveries, &Delivery{
+			ID:         newID(),
+			EventID:    event.ID,
+			EventType:  eventType,
+			EndpointID: endpoint.ID,
+			URL:        endpoint.URL,
+			Status:     StatusPending,
+			Time:       event.Time,
+			body:       body,
+			secret:     endpoint.Secret,
+		})
+	}
+	m.RUnlock()
+
+	var errs []error
+	for _, d := range deliveries {
+		if err := m.enqueue(d); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return event.ID, fmt.Errorf("webhook enqueue %d deliveries error: %v", len(errs), errs[0])
+	}
+	return event.ID, nil
+}
+
+// Redeliver 方法将最近的一个投递重新放入队列。
+func (m *Manager) Redeliver(id string) error {
+	m.RLock()
+	var delivery *Delivery
+	for _, d := range m.deliveries {
+		if d.ID == id {
+			delivery = d
+			break
+		}
+	}
+	m.RUnlock()
+	if delivery == nil {
+		return ErrDeliveryNotFound
+	}
+	d := &Delivery{
+		ID:         newID(),
+		EventID:    delivery.EventID,
+		EventType:  delivery.EventType,
+		EndpointID: delivery.EndpointID,
+		URL:        delivery.URL,
+		Status:     StatusPending,
+		Time:       time.Now(),
+		body:       delivery.body,
+		secret:     delivery.secret,
+	}
+	return m.enqueue(d)
+}
+
+// Deliveries 方法返回最近的投递记录，最新的在前。
+func (m *Manager) Deliveries() []Delivery {
+	m.RLock()
+	defer m.RUnlock()
+	deliveries := make([]Delivery, len(m.deliveries))
+	for i, d := range m.deliveries {
+		deliveries[len(deliveries)-1-i] = *d
+	}
+	return deliveries
+}
+
+// InjectRoutes 方法将webhook后台管理功能注入到路由器中。
+func (m *Manager) InjectRoutes(router eudore.Router) {
+	router.GetFunc("/webhook/endpoints", m.getEndpoints)
+	router.PostFunc("/webhook/endpoints", m.postEndpoint)
+	router.DeleteFunc("/webhook/endpoints/:id", m.deleteEndpoint)
+	router.GetFunc("/webhook/deliveries", m.getDeliveries)
+	router.PostFunc("/webhook/deliveries/:id/redeliver", m.redeliver)
+}
+
+func (m *Manager) getEndpoints(ctx eudore.Context) interface{} {
+	ctx.SetHeader("X-Eudore-Admin", "webhook")
+	return m.Endpoints()
+}
+
+func (m *Manager) postEndpoint(ctx eudore.Context) (interface{}, error) {
+	endpoint := new(Endpoint)
+	err := ctx.Bind(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	m.AddEndpoint(endpoint)
+	return map[string]string{"id": endpoint.ID}, nil
+}
+
+func (m *Manager) deleteEndpoint(ctx eudore.Context) error {
+	return m.DeleteEndpoint(ctx.GetParam("id"))
+}
+
+func (m *Manager) getDeliveries(ctx eudore.Context) interface{} {
+	ctx.SetHeader("X-Eudore-Admin", "webhook")
+	return m.Deliveries()
+}
+
+func (m *Manager) redeliver(ctx eudore.Context) error {
+	return m.Redeliver(ctx.GetParam("id"))
+}
+
+// enqueue 方法记录投递并放入任务队列。
+func (m *Manager) enqueue(d *Delivery) error {
+	m.record(d)
+	return m.Queue.Enqueue(&eudore.Task{
+		ID:      d.ID,
+		Name:    "webhook",
+		Payload: d,
+		Func:    m.deliver,
+	})
+}
+
+// deliver 方法发送一次投递请求，响应状态码不是2xx时返回错误由任务队列重试。
+func (m *Manager) deliver(ctx context.Context, task *eudore.Task) error {
+	d := task.Payload.(*Delivery)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequest(eudore.MethodPost, d.URL, bytes.NewReader(d.body))
+	if err != nil {
+		return m.finish(d, task, 0, err, 0)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set(eudore.HeaderContentType, eudore.MimeApplicationJSON)
+	req.Header.Set(HeaderXWebhookID, d.ID)
+	req.Header.Set(HeaderXWebhookEvent, d.EventType)
+	req.Header.Set(HeaderXWebhookTimestamp, timestamp)
+	if d.secret != "" {
+		req.Header.Set(HeaderXWebhookSignature, Sign(d.secret, timestamp, d.body))
+	}
+
+	start := time.Now()
+	resp, err := m.Client.Do(req)
+	if err != nil {
+		return m.finish(d, task, 0, err, time.Since(start))
+	}
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err = fmt.Errorf("webhook response status %d", resp.StatusCode)
+	}
+	return m.finish(d, task, resp.StatusCode, err, time.Since(start))
+}
+
+// finish 方法更新投递状态并调用OnDelivery。
+func (m *Manager) finish(d *Delivery, task *eudore.Task, code int, err error, duration time.Duration) error {
+	m.Lock()
+	d.Attempts = task.Attempts + 1
+	d.StatusCode = code
+	d.Duration = duration.String()
+	d.Status = StatusSuccess
+	d.Error = ""
+	if err != nil {
+		d.Status = StatusRetrying
+		d.Error = err.Error()
+	}
+	val := *d
+	m.Unlock()
+	if m.OnDelivery != nil {
+		m.OnDelivery(&val)
+	}
+	return err
+}
+
+// deadLetter 方法在超过最大重试次数后将投递标记为失败。
+func (m *Manager) deadLetter(task *eudore.Task) {
+	d, ok := task.Payload.(*Delivery)
+	if !ok {
+		return
+	}
+	m.Lock()
+	d.Status = StatusFailed
+	val := *d
+	m.Unlock()
+	if m.OnDelivery != nil {
+		m.OnDelivery(&val)
+	}
+}
+
+// record 方法保存投递到最近的投递记录，超过Recent时丢弃最早的记录。
+func (m *Manager) record(d *Delivery) {
+	m.Lock()
+	m.deliveries = append(m.deliveries, d)
+	if m.Recent > 0 && len(m.deliveries) > m.Recent {
+		m.deliveries = append(m.deliveries[:0], m.deliveries[len(m.deliveries)-m.Recent:]...)
+	}
+	val := *d
+	m.Unlock()
+	if m.OnDelivery != nil {
+		m.OnDelivery(&val)
+	}
+}
+
+// Sign 函数计算webhook签名，签名原文为时间戳、'.'和body，返回sha256=前缀的hex编码HMAC-SHA256值。
+func Sign(secret, timestamp string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(body)
+	return "sha256=" + hex.EncodeToString(h.Sum(nil))
+}
+
+// Verify 函数用于接收端验证webhook请求的签名和时间戳，tolerance为允许的时间偏差。
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) bool {
+	timestamp := header.Get(HeaderXWebhookTimestamp)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if diff := time.Since(time.Unix(unix, 0)); diff > tolerance || diff < -tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(header.Get(HeaderXWebhookSignature)), []byte(Sign(secret, timestamp, body)))
+}
+
+// matchEvent 函数使用path.Match匹配事件类型，patterns为空时匹配全部事件。
+func matchEvent(patterns []string, eventType string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, eventType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func newID() string {
+	return fmt.Sprintf("%x-%x", time.Now().UnixNano(), atomic.AddUint64(&webhookid, 1))
+}
@@ -3,6 +3,7 @@ package eudore
 // const定义全部全局变量和常量
 
 import (
+	"encoding/json"
 	"errors"
 	"reflect"
 	"time"
@@ -15,6 +16,10 @@ type contextKey struct {
 var (
 	// AppContextKey 定义从context.Value中获取app实例对象的key，如果app支持的话。
 	AppContextKey = &contextKey{"app"}
+	// ContextContextKey 定义http.Handler从请求context.Value中获取eudore.Context的key。
+	ContextContextKey = &contextKey{"context"}
+	// MountContextKey 定义子App从请求context.Value中获取挂载路径前缀的key。
+	MountContextKey = &contextKey{"mount"}
 	// DefaultBodyMaxMemory 默认Body解析占用内存。
 	DefaultBodyMaxMemory int64 = 32 << 20 // 32 MB
 	// DefaultConvertTags 定义默认转换使用的结构体tags。
@@ -23,12 +28,28 @@ var (
 	DefaultConvertFormTags = []string{"form", "alias"}
 	// DefaultConvertURLTags 定义bind url使用tags。
 	DefaultConvertURLTags = []string{"url", "alias"}
+	// DefaultConvertEventTags 定义Event获取和转换payload使用的tags。
+	DefaultConvertEventTags = []string{"json", "alias"}
 	// DefaultRecoverDepth 定义GetPanicStack函数默认显示栈最大层数。
 	DefaultRecoverDepth = 20
+	// DefaultLoggerFingerprintDepth 定义GetErrorFingerprint函数使用的栈顶层数。
+	DefaultLoggerFingerprintDepth = 5
+	// DefaultLoggerWriterRetry 定义日志写入文件失败后重新打开文件的间隔。
+	DefaultLoggerWriterRetry = 10 * time.Second
+	// DefaultContextFatalFunc 定义Context.Fatal错误响应的body，errs为请求中Error和Fatal方法记录的全部错误消息，返回nil时不渲染。
+	DefaultContextFatalFunc func(Context, []string) interface{} = contextFatalBody
+	// DefaultContextDownloadRate 定义Context.Attachment和SendFile每个请求默认的下载速度限制，单位字节每秒，0为不限制，可以使用路由参数download-rate设置。
+	DefaultContextDownloadRate int64
+	// DefaultRenderFilterMask 定义NewRenderFilter无权限字符串字段使用的掩码值。
+	DefaultRenderFilterMask = "***"
 	// LogLevelString 定义日志级别输出字符串。
 	LogLevelString = [5]string{"DEBUG", "INFO", "WARNING", "ERROR", "FATAL"}
 	// RouterAllMethod 定义全部的方法，是Any方法的注册使用的方法。
 	RouterAllMethod = []string{MethodGet, MethodPost, MethodPut, MethodDelete, MethodHead, MethodPatch}
+	// RouterAnyMethod 定义是否允许任意请求方法通过路由匹配，开启后未注册的方法使用Any方法注册的路由匹配，默认返回405。
+	RouterAnyMethod = false
+	// RouterCustomMethods 定义允许注册的自定义方法，例如PURGE、PROPFIND，未定义的方法注册时返回错误。
+	RouterCustomMethods = []string{}
 	// ConfigAllParseFunc 定义ConfigMap和ConfigEudore默认使用的解析函数。
 	ConfigAllParseFunc = []ConfigParseFunc{ConfigParseJSON, ConfigParseArgs, ConfigParseEnvs, ConfigParseMods, ConfigParseWorkdir, ConfigParseHelp}
 	// DefaultHandlerExtend 为默认的函数扩展处理者，是RouterStd使用的最顶级的函数扩展处理者。
@@ -51,6 +72,7 @@ var (
 	typeHandlerFunc       = reflect.TypeOf((*HandlerFunc)(nil)).Elem()
 	typeValidateInterface = reflect.TypeOf((*validateInterface)(nil)).Elem()
 	typeTimeTime          = reflect.TypeOf((*time.Time)(nil)).Elem()
+	typeJSONMarshaler     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
 )
 
 // 检测各类接口
@@ -98,11 +120,27 @@ var (
 	ErrRegisterNewHandlerParamNotFunc = errors.New("The parameter type of RegisterNewHandler must be a function")
 	// ErrResponseWriterHTTPNotHijacker ResponseWriterHTTP对象没有实现http.Hijacker接口。
 	ErrResponseWriterHTTPNotHijacker = errors.New("http.Hijacker interface is not supported")
+	// ErrResponseWriterInformationalCode ResponseWriter写入1xx响应时状态码不是有效的1xx状态码。
+	ErrResponseWriterInformationalCode = errors.New("informational response status code must be 1xx and not 101")
+	// ErrResponseWriterHeaderWritten ResponseWriter已经写入响应状态码后写入1xx响应。
+	ErrResponseWriterHeaderWritten = errors.New("response header is already written")
+	// ErrResponseWriterInformationalNotSupport ResponseWriter未实现ResponseInformational接口，不支持写入1xx响应。
+	ErrResponseWriterInformationalNotSupport = errors.New("response writer not support informational response")
 	// ErrSeterNotSupportField Seter对象不支持设置当前属性。
 	ErrSeterNotSupportField = errors.New("Converter seter not support set field")
 
 	// ErrFormatBindDefaultNotSupportContentType BindDefault函数不支持当前的Content-Type Header。
 	ErrFormatBindDefaultNotSupportContentType = "BindDefault not support content type header: %s"
+	// ErrFormatBindFieldConvert Bind请求值无法转换成字段类型。
+	ErrFormatBindFieldConvert = "bind field '%s' value '%s' cannot convert to type %s"
+	// ErrFormatRenderJSONPCallback RenderJSONP回调函数名称不是合法的标识符。
+	ErrFormatRenderJSONPCallback = "render jsonp callback '%s' is invalid"
+	// ErrFormatBindXMLCharset BindXML不支持xml声明的编码。
+	ErrFormatBindXMLCharset = "bind xml not support charset: %s"
+	// ErrFormatRenderXMLName RenderXML的map key不是合法的xml名称。
+	ErrFormatRenderXMLName = "render xml map key '%s' is not a valid xml name"
+	// ErrFormatRenderTableType RenderCSV和RenderXLSX的数据不是切片、数组或chan。
+	ErrFormatRenderTableType = "render table data must be slice, array or chan, not %s"
 	// ErrFormatControllerBind 执行控制器方法bind时返回错误
 	ErrFormatControllerBind = "Controller bind error: %v"
 	// ErrFormatConverterGetWithTags 在Get方法时，无法或到值，返回错误描述。
@@ -121,6 +159,8 @@ var (
 	ErrFormatConverterSetTypeError = "The type of the set value is %s, which is not configurable, key: %v, val: %s"
 	// ErrFormatConverterSetWithValue setWithValue函数中类型无法赋值。
 	ErrFormatConverterSetWithValue = "The setWithValue method type %s cannot be assigned to type %s"
+	// ErrFormatLoggerRotatePeriod 日志切割周期无效。
+	ErrFormatLoggerRotatePeriod = "logger rotate period '%s' is invalid, must be hourly, daily or weekly"
 	// ErrFormatRegisterHandlerExtendInputParamError RegisterHandlerExtend函数注册的函数参数错误。
 	ErrFormatRegisterHandlerExtendInputParamError = "The '%s' input parameter is illegal and should be one"
 	// ErrFormatRegisterHandlerExtendOutputParamError RegisterHandlerExtend函数注册的函数返回值错误。
@@ -135,6 +175,14 @@ var (
 	ErrFormatRouterStdRegisterHandlersRecover = "The RouterStd.registerHandlers arg method is '%s' and path is '%s', recover error: %v"
 	// ErrFormatRouterStdNewHandlerFuncsUnregisterType RouterStd添加处理对象或中间件的第n个参数类型未注册，需要先使用RegisterHandlerExtend或AddHandlerExtend注册该函数类型。
 	ErrFormatRouterStdNewHandlerFuncsUnregisterType = "The RouterStd.newHandlerFuncs path is '%s', %dth handler parameter type is '%s', this is the unregistered handler type"
+	// ErrFormatRouterStdNewHandlerFuncsNameError RouterStd添加的第n个字符串处理函数创建失败，需要先使用RegisterHandlerName注册名称。
+	ErrFormatRouterStdNewHandlerFuncsNameError = "The RouterStd.newHandlerFuncs path is '%s', %dth handler parameter create error: %v"
+	// ErrFormatAppAudit App启动审计级别为fatal时存在审计问题。
+	ErrFormatAppAudit = "app startup audit found %d problems"
+	// ErrFormatAppInit App加载的组件初始化失败。
+	ErrFormatAppInit = "app init component error: %v"
+	// ErrFormatLoggerStdInit NewLoggerStd2初始化输出流失败。
+	ErrFormatLoggerStdInit = "NewLoggerStd2 init writer error: %v"
 )
 
 // 定义eudore定义各种常量。
@@ -147,12 +195,30 @@ const (
 	EnvEudoreIsNotify = "EUDORE_IS_NOTIFY"
 	// EnvEudoreDisablePidfile 用于Command组件不写入pidfile，Notify组件启动的子程序不写入pidfile。
 	EnvEudoreDisablePidfile = "EUDORE_DISABLE_PIDFILE"
+	// EnvEudoreMode 用于配置mode未设置时指定app运行模式。
+	EnvEudoreMode = "EUDORE_MODE"
+	// EnvEudoreAudit 用于配置audit未设置时指定启动审计级别，值为warning或fatal，CI中设置为fatal使审计问题导致启动失败。
+	EnvEudoreAudit = "EUDORE_AUDIT"
+
+	// App mode
+
+	ModeDevelopment = "development"
+	ModeTest        = "test"
+	ModeProduction  = "production"
+
+	// Router audit kind
+
+	RouterAuditDuplicateMiddleware = "duplicate-middleware"
+	RouterAuditDuplicateRoute      = "duplicate-route"
+	RouterAuditShadowedRoute       = "shadowed-route"
+	RouterAuditUnreachableRoute    = "unreachable-route"
 
 	// Response statue
 
 	StatusContinue           = 100 // RFC 7231, 6.2.1
 	StatusSwitchingProtocols = 101 // RFC 7231, 6.2.2
 	StatusProcessing         = 102 // RFC 2518, 10.1
+	StatusEarlyHints         = 103 // RFC 8297
 
 	StatusOK                   = 200 // RFC 7231, 6.3.1
 	StatusCreated              = 201 // RFC 7231, 6.3.2
@@ -259,6 +325,7 @@ const (
 	HeaderForwarded                       = "Forwarded"
 	HeaderFrom                            = "From"
 	HeaderHost                            = "Host"
+	HeaderIdempotencyKey                  = "Idempotency-Key"
 	HeaderIfMatch                         = "If-Match"
 	HeaderIfModifiedSince                 = "If-Modified-Since"
 	HeaderIfNoneMatch                     = "If-None-Match"
@@ -267,6 +334,7 @@ const (
 	HeaderIndex                           = "Index"
 	HeaderKeepAlive                       = "Keep-Alive"
 	HeaderLastModified                    = "Last-Modified"
+	HeaderLink                            = "Link"
 	HeaderLocation                        = "Location"
 	HeaderOrigin                          = "Origin"
 	HeaderPragma                          = "Pragma"
@@ -333,6 +401,8 @@ const (
 	MimeTextJavascriptUtf8         = MimeTextJavascript + "; " + MimeCharsetUtf8
 	MimeTextMarkdown               = "text/markdown"
 	MimeTextMarkdownUtf8           = MimeTextMarkdown + "; " + MimeCharsetUtf8
+	MimeTextCSV                    = "text/csv"
+	MimeTextCSVUtf8                = MimeTextCSV + "; " + MimeCharsetUtf8
 	MimeTextXML                    = "text/xml"
 	MimeTextXMLCharsetUtf8         = MimeTextXML + "; " + MimeCharsetUtf8
 	MimeApplicationJSON            = "application/json"
@@ -342,6 +412,8 @@ const (
 	MimeApplicationForm            = "application/x-www-form-urlencoded"
 	MimeApplicationFormCharsetUtf8 = MimeApplicationForm + "; " + MimeCharsetUtf8
 	MimeMultipartForm              = "multipart/form-data"
+	MimeApplicationOctetStream     = "application/octet-stream"
+	MimeApplicationXLSX            = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
 
 	// Param
 
@@ -355,4 +427,7 @@ const (
 	ParamDeny            = "deny"
 	ParamUID             = "UID"
 	ParamUNAME           = "UNAME"
+	ParamRole            = "ROLE"
+	ParamRender          = "render"
+	ParamDownloadRate    = "download-rate"
 )
@@ -5,6 +5,7 @@ package eudore
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -13,9 +14,20 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
 )
 
+// contextReleaser 定义请求结束时执行清理函数的接口。
+type contextReleaser interface {
+	release()
+}
+
 // Context 定义请求上下文接口。
 type Context interface {
 	// context
@@ -24,6 +36,8 @@ type Context interface {
 	Request() *http.Request
 	Response() ResponseWriter
 	Logger() Logout
+	Cache() Cache
+	Feature(string) bool
 	WithContext(context.Context)
 	SetRequest(*http.Request)
 	SetResponse(ResponseWriter)
@@ -33,6 +47,9 @@ type Context interface {
 	Next()
 	End()
 	Err() error
+	Defer(func())
+	SetValue(interface{}, interface{})
+	GetValue(interface{}) interface{}
 
 	// request info
 	Read([]byte) (int, error)
@@ -44,6 +61,7 @@ type Context interface {
 	Referer() string
 	ContentType() string
 	Istls() bool
+	TLS() *tls.ConnectionState
 	Body() []byte
 	Bind(interface{}) error
 	BindWith(interface{}, Binder) error
@@ -58,6 +76,7 @@ type Context interface {
 	GetQuery(string) string
 	GetHeader(string) string
 	SetHeader(string, string)
+	SetTrailer(string, string)
 	Cookies() []Cookie
 	GetCookie(string) string
 	SetCookie(cookie *SetCookie)
@@ -72,11 +91,16 @@ type Context interface {
 	WriteHeader(int)
 	Redirect(int, string)
 	Push(string, *http.PushOptions) error
+	WriteEarlyHints(...string) error
+	AcceptContinue() error
 	Render(interface{}) error
 	RenderWith(interface{}, Renderer) error
 	WriteString(string) error
 	WriteJSON(interface{}) error
 	WriteFile(string) error
+	WriteContent(string, time.Time, io.ReadSeeker) error
+	Attachment(string, io.Reader) error
+	SendFile(string, string) error
 
 	// log Logout interface
 	Debug(...interface{})
@@ -103,10 +127,15 @@ type contextBase struct {
 	index          int
 	handler        HandlerFuncs
 	err            string
+	fatalErr       error
+	errs           []string
+	fatal          bool
 	querys         url.Values
 	cookies        []Cookie
 	isReadBody     bool
 	postBody       []byte
+	defers         []func()
+	values         map[interface{}]interface{}
 	// component
 	app *App
 	log Logout
@@ -123,7 +152,22 @@ type entryContextBase struct {
 //
 // ContextBase相关方法文档点击NewContextBase函数跳转到源码查看。
 func NewContextBase(app *App) Context {
-	return &contextBase{app: app}
+	ctx := &contextBase{app: app}
+	ctx.httpResponse.misuse = ctx.reportResponseMisuse
+	return ctx
+}
+
+// reportResponseMisuse 方法输出已经写入状态码或劫持连接后写入响应的Warning日志，开发模式下包含调用栈。
+func (ctx *contextBase) reportResponseMisuse(method string) {
+	log := ctx.log.WithField(ParamCaller, method)
+	if ctx.app.IsMode(ModeDevelopment) {
+		log = log.WithField("stack", GetPanicStack(5))
+	}
+	if ctx.httpResponse.hijacked {
+		log.Warning("eudore response is written after the connection is hijacked: " + method)
+	} else {
+		log.Warningf("eudore response header is already written with status %d, ignore %s", ctx.httpResponse.code, method)
+	}
 }
 
 // Reset Context
@@ -134,6 +178,9 @@ func (ctx *contextBase) Reset(pctx context.Context, w http.ResponseWriter, r *ht
 	ctx.ResponseWriter = &ctx.httpResponse
 	ctx.log = ctx.app.Logger
 	ctx.err = ""
+	ctx.fatalErr = nil
+	ctx.errs = ctx.errs[0:0]
+	ctx.fatal = false
 	// data
 	ctx.querys = nil
 	ctx.httpParams.Keys = ctx.httpParams.Keys[0:0]
@@ -179,6 +226,20 @@ func (ctx *contextBase) SetLogger(log Logout) {
 	ctx.log = log.WithFields(nil)
 }
 
+// Cache 方法返回app.Cache。
+func (ctx *contextBase) Cache() Cache {
+	return ctx.app.Cache
+}
+
+// Feature 方法检查app.Features中功能是否开启，灰度开启时使用UID参数作为key，没有UID时使用RequestID。
+func (ctx *contextBase) Feature(name string) bool {
+	key := ctx.GetParam(ParamUID)
+	if key == "" {
+		key = ctx.RequestID()
+	}
+	return ctx.app.Features.Enabled(name, key)
+}
+
 // WithContext 设置当前请求上下文的ctx，必须是请求上下文的衍生上下文。
 //
 // ctx.WithContext(context.WithValue("key", ctx.Context()))
@@ -203,6 +264,9 @@ func (ctx *contextBase) Next() {
 		ctx.handler[ctx.index](ctx)
 		ctx.index++
 	}
+	if ctx.fatal {
+		ctx.renderFatal()
+	}
 }
 
 // End 结束请求上下文的处理。
@@ -210,8 +274,54 @@ func (ctx *contextBase) End() {
 	ctx.index = 0xff
 }
 
-// Err 方法返回
+// Defer 方法注册一个请求结束后执行的清理函数，在全部处理函数执行完成后按注册的相反顺序执行，处理函数panic时也会执行。
+//
+// 用于删除临时文件、完成指标记录和释放请求占用的资源，清理函数panic会被捕捉并输出Error日志。
+func (ctx *contextBase) Defer(fn func()) {
+	if fn != nil {
+		ctx.defers = append(ctx.defers, fn)
+	}
+}
+
+// SetValue 方法设置一个请求范围的值，请求结束后清空，与WithContext相比不会创建新的context.Context。
+func (ctx *contextBase) SetValue(key, val interface{}) {
+	if ctx.values == nil {
+		ctx.values = make(map[interface{}]interface{})
+	}
+	ctx.values[key] = val
+}
+
+// GetValue 方法获取一个请求范围的值。
+func (ctx *contextBase) GetValue(key interface{}) interface{} {
+	return ctx.values[key]
+}
+
+// release 方法执行请求结束的清理函数并清空请求范围的值。
+func (ctx *contextBase) release() {
+	for i := len(ctx.defers) - 1; i >= 0; i-- {
+		ctx.runDefer(ctx.defers[i])
+		ctx.defers[i] = nil
+	}
+	ctx.defers = ctx.defers[0:0]
+	for key := range ctx.values {
+		delete(ctx.values, key)
+	}
+}
+
+func (ctx *contextBase) runDefer(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			ctx.log.WithField("stack", GetPanicStack(4)).Errorf("eudore context defer panic: %v", r)
+		}
+	}()
+	fn()
+}
+
+// Err 方法返回第一次Fatal的错误，Fatal参数为一个error时返回该error，否则返回请求context.Context的错误。
 func (ctx *contextBase) Err() error {
+	if ctx.fatalErr != nil {
+		return ctx.fatalErr
+	}
 	if ctx.err != "" {
 		return errors.New(ctx.err)
 	}
@@ -262,11 +372,16 @@ func (ctx *contextBase) ContentType() string {
 	return ctx.GetHeader(HeaderContentType)
 }
 
-// Istls 判断是否使用了tls，tls状态使用ctx.Request().TLS()获取。
+// Istls 判断是否使用了tls，tls状态使用ctx.TLS()获取。
 func (ctx *contextBase) Istls() bool {
 	return ctx.RequestReader.TLS != nil
 }
 
+// TLS 方法返回请求tls连接状态，包含协商的版本、加密套件、SNI、ALPN和对端证书，非tls请求返回nil。
+func (ctx *contextBase) TLS() *tls.ConnectionState {
+	return ctx.RequestReader.TLS
+}
+
 // Body 返回请求的body，并保存到缓存中，可重复调用Body方法,每次调用会重置ctx.Request().Body对象成一个body reader。
 func (ctx *contextBase) Body() []byte {
 	if !ctx.isReadBody {
@@ -365,6 +480,11 @@ func (ctx *contextBase) SetHeader(name string, val string) {
 	ctx.ResponseWriter.Header().Set(name, val)
 }
 
+// SetTrailer 方法设置一个响应trailer，可以在写入body后调用，响应使用Content-Length时trailer无法写入。
+func (ctx *contextBase) SetTrailer(name string, val string) {
+	ctx.ResponseWriter.Header().Set(http.TrailerPrefix+name, val)
+}
+
 // Cookies 方法获取全部请求的cookie,获取的cookie值是首次调用Cookies/GetCookie方法后解析的数据。。
 func (ctx *contextBase) Cookies() []Cookie {
 	ctx.readCookies(ctx.RequestReader.Header.Get(HeaderCookie))
@@ -491,6 +611,41 @@ func (ctx *contextBase) Push(target string, opts *http.PushOptions) error {
 	return err
 }
 
+// WriteEarlyHints 方法写入103 Early Hints响应，使用Link header提示客户端预加载资源，需要在写入响应状态码前调用。
+//
+// ctx.WriteEarlyHints("</css/app.css>; rel=preload; as=style")
+func (ctx *contextBase) WriteEarlyHints(links ...string) error {
+	err := ctx.writeInformational(StatusEarlyHints, http.Header{HeaderLink: links})
+	if err != nil {
+		ctx.log.WithField("depth", 1).WithField(ParamCaller, "Context.WriteEarlyHints").Error(err)
+	}
+	return err
+}
+
+// AcceptContinue 方法在请求Expect: 100-continue时立即写入100 Continue，允许客户端发送body，之后删除请求Expect header。
+//
+// 默认在第一次读取body时由net/http写入100 Continue，拒绝请求时不读取body直接写入错误状态码，客户端不会发送body。
+func (ctx *contextBase) AcceptContinue() error {
+	if !strings.EqualFold(ctx.RequestReader.Header.Get(HeaderExpect), "100-continue") {
+		return nil
+	}
+	err := ctx.writeInformational(StatusContinue, nil)
+	if err != nil {
+		ctx.log.WithField("depth", 1).WithField(ParamCaller, "Context.AcceptContinue").Error(err)
+		return err
+	}
+	ctx.RequestReader.Header.Del(HeaderExpect)
+	return nil
+}
+
+// writeInformational 方法在ResponseWriter实现ResponseInformational接口时写入1xx响应。
+func (ctx *contextBase) writeInformational(code int, header http.Header) error {
+	if w, ok := ctx.ResponseWriter.(ResponseInformational); ok {
+		return w.WriteInformational(code, header)
+	}
+	return ErrResponseWriterInformationalNotSupport
+}
+
 // Write 实现io.Writer，向响应写入数据。
 func (ctx *contextBase) Write(data []byte) (n int, err error) {
 	n, err = ctx.ResponseWriter.Write(data)
@@ -500,9 +655,15 @@ func (ctx *contextBase) Write(data []byte) (n int, err error) {
 	return
 }
 
-// WriteString 实现向响应写入一个字符串。
+// WriteString 实现向响应写入一个字符串，ResponseWriter未被中间件包装时不分配内存。
+//
+// 中间件包装的ResponseWriter可能通过嵌入bytes.Buffer等对象获得WriteString方法，只写入嵌入对象，仍然使用Write方法写入。
 func (ctx *contextBase) WriteString(i string) (err error) {
-	_, err = ctx.ResponseWriter.Write([]byte(i))
+	if w, ok := ctx.ResponseWriter.(*responseWriterHTTP); ok {
+		_, err = w.WriteString(i)
+	} else {
+		_, err = ctx.ResponseWriter.Write([]byte(i))
+	}
 	if err != nil {
 		ctx.log.WithField("depth", 1).WithField(ParamCaller, "Context.WriteString").Error(err)
 	}
@@ -520,6 +681,152 @@ func (ctx *contextBase) WriteFile(path string) (err error) {
 	return nil
 }
 
+// WriteContent 方法使用io.ReadSeeker返回内容，支持Range、If-Range断点续传和If-Modified-Since等条件请求。
+//
+// name用于在未设置Content-Type时根据扩展名推断类型，modtime为零值时不处理Last-Modified。
+func (ctx *contextBase) WriteContent(name string, modtime time.Time, content io.ReadSeeker) error {
+	http.ServeContent(ctx.ResponseWriter, ctx.RequestReader, name, modtime, content)
+	return nil
+}
+
+// Attachment 方法使用name作为下载文件名称返回内容，设置Content-Disposition和根据扩展名推断的Content-Type。
+//
+// content实现io.ReadSeeker时使用WriteContent支持Range断点续传，下载速度使用路由参数download-rate或DefaultContextDownloadRate限制。
+func (ctx *contextBase) Attachment(name string, content io.Reader) error {
+	return ctx.writeAttachment(name, time.Time{}, content)
+}
+
+func (ctx *contextBase) writeAttachment(name string, modtime time.Time, content io.Reader) error {
+	h := ctx.ResponseWriter.Header()
+	h.Set(HeaderContentDisposition, getContentDisposition("attachment", name))
+	if h.Get(HeaderContentType) == "" {
+		contentType := mime.TypeByExtension(filepath.Ext(name))
+		if contentType == "" {
+			contentType = MimeApplicationOctetStream
+		}
+		h.Set(HeaderContentType, contentType)
+	}
+
+	rate := DefaultContextDownloadRate
+	if val := ctx.GetParam(ParamDownloadRate); val != "" {
+		rate, _ = strconv.ParseInt(val, 10, 64)
+	}
+	seeker, ok := content.(io.ReadSeeker)
+	if rate > 0 {
+		reader := &rateReader{Reader: content, Context: ctx.GetContext(), Rate: rate, Start: time.Now()}
+		content = reader
+		if ok {
+			seeker = rateReadSeeker{reader, seeker}
+		}
+	}
+	if ok {
+		return ctx.WriteContent(name, modtime, seeker)
+	}
+	_, err := io.Copy(ctx.ResponseWriter, content)
+	return err
+}
+
+// SendFile 方法使用Attachment返回文件，支持If-Modified-Since条件请求，name为空时使用文件名称，文件不存在时设置状态码404，无权限时设置状态码403。
+func (ctx *contextBase) SendFile(path, name string) error {
+	file, err := os.Open(path)
+	if err == nil {
+		defer file.Close()
+		var stat os.FileInfo
+		stat, err = file.Stat()
+		if err == nil && stat.IsDir() {
+			err = &os.PathError{Op: "open", Path: path, Err: syscall.EISDIR}
+		}
+		if err == nil {
+			if name == "" {
+				name = stat.Name()
+			}
+			return ctx.writeAttachment(name, stat.ModTime(), file)
+		}
+	}
+	switch {
+	case os.IsNotExist(err):
+		ctx.WriteHeader(StatusNotFound)
+	case os.IsPermission(err):
+		ctx.WriteHeader(StatusForbidden)
+	}
+	return err
+}
+
+// getContentDisposition 函数返回Content-Disposition值，非ascii名称使用RFC 5987编码的filename*，并保留ascii的filename兼容旧客户端。
+func getContentDisposition(typ, name string) string {
+	fallback := make([]byte, 0, len(name))
+	var encoded bool
+	for _, c := range name {
+		if c >= utf8.RuneSelf || c < ' ' || c == 0x7f || c == '"' || c == '\\' {
+			encoded = true
+			c = '_'
+		}
+		fallback = append(fallback, byte(c))
+	}
+	value := typ + `; filename="` + string(fallback) + `"`
+	if encoded {
+		value += "; filename*=UTF-8''" + encodeRFC5987(name)
+	}
+	return value
+}
+
+// encodeRFC5987 函数使用百分号编码attr-char以外的字节。
+func encodeRFC5987(s string) string {
+	const hex = "0123456789ABCDEF"
+	buf := make([]byte, 0, len(s)*3)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || strings.IndexByte("!#$&+-.^_`|~", c) != -1 {
+			buf = append(buf, c)
+		} else {
+			buf = append(buf, '%', hex[c>>4], hex[c&0xf])
+		}
+	}
+	return string(buf)
+}
+
+// rateReader 定义限制读取速度的Reader，按已读取字节数计算期望耗时，读取过快时等待。
+type rateReader struct {
+	io.Reader
+	Context context.Context
+	Rate    int64
+	Start   time.Time
+	Size    int64
+}
+
+func (r *rateReader) Read(p []byte) (int, error) {
+	// 每次最多读取100ms的数据，使等待间隔平滑
+	if max := r.Rate / 10; max > 0 && int64(len(p)) > max {
+		p = p[:max]
+	}
+	n, err := r.Reader.Read(p)
+	r.Size += int64(n)
+	wait := time.Duration(r.Size*int64(time.Second)/r.Rate) - time.Since(r.Start)
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-r.Context.Done():
+			timer.Stop()
+			return n, r.Context.Err()
+		}
+	}
+	return n, err
+}
+
+// rateReadSeeker 定义可以Seek的rateReader，用于WriteContent处理Range请求。
+type rateReadSeeker struct {
+	*rateReader
+	seeker io.Seeker
+}
+
+// Seek 方法实现io.Seeker，Seek后重新开始计算速度。
+func (r rateReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	r.Start = time.Now()
+	r.Size = 0
+	return r.seeker.Seek(offset, whence)
+}
+
 // Render 使用app.Renderer返回数据。
 func (ctx *contextBase) Render(i interface{}) error {
 	return ctx.writeRenderWith(i, ctx.app.Renderer)
@@ -560,19 +867,23 @@ func (ctx *contextBase) Error(args ...interface{}) {
 		return
 	}
 	ctx.log.WithField("depth", 1).Error(args...)
+	msg := fmt.Sprintln(args...)
+	ctx.errs = append(ctx.errs, msg[:len(msg)-1])
 }
 
 // Fatal 方法写入Fatal日志，并结束请求上下文处理。
 //
+// 请求中多次调用Fatal和Error的错误消息会被收集，处理函数返回后使用DefaultContextFatalFunc合并渲染一次错误响应。
+//
 // 注意：如果err中存在敏感信息会被写入到响应中。
 func (ctx *contextBase) Fatal(args ...interface{}) {
 	if len(args) == 1 && args[0] == nil {
 		return
 	}
 	msg := fmt.Sprintln(args...)
-	ctx.err = msg[:len(msg)-1]
-	ctx.log.WithField("depth", 1).Error(ctx.err)
-	ctx.logFatal()
+	msg = msg[:len(msg)-1]
+	ctx.log.WithField("depth", 1).Error(msg)
+	ctx.logFatal(msg, args)
 }
 
 // Debugf 方法输出Info日志。
@@ -592,36 +903,72 @@ func (ctx *contextBase) Warningf(format string, args ...interface{}) {
 
 // Errorf 方法输出Error日志。
 func (ctx *contextBase) Errorf(format string, args ...interface{}) {
-	ctx.log.WithField("depth", 1).Error(fmt.Sprintf(format, args...))
+	msg := fmt.Sprintf(format, args...)
+	ctx.log.WithField("depth", 1).Error(msg)
+	ctx.errs = append(ctx.errs, msg)
 }
 
 // Fatalf 方法输出Fatal日志，并结束请求上下文处理。
 //
 // 注意：如果err中存在敏感信息会被写入到响应中。
 func (ctx *contextBase) Fatalf(format string, args ...interface{}) {
-	ctx.err = fmt.Sprintf(format, args...)
-	ctx.log.WithField("depth", 1).Errorf(ctx.err)
-	ctx.logFatal()
-}
-
-// logFatal 方法执行Fatal方法的返回信息。
-func (ctx *contextBase) logFatal() {
-	// 结束Context
-	status := ctx.ResponseWriter.Status()
-	if status == 200 && ctx.ResponseWriter.Size() == 0 {
-		status = 500
-		ctx.WriteHeader(500)
-	}
-	if status > 399 {
-		ctx.Render(map[string]interface{}{
-			"error":        ctx.err,
-			"status":       status,
-			"x-request-id": ctx.RequestID(),
-		})
+	msg := fmt.Sprintf(format, args...)
+	ctx.log.WithField("depth", 1).Error(msg)
+	ctx.logFatal(msg, nil)
+}
+
+// logFatal 方法记录Fatal错误消息，设置响应状态码并结束Context，错误响应在Next返回时渲染。
+//
+// Err方法返回第一次Fatal的错误，参数为FieldErrors时响应状态码为400，否则为500。
+func (ctx *contextBase) logFatal(msg string, args []interface{}) {
+	var err error
+	if len(args) == 1 {
+		err, _ = args[0].(error)
+	}
+	if ctx.err == "" {
+		ctx.err = msg
+		ctx.fatalErr = err
 	}
+	ctx.errs = append(ctx.errs, msg)
+	if ctx.ResponseWriter.Status() == 200 && ctx.ResponseWriter.Size() == 0 {
+		if _, ok := err.(FieldErrors); ok {
+			ctx.WriteHeader(StatusBadRequest)
+		} else {
+			ctx.WriteHeader(500)
+		}
+	}
+	ctx.fatal = true
 	ctx.End()
 }
 
+// renderFatal 方法使用收集的错误消息渲染一次错误响应，状态码小于400时不渲染。
+func (ctx *contextBase) renderFatal() {
+	ctx.fatal = false
+	if ctx.ResponseWriter.Status() > 399 && DefaultContextFatalFunc != nil {
+		if body := DefaultContextFatalFunc(ctx, ctx.errs); body != nil {
+			ctx.Render(body)
+		}
+	}
+}
+
+// contextFatalBody 函数创建默认的错误响应，error为第一次Fatal的消息，存在多个错误消息时errors为全部消息，
+// Fatal错误为FieldErrors时fields为全部字段错误。
+func contextFatalBody(ctx Context, errs []string) interface{} {
+	err := ctx.Err()
+	body := map[string]interface{}{
+		"error":        err.Error(),
+		"status":       ctx.Response().Status(),
+		"x-request-id": ctx.RequestID(),
+	}
+	if len(errs) > 1 {
+		body["errors"] = errs
+	}
+	if fields, ok := err.(FieldErrors); ok {
+		body["fields"] = fields
+	}
+	return body
+}
+
 // WithField 方法增加一个日志属性，返回一个新的Logout。
 func (ctx *contextBase) WithField(key string, value interface{}) Logout {
 	return &entryContextBase{
@@ -643,30 +990,101 @@ func (ctx *contextBase) WithFields(fields Fields) Logout {
 	}
 }
 
+// NewLogoutDetached function creates a Logout detached from the request, which can be safely passed to goroutines spawned by the handler.
+//
+// NewLogoutDetached 函数创建一个脱离请求的Logout，复制ctx.Logger()和fields的日志属性，可以安全的传递给处理函数创建的协程。
+//
+// ctx.Logger()包含中间件使用SetLogger设置的属性，例如RequestID中间件设置的X-Request-Id，用于关联请求日志。
+//
+// ctx.WithField返回的Logout使用池化的日志条目，输出一次日志后条目放回池中，Fatal还会修改Context状态，
+// 请求结束后在协程中继续使用会与其他请求竞争；脱离的Logout每次输出复制日志条目，可以在多个协程中重复使用，Fatal仅输出日志。
+func NewLogoutDetached(ctx Context, fields Fields) Logout {
+	log := ctx.Logger()
+	if fields != nil {
+		log = log.WithFields(fields)
+	}
+	return log.WithFields(nil)
+}
+
+// Debug 方法输出Debug级别日志。
+func (e *entryContextBase) Debug(args ...interface{}) {
+	e.checkReleased("Debug")
+	e.Logout.WithField("depth", 1).Debug(args...)
+}
+
+// Info 方法输出Info级别日志。
+func (e *entryContextBase) Info(args ...interface{}) {
+	e.checkReleased("Info")
+	e.Logout.WithField("depth", 1).Info(args...)
+}
+
+// Warning 方法输出Warning级别日志。
+func (e *entryContextBase) Warning(args ...interface{}) {
+	e.checkReleased("Warning")
+	e.Logout.WithField("depth", 1).Warning(args...)
+}
+
+// Debugf 方法输出Debug级别日志。
+func (e *entryContextBase) Debugf(format string, args ...interface{}) {
+	e.checkReleased("Debugf")
+	e.Logout.WithField("depth", 1).Debugf(format, args...)
+}
+
+// Infof 方法输出Info级别日志。
+func (e *entryContextBase) Infof(format string, args ...interface{}) {
+	e.checkReleased("Infof")
+	e.Logout.WithField("depth", 1).Infof(format, args...)
+}
+
+// Warningf 方法输出Warning级别日志。
+func (e *entryContextBase) Warningf(format string, args ...interface{}) {
+	e.checkReleased("Warningf")
+	e.Logout.WithField("depth", 1).Warningf(format, args...)
+}
+
+// Error 方法重写Context的Error方法，记录错误消息用于Fatal错误响应。
+func (e *entryContextBase) Error(args ...interface{}) {
+	e.checkReleased("Error")
+	msg := fmt.Sprintln(args...)
+	e.Logout.WithField("depth", 1).Error(args...)
+	e.Context.errs = append(e.Context.errs, msg[:len(msg)-1])
+}
+
+// Errorf 方法重写Context的Errorf方法，记录错误消息用于Fatal错误响应。
+func (e *entryContextBase) Errorf(format string, args ...interface{}) {
+	e.checkReleased("Errorf")
+	msg := fmt.Sprintf(format, args...)
+	e.Logout.WithField("depth", 1).Error(msg)
+	e.Context.errs = append(e.Context.errs, msg)
+}
+
 // Fatal 方法重写Context的Fatal方法，不执行panic，http返回500和请求id。
 func (e *entryContextBase) Fatal(args ...interface{}) {
+	e.checkReleased("Fatal")
 	msg := fmt.Sprintln(args...)
-	e.Context.err = msg[:len(msg)-1]
+	msg = msg[:len(msg)-1]
 	e.Logout.WithField("depth", 1).Error(msg)
-	e.Context.logFatal()
-
+	e.Context.logFatal(msg, args)
 }
 
 // Fatalf 方法重写Context的Fatalf方法，不执行panic，http返回500和请求id。
 func (e *entryContextBase) Fatalf(format string, args ...interface{}) {
-	e.Context.err = fmt.Sprintf(format, args...)
-	e.Logout.WithField("depth", 1).Error(e.Context.err)
-	e.Context.logFatal()
+	e.checkReleased("Fatalf")
+	msg := fmt.Sprintf(format, args...)
+	e.Logout.WithField("depth", 1).Error(msg)
+	e.Context.logFatal(msg, nil)
 }
 
 // WithField 方法增加一个日志属性。
 func (e *entryContextBase) WithField(key string, value interface{}) Logout {
+	e.checkReleased("WithField")
 	e.Logout = e.Logout.WithField(key, value)
 	return e
 }
 
 // WithFields 方法增加多个日志属性。
 func (e *entryContextBase) WithFields(fields Fields) Logout {
+	e.checkReleased("WithFields")
 	e.Logout = e.Logout.WithFields(fields)
 	return e
 }
@@ -734,6 +1152,16 @@ func (ctx ContextData) GetParamFloat64(key string, nums ...float64) float64 {
 	return GetStringFloat64(ctx.GetParam(key), nums...)
 }
 
+// GetParamDuration 获取参数转换成time.Duration类型。
+func (ctx ContextData) GetParamDuration(key string, nums ...time.Duration) time.Duration {
+	return GetStringDuration(ctx.GetParam(key), nums...)
+}
+
+// GetParamStrings 获取参数使用','分割后的值。
+func (ctx ContextData) GetParamStrings(key string) []string {
+	return ctx.Params().GetStrings(key)
+}
+
 // GetParamString 获取一个参数，如果为空字符串返回默认值。
 func (ctx ContextData) GetParamString(key string, strs ...string) string {
 	return GetString(ctx.GetParam(key), strs...)
@@ -0,0 +1,242 @@
+package eudore
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ErrContextReleased 定义Context调试模式下请求结束后使用Context的错误。
+var ErrContextReleased = errors.New("eudore context is released, it is used after the request ends")
+
+// ContextDebug defines the debug mode of the Context pool, which tracks Context checkouts and releases.
+//
+// After the request ends, the Context is no longer put back into the pool but marked as released,
+// and using its Response, Logger, context.Context or the Logout returned by ctx.WithField will output a Warning log with the caller stack,
+// used to find handlers that retain Context or Logout after the request ends. It should not be used in production.
+//
+// ContextDebug 定义Context池调试模式，记录Context取出和释放数量。
+//
+// 请求结束后Context不再放回池中而是标记为已释放，之后使用Response、Logger、GetContext或ctx.WithField返回的Logout会输出带调用栈的Warning日志，
+// 用于发现处理函数在请求结束后保留Context或Logout，避免池化对象被误用后静默破坏其他请求，不要在生产环境使用。
+//
+// app.Logger为LoggerStd时同时记录日志条目取出和放回数量，条目放回后不再回收，之后继续使用条目会输出Warning日志。
+type ContextDebug struct {
+	Checkouts      int64 `json:"checkouts"`
+	Releases       int64 `json:"releases"`
+	Misuses        int64 `json:"misuses"`
+	EntryCheckouts int64 `json:"entrycheckouts"`
+	EntryPuts      int64 `json:"entryputs"`
+	Logger         Logger
+}
+
+// contextDebugLogger 定义支持ContextDebug记录日志条目的Logger。
+type contextDebugLogger interface {
+	setContextDebug(*ContextDebug)
+}
+
+// NewContextDebug function creates a Context pool debug mode and sets it to app.ContextDebug with app.Options.
+//
+// NewContextDebug 函数创建Context池调试模式，使用app.Options设置到app.ContextDebug。
+func NewContextDebug(logger Logger) *ContextDebug {
+	return &ContextDebug{Logger: logger}
+}
+
+// HandleHTTP method returns the number of Context checkouts, releases, in-flight, misuses and logger entry checkouts and puts.
+//
+// HandleHTTP 方法返回Context取出、释放、处理中、误用和日志条目取出、放回的数量。
+func (cd *ContextDebug) HandleHTTP(ctx Context) {
+	checkouts := atomic.LoadInt64(&cd.Checkouts)
+	releases := atomic.LoadInt64(&cd.Releases)
+	ctx.Render(map[string]interface{}{
+		"checkouts":      checkouts,
+		"releases":       releases,
+		"inflight":       checkouts - releases,
+		"misuses":        atomic.LoadInt64(&cd.Misuses),
+		"entrycheckouts": atomic.LoadInt64(&cd.EntryCheckouts),
+		"entryputs":      atomic.LoadInt64(&cd.EntryPuts),
+	})
+}
+
+// checkout 方法记录取出Context。
+func (cd *ContextDebug) checkout() {
+	atomic.AddInt64(&cd.Checkouts, 1)
+}
+
+// release 方法将请求结束的Context替换为已释放状态的Response、Logger和context.Context。
+func (cd *ContextDebug) release(ctx Context) {
+	atomic.AddInt64(&cd.Releases, 1)
+	info := &contextReleased{
+		debug:   cd,
+		request: ctx.Method() + " " + ctx.Path(),
+		route:   ctx.GetParam(ParamRoute),
+		time:    time.Now(),
+	}
+	ctx.SetResponse(&responseWriterReleased{ResponseWriter: ctx.Response(), info: info})
+	ctx.SetLogger(&logoutReleased{Logout: ctx.Logger(), info: info})
+	ctx.WithContext(&contextContextReleased{Context: ctx.GetContext(), info: info})
+}
+
+// contextReleased 定义已释放Context的请求信息。
+type contextReleased struct {
+	debug   *ContextDebug
+	request string
+	route   string
+	time    time.Time
+}
+
+// report 方法输出释放后使用Context的Warning日志和调用栈。
+func (info *contextReleased) report(method string) {
+	atomic.AddInt64(&info.debug.Misuses, 1)
+	info.debug.Logger.WithFields(Fields{
+		"request": info.request,
+		"route":   info.route,
+		"release": time.Since(info.time).String(),
+		"stack":   GetPanicStack(4),
+	}).Warning("eudore context used after release: " + method)
+}
+
+// reportEntry 方法输出日志条目放回后继续使用的Warning日志和调用栈。
+func (cd *ContextDebug) reportEntry(method string) {
+	atomic.AddInt64(&cd.Misuses, 1)
+	cd.Logger.WithField("stack", GetPanicStack(5)).Warning("eudore logger entry used after put: " + method)
+}
+
+// checkReleased 方法检查ctx.WithField返回Logout的Context是否已经释放，已释放时输出Warning日志。
+func (e *entryContextBase) checkReleased(method string) {
+	if log, ok := e.Context.log.(*logoutReleased); ok {
+		log.info.report("WithField." + method)
+	}
+}
+
+type responseWriterReleased struct {
+	ResponseWriter
+	info *contextReleased
+}
+
+func (w *responseWriterReleased) Header() http.Header {
+	w.info.report("Response.Header")
+	return make(http.Header)
+}
+
+func (w *responseWriterReleased) Write([]byte) (int, error) {
+	w.info.report("Response.Write")
+	return 0, ErrContextReleased
+}
+
+func (w *responseWriterReleased) WriteHeader(int) {
+	w.info.report("Response.WriteHeader")
+}
+
+func (w *responseWriterReleased) Flush() {
+	w.info.report("Response.Flush")
+}
+
+func (w *responseWriterReleased) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.info.report("Response.Hijack")
+	return nil, nil, ErrContextReleased
+}
+
+func (w *responseWriterReleased) Push(string, *http.PushOptions) error {
+	w.info.report("Response.Push")
+	return ErrContextReleased
+}
+
+func (w *responseWriterReleased) WriteInformational(int, http.Header) error {
+	w.info.report("Response.WriteInformational")
+	return ErrContextReleased
+}
+
+// logoutReleased 定义已释放Context的Logout，输出Warning后继续写入日志。
+type logoutReleased struct {
+	Logout
+	info *contextReleased
+}
+
+func (log *logoutReleased) Debug(args ...interface{}) {
+	log.info.report("Logger.Debug")
+	log.Logout.Debug(args...)
+}
+
+func (log *logoutReleased) Info(args ...interface{}) {
+	log.info.report("Logger.Info")
+	log.Logout.Info(args...)
+}
+
+func (log *logoutReleased) Warning(args ...interface{}) {
+	log.info.report("Logger.Warning")
+	log.Logout.Warning(args...)
+}
+
+func (log *logoutReleased) Error(args ...interface{}) {
+	log.info.report("Logger.Error")
+	log.Logout.Error(args...)
+}
+
+func (log *logoutReleased) Fatal(args ...interface{}) {
+	log.info.report("Logger.Fatal")
+	log.Logout.Fatal(args...)
+}
+
+func (log *logoutReleased) Debugf(format string, args ...interface{}) {
+	log.info.report("Logger.Debugf")
+	log.Logout.Debugf(format, args...)
+}
+
+func (log *logoutReleased) Infof(format string, args ...interface{}) {
+	log.info.report("Logger.Infof")
+	log.Logout.Infof(format, args...)
+}
+
+func (log *logoutReleased) Warningf(format string, args ...interface{}) {
+	log.info.report("Logger.Warningf")
+	log.Logout.Warningf(format, args...)
+}
+
+func (log *logoutReleased) Errorf(format string, args ...interface{}) {
+	log.info.report("Logger.Errorf")
+	log.Logout.Errorf(format, args...)
+}
+
+func (log *logoutReleased) Fatalf(format string, args ...interface{}) {
+	log.info.report("Logger.Fatalf")
+	log.Logout.Fatalf(format, args...)
+}
+
+func (log *logoutReleased) WithField(key string, value interface{}) Logout {
+	log.info.report("Logger.WithField")
+	return log.Logout.WithField(key, value)
+}
+
+// WithFields 方法fields为空时返回自身，用于Context.SetLogger设置。
+func (log *logoutReleased) WithFields(fields Fields) Logout {
+	if fields == nil {
+		return log
+	}
+	log.info.report("Logger.WithFields")
+	return log.Logout.WithFields(fields)
+}
+
+type contextContextReleased struct {
+	context.Context
+	info *contextReleased
+}
+
+func (ctx *contextContextReleased) Done() <-chan struct{} {
+	ctx.info.report("GetContext.Done")
+	return ctx.Context.Done()
+}
+
+func (ctx *contextContextReleased) Err() error {
+	ctx.info.report("GetContext.Err")
+	return ctx.Context.Err()
+}
+
+func (ctx *contextContextReleased) Value(key interface{}) interface{} {
+	ctx.info.report("GetContext.Value")
+	return ctx.Context.Value(key)
+}
@@ -0,0 +1,74 @@
+//go:build go1.18
+// +build go1.18
+
+package eudore
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// ContextKey defines a typed context key, use Get and Set methods to access the value of Context without type assertion.
+//
+// ContextKey 定义一个类型化的上下文key，使用Get和Set方法读写Context的值，避免字符串key和interface{}类型断言。
+//
+// key使用指针比较，不同NewContextKey创建的key即使名称相同也不会冲突。
+type ContextKey[T any] struct {
+	name string
+}
+
+var contextKeys sync.Map
+
+// NewContextKey 函数创建一个类型化的上下文key，并登记名称和类型，使用ListContextKeys查看全部key。
+//
+// 通常定义为包级变量，例如var KeyUser = eudore.NewContextKey[*User]("user")。
+func NewContextKey[T any](name string) *ContextKey[T] {
+	key := &ContextKey[T]{name: name}
+	contextKeys.Store(key, name+" "+reflect.TypeOf((*T)(nil)).Elem().String())
+	return key
+}
+
+// ListContextKeys 函数返回全部登记的上下文key名称和类型。
+func ListContextKeys() []string {
+	var keys []string
+	contextKeys.Range(func(_, val interface{}) bool {
+		keys = append(keys, val.(string))
+		return true
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+// String 方法返回key的名称。
+func (key *ContextKey[T]) String() string {
+	return "eudore.ContextKey(" + key.name + ")"
+}
+
+// Set 方法设置Context的值，值保存在ctx.GetContext()中，后续处理函数和衍生的context都可以读取。
+func (key *ContextKey[T]) Set(ctx Context, val T) {
+	ctx.WithContext(context.WithValue(ctx.GetContext(), key, val))
+}
+
+// Get 方法获取Context的值，值不存在时返回零值和false。
+func (key *ContextKey[T]) Get(ctx Context) (T, bool) {
+	return key.From(ctx.GetContext())
+}
+
+// Value 方法获取Context的值，值不存在时返回零值。
+func (key *ContextKey[T]) Value(ctx Context) T {
+	val, _ := key.From(ctx.GetContext())
+	return val
+}
+
+// From 方法从context.Context获取值，用于http.Handler等非Context的场景。
+func (key *ContextKey[T]) From(c context.Context) (T, bool) {
+	val, ok := c.Value(key).(T)
+	return val, ok
+}
+
+// With 方法返回设置值的context.Context。
+func (key *ContextKey[T]) With(c context.Context, val T) context.Context {
+	return context.WithValue(c, key, val)
+}
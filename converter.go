@@ -677,7 +677,8 @@ func (c *converter) convertToStructToStruct(sValue reflect.Value, tValue reflect
 	sType := sValue.Type()
 	tType := tValue.Type()
 	for i := 0; i < sType.NumField(); i++ {
-		if checkValueIsZero(sValue.Field(i)) || !sValue.CanSet() {
+		// 跳过未导出属性，结构体值不可寻址时也可以读取导出属性。
+		if sType.Field(i).PkgPath != "" || checkValueIsZero(sValue.Field(i)) {
 			continue
 		}
 		index := getStructIndexOfTags(tType, sType.Field(i).Name, c.tags)
@@ -0,0 +1,204 @@
+package eudore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventBus defines an intra-app event bus, which delivers the event to all subscribers whose pattern matches the topic.
+//
+// EventBus 定义应用内事件总线，将事件投递给全部匹配topic的订阅者。
+//
+// topic使用'.'分隔，订阅模式中'*'匹配一段，最后一段为'#'时匹配剩余任意段，例如user.*、user.#。
+type EventBus struct {
+	sync.RWMutex
+	Context     context.Context
+	Logger      Logout
+	subscribers []*eventSubscriber
+	wg          sync.WaitGroup
+	closed      bool
+}
+
+// Event 定义一个事件。
+type Event struct {
+	Topic   string      `json:"topic"`
+	Payload interface{} `json:"payload"`
+	Time    time.Time   `json:"time"`
+}
+
+// EventHandler 定义事件处理函数。
+type EventHandler func(context.Context, *Event) error
+
+// EventMode 定义事件投递方式。
+type EventMode int
+
+// 定义事件投递方式。
+const (
+	// EventSync 在Emit调用者协程中同步投递，Emit返回处理函数的错误，默认方式。
+	EventSync EventMode = iota
+	// EventAsync 在新协程中异步投递，处理函数的错误输出到日志。
+	EventAsync
+)
+
+// ErrEventBusClosed 定义Wait后发布事件返回的错误。
+var ErrEventBusClosed = errors.New("event bus is closed")
+
+type eventSubscriber struct {
+	pattern []string
+	mode    EventMode
+	handler EventHandler
+}
+
+// NewEventBus function creates an event bus, ctx is passed to the event handler.
+//
+// NewEventBus 函数创建一个事件总线，ctx会传递给事件处理函数。
+func NewEventBus(ctx context.Context, log Logout) *EventBus {
+	return &EventBus{Context: ctx, Logger: log}
+}
+
+// On method subscribes to the topic pattern, options can be EventMode, and returns the function to cancel the subscription.
+//
+// On 方法订阅topic模式，options可以为EventMode，返回取消订阅的函数。
+func (bus *EventBus) On(pattern string, fn EventHandler, options ...interface{}) func() {
+	sub := &eventSubscriber{
+		pattern: strings.Split(pattern, "."),
+		handler: fn,
+	}
+	for _, i := range options {
+		if mode, ok := i.(EventMode); ok {
+			sub.mode = mode
+		}
+	}
+
+	bus.Lock()
+	bus.subscribers = append(bus.subscribers, sub)
+	bus.Unlock()
+	return func() {
+		bus.Lock()
+		defer bus.Unlock()
+		for i, s := range bus.subscribers {
+			if s == sub {
+				bus.subscribers = append(bus.subscribers[:i:i], bus.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Emit method publishes an event, and returns the errors of sync subscribers, returns ErrEventBusClosed after Wait.
+//
+// Emit 方法发布一个事件，返回同步订阅者处理的错误，Wait后返回ErrEventBusClosed。
+func (bus *EventBus) Emit(topic string, payload interface{}) error {
+	event := &Event{Topic: topic, Payload: payload, Time: time.Now()}
+	topics := strings.Split(topic, ".")
+	bus.RLock()
+	if bus.closed {
+		bus.RUnlock()
+		return ErrEventBusClosed
+	}
+	subs := make([]*eventSubscriber, 0, len(bus.subscribers))
+	async := 0
+	for _, sub := range bus.subscribers {
+		if matchEventTopic(sub.pattern, topics) {
+			subs = append(subs, sub)
+			if sub.mode == EventAsync {
+				async++
+			}
+		}
+	}
+	// 在锁内Add，避免与Wait并发。
+	bus.wg.Add(async)
+	bus.RUnlock()
+
+	var errs muliterror
+	for _, sub := range subs {
+		if sub.mode == EventAsync {
+			go func(sub *eventSubscriber) {
+				defer bus.wg.Done()
+				err := bus.call(sub, event)
+				if err != nil {
+					bus.Logger.WithField("topic", topic).Error(err)
+				}
+			}(sub)
+			continue
+		}
+		errs.HandleError(bus.call(sub, event))
+	}
+	return errs.GetError()
+}
+
+// Wait method rejects new events and waits for all async event handlers to finish.
+//
+// Wait 方法拒绝发布新事件并等待全部异步事件处理函数结束。
+func (bus *EventBus) Wait() {
+	bus.Lock()
+	bus.closed = true
+	bus.Unlock()
+	bus.wg.Wait()
+}
+
+func (bus *EventBus) call(sub *eventSubscriber, event *Event) (err error) {
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = fmt.Errorf("event %s handler panic: %v", event.Topic, rerr)
+		}
+	}()
+	return sub.handler(bus.Context, event)
+}
+
+// matchEventTopic 函数匹配订阅模式和topic。
+func matchEventTopic(pattern, topics []string) bool {
+	for i, p := range pattern {
+		if p == "#" && i == len(pattern)-1 {
+			return true
+		}
+		if i >= len(topics) || (p != "*" && p != topics[i]) {
+			return false
+		}
+	}
+	return len(pattern) == len(topics)
+}
+
+// Bind method sets the payload to the pointer i, if the type is different, use ConvertToWithTags to convert.
+//
+// Bind 方法将payload设置到指针i，如果类型不同使用ConvertToWithTags转换，使用DefaultConvertEventTags。
+func (e *Event) Bind(i interface{}) error {
+	iValue := reflect.ValueOf(i)
+	if iValue.Kind() != reflect.Ptr || iValue.IsNil() {
+		return fmt.Errorf("event bind target must be a non-nil pointer, current type is %T", i)
+	}
+	pValue := reflect.ValueOf(e.Payload)
+	if pValue.IsValid() && pValue.Type().AssignableTo(iValue.Elem().Type()) {
+		iValue.Elem().Set(pValue)
+		return nil
+	}
+	return ConvertToWithTags(e.Payload, i, DefaultConvertEventTags)
+}
+
+// Get method uses the key path to get the value of the payload.
+//
+// Get 方法使用key路径获取payload的值。
+func (e *Event) Get(key string) interface{} {
+	payload := e.Payload
+	// GetWithTags不能获取非指针结构体的属性，复制一个指针。
+	pValue := reflect.ValueOf(payload)
+	if pValue.Kind() == reflect.Struct {
+		ptr := reflect.New(pValue.Type())
+		ptr.Elem().Set(pValue)
+		payload = ptr.Interface()
+	}
+	val, _ := GetWithTags(payload, key, DefaultConvertEventTags)
+	return val
+}
+
+// GetString method uses the key path to get the string value of the payload.
+//
+// GetString 方法使用key路径获取payload的字符串值。
+func (e *Event) GetString(key string) string {
+	return GetString(e.Get(key))
+}
@@ -0,0 +1,169 @@
+package eudore
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+)
+
+// Features defines the feature flag registry, flags can be loaded from config or remote source and toggled at runtime.
+//
+// Features 定义功能开关注册表，可以从配置或远程加载，并在运行时修改。
+type Features struct {
+	sync.RWMutex
+	flags map[string]*Feature
+}
+
+// Feature 定义一个功能开关，Enabled为true且Percent在1-99之间时按key灰度开启。
+type Feature struct {
+	Name        string `alias:"name" json:"name"`
+	Enabled     bool   `alias:"enabled" json:"enabled"`
+	Percent     int    `alias:"percent" json:"percent"`
+	Description string `alias:"description" json:"description,omitempty"`
+}
+
+// NewFeatures function creates an empty feature flag registry.
+//
+// NewFeatures 函数创建一个空的功能开关注册表。
+func NewFeatures() *Features {
+	return &Features{flags: make(map[string]*Feature)}
+}
+
+// Set method adds or replaces a feature flag.
+//
+// Set 方法添加或替换一个功能开关。
+func (fs *Features) Set(f *Feature) {
+	fs.Lock()
+	fs.flags[f.Name] = f
+	fs.Unlock()
+}
+
+// Get method returns a copy of the feature flag, returns nil if it does not exist.
+//
+// Get 方法返回功能开关的副本，不存在返回nil。
+func (fs *Features) Get(name string) *Feature {
+	fs.RLock()
+	defer fs.RUnlock()
+	f, ok := fs.flags[name]
+	if !ok {
+		return nil
+	}
+	c := *f
+	return &c
+}
+
+// List method returns copies of all feature flags sorted by name.
+//
+// List 方法返回按名称排序的全部功能开关副本。
+func (fs *Features) List() []*Feature {
+	fs.RLock()
+	flags := make([]*Feature, 0, len(fs.flags))
+	for _, f := range fs.flags {
+		c := *f
+		flags = append(flags, &c)
+	}
+	fs.RUnlock()
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	return flags
+}
+
+// Enabled method checks whether the feature is enabled for key, the same key always gets the same result in percentage rollouts.
+//
+// Enabled 方法检查功能对key是否开启，灰度开启时相同key总是得到相同结果，不存在的功能返回false。
+func (fs *Features) Enabled(name, key string) bool {
+	fs.RLock()
+	f, ok := fs.flags[name]
+	fs.RUnlock()
+	if !ok || !f.Enabled {
+		return false
+	}
+	if f.Percent <= 0 || f.Percent >= 100 {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name + ":" + key))
+	return int(h.Sum32()%100) < f.Percent
+}
+
+// Load method loads feature flags from []*Feature, map name to Feature or json bytes, existing flags with the same name are replaced.
+//
+// Load 方法从[]*Feature、名称映射Feature的map或json加载功能开关，替换同名开关，
+// 可以使用app.Get("features")的配置，或者使用app.Every周期从远程加载。
+func (fs *Features) Load(i interface{}) error {
+	var flags []*Feature
+	switch val := i.(type) {
+	case nil:
+		return nil
+	case []*Feature:
+		flags = val
+	case []byte:
+		var m map[string]*Feature
+		if json.Unmarshal(val, &m) == nil {
+			return fs.Load(m)
+		}
+		err := json.Unmarshal(val, &flags)
+		if err != nil {
+			return err
+		}
+	case map[string]*Feature:
+		for name, f := range val {
+			if f.Name == "" {
+				f.Name = name
+			}
+			flags = append(flags, f)
+		}
+	case map[string]interface{}:
+		for name, v := range val {
+			f := &Feature{Name: name}
+			err := ConvertTo(v, f)
+			if err != nil {
+				return err
+			}
+			flags = append(flags, f)
+		}
+	default:
+		err := ConvertTo(i, &flags)
+		if err != nil {
+			return fmt.Errorf("features load invalid type %T: %v", i, err)
+		}
+	}
+	fs.Lock()
+	for _, f := range flags {
+		fs.flags[f.Name] = f
+	}
+	fs.Unlock()
+	return nil
+}
+
+// HandleHTTP method implements the admin endpoint, GET lists flags, PUT binds the request body to the flag named by the route param name or body.
+//
+// HandleHTTP 方法实现管理接口，GET返回全部开关，PUT将请求body绑定为路由参数name或body中name对应的开关。
+func (fs *Features) HandleHTTP(ctx Context) {
+	if ctx.Method() != MethodPut {
+		ctx.Render(fs.List())
+		return
+	}
+	name := ctx.GetParam("name")
+	f := fs.Get(name)
+	if f == nil {
+		f = &Feature{Name: name}
+	}
+	err := ctx.Bind(f)
+	if err != nil {
+		ctx.Fatal(err)
+		return
+	}
+	if name != "" {
+		f.Name = name
+	}
+	if f.Name == "" {
+		ctx.WriteHeader(StatusBadRequest)
+		ctx.Fatal("feature name is empty")
+		return
+	}
+	fs.Set(f)
+	ctx.Info("feature update:", f.Name, f.Enabled, f.Percent)
+	ctx.Render(f)
+}
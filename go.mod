@@ -1,3 +1,5 @@
 module github.com/eudore/eudore
 
 go 1.9
+
+require golang.org/x/crypto v0.10.0
@@ -1,11 +1,13 @@
 package eudore
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 	"unsafe"
 )
@@ -56,6 +58,8 @@ var (
 	contextFuncName  = make(map[uintptr]string)   // 最终名称
 	contextSaveName  = make(map[uintptr]string)   // 函数名称
 	contextAliasName = make(map[uintptr][]string) // 对象名称
+	// handlerNames 保存名称对应的处理函数构造函数。
+	handlerNames = make(map[string]func(string) (HandlerFunc, error))
 )
 
 // init 函数初始化内置扩展的请求上下文处理函数。
@@ -66,6 +70,7 @@ func init() {
 	DefaultHandlerExtend.RegisterHandlerExtend("", NewExtendHandlerNetHTTP)
 	DefaultHandlerExtend.RegisterHandlerExtend("", NewExtendFuncNetHTTP1)
 	DefaultHandlerExtend.RegisterHandlerExtend("", NewExtendFuncNetHTTP2)
+	DefaultHandlerExtend.RegisterHandlerExtend("", NewExtendFuncNetHTTPMiddleware)
 	DefaultHandlerExtend.RegisterHandlerExtend("", NewExtendFunc)
 	DefaultHandlerExtend.RegisterHandlerExtend("", NewExtendFuncRender)
 	DefaultHandlerExtend.RegisterHandlerExtend("", NewExtendFuncError)
@@ -90,6 +95,24 @@ func init() {
 	DefaultHandlerExtend.RegisterHandlerExtend("", NewExtendControllerFuncMapStringRender)
 	DefaultHandlerExtend.RegisterHandlerExtend("", NewExtendControllerFuncMapStringError)
 	DefaultHandlerExtend.RegisterHandlerExtend("", NewExtendControllerFuncMapStringRenderError)
+
+	// 名称处理函数
+	RegisterHandlerName("static", func(dir string) (HandlerFunc, error) {
+		return NewStaticHandler(dir), nil
+	})
+	RegisterHandlerName("proxy", func(target string) (HandlerFunc, error) {
+		return NewProxyHandler(target)
+	})
+	RegisterHandlerName("redirect", NewRedirectHandler)
+	RegisterHandlerName("status", func(arg string) (HandlerFunc, error) {
+		code, err := strconv.Atoi(arg)
+		if err != nil || code < 100 || code > 999 {
+			return nil, fmt.Errorf("invalid status code %s", arg)
+		}
+		return func(ctx Context) {
+			ctx.WriteHeader(code)
+		}, nil
+	})
 }
 
 // NewHandlerExtendBase method returns a basic function extension processing object.
@@ -151,6 +174,12 @@ func (ext *handlerExtendBase) NewHandlerFuncs(_ string, i interface{}) HandlerFu
 func (ext *handlerExtendBase) newHandlerFuncs(iValue reflect.Value) HandlerFuncs {
 	// 基础类型返回
 	switch fn := iValue.Interface().(type) {
+	case string:
+		h, err := NewHandlerName(fn)
+		if err != nil {
+			return nil
+		}
+		return HandlerFuncs{h}
 	case func(Context):
 		SetHandlerFuncName(fn, getHandlerAliasName(iValue))
 		return HandlerFuncs{fn}
@@ -541,24 +570,79 @@ func NewExtendHandlerNetHTTP(h http.Handler) HandlerFunc {
 		h = clone.CloneHandler()
 	}
 	return func(ctx Context) {
-		h.ServeHTTP(ctx.Response(), ctx.Request())
+		h.ServeHTTP(ctx.Response(), newNetHTTPRequest(ctx))
 	}
 }
 
 // NewExtendFuncNetHTTP1 函数转换处理func(http.ResponseWriter, *http.Request)类型。
 func NewExtendFuncNetHTTP1(fn func(http.ResponseWriter, *http.Request)) HandlerFunc {
 	return func(ctx Context) {
-		fn(ctx.Response(), ctx.Request())
+		fn(ctx.Response(), newNetHTTPRequest(ctx))
 	}
 }
 
 // NewExtendFuncNetHTTP2 函数转换处理http.HandlerFunc类型。
 func NewExtendFuncNetHTTP2(fn http.HandlerFunc) HandlerFunc {
 	return func(ctx Context) {
-		fn(ctx.Response(), ctx.Request())
+		fn(ctx.Response(), newNetHTTPRequest(ctx))
+	}
+}
+
+// NewExtendFuncNetHTTPMiddleware 函数转换处理func(http.Handler) http.Handler类型的net/http中间件，例如negroni、alice使用的中间件。
+//
+// 中间件调用next时继续执行后续处理函数，中间件修改的请求和响应在后续处理函数中生效，未调用next时结束请求处理。
+func NewExtendFuncNetHTTPMiddleware(fn func(http.Handler) http.Handler) HandlerFunc {
+	h := fn(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := GetRequestContext(r)
+		if ctx == nil {
+			return
+		}
+		req, resp, cctx := ctx.Request(), ctx.Response(), ctx.GetContext()
+		if w != http.ResponseWriter(resp) {
+			rw, ok := w.(ResponseWriter)
+			if !ok {
+				rw = &responseWriterHTTP{ResponseWriter: w, code: StatusOK}
+			}
+			ctx.SetResponse(rw)
+		}
+		ctx.SetRequest(r)
+		ctx.WithContext(r.Context())
+		ctx.Next()
+		ctx.SetRequest(req)
+		ctx.SetResponse(resp)
+		ctx.WithContext(cctx)
+	}))
+	return func(ctx Context) {
+		index, _ := ctx.GetHandler()
+		h.ServeHTTP(ctx.Response(), newNetHTTPRequest(ctx))
+		if i, _ := ctx.GetHandler(); i == index {
+			ctx.End()
+		}
 	}
 }
 
+// GetRequestContext 函数获取http.Handler处理的请求对应的eudore.Context，请求不是由eudore处理时返回nil。
+func GetRequestContext(r *http.Request) Context {
+	ctx, _ := r.Context().Value(ContextContextKey).(Context)
+	return ctx
+}
+
+// GetRequestParam 函数获取http.Handler处理的请求对应的eudore路由参数。
+func GetRequestParam(r *http.Request, key string) string {
+	ctx := GetRequestContext(r)
+	if ctx == nil {
+		return ""
+	}
+	return ctx.GetParam(key)
+}
+
+// newNetHTTPRequest 函数创建http.Handler使用的请求，请求context保存eudore.Context，go1.22及以上版本将路由参数设置为PathValue。
+func newNetHTTPRequest(ctx Context) *http.Request {
+	r := ctx.Request().WithContext(context.WithValue(ctx.GetContext(), ContextContextKey, ctx))
+	setRequestPathValues(r, ctx.Params())
+	return r
+}
+
 // NewExtendFunc 函数处理func()。
 func NewExtendFunc(fn func()) HandlerFunc {
 	return func(Context) {
@@ -756,6 +840,45 @@ func NewStaticHandler(dir string) HandlerFunc {
 	}
 }
 
+// NewRedirectHandler 函数创建一个重定向处理函数，使用302状态码重定向到target。
+func NewRedirectHandler(target string) (HandlerFunc, error) {
+	if target == "" {
+		return nil, fmt.Errorf("redirect target is empty")
+	}
+	return func(ctx Context) {
+		ctx.Redirect(StatusFound, target)
+	}, nil
+}
+
+// RegisterHandlerName function registers a handler constructor by name, used to create handlers from strings like "static:/var/www".
+//
+// RegisterHandlerName 函数注册一个名称处理函数构造函数，构造函数参数为名称后的字符串，
+// 默认存在static、proxy、redirect、status，用于从配置或数据库使用字符串注册路由。
+func RegisterHandlerName(name string, fn func(string) (HandlerFunc, error)) {
+	handlerNames[name] = fn
+}
+
+// NewHandlerName function creates a handler from a string in the format "name:arg", such as "proxy:http://backend".
+//
+// NewHandlerName 函数使用"name:arg"格式的字符串创建处理函数，例如"static:/var/www"、"proxy:http://backend"，
+// 路由注册处理函数为字符串时使用该函数创建处理函数: app.AnyFunc("/api/*", "proxy:http://backend")。
+func NewHandlerName(str string) (HandlerFunc, error) {
+	name, arg := str, ""
+	if pos := strings.IndexByte(str, ':'); pos != -1 {
+		name, arg = str[:pos], strings.TrimSpace(str[pos+1:])
+	}
+	fn, ok := handlerNames[strings.TrimSpace(name)]
+	if !ok {
+		return nil, fmt.Errorf("handler name %s not registered", name)
+	}
+	h, err := fn(arg)
+	if err != nil {
+		return nil, fmt.Errorf("handler %s create error: %v", str, err)
+	}
+	SetHandlerFuncName(h, str)
+	return h, nil
+}
+
 // HandlerEmpty 函数定义一个空的请求上下文处理函数。
 func HandlerEmpty(Context) {
 	// Do nothing because empty handler does not process entries.
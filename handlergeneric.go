@@ -0,0 +1,84 @@
+//go:build go1.18
+// +build go1.18
+
+package eudore
+
+import (
+	"reflect"
+	"runtime"
+)
+
+// HandlerJSON function uses generics to create a typed handler, binds and validates the request, calls fn and renders the response as json.
+//
+// Type checking is done at compile time, no reflection is used at request time.
+//
+// HandlerJSON 函数使用泛型创建类型安全的处理函数，绑定并校验请求，调用fn后使用json渲染响应。
+//
+// 类型在编译时检查，请求时不使用反射；Req为结构体或map类型，绑定或校验失败响应400，fn返回error时使用ctx.Fatal处理。
+func HandlerJSON[Req, Resp any](fn func(Context, Req) (Resp, error)) HandlerFunc {
+	return newHandlerGeneric(fn, RenderJSON, "HandlerJSON")
+}
+
+// HandlerRender 函数与HandlerJSON相同，但是响应使用ctx.Render根据Accept选择渲染器。
+func HandlerRender[Req, Resp any](fn func(Context, Req) (Resp, error)) HandlerFunc {
+	return newHandlerGeneric(fn, nil, "HandlerRender")
+}
+
+// HandlerBind 函数使用泛型创建只绑定校验请求的处理函数，fn写入响应或返回error。
+func HandlerBind[Req any](fn func(Context, Req) error) HandlerFunc {
+	h := func(ctx Context) {
+		var req Req
+		if !bindGeneric(ctx, &req) {
+			return
+		}
+		if err := fn(ctx, req); err != nil {
+			ctx.Fatal(err)
+		}
+	}
+	setHandlerGenericName(h, fn, "HandlerBind")
+	return h
+}
+
+func newHandlerGeneric[Req, Resp any](fn func(Context, Req) (Resp, error), render func(Context, interface{}) error, extname string) HandlerFunc {
+	h := func(ctx Context) {
+		var req Req
+		if !bindGeneric(ctx, &req) {
+			return
+		}
+		resp, err := fn(ctx, req)
+		if err == nil && ctx.Response().Size() == 0 {
+			if render != nil {
+				err = render(ctx, resp)
+			} else {
+				err = ctx.Render(resp)
+			}
+		}
+		if err != nil {
+			ctx.Fatal(err)
+		}
+	}
+	setHandlerGenericName(h, fn, extname)
+	return h
+}
+
+// bindGeneric 函数绑定并校验请求，失败时响应400。
+func bindGeneric(ctx Context, req interface{}) bool {
+	err := ctx.Bind(req)
+	if err == nil {
+		err = ctx.Validate(req)
+	}
+	if err != nil {
+		if ctx.Response().Status() < 400 {
+			ctx.WriteHeader(StatusBadRequest)
+		}
+		ctx.Fatal(err)
+		return false
+	}
+	return true
+}
+
+// setHandlerGenericName 函数使用fn名称设置处理函数名称，用于路由注册输出。
+func setHandlerGenericName(h HandlerFunc, fn interface{}, extname string) {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	SetHandlerFuncName(h, name+"("+extname+")")
+}
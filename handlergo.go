@@ -0,0 +1,11 @@
+//go:build !go1.22
+// +build !go1.22
+
+package eudore
+
+import (
+	"net/http"
+)
+
+// setRequestPathValues 函数在go1.22以下版本不支持PathValue，使用GetRequestParam函数获取路由参数。
+func setRequestPathValues(*http.Request, *Params) {}
@@ -0,0 +1,17 @@
+//go:build go1.22
+// +build go1.22
+
+package eudore
+
+import (
+	"net/http"
+)
+
+// setRequestPathValues 函数将路由参数设置为请求PathValue，http.Handler可以使用r.PathValue获取路由参数。
+func setRequestPathValues(r *http.Request, params *Params) {
+	for i, key := range params.Keys {
+		if key != "" {
+			r.SetPathValue(key, params.Vals[i])
+		}
+	}
+}
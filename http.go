@@ -4,14 +4,18 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"strings"
+	"time"
 )
 
 // ResponseWriter 接口用于写入http请求响应体status、header、body。
 //
 // net/http.response实现了flusher、hijacker、pusher接口。
+//
+// 写入trailer使用http.TrailerPrefix前缀的header，或在写入状态码前设置Trailer header声明。
 type ResponseWriter interface {
 	// http.ResponseWriter
 	Header() http.Header
@@ -27,11 +31,20 @@ type ResponseWriter interface {
 	Status() int
 }
 
+// ResponseInformational 定义ResponseWriter可选实现的1xx响应写入接口，类似http.Pusher，使用类型断言判断是否支持。
+type ResponseInformational interface {
+	WriteInformational(int, http.Header) error
+}
+
 // responseWriterHTTP 是对net/http.ResponseWriter接口封装
 type responseWriterHTTP struct {
 	http.ResponseWriter
-	code int
-	size int
+	code        int
+	size        int
+	wroteHeader bool
+	hijacked    bool
+	// misuse 在写入状态码后再次写入状态码或劫持连接后写入时调用，参数为方法名称。
+	misuse func(string)
 }
 
 // SetCookie 定义响应返回的set-cookie header的数据生成
@@ -110,6 +123,45 @@ func (p *Params) Get(key string) string {
 	return ""
 }
 
+// GetString 方法返回一个参数的值，如果为空字符串返回默认值。
+func (p *Params) GetString(key string, strs ...string) string {
+	return GetString(p.Get(key), strs...)
+}
+
+// GetStrings 方法返回一个参数使用','分割后的值，例如role=admin,editor。
+func (p *Params) GetStrings(key string) []string {
+	val := p.Get(key)
+	if val == "" {
+		return nil
+	}
+	return strings.Split(val, ",")
+}
+
+// GetBool 方法返回一个参数转换成bool类型的值。
+func (p *Params) GetBool(key string) bool {
+	return GetStringBool(p.Get(key))
+}
+
+// GetInt 方法返回一个参数转换成int类型的值。
+func (p *Params) GetInt(key string, nums ...int) int {
+	return GetStringInt(p.Get(key), nums...)
+}
+
+// GetInt64 方法返回一个参数转换成int64类型的值。
+func (p *Params) GetInt64(key string, nums ...int64) int64 {
+	return GetStringInt64(p.Get(key), nums...)
+}
+
+// GetFloat64 方法返回一个参数转换成float64类型的值。
+func (p *Params) GetFloat64(key string, nums ...float64) float64 {
+	return GetStringFloat64(p.Get(key), nums...)
+}
+
+// GetDuration 方法返回一个参数转换成time.Duration类型的值，例如timeout=5s cache=30s。
+func (p *Params) GetDuration(key string, nums ...time.Duration) time.Duration {
+	return GetStringDuration(p.Get(key), nums...)
+}
+
 // Add 方法添加一个参数。
 func (p *Params) Add(key string, val string) {
 	if key != "" {
@@ -143,34 +195,77 @@ func (w *responseWriterHTTP) Reset(writer http.ResponseWriter) {
 	w.ResponseWriter = writer
 	w.code = http.StatusOK
 	w.size = 0
+	w.wroteHeader = false
+	w.hijacked = false
 }
 
-// Write 方法实现io.Writer接口。
+// Write 方法实现io.Writer接口，劫持连接后写入返回http.ErrHijacked。
 func (w *responseWriterHTTP) Write(data []byte) (int, error) {
+	if w.hijacked {
+		w.report("Response.Write")
+		return 0, http.ErrHijacked
+	}
+	w.wroteHeader = true
 	n, err := w.ResponseWriter.Write(data)
 	w.size = w.size + n
 	return n, err
 }
 
-// WriteHeader 方法实现写入http请求状态码。
+// WriteString 方法写入字符串，原始响应实现WriteString方法时避免转换[]byte的内存分配。
+func (w *responseWriterHTTP) WriteString(data string) (int, error) {
+	if w.hijacked {
+		w.report("Response.WriteString")
+		return 0, http.ErrHijacked
+	}
+	w.wroteHeader = true
+	n, err := io.WriteString(w.ResponseWriter, data)
+	w.size = w.size + n
+	return n, err
+}
+
+// WriteHeader 方法实现写入http请求状态码，已经写入状态码或劫持连接后忽略。
 func (w *responseWriterHTTP) WriteHeader(codeCode int) {
+	if w.wroteHeader || w.hijacked {
+		w.report("Response.WriteHeader")
+		return
+	}
+	w.wroteHeader = true
 	w.code = codeCode
 	w.ResponseWriter.WriteHeader(w.code)
 }
 
 // Flush 方法实现刷新缓冲，将缓冲的请求发送给客户端。
 func (w *responseWriterHTTP) Flush() {
-	w.ResponseWriter.(http.Flusher).Flush()
+	if w.hijacked {
+		w.report("Response.Flush")
+		return
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		flusher.Flush()
+	}
 }
 
 // Hijack 方法实现劫持http连接。
 func (w *responseWriterHTTP) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if w.hijacked {
+		w.report("Response.Hijack")
+		return nil, nil, http.ErrHijacked
+	}
 	if hj, ok := w.ResponseWriter.(http.Hijacker); ok {
-		return hj.Hijack()
+		conn, buf, err := hj.Hijack()
+		w.hijacked = err == nil
+		return conn, buf, err
 	}
 	return nil, nil, ErrResponseWriterHTTPNotHijacker
 }
 
+func (w *responseWriterHTTP) report(method string) {
+	if w.misuse != nil {
+		w.misuse(method)
+	}
+}
+
 // Push 方法实现http Psuh，如果responseWriterHTTP实现http.Push接口，则Push资源。
 func (w *responseWriterHTTP) Push(target string, opts *http.PushOptions) error {
 	if pusher, ok := w.ResponseWriter.(http.Pusher); ok {
@@ -179,6 +274,36 @@ func (w *responseWriterHTTP) Push(target string, opts *http.PushOptions) error {
 	return nil
 }
 
+// WriteInformational 方法写入一个1xx响应，例如103 Early Hints，header仅用于本次1xx响应，不会影响最终响应的header。
+//
+// 需要go1.19及以上版本的net/http支持写入多个响应状态码，101需要使用Hijack处理。
+func (w *responseWriterHTTP) WriteInformational(code int, header http.Header) error {
+	if code < 100 || code > 199 || code == StatusSwitchingProtocols {
+		return ErrResponseWriterInformationalCode
+	}
+	if w.wroteHeader || w.hijacked {
+		w.report("Response.WriteInformational")
+		return ErrResponseWriterHeaderWritten
+	}
+	h := w.ResponseWriter.Header()
+	saved := make(http.Header, len(header))
+	for k, v := range header {
+		if old, ok := h[k]; ok {
+			saved[k] = old
+		}
+		h[k] = v
+	}
+	w.ResponseWriter.WriteHeader(code)
+	for k := range header {
+		if old, ok := saved[k]; ok {
+			h[k] = old
+		} else {
+			delete(h, k)
+		}
+	}
+	return nil
+}
+
 // Size 方法获得写入的数据长度。
 func (w *responseWriterHTTP) Size() int {
 	return w.size
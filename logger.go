@@ -10,6 +10,7 @@ Logger定义通用日志处理接口
 
 import (
 	"fmt"
+	"hash/fnv"
 	"runtime"
 	"strconv"
 	"strings"
@@ -400,6 +401,42 @@ func GetPanicStack(depth int) []string {
 	return stack
 }
 
+// GetErrorFingerprint 函数返回错误消息和栈顶函数的哈希，用于日志聚合系统对相同错误分组和告警去重。
+//
+// 消息中的连续数字替换为0，栈使用GetPanicStack格式，忽略runtime函数和文件行号，
+// 使用前DefaultLoggerFingerprintDepth个函数名称，返回16位十六进制字符串。
+func GetErrorFingerprint(message string, stack []string) string {
+	h := fnv.New64a()
+	var digit bool
+	for _, r := range message {
+		if r >= '0' && r <= '9' {
+			if !digit {
+				h.Write([]byte{'0'})
+			}
+			digit = true
+			continue
+		}
+		digit = false
+		h.Write([]byte(string(r)))
+	}
+	var n int
+	for _, frame := range stack {
+		if n >= DefaultLoggerFingerprintDepth {
+			break
+		}
+		if pos := strings.LastIndexByte(frame, ' '); pos != -1 {
+			frame = frame[pos+1:]
+		}
+		if strings.HasPrefix(frame, "runtime.") {
+			continue
+		}
+		h.Write([]byte{'\n'})
+		h.Write([]byte(frame))
+		n++
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
 func printEmpty(...interface{}) {
 	// Do nothing because  not print message.
 }
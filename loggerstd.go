@@ -12,9 +12,9 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
-	"unsafe"
 )
 
 const _hex = "0123456789abcdef"
@@ -37,12 +37,20 @@ var (
 )
 
 // loggerStd 标准日志处理实现，将日志输出到标准输出或者文件。
+//
+// 嵌入的根条目和WithFields(nil)返回的条目为只读，输出和添加属性时复制一个新条目，可以在多个协程中同时使用；
+// 日志级别使用原子操作读写，Mutex仅用于串行写入Writer。
 type loggerStd struct {
 	LoggerStdConfig
 	Writer LoggerWriter `json:"-" alias:"writer"`
 	Pool   sync.Pool    `json:"-" alias:"pool"`
 	Mutex  sync.Mutex   `json:"-" alias:"mutex"`
+	Stat   *LoggerStat  `json:"-" alias:"stat"`
 	*entryStd
+	debug *ContextDebug
+	done  chan struct{}
+	once  sync.Once
+	flush sync.WaitGroup
 }
 
 // LoggerStdConfig 定义loggerStd配置信息。
@@ -61,16 +69,45 @@ type loggerStd struct {
 //
 // TimeFormat 日志输出时间格式化格式。
 //
-// FileLine 是否输出调用日志输出的函数和文件位置
+// FileLine 是否输出调用日志输出的函数和文件位置。
+//
+// FlushInterval 周期将缓冲的日志写入输出流，为0时仅在缓冲满和调用Sync时写入，用于未使用app.Run周期Sync的场景，
+// 关闭Writer前需要调用Close方法停止周期写入。
+//
+// Rotate 指定按时间切割的策略，例如每天零点切割。
+//
+// Levels 指定级别日志的独立输出文件，例如Error以上级别日志额外写入error.log。
+//
+// Fingerprint 是否为Error和Fatal级别日志添加fingerprint属性，使用stack属性或调用栈和消息计算，用于日志聚合系统对相同错误分组。
 type LoggerStdConfig struct {
-	Writer     LoggerWriter `json:"-" alias:"writer"`
-	Std        bool         `json:"std" alias:"std"`
-	Path       string       `json:"path" alias:"path"`
-	MaxSize    uint64       `json:"maxsize" alias:"maxsize"`
-	Link       string       `json:"link" alias:"link"`
-	Level      LoggerLevel  `json:"level" alias:"level"`
-	TimeFormat string       `json:"timeformat" alias:"timeformat"`
-	FileLine   bool         `json:"fileline" alias:"fileline"`
+	Writer        LoggerWriter           `json:"-" alias:"writer"`
+	Std           bool                   `json:"std" alias:"std"`
+	Path          string                 `json:"path" alias:"path"`
+	MaxSize       uint64                 `json:"maxsize" alias:"maxsize"`
+	Link          string                 `json:"link" alias:"link"`
+	Level         LoggerLevel            `json:"level" alias:"level"`
+	TimeFormat    string                 `json:"timeformat" alias:"timeformat"`
+	FileLine      bool                   `json:"fileline" alias:"fileline"`
+	FlushInterval time.Duration          `json:"flushinterval" alias:"flushinterval"`
+	Rotate        LoggerRotateConfig     `json:"rotate" alias:"rotate"`
+	Levels        []LoggerStdLevelConfig `json:"levels" alias:"levels"`
+	Fingerprint   bool                   `json:"fingerprint" alias:"fingerprint"`
+}
+
+// LoggerStdLevelConfig 定义指定级别日志的输出文件，使用独立的切割配置。
+//
+// Level 日志级别大于等于Level时写入Path。
+//
+// Path、MaxSize、Link、Rotate 含义与LoggerStdConfig相同。
+//
+// Exclusive 为true时匹配的日志不再写入默认输出。
+type LoggerStdLevelConfig struct {
+	Level     LoggerLevel        `json:"level" alias:"level"`
+	Path      string             `json:"path" alias:"path"`
+	MaxSize   uint64             `json:"maxsize" alias:"maxsize"`
+	Link      string             `json:"link" alias:"link"`
+	Rotate    LoggerRotateConfig `json:"rotate" alias:"rotate"`
+	Exclusive bool               `json:"exclusive" alias:"exclusive"`
 }
 
 // 标准日志条目
@@ -80,17 +117,38 @@ type entryStd struct {
 	time       time.Time
 	message    string
 	data       []byte
+	buf        []byte
 	timeformat string
 	depth      int
 	logout     bool
+	// put 为ContextDebug模式下条目是否已经放回，放回后使用条目输出Warning日志。
+	put int32
+	// stack 保存stack属性的值，fingerprint记录是否已经设置fingerprint属性。
+	stack       []string
+	fingerprint bool
 }
 
 // NewLoggerStd 创建一个标准日志处理器。
 //
 // 参数为一个eudore.LoggerStdConfig或map保存的创建配置,配置选项含义参考eudore.LoggerStdConfig说明。
+//
+// 初始化输出流失败时panic，需要处理错误时使用NewLoggerStd2函数。
 func NewLoggerStd(arg interface{}) Logger {
+	log, err := NewLoggerStd2(arg)
+	if err != nil {
+		panic(err)
+	}
+	return log
+}
+
+// NewLoggerStd2 function creates a standard logger, and returns an error if the output initialization fails.
+//
+// NewLoggerStd2 函数创建一个标准日志处理器，初始化输出流失败时返回错误，参数同NewLoggerStd函数。
+//
+// 可以使用eudore.NewAppInitOption(eudore.NewLoggerStd2(arg))加载到app，错误在启动前合并报告。
+func NewLoggerStd2(arg interface{}) (Logger, error) {
 	// 解析配置
-	log := &loggerStd{}
+	log := &loggerStd{Stat: &LoggerStat{}}
 	log.TimeFormat = "2006-01-02 15:04:05"
 	ConvertTo(arg, &log.LoggerStdConfig)
 	logdepath := 4
@@ -102,33 +160,80 @@ func NewLoggerStd(arg interface{}) Logger {
 			logger:     log,
 			timeformat: log.TimeFormat,
 			data:       make([]byte, 0, 2048),
+			buf:        make([]byte, 0, 2048),
 			depth:      logdepath,
 		}
 	}
 	log.entryStd = log.Pool.Get().(*entryStd)
 	log.entryStd.logout = true
-	log.initOut()
-	return log
+	if err := log.initOut(); err != nil {
+		return nil, fmt.Errorf(ErrFormatLoggerStdInit, err)
+	}
+	if log.FlushInterval > 0 {
+		log.done = make(chan struct{})
+		log.flush.Add(1)
+		go log.flushLoop(log.FlushInterval)
+	}
+	return log, nil
 }
 
 // initOut 方法初始化输出流。
-func (log *loggerStd) initOut() {
-	if log.LoggerStdConfig.Writer != nil {
-		log.Writer = log.LoggerStdConfig.Writer
-		return
-	}
+func (log *loggerStd) initOut() error {
 	var err error
-	log.Writer, err = NewLoggerWriterRotate(strings.TrimSpace(log.Path), log.Std, log.MaxSize, newLoggerLinkName(log.Link))
-	if err != nil {
-		panic(err)
+	log.Writer = log.LoggerStdConfig.Writer
+	if log.Writer == nil {
+		log.Writer, err = NewLoggerWriterRotateConfig(strings.TrimSpace(log.Path), log.Std, log.MaxSize, log.Rotate, newLoggerLinkName(log.Link))
+		if err != nil {
+			return err
+		}
+	}
+	if len(log.Levels) > 0 {
+		log.Writer, err = NewLoggerWriterLevel(log.Writer, log.Levels...)
+		if err != nil {
+			return err
+		}
 	}
+	if w, ok := log.Writer.(loggerWriterStat); ok {
+		w.setStat(log.Stat)
+	}
+	return nil
 }
 
-// SetLevel 方法设置日志输出级别。
+// SetLevel 方法设置日志输出级别，可以与日志输出并发调用。
 func (log *loggerStd) SetLevel(level LoggerLevel) {
-	log.Mutex.Lock()
-	log.Level = level
-	log.Mutex.Unlock()
+	atomic.StoreInt32((*int32)(&log.Level), int32(level))
+}
+
+// flushLoop 方法周期将Writer的缓冲写入输出流，不执行文件Sync，Close后退出。
+func (log *loggerStd) flushLoop(interval time.Duration) {
+	defer log.flush.Done()
+	flusher, ok := log.Writer.(interface{ Flush() error })
+	if !ok {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-log.done:
+			return
+		case <-ticker.C:
+			log.Mutex.Lock()
+			flusher.Flush()
+			log.Mutex.Unlock()
+		}
+	}
+}
+
+// Close 方法停止FlushInterval周期写入并将缓冲写入到输出流，不关闭Writer，重复调用会被忽略。
+func (log *loggerStd) Close() error {
+	log.once.Do(func() {
+		if log.done != nil {
+			close(log.done)
+		}
+	})
+	log.flush.Wait()
+	return log.Sync()
 }
 
 // Sync 方法将缓冲写入到输出流。
@@ -139,22 +244,79 @@ func (log *loggerStd) Sync() error {
 	return err
 }
 
+// setContextDebug 方法设置ContextDebug，记录条目取出和放回数量。
+func (log *loggerStd) setContextDebug(cd *ContextDebug) {
+	log.debug = cd
+}
+
+// checkPut 方法在ContextDebug模式下检查条目是否已经放回，已放回时输出Warning日志并返回true。
+func (entry *entryStd) checkPut(method string) bool {
+	if entry.logger.debug != nil && atomic.LoadInt32(&entry.put) == 1 {
+		entry.logger.debug.reportEntry(method)
+		return true
+	}
+	return false
+}
+
+// getEntry 方法复制当前条目的属性创建一个新条目，当前条目只读。
 func (entry *entryStd) getEntry() *entryStd {
+	if entry.logger.debug != nil {
+		atomic.AddInt64(&entry.logger.debug.EntryCheckouts, 1)
+	}
 	newentry := entry.logger.Pool.Get().(*entryStd)
 	newentry.time = time.Now()
-	newentry.level = entry.logger.Level
+	newentry.level = LoggerLevel(atomic.LoadInt32((*int32)(&entry.logger.Level)))
 	newentry.depth = entry.depth
-	if len(entry.data) != 0 {
-		newentry.data = newentry.data[:len(entry.data)]
-		copy(newentry.data, entry.data)
-	}
+	newentry.logout = false
+	newentry.data = append(newentry.data[0:0], entry.data...)
+	newentry.stack = entry.stack
+	newentry.fingerprint = entry.fingerprint
 	return newentry
 }
 
+// putEntry 方法在锁外格式化条目，加锁后一次写入Writer，然后将条目放回池中。
 func (entry *entryStd) putEntry() {
+	misuse := entry.checkPut("Logger output")
+	atomic.StoreInt32(&entry.put, 0)
+	if entry.depth > 0 {
+		name, file, line := logFormatNameFileLine(entry.depth - 1)
+		entry.WithField("name", name)
+		entry.WithField("file", file)
+		entry.WithField("line", line)
+	}
+	if entry.level >= LogError && entry.logger.Fingerprint && !entry.fingerprint {
+		stack := entry.stack
+		if stack == nil {
+			depth := entry.depth
+			if depth < 0 {
+				depth += 0x40
+			}
+			stack = GetPanicStack(depth)
+		}
+		entry.WithField("fingerprint", GetErrorFingerprint(entry.message, stack))
+	}
+	entry.format()
+	atomic.AddInt64(&entry.logger.Stat.Entries[entry.level], 1)
+	atomic.AddInt64(&entry.logger.Stat.Bytes, int64(len(entry.buf)))
 	entry.logger.Mutex.Lock()
-	entry.writeTo(entry.logger.Writer)
+	if w, ok := entry.logger.Writer.(loggerWriterLevel); ok {
+		w.WriteLevel(entry.level, entry.buf)
+	} else {
+		entry.logger.Writer.Write(entry.buf)
+	}
 	entry.logger.Mutex.Unlock()
+	entry.data = entry.data[0:0]
+	entry.buf = entry.buf[0:0]
+	entry.stack = nil
+	entry.fingerprint = false
+	if debug := entry.logger.debug; debug != nil {
+		// 调试模式下不回收条目，标记为已放回用于检测误用。
+		if !misuse {
+			atomic.AddInt64(&debug.EntryPuts, 1)
+		}
+		atomic.StoreInt32(&entry.put, 1)
+		return
+	}
 	entry.logger.Pool.Put(entry)
 }
 
@@ -281,6 +443,7 @@ func (entry *entryStd) Fatalf(format string, args ...interface{}) {
 // WithFields 方法设置多个条目属性。
 func (entry *entryStd) WithFields(fields Fields) Logout {
 	if fields == nil {
+		entry.checkPut("Logger.WithFields")
 		entry = entry.getEntry()
 		entry.logout = true
 		return entry
@@ -299,6 +462,7 @@ func (entry *entryStd) WithField(key string, value interface{}) Logout {
 	if entry.logout {
 		entry = entry.getEntry()
 	}
+	entry.checkPut("Logger.WithField")
 	switch key {
 	case "depth":
 		val, ok := value.(int)
@@ -322,6 +486,10 @@ func (entry *entryStd) WithField(key string, value interface{}) Logout {
 			entry.time = val
 			return entry
 		}
+	case "stack":
+		entry.stack, _ = value.([]string)
+	case "fingerprint":
+		entry.fingerprint = true
 	}
 	entry.data = append(entry.data, '"')
 	entry.data = append(entry.data, key...)
@@ -506,38 +674,29 @@ func (entry *entryStd) tryAddRuneError(r rune, size int) bool {
 	return false
 }
 
-// writeTo 将数据写入到输出。
-func (entry *entryStd) writeTo(w io.Writer) {
-	w.Write(part1)
-	timestr := time.Now().Format(entry.timeformat)
-	w.Write(*(*[]byte)(unsafe.Pointer(&timestr)))
-	w.Write(part2)
-	w.Write(levels[entry.level])
-
-	if entry.depth > 0 {
-		name, file, line := logFormatNameFileLine(entry.depth)
-		entry.WithField("name", name)
-		entry.WithField("file", file)
-		entry.WithField("line", line)
-	}
+// format 方法将条目格式化为一行json写入buf。
+func (entry *entryStd) format() {
+	entry.buf = append(entry.buf, part1...)
+	entry.buf = entry.time.AppendFormat(entry.buf, entry.timeformat)
+	entry.buf = append(entry.buf, part2...)
+	entry.buf = append(entry.buf, levels[entry.level]...)
 
 	if len(entry.data) > 1 {
-		w.Write(part3)
+		entry.buf = append(entry.buf, part3...)
 		entry.data[len(entry.data)-1] = '}'
-		w.Write(entry.data)
-		entry.data = entry.data[0:0]
+		entry.buf = append(entry.buf, entry.data...)
 	} else {
-		w.Write(part4)
+		entry.buf = append(entry.buf, part4...)
 	}
 
 	if len(entry.message) > 0 {
-		w.Write(part5)
-		entry.writeString(entry.message)
-		w.Write(entry.data)
+		entry.buf = append(entry.buf, part5...)
 		entry.data = entry.data[0:0]
-		w.Write(part6)
+		entry.writeString(entry.message)
+		entry.buf = append(entry.buf, entry.data...)
+		entry.buf = append(entry.buf, part6...)
 	} else {
-		w.Write(part7)
+		entry.buf = append(entry.buf, part7...)
 	}
 }
 
@@ -552,6 +711,18 @@ type syncWriterFile struct {
 	file *os.File
 }
 
+// loggerWriterLevel 定义按日志级别写入的日志写入流，loggerStd写入日志时传递日志级别。
+type loggerWriterLevel interface {
+	WriteLevel(LoggerLevel, []byte) (int, error)
+}
+
+type syncWriterLevel struct {
+	LoggerWriter
+	writers   []LoggerWriter
+	levels    []LoggerLevel
+	exclusive []bool
+}
+
 type syncWriterRotate struct {
 	name      string
 	std       bool
@@ -559,11 +730,58 @@ type syncWriterRotate struct {
 	nextindex int
 	nexttime  time.Time
 	nbytes    uint64
+	config    LoggerRotateConfig
+	location  *time.Location
+	retrytime time.Time
+	stat      *LoggerStat
 	*bufio.Writer
 	file  *os.File
 	newfn []func(string)
 }
 
+// loggerWriterStat 定义可以设置日志统计的日志写入流。
+type loggerWriterStat interface {
+	setStat(*LoggerStat)
+}
+
+// LoggerStat 定义日志写入统计，使用原子操作更新，使用eudore.Get(app.Logger, "stat")获取LoggerStd的统计。
+//
+// Entries 各级别写入的日志条目数量，Bytes 写入的日志字节数，Rotations 文件切割次数。
+//
+// WriteErrors 写入或打开文件失败次数，Fallbacks 写入失败后未写入文件的字节数，Std为false时写入os.Stderr，
+// Reopens 写入失败后重新打开文件成功的次数。
+type LoggerStat struct {
+	Entries     [5]int64 `json:"entries"`
+	Bytes       int64    `json:"bytes"`
+	Rotations   int64    `json:"rotations"`
+	WriteErrors int64    `json:"writeerrors"`
+	Fallbacks   int64    `json:"fallbacks"`
+	Reopens     int64    `json:"reopens"`
+}
+
+// HandleHTTP 方法返回日志统计，Entries使用日志级别名称作为key，用于监控系统采集并对Error日志数量突增告警。
+func (stat *LoggerStat) HandleHTTP(ctx Context) {
+	entries := make(map[string]int64, len(stat.Entries))
+	for i := range stat.Entries {
+		entries[LogLevelString[i]] = atomic.LoadInt64(&stat.Entries[i])
+	}
+	ctx.Render(map[string]interface{}{
+		"entries":     entries,
+		"bytes":       atomic.LoadInt64(&stat.Bytes),
+		"rotations":   atomic.LoadInt64(&stat.Rotations),
+		"writeerrors": atomic.LoadInt64(&stat.WriteErrors),
+		"fallbacks":   atomic.LoadInt64(&stat.Fallbacks),
+		"reopens":     atomic.LoadInt64(&stat.Reopens),
+	})
+}
+
+// loggerWriterFunc 定义函数实现io.Writer接口。
+type loggerWriterFunc func([]byte) (int, error)
+
+func (fn loggerWriterFunc) Write(p []byte) (int, error) {
+	return fn(p)
+}
+
 // NewLoggerWriterStd 函数返回一个标准输出流的日志写入流。
 func NewLoggerWriterStd() LoggerWriter {
 	return os.Stdout
@@ -574,8 +792,9 @@ func NewLoggerWriterFile(name string, std bool) (LoggerWriter, error) {
 	if name == "" {
 		return NewLoggerWriterStd(), nil
 	}
-	os.MkdirAll(filepath.Dir(name), 0644)
-	file, err := os.OpenFile(formatDateName(name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	name = formatDateName(filepath.FromSlash(name), time.Now())
+	os.MkdirAll(filepath.Dir(name), 0755)
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	if err != nil {
 		return nil, err
 	}
@@ -591,49 +810,146 @@ func (w syncWriterFile) Sync() error {
 	return w.file.Sync()
 }
 
+// NewLoggerWriterLevel 函数创建一个按日志级别写入的日志写入流，默认写入w，匹配级别的日志写入对应的切割文件。
+func NewLoggerWriterLevel(w LoggerWriter, configs ...LoggerStdLevelConfig) (LoggerWriter, error) {
+	lw := &syncWriterLevel{LoggerWriter: w}
+	for _, config := range configs {
+		writer, err := NewLoggerWriterRotateConfig(strings.TrimSpace(config.Path), false, config.MaxSize, config.Rotate, newLoggerLinkName(config.Link))
+		if err != nil {
+			lw.Sync()
+			return nil, err
+		}
+		lw.writers = append(lw.writers, writer)
+		lw.levels = append(lw.levels, config.Level)
+		lw.exclusive = append(lw.exclusive, config.Exclusive)
+	}
+	return lw, nil
+}
+
+// WriteLevel 方法写入日志数据到级别匹配的写入流，没有独占匹配时同时写入默认写入流。
+func (w *syncWriterLevel) WriteLevel(level LoggerLevel, p []byte) (n int, err error) {
+	exclusive := false
+	for i, writer := range w.writers {
+		if level >= w.levels[i] {
+			n, err = writer.Write(p)
+			exclusive = exclusive || w.exclusive[i]
+		}
+	}
+	if !exclusive {
+		n, err = w.LoggerWriter.Write(p)
+	}
+	return
+}
+
+func (w *syncWriterLevel) setStat(stat *LoggerStat) {
+	for i := 0; i <= len(w.writers); i++ {
+		if writer, ok := w.writer(i).(loggerWriterStat); ok {
+			writer.setStat(stat)
+		}
+	}
+}
+
+// Flush 方法将全部写入流的缓冲数据写入输出，不执行文件Sync。
+func (w *syncWriterLevel) Flush() error {
+	var err error
+	for i := 0; i <= len(w.writers); i++ {
+		if flusher, ok := w.writer(i).(interface{ Flush() error }); ok {
+			if e := flusher.Flush(); e != nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+// Sync 方法将全部写入流的缓冲数据写入到文件。
+func (w *syncWriterLevel) Sync() error {
+	var err error
+	for i := 0; i <= len(w.writers); i++ {
+		if e := w.writer(i).Sync(); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// writer 方法返回索引的级别写入流，索引等于级别写入流数量时返回默认写入流。
+func (w *syncWriterLevel) writer(i int) LoggerWriter {
+	if i < len(w.writers) {
+		return w.writers[i]
+	}
+	return w.LoggerWriter
+}
+
 // NewLoggerWriterRotate 函数创建一个支持文件切割的的日志写入流。
+//
+// 文件名称包含日期格式时每小时检查名称变化并切换文件，使用NewLoggerWriterRotateConfig指定按时间切割策略。
 func NewLoggerWriterRotate(name string, std bool, maxsize uint64, fn ...func(string)) (LoggerWriter, error) {
+	return NewLoggerWriterRotateConfig(name, std, maxsize, LoggerRotateConfig{}, fn...)
+}
+
+// NewLoggerWriterRotateConfig 函数创建一个按大小和时间切割的日志写入流，时间切割策略参考LoggerRotateConfig说明。
+func NewLoggerWriterRotateConfig(name string, std bool, maxsize uint64, config LoggerRotateConfig, fn ...func(string)) (LoggerWriter, error) {
+	location, err := config.location()
+	if err != nil {
+		return nil, err
+	}
+	if config.Period != "" && config.step(time.Now()).IsZero() {
+		return nil, fmt.Errorf(ErrFormatLoggerRotatePeriod, config.Period)
+	}
 	if strings.Index(name, "index") == -1 {
 		maxsize = 0
 	}
+	if name == "" {
+		return NewLoggerWriterStd(), nil
+	}
+	name = filepath.FromSlash(name)
 	if maxsize <= 0 {
-		if name == formatDateName(name) {
-			return NewLoggerWriterFile(name, std)
-		}
 		maxsize = 0xffffffff
 	}
 	lw := &syncWriterRotate{
 		name:     name,
 		std:      std,
 		MaxSize:  maxsize,
-		nexttime: getNextHour(),
+		config:   config,
+		location: location,
+		stat:     &LoggerStat{},
 		newfn:    fn,
 	}
+	lw.Writer = bufio.NewWriter(loggerWriterFunc(lw.writeFile))
+	lw.nexttime = config.next(time.Now().In(location))
 	return lw, lw.rotateFile()
 }
 
+func (w *syncWriterRotate) setStat(stat *LoggerStat) {
+	w.stat = stat
+}
+
 // Sync 方法将缓冲数据写入到文件。
 func (w *syncWriterRotate) Sync() error {
+	w.Flush()
 	if w.file == nil {
 		return nil
 	}
-	w.Flush()
 	return w.file.Sync()
 }
 
-// Write 方法写入日志数据。
+// Write 方法写入日志数据，每次写入一条完整日志，在日志之间执行切割。
+//
+// 文件写入失败后日志写入os.Stderr，间隔DefaultLoggerWriterRetry尝试重新打开文件。
 func (w *syncWriterRotate) Write(p []byte) (n int, err error) {
-	if len(p) == 9 && w.nbytes+uint64(len(p)) >= w.MaxSize && string(p) == string(part1) {
+	now := time.Now()
+	switch {
+	case w.file == nil:
+		if now.After(w.retrytime) {
+			w.reopenFile(now)
+		}
+	case now.After(w.nexttime):
+		w.rotateTime(now.In(w.location))
+	case w.nbytes+uint64(len(p)) >= w.MaxSize && strings.Contains(w.name, "index"):
 		// 执行size滚动
 		w.rotateFile()
-	}
-	if time.Now().After(w.nexttime) {
-		w.nexttime = getNextHour()
-		// 检查时间变化
-		if strings.Replace(formatDateName(w.name), "index", fmt.Sprint(w.nextindex-1), -1) != w.file.Name() {
-			w.nextindex = 0
-			w.rotateFile()
-		}
+		atomic.AddInt64(&w.stat.Rotations, 1)
 	}
 	n, err = w.Writer.Write(p)
 	if w.std {
@@ -643,11 +959,85 @@ func (w *syncWriterRotate) Write(p []byte) (n int, err error) {
 	return
 }
 
+// rotateTime 方法到达时间边界时切割文件。
+//
+// 文件名称包含日期格式时使用新名称，包含index时使用新索引，否则将当前文件重命名为带周期时间后缀的名称。
+func (w *syncWriterRotate) rotateTime(now time.Time) {
+	start := w.nexttime
+	w.nexttime = w.config.next(now)
+	if w.config.Period == "" {
+		// 兼容未指定周期时每小时检查文件名称变化。
+		if strings.Replace(formatDateName(w.name, now), "index", fmt.Sprint(w.nextindex-1), -1) != w.file.Name() {
+			w.nextindex = 0
+			w.rotateFile()
+			atomic.AddInt64(&w.stat.Rotations, 1)
+		}
+		return
+	}
+
+	switch {
+	case w.formatName() != formatDateName(w.name, w.config.prev(start)):
+		w.nextindex = 0
+	case strings.Contains(w.name, "index"):
+	default:
+		// 文件名称不包含日期和索引，归档当前文件。
+		name := w.formatName()
+		w.Sync()
+		w.file.Close()
+		w.file = nil
+		os.Rename(name, w.config.archiveName(name, w.config.prev(start)))
+	}
+	w.rotateFile()
+	atomic.AddInt64(&w.stat.Rotations, 1)
+}
+
+// formatName 方法格式化文件名称中的日期，指定切割周期时使用当前周期的开始时间。
+func (w *syncWriterRotate) formatName() string {
+	if w.config.Period != "" {
+		return formatDateName(w.name, w.config.prev(w.nexttime))
+	}
+	return formatDateName(w.name, time.Now().In(w.location))
+}
+
+// writeFile 方法将bufio缓冲写入文件，写入失败时关闭文件，将未写入的数据写入os.Stderr，不返回错误避免bufio停止写入。
+func (w *syncWriterRotate) writeFile(p []byte) (int, error) {
+	if w.file != nil {
+		n, err := w.file.Write(p)
+		if err == nil {
+			return n, nil
+		}
+		atomic.AddInt64(&w.stat.WriteErrors, 1)
+		fmt.Fprintf(os.Stderr, "eudore logger write file %s error: %v, fallback to stderr\n", w.file.Name(), err)
+		w.file.Close()
+		w.file = nil
+		w.retrytime = time.Now().Add(DefaultLoggerWriterRetry)
+		p = p[n:]
+	}
+	if !w.std {
+		os.Stderr.Write(p)
+	}
+	atomic.AddInt64(&w.stat.Fallbacks, int64(len(p)))
+	return len(p), nil
+}
+
+// reopenFile 方法在写入失败后重新打开文件。
+func (w *syncWriterRotate) reopenFile(now time.Time) {
+	w.Flush()
+	w.nexttime = w.config.next(now.In(w.location))
+	if err := w.rotateFile(); err != nil {
+		atomic.AddInt64(&w.stat.WriteErrors, 1)
+		w.retrytime = now.Add(DefaultLoggerWriterRetry)
+		return
+	}
+	atomic.AddInt64(&w.stat.Reopens, 1)
+	fmt.Fprintf(os.Stderr, "eudore logger reopen file %s\n", w.file.Name())
+}
+
 func (w *syncWriterRotate) rotateFile() error {
-	name := formatDateName(w.name)
+	name := w.formatName()
 	for {
 		name := strings.Replace(name, "index", fmt.Sprint(w.nextindex), -1)
-		os.MkdirAll(filepath.Dir(name), 0644)
+		os.MkdirAll(filepath.Dir(name), 0755)
 		file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 		if err != nil {
 			return err
@@ -659,7 +1049,6 @@ func (w *syncWriterRotate) rotateFile() error {
 		if w.nbytes < w.MaxSize {
 			w.Sync()
 			w.file.Close()
-			w.Writer = bufio.NewWriter(file)
 			w.file = file
 			for _, fn := range w.newfn {
 				fn(name)
@@ -670,32 +1059,118 @@ func (w *syncWriterRotate) rotateFile() error {
 	}
 }
 
-func formatDateName(name string) string {
-	now := time.Now()
-	name = strings.Replace(name, "yyyy", "2006", 1)
-	name = strings.Replace(name, "yy", "06", 1)
-	name = strings.Replace(name, "MM", "01", 1)
-	name = strings.Replace(name, "dd", "02", 1)
-	name = strings.Replace(name, "HH", "15", 1)
-	return now.Format(name)
+// LoggerRotateConfig 定义日志按时间切割的策略，与文件名称中的日期格式无关。
+//
+// Period 切割周期，可以为hourly、daily、weekly，为空时每小时检查文件名称中日期格式的变化。
+//
+// Offset 切割时间相对周期开始的偏移，例如daily周期使用4h在每天04:00切割，weekly周期从周一00:00开始。
+//
+// Location 切割时间和文件名称日期使用的时区名称，例如Asia/Shanghai，默认使用本地时区。
+type LoggerRotateConfig struct {
+	Period   string        `json:"period" alias:"period"`
+	Offset   time.Duration `json:"offset" alias:"offset"`
+	Location string        `json:"location" alias:"location"`
 }
 
-func getNextHour() time.Time {
-	now := time.Now()
-	return time.Date(now.Year(), now.Month(), now.Day(), now.Hour()+1, 0, 0, 0, now.Location())
+func (config LoggerRotateConfig) location() (*time.Location, error) {
+	if config.Location == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(config.Location)
 }
 
+// start 方法返回时间所在周期的开始时间，未指定周期时使用小时。
+func (config LoggerRotateConfig) start(t time.Time) time.Time {
+	switch config.Period {
+	case "daily":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	case "weekly":
+		return time.Date(t.Year(), t.Month(), t.Day()-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	}
+}
+
+// step 方法返回下一个周期的时间，周期无效时返回零值。
+func (config LoggerRotateConfig) step(t time.Time) time.Time {
+	switch config.Period {
+	case "", "hourly":
+		return t.Add(time.Hour)
+	case "daily":
+		return t.AddDate(0, 0, 1)
+	case "weekly":
+		return t.AddDate(0, 0, 7)
+	}
+	return time.Time{}
+}
+
+// next 方法返回时间之后的下一个切割时间。
+func (config LoggerRotateConfig) next(t time.Time) time.Time {
+	if config.Period == "" {
+		return config.step(config.start(t))
+	}
+	next := config.start(t).Add(config.Offset)
+	for !next.After(t) {
+		next = config.step(next)
+	}
+	return next
+}
+
+// prev 方法返回切割时间的上一个切割时间，用于归档文件名称。
+func (config LoggerRotateConfig) prev(t time.Time) time.Time {
+	switch config.Period {
+	case "daily":
+		return t.AddDate(0, 0, -1)
+	case "weekly":
+		return t.AddDate(0, 0, -7)
+	}
+	return t.Add(-time.Hour)
+}
+
+// archiveName 方法返回归档文件名称，在扩展名前添加周期开始时间。
+func (config LoggerRotateConfig) archiveName(name string, t time.Time) string {
+	layout := "2006-01-02"
+	if config.Period == "hourly" {
+		layout = "2006-01-02-15"
+	}
+	ext := filepath.Ext(name)
+	return name[:len(name)-len(ext)] + "-" + t.Format(layout) + ext
+}
+
+// formatDateName 函数替换名称中的yyyy、yy、MM、dd、HH日期格式，名称的其他部分保持不变。
+func formatDateName(name string, now time.Time) string {
+	return strings.NewReplacer(
+		"yyyy", now.Format("2006"),
+		"yy", now.Format("06"),
+		"MM", now.Format("01"),
+		"dd", now.Format("02"),
+		"HH", now.Format("15"),
+	).Replace(name)
+}
+
+// newLoggerLinkName 函数创建日志文件切换后更新软连接的函数。
+//
+// 平台不支持或没有权限创建软连接时(例如Windows)使用硬链接，硬链接也失败时输出一次警告并跳过。
 func newLoggerLinkName(link string) func(string) {
-	os.MkdirAll(filepath.Dir(link), 0644)
+	if link == "" {
+		return func(string) {}
+	}
+	link = filepath.FromSlash(link)
+	os.MkdirAll(filepath.Dir(link), 0755)
+	var warned bool
 	return func(name string) {
-		if link == "" {
-			return
-		}
-		if name[0] != '/' {
+		if !filepath.IsAbs(name) {
 			pwd, _ := os.Getwd()
 			name = filepath.Join(pwd, name)
 		}
 		os.Remove(link)
-		os.Symlink(name, link)
+		err := os.Symlink(name, link)
+		if err != nil {
+			err = os.Link(name, link)
+		}
+		if err != nil && !warned {
+			warned = true
+			fmt.Fprintf(os.Stderr, "eudore logger create link %s to %s error: %v, skip link\n", link, name, err)
+		}
 	}
 }
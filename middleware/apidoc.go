@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"strings"
+
+	"github.com/eudore/eudore"
+)
+
+// NewAPIDocFunc 函数创建一个api文档页面处理函数，使用路由注册时传入的eudore.RouterDoc生成html页面，
+// 注册到任意路径访问，例如app.GetFunc("/docs", middleware.NewAPIDocFunc(app.Router))。
+//
+// 仅显示存在文档的路由，按Tags分组，没有Tags的路由在default分组；Accept为json时返回分组数据。
+// router需要实现Routes() []eudore.RouterRoute方法，例如eudore.RouterStd。
+func NewAPIDocFunc(router eudore.Router) eudore.HandlerFunc {
+	return func(ctx eudore.Context) {
+		groups := getAPIDocGroups(router)
+		if strings.Contains(ctx.GetHeader(eudore.HeaderAccept), eudore.MimeApplicationJSON) {
+			ctx.Render(groups)
+			return
+		}
+		ctx.SetHeader(eudore.HeaderContentType, eudore.MimeTextHTMLCharsetUtf8)
+		err := apidocTemplate.Execute(ctx, groups)
+		if err != nil {
+			ctx.Fatal(err)
+		}
+	}
+}
+
+type apidocGroup struct {
+	Tag    string        `json:"tag"`
+	Routes []apidocRoute `json:"routes"`
+}
+
+type apidocRoute struct {
+	Method      string      `json:"method"`
+	Path        string      `json:"path"`
+	Summary     string      `json:"summary,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Tags        []string    `json:"tags,omitempty"`
+	Request     interface{} `json:"request,omitempty"`
+	Response    interface{} `json:"response,omitempty"`
+}
+
+// getAPIDocGroups 函数读取路由文档并按Tags分组，分组按第一次出现的顺序排列。
+func getAPIDocGroups(router eudore.Router) []apidocGroup {
+	r, ok := router.(interface{ Routes() []eudore.RouterRoute })
+	if !ok {
+		return nil
+	}
+	var groups []apidocGroup
+	indexs := make(map[string]int)
+	for _, route := range r.Routes() {
+		if route.Doc == nil {
+			continue
+		}
+		doc := apidocRoute{
+			Method:      route.Method,
+			Path:        strings.SplitN(route.Path, " ", 2)[0],
+			Summary:     route.Doc.Summary,
+			Description: route.Doc.Description,
+			Tags:        route.Doc.Tags,
+			Request:     route.Doc.Request,
+			Response:    route.Doc.Response,
+		}
+		tags := route.Doc.Tags
+		if len(tags) == 0 {
+			tags = []string{"default"}
+		}
+		for _, tag := range tags {
+			index, ok := indexs[tag]
+			if !ok {
+				index = len(groups)
+				indexs[tag] = index
+				groups = append(groups, apidocGroup{Tag: tag})
+			}
+			groups[index].Routes = append(groups[index].Routes, doc)
+		}
+	}
+	return groups
+}
+
+// getAPIDocExample 函数格式化示例，字符串和[]byte直接返回，其他类型使用缩进json格式。
+func getAPIDocExample(i interface{}) string {
+	switch val := i.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	}
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(i); err != nil {
+		return err.Error()
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+var apidocTemplate = template.Must(template.New("apidoc").Funcs(template.FuncMap{"example": getAPIDocExample}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>API Docs</title>
+<style>
+body{font-family:sans-serif;margin:0 auto;max-width:960px;padding:16px;color:#333}
+h2{border-bottom:1px solid #ddd;padding-bottom:4px}
+.route{border:1px solid #ddd;border-radius:4px;margin:12px 0;padding:8px 12px}
+.method{display:inline-block;min-width:64px;font-weight:bold;color:#fff;background:#61affe;border-radius:3px;text-align:center;margin-right:8px}
+.path{font-family:monospace;font-size:16px}
+.tag{display:inline-block;background:#eee;border-radius:3px;padding:0 6px;margin-left:4px;font-size:12px}
+pre{background:#f6f8fa;padding:8px;overflow:auto}
+</style>
+</head>
+<body>
+<h1>API Docs</h1>
+{{- range .}}
+<h2 id="{{.Tag}}">{{.Tag}}</h2>
+{{- range .Routes}}
+<div class="route">
+<div><span class="method">{{.Method}}</span><span class="path">{{.Path}}</span>{{range .Tags}}<span class="tag">{{.}}</span>{{end}}</div>
+{{- if .Summary}}<p><b>{{.Summary}}</b></p>{{end}}
+{{- if .Description}}<p>{{.Description}}</p>{{end}}
+{{- if .Request}}<div>Request example:</div><pre>{{example .Request}}</pre>{{end}}
+{{- if .Response}}<div>Response example:</div><pre>{{example .Response}}</pre>{{end}}
+</div>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"crypto/x509"
+	"strings"
+
+	"github.com/eudore/eudore"
+)
+
+// HeaderXApiKey 定义默认读取API Key的Header。
+const HeaderXApiKey = "X-Api-Key"
+
+type (
+	// ApiKeyStore 定义API Key校验，返回Key名称和授权范围，Key无效、过期或吊销时返回错误。
+	ApiKeyStore interface {
+		VerifyApiKey(context.Context, string) (string, []string, error)
+	}
+	// ApiKeyCertificateStore 定义客户端证书校验，ApiKeyStore实现该接口时请求没有API Key使用客户端证书认证。
+	ApiKeyCertificateStore interface {
+		VerifyApiKeyCertificate(context.Context, *x509.Certificate) (string, []string, error)
+	}
+)
+
+// NewApiKeyFunc 函数创建一个API Key认证处理函数，用于服务之间的简单认证，认证成功后设置apikey参数为Key名称。
+//
+// 默认从X-Api-Key Header读取API Key，Header为Authorization时读取Bearer值；
+// 路由参数scope设置路由需要的授权范围，多个使用','分割并需要全部拥有，授权范围"*"表示拥有全部授权。
+//
+// 缺少凭证或凭证无效返回401，缺少授权范围返回403。
+//
+// options:
+// string    =>    读取API Key的Header，默认为X-Api-Key
+func NewApiKeyFunc(store ApiKeyStore, options ...interface{}) eudore.HandlerFunc {
+	header := HeaderXApiKey
+	for _, i := range options {
+		if val, ok := i.(string); ok {
+			header = val
+		}
+	}
+	certstore, _ := store.(ApiKeyCertificateStore)
+	return func(ctx eudore.Context) {
+		key := ctx.GetHeader(header)
+		if header == eudore.HeaderAuthorization {
+			if !strings.HasPrefix(key, "Bearer ") {
+				key = ""
+			}
+			key = strings.TrimPrefix(key, "Bearer ")
+		}
+
+		var name string
+		var scopes []string
+		var err error
+		switch {
+		case key != "":
+			name, scopes, err = store.VerifyApiKey(ctx.GetContext(), key)
+		case certstore != nil && ctx.Request().TLS != nil && len(ctx.Request().TLS.PeerCertificates) > 0:
+			name, scopes, err = certstore.VerifyApiKeyCertificate(ctx.GetContext(), ctx.Request().TLS.PeerCertificates[0])
+		default:
+			ctx.SetHeader(eudore.HeaderWWWAuthenticate, "ApiKey")
+			writeFatal(ctx, eudore.StatusUnauthorized, "apikey is required")
+			return
+		}
+		if err != nil {
+			ctx.SetHeader(eudore.HeaderWWWAuthenticate, "ApiKey")
+			writeFatal(ctx, eudore.StatusUnauthorized, err.Error())
+			return
+		}
+
+		for _, scope := range strings.Split(ctx.GetParam("scope"), ",") {
+			scope = strings.TrimSpace(scope)
+			if scope != "" && !hasApiKeyScope(scopes, scope) {
+				writeFatal(ctx, eudore.StatusForbidden, "apikey "+name+" missing scope: "+scope)
+				return
+			}
+		}
+		ctx.SetParam("apikey", name)
+	}
+}
+
+func hasApiKeyScope(scopes []string, scope string) bool {
+	for _, i := range scopes {
+		if i == scope || i == "*" {
+			return true
+		}
+	}
+	return false
+}
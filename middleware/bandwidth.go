@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// NewBandwidthFunc 函数创建一个响应带宽限制处理函数，rate为每秒允许写入的字节数，使用令牌桶限制响应写入速度。
+//
+// 路由参数bandwidth可以设置组路由或路由的速度，bandwidth=0表示不限制；
+// 默认按连接限制，同一个连接的请求共享速度，key函数返回相同key的全部请求共享速度，例如按ip或用户限制。
+//
+// options:
+// context.Context               =>    控制cleanupBuckets退出的生命周期
+// func(eudore.Context) string   =>    获取限制key的函数，默认使用连接地址
+func NewBandwidthFunc(rate int64, options ...interface{}) eudore.HandlerFunc {
+	b := &bandwidth{
+		buckets: make(map[string]*bandwidthBucket),
+		GetKeyFunc: func(ctx eudore.Context) string {
+			return ctx.Request().RemoteAddr
+		},
+	}
+	ctx := context.Background()
+	for _, i := range options {
+		switch val := i.(type) {
+		case context.Context:
+			ctx = val
+		case func(eudore.Context) string:
+			b.GetKeyFunc = val
+		}
+	}
+	go b.cleanupBuckets(ctx)
+	return func(ctx eudore.Context) {
+		limit := rate
+		if val := ctx.GetParam("bandwidth"); val != "" {
+			limit = eudore.GetStringInt64(val)
+		}
+		if limit <= 0 {
+			return
+		}
+
+		w := &bandwidthResponse{
+			ResponseWriter: ctx.Response(),
+			ctx:            ctx.GetContext(),
+			bucket:         b.GetBucket(b.GetKeyFunc(ctx)),
+			rate:           limit,
+		}
+		ctx.SetResponse(w)
+		ctx.Next()
+		ctx.SetResponse(w.ResponseWriter)
+	}
+}
+
+// bandwidth 定义带宽限制器，保存每个key的令牌桶。
+type bandwidth struct {
+	sync.Mutex
+	buckets    map[string]*bandwidthBucket
+	GetKeyFunc func(eudore.Context) string
+}
+
+// GetBucket 方法通过key获得bandwidthBucket。
+func (b *bandwidth) GetBucket(key string) *bandwidthBucket {
+	b.Lock()
+	defer b.Unlock()
+	bucket, ok := b.buckets[key]
+	if !ok {
+		bucket = &bandwidthBucket{}
+		b.buckets[key] = bucket
+	}
+	return bucket
+}
+
+// cleanupBuckets 方法每分钟清理一次一分钟内没有写入的令牌桶。
+func (b *bandwidth) cleanupBuckets(ctx context.Context) {
+	for {
+		select {
+		case now := <-time.After(time.Minute):
+			dead := now.UnixNano() - int64(time.Minute)
+			b.Lock()
+			for key, bucket := range b.buckets {
+				bucket.Lock()
+				if bucket.last < dead {
+					delete(b.buckets, key)
+				}
+				bucket.Unlock()
+			}
+			b.Unlock()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// bandwidthBucket 定义字节令牌桶，last为令牌用完的时间，最多累积100ms的令牌。
+type bandwidthBucket struct {
+	sync.Mutex
+	last int64
+}
+
+// Wait 方法消耗写入n字节的令牌，令牌不足时等待，ctx结束时返回false。
+func (bucket *bandwidthBucket) Wait(ctx context.Context, n, rate int64) bool {
+	bucket.Lock()
+	now := time.Now().UnixNano()
+	if min := now - int64(100*time.Millisecond); bucket.last < min {
+		bucket.last = min
+	}
+	bucket.last += n * int64(time.Second) / rate
+	wait := time.Duration(bucket.last - now)
+	bucket.Unlock()
+	if wait <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// bandwidthResponse 定义限制写入速度的响应，每次最多写入100ms的数据，使等待间隔平滑。
+type bandwidthResponse struct {
+	eudore.ResponseWriter
+	ctx    context.Context
+	bucket *bandwidthBucket
+	rate   int64
+}
+
+// Write 实现ResponseWriter中的Write方法。
+func (w *bandwidthResponse) Write(data []byte) (int, error) {
+	size := int(w.rate / 10)
+	if size < 512 {
+		size = 512
+	}
+	var n int
+	for len(data) > 0 {
+		chunk := data
+		if len(chunk) > size {
+			chunk = chunk[:size]
+		}
+		if !w.bucket.Wait(w.ctx, int64(len(chunk)), w.rate) {
+			return n, w.ctx.Err()
+		}
+		m, err := w.ResponseWriter.Write(chunk)
+		n += m
+		if err != nil {
+			return n, err
+		}
+		data = data[m:]
+	}
+	return n, nil
+}
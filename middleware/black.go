@@ -2,21 +2,24 @@ package middleware
 
 import (
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/eudore/eudore"
 )
 
 // Black 定义黑名单中间件后台。
-type black struct {
+type Black struct {
+	sync.Mutex
 	White *BlackNode
 	Black *BlackNode
 }
 
 // newBlack 函数创建一个黑名单后台。
-func newBlack() *black {
-	return &black{
+func newBlack() *Black {
+	return &Black{
 		White: new(BlackNode),
 		Black: new(BlackNode),
 	}
@@ -24,6 +27,15 @@ func newBlack() *black {
 
 // NewBlackFunc 函数创建一个黑名单处理函数，传入map[string]bool类型参数记录初始化使用的黑/白名单，白名单值为true/黑名单值为false。
 func NewBlackFunc(data map[string]bool, router eudore.Router) eudore.HandlerFunc {
+	b := NewBlack(data)
+	if router != nil {
+		b.InjectRoutes(router)
+	}
+	return b.HandleHTTP
+}
+
+// NewBlack 函数创建一个黑名单，可以和NewConnGuard共享黑名单存储，data参数同NewBlackFunc函数。
+func NewBlack(data map[string]bool) *Black {
 	b := newBlack()
 	for k, v := range data {
 		if v {
@@ -32,14 +44,11 @@ func NewBlackFunc(data map[string]bool, router eudore.Router) eudore.HandlerFunc
 			b.InsertBlack(k)
 		}
 	}
-	if router != nil {
-		b.InjectRoutes(router)
-	}
-	return b.HandleHTTP
+	return b
 }
 
 // InjectRoutes 方法将黑名单后台管理功能注入到路由器中。
-func (b *black) InjectRoutes(router eudore.Router) {
+func (b *Black) InjectRoutes(router eudore.Router) {
 	router.AnyFunc("/black/ui", HandlerAdmin)
 	router.GetFunc("/black/data", b.data)
 	router.PutFunc("/black/white/:ip", b.putIP)
@@ -48,15 +57,17 @@ func (b *black) InjectRoutes(router eudore.Router) {
 	router.DeleteFunc("/black/black/:ip black=black", b.deleteIP)
 }
 
-func (b *black) data(ctx eudore.Context) interface{} {
+func (b *Black) data(ctx eudore.Context) interface{} {
 	ctx.SetHeader("X-Eudore-Admin", "black")
+	b.Lock()
+	defer b.Unlock()
 	return map[string]interface{}{
 		"white": b.White.List(nil, 0, 32),
 		"black": b.Black.List(nil, 0, 32),
 	}
 }
 
-func (b *black) putIP(ctx eudore.Context) {
+func (b *Black) putIP(ctx eudore.Context) {
 	ip := fmt.Sprintf("%s/%s", ctx.GetParam("ip"), eudore.GetString(ctx.GetQuery("mask"), "32"))
 	ctx.Infof("%s insert %s ip: %s", ctx.RealIP(), eudore.GetString(ctx.GetQuery("black"), "white"), ip)
 	if ctx.GetParam("black") != "" {
@@ -66,7 +77,7 @@ func (b *black) putIP(ctx eudore.Context) {
 	}
 }
 
-func (b *black) deleteIP(ctx eudore.Context) {
+func (b *Black) deleteIP(ctx eudore.Context) {
 	ip := fmt.Sprintf("%s/%s", ctx.GetParam("ip"), eudore.GetString(ctx.GetQuery("mask"), "32"))
 	ctx.Infof("%s delete %s ip: %s", ctx.RealIP(), eudore.GetString(ctx.GetQuery("black"), "white"), ip)
 	if ctx.GetParam("black") != "" {
@@ -77,36 +88,61 @@ func (b *black) deleteIP(ctx eudore.Context) {
 }
 
 // HandleHTTP 方法定义黑名单后台的中间件处理函数。
-func (b *black) HandleHTTP(ctx eudore.Context) {
-	ip := ip2int(ctx.RealIP())
-	if b.White.Look(ip) {
-		return
-	}
-	if b.Black.Look(ip) {
+func (b *Black) HandleHTTP(ctx eudore.Context) {
+	if b.Deny(ctx.RealIP()) {
 		ctx.WriteHeader(403)
 		ctx.WriteString("black list deny your ip " + ctx.RealIP())
 		ctx.End()
 	}
 }
 
+// Deny 方法判断ip是否在黑名单并且不在白名单，仅匹配ipv4地址。
+func (b *Black) Deny(ipstr string) bool {
+	if ip := net.ParseIP(ipstr); ip == nil || ip.To4() == nil {
+		return false
+	}
+	ip := ip2int(ipstr)
+	b.Lock()
+	defer b.Unlock()
+	return !b.White.Look(ip) && b.Black.Look(ip)
+}
+
+// IsWhite 方法判断ip是否在白名单，仅匹配ipv4地址。
+func (b *Black) IsWhite(ipstr string) bool {
+	if ip := net.ParseIP(ipstr); ip == nil || ip.To4() == nil {
+		return false
+	}
+	b.Lock()
+	defer b.Unlock()
+	return b.White.Look(ip2int(ipstr))
+}
+
 // InsertWhite 方法新增一个白名单ip或ip段。
-func (b *black) InsertWhite(ip string) {
+func (b *Black) InsertWhite(ip string) {
+	b.Lock()
 	b.White.Insert(ip)
+	b.Unlock()
 }
 
 // InsertBlack 方法新增一个黑名单ip或ip段。
-func (b *black) InsertBlack(ip string) {
+func (b *Black) InsertBlack(ip string) {
+	b.Lock()
 	b.Black.Insert(ip)
+	b.Unlock()
 }
 
 // DeleteWhite 方法删除一个白名单ip或ip段。
-func (b *black) DeleteWhite(ip string) {
+func (b *Black) DeleteWhite(ip string) {
+	b.Lock()
 	b.White.Delete(ip)
+	b.Unlock()
 }
 
 // DeleteBlack 方法删除一个黑名单ip或ip段。
-func (b *black) DeleteBlack(ip string) {
+func (b *Black) DeleteBlack(ip string) {
+	b.Lock()
 	b.Black.Delete(ip)
+	b.Unlock()
 }
 
 // BlackNode 定义黑名单存储树节点。
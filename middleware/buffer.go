@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/eudore/eudore"
+)
+
+// NewBufferFunc 函数创建一个响应缓冲处理函数，缓冲不超过size字节的响应，处理结束后设置Content-Length一次写入。
+//
+// 缓冲期间可以修改状态码，写入大于等于400的状态码时丢弃已经缓冲的body，使后续错误可以返回完整的错误响应；
+// 超过size或调用Flush后切换为流式写入，路由参数buffer可以设置组路由或路由的缓冲大小，buffer=0表示不缓冲。
+func NewBufferFunc(size int) eudore.HandlerFunc {
+	pool := sync.Pool{
+		New: func() interface{} {
+			return &bufferResponse{}
+		},
+	}
+	return func(ctx eudore.Context) {
+		limit := size
+		if val := ctx.GetParam("buffer"); val != "" {
+			limit = eudore.GetStringInt(val)
+		}
+		if limit <= 0 {
+			return
+		}
+
+		w := pool.Get().(*bufferResponse)
+		w.ResponseWriter = ctx.Response()
+		w.limit = limit
+		ctx.SetResponse(w)
+		ctx.Next()
+		ctx.SetResponse(w.ResponseWriter)
+
+		if !w.streaming {
+			h := w.ResponseWriter.Header()
+			if h.Get(eudore.HeaderContentLength) == "" && len(w.buf) > 0 && !hasTrailer(h) {
+				h.Set(eudore.HeaderContentLength, strconv.Itoa(len(w.buf)))
+			}
+			if w.code != 0 {
+				w.ResponseWriter.WriteHeader(w.code)
+			}
+			if len(w.buf) > 0 {
+				w.ResponseWriter.Write(w.buf)
+			}
+		}
+
+		w.ResponseWriter = nil
+		w.buf = w.buf[:0]
+		w.code = 0
+		w.streaming = false
+		pool.Put(w)
+	}
+}
+
+// hasTrailer 函数检查响应是否设置trailer，写入trailer需要使用chunked编码不能设置Content-Length。
+func hasTrailer(h http.Header) bool {
+	if _, ok := h[eudore.HeaderTrailer]; ok {
+		return true
+	}
+	for k := range h {
+		if strings.HasPrefix(k, http.TrailerPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferResponse 定义缓冲响应，在超过缓冲大小前记录状态码和body。
+type bufferResponse struct {
+	eudore.ResponseWriter
+	buf       []byte
+	limit     int
+	code      int
+	streaming bool
+}
+
+// WriteHeader 方法缓冲时记录状态码，错误状态码丢弃已经缓冲的body。
+func (w *bufferResponse) WriteHeader(code int) {
+	if w.streaming {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.code = code
+	if code >= 400 {
+		w.buf = w.buf[:0]
+	}
+}
+
+// Write 方法写入缓冲，超过缓冲大小后切换为流式写入。
+func (w *bufferResponse) Write(data []byte) (int, error) {
+	if !w.streaming && len(w.buf)+len(data) > w.limit {
+		w.stream()
+	}
+	if w.streaming {
+		return w.ResponseWriter.Write(data)
+	}
+	w.buf = append(w.buf, data...)
+	return len(data), nil
+}
+
+// Flush 方法切换为流式写入并刷新缓冲。
+func (w *bufferResponse) Flush() {
+	if !w.streaming {
+		w.stream()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Status 方法返回缓冲或已经写入的状态码。
+func (w *bufferResponse) Status() int {
+	if !w.streaming && w.code != 0 {
+		return w.code
+	}
+	return w.ResponseWriter.Status()
+}
+
+// Size 方法返回已经写入和缓冲的body长度。
+func (w *bufferResponse) Size() int {
+	return w.ResponseWriter.Size() + len(w.buf)
+}
+
+// stream 方法写入缓冲的状态码和body，之后直接写入原始响应。
+func (w *bufferResponse) stream() {
+	w.streaming = true
+	if w.code != 0 {
+		w.ResponseWriter.WriteHeader(w.code)
+	}
+	if len(w.buf) > 0 {
+		w.ResponseWriter.Write(w.buf)
+		w.buf = w.buf[:0]
+	}
+}
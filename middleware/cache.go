@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// HeaderXCache 定义响应缓存状态Header，值为HIT或MISS。
+const HeaderXCache = "X-Cache"
+
+// cacheResponse 定义缓存的响应数据。
+type cacheResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+	Time   time.Time   `json:"time"`
+}
+
+// NewCacheFunc 函数创建一个响应缓存处理函数，缓存GET请求的200响应，按响应Vary Header列出的请求Header区分缓存。
+//
+// 缓存时间优先使用响应Cache-Control的s-maxage和max-age，响应Cache-Control包含no-store、no-cache或private，
+// 设置了Set-Cookie或Vary为*时不缓存，请求Cache-Control包含no-cache时不读取缓存。
+//
+// 请求携带Authorization或Cookie时，只有响应Cache-Control包含public或s-maxage才会缓存和读取缓存，
+// 避免将用户个人响应返回给其他用户。
+//
+// 单实例部署可以使用eudore.NewCacheLRU作为缓存，Stats方法返回命中、未命中和淘汰次数。
+//
+// options:
+// time.Duration                  =>    响应没有Cache-Control缓存时间时使用的缓存时间，默认0不缓存
+// int                            =>    缓存响应body的最大长度，默认1MB
+// func(eudore.Context) string    =>    获取缓存key的函数，默认使用Host和RequestURI
+func NewCacheFunc(cache eudore.Cache, options ...interface{}) eudore.HandlerFunc {
+	var ttl time.Duration
+	size := 1 << 20
+	getkey := func(ctx eudore.Context) string {
+		return ctx.Host() + ctx.Request().RequestURI
+	}
+	for _, i := range options {
+		switch val := i.(type) {
+		case time.Duration:
+			ttl = val
+		case int:
+			size = val
+		case func(eudore.Context) string:
+			getkey = val
+		}
+	}
+	return func(ctx eudore.Context) {
+		if ctx.Method() != eudore.MethodGet {
+			return
+		}
+		key := "response:" + getkey(ctx)
+		credential := ctx.GetHeader(eudore.HeaderAuthorization) != "" || ctx.GetHeader(eudore.HeaderCookie) != ""
+		if !strings.Contains(ctx.GetHeader(eudore.HeaderCacheControl), "no-cache") {
+			vary, err := cache.Get(key + "\nvary")
+			if err == nil {
+				data, err := cache.Get(key + getCacheVaryKey(ctx, string(vary)))
+				resp := &cacheResponse{}
+				if err == nil && json.Unmarshal(data, resp) == nil && (!credential || isCacheShared(resp.Header)) {
+					resp.writeData(ctx.Response())
+					ctx.End()
+					return
+				}
+			}
+		}
+
+		ctx.SetHeader(HeaderXCache, "MISS")
+		w := &cacheResponseWriter{ResponseWriter: ctx.Response(), limit: size}
+		ctx.SetResponse(w)
+		ctx.Next()
+		ctx.SetResponse(w.ResponseWriter)
+
+		h := w.Header()
+		age := getCacheMaxAge(h, ttl)
+		if w.Status() != eudore.StatusOK || w.overflow || age <= 0 || h.Get(eudore.HeaderSetCookie) != "" ||
+			(credential && !isCacheShared(h)) {
+			return
+		}
+		vary := strings.Join(h[eudore.HeaderVary], ",")
+		if strings.Contains(vary, "*") {
+			return
+		}
+		header := make(http.Header, len(h))
+		for k, v := range h {
+			if k != HeaderXCache {
+				header[k] = append([]string(nil), v...)
+			}
+		}
+		data, err := json.Marshal(&cacheResponse{
+			Status: w.Status(),
+			Header: header,
+			Body:   w.buf,
+			Time:   time.Now(),
+		})
+		if err == nil {
+			cache.Set(key+"\nvary", []byte(vary), age)
+			cache.Set(key+getCacheVaryKey(ctx, vary), data, age)
+		}
+	}
+}
+
+// getCacheVaryKey 函数使用Vary列出的请求Header值组成key后缀，Vary列表保存在key+"\nvary"。
+func getCacheVaryKey(ctx eudore.Context, vary string) string {
+	var key string
+	for _, name := range strings.Split(vary, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			key += "\n" + strings.ToLower(name) + ":" + ctx.GetHeader(name)
+		}
+	}
+	return key
+}
+
+// getCacheMaxAge 函数从响应Cache-Control读取缓存时间，s-maxage优先于max-age，不允许缓存时返回0。
+func getCacheMaxAge(h http.Header, ttl time.Duration) time.Duration {
+	maxage, smaxage := -1, -1
+	for _, directive := range strings.Split(h.Get(eudore.HeaderCacheControl), ",") {
+		directive = strings.TrimSpace(strings.ToLower(directive))
+		switch {
+		case directive == "no-store" || directive == "no-cache" || directive == "private":
+			return 0
+		case strings.HasPrefix(directive, "s-maxage="):
+			smaxage = eudore.GetStringInt(directive[9:])
+		case strings.HasPrefix(directive, "max-age="):
+			maxage = eudore.GetStringInt(directive[8:])
+		}
+	}
+	switch {
+	case smaxage != -1:
+		return time.Duration(smaxage) * time.Second
+	case maxage != -1:
+		return time.Duration(maxage) * time.Second
+	}
+	return ttl
+}
+
+// isCacheShared 函数判断响应Cache-Control是否包含public或s-maxage，允许将携带凭据请求的响应共享缓存。
+func isCacheShared(h http.Header) bool {
+	for _, directive := range strings.Split(h.Get(eudore.HeaderCacheControl), ",") {
+		directive = strings.TrimSpace(strings.ToLower(directive))
+		if directive == "public" || strings.HasPrefix(directive, "s-maxage=") {
+			return true
+		}
+	}
+	return false
+}
+
+// writeData 方法写入缓存的响应，设置X-Cache和Age Header。
+func (resp *cacheResponse) writeData(w eudore.ResponseWriter) {
+	h := w.Header()
+	for k, v := range resp.Header {
+		h[k] = append([]string(nil), v...)
+	}
+	h.Set(HeaderXCache, "HIT")
+	h.Set(eudore.HeaderAge, strconv.Itoa(int(time.Since(resp.Time)/time.Second)))
+	w.WriteHeader(resp.Status)
+	w.Write(resp.Body)
+}
+
+// cacheResponseWriter 定义记录写入数据的ResponseWriter，超过limit后停止记录。
+type cacheResponseWriter struct {
+	eudore.ResponseWriter
+	buf      []byte
+	limit    int
+	overflow bool
+}
+
+// Write 方法写入响应并记录body。
+func (w *cacheResponseWriter) Write(data []byte) (int, error) {
+	if !w.overflow {
+		if len(w.buf)+len(data) > w.limit {
+			w.overflow = true
+			w.buf = nil
+		} else {
+			w.buf = append(w.buf, data...)
+		}
+	}
+	return w.ResponseWriter.Write(data)
+}
@@ -0,0 +1,207 @@
+package middleware
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// CachePolicy 定义响应缓存策略，统一设置Cache-Control、Expires和Vary Header。
+//
+// 策略仅在响应状态码小于400并且处理函数没有设置Cache-Control时生效。
+type CachePolicy struct {
+	MaxAge               time.Duration `json:"maxage"`
+	SMaxAge              time.Duration `json:"smaxage"`
+	StaleWhileRevalidate time.Duration `json:"staleWhileRevalidate"`
+	StaleIfError         time.Duration `json:"staleIfError"`
+	Public               bool          `json:"public"`
+	Private              bool          `json:"private"`
+	NoCache              bool          `json:"nocache"`
+	NoStore              bool          `json:"nostore"`
+	MustRevalidate       bool          `json:"mustRevalidate"`
+	Immutable            bool          `json:"immutable"`
+	Vary                 []string      `json:"vary"`
+	// ETag 为true时使用NewETagFunc计算GET响应的弱ETag，If-None-Match匹配时返回304。
+	ETag bool `json:"etag"`
+}
+
+// NewCachePolicyFunc 函数创建一个缓存策略处理函数，路由参数cache选择使用的策略名称，
+// 没有cache参数时使用名称为空字符串的策略，没有匹配的策略时不处理。
+//
+// 例如: app.GetFunc("/static/* cache=static", handler)
+func NewCachePolicyFunc(policies map[string]*CachePolicy) eudore.HandlerFunc {
+	return func(ctx eudore.Context) {
+		policy, ok := policies[ctx.GetParam("cache")]
+		if ok {
+			policy.HandleHTTP(ctx)
+		}
+	}
+}
+
+// NewETagFunc 函数创建一个ETag处理函数，缓冲不超过size字节的GET响应计算弱ETag，默认1MB。
+//
+// 请求If-None-Match匹配响应ETag时返回304，处理函数设置ETag时使用设置的值。
+func NewETagFunc(size int) eudore.HandlerFunc {
+	return func(ctx eudore.Context) {
+		handleETag(ctx, size)
+	}
+}
+
+// String 方法返回策略的Cache-Control值。
+func (p *CachePolicy) String() string {
+	if p.NoStore {
+		return "no-store"
+	}
+	var strs []string
+	switch {
+	case p.Private:
+		strs = append(strs, "private")
+	case p.Public:
+		strs = append(strs, "public")
+	}
+	if p.NoCache {
+		strs = append(strs, "no-cache")
+	}
+	for _, i := range []struct {
+		name string
+		age  time.Duration
+	}{
+		{"max-age", p.MaxAge},
+		{"s-maxage", p.SMaxAge},
+		{"stale-while-revalidate", p.StaleWhileRevalidate},
+		{"stale-if-error", p.StaleIfError},
+	} {
+		if i.age > 0 && !(i.name == "s-maxage" && p.Private) {
+			strs = append(strs, i.name+"="+strconv.FormatInt(int64(i.age/time.Second), 10))
+		}
+	}
+	if p.MustRevalidate {
+		strs = append(strs, "must-revalidate")
+	}
+	if p.Immutable {
+		strs = append(strs, "immutable")
+	}
+	return strings.Join(strs, ", ")
+}
+
+// HandleHTTP 方法设置响应缓存策略，用于单独给路由或路由组添加策略。
+func (p *CachePolicy) HandleHTTP(ctx eudore.Context) {
+	w := &cachePolicyResponse{ResponseWriter: ctx.Response(), policy: p}
+	ctx.SetResponse(w)
+	if p.ETag && !p.NoStore {
+		handleETag(ctx, 0)
+	} else {
+		ctx.Next()
+	}
+	w.writeHeader(w.ResponseWriter.Status())
+	ctx.SetResponse(w.ResponseWriter)
+}
+
+// apply 方法写入策略Header。
+func (p *CachePolicy) apply(h http.Header, code int) {
+	for _, vary := range p.Vary {
+		h.Add(eudore.HeaderVary, vary)
+	}
+	if code >= 400 || h.Get(eudore.HeaderCacheControl) != "" {
+		return
+	}
+	h.Set(eudore.HeaderCacheControl, p.String())
+	switch {
+	case p.NoStore || p.NoCache:
+		h.Set(eudore.HeaderExpires, "0")
+	case p.MaxAge > 0:
+		h.Set(eudore.HeaderExpires, time.Now().Add(p.MaxAge).UTC().Format(http.TimeFormat))
+	}
+}
+
+// cachePolicyResponse 定义在写入状态码时设置缓存策略的响应。
+type cachePolicyResponse struct {
+	eudore.ResponseWriter
+	policy *CachePolicy
+	wrote  bool
+}
+
+func (w *cachePolicyResponse) WriteHeader(code int) {
+	w.writeHeader(code)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *cachePolicyResponse) Write(data []byte) (int, error) {
+	w.writeHeader(w.ResponseWriter.Status())
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *cachePolicyResponse) Flush() {
+	w.writeHeader(w.ResponseWriter.Status())
+	w.ResponseWriter.Flush()
+}
+
+func (w *cachePolicyResponse) writeHeader(code int) {
+	if !w.wrote {
+		w.wrote = true
+		w.policy.apply(w.ResponseWriter.Header(), code)
+	}
+}
+
+// handleETag 函数使用bufferResponse缓冲响应计算ETag，超过缓冲大小时流式写入不设置ETag。
+func handleETag(ctx eudore.Context, size int) {
+	if ctx.Method() != eudore.MethodGet {
+		return
+	}
+	if size <= 0 {
+		size = 1 << 20
+	}
+	w := &bufferResponse{ResponseWriter: ctx.Response(), limit: size}
+	ctx.SetResponse(w)
+	ctx.Next()
+	ctx.SetResponse(w.ResponseWriter)
+	if w.streaming {
+		return
+	}
+
+	h := w.ResponseWriter.Header()
+	code := w.Status()
+	if code == eudore.StatusOK {
+		etag := h.Get(eudore.HeaderETag)
+		if etag == "" {
+			sum := sha1.Sum(w.buf)
+			etag = `W/"` + hex.EncodeToString(sum[:16]) + `"`
+			h.Set(eudore.HeaderETag, etag)
+		}
+		if matchETag(ctx.GetHeader(eudore.HeaderIfNoneMatch), etag) {
+			h.Del(eudore.HeaderContentLength)
+			h.Del(eudore.HeaderContentType)
+			w.ResponseWriter.WriteHeader(eudore.StatusNotModified)
+			return
+		}
+	}
+	if h.Get(eudore.HeaderContentLength) == "" && len(w.buf) > 0 && !hasTrailer(h) {
+		h.Set(eudore.HeaderContentLength, strconv.Itoa(len(w.buf)))
+	}
+	if w.code != 0 {
+		w.ResponseWriter.WriteHeader(w.code)
+	}
+	if len(w.buf) > 0 {
+		w.ResponseWriter.Write(w.buf)
+	}
+}
+
+// matchETag 函数使用弱比较判断If-None-Match是否匹配etag。
+func matchETag(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, i := range strings.Split(header, ",") {
+		i = strings.TrimSpace(i)
+		if i == "*" || strings.TrimPrefix(i, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// Canary 定义A/B分流，按权重、Header或Cookie粘性将请求分配给stable或canary处理函数，并统计每个版本的指标。
+type Canary struct {
+	// 64位原子操作的字段放在结构体开头保证对齐。
+	StableData CanaryStat `json:"stable"`
+	CanaryData CanaryStat `json:"canary"`
+	// Weight 定义canary版本的请求百分比。
+	Weight int32 `json:"weight"`
+	// Header 定义强制选择版本的Header，值为canary或stable。
+	Header string `json:"header"`
+	// Cookie 定义保存选择版本的Cookie，相同客户端总是访问相同版本。
+	Cookie string              `json:"cookie"`
+	MaxAge int                 `json:"maxage"`
+	Stable eudore.HandlerFuncs `json:"-"`
+	Canary eudore.HandlerFuncs `json:"-"`
+}
+
+// CanaryStat 定义一个版本的统计指标。
+type CanaryStat struct {
+	Requests uint64 `json:"requests"`
+	Errors   uint64 `json:"errors"`
+	Duration int64  `json:"duration"`
+}
+
+// NewCanaryFunc 函数创建一个A/B分流处理函数，weight百分比的请求使用canary处理，其他请求继续执行路由处理函数。
+func NewCanaryFunc(weight int, canary eudore.HandlerFuncs) eudore.HandlerFunc {
+	c := NewCanary(weight, nil, canary)
+	return c.NewCanaryFunc(nil)
+}
+
+// NewCanary 函数创建一个A/B分流，stable为空时继续执行路由处理函数。
+func NewCanary(weight int, stable, canary eudore.HandlerFuncs) *Canary {
+	return &Canary{
+		Weight: int32(weight),
+		Header: "X-Canary",
+		Cookie: "_canary",
+		MaxAge: 86400,
+		Stable: stable,
+		Canary: canary,
+	}
+}
+
+// NewCanaryFunc 方法创建A/B分流处理函数，router参数非空时注入GET /canary和PUT /canary/weight/:weight路由查看指标和修改权重。
+//
+// 选择版本的顺序为Header、Cookie、权重随机，选择的版本会设置到canary参数，可以使用Logger中间件输出。
+func (c *Canary) NewCanaryFunc(router eudore.Router) eudore.HandlerFunc {
+	if router != nil {
+		router.GetFunc("/canary", c.getData)
+		router.PutFunc("/canary/weight/:weight", c.putWeight)
+	}
+	return func(ctx eudore.Context) {
+		variant := c.choose(ctx)
+		ctx.SetParam("canary", variant)
+		stat, hs := &c.StableData, c.Stable
+		if variant == "canary" {
+			stat, hs = &c.CanaryData, c.Canary
+		}
+		if hs != nil {
+			ctx.SetHandler(-1, hs)
+		}
+
+		start := time.Now()
+		ctx.Next()
+		atomic.AddUint64(&stat.Requests, 1)
+		atomic.AddInt64(&stat.Duration, int64(time.Since(start)))
+		if ctx.Response().Status() >= 500 {
+			atomic.AddUint64(&stat.Errors, 1)
+		}
+	}
+}
+
+func (c *Canary) choose(ctx eudore.Context) string {
+	switch ctx.GetHeader(c.Header) {
+	case "canary":
+		return "canary"
+	case "stable":
+		return "stable"
+	}
+	switch val := ctx.GetCookie(c.Cookie); val {
+	case "canary", "stable":
+		return val
+	}
+	variant := "stable"
+	if rand.Int31n(100) < atomic.LoadInt32(&c.Weight) {
+		variant = "canary"
+	}
+	if c.Cookie != "" {
+		ctx.SetCookieValue(c.Cookie, variant, c.MaxAge)
+	}
+	return variant
+}
+
+func (c *Canary) getData(ctx eudore.Context) {
+	ctx.Render(map[string]interface{}{
+		"weight": atomic.LoadInt32(&c.Weight),
+		"stable": c.getStat(&c.StableData),
+		"canary": c.getStat(&c.CanaryData),
+	})
+}
+
+func (c *Canary) getStat(stat *CanaryStat) CanaryStat {
+	return CanaryStat{
+		Requests: atomic.LoadUint64(&stat.Requests),
+		Errors:   atomic.LoadUint64(&stat.Errors),
+		Duration: atomic.LoadInt64(&stat.Duration),
+	}
+}
+
+func (c *Canary) putWeight(ctx eudore.Context) {
+	weight := eudore.GetStringInt(ctx.GetParam("weight"), -1)
+	if weight < 0 || weight > 100 {
+		ctx.Fatal("weight is invalid")
+		return
+	}
+	ctx.Infof("canary admin set weight from %d to %d", atomic.LoadInt32(&c.Weight), weight)
+	atomic.StoreInt32(&c.Weight, int32(weight))
+}
@@ -0,0 +1,220 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/eudore/eudore"
+)
+
+// ChainConstructor 定义使用配置创建中间件的构造函数，options为中间件配置。
+type ChainConstructor func(app *eudore.App, options map[string]interface{}) (eudore.HandlerFunc, error)
+
+var (
+	chainLock sync.RWMutex
+	chainnews = make(map[string]ChainConstructor)
+	// chainKeyQuote 匹配没有引号的json对象key，例如{level:5}。
+	chainKeyQuote = regexp.MustCompile(`([{,]\s*)([A-Za-z_][A-Za-z0-9_]*)\s*:`)
+)
+
+func init() {
+	chainnews["recover"] = func(*eudore.App, map[string]interface{}) (eudore.HandlerFunc, error) {
+		return NewRecoverFunc(), nil
+	}
+	chainnews["requestid"] = func(*eudore.App, map[string]interface{}) (eudore.HandlerFunc, error) {
+		return NewRequestIDFunc(nil), nil
+	}
+	chainnews["logger"] = func(app *eudore.App, options map[string]interface{}) (eudore.HandlerFunc, error) {
+		return NewLoggerFunc(app, eudore.GetStrings(options["params"])...), nil
+	}
+	chainnews["gzip"] = func(_ *eudore.App, options map[string]interface{}) (eudore.HandlerFunc, error) {
+		return NewGzipFunc(eudore.GetInt(options["level"], 5)), nil
+	}
+	chainnews["compress"] = func(*eudore.App, map[string]interface{}) (eudore.HandlerFunc, error) {
+		return NewCompressFunc(), nil
+	}
+	chainnews["timeout"] = func(_ *eudore.App, options map[string]interface{}) (eudore.HandlerFunc, error) {
+		timeout := eudore.GetStringDuration(eudore.GetString(options["timeout"]))
+		if timeout <= 0 {
+			return nil, fmt.Errorf("timeout option timeout is invalid: %v", options["timeout"])
+		}
+		return NewTimeoutFunc(timeout), nil
+	}
+	chainnews["rate"] = func(_ *eudore.App, options map[string]interface{}) (eudore.HandlerFunc, error) {
+		speed, max := eudore.GetInt64(options["speed"]), eudore.GetInt64(options["max"])
+		if speed <= 0 || max <= 0 {
+			return nil, fmt.Errorf("rate option speed and max must be greater than 0")
+		}
+		return NewRateFunc(speed, max), nil
+	}
+	chainnews["cors"] = func(_ *eudore.App, options map[string]interface{}) (eudore.HandlerFunc, error) {
+		return NewCorsFunc(eudore.GetStrings(options["origins"]), getChainMapString(options["headers"])), nil
+	}
+	chainnews["referer"] = func(_ *eudore.App, options map[string]interface{}) (eudore.HandlerFunc, error) {
+		return NewRefererFunc(getChainMapBool(options["data"])), nil
+	}
+	chainnews["rewrite"] = func(_ *eudore.App, options map[string]interface{}) (eudore.HandlerFunc, error) {
+		return NewRewriteFunc(getChainMapString(options["data"])), nil
+	}
+	chainnews["basicauth"] = func(_ *eudore.App, options map[string]interface{}) (eudore.HandlerFunc, error) {
+		return NewBasicAuthFunc(getChainMapString(options["names"])), nil
+	}
+	chainnews["black"] = func(_ *eudore.App, options map[string]interface{}) (eudore.HandlerFunc, error) {
+		return NewBlackFunc(getChainMapBool(options["data"]), nil), nil
+	}
+	chainnews["buffer"] = func(_ *eudore.App, options map[string]interface{}) (eudore.HandlerFunc, error) {
+		return NewBufferFunc(eudore.GetInt(options["size"])), nil
+	}
+	chainnews["timing"] = func(*eudore.App, map[string]interface{}) (eudore.HandlerFunc, error) {
+		return NewTimingFunc(), nil
+	}
+	chainnews["useragent"] = func(*eudore.App, map[string]interface{}) (eudore.HandlerFunc, error) {
+		return NewUserAgentFunc(), nil
+	}
+}
+
+// RegisterChain 函数注册一个中间件构造函数，用于NewChainFuncs函数按名称创建中间件，
+// 默认存在recover、requestid、logger、gzip、compress、timeout、rate、cors、referer、rewrite、basicauth、black、buffer、timing、useragent。
+func RegisterChain(name string, fn ChainConstructor) {
+	chainLock.Lock()
+	chainnews[strings.ToLower(name)] = fn
+	chainLock.Unlock()
+}
+
+// NewChainFuncs 函数使用配置创建中间件链，conf为中间件声明列表，按顺序使用注册的构造函数创建中间件。
+//
+// 声明可以是名称字符串"recover"，名称和json对象配置"gzip:{level:5}"，json对象key可以不加引号，
+// 或者包含name属性的map{"name": "gzip", "level": 5}；配置disable为true时跳过该中间件，
+// 用于运维调整中间件顺序和开关时不需要重新编译，全部声明的错误合并返回。
+//
+// 例如: hs, err := middleware.NewChainFuncs(app, app.Get("middleware"))
+func NewChainFuncs(app *eudore.App, conf interface{}) (eudore.HandlerFuncs, error) {
+	var hs eudore.HandlerFuncs
+	var errs []string
+	for i, decl := range getChainDeclares(conf) {
+		name, options, err := parseChainDeclare(decl)
+		if err == nil && !eudore.GetBool(options["disable"]) {
+			var h eudore.HandlerFunc
+			h, err = newChainFunc(app, name, options)
+			if h != nil {
+				hs = append(hs, h)
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("middleware %d %v", i, err))
+		}
+	}
+	if errs != nil {
+		return nil, fmt.Errorf("chain middleware error: %s", strings.Join(errs, "; "))
+	}
+	return hs, nil
+}
+
+func newChainFunc(app *eudore.App, name string, options map[string]interface{}) (eudore.HandlerFunc, error) {
+	chainLock.RLock()
+	fn, ok := chainnews[name]
+	chainLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%s not registered", name)
+	}
+	h, err := fn(app, options)
+	if err != nil {
+		return nil, fmt.Errorf("%s %v", name, err)
+	}
+	return h, nil
+}
+
+// getChainDeclares 函数将配置转换成中间件声明列表，字符串使用逗号分隔时需要不包含json配置。
+func getChainDeclares(conf interface{}) []interface{} {
+	switch val := conf.(type) {
+	case []interface{}:
+		return val
+	case []string:
+		decls := make([]interface{}, len(val))
+		for i := range val {
+			decls[i] = val[i]
+		}
+		return decls
+	case string:
+		var decls []interface{}
+		for _, str := range strings.Split(val, ",") {
+			if str = strings.TrimSpace(str); str != "" {
+				decls = append(decls, str)
+			}
+		}
+		return decls
+	case nil:
+		return nil
+	}
+	return []interface{}{conf}
+}
+
+// parseChainDeclare 函数解析一个中间件声明，返回小写名称和配置。
+func parseChainDeclare(decl interface{}) (string, map[string]interface{}, error) {
+	var name string
+	options := make(map[string]interface{})
+	switch val := decl.(type) {
+	case string:
+		name = val
+		pos := strings.IndexByte(val, ':')
+		if pos != -1 {
+			name = val[:pos]
+			err := unmarshalChainOptions(strings.TrimSpace(val[pos+1:]), &options)
+			if err != nil {
+				return "", nil, fmt.Errorf("%s parse options error: %v", strings.TrimSpace(name), err)
+			}
+		}
+	case map[string]interface{}:
+		for k, v := range val {
+			options[k] = v
+		}
+		name = eudore.GetString(options["name"])
+	default:
+		return "", nil, fmt.Errorf("invalid declare type %T", decl)
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", nil, fmt.Errorf("name is empty")
+	}
+	return name, options, nil
+}
+
+// unmarshalChainOptions 函数解析json配置，失败时给没有引号的key添加引号再次解析。
+func unmarshalChainOptions(str string, options *map[string]interface{}) error {
+	err := json.Unmarshal([]byte(str), options)
+	if err != nil {
+		if json.Unmarshal([]byte(chainKeyQuote.ReplaceAllString(str, `$1"$2":`)), options) == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// getChainMapString 函数转换json配置对象成map[string]string。
+func getChainMapString(i interface{}) map[string]string {
+	if data, ok := i.(map[string]string); ok {
+		return data
+	}
+	data := make(map[string]string)
+	m, _ := i.(map[string]interface{})
+	for k, v := range m {
+		data[k] = eudore.GetString(v)
+	}
+	return data
+}
+
+// getChainMapBool 函数转换json配置对象成map[string]bool。
+func getChainMapBool(i interface{}) map[string]bool {
+	if data, ok := i.(map[string]bool); ok {
+		return data
+	}
+	data := make(map[string]bool)
+	m, _ := i.(map[string]interface{})
+	for k, v := range m {
+		data[k] = eudore.GetBool(v)
+	}
+	return data
+}
@@ -0,0 +1,231 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/eudore/eudore"
+)
+
+// Compressor 定义响应压缩编码器，*gzip.Writer、*zlib.Writer和常用zstd、brotli库的Writer都实现了该接口，Reset方法用于池化复用。
+type Compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+// CompressFunc 定义创建压缩编码器的函数，level为压缩等级，未设置等级时为-1表示编码默认等级。
+type CompressFunc func(level int) Compressor
+
+// DefaultCompressOrder 定义q值相同时压缩编码的优先顺序。
+var DefaultCompressOrder = []string{"zstd", "br", "gzip", "deflate"}
+
+// NewCompressFunc 函数创建一个响应压缩处理函数，根据Accept-Encoding的q值选择压缩编码，q值相同时按优先顺序选择，
+// 每种编码使用sync.Pool复用编码器。
+//
+// 默认支持gzip和deflate，zstd和br需要使用第三方库添加CompressFunc；
+// 状态码为204、304或已经设置Content-Encoding的响应不压缩。
+//
+// options:
+// map[string]CompressFunc     =>    添加或替换压缩编码，key为Content-Encoding，例如zstd、br
+// map[string]int              =>    设置编码的压缩等级，默认gzip和deflate为5
+// []string                    =>    设置q值相同时编码的优先顺序，默认使用DefaultCompressOrder
+func NewCompressFunc(options ...interface{}) eudore.HandlerFunc {
+	funcs := map[string]CompressFunc{
+		"gzip":    newCompressGzip,
+		"deflate": newCompressDeflate,
+	}
+	levels := map[string]int{"gzip": 5, "deflate": 5}
+	order := DefaultCompressOrder
+	for _, i := range options {
+		switch val := i.(type) {
+		case map[string]CompressFunc:
+			for k, v := range val {
+				funcs[strings.ToLower(k)] = v
+			}
+		case map[string]int:
+			for k, v := range val {
+				levels[strings.ToLower(k)] = v
+			}
+		case []string:
+			order = val
+		}
+	}
+
+	c := &compress{pools: make(map[string]*sync.Pool)}
+	for _, name := range order {
+		fn, ok := funcs[name]
+		if !ok {
+			continue
+		}
+		level, ok := levels[name]
+		if !ok {
+			level = -1
+		}
+		c.order = append(c.order, name)
+		c.pools[name] = &sync.Pool{
+			New: func() interface{} {
+				return fn(level)
+			},
+		}
+	}
+	return c.HandleHTTP
+}
+
+func newCompressGzip(level int) Compressor {
+	w, err := gzip.NewWriterLevel(nil, level)
+	if err != nil {
+		w = gzip.NewWriter(nil)
+	}
+	return w
+}
+
+func newCompressDeflate(level int) Compressor {
+	w, err := zlib.NewWriterLevel(nil, level)
+	if err != nil {
+		w = zlib.NewWriter(nil)
+	}
+	return w
+}
+
+type compress struct {
+	order []string
+	pools map[string]*sync.Pool
+}
+
+// HandleHTTP 方法实现eudore请求上下文处理函数。
+func (c *compress) HandleHTTP(ctx eudore.Context) {
+	h := ctx.Request().Header
+	if strings.Contains(h.Get(eudore.HeaderConnection), "Upgrade") ||
+		strings.Contains(h.Get(eudore.HeaderAccept), "text/event-stream") ||
+		ctx.Response().Header().Get(eudore.HeaderContentEncoding) != "" {
+		return
+	}
+	encoding := c.negotiate(h.Get(eudore.HeaderAcceptEncoding))
+	ctx.Response().Header().Add(eudore.HeaderVary, eudore.HeaderAcceptEncoding)
+	if encoding == "" {
+		return
+	}
+
+	w := &compressResponse{
+		ResponseWriter: ctx.Response(),
+		pool:           c.pools[encoding],
+		encoding:       encoding,
+	}
+	ctx.SetResponse(w)
+	ctx.Next()
+	ctx.SetResponse(w.ResponseWriter)
+	w.Close()
+}
+
+// negotiate 方法返回q值最大的编码，q值相同时使用order中靠前的编码，没有可用编码时返回空字符串。
+func (c *compress) negotiate(accept string) string {
+	if accept == "" {
+		return ""
+	}
+	quality := make(map[string]float64)
+	for _, item := range strings.Split(accept, ",") {
+		params := strings.Split(item, ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+		q := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				val, err := strconv.ParseFloat(param[2:], 64)
+				if err == nil {
+					q = val
+				}
+			}
+		}
+		quality[name] = q
+	}
+
+	var encoding string
+	var max float64
+	for _, name := range c.order {
+		q, ok := quality[name]
+		if !ok {
+			q = quality["*"]
+		}
+		if q > max {
+			encoding, max = name, q
+		}
+	}
+	return encoding
+}
+
+// compressResponse 定义压缩响应，在写入状态码或第一次写入数据时判断是否压缩。
+type compressResponse struct {
+	eudore.ResponseWriter
+	pool     *sync.Pool
+	writer   Compressor
+	encoding string
+	init     bool
+}
+
+func (w *compressResponse) start(code int) {
+	if w.init {
+		return
+	}
+	w.init = true
+	h := w.ResponseWriter.Header()
+	if code < 200 || code == eudore.StatusNoContent || code == eudore.StatusNotModified || h.Get(eudore.HeaderContentEncoding) != "" {
+		return
+	}
+	w.writer = w.pool.Get().(Compressor)
+	w.writer.Reset(w.ResponseWriter)
+	h.Set(eudore.HeaderContentEncoding, w.encoding)
+	h.Del(eudore.HeaderContentLength)
+}
+
+// WriteHeader 实现ResponseWriter中的WriteHeader方法。
+func (w *compressResponse) WriteHeader(code int) {
+	w.start(code)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write 实现ResponseWriter中的Write方法。
+func (w *compressResponse) Write(data []byte) (int, error) {
+	w.start(eudore.StatusOK)
+	if w.writer == nil {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.writer.Write(data)
+}
+
+// Flush 实现ResponseWriter中的Flush方法。
+func (w *compressResponse) Flush() {
+	if w.writer != nil {
+		w.writer.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Push 方法使用相同的压缩编码推送资源。
+func (w *compressResponse) Push(target string, opts *http.PushOptions) error {
+	if opts == nil {
+		opts = &http.PushOptions{}
+	}
+	if opts.Header == nil {
+		opts.Header = make(http.Header)
+	}
+	if opts.Header.Get(eudore.HeaderAcceptEncoding) == "" {
+		opts.Header.Set(eudore.HeaderAcceptEncoding, w.encoding)
+	}
+	return w.ResponseWriter.Push(target, opts)
+}
+
+// Close 方法结束压缩并回收编码器。
+func (w *compressResponse) Close() {
+	if w.writer != nil {
+		w.writer.Close()
+		w.writer.Reset(nil)
+		w.pool.Put(w.writer)
+		w.writer = nil
+	}
+}
@@ -0,0 +1,337 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// ConnGuard 定义连接级别防护，限制每个IP的并发连接数，客户端多次触发限制后临时封禁IP。
+//
+// 触发限制包括超过并发连接数、读取请求超时(慢速攻击)和请求返回429状态码，
+// 在Window时间内触发MaxStrikes次后封禁BanTime时间，封禁写入Black黑名单，黑名单中间件同时拒绝该IP的请求。
+//
+// 拒绝的连接延迟Tarpit时间后关闭，消耗攻击客户端资源，最多同时延迟MaxTarpit个连接。
+type ConnGuard struct {
+	sync.Mutex
+	Black *Black
+	// MaxConns 为每个IP的最大并发连接数，默认64，为0时不限制。
+	MaxConns   int
+	MaxStrikes int
+	Window     time.Duration
+	BanTime    time.Duration
+	Tarpit     time.Duration
+	MaxTarpit  int
+	// TrustedProxies 为可信代理的ip或cidr，请求来自可信代理时从X-Forwarded-For读取客户端ip，默认为空使用连接地址。
+	TrustedProxies []string
+	// Print 输出封禁日志，默认不输出。
+	Print   func(...interface{})
+	conns   map[string]int
+	states  map[string]*connGuardConn
+	strikes map[string]*connGuardStrike
+	bans    map[string]time.Time
+	tarpits chan struct{}
+}
+
+// connGuardStrike 定义一个IP在窗口内的触发次数。
+type connGuardStrike struct {
+	Count int
+	Reset time.Time
+}
+
+// connGuardListener 定义检查连接的监听。
+type connGuardListener struct {
+	net.Listener
+	guard *ConnGuard
+}
+
+// connGuardConn 定义记录读取超时的连接。
+type connGuardConn struct {
+	net.Conn
+	guard   *ConnGuard
+	ip      string
+	once    sync.Once
+	timeout bool
+	state   http.ConnState
+}
+
+// NewConnGuard 函数创建连接级别防护，black为空时创建新的黑名单，和NewBlackFunc使用相同黑名单时共享封禁。
+//
+// 需要使用NewListen或Listener方法包装监听，并设置ConnState方法到ServerStdConfig.ConnState，
+// 读取请求header的超时时间使用ServerStdConfig.ReadHeaderTimeout设置。
+func NewConnGuard(black *Black) *ConnGuard {
+	if black == nil {
+		black = newBlack()
+	}
+	return &ConnGuard{
+		Black:      black,
+		MaxConns:   64,
+		MaxStrikes: 5,
+		Window:     time.Minute,
+		BanTime:    10 * time.Minute,
+		Tarpit:     5 * time.Second,
+		MaxTarpit:  256,
+		conns:      make(map[string]int),
+		states:     make(map[string]*connGuardConn),
+		strikes:    make(map[string]*connGuardStrike),
+		bans:       make(map[string]time.Time),
+	}
+}
+
+// NewListen 方法实现ServerListenConfig.NewListen，使用net.Listen创建监听后包装。
+func (g *ConnGuard) NewListen(network, addr string) (net.Listener, error) {
+	ln, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return g.Listener(ln), nil
+}
+
+// Listener 方法包装监听，拒绝封禁和超过并发连接数的连接，需要在tls之前包装。
+func (g *ConnGuard) Listener(ln net.Listener) net.Listener {
+	return &connGuardListener{Listener: ln, guard: g}
+}
+
+// Accept 方法接收连接，拒绝的连接不返回给server。
+func (ln *connGuardListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := ln.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		ip := getConnGuardIP(conn.RemoteAddr())
+		if ln.guard.accept(ip) {
+			c := &connGuardConn{Conn: conn, guard: ln.guard, ip: ip, state: http.StateNew}
+			ln.guard.Lock()
+			ln.guard.states[conn.RemoteAddr().String()] = c
+			ln.guard.Unlock()
+			return c, nil
+		}
+		ln.guard.reject(conn)
+	}
+}
+
+func (c *connGuardConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		c.guard.Lock()
+		c.timeout = true
+		c.guard.Unlock()
+	}
+	return n, err
+}
+
+// Close 方法关闭连接，连接在new或active状态读取超时后关闭记录一次触发，idle状态超时为正常keep-alive超时。
+func (c *connGuardConn) Close() error {
+	c.once.Do(func() {
+		c.guard.Lock()
+		c.guard.conns[c.ip]--
+		if c.guard.conns[c.ip] <= 0 {
+			delete(c.guard.conns, c.ip)
+		}
+		delete(c.guard.states, c.RemoteAddr().String())
+		strike := c.timeout && (c.state == http.StateNew || c.state == http.StateActive)
+		c.guard.Unlock()
+		if strike {
+			c.guard.Strike(c.ip)
+		}
+	})
+	return c.Conn.Close()
+}
+
+// ConnState 方法实现http.Server.ConnState钩子，记录连接状态用于区分读取请求超时和keep-alive超时。
+//
+// 读取请求超时后server将已经读取数据的连接设置为active状态，所以仅在进入idle状态时清除超时记录。
+func (g *ConnGuard) ConnState(conn net.Conn, state http.ConnState) {
+	g.Lock()
+	c, ok := g.states[conn.RemoteAddr().String()]
+	if ok {
+		c.state = state
+		if state == http.StateIdle {
+			c.timeout = false
+		}
+	}
+	g.Unlock()
+}
+
+// NewConnGuardFunc 方法返回处理函数，请求返回429状态码时记录一次触发，需要在限流中间件之前添加。
+func (g *ConnGuard) NewConnGuardFunc() eudore.HandlerFunc {
+	return func(ctx eudore.Context) {
+		ctx.Next()
+		if ctx.Response().Status() == eudore.StatusTooManyRequests {
+			g.Strike(g.RealIP(ctx))
+		}
+	}
+}
+
+// RealIP 方法返回请求的客户端ip，默认使用连接地址；
+// 连接地址属于TrustedProxies时从右向左读取X-Forwarded-For，返回第一个不可信的ip。
+func (g *ConnGuard) RealIP(ctx eudore.Context) string {
+	ip := ctx.Request().RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	if !g.isTrusted(ip) {
+		return ip
+	}
+	ips := strings.Split(ctx.GetHeader(eudore.HeaderXForwardedFor), ",")
+	for i := len(ips) - 1; i >= 0; i-- {
+		forward := strings.TrimSpace(ips[i])
+		if net.ParseIP(forward) == nil {
+			break
+		}
+		ip = forward
+		if !g.isTrusted(ip) {
+			break
+		}
+	}
+	return ip
+}
+
+func (g *ConnGuard) isTrusted(ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, proxy := range g.TrustedProxies {
+		if _, cidr, err := net.ParseCIDR(proxy); err == nil {
+			if cidr.Contains(addr) {
+				return true
+			}
+		} else if addr.Equal(net.ParseIP(proxy)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Strike 方法记录IP触发一次限制，在Window时间内达到MaxStrikes次后封禁IP。
+func (g *ConnGuard) Strike(ip string) {
+	if g.Black.IsWhite(ip) {
+		return
+	}
+	now := time.Now()
+	g.Lock()
+	strike, ok := g.strikes[ip]
+	if !ok || now.After(strike.Reset) {
+		strike = &connGuardStrike{Reset: now.Add(g.Window)}
+		g.strikes[ip] = strike
+	}
+	strike.Count++
+	count := strike.Count
+	if count >= g.MaxStrikes {
+		delete(g.strikes, ip)
+	}
+	g.Unlock()
+	if count >= g.MaxStrikes {
+		g.Ban(ip, g.BanTime)
+	}
+}
+
+// Ban 方法封禁IP一段时间，ipv4地址同时写入黑名单，到期后从黑名单删除。
+func (g *ConnGuard) Ban(ip string, d time.Duration) {
+	g.Lock()
+	_, banned := g.bans[ip]
+	g.bans[ip] = time.Now().Add(d)
+	g.Unlock()
+	ipv4 := net.ParseIP(ip) != nil && net.ParseIP(ip).To4() != nil
+	if !banned && ipv4 {
+		g.Black.InsertBlack(ip + "/32")
+	}
+	if g.Print != nil {
+		g.Print("conn guard ban ip", ip, d.String())
+	}
+	time.AfterFunc(d, func() {
+		g.Lock()
+		expire, ok := g.bans[ip]
+		if ok && !time.Now().Before(expire) {
+			delete(g.bans, ip)
+		}
+		g.Unlock()
+		if ok && !time.Now().Before(expire) && ipv4 {
+			g.Black.DeleteBlack(ip + "/32")
+		}
+	})
+}
+
+// Banned 方法判断IP是否在封禁时间内。
+func (g *ConnGuard) Banned(ip string) bool {
+	g.Lock()
+	expire, ok := g.bans[ip]
+	g.Unlock()
+	return ok && time.Now().Before(expire)
+}
+
+// Bans 方法返回当前封禁的IP和到期时间。
+func (g *ConnGuard) Bans() map[string]time.Time {
+	g.Lock()
+	defer g.Unlock()
+	bans := make(map[string]time.Time, len(g.bans))
+	for k, v := range g.bans {
+		bans[k] = v
+	}
+	return bans
+}
+
+// accept 方法检查IP是否封禁或超过并发连接数，允许时连接数加一。
+func (g *ConnGuard) accept(ip string) bool {
+	if g.Black.IsWhite(ip) {
+		g.Lock()
+		g.conns[ip]++
+		g.Unlock()
+		return true
+	}
+	if g.Black.Deny(ip) {
+		return false
+	}
+	g.Lock()
+	expire, banned := g.bans[ip]
+	if banned && time.Now().Before(expire) {
+		g.Unlock()
+		return false
+	}
+	if g.MaxConns > 0 && g.conns[ip] >= g.MaxConns {
+		g.Unlock()
+		g.Strike(ip)
+		return false
+	}
+	g.conns[ip]++
+	g.Unlock()
+	return true
+}
+
+// reject 方法延迟Tarpit时间后关闭连接，延迟的连接达到MaxTarpit时立即关闭。
+func (g *ConnGuard) reject(conn net.Conn) {
+	g.Lock()
+	if g.tarpits == nil && g.MaxTarpit > 0 {
+		g.tarpits = make(chan struct{}, g.MaxTarpit)
+	}
+	tarpits, delay := g.tarpits, g.Tarpit
+	g.Unlock()
+	if delay <= 0 || tarpits == nil {
+		conn.Close()
+		return
+	}
+	select {
+	case tarpits <- struct{}{}:
+		go func() {
+			time.Sleep(delay)
+			conn.Close()
+			<-tarpits
+		}()
+	default:
+		conn.Close()
+	}
+}
+
+func getConnGuardIP(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
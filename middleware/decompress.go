@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/eudore/eudore"
+)
+
+// DecompressFunc 定义请求body解压函数，例如使用第三方库实现zstd解压。
+type DecompressFunc func(io.Reader) (io.ReadCloser, error)
+
+// 定义请求body解压错误。
+var (
+	ErrDecompressTooLarge = errors.New("decompressed request body is too large")
+	ErrDecompressRatio    = errors.New("decompressed request body expansion ratio is too large")
+)
+
+// NewDecompressFunc 函数创建请求body解压处理函数，根据Content-Encoding解压gzip、deflate请求body，需要在读取body的中间件之前注册。
+//
+// 解压后超过size字节或解压后超过64KB且解压比超过ratio时读取返回错误并设置状态码413，size和ratio为0时不限制；
+// 不支持的Content-Encoding返回415，解压后删除Content-Encoding和Content-Length Header。
+//
+// options:
+// map[string]DecompressFunc    =>    添加或替换解压函数，key为Content-Encoding，例如zstd
+func NewDecompressFunc(size, ratio int64, options ...interface{}) eudore.HandlerFunc {
+	decoders := map[string]DecompressFunc{
+		"gzip":    newDecompressGzip,
+		"x-gzip":  newDecompressGzip,
+		"deflate": newDecompressDeflate,
+	}
+	for _, i := range options {
+		if val, ok := i.(map[string]DecompressFunc); ok {
+			for k, v := range val {
+				decoders[strings.ToLower(k)] = v
+			}
+		}
+	}
+	return func(ctx eudore.Context) {
+		encoding := ctx.GetHeader(eudore.HeaderContentEncoding)
+		if encoding == "" {
+			return
+		}
+
+		r := ctx.Request()
+		counter := &decompressCounter{Reader: r.Body}
+		var reader io.Reader = counter
+		body := &decompressReader{
+			ctx:     ctx,
+			counter: counter,
+			closer:  r.Body,
+			size:    size,
+			ratio:   ratio,
+		}
+		// 多个编码按相反顺序解压
+		encodings := strings.Split(encoding, ",")
+		for i := len(encodings) - 1; i >= 0; i-- {
+			name := strings.ToLower(strings.TrimSpace(encodings[i]))
+			if name == "identity" || name == "" {
+				continue
+			}
+			fn, ok := decoders[name]
+			if !ok {
+				ctx.WriteHeader(eudore.StatusUnsupportedMediaType)
+				ctx.Fatal("unsupported request Content-Encoding: " + name)
+				ctx.End()
+				return
+			}
+			rc, err := fn(reader)
+			if err != nil {
+				ctx.WriteHeader(eudore.StatusBadRequest)
+				ctx.Fatal(fmt.Sprintf("decompress request body %s error: %v", name, err))
+				ctx.End()
+				return
+			}
+			reader = rc
+		}
+		body.Reader = reader
+		r.Body = body
+		r.ContentLength = -1
+		r.Header.Del(eudore.HeaderContentEncoding)
+		r.Header.Del(eudore.HeaderContentLength)
+	}
+}
+
+func newDecompressGzip(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// newDecompressDeflate 函数解压deflate，兼容未使用zlib格式封装的原始deflate数据。
+func newDecompressDeflate(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(2)
+	if err == nil && head[0]&0x0f == 8 && (uint16(head[0])<<8|uint16(head[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// decompressCounter 定义统计压缩数据读取字节数的Reader。
+type decompressCounter struct {
+	io.Reader
+	size int64
+}
+
+func (r *decompressCounter) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// decompressReader 定义解压后的请求body，检查解压大小和解压比。
+type decompressReader struct {
+	io.Reader
+	ctx     eudore.Context
+	counter *decompressCounter
+	closer  io.Closer
+	size    int64
+	ratio   int64
+	read    int64
+	err     error
+}
+
+func (r *decompressReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := r.Reader.Read(p)
+	r.read += int64(n)
+	switch {
+	case r.size > 0 && r.read > r.size:
+		r.err = ErrDecompressTooLarge
+	case r.ratio > 0 && r.read > 64<<10 && r.read > r.counter.size*r.ratio:
+		r.err = ErrDecompressRatio
+	default:
+		return n, err
+	}
+	if r.ctx.Response().Size() == 0 {
+		r.ctx.WriteHeader(eudore.StatusRequestEntityTooLarge)
+	}
+	return 0, r.err
+}
+
+func (r *decompressReader) Close() error {
+	if closer, ok := r.Reader.(io.Closer); ok {
+		closer.Close()
+	}
+	return r.closer.Close()
+}
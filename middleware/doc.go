@@ -1,6 +1,40 @@
 /*
 Package middleware 包实现eudore基础请求中间件。
 
+APIDoc
+
+实现使用路由注册的eudore.RouterDoc生成api文档页面，按Tags分组显示
+
+参数:
+	eudore.Router    读取路由文档的路由器，需要实现Routes方法
+example:
+	app.GetFunc("/docs", middleware.NewAPIDocFunc(app.Router))
+	app.GetFunc("/users/:id", eudore.RouterDoc{Summary: "获取用户", Tags: []string{"user"}}, handler)
+
+ApiKey
+
+实现API Key认证，默认读取X-Api-Key Header，路由参数scope设置需要的授权范围，apikey组件实现凭证管理
+
+参数:
+	ApiKeyStore       校验API Key的存储，实现ApiKeyCertificateStore时没有API Key使用客户端证书认证
+	...interface{}    额外使用的Options,根据类型来断言设置选项
+		string    =>    读取API Key的Header，默认为X-Api-Key
+example:
+	app.AddMiddleware(middleware.NewApiKeyFunc(apikey.NewStore(nil)))
+	app.GetFunc("/orders scope=orders:read", handler)
+
+Bandwidth
+
+实现令牌桶限制响应写入速度，默认按连接限制
+
+参数:
+	int64             每秒允许写入的字节数，路由参数bandwidth可以设置组路由或路由的速度，bandwidth=0表示不限制
+	...interface{}    额外使用的Options,根据类型来断言设置选项
+		context.Context               =>    控制cleanupBuckets退出的生命周期
+		func(eudore.Context) string   =>    获取限制key的函数，默认使用连接地址
+example:
+	app.AddMiddleware(middleware.NewBandwidthFunc(1<<20, app.Context))
+
 BasicAuth
 
 实现请求BasicAuth访问认证
@@ -50,6 +84,115 @@ example:
 
 在关闭状态下连续错误一定次数后熔断器进入半开状态；在半开状态下请求将进入限流状态，半开连续错误一定次数后进入打开状态，半开连续成功一定次数后回到关闭状态；在进入关闭状态后等待一定时间后恢复到半开状态。
 
+Buffer
+
+缓冲不超过指定大小的响应，处理结束后设置Content-Length一次写入，缓冲期间写入大于等于400的状态码时丢弃已经缓冲的body，超过缓冲大小或调用Flush后切换为流式写入
+
+参数:
+	int    缓冲大小，路由参数buffer可以设置组路由或路由的缓冲大小，buffer=0表示不缓冲
+example:
+	app.AddMiddleware(middleware.NewBufferFunc(64 << 10))
+	app.Group("/stream buffer=0")
+
+Cache
+
+实现响应缓存，缓存GET请求的200响应，按响应Vary Header列出的请求Header区分缓存，设置X-Cache Header为HIT或MISS，
+缓存时间优先使用响应Cache-Control的s-maxage和max-age，单实例部署可以使用eudore.NewCacheLRU按字节数限制的LRU内存缓存，
+请求携带Authorization或Cookie时只缓存Cache-Control包含public或s-maxage的响应
+
+参数:
+	eudore.Cache                   响应存储
+	time.Duration                  响应没有Cache-Control缓存时间时使用的缓存时间，默认0不缓存
+	int                            缓存响应body的最大长度，默认1MB
+	func(eudore.Context) string    获取缓存key的函数，默认使用Host和RequestURI
+example:
+	lru := eudore.NewCacheLRU(64<<20, 16)
+	app.AddMiddleware(middleware.NewCacheFunc(lru, time.Minute))
+
+CachePolicy
+
+实现响应缓存策略，统一设置Cache-Control、Expires和Vary Header，使用路由参数cache选择策略，错误响应和处理函数设置了Cache-Control时不使用策略，
+策略开启ETag时使用NewETagFunc计算GET响应的弱ETag，If-None-Match匹配时返回304
+
+参数:
+	map[string]*CachePolicy    策略名称和策略，没有cache参数时使用名称为空字符串的策略
+example:
+	app.AddMiddleware(middleware.NewCachePolicyFunc(map[string]*middleware.CachePolicy{
+		"":       {NoStore: true},
+		"static": {Public: true, MaxAge: time.Hour, Immutable: true},
+		"page":   {Public: true, MaxAge: time.Minute, StaleWhileRevalidate: 10 * time.Minute, ETag: true},
+	}))
+	app.GetFunc("/static/* cache=static", handler)
+	app.AddMiddleware(middleware.NewETagFunc(1 << 20))
+
+Canary
+
+实现A/B分流，按权重、Header或Cookie粘性将请求分配给stable或canary处理函数，并统计每个版本的请求数、错误数和耗时
+
+参数:
+- eudore.Router    注入GET /canary和PUT /canary/weight/:weight路由查看指标和修改权重
+属性:
+- Weight    int32                  canary版本的请求百分比
+- Header    string                 强制选择版本的Header，默认X-Canary
+- Cookie    string                 保存选择版本的Cookie，默认_canary
+- MaxAge    int                    Cookie有效时间
+- Stable    eudore.HandlerFuncs    stable版本处理函数，为空时继续执行路由处理函数
+- Canary    eudore.HandlerFuncs    canary版本处理函数
+
+example:
+
+	app.AddMiddleware(middleware.NewCanaryFunc(10, eudore.HandlerFuncs{handlerV2}))
+
+	canary := middleware.NewCanary(10, nil, eudore.HandlerFuncs{handlerV2})
+	app.AddMiddleware("/api", canary.NewCanaryFunc(app.Group("/eudore/debug")))
+
+选择版本的顺序为Header、Cookie、权重随机，选择的版本设置到canary参数。
+
+Chain
+
+使用配置声明中间件链，按名称从注册的构造函数创建中间件，运维调整中间件顺序和开关时修改配置即可，不需要重新编译，
+声明可以是名称、名称和json配置或包含name属性的对象，配置disable为true时跳过，使用RegisterChain注册自定义中间件
+
+参数:
+	*eudore.App    app对象
+	interface{}    中间件声明列表
+example:
+	app.Set("middleware", []interface{}{"recover", "requestid", "gzip:{level:5}",
+		map[string]interface{}{"name": "rate", "speed": 10, "max": 20}})
+	hs, err := middleware.NewChainFuncs(app, app.Get("middleware"))
+	app.AddMiddleware(hs)
+
+Compress
+
+根据Accept-Encoding的q值选择响应压缩编码，q值相同时按优先顺序选择，使用sync.Pool复用编码器，默认支持gzip和deflate，zstd和br需要使用第三方库添加
+
+参数:
+	...interface{}    额外使用的Options,根据类型来断言设置选项
+		map[string]CompressFunc    =>    添加或替换压缩编码，例如zstd、br
+		map[string]int             =>    设置编码的压缩等级，默认gzip和deflate为5
+		[]string                   =>    设置q值相同时编码的优先顺序，默认zstd、br、gzip、deflate
+example:
+	app.AddMiddleware(middleware.NewCompressFunc(map[string]int{"gzip": 6}))
+
+ConnGuard
+
+实现连接级别防护，包装监听限制每个IP的并发连接数，客户端触发限制(超过并发连接数、读取请求header超时、请求返回429)达到次数后临时封禁IP，
+封禁写入NewBlack创建的黑名单，拒绝的连接延迟Tarpit时间后关闭，读取请求header超时时间使用ServerStdConfig.ReadHeaderTimeout设置，
+客户端ip使用连接地址，仅连接地址属于TrustedProxies时读取X-Forwarded-For
+
+参数:
+	*Black    共享的黑名单，为空时创建新的黑名单
+example:
+	black := middleware.NewBlack(nil)
+	guard := middleware.NewConnGuard(black)
+	eudore.Set(app.Server, "", eudore.ServerStdConfig{
+		ReadHeaderTimeout: eudore.TimeDuration(5 * time.Second),
+		ConnState:         guard.ConnState,
+	})
+	app.AddMiddleware(guard.NewConnGuardFunc(), black.HandleHTTP)
+	ln, _ := net.Listen("tcp", ":8088")
+	app.Serve(guard.Listener(ln))
+
 ContextWarp
 
 使中间件之后的处理函数使用的eudore.Context对象为新的Context
@@ -99,6 +242,18 @@ Csrf
 example:
 	app.AddMiddleware(middleware.NewCsrfFunc("csrf", nil))
 
+Decompress
+
+根据Content-Encoding解压gzip、deflate请求body，防止解压炸弹，需要在读取body的中间件之前注册
+
+参数:
+	int64             解压后最大字节数，超过时返回413，0为不限制
+	int64             解压后超过64KB时允许的最大解压比，超过时返回413，0为不限制
+	...interface{}    额外使用的Options,根据类型来断言设置选项
+		map[string]DecompressFunc    =>    添加或替换解压函数，key为Content-Encoding，例如zstd
+example:
+	app.AddMiddleware(middleware.NewDecompressFunc(10<<20, 100))
+
 Dump
 
 截取请求信息的中间件，将匹配请求使用webscoket输出给客户端。
@@ -108,6 +263,18 @@ Dump
 example:
 	app.AddMiddleware(middleware.NewDumpFunc(app.Group("/eudore/debug")))
 
+Expect
+
+处理Expect: 100-continue请求，在客户端发送body前拒绝超过大小或不接受的请求，客户端不会发送body，检查通过后立即写入100 Continue
+
+参数:
+	int64             允许的最大Content-Length，超过时返回413，路由参数expect可以设置组路由或路由的最大长度，0表示不限制
+	...interface{}    额外使用的Options,根据类型来断言设置选项
+		func(eudore.Context) bool    =>    检查是否接受请求body，返回false时返回417
+example:
+	app.AddMiddleware(middleware.NewExpectFunc(10 << 20))
+	app.PutFunc("/upload/* expect=104857600", handler)
+
 Gzip
 
 对请求响应body使用gzip压缩
@@ -117,6 +284,33 @@ Gzip
 example:
 	app.AddMiddleware(middleware.NewGzipFunc(5))
 
+Honeypot
+
+实现蜜罐和爬虫防护，访问蜜罐路径或请求评分(缺少User-Agent/Accept、爬虫User-Agent、404响应)累计达到阈值后使用ConnGuard临时封禁IP并写入黑名单，
+可选cookie或pow(工作量证明)挑战，客户端需要执行js写入签名cookie后才能访问，客户端ip使用ConnGuard.RealIP获取
+
+参数:
+	*ConnGuard    封禁使用的ConnGuard，共享黑名单和封禁记录，为空时创建新的ConnGuard
+	...string     蜜罐路径前缀，为空时使用默认扫描路径
+example:
+	app.AddMiddleware(middleware.NewHoneypotFunc(guard, "/.env", "/wp-admin", "/hidden-link"))
+	honeypot := middleware.NewHoneypot(guard)
+	honeypot.Challenge = middleware.HoneypotChallengePoW
+	app.AddMiddleware(honeypot.NewHoneypotFunc())
+
+Idempotency
+
+实现Idempotency-Key幂等请求，保存POST和PATCH请求的第一次响应，重试请求直接返回保存的响应，相同key的请求仍在处理中返回409
+
+参数:
+	...interface{}    额外使用的Options,根据类型来断言设置选项
+		IdempotencyStore              =>    响应存储，默认使用内存存储
+		eudore.Cache                  =>    使用缓存作为响应存储，例如app.Cache
+		time.Duration                 =>    响应保存时间，默认24小时
+		func(eudore.Context) string   =>    获取key的函数，默认使用请求方法、路径和Idempotency-Key Header
+example:
+	app.AddMiddleware(middleware.NewIdempotencyFunc(time.Hour))
+
 Logger
 
 输出请求access logger并记录相关fields
@@ -127,6 +321,96 @@ Logger
 example:
 	app.AddMiddleware(middleware.NewLoggerFunc(app, "route"))
 
+Maintenance
+
+实现维护模式，开启后非白名单路由返回503维护响应，/health开头的路径和路由参数maintenance=allow的路由允许访问
+
+参数:
+	eudore.Router    注入GET /maintenance和PUT /maintenance/:state路由查看和修改状态
+属性:
+	Allows        []string         允许访问的路径前缀
+	Response      interface{}      维护响应内容
+	RetryAfter    time.Duration    Retry-After Header
+example:
+	maintenance := middleware.NewMaintenance()
+	maintenance.WatchSignal(app, app, syscall.SIGUSR2)
+	app.AddMiddleware(maintenance.NewMaintenanceFunc(app.Group("/eudore/debug")))
+
+Mirror
+
+实现请求镜像，按百分比将请求和body异步复制到upstream，忽略镜像响应，不影响原请求延迟
+
+参数:
+	string            镜像upstream地址
+	int               镜像请求百分比
+	...interface{}    额外使用的Options,根据类型来断言设置选项
+		*http.Client    =>    发送镜像请求的客户端，默认超时5秒
+		int             =>    最大并发镜像请求数量，默认100
+		int64           =>    最大镜像body长度，默认4MB
+example:
+	app.AddMiddleware(middleware.NewMirrorFunc("http://127.0.0.1:8089", 10))
+
+Nonce
+
+拒绝重放请求，请求使用X-Nonce Header传递唯一nonce，X-Nonce-Timestamp Header传递unix秒时间戳，nonce或时间戳无效返回400，nonce重复使用返回409
+
+参数:
+	...interface{}         额外使用的Options,根据类型来断言设置选项
+		time.Duration                  =>    时间窗口，默认5分钟，nonce保存两倍时间窗口
+		NonceStore                     =>    nonce存储，默认使用内存存储
+		eudore.Cache                   =>    使用NewNonceStoreCache创建nonce存储，多实例共享nonce
+		func(eudore.Context) string    =>    nonce的作用域，例如返回客户端id
+example:
+	app.AddMiddleware(middleware.NewNonceFunc(app.Cache, time.Minute))
+
+OIDC
+
+实现OpenID Connect授权码模式登录，使用PKCE，登录后使用GetOIDCClaims函数获取userinfo claims，
+state和PKCE verifier签名保存在短期Cookie中绑定发起登录的浏览器，不校验id_token
+
+参数:
+	eudore.Router        注入/oidc/login/:provider、/oidc/callback/:provider、/oidc/logout路由
+	...*OIDCProvider     身份提供者，第一个为默认身份提供者
+example:
+	app.AddMiddleware(middleware.NewOIDCFunc(app.Group("/auth"), &middleware.OIDCProvider{
+		Name:         "github",
+		ClientID:     "client_id",
+		ClientSecret: "client_secret",
+		AuthURL:      "https://provider/authorize",
+		TokenURL:     "https://provider/token",
+		UserinfoURL:  "https://provider/userinfo",
+		RedirectURL:  "https://localhost/auth/oidc/callback/github",
+	}))
+
+Queue
+
+实现路由请求并发限制和排队，队列已满或排队超时返回503并设置Retry-After Header
+
+参数:
+	int               每个路由最多同时处理的请求数量
+	int               每个路由最多排队等待的请求数量
+	...interface{}    额外使用的Options,根据类型来断言设置选项
+		time.Duration                 =>    请求排队等待的最大时间，默认1秒
+		func(eudore.Context) string   =>    获取限制key的函数，默认使用route参数
+example:
+	app.AddMiddleware(middleware.NewQueueFunc(10, 20, time.Second*3))
+
+Quota
+
+实现配额统计，依次按API Key、UID、租户或IP统计滚动窗口内的请求数和响应字节数，设置X-RateLimit-*和X-Quota-Bytes-* Header，超出配额返回429，
+租户需要使用Tenant检查函数校验，校验后读取租户配置quota.requests和quota.bytes，注入/quota/data、/quota/key/:key后台接口查看和重置用量
+
+参数:
+	int64                                  窗口内最大请求数，为0时不限制
+	int64                                  窗口内最大响应字节数，为0时不限制
+	time.Duration                          滚动窗口时间，默认1小时
+	QuotaStore                             配额计数存储，默认使用内存存储
+	func(eudore.Context) string            获取统计key的函数
+	func(eudore.Context) (int64, int64)    获取请求配额的函数
+	eudore.Router                          注入配额后台路由
+example:
+	app.AddMiddleware(middleware.NewTenantFunc("default", app.Config), middleware.NewQuotaFunc(1000, 1<<30, time.Hour, app.Group("/admin")))
+
 Rate
 
 实现请求令牌桶限流
@@ -138,6 +422,7 @@ Rate
 		context.Context               =>    控制cleanupVisitors退出的生命周期
 		time.Duration                 =>    基础时间周期单位，默认秒
 		func(eudore.Context) string   =>    限流获取key的函数，默认Context.ReadIP
+		RateStore                     =>    限流计数存储，多实例共享存储时限流在全部实例生效
 example:
 	app.AddMiddleware(middleware.NewRateFunc(1, 3, app.Context))
 
@@ -195,6 +480,31 @@ example:
 		"/help/*":        "$0",
 	}))
 
+RewriteRules
+
+按规则重写请求，可以使用正则捕获组重写路径，添加、删除、重命名请求Header和请求参数，需要注册全局中间件
+
+参数:
+- []*RewriteRule    按顺序执行的重写规则
+属性:
+- Path              string               匹配路径的正则表达式，为空匹配全部请求
+- Target            string               目标路径，使用$1、${name}引用捕获组
+- Header            map[string]string    添加的请求Header
+- DelHeader         []string             删除的请求Header
+- RenameHeader      map[string]string    重命名请求Header
+- Query             map[string]string    设置的请求参数
+- DelQuery          []string             删除的请求参数
+- RenameQuery       map[string]string    重命名请求参数
+- ResponseHeader    map[string]string    添加的响应Header
+- Last              bool                 匹配后不再执行后续规则
+
+example:
+
+	app.AddMiddleware("global", middleware.NewRewriteRulesFunc([]*middleware.RewriteRule{
+		{Path: `^/api/v1/users/(\d+)$`, Target: "/api/v3/user/$1", RenameQuery: map[string]string{"uid": "id"}, Last: true},
+		{Path: `^/legacy/(.*)$`, Target: "/api/v3/$1", Header: map[string]string{"X-Legacy": "1"}},
+	}))
+
 Router
 
 用于执行额外的路由匹配行为
@@ -227,19 +537,142 @@ example:
 		"/help/*":        "$0",
 	}))
 
+Signature
+
+实现HMAC请求签名验证，签名原文默认为方法、uri、时间戳、nonce、body使用换行连接，使用nonce防止请求重放
+
+参数:
+	func(string) string    根据X-Signature-Client Header获取密钥的函数，密钥为空表示无效
+	...interface{}         额外使用的Options,根据类型来断言设置选项
+		time.Duration                 =>    允许的时间偏差，默认5分钟
+		func(eudore.Context) []byte   =>    签名原文的规范化函数
+		func() hash.Hash              =>    HMAC使用的hash函数，默认sha256
+		NonceStore                    =>    nonce存储，默认使用内存存储
+example:
+	app.AddMiddleware(middleware.NewSignatureFunc(func(client string) string {
+		return secrets[client]
+	}))
+
+SignedURL
+
+生成和校验有时效的签名URL，用于下载和上传接口临时授权，签名包含方法、path、过期时间和其他query参数，签名无效或过期返回403
+
+参数:
+	func() []string    返回当前密钥的函数，第一个密钥用于签名，全部密钥用于校验，每次请求调用用于读取配置轮换密钥
+example:
+	signer := middleware.NewSignedURL(func() []string {
+		return eudore.GetStrings(app.Get("signedurl.secrets"))
+	})
+	app.Group("/files").AddMiddleware(signer.NewSignedURLFunc())
+	url, err := signer.Sign("GET", "/files/report.pdf", time.Hour)
+
 SingleFlight
 
-同时多次请求同一资源时，缓存一份处理结果返回给全部请求
+同时多次请求同一资源时，缓存一份处理结果返回给全部请求，仅合并GET和HEAD请求。
 
+参数:
+	...func(eudore.Context) string    获取请求合并key的函数，默认为请求方法和uri
 example:
 	app.AddMiddleware(middleware.NewSingleFlightFunc())
+	app.AddMiddleware(middleware.NewSingleFlightFunc(func(ctx eudore.Context) string {
+		return ctx.Path()
+	}))
+
+Slow
+
+检测慢请求，请求处理时间超过阈值时输出Warning日志，包含路由、耗时和状态码
+
+参数:
+- time.Duration     慢请求阈值
+- ...interface{}    额外使用的Options,根据类型来断言设置选项
+	bool    =>    请求处理到达阈值时采样处理协程的栈，默认false
+
+example:
+
+	app.AddMiddleware(middleware.NewSlowFunc(time.Second, true))
+
+Tenant
+
+实现多租户解析，依次使用子域名、Header、路由参数等解析函数解析请求的租户，租户保存在请求Context中使用GetTenant函数获取，
+设置tenant参数和日志tenant属性用于按租户输出日志和统计，租户配置读取tenant.<name>.<key>覆盖默认配置，Tenant.NewRateFunc方法按租户限流，
+解析的租户由客户端决定，需要使用检查函数校验租户，租户限流最多保存MaxRates个并淘汰最久未使用的租户
+
+参数:
+	func(eudore.Context) string    租户解析函数，可以传入多个依次解析，默认解析X-Tenant-Id Header
+	string                         无法解析租户时使用的默认租户，为空时返回400
+	func(string) bool              检查租户是否存在，不存在返回404
+	eudore.Config                  租户配置，使用GetTenantValue函数获取
+example:
+	tenant := middleware.NewTenant(middleware.NewTenantSubdomain("example.com"), middleware.NewTenantHeader(middleware.HeaderXTenantID), app.Config)
+	app.AddMiddleware(middleware.NewLoggerFunc(app, "tenant"), tenant.HandleHTTP, tenant.NewRateFunc(10, 100, app.Context))
+
+Timing
+
+记录后续每个中间件和路由处理函数自身的执行耗时，在响应首次写入时设置Server-Timing header，并设置路由参数timing用于日志输出
+
+参数:
+- ...interface{}    额外使用的Options,根据类型来断言设置选项
+	bool      =>    是否设置Server-Timing header，默认true
+	string    =>    设置的路由参数名称，默认timing，为空时不设置
+
+example:
+
+	app.AddMiddleware(middleware.NewLoggerFunc(app, "route", "timing"))
+	app.AddMiddleware(middleware.NewTimingFunc())
 
 Timeout
 
-设置请求处理超时时间，如果超时返回503状态码并取消context，
+设置请求处理超时时间，如果超时返回503状态码并取消context，超时时间按路由参数timeout、组路由参数timeout、全局timeout的顺序生效，timeout=0表示不限制
+
+参数:
+- time.Duration    全局超时时间
+
+example:
+
+	app.AddMiddleware(middleware.NewTimeoutFunc(10 * time.Second))
+	app.Group("/api timeout=3s").GetFunc("/export timeout=30s", handler)
 
 实现难点：写入中超时状态码异常、panic栈无法捕捉信息异常、http.Header并发读写、sync.Pool回收了Context、Context数据竟态检测
 
+TOTP
+
+实现TOTP(RFC 6238)二次验证，保护敏感路由组，注入POST /totp/verify验证路由，验证状态保存在OIDC会话存储中，
+用户验证失败5次后锁定15分钟返回429，可以设置Throttle使用password.Throttle
+
+参数:
+	eudore.Router                                         注入验证路由的路由器
+	OIDCSessionStore                                      会话存储，使用OIDC的Store共享登录会话
+	func(context.Context, string) (string, error)         获取用户TOTP密钥的函数，密钥为空表示未启用
+example:
+	secret, err := middleware.NewTOTPSecret()
+	uri := middleware.NewTOTPURI("eudore", "alice", secret)
+	app.Group("/admin").AddMiddleware(middleware.NewTOTPFunc(app, oidc.Store, getSecret))
+
+UserAgent
+
+解析请求User-Agent的浏览器、操作系统和设备类型，解析结果按User-Agent缓存，使用GetUserAgent函数获取解析结果，并设置browser、os、device参数用于日志输出
+
+example:
+	app.AddMiddleware(middleware.NewUserAgentFunc(), middleware.NewLoggerFunc(app, "browser", "os", "device"))
+	ua := middleware.GetUserAgent(ctx)
+
+When
+
+按条件执行中间件，条件不成立时跳过中间件继续执行后续处理函数，返回的处理函数名称为when(h)
+
+参数:
+- func(eudore.Context) bool    执行条件，可以使用WhenPath、WhenMethod、WhenHeader、WhenMode、WhenNot、WhenAll、WhenAny组合
+- eudore.HandlerFunc           条件成立时执行的中间件
+
+example:
+
+	app.AddMiddleware(middleware.NewWhenFunc(middleware.WhenAll(
+		middleware.WhenMethod("POST", "PUT", "DELETE"),
+		middleware.WhenNot(middleware.WhenPath("/api/public/*")),
+	), middleware.NewCsrfFunc("query: csrf", "_csrf")))
+
+AddMiddleware在路径参数后可以指定int类型的优先级，优先级高的中间件先执行，RouterStd.GetMiddlewares返回路径实际生效的中间件链。
+
 */
 package middleware // import "github.com/eudore/eudore/middleware"
 
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/eudore/eudore"
+)
+
+// NewExpectFunc 函数创建一个Expect: 100-continue处理函数，在客户端发送body前检查请求，拒绝请求时客户端不会发送body。
+//
+// size为允许的最大Content-Length，超过时返回413，路由参数expect可以设置组路由或路由的最大长度，0表示不限制长度；
+// 检查通过后立即写入100 Continue，需要在认证等中间件之后注册。
+//
+// options:
+// func(eudore.Context) bool    =>    检查是否接受请求body，返回false时返回417
+func NewExpectFunc(size int64, options ...interface{}) eudore.HandlerFunc {
+	var accepts []func(eudore.Context) bool
+	for _, i := range options {
+		switch val := i.(type) {
+		case func(eudore.Context) bool:
+			accepts = append(accepts, val)
+		}
+	}
+	return func(ctx eudore.Context) {
+		if !strings.EqualFold(ctx.GetHeader(eudore.HeaderExpect), "100-continue") {
+			return
+		}
+		limit := size
+		if val := ctx.GetParam("expect"); val != "" {
+			limit = eudore.GetStringInt64(val)
+		}
+		if limit > 0 && ctx.Request().ContentLength > limit {
+			rejectExpect(ctx, eudore.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		for _, fn := range accepts {
+			if !fn(ctx) {
+				rejectExpect(ctx, eudore.StatusExpectationFailed, "expectation failed")
+				return
+			}
+		}
+		ctx.AcceptContinue()
+	}
+}
+
+// rejectExpect 函数拒绝请求body，设置Connection: close使客户端不再发送body。
+func rejectExpect(ctx eudore.Context, code int, msg string) {
+	ctx.SetHeader(eudore.HeaderConnection, "close")
+	ctx.WriteHeader(code)
+	ctx.Fatal(msg)
+	ctx.End()
+}
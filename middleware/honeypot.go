@@ -0,0 +1,235 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// 定义Honeypot客户端挑战方式。
+const (
+	HoneypotChallengeCookie = "cookie"
+	HoneypotChallengePoW    = "pow"
+)
+
+// Honeypot 定义蜜罐和爬虫防护，请求评分累计达到阈值后使用ConnGuard封禁IP，封禁同时写入黑名单。
+//
+// 访问蜜罐路径直接达到阈值，其他请求使用Score函数评分，
+// 开启挑战后客户端需要执行js写入cookie或完成工作量证明，不执行js的爬虫无法访问。
+type Honeypot struct {
+	sync.Mutex
+	Guard *ConnGuard
+	// Paths 为蜜罐路径前缀，正常用户不会访问，例如页面中隐藏的链接和常见扫描路径。
+	Paths     []string
+	Threshold int
+	Window    time.Duration
+	BanTime   time.Duration
+	// Score 为请求评分函数，在请求处理后执行，默认为HoneypotScore。
+	Score func(eudore.Context) int
+	// Challenge 为挑战方式，可选cookie和pow，默认为空不挑战。
+	Challenge string
+	// Difficulty 为工作量证明要求sha256前导零的位数，默认16。
+	Difficulty int
+	// Secret 为挑战cookie的签名密钥，默认随机生成，多实例需要设置相同密钥。
+	Secret  []byte
+	Cookie  string
+	Expires time.Duration
+	scores  map[string]*connGuardStrike
+}
+
+// NewHoneypotFunc 函数创建一个蜜罐处理函数，guard为空时创建新的ConnGuard，paths为空时使用默认扫描路径。
+func NewHoneypotFunc(guard *ConnGuard, paths ...string) eudore.HandlerFunc {
+	h := NewHoneypot(guard)
+	if len(paths) > 0 {
+		h.Paths = paths
+	}
+	return h.NewHoneypotFunc()
+}
+
+// NewHoneypot 函数创建蜜罐和爬虫防护，guard为空时创建新的ConnGuard，使用guard.Black共享黑名单。
+func NewHoneypot(guard *ConnGuard) *Honeypot {
+	if guard == nil {
+		guard = NewConnGuard(nil)
+	}
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	return &Honeypot{
+		Guard:      guard,
+		Paths:      []string{"/.env", "/.git/", "/wp-admin", "/wp-login.php", "/phpmyadmin", "/xmlrpc.php"},
+		Threshold:  100,
+		Window:     10 * time.Minute,
+		BanTime:    time.Hour,
+		Score:      HoneypotScore,
+		Difficulty: 16,
+		Secret:     secret,
+		Cookie:     "_honeypot",
+		Expires:    24 * time.Hour,
+		scores:     make(map[string]*connGuardStrike),
+	}
+}
+
+// NewHoneypotFunc 方法返回蜜罐处理函数，白名单IP不检查，封禁IP返回403，蜜罐路径返回404。
+//
+// 客户端ip使用Guard.RealIP获取，部署在反向代理后需要设置Guard.TrustedProxies。
+func (h *Honeypot) NewHoneypotFunc() eudore.HandlerFunc {
+	return func(ctx eudore.Context) {
+		ip := h.Guard.RealIP(ctx)
+		if h.Guard.Black.IsWhite(ip) {
+			return
+		}
+		if h.Guard.Banned(ip) || h.Guard.Black.Deny(ip) {
+			ctx.WriteHeader(eudore.StatusForbidden)
+			ctx.WriteString("honeypot deny your ip")
+			ctx.End()
+			return
+		}
+		if h.isTrap(ctx.Path()) {
+			h.AddScore(ip, h.Threshold)
+			ctx.WriteHeader(eudore.StatusNotFound)
+			ctx.End()
+			return
+		}
+
+		if h.Challenge != "" && !h.verify(ip, ctx.GetCookie(h.Cookie)) {
+			h.challenge(ctx, ip)
+			ctx.End()
+		} else {
+			ctx.Next()
+		}
+		if h.Score != nil {
+			h.AddScore(ip, h.Score(ctx))
+		}
+	}
+}
+
+// HoneypotScore 函数是默认请求评分函数，缺少User-Agent或Accept、爬虫User-Agent和404响应增加评分。
+func HoneypotScore(ctx eudore.Context) int {
+	var score int
+	ua := ctx.GetHeader(eudore.HeaderUserAgent)
+	switch {
+	case ua == "":
+		score += 10
+	case ParseUserAgent(ua).Device == DeviceBot:
+		score += 5
+	}
+	if ctx.GetHeader(eudore.HeaderAccept) == "" {
+		score += 5
+	}
+	if ctx.Response().Status() == eudore.StatusNotFound {
+		score += 2
+	}
+	return score
+}
+
+// AddScore 方法给IP增加评分，在Window时间内累计达到Threshold后封禁BanTime时间，封禁时返回true。
+func (h *Honeypot) AddScore(ip string, score int) bool {
+	if score <= 0 || h.Guard.Black.IsWhite(ip) {
+		return false
+	}
+	now := time.Now()
+	h.Lock()
+	if len(h.scores) >= 4096 {
+		for k, v := range h.scores {
+			if now.After(v.Reset) {
+				delete(h.scores, k)
+			}
+		}
+	}
+	s, ok := h.scores[ip]
+	if !ok || now.After(s.Reset) {
+		s = &connGuardStrike{Reset: now.Add(h.Window)}
+		h.scores[ip] = s
+	}
+	s.Count += score
+	ban := s.Count >= h.Threshold
+	if ban {
+		delete(h.scores, ip)
+	}
+	h.Unlock()
+	if ban {
+		h.Guard.Ban(ip, h.BanTime)
+	}
+	return ban
+}
+
+func (h *Honeypot) isTrap(path string) bool {
+	for _, trap := range h.Paths {
+		if strings.HasPrefix(path, trap) {
+			return true
+		}
+	}
+	return false
+}
+
+// challenge 方法返回挑战页面，页面js写入签名cookie后刷新页面，pow方式需要先计算工作量证明。
+//
+// pow使用crypto.subtle计算sha256，浏览器仅在https或localhost下支持。
+func (h *Honeypot) challenge(ctx eudore.Context, ip string) {
+	token := h.sign(ip, time.Now().Add(h.Expires).Unix())
+	cookie := fmt.Sprintf("%s=\"+v+\";path=/;max-age=%d", h.Cookie, int(h.Expires/time.Second))
+	script := `var v="` + token + `";`
+	if h.Challenge == HoneypotChallengePoW {
+		script = fmt.Sprintf(`var c="%s",d=%d,e=new TextEncoder(),v;
+function z(h){for(var i=0;i<d;i++){if(h[i>>3]>>(7-(i&7))&1)return false}return true}
+for(var n=0;;n++){if(z(new Uint8Array(await crypto.subtle.digest("SHA-256",e.encode(c+n))))){v=c+"."+n;break}}`, token, h.Difficulty)
+	}
+	ctx.SetHeader(eudore.HeaderContentType, eudore.MimeTextHTMLCharsetUtf8)
+	ctx.SetHeader(eudore.HeaderCacheControl, "no-store")
+	ctx.WriteHeader(eudore.StatusForbidden)
+	ctx.WriteString(`<!DOCTYPE html><html><head><title>Checking your browser</title></head><body>
+<noscript>Please enable JavaScript to continue.</noscript>
+<script>(async function(){` + script + `
+document.cookie="` + cookie + `";location.reload()})()</script></body></html>`)
+}
+
+// verify 方法检查挑战cookie，cookie为expires.signature，pow方式追加.nonce。
+func (h *Honeypot) verify(ip, cookie string) bool {
+	strs := strings.Split(cookie, ".")
+	if len(strs) < 2 || (h.Challenge == HoneypotChallengePoW) != (len(strs) == 3) {
+		return false
+	}
+	expires, err := strconv.ParseInt(strs[0], 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+	token := h.sign(ip, expires)
+	if !hmac.Equal([]byte(token), []byte(strs[0]+"."+strs[1])) {
+		return false
+	}
+	if h.Challenge == HoneypotChallengePoW {
+		sum := sha256.Sum256([]byte(token + strs[2]))
+		return getHoneypotZeroBits(sum[:]) >= h.Difficulty
+	}
+	return true
+}
+
+func (h *Honeypot) sign(ip string, expires int64) string {
+	exp := strconv.FormatInt(expires, 10)
+	mac := hmac.New(sha256.New, h.Secret)
+	mac.Write([]byte(ip + "\n" + exp))
+	return exp + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// getHoneypotZeroBits 函数计算前导零的位数。
+func getHoneypotZeroBits(b []byte) int {
+	var n int
+	for _, c := range b {
+		if c != 0 {
+			for c&0x80 == 0 {
+				n++
+				c <<= 1
+			}
+			return n
+		}
+		n += 8
+	}
+	return n
+}
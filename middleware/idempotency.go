@@ -0,0 +1,242 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// IdempotencyStore 定义Idempotency-Key响应存储，可以使用外部存储实现多实例共享。
+type IdempotencyStore interface {
+	// Begin 方法尝试占用key，如果key已经存在返回保存的响应和true，响应为nil表示请求仍在处理中。
+	Begin(string, time.Duration) (*IdempotencyResponse, bool)
+	// Save 方法保存key对应的响应。
+	Save(string, *IdempotencyResponse, time.Duration)
+	// Delete 方法删除key，允许后续请求重新处理。
+	Delete(string)
+}
+
+// IdempotencyResponse 定义保存的响应数据。
+type IdempotencyResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// NewIdempotencyFunc 函数创建一个Idempotency-Key处理函数。
+//
+// 仅处理POST和PATCH请求，第一次请求的响应会被保存，相同key的重试请求直接返回保存的响应，
+// 如果相同key的请求仍在处理中返回409，处理函数返回5xx或panic时不保存响应。
+//
+// options:
+// IdempotencyStore              =>    响应存储，默认使用内存存储
+// eudore.Cache                  =>    使用缓存作为响应存储，例如app.Cache
+// time.Duration                 =>    响应保存时间，默认24小时
+// func(eudore.Context) string   =>    获取key的函数，默认使用Idempotency-Key Header
+func NewIdempotencyFunc(options ...interface{}) eudore.HandlerFunc {
+	var store IdempotencyStore
+	ttl := 24 * time.Hour
+	getkey := getIdempotencyKey
+	for _, i := range options {
+		switch val := i.(type) {
+		case IdempotencyStore:
+			store = val
+		case eudore.Cache:
+			store = NewIdempotencyStoreCache(val)
+		case time.Duration:
+			ttl = val
+		case func(eudore.Context) string:
+			getkey = val
+		}
+	}
+	if store == nil {
+		store = NewIdempotencyStoreMemory()
+	}
+	return func(ctx eudore.Context) {
+		switch ctx.Method() {
+		case eudore.MethodPost, eudore.MethodPatch:
+		default:
+			return
+		}
+		key := getkey(ctx)
+		if key == "" {
+			return
+		}
+
+		resp, ok := store.Begin(key, ttl)
+		if ok {
+			if resp == nil {
+				writeFatal(ctx, eudore.StatusConflict, "idempotency request is processing: "+key)
+				return
+			}
+			resp.WriteData(ctx.Response())
+			ctx.End()
+			return
+		}
+
+		// 处理函数panic时删除key，允许客户端重试。
+		var finish bool
+		w := &idempotencyResponse{ResponseWriter: ctx.Response()}
+		defer func() {
+			if !finish {
+				store.Delete(key)
+			}
+		}()
+		ctx.SetResponse(w)
+		ctx.Next()
+		ctx.SetResponse(w.ResponseWriter)
+		finish = true
+
+		status := w.Status()
+		if status >= 500 {
+			store.Delete(key)
+			return
+		}
+		header := make(http.Header, len(w.Header()))
+		for k, v := range w.Header() {
+			header[k] = append([]string(nil), v...)
+		}
+		store.Save(key, &IdempotencyResponse{
+			Status: status,
+			Header: header,
+			Body:   w.Bytes(),
+		}, ttl)
+	}
+}
+
+// getIdempotencyKey 函数返回请求方法、路径和Idempotency-Key Header组合成的默认key。
+func getIdempotencyKey(ctx eudore.Context) string {
+	key := ctx.GetHeader(eudore.HeaderIdempotencyKey)
+	if key == "" {
+		return ""
+	}
+	return ctx.Method() + " " + ctx.Path() + " " + key
+}
+
+// WriteData 方法将保存的响应写入到请求响应。
+func (resp *IdempotencyResponse) WriteData(w eudore.ResponseWriter) {
+	h := w.Header()
+	for k, v := range resp.Header {
+		h[k] = append([]string(nil), v...)
+	}
+	w.WriteHeader(resp.Status)
+	w.Write(resp.Body)
+}
+
+// idempotencyResponse 定义记录写入数据的ResponseWriter。
+type idempotencyResponse struct {
+	eudore.ResponseWriter
+	bytes.Buffer
+}
+
+// Write 方法实现ResponseWriter中的Write方法。
+func (w *idempotencyResponse) Write(data []byte) (int, error) {
+	w.Buffer.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// idempotencyStoreMemory 定义基于内存的IdempotencyStore。
+type idempotencyStoreMemory struct {
+	sync.Mutex
+	data  map[string]*idempotencyItem
+	clean time.Time
+}
+
+type idempotencyItem struct {
+	resp   *IdempotencyResponse
+	expire time.Time
+}
+
+// NewIdempotencyStoreMemory 函数创建一个基于内存的IdempotencyStore。
+func NewIdempotencyStoreMemory() IdempotencyStore {
+	return &idempotencyStoreMemory{
+		data:  make(map[string]*idempotencyItem),
+		clean: time.Now(),
+	}
+}
+
+// Begin 方法尝试占用key，如果key存在且未过期返回保存的响应。
+func (s *idempotencyStoreMemory) Begin(key string, ttl time.Duration) (*IdempotencyResponse, bool) {
+	s.Lock()
+	defer s.Unlock()
+	now := time.Now()
+	s.cleanup(now, ttl)
+	item, ok := s.data[key]
+	if ok && now.Before(item.expire) {
+		return item.resp, true
+	}
+	s.data[key] = &idempotencyItem{expire: now.Add(ttl)}
+	return nil, false
+}
+
+// Save 方法保存key对应的响应。
+func (s *idempotencyStoreMemory) Save(key string, resp *IdempotencyResponse, ttl time.Duration) {
+	s.Lock()
+	s.data[key] = &idempotencyItem{resp: resp, expire: time.Now().Add(ttl)}
+	s.Unlock()
+}
+
+// Delete 方法删除key。
+func (s *idempotencyStoreMemory) Delete(key string) {
+	s.Lock()
+	delete(s.data, key)
+	s.Unlock()
+}
+
+// cleanup 方法每个ttl周期清理一次过期数据。
+func (s *idempotencyStoreMemory) cleanup(now time.Time, ttl time.Duration) {
+	if now.Sub(s.clean) < ttl {
+		return
+	}
+	s.clean = now
+	for key, item := range s.data {
+		if now.After(item.expire) {
+			delete(s.data, key)
+		}
+	}
+}
+
+// idempotencyStoreCache 定义基于eudore.Cache的IdempotencyStore。
+type idempotencyStoreCache struct {
+	cache eudore.Cache
+}
+
+// NewIdempotencyStoreCache 函数使用eudore.Cache创建IdempotencyStore，使用共享缓存时多实例共享响应。
+func NewIdempotencyStoreCache(cache eudore.Cache) IdempotencyStore {
+	return &idempotencyStoreCache{cache: cache}
+}
+
+// Begin 方法使用Cache.Add占用key，空值表示请求仍在处理中。
+func (s *idempotencyStoreCache) Begin(key string, ttl time.Duration) (*IdempotencyResponse, bool) {
+	key = "idempotency:" + key
+	ok, err := s.cache.Add(key, nil, ttl)
+	if ok || err != nil {
+		return nil, false
+	}
+	body, err := s.cache.Get(key)
+	if err != nil || len(body) == 0 {
+		return nil, err == nil
+	}
+	resp := &IdempotencyResponse{}
+	if json.Unmarshal(body, resp) != nil {
+		return nil, true
+	}
+	return resp, true
+}
+
+// Save 方法保存key对应的响应。
+func (s *idempotencyStoreCache) Save(key string, resp *IdempotencyResponse, ttl time.Duration) {
+	body, err := json.Marshal(resp)
+	if err == nil {
+		s.cache.Set("idempotency:"+key, body, ttl)
+	}
+}
+
+// Delete 方法删除key。
+func (s *idempotencyStoreCache) Delete(key string) {
+	s.cache.Delete("idempotency:" + key)
+}
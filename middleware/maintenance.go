@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// Maintenance 定义维护模式开关，开启后非白名单路由返回503维护响应。
+type Maintenance struct {
+	enabled int32
+	// Allows 定义允许访问的路径前缀，默认允许/health开头的健康检查路径。
+	Allows []string `json:"allows"`
+	// Response 定义维护响应内容，使用ctx.Render渲染。
+	Response   interface{}   `json:"response"`
+	RetryAfter time.Duration `json:"retryafter"`
+}
+
+// NewMaintenanceFunc 函数创建一个维护模式处理函数。
+func NewMaintenanceFunc(router eudore.Router) eudore.HandlerFunc {
+	return NewMaintenance().NewMaintenanceFunc(router)
+}
+
+// NewMaintenance 函数创建一个关闭状态的维护模式开关。
+func NewMaintenance() *Maintenance {
+	return &Maintenance{
+		Allows: []string{"/health"},
+		Response: map[string]interface{}{
+			"status":  eudore.StatusServiceUnavailable,
+			"message": "service under maintenance",
+		},
+		RetryAfter: 5 * time.Minute,
+	}
+}
+
+// NewMaintenanceFunc 方法创建维护模式处理函数。
+//
+// router参数非空时注入GET /maintenance和PUT /maintenance/:state路由查看和修改状态，state为on或off，
+// 路由参数maintenance=allow的路由在维护模式下允许访问。
+func (m *Maintenance) NewMaintenanceFunc(router eudore.Router) eudore.HandlerFunc {
+	if router != nil {
+		router.GetFunc("/maintenance maintenance=allow", m.getState)
+		router.PutFunc("/maintenance/:state maintenance=allow", m.putState)
+	}
+	return func(ctx eudore.Context) {
+		if !m.Enabled() || ctx.GetParam("maintenance") == "allow" {
+			return
+		}
+		path := ctx.Path()
+		for _, allow := range m.Allows {
+			if strings.HasPrefix(path, allow) {
+				return
+			}
+		}
+		if m.RetryAfter > 0 {
+			ctx.SetHeader(eudore.HeaderRetryAfter, strconv.Itoa(int(m.RetryAfter/time.Second)))
+		}
+		ctx.WriteHeader(eudore.StatusServiceUnavailable)
+		ctx.Render(m.Response)
+		ctx.End()
+	}
+}
+
+// Enabled 方法返回是否处于维护模式。
+func (m *Maintenance) Enabled() bool {
+	return atomic.LoadInt32(&m.enabled) == 1
+}
+
+// Set 方法设置维护模式状态。
+func (m *Maintenance) Set(enabled bool) {
+	var val int32
+	if enabled {
+		val = 1
+	}
+	atomic.StoreInt32(&m.enabled, val)
+}
+
+// WatchSignal 方法每次收到信号时切换维护模式状态，直到ctx结束，例如syscall.SIGUSR2。
+func (m *Maintenance) WatchSignal(ctx context.Context, log eudore.Logout, sig ...os.Signal) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sig...)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case s := <-ch:
+				m.Set(!m.Enabled())
+				log.Infof("maintenance signal %s set state %v", s, m.Enabled())
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// WatchFunc 方法每个周期调用fn，返回值变化时设置维护模式状态，直到ctx结束，用于监听配置变化。
+func (m *Maintenance) WatchFunc(ctx context.Context, interval time.Duration, fn func() bool) {
+	last := fn()
+	m.Set(last)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				// 仅在返回值变化时修改，不覆盖管理接口和信号的修改。
+				if val := fn(); val != last {
+					last = val
+					m.Set(val)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (m *Maintenance) getState(ctx eudore.Context) {
+	ctx.Render(map[string]interface{}{"maintenance": m.Enabled()})
+}
+
+func (m *Maintenance) putState(ctx eudore.Context) {
+	switch ctx.GetParam("state") {
+	case "on":
+		m.Set(true)
+	case "off":
+		m.Set(false)
+	default:
+		ctx.Fatal("state is invalid")
+		return
+	}
+	ctx.Infof("maintenance admin set state %v", m.Enabled())
+	m.getState(ctx)
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// HeaderXMirror 定义镜像请求添加的Header。
+const HeaderXMirror = "X-Mirror"
+
+// NewMirrorFunc 函数创建一个请求镜像处理函数，按百分比将请求和body异步复制到upstream，忽略镜像响应。
+//
+// 镜像请求在新协程中使用独立的context发送，超过并发上限或body过大时不镜像，不影响原请求延迟。
+//
+// options:
+// *http.Client    =>    发送镜像请求的客户端，默认超时5秒
+// int             =>    最大并发镜像请求数量，默认100
+// int64           =>    最大镜像body长度，默认4MB
+func NewMirrorFunc(upstream string, percent int, options ...interface{}) eudore.HandlerFunc {
+	client := &http.Client{Timeout: 5 * time.Second}
+	concurrency := 100
+	var maxbody int64 = 4 << 20
+	for _, i := range options {
+		switch val := i.(type) {
+		case *http.Client:
+			client = val
+		case int:
+			concurrency = val
+		case int64:
+			maxbody = val
+		}
+	}
+	upstream = strings.TrimSuffix(upstream, "/")
+	sem := make(chan struct{}, concurrency)
+	var count uint64
+	return func(ctx eudore.Context) {
+		// 按百分比均匀选择请求，例如50%时每两个请求镜像一个。
+		n := atomic.AddUint64(&count, 1)
+		if n*uint64(percent)/100 == (n-1)*uint64(percent)/100 {
+			return
+		}
+		r := ctx.Request()
+		if r.ContentLength > maxbody {
+			return
+		}
+		select {
+		case sem <- struct{}{}:
+		default:
+			return
+		}
+
+		var body []byte
+		if r.ContentLength != 0 {
+			body = ctx.Body()
+			if int64(len(body)) > maxbody {
+				<-sem
+				return
+			}
+		}
+		req, err := http.NewRequest(r.Method, upstream+r.RequestURI, bytes.NewReader(body))
+		if err != nil {
+			<-sem
+			return
+		}
+		for k, v := range r.Header {
+			req.Header[k] = append([]string(nil), v...)
+		}
+		for _, h := range mirrorHopHeaders {
+			req.Header.Del(h)
+		}
+		req.Host = r.Host
+		req.Header.Set(HeaderXMirror, "1")
+		req.Header.Set(eudore.HeaderXForwardedFor, ctx.RealIP())
+
+		log := ctx.Logger()
+		go func() {
+			defer func() { <-sem }()
+			resp, err := client.Do(req)
+			if err != nil {
+				log.Debug("mirror request error:", err)
+				return
+			}
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}()
+	}
+}
+
+var mirrorHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// 定义nonce检查使用的Header。
+const (
+	HeaderXNonce          = "X-Nonce"
+	HeaderXNonceTimestamp = "X-Nonce-Timestamp"
+)
+
+// NewNonceFunc 函数创建一个请求nonce检查处理函数，拒绝重放请求，用于高安全接口。
+//
+// 客户端使用X-Nonce Header传递每个请求唯一的nonce，最长128字节，
+// 使用X-Nonce-Timestamp Header传递unix秒时间戳，时间戳超出时间窗口的请求拒绝，nonce保存两倍时间窗口。
+//
+// nonce或时间戳无效返回400，nonce重复使用返回409。
+//
+// options:
+// time.Duration                  =>    时间窗口，默认5分钟
+// NonceStore                     =>    nonce存储，默认使用内存存储
+// eudore.Cache                   =>    使用NewNonceStoreCache创建nonce存储，使用共享缓存时多实例共享nonce
+// func(eudore.Context) string    =>    nonce的作用域，例如返回客户端id，不同作用域可以使用相同nonce
+func NewNonceFunc(options ...interface{}) eudore.HandlerFunc {
+	window := 5 * time.Minute
+	var store NonceStore
+	var scope func(eudore.Context) string
+	for _, i := range options {
+		switch val := i.(type) {
+		case time.Duration:
+			window = val
+		case NonceStore:
+			store = val
+		case eudore.Cache:
+			store = NewNonceStoreCache(val)
+		case func(eudore.Context) string:
+			scope = val
+		}
+	}
+	if store == nil {
+		store = NewNonceStoreMemory()
+	}
+	return func(ctx eudore.Context) {
+		nonce := ctx.GetHeader(HeaderXNonce)
+		if nonce == "" || len(nonce) > 128 {
+			writeFatal(ctx, eudore.StatusBadRequest, "nonce invalid: "+nonce)
+			return
+		}
+		timestamp, err := strconv.ParseInt(ctx.GetHeader(HeaderXNonceTimestamp), 10, 64)
+		if err != nil {
+			writeFatal(ctx, eudore.StatusBadRequest, "nonce timestamp invalid: "+err.Error())
+			return
+		}
+		offset := time.Since(time.Unix(timestamp, 0))
+		if offset > window || offset < -window {
+			writeFatal(ctx, eudore.StatusBadRequest, "nonce timestamp expired")
+			return
+		}
+
+		if scope != nil {
+			nonce = scope(ctx) + " " + nonce
+		}
+		if !store.Add(nonce, window*2) {
+			writeFatal(ctx, eudore.StatusConflict, "nonce replay: "+ctx.GetHeader(HeaderXNonce))
+		}
+	}
+}
+
+// nonceStoreCache 定义基于eudore.Cache的NonceStore。
+type nonceStoreCache struct {
+	cache eudore.Cache
+}
+
+// NewNonceStoreCache 函数使用eudore.Cache创建NonceStore，使用共享缓存时多实例共享nonce。
+func NewNonceStoreCache(cache eudore.Cache) NonceStore {
+	return &nonceStoreCache{cache: cache}
+}
+
+// Add 方法使用Cache.Add保存nonce，nonce已经存在或缓存错误时返回false。
+func (s *nonceStoreCache) Add(nonce string, ttl time.Duration) bool {
+	ok, err := s.cache.Add("nonce:"+nonce, []byte{1}, ttl)
+	return ok && err == nil
+}
@@ -0,0 +1,481 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// OIDC 定义OpenID Connect授权码模式客户端。
+//
+// 用户claims使用access token从UserinfoURL获取，不使用也不校验token响应中的id_token。
+type OIDC struct {
+	Providers []*OIDCProvider
+	Store     OIDCSessionStore
+	Client    *http.Client
+	Cookie    http.Cookie
+	// StateCookie 保存签名的state和PKCE verifier，回调时比较请求state，将授权请求绑定到发起登录的浏览器。
+	StateCookie http.Cookie
+	// Secret 为StateCookie的签名密钥，默认随机生成，多实例需要设置相同密钥。
+	Secret []byte
+	// 会话有效时间，默认8小时。
+	Expires time.Duration
+	// StateExpires 为授权请求有效时间，默认10分钟。
+	StateExpires time.Duration
+	// MaxStates 为一个浏览器同时等待回调的授权请求数量，超过后丢弃最早的授权请求，默认4。
+	MaxStates int
+}
+
+// OIDCProvider 定义一个OIDC身份提供者。
+//
+// RedirectURL需要指向NewOIDCFunc注入路由器的/oidc/callback/:provider路由。
+type OIDCProvider struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	AuthURL      string
+	TokenURL     string
+	UserinfoURL  string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// OIDCSessionStore 定义OIDC会话存储，保存会话id对应的用户claims。
+type OIDCSessionStore interface {
+	Get(string) map[string]interface{}
+	Set(string, map[string]interface{}, time.Duration)
+	Delete(string)
+}
+
+// oidcState 定义一次授权请求的状态，签名后保存在StateCookie中。
+type oidcState struct {
+	State    string `json:"s"`
+	Provider string `json:"p"`
+	Verifier string `json:"v"`
+	Redirect string `json:"r"`
+	Expire   int64  `json:"e"`
+}
+
+type oidcClaimsKey struct{}
+
+// NewOIDCFunc 函数创建一个OIDC认证处理函数，第一个provider为默认身份提供者。
+//
+// router参数是eudore.Router类型，然后注入登录、回调、登出路由。
+func NewOIDCFunc(router eudore.Router, providers ...*OIDCProvider) eudore.HandlerFunc {
+	return NewOIDC(providers...).NewOIDCFunc(router)
+}
+
+// NewOIDC 函数创建一个OIDC客户端，读取随机数生成Secret失败时panic。
+func NewOIDC(providers ...*OIDCProvider) *OIDC {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		panic(fmt.Errorf("oidc generate secret error: %v", err))
+	}
+	return &OIDC{
+		Providers:    providers,
+		Store:        NewOIDCSessionStoreMemory(),
+		Client:       http.DefaultClient,
+		Cookie:       http.Cookie{Name: "_oidc", Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode},
+		StateCookie:  http.Cookie{Name: "_oidc_state", Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode},
+		Secret:       secret,
+		Expires:      8 * time.Hour,
+		StateExpires: 10 * time.Minute,
+		MaxStates:    4,
+	}
+}
+
+// NewOIDCFunc 方法注入OIDC路由并返回认证处理函数。
+//
+// 未登录的GET请求重定向到默认身份提供者，其他请求返回401，登录后使用GetOIDCClaims函数获取用户claims。
+func (o *OIDC) NewOIDCFunc(router eudore.Router) eudore.HandlerFunc {
+	router.GetFunc("/oidc/login/:provider oidc=login", o.login)
+	router.GetFunc("/oidc/callback/:provider oidc=callback", o.callback)
+	router.AnyFunc("/oidc/logout oidc=logout", o.logout)
+	return func(ctx eudore.Context) {
+		if ctx.GetParam("oidc") != "" {
+			return
+		}
+		claims := o.getClaims(ctx)
+		if claims != nil {
+			ctx.WithContext(context.WithValue(ctx.GetContext(), oidcClaimsKey{}, claims))
+			return
+		}
+		if ctx.Method() != eudore.MethodGet || len(o.Providers) == 0 {
+			ctx.WriteHeader(eudore.StatusUnauthorized)
+			ctx.Fatal("oidc session not found")
+			ctx.End()
+			return
+		}
+		o.redirectProvider(ctx, o.Providers[0], getOIDCRedirect(ctx.Request().URL.RequestURI()))
+		ctx.End()
+	}
+}
+
+// getClaims 方法读取会话Cookie对应的claims，只接受callback创建的会话id，
+// 避免Cookie读取Store中其他中间件保存的状态，例如TOTP使用"totp:"前缀的键。
+func (o *OIDC) getClaims(ctx eudore.Context) map[string]interface{} {
+	id := ctx.GetCookie(o.Cookie.Name)
+	if !checkOIDCSessionID(id) {
+		return nil
+	}
+	return o.Store.Get(id)
+}
+
+// GetOIDCClaims 函数获取OIDC登录用户的claims，未登录返回nil。
+func GetOIDCClaims(ctx eudore.Context) map[string]interface{} {
+	claims, _ := ctx.GetContext().Value(oidcClaimsKey{}).(map[string]interface{})
+	return claims
+}
+
+func (o *OIDC) getProvider(name string) *OIDCProvider {
+	for _, p := range o.Providers {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// login 方法处理指定身份提供者的登录请求，使用redirect参数指定登录后的跳转地址。
+func (o *OIDC) login(ctx eudore.Context) {
+	provider := o.getProvider(ctx.GetParam("provider"))
+	if provider == nil {
+		ctx.WriteHeader(eudore.StatusNotFound)
+		ctx.Fatal("oidc provider not found: " + ctx.GetParam("provider"))
+		return
+	}
+	o.redirectProvider(ctx, provider, getOIDCRedirect(ctx.GetQuery("redirect")))
+}
+
+// getOIDCRedirect 函数检查登录后的跳转地址，只允许当前站点的路径，其他地址返回"/"。
+//
+// 浏览器将'\'视为'/'，"/\evil.com"和"//evil.com"都会跳转到其他站点。
+func getOIDCRedirect(redirect string) string {
+	u, err := url.Parse(redirect)
+	if err != nil || u.Scheme != "" || u.Host != "" || !strings.HasPrefix(redirect, "/") ||
+		strings.HasPrefix(redirect, "//") || strings.ContainsRune(redirect, '\\') {
+		return "/"
+	}
+	return redirect
+}
+
+// redirectProvider 方法创建state和PKCE参数保存到StateCookie，并重定向到身份提供者授权地址。
+func (o *OIDC) redirectProvider(ctx eudore.Context, provider *OIDCProvider, redirect string) {
+	now := time.Now()
+	key, err := getOIDCRandom()
+	if err != nil {
+		ctx.WriteHeader(eudore.StatusInternalServerError)
+		ctx.Fatal(err)
+		return
+	}
+	verifier, err := getOIDCRandom()
+	if err != nil {
+		ctx.WriteHeader(eudore.StatusInternalServerError)
+		ctx.Fatal(err)
+		return
+	}
+	state := &oidcState{
+		State:    key,
+		Provider: provider.Name,
+		Verifier: verifier,
+		Redirect: redirect,
+		Expire:   now.Add(o.StateExpires).Unix(),
+	}
+	states := append([]*oidcState{state}, o.getStates(ctx, now)...)
+	if len(states) > o.MaxStates && o.MaxStates > 0 {
+		states = states[:o.MaxStates]
+	}
+	o.setStates(ctx, states)
+
+	challenge := sha256.Sum256([]byte(state.Verifier))
+	scopes := provider.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "profile", "email"}
+	}
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {provider.ClientID},
+		"redirect_uri":          {provider.RedirectURL},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {state.State},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(provider.AuthURL, "?") {
+		sep = "&"
+	}
+	ctx.Redirect(eudore.StatusFound, provider.AuthURL+sep+query.Encode())
+}
+
+// callback 方法处理身份提供者回调，state需要与StateCookie中的state一致，使用code交换token并获取userinfo建立会话。
+func (o *OIDC) callback(ctx eudore.Context) {
+	if errmsg := ctx.GetQuery("error"); errmsg != "" {
+		ctx.WriteHeader(eudore.StatusUnauthorized)
+		ctx.Fatal("oidc provider error: " + errmsg)
+		return
+	}
+	key := ctx.GetQuery("state")
+	var state *oidcState
+	states := o.getStates(ctx, time.Now())
+	for i := range states {
+		if key != "" && hmac.Equal([]byte(states[i].State), []byte(key)) {
+			state = states[i]
+			states = append(states[:i], states[i+1:]...)
+			break
+		}
+	}
+	if state == nil || state.Provider != ctx.GetParam("provider") {
+		ctx.WriteHeader(eudore.StatusBadRequest)
+		ctx.Fatal("oidc state invalid")
+		return
+	}
+	o.setStates(ctx, states)
+
+	provider := o.getProvider(state.Provider)
+	claims, err := o.exchange(ctx.GetContext(), provider, ctx.GetQuery("code"), state.Verifier)
+	if err != nil {
+		ctx.WriteHeader(eudore.StatusUnauthorized)
+		ctx.Fatal(err)
+		return
+	}
+
+	id, err := getOIDCRandom()
+	if err != nil {
+		ctx.WriteHeader(eudore.StatusInternalServerError)
+		ctx.Fatal(err)
+		return
+	}
+	o.Store.Set(id, claims, o.Expires)
+	cookie := o.Cookie
+	cookie.Value = id
+	cookie.MaxAge = int(o.Expires / time.Second)
+	ctx.SetCookie(&cookie)
+	ctx.Redirect(eudore.StatusFound, state.Redirect)
+}
+
+// logout 方法删除当前会话。
+func (o *OIDC) logout(ctx eudore.Context) {
+	if id := ctx.GetCookie(o.Cookie.Name); checkOIDCSessionID(id) {
+		o.Store.Delete(id)
+	}
+	cookie := o.Cookie
+	cookie.MaxAge = -1
+	ctx.SetCookie(&cookie)
+}
+
+// exchange 方法使用授权码获取access token，然后获取userinfo claims。
+func (o *OIDC) exchange(ctx context.Context, provider *OIDCProvider, code, verifier string) (map[string]interface{}, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {provider.RedirectURL},
+		"client_id":     {provider.ClientID},
+		"client_secret": {provider.ClientSecret},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequest(eudore.MethodPost, provider.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(eudore.HeaderContentType, eudore.MimeApplicationForm)
+	req.Header.Set(eudore.HeaderAccept, eudore.MimeApplicationJSON)
+	var token struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+	}
+	err = o.do(req.WithContext(ctx), &token)
+	if err != nil {
+		return nil, fmt.Errorf("oidc token exchange error: %v", err)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("oidc token exchange error: access_token is empty")
+	}
+
+	req, err = http.NewRequest(eudore.MethodGet, provider.UserinfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(eudore.HeaderAuthorization, "Bearer "+token.AccessToken)
+	req.Header.Set(eudore.HeaderAccept, eudore.MimeApplicationJSON)
+	claims := make(map[string]interface{})
+	err = o.do(req.WithContext(ctx), &claims)
+	if err != nil {
+		return nil, fmt.Errorf("oidc userinfo error: %v", err)
+	}
+	return claims, nil
+}
+
+func (o *OIDC) do(req *http.Request, data interface{}) error {
+	resp, err := o.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != eudore.StatusOK {
+		return fmt.Errorf("%s response status %d", req.URL.Path, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(data)
+}
+
+// getStates 方法读取StateCookie中签名有效且未过期的state。
+func (o *OIDC) getStates(ctx eudore.Context, now time.Time) []*oidcState {
+	var states []*oidcState
+	for _, value := range strings.Split(ctx.GetCookie(o.StateCookie.Name), "~") {
+		pos := strings.IndexByte(value, '.')
+		if pos == -1 || !hmac.Equal([]byte(value[pos+1:]), []byte(o.sign(value[:pos]))) {
+			continue
+		}
+		body, err := base64.RawURLEncoding.DecodeString(value[:pos])
+		state := &oidcState{}
+		if err == nil && json.Unmarshal(body, state) == nil && now.Unix() <= state.Expire {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// setStates 方法签名state写入StateCookie，没有state时删除StateCookie。
+func (o *OIDC) setStates(ctx eudore.Context, states []*oidcState) {
+	values := make([]string, 0, len(states))
+	for _, state := range states {
+		body, _ := json.Marshal(state)
+		value := base64.RawURLEncoding.EncodeToString(body)
+		values = append(values, value+"."+o.sign(value))
+	}
+	cookie := o.StateCookie
+	cookie.Value = strings.Join(values, "~")
+	cookie.MaxAge = int(o.StateExpires / time.Second)
+	if len(values) == 0 {
+		cookie.MaxAge = -1
+	}
+	ctx.SetCookie(&cookie)
+}
+
+func (o *OIDC) sign(value string) string {
+	mac := hmac.New(sha256.New, o.Secret)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// checkOIDCSessionID 函数检查会话id是否为getOIDCRandom生成的格式，32字节随机数的无填充base64url编码。
+func checkOIDCSessionID(id string) bool {
+	if len(id) != 43 {
+		return false
+	}
+	for _, c := range id {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// getOIDCRandom 函数使用crypto/rand创建一个随机字符串，读取随机数失败返回错误，避免使用可预测的state和会话id。
+func getOIDCRandom() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("oidc generate random error: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// oidcSessionStoreMemory 定义基于内存的OIDCSessionStore。
+type oidcSessionStoreMemory struct {
+	sync.RWMutex
+	data map[string]*oidcSession
+}
+
+type oidcSession struct {
+	claims map[string]interface{}
+	expire time.Time
+}
+
+// NewOIDCSessionStoreMemory 函数创建一个基于内存的OIDCSessionStore。
+func NewOIDCSessionStoreMemory() OIDCSessionStore {
+	return &oidcSessionStoreMemory{data: make(map[string]*oidcSession)}
+}
+
+// Get 方法获取会话claims，会话不存在或过期返回nil。
+func (s *oidcSessionStoreMemory) Get(id string) map[string]interface{} {
+	if id == "" {
+		return nil
+	}
+	s.RLock()
+	session, ok := s.data[id]
+	s.RUnlock()
+	if !ok || time.Now().After(session.expire) {
+		return nil
+	}
+	return session.claims
+}
+
+// Set 方法保存会话claims，并清理过期会话。
+func (s *oidcSessionStoreMemory) Set(id string, claims map[string]interface{}, ttl time.Duration) {
+	s.Lock()
+	defer s.Unlock()
+	now := time.Now()
+	for k, v := range s.data {
+		if now.After(v.expire) {
+			delete(s.data, k)
+		}
+	}
+	s.data[id] = &oidcSession{claims: claims, expire: now.Add(ttl)}
+}
+
+// Delete 方法删除会话。
+func (s *oidcSessionStoreMemory) Delete(id string) {
+	s.Lock()
+	delete(s.data, id)
+	s.Unlock()
+}
+
+// oidcSessionStoreCache 定义基于eudore.Cache的OIDCSessionStore。
+type oidcSessionStoreCache struct {
+	cache eudore.Cache
+}
+
+// NewOIDCSessionStoreCache 函数使用eudore.Cache创建OIDCSessionStore，使用共享缓存时多实例共享会话。
+func NewOIDCSessionStoreCache(cache eudore.Cache) OIDCSessionStore {
+	return &oidcSessionStoreCache{cache: cache}
+}
+
+// Get 方法获取会话claims，会话不存在或过期返回nil。
+func (s *oidcSessionStoreCache) Get(id string) map[string]interface{} {
+	if id == "" {
+		return nil
+	}
+	body, err := s.cache.Get("oidc:" + id)
+	if err != nil {
+		return nil
+	}
+	var claims map[string]interface{}
+	json.Unmarshal(body, &claims)
+	return claims
+}
+
+// Set 方法保存会话claims。
+func (s *oidcSessionStoreCache) Set(id string, claims map[string]interface{}, ttl time.Duration) {
+	body, err := json.Marshal(claims)
+	if err == nil {
+		s.cache.Set("oidc:"+id, body, ttl)
+	}
+}
+
+// Delete 方法删除会话。
+func (s *oidcSessionStoreCache) Delete(id string) {
+	s.cache.Delete("oidc:" + id)
+}
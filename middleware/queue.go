@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// NewQueueFunc 函数创建一个请求排队处理函数，限制每个路由同时处理的请求数量。
+//
+// 每个路由最多同时处理max个请求，超出的请求最多排队size个，排队超时或队列已满返回503并设置Retry-After Header。
+//
+// options:
+// time.Duration                 =>    请求排队等待的最大时间，默认1秒
+// func(eudore.Context) string   =>    获取限制key的函数，默认使用route参数
+func NewQueueFunc(max, size int, options ...interface{}) eudore.HandlerFunc {
+	return newQueue(max, size, options...).HandleHTTP
+}
+
+func newQueue(max, size int, options ...interface{}) *queue {
+	if max < 1 {
+		max = 1
+	}
+	q := &queue{
+		routes:  make(map[string]*queueRoute),
+		max:     max,
+		size:    int32(size),
+		timeout: time.Second,
+		GetKeyFunc: func(ctx eudore.Context) string {
+			return ctx.GetParam(eudore.ParamRoute)
+		},
+	}
+	for _, i := range options {
+		switch val := i.(type) {
+		case time.Duration:
+			q.timeout = val
+		case func(eudore.Context) string:
+			q.GetKeyFunc = val
+		}
+	}
+	q.retryAfter = fmt.Sprint(int64((q.timeout + time.Second - 1) / time.Second))
+	return q
+}
+
+// queue 定义请求排队器。
+type queue struct {
+	mu         sync.RWMutex
+	routes     map[string]*queueRoute
+	GetKeyFunc func(eudore.Context) string
+	max        int
+	size       int32
+	timeout    time.Duration
+	retryAfter string
+}
+
+// queueRoute 定义一个路由的并发信号量和排队数量。
+type queueRoute struct {
+	sem     chan struct{}
+	waiting int32
+}
+
+// HandleHTTP 方法实现eudore请求上下文处理函数。
+func (q *queue) HandleHTTP(ctx eudore.Context) {
+	key := q.GetKeyFunc(ctx)
+	route := q.getRoute(key)
+	if !route.Acquire(ctx, q.size, q.timeout) {
+		ctx.SetHeader(eudore.HeaderRetryAfter, q.retryAfter)
+		ctx.WriteHeader(eudore.StatusServiceUnavailable)
+		ctx.Fatal("deny request of queue: " + key)
+		ctx.End()
+		return
+	}
+	defer route.Release()
+	ctx.Next()
+}
+
+// getRoute 方法获取key对应的queueRoute，不存在则创建。
+func (q *queue) getRoute(key string) *queueRoute {
+	q.mu.RLock()
+	route, ok := q.routes[key]
+	q.mu.RUnlock()
+	if ok {
+		return route
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	route, ok = q.routes[key]
+	if !ok {
+		route = &queueRoute{sem: make(chan struct{}, q.max)}
+		q.routes[key] = route
+	}
+	return route
+}
+
+// Acquire 方法获取一个处理请求的位置，如果没有空闲位置则排队等待。
+func (r *queueRoute) Acquire(ctx eudore.Context, size int32, timeout time.Duration) bool {
+	select {
+	case r.sem <- struct{}{}:
+		return true
+	default:
+	}
+
+	if atomic.AddInt32(&r.waiting, 1) > size {
+		atomic.AddInt32(&r.waiting, -1)
+		return false
+	}
+	defer atomic.AddInt32(&r.waiting, -1)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r.sem <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.GetContext().Done():
+		return false
+	}
+}
+
+// Release 方法释放一个处理请求的位置。
+func (r *queueRoute) Release() {
+	<-r.sem
+}
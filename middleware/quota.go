@@ -0,0 +1,299 @@
+package middleware
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// 定义配额使用的Header。
+const (
+	HeaderXRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderXRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderXRateLimitReset     = "X-RateLimit-Reset"
+	HeaderXQuotaBytesLimit    = "X-Quota-Bytes-Limit"
+	HeaderXQuotaBytesRemain   = "X-Quota-Bytes-Remaining"
+)
+
+// QuotaUsage 定义一个key在滑动窗口内的用量。
+type QuotaUsage struct {
+	Requests int64     `json:"requests"`
+	Bytes    int64     `json:"bytes"`
+	Reset    time.Time `json:"reset"`
+}
+
+// QuotaStore 定义配额计数存储，使用滑动窗口统计用量，多实例共享存储时配额在全部实例生效。
+type QuotaStore interface {
+	// Add 方法给key增加请求数和字节数，返回增加后窗口内的用量。
+	Add(key string, requests, bytes int64, window time.Duration) (QuotaUsage, error)
+	// Get 方法返回key在窗口内的用量。
+	Get(key string, window time.Duration) (QuotaUsage, error)
+	// List 方法返回全部key在窗口内的用量，用于后台接口。
+	List(window time.Duration) (map[string]QuotaUsage, error)
+	// Delete 方法重置key的用量。
+	Delete(key string) error
+}
+
+// Quota 定义配额统计，按API Key、用户、租户或IP统计滚动窗口内的请求数和响应字节数，超出配额返回429。
+type Quota struct {
+	Store    QuotaStore
+	Window   time.Duration
+	Requests int64
+	Bytes    int64
+	// GetKey 返回统计的key，默认依次使用apikey参数、UID参数、经过Tenant.Check校验的租户和RealIP。
+	GetKey func(eudore.Context) string
+	// GetLimit 返回请求的配额，默认读取校验后的租户配置quota.requests和quota.bytes，不存在时使用Requests和Bytes。
+	GetLimit func(eudore.Context) (int64, int64)
+}
+
+// NewQuotaFunc 函数创建一个配额统计处理函数，requests和bytes为窗口内最大请求数和响应字节数，为0时不限制。
+//
+// options:
+// time.Duration                               =>    滚动窗口时间，默认1小时
+// QuotaStore                                  =>    配额计数存储，默认使用内存存储
+// func(eudore.Context) string                 =>    获取统计key的函数
+// func(eudore.Context) (int64, int64)         =>    获取请求配额的函数
+// eudore.Router                               =>    注入配额后台路由
+func NewQuotaFunc(requests, bytes int64, options ...interface{}) eudore.HandlerFunc {
+	return NewQuota(requests, bytes, options...).HandleHTTP
+}
+
+// NewQuota 函数创建配额统计，参数同NewQuotaFunc函数。
+func NewQuota(requests, bytes int64, options ...interface{}) *Quota {
+	q := &Quota{
+		Window:   time.Hour,
+		Requests: requests,
+		Bytes:    bytes,
+		GetKey:   getQuotaKey,
+	}
+	q.GetLimit = q.getLimit
+	var router eudore.Router
+	for _, i := range options {
+		switch val := i.(type) {
+		case time.Duration:
+			q.Window = val
+		case QuotaStore:
+			q.Store = val
+		case func(eudore.Context) string:
+			q.GetKey = val
+		case func(eudore.Context) (int64, int64):
+			q.GetLimit = val
+		case eudore.Router:
+			router = val
+		}
+	}
+	if q.Store == nil {
+		q.Store = NewQuotaStoreMemory()
+	}
+	if router != nil {
+		q.InjectRoutes(router)
+	}
+	return q
+}
+
+// InjectRoutes 方法将配额后台接口注入到路由器中。
+func (q *Quota) InjectRoutes(router eudore.Router) {
+	router.GetFunc("/quota/data", q.data)
+	router.GetFunc("/quota/key/:key", q.getKey)
+	router.DeleteFunc("/quota/key/:key", q.deleteKey)
+}
+
+// HandleHTTP 方法检查请求配额并设置X-RateLimit Header，请求处理后统计响应字节数。
+//
+// 存储不可用时放行请求，避免配额存储故障导致服务不可用。
+func (q *Quota) HandleHTTP(ctx eudore.Context) {
+	key := q.GetKey(ctx)
+	requests, bytes := q.GetLimit(ctx)
+	usage, err := q.Store.Get(key, q.Window)
+	if err != nil {
+		ctx.Error("quota store error:", err)
+		return
+	}
+	if (requests > 0 && usage.Requests >= requests) || (bytes > 0 && usage.Bytes >= bytes) {
+		q.setHeader(ctx, requests, bytes, usage)
+		ctx.SetHeader(eudore.HeaderRetryAfter, ctx.Response().Header().Get(HeaderXRateLimitReset))
+		ctx.WriteHeader(eudore.StatusTooManyRequests)
+		ctx.Fatal("quota exceeded: " + key)
+		ctx.End()
+		return
+	}
+
+	usage, err = q.Store.Add(key, 1, 0, q.Window)
+	if err != nil {
+		ctx.Error("quota store error:", err)
+		return
+	}
+	q.setHeader(ctx, requests, bytes, usage)
+	ctx.Next()
+	if size := ctx.Response().Size(); size > 0 {
+		_, err = q.Store.Add(key, 0, int64(size), q.Window)
+		if err != nil {
+			ctx.Error("quota store error:", err)
+		}
+	}
+}
+
+func (q *Quota) setHeader(ctx eudore.Context, requests, bytes int64, usage QuotaUsage) {
+	reset := int64(time.Until(usage.Reset)/time.Second) + 1
+	if reset < 1 {
+		reset = 1
+	}
+	if requests > 0 {
+		ctx.SetHeader(HeaderXRateLimitLimit, strconv.FormatInt(requests, 10))
+		ctx.SetHeader(HeaderXRateLimitRemaining, strconv.FormatInt(getQuotaRemaining(requests, usage.Requests), 10))
+	}
+	if bytes > 0 {
+		ctx.SetHeader(HeaderXQuotaBytesLimit, strconv.FormatInt(bytes, 10))
+		ctx.SetHeader(HeaderXQuotaBytesRemain, strconv.FormatInt(getQuotaRemaining(bytes, usage.Bytes), 10))
+	}
+	ctx.SetHeader(HeaderXRateLimitReset, strconv.FormatInt(reset, 10))
+}
+
+// getLimit 方法读取经过Tenant.Check校验的租户配置，避免客户端选择配额更大的租户。
+func (q *Quota) getLimit(ctx eudore.Context) (int64, int64) {
+	if getTenantChecked(ctx) == "" {
+		return q.Requests, q.Bytes
+	}
+	return eudore.GetInt64(GetTenantValue(ctx, "quota.requests"), q.Requests),
+		eudore.GetInt64(GetTenantValue(ctx, "quota.bytes"), q.Bytes)
+}
+
+func (q *Quota) data(ctx eudore.Context) (interface{}, error) {
+	ctx.SetHeader("X-Eudore-Admin", "quota")
+	return q.Store.List(q.Window)
+}
+
+func (q *Quota) getKey(ctx eudore.Context) (interface{}, error) {
+	return q.Store.Get(ctx.GetParam("key"), q.Window)
+}
+
+func (q *Quota) deleteKey(ctx eudore.Context) error {
+	ctx.Infof("%s reset quota key: %s", ctx.RealIP(), ctx.GetParam("key"))
+	return q.Store.Delete(ctx.GetParam("key"))
+}
+
+// getQuotaKey 函数优先使用认证后的apikey参数和UID参数，然后使用经过Check校验的租户，最后使用RealIP。
+func getQuotaKey(ctx eudore.Context) string {
+	if apikey := ctx.GetParam("apikey"); apikey != "" {
+		return "apikey:" + apikey
+	}
+	if uid := ctx.GetParam(eudore.ParamUID); uid != "" {
+		return "uid:" + uid
+	}
+	if tenant := getTenantChecked(ctx); tenant != "" {
+		return "tenant:" + tenant
+	}
+	return "ip:" + ctx.RealIP()
+}
+
+func getQuotaRemaining(limit, used int64) int64 {
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
+
+// quotaStoreMemory 定义基于内存的QuotaStore，使用当前和上一个固定窗口加权估算滑动窗口用量。
+type quotaStoreMemory struct {
+	sync.Mutex
+	counters map[string]*quotaCounter
+	clean    time.Time
+}
+
+type quotaCounter struct {
+	start time.Time
+	cur   [2]int64
+	prev  [2]int64
+}
+
+// NewQuotaStoreMemory 函数创建一个基于内存的QuotaStore。
+func NewQuotaStoreMemory() QuotaStore {
+	return &quotaStoreMemory{
+		counters: make(map[string]*quotaCounter),
+		clean:    time.Now(),
+	}
+}
+
+// Add 方法给key增加用量，每个窗口清理一次两个窗口没有用量的key。
+func (s *quotaStoreMemory) Add(key string, requests, bytes int64, window time.Duration) (QuotaUsage, error) {
+	now := time.Now()
+	s.Lock()
+	defer s.Unlock()
+	if now.Sub(s.clean) > window {
+		s.clean = now
+		for k, v := range s.counters {
+			if now.Sub(v.start) > window*2 {
+				delete(s.counters, k)
+			}
+		}
+	}
+	c, ok := s.counters[key]
+	if !ok {
+		c = &quotaCounter{start: now.Truncate(window)}
+		s.counters[key] = c
+	}
+	c.roll(now, window)
+	c.cur[0] += requests
+	c.cur[1] += bytes
+	return c.usage(now, window), nil
+}
+
+// Get 方法返回key在窗口内的用量。
+func (s *quotaStoreMemory) Get(key string, window time.Duration) (QuotaUsage, error) {
+	now := time.Now()
+	s.Lock()
+	defer s.Unlock()
+	c, ok := s.counters[key]
+	if !ok {
+		return QuotaUsage{Reset: now.Truncate(window).Add(window)}, nil
+	}
+	return c.usage(now, window), nil
+}
+
+// List 方法返回全部key在窗口内的用量。
+func (s *quotaStoreMemory) List(window time.Duration) (map[string]QuotaUsage, error) {
+	now := time.Now()
+	s.Lock()
+	defer s.Unlock()
+	data := make(map[string]QuotaUsage, len(s.counters))
+	for k, v := range s.counters {
+		data[k] = v.usage(now, window)
+	}
+	return data, nil
+}
+
+// Delete 方法重置key的用量。
+func (s *quotaStoreMemory) Delete(key string) error {
+	s.Lock()
+	delete(s.counters, key)
+	s.Unlock()
+	return nil
+}
+
+// roll 方法滚动计数窗口。
+func (c *quotaCounter) roll(now time.Time, window time.Duration) {
+	start := now.Truncate(window)
+	switch {
+	case start.Sub(c.start) >= window*2:
+		c.prev = [2]int64{}
+		c.cur = [2]int64{}
+	case start.Sub(c.start) >= window:
+		c.prev = c.cur
+		c.cur = [2]int64{}
+	}
+	c.start = start
+}
+
+// usage 方法返回滑动窗口用量，上一个窗口用量按剩余时间比例计入。
+func (c *quotaCounter) usage(now time.Time, window time.Duration) QuotaUsage {
+	c.roll(now, window)
+	start := c.start
+	weight := float64(window-now.Sub(start)) / float64(window)
+	return QuotaUsage{
+		Requests: c.cur[0] + int64(float64(c.prev[0])*weight),
+		Bytes:    c.cur[1] + int64(float64(c.prev[1])*weight),
+		Reset:    start.Add(window),
+	}
+}
@@ -16,6 +16,7 @@ import (
 // context.Context               =>    控制cleanupVisitors退出的生命周期
 // time.Duration                 =>    基础时间周期单位，默认秒
 // func(eudore.Context) string   =>    限流获取key的函数，默认Context.ReadIP
+// RateStore                     =>    限流计数存储，多实例共享存储时限流在全部实例生效，使用存储时不支持等待令牌
 func NewRateFunc(speed, max int64, options ...interface{}) eudore.HandlerFunc {
 	return newRate(speed, max, options...).HandleHTTP
 }
@@ -39,12 +40,60 @@ func newRate(speed, max int64, options ...interface{}) *rate {
 			r.max = int64(val) / speed * max
 		case func(eudore.Context) string:
 			r.GetKeyFunc = val
+		case RateStore:
+			r.store = val
 		}
 	}
-	go r.cleanupVisitors(ctx)
+	if r.store == nil {
+		go r.cleanupVisitors(ctx)
+	}
 	return r
 }
 
+// RateStore 定义限流计数存储，例如component/redis实现的分布式存储。
+type RateStore interface {
+	// Allow 方法判断key是否允许通过，speed为产生一个令牌的纳秒数，max为令牌桶容量的纳秒数。
+	Allow(key string, speed, max int64) (bool, error)
+}
+
+// rateStoreMemory 定义基于内存的RateStore。
+type rateStoreMemory struct {
+	sync.Mutex
+	buckets map[string]*rateBucket
+	clean   int64
+}
+
+// NewRateStoreMemory 函数创建一个基于内存的RateStore，可以在同一进程的多个App之间共享。
+func NewRateStoreMemory() RateStore {
+	return &rateStoreMemory{
+		buckets: make(map[string]*rateBucket),
+		clean:   time.Now().UnixNano(),
+	}
+}
+
+// Allow 方法判断key是否允许通过，每分钟清理一次令牌已满的key。
+func (s *rateStoreMemory) Allow(key string, speed, max int64) (bool, error) {
+	s.Lock()
+	now := time.Now().UnixNano()
+	if now-s.clean > int64(time.Minute) {
+		s.clean = now
+		for k, v := range s.buckets {
+			v.Lock()
+			if v.last < now-v.max {
+				delete(s.buckets, k)
+			}
+			v.Unlock()
+		}
+	}
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = newBucket(speed, max)
+		s.buckets[key] = bucket
+	}
+	s.Unlock()
+	return bucket.Allow(), nil
+}
+
 // rate 定义限流器
 type rate struct {
 	mu         sync.RWMutex
@@ -52,11 +101,26 @@ type rate struct {
 	GetKeyFunc func(eudore.Context) string
 	speed      int64
 	max        int64
+	store      RateStore
 }
 
 // HandleHTTP 方法实现eudore请求上下文处理函数。
 func (r *rate) HandleHTTP(ctx eudore.Context) {
 	key := r.GetKeyFunc(ctx)
+	if r.store != nil {
+		ok, err := r.store.Allow(key, r.speed, r.max)
+		if err != nil {
+			// 存储不可用时放行请求，避免限流存储故障导致服务不可用。
+			ctx.Error("rate store error:", err)
+			return
+		}
+		if !ok {
+			ctx.WriteHeader(eudore.StatusTooManyRequests)
+			ctx.Fatal("deny request of rate: " + key)
+			ctx.End()
+		}
+		return
+	}
 	if !r.GetVisitor(key).WaitWithDeadline(ctx.GetContext()) {
 		ctx.WriteHeader(eudore.StatusTooManyRequests)
 		ctx.Fatal("deny request of rate: " + key)
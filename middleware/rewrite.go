@@ -2,6 +2,9 @@ package middleware
 
 import (
 	"bytes"
+	"net/http"
+	"net/url"
+	"regexp"
 	"strings"
 
 	"github.com/eudore/eudore"
@@ -189,3 +192,94 @@ func getSubsetPrefix(str1, str2 string) (string, bool) {
 
 	return str1, findSubset
 }
+
+// RewriteRule 定义一条请求重写规则，Path为空时匹配全部请求。
+type RewriteRule struct {
+	// Path 定义匹配路径的正则表达式，Target使用$1、${name}引用捕获组，Target为空时不修改路径。
+	Path   string `alias:"path" json:"path"`
+	Target string `alias:"target" json:"target"`
+	// Header 定义添加的请求Header，DelHeader定义删除的请求Header，RenameHeader定义旧名称对应新名称。
+	Header       map[string]string `alias:"header" json:"header"`
+	DelHeader    []string          `alias:"delheader" json:"delheader"`
+	RenameHeader map[string]string `alias:"renameheader" json:"renameheader"`
+	// Query 定义设置的请求参数，DelQuery定义删除的请求参数，RenameQuery定义旧名称对应新名称。
+	Query       map[string]string `alias:"query" json:"query"`
+	DelQuery    []string          `alias:"delquery" json:"delquery"`
+	RenameQuery map[string]string `alias:"renamequery" json:"renamequery"`
+	// ResponseHeader 定义添加的响应Header。
+	ResponseHeader map[string]string `alias:"responseheader" json:"responseheader"`
+	// Last 定义匹配后不再执行后续规则。
+	Last   bool `alias:"last" json:"last"`
+	regexp *regexp.Regexp
+}
+
+// NewRewriteRulesFunc 函数创建一个按规则重写请求的处理函数，需要注册全局中间件在路由匹配前执行。
+//
+// 规则按顺序执行，前一条规则重写后的路径用于后续规则匹配，可以修改路径、请求Header、请求参数和响应Header，
+// 用于合并旧版本url，正则表达式无效时panic。
+//
+// {Path: "^/api/v1/users/(\\d+)$", Target: "/api/v3/user/$1", RenameQuery: map[string]string{"uid": "id"}}
+func NewRewriteRulesFunc(rules []*RewriteRule) eudore.HandlerFunc {
+	for _, rule := range rules {
+		if rule.Path != "" {
+			rule.regexp = regexp.MustCompile(rule.Path)
+		}
+	}
+	return func(ctx eudore.Context) {
+		r := ctx.Request()
+		for _, rule := range rules {
+			if rule.rewrite(ctx, r.URL) && rule.Last {
+				return
+			}
+		}
+	}
+}
+
+func (rule *RewriteRule) rewrite(ctx eudore.Context, u *url.URL) bool {
+	if rule.regexp != nil {
+		match := rule.regexp.FindStringSubmatchIndex(u.Path)
+		if match == nil {
+			return false
+		}
+		if rule.Target != "" {
+			u.Path = string(rule.regexp.ExpandString(nil, rule.Target, u.Path, match))
+			u.RawPath = ""
+		}
+	}
+
+	h := ctx.Request().Header
+	for k, v := range rule.RenameHeader {
+		if vals, ok := h[http.CanonicalHeaderKey(k)]; ok {
+			h.Del(k)
+			h[http.CanonicalHeaderKey(v)] = vals
+		}
+	}
+	for _, k := range rule.DelHeader {
+		h.Del(k)
+	}
+	for k, v := range rule.Header {
+		h.Set(k, v)
+	}
+
+	if rule.RenameQuery != nil || rule.DelQuery != nil || rule.Query != nil {
+		query := u.Query()
+		for k, v := range rule.RenameQuery {
+			if vals, ok := query[k]; ok {
+				delete(query, k)
+				query[v] = vals
+			}
+		}
+		for _, k := range rule.DelQuery {
+			query.Del(k)
+		}
+		for k, v := range rule.Query {
+			query.Set(k, v)
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	for k, v := range rule.ResponseHeader {
+		ctx.SetHeader(k, v)
+	}
+	return true
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// 定义签名使用的Header
+const (
+	HeaderXSignature          = "X-Signature"
+	HeaderXSignatureClient    = "X-Signature-Client"
+	HeaderXSignatureTimestamp = "X-Signature-Timestamp"
+	HeaderXSignatureNonce     = "X-Signature-Nonce"
+)
+
+// NonceStore 定义nonce存储，用于防止请求重放。
+type NonceStore interface {
+	// Add 方法保存nonce，如果nonce已经存在返回false。
+	Add(string, time.Duration) bool
+}
+
+// NewSignatureFunc 函数创建一个HMAC请求签名验证处理函数。
+//
+// 客户端使用X-Signature-Client、X-Signature-Timestamp、X-Signature-Nonce Header传递签名参数，
+// 使用X-Signature Header传递hex编码的签名，fn参数根据client获取密钥，密钥为空表示client无效。
+//
+// options:
+// time.Duration                   =>    允许的时间偏差，默认5分钟，nonce保存时间为两倍时间偏差
+// func(eudore.Context) []byte     =>    签名原文的规范化函数，默认为方法、uri、时间戳、nonce、body使用换行连接
+// func() hash.Hash                =>    HMAC使用的hash函数，默认sha256
+// NonceStore                      =>    nonce存储，默认使用内存存储
+func NewSignatureFunc(fn func(string) string, options ...interface{}) eudore.HandlerFunc {
+	skew := 5 * time.Minute
+	canonical := getSignatureCanonical
+	newhash := sha256.New
+	var store NonceStore
+	for _, i := range options {
+		switch val := i.(type) {
+		case time.Duration:
+			skew = val
+		case func(eudore.Context) []byte:
+			canonical = val
+		case func() hash.Hash:
+			newhash = val
+		case NonceStore:
+			store = val
+		}
+	}
+	if store == nil {
+		store = NewNonceStoreMemory()
+	}
+	return func(ctx eudore.Context) {
+		client := ctx.GetHeader(HeaderXSignatureClient)
+		secret := fn(client)
+		if client == "" || secret == "" {
+			writeFatal(ctx, eudore.StatusUnauthorized, "signature client invalid: "+client)
+			return
+		}
+
+		timestamp, err := strconv.ParseInt(ctx.GetHeader(HeaderXSignatureTimestamp), 10, 64)
+		if err != nil {
+			writeFatal(ctx, eudore.StatusUnauthorized, "signature timestamp invalid: "+err.Error())
+			return
+		}
+		offset := time.Since(time.Unix(timestamp, 0))
+		if offset > skew || offset < -skew {
+			writeFatal(ctx, eudore.StatusUnauthorized, "signature timestamp expired")
+			return
+		}
+
+		sign, err := hex.DecodeString(ctx.GetHeader(HeaderXSignature))
+		if err != nil {
+			writeFatal(ctx, eudore.StatusUnauthorized, "signature invalid: "+err.Error())
+			return
+		}
+		h := hmac.New(newhash, []byte(secret))
+		h.Write(canonical(ctx))
+		if !hmac.Equal(sign, h.Sum(nil)) {
+			writeFatal(ctx, eudore.StatusUnauthorized, "signature invalid")
+			return
+		}
+
+		// 签名有效后再保存nonce，避免无效请求占用nonce。
+		nonce := ctx.GetHeader(HeaderXSignatureNonce)
+		if nonce == "" || !store.Add(client+" "+nonce, skew*2) {
+			writeFatal(ctx, eudore.StatusUnauthorized, "signature nonce replay: "+nonce)
+			return
+		}
+	}
+}
+
+// writeFatal 函数写入拒绝请求的状态码并使用Fatal记录错误，Fatal会结束请求处理。
+func writeFatal(ctx eudore.Context, code int, err interface{}) {
+	ctx.WriteHeader(code)
+	ctx.Fatal(err)
+}
+
+// getSignatureCanonical 函数返回默认的签名原文。
+func getSignatureCanonical(ctx eudore.Context) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(ctx.Method())
+	buf.WriteByte('\n')
+	buf.WriteString(ctx.Request().URL.RequestURI())
+	buf.WriteByte('\n')
+	buf.WriteString(ctx.GetHeader(HeaderXSignatureTimestamp))
+	buf.WriteByte('\n')
+	buf.WriteString(ctx.GetHeader(HeaderXSignatureNonce))
+	buf.WriteByte('\n')
+	buf.Write(ctx.Body())
+	return buf.Bytes()
+}
+
+// nonceStoreMemory 定义基于内存的NonceStore。
+type nonceStoreMemory struct {
+	sync.Mutex
+	data  map[string]time.Time
+	clean time.Time
+}
+
+// NewNonceStoreMemory 函数创建一个基于内存的NonceStore。
+func NewNonceStoreMemory() NonceStore {
+	return &nonceStoreMemory{
+		data:  make(map[string]time.Time),
+		clean: time.Now(),
+	}
+}
+
+// Add 方法保存nonce，如果nonce存在且未过期返回false。
+func (s *nonceStoreMemory) Add(nonce string, ttl time.Duration) bool {
+	s.Lock()
+	defer s.Unlock()
+	now := time.Now()
+	if now.Sub(s.clean) > ttl {
+		s.clean = now
+		for key, expire := range s.data {
+			if now.After(expire) {
+				delete(s.data, key)
+			}
+		}
+	}
+	expire, ok := s.data[nonce]
+	if ok && now.Before(expire) {
+		return false
+	}
+	s.data[nonce] = now.Add(ttl)
+	return true
+}
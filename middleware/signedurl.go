@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// 定义签名URL使用的query参数。
+const (
+	SignedURLExpires   = "expires"
+	SignedURLSignature = "signature"
+)
+
+// 定义签名URL错误。
+var (
+	ErrSignedURLInvalid = errors.New("signed url is invalid")
+	ErrSignedURLExpired = errors.New("signed url is expired")
+	ErrSignedURLSecret  = errors.New("signed url secret is empty")
+)
+
+// SignedURL 定义有时效的签名URL生成和校验，用于下载和上传接口临时授权。
+//
+// 签名原文为方法、path、过期时间、排序后的其他query参数使用换行连接，签名使用HMAC并base64url编码。
+type SignedURL struct {
+	// Secrets 返回当前密钥，第一个密钥用于签名，全部密钥用于校验，每次调用读取配置实现密钥轮换。
+	Secrets func() []string
+	Hash    func() hash.Hash
+}
+
+// NewSignedURLFunc 函数创建一个签名URL校验处理函数，签名无效或过期返回403。
+//
+// secrets参数每次请求时调用，返回当前密钥，例如读取配置:
+//
+//	func() []string { return eudore.GetStrings(app.Get("signedurl.secrets")) }
+func NewSignedURLFunc(secrets func() []string) eudore.HandlerFunc {
+	return NewSignedURL(secrets).NewSignedURLFunc()
+}
+
+// NewSignedURL 函数创建签名URL，HMAC默认使用sha256。
+func NewSignedURL(secrets func() []string) *SignedURL {
+	return &SignedURL{Secrets: secrets, Hash: sha256.New}
+}
+
+// Sign 方法生成在ttl后过期的签名URL，uri可以包含query参数，HEAD请求和GET请求使用相同签名。
+func (s *SignedURL) Sign(method, uri string, ttl time.Duration) (string, error) {
+	secrets := s.Secrets()
+	if len(secrets) == 0 || secrets[0] == "" {
+		return "", ErrSignedURLSecret
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if method == eudore.MethodHead {
+		method = eudore.MethodGet
+	}
+	query := u.Query()
+	query.Del(SignedURLSignature)
+	query.Set(SignedURLExpires, strconv.FormatInt(time.Now().Add(ttl).Unix(), 10))
+	query.Set(SignedURLSignature, base64.RawURLEncoding.EncodeToString(s.sign(secrets[0], method, u.EscapedPath(), query)))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// Verify 方法校验请求方法和uri的签名，依次使用全部密钥校验。
+func (s *SignedURL) Verify(method, uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ErrSignedURLInvalid
+	}
+	query := u.Query()
+	sign, err := base64.RawURLEncoding.DecodeString(query.Get(SignedURLSignature))
+	expires, err2 := strconv.ParseInt(query.Get(SignedURLExpires), 10, 64)
+	if err != nil || err2 != nil || len(sign) == 0 {
+		return ErrSignedURLInvalid
+	}
+	query.Del(SignedURLSignature)
+
+	if method == eudore.MethodHead {
+		method = eudore.MethodGet
+	}
+	for _, secret := range s.Secrets() {
+		if secret != "" && hmac.Equal(sign, s.sign(secret, method, u.EscapedPath(), query)) {
+			if time.Now().Unix() > expires {
+				return ErrSignedURLExpired
+			}
+			return nil
+		}
+	}
+	return ErrSignedURLInvalid
+}
+
+// NewSignedURLFunc 方法返回签名URL校验处理函数，签名无效或过期返回403。
+func (s *SignedURL) NewSignedURLFunc() eudore.HandlerFunc {
+	return func(ctx eudore.Context) {
+		err := s.Verify(ctx.Method(), ctx.Request().URL.RequestURI())
+		if err != nil {
+			writeFatal(ctx, eudore.StatusForbidden, err)
+		}
+	}
+}
+
+// sign 方法计算签名，query使用url.Values.Encode按key排序。
+func (s *SignedURL) sign(secret, method, path string, query url.Values) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(method)
+	buf.WriteByte('\n')
+	buf.WriteString(path)
+	buf.WriteByte('\n')
+	buf.WriteString(query.Get(SignedURLExpires))
+	buf.WriteByte('\n')
+	buf.WriteString(query.Encode())
+	h := hmac.New(s.Hash, []byte(secret))
+	h.Write(buf.Bytes())
+	return h.Sum(nil)
+}
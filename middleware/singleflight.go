@@ -13,22 +13,30 @@ import (
 
 // 定义SingleFlight响应错误
 var (
-	ErrsingleFlightResponseHijack = errors.New("SingleFlight not support eudore.ReadWriter.Hijack method")
-	ErrsingleFlightResponsePush   = errors.New("SingleFlight not support eudore.ReadWriter.Push method")
+	ErrsingleFlightResponseHijack        = errors.New("SingleFlight not support eudore.ReadWriter.Hijack method")
+	ErrsingleFlightResponsePush          = errors.New("SingleFlight not support eudore.ReadWriter.Push method")
+	ErrsingleFlightResponseInformational = errors.New("SingleFlight not support eudore.ReadWriter.WriteInformational method")
 )
 
 // NewSingleFlightFunc 函数创建一个SingleFlight处理函数。
-func NewSingleFlightFunc() eudore.HandlerFunc {
+//
+// 仅合并GET和HEAD请求，默认使用请求方法和uri作为合并的key，可以传入一个函数指定获取key的方法。
+func NewSingleFlightFunc(fn ...func(eudore.Context) string) eudore.HandlerFunc {
+	getkey := getSingleFlightKey
+	if len(fn) > 0 && fn[0] != nil {
+		getkey = fn[0]
+	}
 	mu := sync.Mutex{}
 	calls := make(map[string]*singleFlightResponse)
 	return func(ctx eudore.Context) {
 		// 非幂等方法不允许启用SingleFlight
 		switch ctx.Method() {
-		case eudore.MethodPost, eudore.MethodPatch:
+		case eudore.MethodGet, eudore.MethodHead:
+		default:
 			return
 		}
 
-		key := ctx.Path()
+		key := getkey(ctx)
 		mu.Lock()
 		if call, ok := calls[key]; ok {
 			mu.Unlock()
@@ -46,19 +54,33 @@ func NewSingleFlightFunc() eudore.HandlerFunc {
 		calls[key] = call
 		mu.Unlock()
 
+		// 处理函数panic时也要释放等待的请求，防止等待请求永久阻塞。
+		var finish bool
 		w := ctx.Response()
+		defer func() {
+			if !finish {
+				call.code = 500
+				ctx.SetResponse(w)
+			}
+			mu.Lock()
+			delete(calls, key)
+			mu.Unlock()
+			call.Done()
+		}()
+
 		ctx.SetResponse(call)
 		ctx.Next()
 		ctx.SetResponse(w)
-		call.Done()
+		finish = true
 		call.WriteData(w)
-
-		mu.Lock()
-		delete(calls, key)
-		mu.Unlock()
 	}
 }
 
+// getSingleFlightKey 函数返回请求方法和uri组合成的默认key。
+func getSingleFlightKey(ctx eudore.Context) string {
+	return ctx.Method() + " " + ctx.Request().URL.RequestURI()
+}
+
 // singleFlightResponse 定义SingleFlight请求写入的响应。
 type singleFlightResponse struct {
 	sync.WaitGroup
@@ -69,6 +91,10 @@ type singleFlightResponse struct {
 
 // WriteData 方法将SingleFlight响应数据写入到请求响应。
 func (w *singleFlightResponse) WriteData(resp eudore.ResponseWriter) {
+	h := resp.Header()
+	for k, v := range w.header {
+		h[k] = append([]string(nil), v...)
+	}
 	resp.WriteHeader(w.code)
 	resp.Write(w.buffer.Bytes())
 }
@@ -103,6 +129,11 @@ func (w *singleFlightResponse) Push(string, *http.PushOptions) error {
 	return ErrsingleFlightResponsePush
 }
 
+// WriteInformational 方法写入1xx响应，SingleFlight不支持1xx响应。
+func (w *singleFlightResponse) WriteInformational(int, http.Header) error {
+	return ErrsingleFlightResponseInformational
+}
+
 // Size 方法返回写入数据长度。
 func (w *singleFlightResponse) Size() int {
 	return w.buffer.Len()
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// NewSlowFunc 函数创建一个慢请求检测处理函数，请求处理时间超过threshold时输出Warning日志，包含路由、耗时和状态码。
+//
+// options:
+// bool    =>    请求处理到达阈值时采样处理协程的栈，用于定位延迟来源，默认false
+func NewSlowFunc(threshold time.Duration, options ...interface{}) eudore.HandlerFunc {
+	var stack bool
+	for _, i := range options {
+		switch val := i.(type) {
+		case bool:
+			stack = val
+		}
+	}
+	return func(ctx eudore.Context) {
+		start := time.Now()
+		var sample []string
+		var timer *time.Timer
+		var done chan struct{}
+		if stack {
+			id := getGoroutineID()
+			done = make(chan struct{})
+			timer = time.AfterFunc(threshold, func() {
+				sample = getGoroutineStack(id)
+				close(done)
+			})
+		}
+
+		ctx.Next()
+		if timer != nil && !timer.Stop() {
+			<-done
+		}
+		duration := time.Since(start)
+		if duration < threshold {
+			return
+		}
+		log := ctx.WithField("route", ctx.GetParam(eudore.ParamRoute)).
+			WithField("method", ctx.Method()).
+			WithField("path", ctx.Path()).
+			WithField("status", ctx.Response().Status()).
+			WithField("duration", duration.String())
+		if sample != nil {
+			log = log.WithField("stack", sample)
+		}
+		log.Warning("slow request")
+	}
+}
+
+// getGoroutineID 函数从栈信息首行解析当前协程id。
+func getGoroutineID() string {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	pos := bytes.IndexByte(buf, ' ')
+	if pos == -1 {
+		return ""
+	}
+	return string(buf[:pos])
+}
+
+// getGoroutineStack 函数获取指定id协程的栈信息，协程已经结束返回nil。
+func getGoroutineStack(id string) []string {
+	if _, err := strconv.Atoi(id); err != nil {
+		return nil
+	}
+	buf := make([]byte, 1<<20)
+	stack := string(buf[:runtime.Stack(buf, true)])
+	prefix := "goroutine " + id + " ["
+	for _, block := range strings.Split(stack, "\n\n") {
+		if strings.HasPrefix(block, prefix) {
+			// 合并函数和文件行，格式与eudore.GetPanicStack相同。
+			lines := strings.Split(strings.TrimSpace(block), "\n")[1:]
+			frames := make([]string, 0, len(lines)/2)
+			for i := 0; i+1 < len(lines); i += 2 {
+				fn := lines[i]
+				if pos := strings.LastIndexByte(fn, '('); pos > 0 {
+					fn = fn[:pos]
+				}
+				if pos := strings.LastIndexByte(fn, '/'); pos >= 0 {
+					fn = fn[pos+1:]
+				}
+				file := strings.TrimSpace(lines[i+1])
+				if pos := strings.LastIndex(file, " +0x"); pos > 0 {
+					file = file[:pos]
+				}
+				frames = append(frames, file+" "+fn)
+			}
+			return frames
+		}
+	}
+	return nil
+}
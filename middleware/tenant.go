@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/eudore/eudore"
+)
+
+// HeaderXTenantID 定义传递租户的Header。
+const HeaderXTenantID = "X-Tenant-Id"
+
+// Tenant 定义多租户解析，依次使用Resolvers解析请求的租户，租户保存在请求Context中。
+//
+// 租户配置从Config中读取tenant.<name>.<key>，不存在时读取<key>作为默认值。
+type Tenant struct {
+	Resolvers []func(eudore.Context) string
+	// Default 为无法解析租户时使用的租户，为空时返回400。
+	Default string
+	// Check 检查租户是否存在，不存在返回404，默认为空不检查。
+	Check  func(string) bool
+	Config eudore.Config
+	// MaxRates 为NewRateFunc保存的租户限流数量，超过后淘汰最久未使用租户的限流，默认1024。
+	MaxRates int
+}
+
+// tenantRates 定义按租户保存的限流，使用LRU淘汰，淘汰时取消限流的清理协程。
+type tenantRates struct {
+	sync.Mutex
+	list  *list.List
+	items map[string]*list.Element
+}
+
+type tenantRate struct {
+	name    string
+	handler eudore.HandlerFunc
+	cancel  context.CancelFunc
+}
+
+type tenantKey struct{}
+
+// tenantContext 定义保存在请求Context中的租户，checked表示租户经过Check校验或为默认租户。
+type tenantContext struct {
+	name    string
+	tenant  *Tenant
+	checked bool
+}
+
+// NewTenantFunc 函数创建一个多租户解析处理函数，使用GetTenant函数获取请求的租户。
+//
+// options:
+// func(eudore.Context) string    =>    租户解析函数，可以传入多个依次解析，默认使用NewTenantHeader(HeaderXTenantID)
+// string                         =>    默认租户
+// func(string) bool              =>    检查租户是否存在
+// eudore.Config                  =>    租户配置，使用GetTenantValue函数获取
+func NewTenantFunc(options ...interface{}) eudore.HandlerFunc {
+	return NewTenant(options...).HandleHTTP
+}
+
+// NewTenant 函数创建多租户解析，options同NewTenantFunc函数。
+func NewTenant(options ...interface{}) *Tenant {
+	t := &Tenant{MaxRates: 1024}
+	for _, i := range options {
+		switch val := i.(type) {
+		case func(eudore.Context) string:
+			t.Resolvers = append(t.Resolvers, val)
+		case string:
+			t.Default = val
+		case func(string) bool:
+			t.Check = val
+		case eudore.Config:
+			t.Config = val
+		}
+	}
+	if t.Resolvers == nil {
+		t.Resolvers = []func(eudore.Context) string{NewTenantHeader(HeaderXTenantID)}
+	}
+	return t
+}
+
+// NewTenantSubdomain 函数创建从子域名解析租户的函数，例如base为example.com时acme.example.com的租户为acme。
+func NewTenantSubdomain(base string) func(eudore.Context) string {
+	suffix := "." + strings.TrimPrefix(base, ".")
+	return func(ctx eudore.Context) string {
+		host := ctx.Host()
+		if pos := strings.LastIndexByte(host, ':'); pos != -1 && !strings.HasSuffix(host, "]") {
+			host = host[:pos]
+		}
+		if strings.HasSuffix(host, suffix) {
+			name := host[:len(host)-len(suffix)]
+			if !strings.Contains(name, ".") {
+				return name
+			}
+		}
+		return ""
+	}
+}
+
+// NewTenantHeader 函数创建从Header解析租户的函数。
+func NewTenantHeader(name string) func(eudore.Context) string {
+	return func(ctx eudore.Context) string {
+		return ctx.GetHeader(name)
+	}
+}
+
+// NewTenantParam 函数创建从路由参数解析租户的函数，用于/t/:tenant/*形式的路径。
+func NewTenantParam(name string) func(eudore.Context) string {
+	return func(ctx eudore.Context) string {
+		return ctx.GetParam(name)
+	}
+}
+
+// HandleHTTP 方法解析请求的租户，设置tenant参数和日志tenant属性，用于按租户输出日志和统计。
+func (t *Tenant) HandleHTTP(ctx eudore.Context) {
+	var name string
+	for _, fn := range t.Resolvers {
+		name = fn(ctx)
+		if name != "" {
+			break
+		}
+	}
+	checked := t.Check != nil
+	if name == "" {
+		name, checked = t.Default, true
+	}
+	if name == "" {
+		ctx.WriteHeader(eudore.StatusBadRequest)
+		ctx.Fatal("tenant not found")
+		ctx.End()
+		return
+	}
+	if t.Check != nil && !t.Check(name) {
+		ctx.WriteHeader(eudore.StatusNotFound)
+		ctx.Fatal("tenant not found: " + name)
+		ctx.End()
+		return
+	}
+
+	ctx.WithContext(context.WithValue(ctx.GetContext(), tenantKey{}, &tenantContext{name, t, checked}))
+	ctx.SetParam("tenant", name)
+	ctx.SetLogger(ctx.Logger().WithField("tenant", name))
+}
+
+// Get 方法获取租户配置，读取tenant.<name>.<key>，不存在时读取<key>。
+func (t *Tenant) Get(name, key string) interface{} {
+	if t.Config == nil {
+		return nil
+	}
+	val := t.Config.Get("tenant." + name + "." + key)
+	if val == nil {
+		val = t.Config.Get(key)
+	}
+	return val
+}
+
+// NewRateFunc 方法创建按租户限流的处理函数，同一租户共享令牌桶。
+//
+// 租户的speed和max从租户配置rate.speed和rate.max读取，不存在时使用参数值，options同NewRateFunc函数。
+// 每个租户的限流在首次请求时创建，之后修改配置不生效；
+// 没有设置Check时租户由客户端决定，最多保存MaxRates个租户的限流，淘汰的限流停止清理协程。
+func (t *Tenant) NewRateFunc(speed, max int64, options ...interface{}) eudore.HandlerFunc {
+	parent := context.Background()
+	for _, i := range options {
+		if ctx, ok := i.(context.Context); ok {
+			parent = ctx
+		}
+	}
+	rates := &tenantRates{list: list.New(), items: make(map[string]*list.Element)}
+	return func(ctx eudore.Context) {
+		name := GetTenant(ctx)
+		rates.Lock()
+		elem, ok := rates.items[name]
+		if ok {
+			rates.list.MoveToFront(elem)
+		} else {
+			rctx, cancel := context.WithCancel(parent)
+			opts := append(options[:len(options):len(options)], GetTenant, rctx)
+			elem = rates.list.PushFront(&tenantRate{
+				name: name,
+				handler: NewRateFunc(eudore.GetInt64(t.Get(name, "rate.speed"), speed),
+					eudore.GetInt64(t.Get(name, "rate.max"), max), opts...),
+				cancel: cancel,
+			})
+			rates.items[name] = elem
+			for t.MaxRates > 0 && rates.list.Len() > t.MaxRates {
+				rate := rates.list.Remove(rates.list.Back()).(*tenantRate)
+				delete(rates.items, rate.name)
+				rate.cancel()
+			}
+		}
+		fn := elem.Value.(*tenantRate).handler
+		rates.Unlock()
+		fn(ctx)
+	}
+}
+
+// GetTenant 函数获取请求的租户，没有使用NewTenantFunc时返回空字符串。
+func GetTenant(ctx eudore.Context) string {
+	tc, ok := ctx.GetContext().Value(tenantKey{}).(*tenantContext)
+	if ok {
+		return tc.name
+	}
+	return ""
+}
+
+// getTenantChecked 函数获取经过Check校验的租户，没有校验的租户由客户端决定，返回空字符串。
+func getTenantChecked(ctx eudore.Context) string {
+	tc, ok := ctx.GetContext().Value(tenantKey{}).(*tenantContext)
+	if ok && tc.checked {
+		return tc.name
+	}
+	return ""
+}
+
+// GetTenantValue 函数获取请求租户的配置，读取tenant.<name>.<key>，不存在时读取<key>。
+func GetTenantValue(ctx eudore.Context, key string) interface{} {
+	tc, ok := ctx.GetContext().Value(tenantKey{}).(*tenantContext)
+	if ok {
+		return tc.tenant.Get(tc.name, key)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// NewTimeoutFunc 函数创建一个请求超时处理函数，超时时间按路由参数timeout、组路由参数timeout、全局timeout的顺序生效，
+// 例如app.Group("/api timeout=3s")和app.GetFunc("/export timeout=30s", handler)，timeout=0表示不限制。
+//
+// 超时后取消请求context，如果未写入响应返回503，并丢弃处理函数后续的写入，处理函数需要检查ctx.GetContext().Done()及时返回。
+func NewTimeoutFunc(timeout time.Duration) eudore.HandlerFunc {
+	return func(ctx eudore.Context) {
+		t := timeout
+		if val := ctx.GetParam("timeout"); val != "" {
+			t = eudore.GetStringDuration(val)
+		}
+		if t <= 0 {
+			return
+		}
+
+		c, cancel := context.WithTimeout(ctx.GetContext(), t)
+		defer cancel()
+		w := &timeoutResponse{
+			ResponseWriter: ctx.Response(),
+			header:         cloneHeader(ctx.Response().Header()),
+		}
+		timer := time.AfterFunc(t, w.writeTimeout)
+		ctx.WithContext(c)
+		ctx.SetResponse(w)
+		ctx.Next()
+
+		timer.Stop()
+		w.Lock()
+		ctx.SetResponse(w.ResponseWriter)
+		if w.timeout {
+			ctx.Error("request timeout after", t)
+		} else if !w.wrote {
+			w.writeHeader()
+		} else {
+			w.writeTrailer()
+		}
+		w.Unlock()
+	}
+}
+
+// timeoutResponse 定义超时响应，处理函数使用独立的header，超时后丢弃写入。
+type timeoutResponse struct {
+	eudore.ResponseWriter
+	sync.Mutex
+	header  http.Header
+	wrote   bool
+	timeout bool
+}
+
+// Header 方法返回处理函数使用的header，在第一次写入时复制到原始响应。
+func (w *timeoutResponse) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader 方法未超时时写入状态码。
+func (w *timeoutResponse) WriteHeader(code int) {
+	w.Lock()
+	defer w.Unlock()
+	if !w.timeout {
+		w.writeHeader()
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+// Write 方法未超时时写入数据，超时后返回http.ErrHandlerTimeout。
+func (w *timeoutResponse) Write(data []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	if w.timeout {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.writeHeader()
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteInformational 方法未写入响应时写入1xx响应。
+func (w *timeoutResponse) WriteInformational(code int, header http.Header) error {
+	w.Lock()
+	defer w.Unlock()
+	if w.timeout {
+		return http.ErrHandlerTimeout
+	}
+	if rw, ok := w.ResponseWriter.(eudore.ResponseInformational); ok {
+		return rw.WriteInformational(code, header)
+	}
+	return eudore.ErrResponseWriterInformationalNotSupport
+}
+
+// Flush 方法未超时时刷新缓冲。
+func (w *timeoutResponse) Flush() {
+	w.Lock()
+	defer w.Unlock()
+	if !w.timeout {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// Status 方法返回原始响应的状态码。
+func (w *timeoutResponse) Status() int {
+	w.Lock()
+	defer w.Unlock()
+	return w.ResponseWriter.Status()
+}
+
+// Size 方法返回原始响应写入的长度。
+func (w *timeoutResponse) Size() int {
+	w.Lock()
+	defer w.Unlock()
+	return w.ResponseWriter.Size()
+}
+
+// writeHeader 方法第一次写入时将处理函数设置的header复制到原始响应。
+func (w *timeoutResponse) writeHeader() {
+	if w.wrote {
+		return
+	}
+	w.wrote = true
+	h := w.ResponseWriter.Header()
+	for k := range h {
+		delete(h, k)
+	}
+	for k, v := range w.header {
+		h[k] = v
+	}
+}
+
+// writeTrailer 方法将处理函数在写入后设置的trailer复制到原始响应。
+func (w *timeoutResponse) writeTrailer() {
+	h := w.ResponseWriter.Header()
+	for k, v := range w.header {
+		if strings.HasPrefix(k, http.TrailerPrefix) {
+			h[k] = v
+		}
+	}
+}
+
+// writeTimeout 方法在超时时如果未写入响应则写入503。
+func (w *timeoutResponse) writeTimeout() {
+	w.Lock()
+	defer w.Unlock()
+	if w.wrote {
+		return
+	}
+	w.wrote = true
+	w.timeout = true
+	h := w.ResponseWriter.Header()
+	h.Set(eudore.HeaderContentType, eudore.MimeTextPlainCharsetUtf8)
+	w.ResponseWriter.WriteHeader(eudore.StatusServiceUnavailable)
+	w.ResponseWriter.Write([]byte(http.StatusText(eudore.StatusServiceUnavailable) + ": request timeout"))
+	w.ResponseWriter.Flush()
+}
+
+func cloneHeader(h http.Header) http.Header {
+	nh := make(http.Header, len(h))
+	for k, v := range h {
+		nh[k] = append([]string(nil), v...)
+	}
+	return nh
+}
@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// NewTimingFunc 函数创建一个处理耗时记录处理函数，记录后续每个中间件和路由处理函数的执行耗时，用于定位请求链中增加延迟的处理函数。
+//
+// 耗时为处理函数自身的执行时间，不包含其调用ctx.Next执行的后续处理函数；
+// 在响应首次写入时设置Server-Timing header，请求处理完成后设置路由参数timing，可以使用NewLoggerFunc(app, "timing")输出到日志。
+//
+// options:
+// bool      =>    是否设置Server-Timing header，默认true
+// string    =>    设置的路由参数名称，默认timing，为空时不设置
+func NewTimingFunc(options ...interface{}) eudore.HandlerFunc {
+	header, param := true, "timing"
+	for _, i := range options {
+		switch val := i.(type) {
+		case bool:
+			header = val
+		case string:
+			param = val
+		}
+	}
+	return func(ctx eudore.Context) {
+		index, handlers := ctx.GetHandler()
+		if index+1 >= len(handlers) {
+			return
+		}
+		r := newTimingRecorder(handlers[index+1:])
+		hs := make(eudore.HandlerFuncs, len(handlers))
+		copy(hs, handlers[:index+1])
+		for i, h := range handlers[index+1:] {
+			hs[index+1+i] = r.wrap(i, h)
+		}
+
+		if header {
+			w := &timingResponse{ResponseWriter: ctx.Response(), recorder: r}
+			ctx.SetResponse(w)
+			defer ctx.SetResponse(w.ResponseWriter)
+			defer w.setHeader()
+		}
+		ctx.SetHandler(index, hs)
+		ctx.Next()
+		if param != "" {
+			ctx.SetParam(param, r.String())
+		}
+	}
+}
+
+// timingRecorder 定义一个请求的处理函数耗时记录。
+type timingRecorder struct {
+	start  time.Time
+	names  []string
+	starts []time.Time
+	totals []time.Duration
+	childs []time.Duration
+	dones  []bool
+	stack  []int
+}
+
+func newTimingRecorder(hs eudore.HandlerFuncs) *timingRecorder {
+	r := &timingRecorder{
+		start:  time.Now(),
+		names:  make([]string, len(hs)),
+		starts: make([]time.Time, len(hs)),
+		totals: make([]time.Duration, len(hs)),
+		childs: make([]time.Duration, len(hs)),
+		dones:  make([]bool, len(hs)),
+	}
+	for i, h := range hs {
+		r.names[i] = getTimingName(h)
+	}
+	return r
+}
+
+// wrap 方法包装处理函数，记录处理函数总耗时并累加到调用者的子处理耗时。
+func (r *timingRecorder) wrap(i int, h eudore.HandlerFunc) eudore.HandlerFunc {
+	return func(ctx eudore.Context) {
+		r.starts[i] = time.Now()
+		r.stack = append(r.stack, i)
+		defer func() {
+			r.totals[i] = time.Since(r.starts[i])
+			r.dones[i] = true
+			r.stack = r.stack[:len(r.stack)-1]
+			if len(r.stack) > 0 {
+				r.childs[r.stack[len(r.stack)-1]] += r.totals[i]
+			}
+		}()
+		h(ctx)
+	}
+}
+
+// self 方法返回处理函数自身耗时，未执行返回false，执行中的处理函数使用当前已用时间。
+func (r *timingRecorder) self(i int) (time.Duration, bool) {
+	switch {
+	case r.dones[i]:
+		return r.totals[i] - r.childs[i], true
+	case !r.starts[i].IsZero():
+		// 执行中的子处理函数耗时未累加到childs，需要减去。
+		d := time.Since(r.starts[i]) - r.childs[i]
+		for pos, j := range r.stack {
+			if j == i && pos+1 < len(r.stack) {
+				d -= time.Since(r.starts[r.stack[pos+1]])
+			}
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// ServerTiming 方法返回Server-Timing header格式的耗时。
+func (r *timingRecorder) ServerTiming() string {
+	metrics := make([]string, 0, len(r.names)+1)
+	for i, name := range r.names {
+		if d, ok := r.self(i); ok {
+			metrics = append(metrics, fmt.Sprintf("h%d;desc=\"%s\";dur=%s", i, name, formatTimingDuration(d)))
+		}
+	}
+	metrics = append(metrics, "total;dur="+formatTimingDuration(time.Since(r.start)))
+	return strings.Join(metrics, ", ")
+}
+
+// String 方法返回日志格式的耗时，格式为name=duration。
+func (r *timingRecorder) String() string {
+	metrics := make([]string, 0, len(r.names))
+	for i, name := range r.names {
+		if d, ok := r.self(i); ok {
+			metrics = append(metrics, name+"="+d.String())
+		}
+	}
+	return strings.Join(metrics, " ")
+}
+
+// getTimingName 函数获取处理函数的短名称，去除包路径前缀和Server-Timing不允许的字符。
+func getTimingName(h eudore.HandlerFunc) string {
+	name := h.String()
+	if pos := strings.LastIndexByte(name, '/'); pos != -1 {
+		name = name[pos+1:]
+	}
+	return strings.NewReplacer(`"`, "", `\`, "", " ", "").Replace(name)
+}
+
+func formatTimingDuration(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
+
+// timingResponse 定义在响应首次写入时设置Server-Timing header的ResponseWriter。
+type timingResponse struct {
+	eudore.ResponseWriter
+	recorder *timingRecorder
+	written  bool
+}
+
+func (w *timingResponse) setHeader() {
+	if !w.written {
+		w.written = true
+		w.ResponseWriter.Header().Add(eudore.HeaderServerTiming, w.recorder.ServerTiming())
+	}
+}
+
+// WriteHeader 方法实现ResponseWriter中的WriteHeader方法。
+func (w *timingResponse) WriteHeader(code int) {
+	w.setHeader()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write 方法实现ResponseWriter中的Write方法。
+func (w *timingResponse) Write(data []byte) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 方法实现io.StringWriter接口。
+func (w *timingResponse) WriteString(data string) (int, error) {
+	w.setHeader()
+	return io.WriteString(w.ResponseWriter, data)
+}
+
+// Flush 方法实现ResponseWriter中的Flush方法。
+func (w *timingResponse) Flush() {
+	w.setHeader()
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,292 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eudore/eudore"
+)
+
+// TOTP 定义基于时间的一次性密码(RFC 6238)二次验证，使用HMAC-SHA1、30秒步长、6位验证码。
+//
+// 二次验证状态使用Store保存在会话id对应的"totp:"+id中，与OIDC使用相同的Store和Cookie时共享登录会话；
+// OIDC只接受自身生成的会话id，Cookie无法读取"totp:"前缀的二次验证状态。
+type TOTP struct {
+	sync.Mutex
+	Store OIDCSessionStore
+	// 读取会话id的Cookie名称，默认与OIDC相同为_oidc。
+	Cookie string
+	// GetUser 获取当前会话的用户，默认读取OIDC claims的sub。
+	GetUser func(eudore.Context) string
+	// GetSecret 获取用户的TOTP密钥，密钥为空表示用户未启用二次验证。
+	GetSecret func(context.Context, string) (string, error)
+	// 二次验证有效时间，默认8小时。
+	Expires time.Duration
+	// 允许的时间步长偏差，默认1。
+	Skew int
+	// Throttle 限制用户的验证失败次数，锁定时验证路由返回429，默认使用Store按用户记录失败次数。
+	//
+	// 可以使用password.Throttle，调用时ip参数为空，需要设置MaxIPAttempts为0。
+	Throttle TOTPThrottle
+	// MaxAttempts 为默认Throttle允许的失败次数，默认5。
+	MaxAttempts int
+	// Lockout 为默认Throttle失败次数的累计时间和锁定时间，默认15分钟。
+	Lockout time.Duration
+}
+
+// TOTPThrottle 定义验证失败次数限制，与password.Throttle方法相同。
+type TOTPThrottle interface {
+	Allow(account, ip string) (bool, time.Duration)
+	Failure(account, ip string) time.Duration
+	Success(account, ip string)
+}
+
+// totpThrottle 定义默认的验证失败次数限制，使用TOTP.Store保存用户失败次数。
+type totpThrottle struct {
+	totp *TOTP
+}
+
+const (
+	totpDigits = 6
+	totpPeriod = 30
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewTOTPSecret 函数生成一个160位随机TOTP密钥，使用无填充的base32编码，读取随机数失败返回错误。
+func NewTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// NewTOTPURI 函数创建TOTP配置URI，生成二维码后可以使用身份验证器应用扫描添加。
+//
+// 格式为otpauth://totp/Issuer:account?secret=xxx&issuer=Issuer&algorithm=SHA1&digits=6&period=30
+func NewTOTPURI(issuer, account, secret string) string {
+	label := account
+	if issuer != "" {
+		label = issuer + ":" + account
+	}
+	query := url.Values{
+		"secret":    {secret},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(totpDigits)},
+		"period":    {fmt.Sprint(totpPeriod)},
+	}
+	if issuer != "" {
+		query.Set("issuer", issuer)
+	}
+	return "otpauth://totp/" + url.PathEscape(label) + "?" + query.Encode()
+}
+
+// GetTOTPCode 函数计算密钥在指定时间的验证码，密钥不是有效的base32编码时返回错误。
+func GetTOTPCode(secret string, t time.Time) (string, error) {
+	key, err := getTOTPKey(secret)
+	if err != nil {
+		return "", err
+	}
+	return getTOTPCode(key, t.Unix()/totpPeriod), nil
+}
+
+// VerifyTOTPCode 函数校验验证码，允许前后skew个时间步长的偏差。
+func VerifyTOTPCode(secret, code string, t time.Time, skew int) bool {
+	_, ok := verifyTOTPCode(secret, code, t, skew)
+	return ok
+}
+
+// NewTOTPFunc 函数创建一个TOTP二次验证处理函数，用于保护敏感路由组。
+//
+// router参数是eudore.Router类型，然后注入验证路由POST /totp/verify，
+// store参数应该使用OIDC的会话存储，getSecret获取用户的TOTP密钥。
+func NewTOTPFunc(router eudore.Router, store OIDCSessionStore, getSecret func(context.Context, string) (string, error)) eudore.HandlerFunc {
+	t := NewTOTP(store)
+	t.GetSecret = getSecret
+	return t.NewTOTPFunc(router)
+}
+
+// NewTOTP 函数创建一个TOTP二次验证，store为空时使用内存存储。
+func NewTOTP(store OIDCSessionStore) *TOTP {
+	if store == nil {
+		store = NewOIDCSessionStoreMemory()
+	}
+	t := &TOTP{
+		Store:       store,
+		Cookie:      "_oidc",
+		Expires:     8 * time.Hour,
+		Skew:        1,
+		MaxAttempts: 5,
+		Lockout:     15 * time.Minute,
+	}
+	t.GetUser = t.getUser
+	t.Throttle = &totpThrottle{totp: t}
+	return t
+}
+
+// NewTOTPFunc 方法注入验证路由并返回二次验证处理函数。
+//
+// 验证路由读取body或表单的code字段，验证成功后当前会话在Expires时间内通过二次验证，
+// 同一用户已经使用的验证码不能再次使用。
+//
+// 会话未登录返回401，未通过二次验证或用户未启用二次验证返回403，用户验证失败次数达到限制后返回429。
+func (t *TOTP) NewTOTPFunc(router eudore.Router) eudore.HandlerFunc {
+	router.PostFunc("/totp/verify totp=verify", t.verify)
+	return func(ctx eudore.Context) {
+		if ctx.GetParam("totp") != "" {
+			return
+		}
+		id, user := ctx.GetCookie(t.Cookie), t.GetUser(ctx)
+		if !checkOIDCSessionID(id) || user == "" {
+			writeFatal(ctx, eudore.StatusUnauthorized, "totp session not found")
+			return
+		}
+		state := t.Store.Get("totp:" + id)
+		if state == nil || state["user"] != user {
+			writeFatal(ctx, eudore.StatusForbidden, "totp verification required")
+		}
+	}
+}
+
+// verify 方法校验验证码并标记会话通过二次验证。
+func (t *TOTP) verify(ctx eudore.Context) {
+	id, user := ctx.GetCookie(t.Cookie), t.GetUser(ctx)
+	if !checkOIDCSessionID(id) || user == "" {
+		writeFatal(ctx, eudore.StatusUnauthorized, "totp session not found")
+		return
+	}
+	var req struct {
+		Code string `json:"code" form:"code"`
+	}
+	err := ctx.Bind(&req)
+	if err != nil {
+		writeFatal(ctx, eudore.StatusBadRequest, err.Error())
+		return
+	}
+	secret, err := t.GetSecret(ctx.GetContext(), user)
+	if err != nil {
+		writeFatal(ctx, eudore.StatusInternalServerError, err.Error())
+		return
+	}
+	if secret == "" {
+		writeFatal(ctx, eudore.StatusForbidden, "totp is not enrolled")
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	if ok, wait := t.Throttle.Allow(user, ""); !ok {
+		ctx.SetHeader(eudore.HeaderRetryAfter, strconv.Itoa(int(wait/time.Second)+1))
+		writeFatal(ctx, eudore.StatusTooManyRequests, "totp attempts exceeded")
+		return
+	}
+	step, ok := verifyTOTPCode(secret, req.Code, time.Now(), t.Skew)
+	if !ok || step <= t.getLastStep(user) {
+		if wait := t.Throttle.Failure(user, ""); wait > 0 {
+			ctx.SetHeader(eudore.HeaderRetryAfter, strconv.Itoa(int(wait/time.Second)+1))
+			writeFatal(ctx, eudore.StatusTooManyRequests, "totp attempts exceeded")
+			return
+		}
+		writeFatal(ctx, eudore.StatusForbidden, "totp code invalid")
+		return
+	}
+	t.Throttle.Success(user, "")
+	// 时间步长最多在skew+1个步长后过期，之后无需保留。
+	t.Store.Set("totp-step:"+user, map[string]interface{}{"step": float64(step)}, time.Duration(2*t.Skew+2)*totpPeriod*time.Second)
+	t.Store.Set("totp:"+id, map[string]interface{}{"user": user}, t.Expires)
+}
+
+// getUser 方法读取OIDC claims的sub，没有OIDC中间件时使用会话id从Store读取claims。
+func (t *TOTP) getUser(ctx eudore.Context) string {
+	claims := GetOIDCClaims(ctx)
+	if id := ctx.GetCookie(t.Cookie); claims == nil && checkOIDCSessionID(id) {
+		claims = t.Store.Get(id)
+	}
+	sub, _ := claims["sub"].(string)
+	return sub
+}
+
+// getLastStep 方法获取用户最后一次使用的时间步长，Cache存储的数字使用json解析为float64。
+func (t *TOTP) getLastStep(user string) int64 {
+	step, _ := t.Store.Get("totp-step:" + user)["step"].(float64)
+	return int64(step)
+}
+
+// Allow 方法检查用户是否锁定，锁定时返回剩余锁定时间。
+func (t *totpThrottle) Allow(account, _ string) (bool, time.Duration) {
+	until, _ := t.totp.Store.Get("totp-fail:" + account)["until"].(float64)
+	wait := time.Until(time.Unix(int64(until), 0))
+	if wait > 0 {
+		return false, wait
+	}
+	return true, 0
+}
+
+// Failure 方法记录一次验证失败，在Lockout时间内达到MaxAttempts次后锁定Lockout时间。
+func (t *totpThrottle) Failure(account, _ string) time.Duration {
+	key := "totp-fail:" + account
+	state := t.totp.Store.Get(key)
+	count, _ := state["count"].(float64)
+	reset, _ := state["reset"].(float64)
+	now := time.Now()
+	if state == nil || now.Unix() >= int64(reset) {
+		count, reset = 0, float64(now.Add(t.totp.Lockout).Unix())
+	}
+	count++
+	if int(count) >= t.totp.MaxAttempts {
+		until := now.Add(t.totp.Lockout)
+		t.totp.Store.Set(key, map[string]interface{}{"until": float64(until.Unix())}, t.totp.Lockout)
+		return t.totp.Lockout
+	}
+	t.totp.Store.Set(key, map[string]interface{}{"count": count, "reset": reset}, time.Unix(int64(reset), 0).Sub(now))
+	return 0
+}
+
+// Success 方法在验证成功后清除用户失败次数。
+func (t *totpThrottle) Success(account, _ string) {
+	t.totp.Store.Delete("totp-fail:" + account)
+}
+
+func verifyTOTPCode(secret, code string, t time.Time, skew int) (int64, bool) {
+	key, err := getTOTPKey(secret)
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+	current := t.Unix() / totpPeriod
+	for i := -int64(skew); i <= int64(skew); i++ {
+		if subtle.ConstantTimeCompare([]byte(getTOTPCode(key, current+i)), []byte(code)) == 1 {
+			return current + i, true
+		}
+	}
+	return 0, false
+}
+
+func getTOTPKey(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.Replace(secret, " ", "", -1))
+	return totpEncoding.DecodeString(strings.TrimRight(secret, "="))
+}
+
+// getTOTPCode 函数使用RFC 4226动态截断计算验证码。
+func getTOTPCode(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	h := hmac.New(sha1.New, key)
+	h.Write(msg[:])
+	sum := h.Sum(nil)
+	offset := sum[len(sum)-1] & 0xf
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/eudore/eudore"
+)
+
+// 定义UserAgent设备类型。
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+	DeviceUnknown = "unknown"
+)
+
+// UserAgent 定义User-Agent解析结果。
+type UserAgent struct {
+	Browser        string `json:"browser"`
+	BrowserVersion string `json:"browserVersion"`
+	OS             string `json:"os"`
+	OSVersion      string `json:"osVersion"`
+	Device         string `json:"device"`
+}
+
+type userAgentKey struct{}
+
+// userAgentCache 定义User-Agent解析缓存，数量达到上限后清空。
+type userAgentCache struct {
+	sync.RWMutex
+	data map[string]*UserAgent
+	size int
+}
+
+var defaultUserAgentCache = &userAgentCache{data: make(map[string]*UserAgent), size: 4096}
+
+var userAgentBots = []string{"bot", "spider", "crawl", "slurp", "headless", "curl/", "wget/", "python-", "go-http-client", "java/", "okhttp/"}
+
+// userAgentBrowsers 定义浏览器匹配顺序，基于Chromium的浏览器需要在Chrome之前匹配。
+var userAgentBrowsers = [][2]string{
+	{"Edg/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"EdgiOS/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"UCBrowser/", "UC Browser"},
+	{"YaBrowser/", "Yandex"},
+	{"MicroMessenger/", "WeChat"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"MSIE ", "IE"},
+}
+
+var userAgentWindows = map[string]string{
+	"10.0": "10",
+	"6.3":  "8.1",
+	"6.2":  "8",
+	"6.1":  "7",
+	"6.0":  "Vista",
+	"5.1":  "XP",
+}
+
+// NewUserAgentFunc 函数创建一个User-Agent解析处理函数，解析结果使用GetUserAgent函数获取，
+// 并设置browser、os、device参数用于日志输出，解析结果按User-Agent缓存。
+func NewUserAgentFunc() eudore.HandlerFunc {
+	return func(ctx eudore.Context) {
+		ua := ParseUserAgent(ctx.GetHeader(eudore.HeaderUserAgent))
+		ctx.WithContext(context.WithValue(ctx.GetContext(), userAgentKey{}, ua))
+		if ua.Browser != "" {
+			ctx.SetParam("browser", strings.TrimSpace(ua.Browser+" "+ua.BrowserVersion))
+		}
+		if ua.OS != "" {
+			ctx.SetParam("os", strings.TrimSpace(ua.OS+" "+ua.OSVersion))
+		}
+		ctx.SetParam("device", ua.Device)
+	}
+}
+
+// GetUserAgent 函数获取请求的User-Agent解析结果，没有使用NewUserAgentFunc时解析请求Header。
+func GetUserAgent(ctx eudore.Context) *UserAgent {
+	ua, ok := ctx.GetContext().Value(userAgentKey{}).(*UserAgent)
+	if ok {
+		return ua
+	}
+	return ParseUserAgent(ctx.GetHeader(eudore.HeaderUserAgent))
+}
+
+// ParseUserAgent 函数解析User-Agent的浏览器、操作系统和设备类型，解析结果按User-Agent缓存，返回值不可修改。
+func ParseUserAgent(str string) *UserAgent {
+	c := defaultUserAgentCache
+	c.RLock()
+	ua, ok := c.data[str]
+	c.RUnlock()
+	if ok {
+		return ua
+	}
+
+	ua = parseUserAgent(str)
+	c.Lock()
+	if len(c.data) >= c.size {
+		c.data = make(map[string]*UserAgent)
+	}
+	c.data[str] = ua
+	c.Unlock()
+	return ua
+}
+
+func parseUserAgent(str string) *UserAgent {
+	ua := &UserAgent{Device: DeviceUnknown}
+	if str == "" {
+		return ua
+	}
+	lower := strings.ToLower(str)
+	for _, bot := range userAgentBots {
+		if strings.Contains(lower, bot) {
+			ua.Device = DeviceBot
+			ua.Browser, ua.BrowserVersion = getUserAgentProduct(str, strings.Index(lower, bot))
+			return ua
+		}
+	}
+
+	for _, browser := range userAgentBrowsers {
+		if pos := strings.Index(str, browser[0]); pos != -1 {
+			ua.Browser = browser[1]
+			ua.BrowserVersion = getUserAgentVersion(str[pos+len(browser[0]):])
+			break
+		}
+	}
+	switch {
+	case ua.Browser != "":
+	case strings.Contains(str, "Trident/"):
+		ua.Browser = "IE"
+		if pos := strings.Index(str, "rv:"); pos != -1 {
+			ua.BrowserVersion = getUserAgentVersion(str[pos+3:])
+		}
+	case strings.Contains(str, "Safari/"):
+		ua.Browser = "Safari"
+		if pos := strings.Index(str, "Version/"); pos != -1 {
+			ua.BrowserVersion = getUserAgentVersion(str[pos+8:])
+		}
+	}
+
+	parseUserAgentOS(ua, str)
+	switch {
+	case strings.Contains(str, "iPad") || strings.Contains(str, "Tablet") || (ua.OS == "Android" && !strings.Contains(str, "Mobile")):
+		ua.Device = DeviceTablet
+	case strings.Contains(str, "Mobi") || strings.Contains(str, "iPhone") || ua.OS == "Android":
+		ua.Device = DeviceMobile
+	case ua.OS != "":
+		ua.Device = DeviceDesktop
+	}
+	return ua
+}
+
+func parseUserAgentOS(ua *UserAgent, str string) {
+	switch {
+	case strings.Contains(str, "Windows NT "):
+		ua.OS = "Windows"
+		version := getUserAgentVersion(str[strings.Index(str, "Windows NT ")+11:])
+		ua.OSVersion = userAgentWindows[version]
+		if ua.OSVersion == "" {
+			ua.OSVersion = version
+		}
+	case strings.Contains(str, "Android"):
+		ua.OS = "Android"
+		ua.OSVersion = getUserAgentVersion(strings.TrimPrefix(str[strings.Index(str, "Android")+7:], " "))
+	case strings.Contains(str, "iPhone OS ") || strings.Contains(str, "CPU OS "):
+		ua.OS = "iOS"
+		pos := strings.Index(str, " OS ")
+		ua.OSVersion = getUserAgentVersion(strings.Replace(str[pos+4:], "_", ".", -1))
+	case strings.Contains(str, "Mac OS X"):
+		ua.OS = "macOS"
+		ua.OSVersion = getUserAgentVersion(strings.Replace(strings.TrimPrefix(str[strings.Index(str, "Mac OS X")+8:], " "), "_", ".", -1))
+	case strings.Contains(str, "CrOS"):
+		ua.OS = "ChromeOS"
+	case strings.Contains(str, "Linux"):
+		ua.OS = "Linux"
+	}
+}
+
+// getUserAgentProduct 函数获取pos位置所在的product名称和版本，例如Googlebot/2.1。
+func getUserAgentProduct(str string, pos int) (string, string) {
+	start := strings.LastIndexAny(str[:pos], " (;+") + 1
+	end := strings.IndexAny(str[start:], " ;)/")
+	if end == -1 {
+		return str[start:], ""
+	}
+	name := str[start : start+end]
+	if str[start+end] == '/' {
+		return name, getUserAgentVersion(str[start+end+1:])
+	}
+	return name, ""
+}
+
+// getUserAgentVersion 函数获取字符串开头的版本号。
+func getUserAgentVersion(str string) string {
+	for i, c := range str {
+		if (c < '0' || c > '9') && c != '.' {
+			return str[:i]
+		}
+	}
+	return str
+}
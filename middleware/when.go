@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/eudore/eudore"
+)
+
+// NewWhenFunc 函数创建一个条件处理函数，predicate返回true时执行处理函数h，否则跳过h继续执行后续处理函数。
+//
+// 可以使用WhenPath、WhenMethod、WhenHeader、WhenMode、WhenNot、WhenAll和WhenAny组合条件，
+// 返回的处理函数名称为when(h)，可以使用RouterStd.GetMiddlewares查看。
+func NewWhenFunc(predicate func(eudore.Context) bool, h eudore.HandlerFunc) eudore.HandlerFunc {
+	fn := func(ctx eudore.Context) {
+		if predicate(ctx) {
+			h(ctx)
+		}
+	}
+	eudore.SetHandlerFuncName(fn, "when("+h.String()+")")
+	return fn
+}
+
+// WhenPath 函数创建路径匹配任意模式的条件，模式使用path.Match语法，以'*'结尾时匹配路径前缀。
+func WhenPath(patterns ...string) func(eudore.Context) bool {
+	return func(ctx eudore.Context) bool {
+		p := ctx.Path()
+		for _, pattern := range patterns {
+			if strings.HasSuffix(pattern, "*") && strings.HasPrefix(p, pattern[:len(pattern)-1]) {
+				return true
+			}
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// WhenMethod 函数创建请求方法为任意方法的条件。
+func WhenMethod(methods ...string) func(eudore.Context) bool {
+	for i := range methods {
+		methods[i] = strings.ToUpper(methods[i])
+	}
+	return func(ctx eudore.Context) bool {
+		method := ctx.Method()
+		for _, i := range methods {
+			if i == method {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// WhenHeader 函数创建请求Header存在的条件，vals非空时Header值需要等于任意一个值。
+func WhenHeader(key string, vals ...string) func(eudore.Context) bool {
+	key = http.CanonicalHeaderKey(key)
+	return func(ctx eudore.Context) bool {
+		val, ok := ctx.Request().Header[key]
+		if !ok {
+			return false
+		}
+		if len(vals) == 0 {
+			return true
+		}
+		for _, i := range vals {
+			if len(val) > 0 && val[0] == i {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// WhenMode 函数创建app运行在任意模式的条件，每次请求读取app模式，例如开发模式输出详细错误。
+func WhenMode(app *eudore.App, modes ...string) func(eudore.Context) bool {
+	return func(eudore.Context) bool {
+		return app.IsMode(modes...)
+	}
+}
+
+// WhenNot 函数创建条件取反的条件。
+func WhenNot(fn func(eudore.Context) bool) func(eudore.Context) bool {
+	return func(ctx eudore.Context) bool {
+		return !fn(ctx)
+	}
+}
+
+// WhenAll 函数创建全部条件成立的条件。
+func WhenAll(fns ...func(eudore.Context) bool) func(eudore.Context) bool {
+	return func(ctx eudore.Context) bool {
+		for _, fn := range fns {
+			if !fn(ctx) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// WhenAny 函数创建任意条件成立的条件。
+func WhenAny(fns ...func(eudore.Context) bool) func(eudore.Context) bool {
+	return func(ctx eudore.Context) bool {
+		for _, fn := range fns {
+			if fn(ctx) {
+				return true
+			}
+		}
+		return false
+	}
+}
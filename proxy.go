@@ -0,0 +1,639 @@
+package eudore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProxyBalancer defines a reverse proxy load balancer that supports consistent hashing, sticky sessions and draining upstreams.
+//
+// ProxyBalancer 定义反向代理负载均衡，支持一致性哈希、粘性会话和摘除上游。
+//
+// 存在粘性会话Cookie时使用Cookie记录的上游，否则Hash返回非空key时使用一致性哈希选择上游，否则轮询；
+// 摘除中的上游不分配新请求，已有粘性会话继续访问，Active为0后可以下线上游。
+//
+// 被动健康检查统计上游连续的连接失败、超时和5xx响应，客户端取消的请求不计入，达到Consecutive次后驱逐上游，
+// 驱逐时间从EjectionTime开始每次翻倍，最大MaxEjectionTime，恢复后在SlowStart时间内逐步增加流量；
+// 不会驱逐最后一个可用上游，全部上游被驱逐时忽略驱逐状态。
+type ProxyBalancer struct {
+	sync.RWMutex
+	// Hash 返回一致性哈希的key，例如Cookie、Header或IP，为空时轮询。
+	Hash func(Context) string
+	// Sticky 为粘性会话Cookie名称，为空时不使用粘性会话。
+	Sticky string
+	// MaxAge 为粘性会话Cookie的有效时间，默认为0浏览器关闭后失效。
+	MaxAge int
+	// Consecutive 为驱逐上游的连续失败次数，默认5，为0时不检查。
+	Consecutive int32
+	// EjectionTime 为首次驱逐时间，默认10秒。
+	EjectionTime time.Duration
+	// MaxEjectionTime 为最大驱逐时间，默认5分钟，上游在该时间内没有被驱逐后驱逐时间重置。
+	MaxEjectionTime time.Duration
+	// SlowStart 为驱逐结束后逐步恢复流量的时间，默认30秒。
+	SlowStart time.Duration
+	upstreams []*proxyUpstream
+	options   []interface{}
+	retry     *ProxyRetry
+	ring      []proxyNode
+	next      uint32
+}
+
+// ProxyUpstream 定义上游状态。
+type ProxyUpstream struct {
+	ID       string `json:"id"`
+	Target   string `json:"target"`
+	Draining bool   `json:"draining"`
+	Active   int64  `json:"active"`
+	Requests uint64 `json:"requests"`
+	// Ejected 为上游当前是否被驱逐。
+	Ejected bool `json:"ejected"`
+	// Ejections 为上游累计被驱逐次数。
+	Ejections uint64 `json:"ejections"`
+	// Failures 为上游当前连续失败次数。
+	Failures int32 `json:"failures"`
+	// EjectUntil 为最近一次驱逐的结束时间。
+	EjectUntil time.Time `json:"ejectuntil"`
+}
+
+// proxyUpstream 定义上游，原子计数放在开头保证64位对齐。
+type proxyUpstream struct {
+	active     int64
+	requests   uint64
+	ejections  uint64
+	ejectUntil int64
+	draining   int32
+	failures   int32
+	ejectLevel int32
+	id         string
+	target     string
+	host       string
+	handler    HandlerFunc
+	director   func(*http.Request)
+	transport  http.RoundTripper
+}
+
+type proxyNode struct {
+	hash     uint32
+	upstream *proxyUpstream
+}
+
+// proxyReplicas 定义每个上游在哈希环上的虚拟节点数量。
+const proxyReplicas = 160
+
+// proxyStatusClientClosed 定义客户端取消请求时代理返回的状态码，与nginx相同。
+const proxyStatusClientClosed = 499
+
+// NewProxyHandler 函数创建一个反向代理处理函数，将请求转发给target，代理失败返回502，
+// target使用逗号分隔多个时使用NewProxyBalancer轮询。
+//
+// target协议为h2c时使用明文http2连接上游，为grpc、grpcs时透传gRPC请求，
+// 响应立即刷新并转发trailer，代理失败时额外返回gRPC状态码14(Unavailable)，h2c和grpc需要go1.24。
+//
+// options:
+// *ProxyTransform    =>    请求或响应body转换，可以使用NewProxyTransform创建
+// *ProxyRetry        =>    重试和对冲策略
+func NewProxyHandler(target string, options ...interface{}) (HandlerFunc, error) {
+	if strings.Contains(target, ",") {
+		b, err := NewProxyBalancer(strings.Split(target, ","), options...)
+		if err != nil {
+			return nil, err
+		}
+		return b.HandleHTTP, nil
+	}
+	proxy, reqs, err := newProxyReverse(target, options)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range options {
+		if retry, ok := i.(*ProxyRetry); ok && retry != nil {
+			proxy.Transport = proxyRetryTransport{retry: retry, transport: proxy.Transport}
+		}
+	}
+	return newProxyHandler(proxy, reqs), nil
+}
+
+// newProxyReverse 函数创建target的反向代理，返回请求body转换。
+func newProxyReverse(target string, options []interface{}) (*httputil.ReverseProxy, []*ProxyTransform, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, nil, fmt.Errorf("invalid proxy target %s", target)
+	}
+
+	var transport http.RoundTripper = http.DefaultTransport
+	grpc := u.Scheme == "grpc" || u.Scheme == "grpcs"
+	switch u.Scheme {
+	case "h2c", "grpc":
+		if proxyTransportH2C == nil {
+			return nil, nil, fmt.Errorf("proxy target %s h2c requires go1.24", target)
+		}
+		u.Scheme, transport = "http", proxyTransportH2C
+	case "grpcs":
+		u.Scheme = "https"
+	}
+	proxy := httputil.NewSingleHostReverseProxy(u)
+	proxy.Transport = transport
+	if grpc {
+		proxy.FlushInterval = -1
+	}
+	reqs, resps := splitProxyTransforms(options)
+	if resps != nil {
+		director := proxy.Director
+		proxy.Director = func(r *http.Request) {
+			director(r)
+			r.Header.Del(HeaderAcceptEncoding)
+		}
+		proxy.ModifyResponse = func(resp *http.Response) error {
+			return transformProxyResponse(resp, resps)
+		}
+	}
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		// 客户端取消请求不是上游错误，不返回502。
+		if errors.Is(err, context.Canceled) || r.Context().Err() == context.Canceled {
+			w.WriteHeader(proxyStatusClientClosed)
+			return
+		}
+		if ctx := GetRequestContext(r); ctx != nil {
+			ctx.Error("proxy error:", err)
+		}
+		if grpc {
+			w.Header().Set(HeaderContentType, "application/grpc")
+			w.Header().Set("Grpc-Status", "14")
+			w.Header().Set("Grpc-Message", "proxy upstream unavailable")
+		}
+		w.WriteHeader(StatusBadGateway)
+	}
+	return proxy, reqs, nil
+}
+
+// newProxyHandler 函数创建反向代理处理函数，代理前执行请求body转换。
+func newProxyHandler(proxy *httputil.ReverseProxy, reqs []*ProxyTransform) HandlerFunc {
+	if reqs == nil {
+		return NewExtendHandlerNetHTTP(proxy)
+	}
+	return func(ctx Context) {
+		r := newNetHTTPRequest(ctx)
+		code, err := transformProxyRequest(r, reqs)
+		if err != nil {
+			ctx.WriteHeader(code)
+			ctx.Fatal(err)
+			return
+		}
+		proxy.ServeHTTP(ctx.Response(), r)
+	}
+}
+
+// splitProxyTransforms 函数从选项中分离请求和响应body转换。
+func splitProxyTransforms(options []interface{}) (reqs, resps []*ProxyTransform) {
+	for _, i := range options {
+		t, ok := i.(*ProxyTransform)
+		if !ok || t == nil {
+			continue
+		}
+		if t.Request {
+			reqs = append(reqs, t)
+		} else {
+			resps = append(resps, t)
+		}
+	}
+	return
+}
+
+// NewProxyBalancer 函数创建一个反向代理负载均衡。
+//
+// options:
+// func(Context) string    =>    一致性哈希key函数，可以使用NewProxyHashCookie、NewProxyHashHeader、NewProxyHashIP创建
+// string                  =>    粘性会话Cookie名称
+// *ProxyTransform         =>    请求或响应body转换，全部上游使用
+// *ProxyRetry             =>    重试和对冲策略，重试和对冲请求选择其他上游，Next返回的host为上游时使用该上游
+func NewProxyBalancer(targets []string, options ...interface{}) (*ProxyBalancer, error) {
+	b := &ProxyBalancer{
+		Consecutive:     5,
+		EjectionTime:    10 * time.Second,
+		MaxEjectionTime: 5 * time.Minute,
+		SlowStart:       30 * time.Second,
+	}
+	for _, i := range options {
+		switch val := i.(type) {
+		case func(Context) string:
+			b.Hash = val
+		case string:
+			b.Sticky = val
+		case *ProxyTransform:
+			b.options = append(b.options, val)
+		case *ProxyRetry:
+			b.retry = val
+		}
+	}
+	for _, target := range targets {
+		err := b.Add(strings.TrimSpace(target))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// NewProxyHashCookie 函数创建使用Cookie值作为一致性哈希key的函数。
+func NewProxyHashCookie(name string) func(Context) string {
+	return func(ctx Context) string {
+		return ctx.GetCookie(name)
+	}
+}
+
+// NewProxyHashHeader 函数创建使用Header值作为一致性哈希key的函数。
+func NewProxyHashHeader(name string) func(Context) string {
+	return func(ctx Context) string {
+		return ctx.GetHeader(name)
+	}
+}
+
+// NewProxyHashIP 函数创建使用RealIP作为一致性哈希key的函数。
+func NewProxyHashIP() func(Context) string {
+	return func(ctx Context) string {
+		return ctx.RealIP()
+	}
+}
+
+// Add 方法添加一个上游，重建哈希环，只有新上游附近的key会改变选择的上游。
+func (b *ProxyBalancer) Add(target string) error {
+	proxy, reqs, err := newProxyReverse(target, b.options)
+	if err != nil {
+		return err
+	}
+	u, _ := url.Parse(target)
+	upstream := &proxyUpstream{
+		id:        strconv.FormatUint(uint64(getProxyHash(target)), 36),
+		target:    target,
+		host:      u.Host,
+		director:  proxy.Director,
+		transport: proxy.Transport,
+	}
+	proxy.Transport = proxyUpstreamTransport{b, upstream}
+	if b.retry != nil {
+		proxy.Transport = proxyRetryTransport{
+			retry:     b.retry,
+			transport: proxy.Transport,
+			next: func(r *http.Request, attempt int) (*http.Response, error) {
+				return b.roundTripUpstream(upstream, r, attempt)
+			},
+		}
+	}
+	upstream.handler = newProxyHandler(proxy, reqs)
+
+	b.Lock()
+	defer b.Unlock()
+	for _, i := range b.upstreams {
+		if i.target == target {
+			return fmt.Errorf("proxy upstream %s is exists", target)
+		}
+	}
+	b.upstreams = append(b.upstreams, upstream)
+	b.buildRing()
+	return nil
+}
+
+// Remove 方法删除一个上游，粘性会话会重新选择上游。
+func (b *ProxyBalancer) Remove(target string) {
+	b.Lock()
+	defer b.Unlock()
+	for i, upstream := range b.upstreams {
+		if upstream.target == target {
+			b.upstreams = append(b.upstreams[:i:i], b.upstreams[i+1:]...)
+			b.buildRing()
+			return
+		}
+	}
+}
+
+// Drain 方法设置上游是否摘除，摘除中的上游只处理已有粘性会话的请求。
+func (b *ProxyBalancer) Drain(target string, drain bool) error {
+	b.RLock()
+	defer b.RUnlock()
+	for _, upstream := range b.upstreams {
+		if upstream.target == target {
+			var val int32
+			if drain {
+				val = 1
+			}
+			atomic.StoreInt32(&upstream.draining, val)
+			return nil
+		}
+	}
+	return fmt.Errorf("proxy upstream %s not found", target)
+}
+
+// Upstreams 方法返回全部上游状态和驱逐指标，摘除中的上游Active为0后可以安全下线。
+func (b *ProxyBalancer) Upstreams() []ProxyUpstream {
+	b.RLock()
+	defer b.RUnlock()
+	now := time.Now().UnixNano()
+	upstreams := make([]ProxyUpstream, len(b.upstreams))
+	for i, upstream := range b.upstreams {
+		until := atomic.LoadInt64(&upstream.ejectUntil)
+		upstreams[i] = ProxyUpstream{
+			ID:        upstream.id,
+			Target:    upstream.target,
+			Draining:  atomic.LoadInt32(&upstream.draining) == 1,
+			Active:    atomic.LoadInt64(&upstream.active),
+			Requests:  atomic.LoadUint64(&upstream.requests),
+			Ejected:   until > now,
+			Ejections: atomic.LoadUint64(&upstream.ejections),
+			Failures:  atomic.LoadInt32(&upstream.failures),
+		}
+		if until != 0 {
+			upstreams[i].EjectUntil = time.Unix(0, until)
+		}
+	}
+	return upstreams
+}
+
+// HandleHTTP 方法选择上游并转发请求，没有可用上游时返回503。
+func (b *ProxyBalancer) HandleHTTP(ctx Context) {
+	var upstream *proxyUpstream
+	var sticky string
+	if b.Sticky != "" {
+		sticky = ctx.GetCookie(b.Sticky)
+	}
+	var key string
+	if b.Hash != nil {
+		key = b.Hash(ctx)
+	}
+	now := time.Now().UnixNano()
+	b.RLock()
+	if sticky != "" {
+		upstream = b.getUpstream(sticky, now)
+	}
+	for _, strict := range [...]bool{true, false} {
+		if upstream != nil {
+			break
+		}
+		if key != "" {
+			upstream = b.getHashUpstream(key, now, strict)
+		} else {
+			upstream = b.getNextUpstream(now, strict)
+		}
+	}
+	b.RUnlock()
+
+	if upstream == nil {
+		ctx.WriteHeader(StatusServiceUnavailable)
+		ctx.Fatal("proxy no available upstream")
+		ctx.End()
+		return
+	}
+	if b.Sticky != "" && sticky != upstream.id {
+		ctx.SetCookieValue(b.Sticky, upstream.id, b.MaxAge)
+	}
+	atomic.AddUint64(&upstream.requests, 1)
+	atomic.AddInt64(&upstream.active, 1)
+	defer atomic.AddInt64(&upstream.active, -1)
+	upstream.handler(ctx)
+}
+
+// roundTripUpstream 方法发送第attempt次请求，重试和对冲请求选择其他上游，
+// 使用上游的Director重写原始请求并使用上游的Transport发送，记录上游的请求数量和结果，
+// 只有连接错误和上游5xx响应计为失败，客户端取消的请求不记录结果。
+func (b *ProxyBalancer) roundTripUpstream(current *proxyUpstream, req *http.Request, attempt int) (*http.Response, error) {
+	ctx := GetRequestContext(req)
+	if ctx == nil {
+		return current.transport.RoundTrip(req)
+	}
+	// 对冲请求可能在Context释放后返回，使用ctx.Logger()记录驱逐日志。
+	log := ctx.Logger()
+	upstream, r := current, req
+	if attempt > 0 {
+		var host string
+		if b.retry.Next != nil {
+			host = b.retry.Next(req, attempt)
+		}
+		upstream = b.getRetryUpstream(current, host)
+		u := *ctx.Request().URL
+		r = newProxyRetryRequest(req)
+		r.URL = &u
+		r.Header = req.Header.Clone()
+		upstream.director(r)
+		atomic.AddUint64(&upstream.requests, 1)
+		atomic.AddInt64(&upstream.active, 1)
+	}
+
+	resp, err := upstream.transport.RoundTrip(r)
+	if err == nil || (r.Context().Err() == nil && !errors.Is(err, context.Canceled)) {
+		b.checkUpstream(log, upstream, err != nil || resp.StatusCode >= StatusInternalServerError)
+	}
+	if attempt > 0 {
+		if err != nil || resp.StatusCode == StatusSwitchingProtocols {
+			atomic.AddInt64(&upstream.active, -1)
+		} else {
+			resp.Body = &proxyUpstreamBody{ReadCloser: resp.Body, active: &upstream.active}
+		}
+	}
+	return resp, err
+}
+
+// RoundTrip 方法使用上游的Transport发送请求并记录结果。
+func (t proxyUpstreamTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.balancer.roundTripUpstream(t.upstream, req, 0)
+}
+
+// checkUpstream 方法记录请求结果，连续失败达到Consecutive次后驱逐上游。
+func (b *ProxyBalancer) checkUpstream(log Logout, upstream *proxyUpstream, failed bool) {
+	if b.Consecutive <= 0 {
+		return
+	}
+	if !failed {
+		atomic.StoreInt32(&upstream.failures, 0)
+		// 超过最大驱逐时间没有被驱逐，重置驱逐时间。
+		until := atomic.LoadInt64(&upstream.ejectUntil)
+		if until != 0 && time.Now().UnixNano()-until > int64(b.MaxEjectionTime) {
+			atomic.StoreInt32(&upstream.ejectLevel, 0)
+		}
+		return
+	}
+	if atomic.AddInt32(&upstream.failures, 1) < b.Consecutive {
+		return
+	}
+
+	b.Lock()
+	defer b.Unlock()
+	now := time.Now().UnixNano()
+	if atomic.LoadInt64(&upstream.ejectUntil) > now || atomic.LoadInt32(&upstream.failures) < b.Consecutive {
+		return
+	}
+	// 不驱逐最后一个可用上游。
+	var available int
+	for _, i := range b.upstreams {
+		if i != upstream && atomic.LoadInt32(&i.draining) == 0 && atomic.LoadInt64(&i.ejectUntil) <= now {
+			available++
+		}
+	}
+	if available == 0 {
+		return
+	}
+	level := atomic.AddInt32(&upstream.ejectLevel, 1)
+	duration := b.EjectionTime << uint(level-1)
+	if duration > b.MaxEjectionTime || duration <= 0 {
+		duration = b.MaxEjectionTime
+		atomic.AddInt32(&upstream.ejectLevel, -1)
+	}
+	atomic.StoreInt32(&upstream.failures, 0)
+	atomic.StoreInt64(&upstream.ejectUntil, now+int64(duration))
+	atomic.AddUint64(&upstream.ejections, 1)
+	log.Warningf("proxy upstream %s ejected %s after %d consecutive failures", upstream.target, duration, b.Consecutive)
+}
+
+// isAvailable 方法检查上游是否可以分配新请求，strict为true时跳过被驱逐的上游，
+// 驱逐结束后在SlowStart时间内按已恢复时间比例随机分配请求。
+func (b *ProxyBalancer) isAvailable(upstream *proxyUpstream, now int64, strict bool) bool {
+	if atomic.LoadInt32(&upstream.draining) == 1 {
+		return false
+	}
+	if !strict {
+		return true
+	}
+	until := atomic.LoadInt64(&upstream.ejectUntil)
+	switch {
+	case until > now:
+		return false
+	case until != 0 && b.SlowStart > 0 && now-until < int64(b.SlowStart):
+		return rand.Int63n(int64(b.SlowStart)) < now-until
+	}
+	return true
+}
+
+// getUpstream 方法使用粘性会话id查找上游，摘除中的上游也会返回，被驱逐的上游重新选择。
+func (b *ProxyBalancer) getUpstream(id string, now int64) *proxyUpstream {
+	for _, upstream := range b.upstreams {
+		if upstream.id == id {
+			if atomic.LoadInt64(&upstream.ejectUntil) > now {
+				return nil
+			}
+			return upstream
+		}
+	}
+	return nil
+}
+
+// getHashUpstream 方法在哈希环上查找key之后第一个可用的上游。
+func (b *ProxyBalancer) getHashUpstream(key string, now int64, strict bool) *proxyUpstream {
+	if len(b.ring) == 0 {
+		return nil
+	}
+	hash := getProxyHash(key)
+	start := sort.Search(len(b.ring), func(i int) bool {
+		return b.ring[i].hash >= hash
+	})
+	// 每个上游只检查一次，避免多个虚拟节点重复计算慢启动比例。
+	var checked []*proxyUpstream
+	for i := 0; i < len(b.ring) && len(checked) < len(b.upstreams); i++ {
+		node := b.ring[(start+i)%len(b.ring)]
+		if containsProxyUpstream(checked, node.upstream) {
+			continue
+		}
+		if b.isAvailable(node.upstream, now, strict) {
+			return node.upstream
+		}
+		checked = append(checked, node.upstream)
+	}
+	return nil
+}
+
+func containsProxyUpstream(upstreams []*proxyUpstream, upstream *proxyUpstream) bool {
+	for _, i := range upstreams {
+		if i == upstream {
+			return true
+		}
+	}
+	return false
+}
+
+// getRetryUpstream 方法选择重试和对冲请求的上游，host为上游时使用该上游，
+// 否则轮询选择与current不同的可用上游，没有其他可用上游时使用current。
+func (b *ProxyBalancer) getRetryUpstream(current *proxyUpstream, host string) *proxyUpstream {
+	now := time.Now().UnixNano()
+	b.RLock()
+	defer b.RUnlock()
+	for _, upstream := range b.upstreams {
+		if host != "" && upstream.host == host {
+			return upstream
+		}
+	}
+	for range b.upstreams {
+		upstream := b.getNextUpstream(now, true)
+		if upstream == nil {
+			break
+		}
+		if upstream != current {
+			return upstream
+		}
+	}
+	return current
+}
+
+// getNextUpstream 方法轮询选择一个可用的上游。
+func (b *ProxyBalancer) getNextUpstream(now int64, strict bool) *proxyUpstream {
+	size := uint32(len(b.upstreams))
+	next := atomic.AddUint32(&b.next, 1)
+	for i := uint32(0); i < size; i++ {
+		upstream := b.upstreams[(next+i)%size]
+		if b.isAvailable(upstream, now, strict) {
+			return upstream
+		}
+	}
+	return nil
+}
+
+func (b *ProxyBalancer) buildRing() {
+	ring := make([]proxyNode, 0, len(b.upstreams)*proxyReplicas)
+	for _, upstream := range b.upstreams {
+		for i := 0; i < proxyReplicas; i++ {
+			ring = append(ring, proxyNode{getProxyHash(upstream.target + "#" + strconv.Itoa(i)), upstream})
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool {
+		return ring[i].hash < ring[j].hash
+	})
+	b.ring = ring
+}
+
+func getProxyHash(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32()
+}
+
+// proxyUpstreamTransport 定义负载均衡上游的RoundTripper，记录上游请求结果。
+type proxyUpstreamTransport struct {
+	balancer *ProxyBalancer
+	upstream *proxyUpstream
+}
+
+// proxyUpstreamBody 定义重试上游的响应body，关闭时减少上游的活动请求数量。
+type proxyUpstreamBody struct {
+	io.ReadCloser
+	active *int64
+	closed int32
+}
+
+// Close 方法关闭响应body。
+func (body *proxyUpstreamBody) Close() error {
+	if atomic.CompareAndSwapInt32(&body.closed, 0, 1) {
+		atomic.AddInt64(body.active, -1)
+	}
+	return body.ReadCloser.Close()
+}
@@ -0,0 +1,11 @@
+//go:build !go1.24
+// +build !go1.24
+
+package eudore
+
+import (
+	"net/http"
+)
+
+// proxyTransportH2C 在go1.24以下版本不支持http.Protocols，h2c和grpc上游返回错误。
+var proxyTransportH2C http.RoundTripper
@@ -0,0 +1,18 @@
+//go:build go1.24
+// +build go1.24
+
+package eudore
+
+import (
+	"net/http"
+)
+
+// proxyTransportH2C 定义使用明文http2连接上游的Transport，h2c和grpc上游共享连接池。
+var proxyTransportH2C http.RoundTripper = newProxyTransportH2C()
+
+func newProxyTransportH2C() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Protocols = new(http.Protocols)
+	transport.Protocols.SetUnencryptedHTTP2(true)
+	return transport
+}
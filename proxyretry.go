@@ -0,0 +1,309 @@
+package eudore
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProxyRetry defines the retry and hedging policy of an http.RoundTripper, which can be used by reverse proxies and http.Client.
+//
+// ProxyRetry 定义请求重试和对冲策略，实现http.RoundTripper，可以作为http.Client的Transport，
+// 或者作为NewProxyHandler、NewProxyBalancer的选项。
+//
+// 只重试幂等方法或存在Idempotency-Key Header的请求，请求body需要可以使用GetBody重新读取；
+// 连接错误或响应状态码为502、503、504时重试，每次重试消耗一个预算令牌，每个请求增加Budget个令牌，最多10个，
+// 令牌不足时不重试，避免上游故障时重试放大流量。
+//
+// HedgeDelay大于0时，请求超过HedgeDelay未响应发送对冲请求，使用先返回的成功响应并取消另一个请求，对冲同样消耗预算令牌。
+type ProxyRetry struct {
+	requests  uint64
+	retries   uint64
+	hedges    uint64
+	hedgeWins uint64
+	exhausted uint64
+	// Transport 为发送请求使用的RoundTripper，默认http.DefaultTransport，作为代理选项时使用代理的Transport。
+	Transport http.RoundTripper
+	// Attempts 为最大请求次数，包含首次请求，默认3。
+	Attempts int
+	// Budget 为每个请求增加的重试令牌，默认0.2即重试最多为请求的20%。
+	Budget float64
+	// Backoff 为重试间隔，第n次重试等待n*Backoff，默认25毫秒。
+	Backoff time.Duration
+	// HedgeDelay 为发送对冲请求的延迟，为0时不对冲。
+	HedgeDelay time.Duration
+	// Next 返回第attempt次重试或对冲请求使用的上游host，为空时使用原始host，
+	// ProxyBalancer使用时返回的host为上游时使用该上游，否则选择其他上游。
+	Next   func(r *http.Request, attempt int) string
+	mu     sync.Mutex
+	tokens float64
+	inited bool
+}
+
+// ProxyRetryMetrics 定义重试和对冲指标。
+type ProxyRetryMetrics struct {
+	Requests uint64 `json:"requests"`
+	Retries  uint64 `json:"retries"`
+	Hedges   uint64 `json:"hedges"`
+	// HedgeWins 为对冲请求先于原始请求返回的次数。
+	HedgeWins uint64 `json:"hedgewins"`
+	// Exhausted 为预算令牌不足放弃重试或对冲的次数。
+	Exhausted uint64 `json:"exhausted"`
+}
+
+// proxyRetryTokens 定义重试预算最多保留的令牌数量。
+const proxyRetryTokens = 10
+
+// proxyRetryTransport 定义代理使用的重试RoundTripper，使用代理自身的Transport发送请求。
+type proxyRetryTransport struct {
+	retry     *ProxyRetry
+	transport http.RoundTripper
+	// next 为ProxyBalancer发送第attempt次请求的函数，使用选择上游的Director和Transport。
+	next func(r *http.Request, attempt int) (*http.Response, error)
+}
+
+type proxyRetryResult struct {
+	resp  *http.Response
+	err   error
+	index int
+	hedge bool
+}
+
+// proxyRetryBody 定义响应body，关闭时取消请求的context。
+type proxyRetryBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// RoundTrip 方法实现http.RoundTripper接口。
+func (t *ProxyRetry) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return t.roundTrip(proxyRetryTransport{retry: t, transport: transport}.send, req)
+}
+
+// RoundTrip 方法使用代理的Transport执行重试。
+func (t proxyRetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.retry.roundTrip(t.send, req)
+}
+
+// send 方法发送第attempt次请求，重试和对冲请求重新读取body并使用Next选择上游host。
+func (t proxyRetryTransport) send(req *http.Request, attempt int) (*http.Response, error) {
+	if t.next != nil {
+		return t.next(req, attempt)
+	}
+	if attempt == 0 {
+		return t.transport.RoundTrip(req)
+	}
+	r := newProxyRetryRequest(req)
+	if t.retry.Next != nil {
+		if host := t.retry.Next(req, attempt); host != "" {
+			r.URL.Host = host
+		}
+	}
+	return t.transport.RoundTrip(r)
+}
+
+// Metrics 方法返回重试和对冲指标。
+func (t *ProxyRetry) Metrics() ProxyRetryMetrics {
+	return ProxyRetryMetrics{
+		Requests:  atomic.LoadUint64(&t.requests),
+		Retries:   atomic.LoadUint64(&t.retries),
+		Hedges:    atomic.LoadUint64(&t.hedges),
+		HedgeWins: atomic.LoadUint64(&t.hedgeWins),
+		Exhausted: atomic.LoadUint64(&t.exhausted),
+	}
+}
+
+// proxyRetrySend 定义发送第attempt次请求的函数，attempt为0时发送原始请求。
+type proxyRetrySend func(req *http.Request, attempt int) (*http.Response, error)
+
+func (t *ProxyRetry) roundTrip(send proxyRetrySend, req *http.Request) (*http.Response, error) {
+	atomic.AddUint64(&t.requests, 1)
+	t.deposit()
+	if !isProxyRetryable(req) {
+		return send(req, 0)
+	}
+
+	attempts := t.Attempts
+	if attempts <= 0 {
+		attempts = 3
+	}
+	backoff := t.Backoff
+	if backoff <= 0 {
+		backoff = 25 * time.Millisecond
+	}
+	// attempt为已发送请求次数，对冲请求同样计数，用于Next选择不同上游。
+	attempt := 0
+	for i := 1; ; i++ {
+		resp, err := t.roundTripHedge(send, req, &attempt)
+		if i >= attempts || !isProxyRetryResponse(resp, err) || req.Context().Err() != nil || !t.withdraw() {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		atomic.AddUint64(&t.retries, 1)
+
+		timer := time.NewTimer(time.Duration(i) * backoff)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// roundTripHedge 方法发送请求，超过HedgeDelay未响应时发送对冲请求，返回先完成的成功响应。
+func (t *ProxyRetry) roundTripHedge(send proxyRetrySend, req *http.Request, attempt *int) (*http.Response, error) {
+	*attempt++
+	if t.HedgeDelay <= 0 {
+		return send(req, *attempt-1)
+	}
+
+	ch := make(chan proxyRetryResult, 2)
+	var cancels []context.CancelFunc
+	start := func(n int, hedge bool) {
+		ctx, cancel := context.WithCancel(req.Context())
+		cancels = append(cancels, cancel)
+		index := len(cancels) - 1
+		go func() {
+			resp, err := send(req.WithContext(ctx), n)
+			ch <- proxyRetryResult{resp, err, index, hedge}
+		}()
+	}
+	start(*attempt-1, false)
+	timer := time.NewTimer(t.HedgeDelay)
+	defer timer.Stop()
+
+	pending := 1
+	var last *proxyRetryResult
+	for {
+		select {
+		case <-timer.C:
+			if !t.withdraw() {
+				continue
+			}
+			atomic.AddUint64(&t.hedges, 1)
+			pending++
+			start(*attempt, true)
+			*attempt++
+		case result := <-ch:
+			pending--
+			if last != nil {
+				closeProxyRetryResult(*last, cancels[last.index])
+			}
+			last = &result
+			if pending > 0 && isProxyRetryResponse(result.resp, result.err) {
+				continue
+			}
+			if result.hedge {
+				atomic.AddUint64(&t.hedgeWins, 1)
+			}
+			// 取消并丢弃未完成的请求
+			for i, cancel := range cancels {
+				if i != result.index {
+					cancel()
+				}
+			}
+			for ; pending > 0; pending-- {
+				go func() {
+					result := <-ch
+					closeProxyRetryResult(result, cancels[result.index])
+				}()
+			}
+			if result.err != nil {
+				cancels[result.index]()
+				return nil, result.err
+			}
+			result.resp.Body = proxyRetryBody{result.resp.Body, cancels[result.index]}
+			return result.resp, nil
+		}
+	}
+}
+
+// newProxyRetryRequest 函数创建重试或对冲请求，复制URL并重新读取body。
+func newProxyRetryRequest(req *http.Request) *http.Request {
+	r := req.WithContext(req.Context())
+	u := *req.URL
+	r.URL = &u
+	if req.GetBody != nil {
+		r.Body, _ = req.GetBody()
+	}
+	return r
+}
+
+// deposit 方法为每个请求增加Budget个重试令牌。
+func (t *ProxyRetry) deposit() {
+	budget := t.Budget
+	if budget <= 0 {
+		budget = 0.2
+	}
+	t.mu.Lock()
+	if !t.inited {
+		t.tokens, t.inited = proxyRetryTokens, true
+	}
+	t.tokens += budget
+	if t.tokens > proxyRetryTokens {
+		t.tokens = proxyRetryTokens
+	}
+	t.mu.Unlock()
+}
+
+// withdraw 方法消耗一个重试令牌，令牌不足时返回false。
+func (t *ProxyRetry) withdraw() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tokens < 1 {
+		atomic.AddUint64(&t.exhausted, 1)
+		return false
+	}
+	t.tokens--
+	return true
+}
+
+// Close 方法关闭响应body并取消请求。
+func (body proxyRetryBody) Close() error {
+	err := body.ReadCloser.Close()
+	body.cancel()
+	return err
+}
+
+func closeProxyRetryResult(result proxyRetryResult, cancel context.CancelFunc) {
+	if result.resp != nil {
+		result.resp.Body.Close()
+	}
+	cancel()
+}
+
+// isProxyRetryable 函数返回请求是否可以重试，请求需要是幂等的并且body可以重新读取。
+func isProxyRetryable(req *http.Request) bool {
+	switch req.Method {
+	case MethodGet, MethodHead, MethodOptions, MethodTrace, MethodPut, MethodDelete:
+	default:
+		if req.Header.Get("Idempotency-Key") == "" {
+			return false
+		}
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// isProxyRetryResponse 函数返回请求结果是否需要重试，请求被取消时不重试。
+func isProxyRetryResponse(resp *http.Response, err error) bool {
+	if err != nil {
+		return err != context.Canceled
+	}
+	switch resp.StatusCode {
+	case StatusBadGateway, StatusServiceUnavailable, StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,334 @@
+package eudore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// ProxyTransform defines the request or response body transformation of a reverse proxy.
+//
+// ProxyTransform 定义反向代理请求或响应body转换，Stream流式转换body，Buffer缓冲完整body后转换。
+//
+// Buffer缓冲body最大MaxSize，超过时Passthrough为true原样转发，否则请求返回413、响应返回502；
+// 响应存在Content-Encoding时不转换，代理存在响应转换时不向上游发送Accept-Encoding。
+type ProxyTransform struct {
+	// Name 为转换声明，用于输出错误。
+	Name string
+	// Request 为true时转换请求body，否则转换响应body。
+	Request bool
+	// ContentType 为转换的Content-Type前缀，多个使用逗号分隔，为空时转换全部。
+	ContentType string
+	// MaxSize 为Buffer转换缓冲body的最大长度，默认4MB。
+	MaxSize int64
+	// Passthrough 为true时body超过MaxSize原样转发。
+	Passthrough bool
+	// Stream 为流式转换函数，返回转换后的Reader。
+	Stream func(io.Reader) io.Reader
+	// Buffer 为缓冲转换函数，参数为完整body和请求或响应header。
+	Buffer func([]byte, http.Header) ([]byte, error)
+}
+
+// proxyTransformMaxSize 定义Buffer转换默认缓冲body的最大长度。
+const proxyTransformMaxSize = 4 << 20
+
+// proxyTransformNames 保存名称对应的转换构造函数。
+var proxyTransformNames = map[string]func(string) (*ProxyTransform, error){
+	"json-set": NewProxyTransformJSONSet,
+	"json-del": NewProxyTransformJSONDelete,
+	"replace":  NewProxyTransformReplace,
+	"template": NewProxyTransformTemplate,
+}
+
+// proxyBody 定义转换后的body，关闭时关闭原始body。
+type proxyBody struct {
+	io.Reader
+	io.Closer
+}
+
+// proxyReplaceReader 定义流式替换Reader，保留可能跨越两次读取的匹配前缀。
+type proxyReplaceReader struct {
+	reader io.Reader
+	old    []byte
+	new    []byte
+	buf    []byte
+	out    []byte
+	eof    bool
+}
+
+// RegisterProxyTransform 函数注册一个名称的body转换构造函数，用于NewProxyTransform使用名称创建。
+func RegisterProxyTransform(name string, fn func(string) (*ProxyTransform, error)) {
+	proxyTransformNames[name] = fn
+}
+
+// NewProxyTransform 函数使用"name:arg"格式的字符串创建body转换，名称前缀为request.时转换请求body。
+//
+// 默认存在json-set、json-del、replace、template，例如"json-del:password"、"request.json-set:{\"source\":\"gateway\"}"。
+func NewProxyTransform(str string) (*ProxyTransform, error) {
+	name, arg := str, ""
+	if pos := strings.IndexByte(str, ':'); pos != -1 {
+		name, arg = str[:pos], str[pos+1:]
+	}
+	name = strings.TrimSpace(name)
+	request := strings.HasPrefix(name, "request.")
+	fn, ok := proxyTransformNames[strings.TrimPrefix(name, "request.")]
+	if !ok {
+		return nil, fmt.Errorf("proxy transform %s not registered", name)
+	}
+	t, err := fn(arg)
+	if err != nil {
+		return nil, fmt.Errorf("proxy transform %s create error: %v", str, err)
+	}
+	t.Name = str
+	t.Request = t.Request || request
+	return t, nil
+}
+
+// NewProxyTransformJSONSet 函数创建json对象设置属性的转换，arg为json对象，例如{"source":"gateway"}。
+func NewProxyTransformJSONSet(arg string) (*ProxyTransform, error) {
+	var fields map[string]interface{}
+	err := json.Unmarshal([]byte(arg), &fields)
+	if err != nil {
+		return nil, err
+	}
+	return &ProxyTransform{
+		ContentType: MimeApplicationJSON,
+		Buffer: func(body []byte, _ http.Header) ([]byte, error) {
+			return transformJSONObject(body, func(data map[string]interface{}) {
+				for key, val := range fields {
+					data[key] = val
+				}
+			})
+		},
+	}, nil
+}
+
+// NewProxyTransformJSONDelete 函数创建json对象删除属性的转换，arg为逗号分隔的属性，使用.分隔嵌套属性，例如password,user.token。
+func NewProxyTransformJSONDelete(arg string) (*ProxyTransform, error) {
+	if strings.TrimSpace(arg) == "" {
+		return nil, fmt.Errorf("json-del fields is empty")
+	}
+	var fields [][]string
+	for _, field := range strings.Split(arg, ",") {
+		fields = append(fields, strings.Split(strings.TrimSpace(field), "."))
+	}
+	return &ProxyTransform{
+		ContentType: MimeApplicationJSON,
+		Buffer: func(body []byte, _ http.Header) ([]byte, error) {
+			return transformJSONObject(body, func(data map[string]interface{}) {
+				for _, field := range fields {
+					deleteJSONField(data, field)
+				}
+			})
+		},
+	}, nil
+}
+
+// NewProxyTransformReplace 函数创建流式替换字符串的转换，arg格式为"old new"，用于替换响应中的上游地址。
+func NewProxyTransformReplace(arg string) (*ProxyTransform, error) {
+	strs := strings.Fields(arg)
+	if len(strs) != 2 {
+		return nil, fmt.Errorf("replace arg must be 'old new'")
+	}
+	old, new := []byte(strs[0]), []byte(strs[1])
+	return &ProxyTransform{
+		ContentType: "text/,application/json,application/javascript,application/xml",
+		Stream: func(r io.Reader) io.Reader {
+			return &proxyReplaceReader{reader: r, old: old, new: new}
+		},
+	}, nil
+}
+
+// NewProxyTransformTemplate 函数创建使用text/template重写html的转换，
+// 模板数据Body为响应body，Header为响应header，函数replace使用strings.Replace替换全部。
+func NewProxyTransformTemplate(arg string) (*ProxyTransform, error) {
+	tmpl, err := template.New("proxy").Funcs(template.FuncMap{
+		"replace": func(s, old, new string) string {
+			return strings.Replace(s, old, new, -1)
+		},
+	}).Parse(arg)
+	if err != nil {
+		return nil, err
+	}
+	return &ProxyTransform{
+		ContentType: MimeTextHTML,
+		Buffer: func(body []byte, header http.Header) ([]byte, error) {
+			buf := bytes.NewBuffer(make([]byte, 0, len(body)))
+			err := tmpl.Execute(buf, map[string]interface{}{
+				"Body":   string(body),
+				"Header": header,
+			})
+			return buf.Bytes(), err
+		},
+	}, nil
+}
+
+func transformJSONObject(body []byte, fn func(map[string]interface{})) ([]byte, error) {
+	var data map[string]interface{}
+	err := json.Unmarshal(body, &data)
+	if err != nil {
+		return nil, err
+	}
+	fn(data)
+	return json.Marshal(data)
+}
+
+func deleteJSONField(data map[string]interface{}, field []string) {
+	for len(field) > 1 {
+		next, ok := data[field[0]].(map[string]interface{})
+		if !ok {
+			return
+		}
+		data, field = next, field[1:]
+	}
+	delete(data, field[0])
+}
+
+// match 方法返回header是否需要转换，存在Content-Encoding时不转换。
+func (t *ProxyTransform) match(header http.Header) bool {
+	encoding := header.Get(HeaderContentEncoding)
+	if encoding != "" && encoding != "identity" {
+		return false
+	}
+	if t.ContentType == "" {
+		return true
+	}
+	mediatype, _, _ := mime.ParseMediaType(header.Get(HeaderContentType))
+	for _, prefix := range strings.Split(t.ContentType, ",") {
+		if prefix != "" && strings.HasPrefix(mediatype, strings.TrimSpace(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// transform 方法转换body，返回新body和长度，流式转换长度为-1。
+//
+// 超过MaxSize时返回的错误实现StatusCode方法。
+func (t *ProxyTransform) transform(body io.ReadCloser, header http.Header) (io.ReadCloser, int64, error) {
+	if body == nil || body == http.NoBody {
+		body = ioutil.NopCloser(bytes.NewReader(nil))
+	}
+	if t.Stream != nil {
+		return proxyBody{t.Stream(body), body}, -1, nil
+	}
+
+	size := t.MaxSize
+	if size <= 0 {
+		size = proxyTransformMaxSize
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(body, size+1))
+	if err != nil {
+		body.Close()
+		return nil, 0, err
+	}
+	if int64(len(data)) > size {
+		if t.Passthrough {
+			return proxyBody{io.MultiReader(bytes.NewReader(data), body), body}, -1, nil
+		}
+		body.Close()
+		return nil, 0, proxyTransformError{fmt.Errorf("proxy transform %s body size exceeds %d", t.Name, size)}
+	}
+	body.Close()
+	data, err = t.Buffer(data, header)
+	if err != nil {
+		return nil, 0, fmt.Errorf("proxy transform %s error: %v", t.Name, err)
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+}
+
+// proxyTransformError 定义body超过缓冲长度的错误。
+type proxyTransformError struct {
+	error
+}
+
+// transformProxyRequest 函数转换请求body，失败时返回响应状态码。
+func transformProxyRequest(r *http.Request, transforms []*ProxyTransform) (int, error) {
+	for _, t := range transforms {
+		if !t.match(r.Header) {
+			continue
+		}
+		body, length, err := t.transform(r.Body, r.Header)
+		if err != nil {
+			if _, ok := err.(proxyTransformError); ok {
+				return StatusRequestEntityTooLarge, err
+			}
+			return StatusBadRequest, err
+		}
+		r.Body, r.ContentLength = body, length
+		setProxyContentLength(r.Header, length)
+	}
+	return 0, nil
+}
+
+// transformProxyResponse 函数转换响应body，失败时代理返回502。
+func transformProxyResponse(resp *http.Response, transforms []*ProxyTransform) error {
+	for _, t := range transforms {
+		if !t.match(resp.Header) {
+			continue
+		}
+		body, length, err := t.transform(resp.Body, resp.Header)
+		if err != nil {
+			return err
+		}
+		resp.Body, resp.ContentLength = body, length
+		setProxyContentLength(resp.Header, length)
+	}
+	return nil
+}
+
+func setProxyContentLength(header http.Header, length int64) {
+	if length < 0 {
+		header.Del(HeaderContentLength)
+	} else {
+		header.Set(HeaderContentLength, strconv.FormatInt(length, 10))
+	}
+}
+
+// Read 方法读取替换后的数据，未读取到EOF时保留len(old)-1字节等待下次匹配。
+func (r *proxyReplaceReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		buf := make([]byte, 4096)
+		n, err := r.reader.Read(buf)
+		r.buf = append(r.buf, buf[:n]...)
+		if err == io.EOF {
+			r.eof = true
+		} else if err != nil {
+			return 0, err
+		}
+
+		var out []byte
+		i := 0
+		for {
+			pos := bytes.Index(r.buf[i:], r.old)
+			if pos == -1 {
+				break
+			}
+			out = append(out, r.buf[i:i+pos]...)
+			out = append(out, r.new...)
+			i += pos + len(r.old)
+		}
+		end := len(r.buf)
+		if !r.eof {
+			end -= len(r.old) - 1
+		}
+		if end < i {
+			end = i
+		}
+		r.out = append(out, r.buf[i:end]...)
+		r.buf = append(r.buf[:0:0], r.buf[end:]...)
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
@@ -1,17 +1,46 @@
 package eudore
 
 import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"html/template"
+	"io"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Renderer 接口定义根据请求接受的数据类型来序列化数据。
 type Renderer func(Context, interface{}) error
 
+// JSONAppender 定义可以将自身json编码追加到[]byte的对象，通常由代码生成工具为简单结构体生成，
+// RenderJSON使用池化缓冲调用AppendJSON，跳过encoding/json的反射编码，可以使用AppendJSONString编码字符串。
+type JSONAppender interface {
+	AppendJSON([]byte) []byte
+}
+
+var (
+	// 预先创建header值，避免http.Header.Set分配内存。
+	headerValueText = []string{MimeTextPlainCharsetUtf8}
+	headerValueJSON = []string{MimeApplicationJSONUtf8}
+	renderBuffers   = sync.Pool{
+		New: func() interface{} {
+			buf := make([]byte, 0, 512)
+			return &buf
+		},
+	}
+)
+
 // RenderDefault 函数是默认Render，更加Accent Header选择Json、Xml、Text三种Render。
 func RenderDefault(ctx Context, data interface{}) error {
 	for _, accept := range strings.Split(ctx.GetHeader(HeaderAccept), ",") {
@@ -30,23 +59,142 @@ func RenderDefault(ctx Context, data interface{}) error {
 	return RenderText(ctx, data)
 }
 
+// NewRenderAccept 函数创建根据Accept header选择Renderer的Renderer，renders的key为mime类型，用于注册和替换各类型的Renderer。
+//
+// 未匹配Accept时使用text/plain对应的Renderer，不存在时使用RenderText，例如：
+//
+//	app.Renderer = eudore.NewRenderAccept(map[string]eudore.Renderer{
+//		eudore.MimeApplicationJSON: eudore.NewRenderJSON(&eudore.RenderJSONConfig{EmptyArray: true}),
+//		eudore.MimeApplicationXML:  eudore.RenderXML,
+//		eudore.MimeTextPlain:       eudore.RenderText,
+//	})
+func NewRenderAccept(renders map[string]Renderer) Renderer {
+	defaultRender := renders[MimeTextPlain]
+	if defaultRender == nil {
+		defaultRender = RenderText
+	}
+	return func(ctx Context, data interface{}) error {
+		for _, accept := range strings.Split(ctx.GetHeader(HeaderAccept), ",") {
+			accept = strings.TrimSpace(strings.SplitN(accept, ";", 2)[0])
+			if r, ok := renders[accept]; ok {
+				return r(ctx, data)
+			}
+		}
+		return defaultRender(ctx, data)
+	}
+}
+
 // RenderText 函数Render Text，使用fmt.Fprint函数写入。
 func RenderText(ctx Context, data interface{}) error {
 	header := ctx.Response().Header()
 	if val := header.Get(HeaderContentType); len(val) == 0 {
-		header.Add(HeaderContentType, MimeTextPlainCharsetUtf8)
+		header[HeaderContentType] = headerValueText
 	}
 	_, err := fmt.Fprintf(ctx, "%#v", data)
 	return err
 }
 
 // RenderJSON 函数Render Json，使用encoding/json库实现json反序列化。
+//
+// 不超过32个key的map[string]string和实现JSONAppender接口的对象使用池化缓冲直接编码，输出与encoding/json相同。
 func RenderJSON(ctx Context, data interface{}) error {
 	header := ctx.Response().Header()
 	if val := header.Get(HeaderContentType); len(val) == 0 {
-		header.Add(HeaderContentType, MimeApplicationJSONUtf8)
+		header[HeaderContentType] = headerValueJSON
+	}
+	buf := renderBuffers.Get().(*[]byte)
+	switch val := data.(type) {
+	case map[string]string:
+		// nil和大map使用encoding/json处理。
+		if val == nil || len(val) > 32 {
+			renderBuffers.Put(buf)
+			return json.NewEncoder(ctx).Encode(data)
+		}
+		*buf = appendJSONMapString((*buf)[:0], val)
+	case JSONAppender:
+		*buf = val.AppendJSON((*buf)[:0])
+	default:
+		renderBuffers.Put(buf)
+		return json.NewEncoder(ctx).Encode(data)
+	}
+	*buf = append(*buf, '\n')
+	_, err := ctx.Write(*buf)
+	renderBuffers.Put(buf)
+	return err
+}
+
+// AppendJSONString 函数将字符串json编码追加到dst，与encoding/json相同转义html字符和无效utf8。
+func AppendJSONString(dst []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				dst = append(dst, '\\', b)
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		c, size := utf8.DecodeRuneInString(s[i:])
+		if c == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if c == '\u2028' || c == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hex[c&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
 	}
-	return json.NewEncoder(ctx).Encode(data)
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
+
+// appendJSONMapString 函数按key排序编码map[string]string，与encoding/json结果相同。
+func appendJSONMapString(dst []byte, data map[string]string) []byte {
+	var arr [16]string
+	keys := arr[:0]
+	for k := range data {
+		keys = append(keys, k)
+	}
+	// 插入排序避免sort.Strings转换接口的内存分配。
+	for i := 1; i < len(keys); i++ {
+		for j := i; j > 0 && keys[j] < keys[j-1]; j-- {
+			keys[j], keys[j-1] = keys[j-1], keys[j]
+		}
+	}
+	dst = append(dst, '{')
+	for i, k := range keys {
+		if i != 0 {
+			dst = append(dst, ',')
+		}
+		dst = AppendJSONString(dst, k)
+		dst = append(dst, ':')
+		dst = AppendJSONString(dst, data[k])
+	}
+	return append(dst, '}')
 }
 
 // RenderIndentJSON 函数Render Indent Json，使用encoding/json库实现json反序列化。
@@ -60,15 +208,368 @@ func RenderIndentJSON(ctx Context, data interface{}) error {
 	return en.Encode(data)
 }
 
-// RenderXML 函数Render Xml，使用encoding/xml库实现xml反序列化。
+// RenderJSONConfig 定义NewRenderJSON的默认选项，路由参数render可以使用逗号分隔的pretty、noescape、emptyarray选项启用对应选项。
+//
+// Indent 缩进字符串，非空时使用缩进格式化。
+//
+// PrettyQuery 请求参数名称，请求参数值为1或true时使用缩进格式化，缩进字符串为空时使用制表符。
+//
+// NoEscapeHTML 不转义html字符<>&。
+//
+// EmptyArray 空切片输出[]代替null。
+type RenderJSONConfig struct {
+	Indent       string `alias:"indent" json:"indent"`
+	PrettyQuery  string `alias:"prettyquery" json:"prettyquery"`
+	NoEscapeHTML bool   `alias:"noescapehtml" json:"noescapehtml"`
+	EmptyArray   bool   `alias:"emptyarray" json:"emptyarray"`
+}
+
+// NewRenderJSON 函数使用选项创建json Renderer，选项可以使用RenderJSONConfig全局设置、路由参数render按路由设置和请求参数按请求设置。
+//
+// 例如注册路由"/users render=pretty,emptyarray"，或请求"/users?pretty=1"。
+func NewRenderJSON(config *RenderJSONConfig) Renderer {
+	if config == nil {
+		config = &RenderJSONConfig{}
+	}
+	return func(ctx Context, data interface{}) error {
+		indent, noescape, emptyarray := config.Indent, config.NoEscapeHTML, config.EmptyArray
+		for _, option := range strings.Split(ctx.GetParam(ParamRender), ",") {
+			switch strings.TrimSpace(option) {
+			case "pretty":
+				indent = "\t"
+			case "noescape":
+				noescape = true
+			case "emptyarray":
+				emptyarray = true
+			}
+		}
+		if config.PrettyQuery != "" && indent == "" {
+			switch ctx.GetQuery(config.PrettyQuery) {
+			case "1", "true":
+				indent = "\t"
+			}
+		}
+		if indent == "" && !noescape && !emptyarray {
+			return RenderJSON(ctx, data)
+		}
+
+		header := ctx.Response().Header()
+		if val := header.Get(HeaderContentType); len(val) == 0 {
+			header[HeaderContentType] = headerValueJSON
+		}
+		if emptyarray && data != nil {
+			data = renderEmptyArray(reflect.ValueOf(data)).Interface()
+		}
+		en := json.NewEncoder(ctx)
+		en.SetIndent("", indent)
+		en.SetEscapeHTML(!noescape)
+		return en.Encode(data)
+	}
+}
+
+// NewRenderJSONP 函数创建JSONP Renderer，请求参数param非空时使用参数值作为回调函数名称输出javascript，否则使用r渲染。
+//
+// 回调函数名称需要是使用.分隔的javascript标识符且不超过128字节，否则响应400并返回错误，错误响应使用r渲染；
+// 输出前添加注释/**/并设置X-Content-Type-Options: nosniff，避免内容嗅探攻击。
+func NewRenderJSONP(r Renderer, param string) Renderer {
+	if param == "" {
+		param = "callback"
+	}
+	return func(ctx Context, data interface{}) error {
+		callback := ctx.GetQuery(param)
+		if callback == "" {
+			return r(ctx, data)
+		}
+		if !checkJSONPCallback(callback) {
+			// 渲染错误响应时忽略回调函数
+			if ctx.Response().Status() >= 400 {
+				return r(ctx, data)
+			}
+			if ctx.Response().Size() == 0 {
+				ctx.WriteHeader(StatusBadRequest)
+			}
+			return fmt.Errorf(ErrFormatRenderJSONPCallback, callback)
+		}
+		body, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		header := ctx.Response().Header()
+		header.Set(HeaderContentType, MimeTextJavascriptUtf8)
+		header.Set(HeaderXContentTypeOptions, "nosniff")
+		buf := renderBuffers.Get().(*[]byte)
+		*buf = append((*buf)[:0], "/**/"...)
+		*buf = append(*buf, callback...)
+		*buf = append(*buf, '(')
+		*buf = append(*buf, body...)
+		*buf = append(*buf, ");"...)
+		_, err = ctx.Write(*buf)
+		renderBuffers.Put(buf)
+		return err
+	}
+}
+
+// checkJSONPCallback 函数检查回调函数名称是否为使用.分隔的javascript标识符。
+func checkJSONPCallback(callback string) bool {
+	if len(callback) > 128 {
+		return false
+	}
+	for _, name := range strings.Split(callback, ".") {
+		if name == "" {
+			return false
+		}
+		for i, c := range name {
+			switch {
+			case c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			case i > 0 && c >= '0' && c <= '9':
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+var renderEmptyArrayTypes sync.Map
+
+// renderEmptyArray 函数复制数据并将nil切片替换为空切片，不修改原数据，不包含切片的类型直接返回。
+func renderEmptyArray(iValue reflect.Value) reflect.Value {
+	if !iValue.IsValid() || !hasRenderEmptyArray(iValue.Type(), nil) {
+		return iValue
+	}
+	switch iValue.Kind() {
+	case reflect.Ptr:
+		if iValue.IsNil() {
+			return iValue
+		}
+		newValue := reflect.New(iValue.Type().Elem())
+		newValue.Elem().Set(renderEmptyArray(iValue.Elem()))
+		return newValue
+	case reflect.Interface:
+		if iValue.IsNil() {
+			return iValue
+		}
+		newValue := reflect.New(iValue.Type()).Elem()
+		newValue.Set(renderEmptyArray(iValue.Elem()))
+		return newValue
+	case reflect.Slice:
+		if iValue.IsNil() {
+			return reflect.MakeSlice(iValue.Type(), 0, 0)
+		}
+		if !hasRenderEmptyArray(iValue.Type().Elem(), nil) {
+			return iValue
+		}
+		newValue := reflect.MakeSlice(iValue.Type(), iValue.Len(), iValue.Len())
+		for i := 0; i < iValue.Len(); i++ {
+			newValue.Index(i).Set(renderEmptyArray(iValue.Index(i)))
+		}
+		return newValue
+	case reflect.Array:
+		newValue := reflect.New(iValue.Type()).Elem()
+		for i := 0; i < iValue.Len(); i++ {
+			newValue.Index(i).Set(renderEmptyArray(iValue.Index(i)))
+		}
+		return newValue
+	case reflect.Map:
+		if iValue.IsNil() {
+			return iValue
+		}
+		newValue := reflect.MakeMap(iValue.Type())
+		for _, key := range iValue.MapKeys() {
+			newValue.SetMapIndex(key, renderEmptyArray(iValue.MapIndex(key)))
+		}
+		return newValue
+	case reflect.Struct:
+		newValue := reflect.New(iValue.Type()).Elem()
+		newValue.Set(iValue)
+		for i := 0; i < newValue.NumField(); i++ {
+			field := newValue.Field(i)
+			if field.CanSet() {
+				field.Set(renderEmptyArray(field))
+			}
+		}
+		return newValue
+	}
+	return iValue
+}
+
+// hasRenderEmptyArray 函数检查类型是否可能包含切片，[]byte和实现json.Marshaler的类型除外，仅缓存顶层调用的结果。
+func hasRenderEmptyArray(iType reflect.Type, visited map[reflect.Type]bool) bool {
+	if has, ok := renderEmptyArrayTypes.Load(iType); ok {
+		return has.(bool)
+	}
+	if visited[iType] || iType.Implements(typeJSONMarshaler) || reflect.PtrTo(iType).Implements(typeJSONMarshaler) {
+		return false
+	}
+	top := visited == nil
+	if top {
+		visited = make(map[reflect.Type]bool)
+	}
+	visited[iType] = true
+
+	var has bool
+	switch iType.Kind() {
+	case reflect.Interface:
+		has = true
+	case reflect.Slice:
+		has = iType.Elem().Kind() != reflect.Uint8
+	case reflect.Ptr, reflect.Array, reflect.Map:
+		has = hasRenderEmptyArray(iType.Elem(), visited)
+	case reflect.Struct:
+		for i := 0; i < iType.NumField() && !has; i++ {
+			field := iType.Field(i)
+			has = (field.PkgPath == "" || field.Anonymous) && hasRenderEmptyArray(field.Type, visited)
+		}
+	}
+	if top {
+		renderEmptyArrayTypes.Store(iType, has)
+	}
+	return has
+}
+
+// RenderXML 函数Render Xml，结构体使用encoding/xml库实现xml序列化。
+//
+// map[string]interface{}使用与BindXML相同的格式，只有一个key时key为根元素，否则使用xml作为根元素；
+// @开头的key为属性，#text为文本，#cdata为CDATA文本，元素名称可以包含命名空间前缀，重复元素使用切片。
 func RenderXML(ctx Context, data interface{}) error {
 	header := ctx.Response().Header()
 	if val := header.Get(HeaderContentType); len(val) == 0 {
 		header.Add(HeaderContentType, MimeApplicationxmlCharsetUtf8)
 	}
+	if val, ok := data.(map[string]interface{}); ok {
+		return renderXMLMap(ctx, val)
+	}
 	return xml.NewEncoder(ctx).Encode(data)
 }
 
+func renderXMLMap(w io.Writer, data map[string]interface{}) error {
+	buf := &bytes.Buffer{}
+	var err error
+	if len(data) == 1 {
+		for key, val := range data {
+			if key[0] != '@' && key[0] != '#' {
+				err = renderXMLElement(buf, key, val)
+			} else {
+				err = renderXMLElement(buf, "xml", data)
+			}
+		}
+	} else {
+		err = renderXMLElement(buf, "xml", data)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func renderXMLElement(buf *bytes.Buffer, name string, data interface{}) error {
+	if !checkXMLName(name) {
+		return fmt.Errorf(ErrFormatRenderXMLName, name)
+	}
+	iValue := reflect.ValueOf(data)
+	for iValue.Kind() == reflect.Ptr || iValue.Kind() == reflect.Interface {
+		iValue = iValue.Elem()
+	}
+	switch iValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		if iValue.Type().Elem().Kind() != reflect.Uint8 {
+			for i := 0; i < iValue.Len(); i++ {
+				if err := renderXMLElement(buf, name, iValue.Index(i).Interface()); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case reflect.Map:
+		if iValue.Type().Key().Kind() == reflect.String {
+			return renderXMLNode(buf, name, iValue)
+		}
+	}
+
+	buf.WriteString("<" + name + ">")
+	if iValue.IsValid() {
+		xml.EscapeText(buf, []byte(getRenderText(iValue.Interface())))
+	}
+	buf.WriteString("</" + name + ">")
+	return nil
+}
+
+// renderXMLNode 函数按属性、文本、子元素顺序输出map，key排序保证输出稳定。
+func renderXMLNode(buf *bytes.Buffer, name string, iValue reflect.Value) error {
+	keys := make([]string, 0, iValue.Len())
+	for _, key := range iValue.MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+
+	buf.WriteString("<" + name)
+	for _, key := range keys {
+		if key != "" && key[0] == '@' {
+			if !checkXMLName(key[1:]) {
+				return fmt.Errorf(ErrFormatRenderXMLName, key)
+			}
+			buf.WriteString(" " + key[1:] + "=\"")
+			xml.EscapeText(buf, []byte(getRenderText(iValue.MapIndex(reflect.ValueOf(key)).Interface())))
+			buf.WriteByte('"')
+		}
+	}
+	buf.WriteByte('>')
+	for _, key := range keys {
+		val := iValue.MapIndex(reflect.ValueOf(key)).Interface()
+		switch {
+		case key == "#text":
+			xml.EscapeText(buf, []byte(getRenderText(val)))
+		case key == "#cdata":
+			buf.WriteString("<![CDATA[")
+			buf.WriteString(strings.Replace(getRenderText(val), "]]>", "]]]]><![CDATA[>", -1))
+			buf.WriteString("]]>")
+		case key != "" && key[0] == '@':
+		default:
+			if err := renderXMLElement(buf, key, val); err != nil {
+				return err
+			}
+		}
+	}
+	buf.WriteString("</" + name + ">")
+	return nil
+}
+
+// getRenderText 函数将数据格式化为文本，实现encoding.TextMarshaler的对象使用MarshalText。
+func getRenderText(data interface{}) string {
+	switch val := data.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case encoding.TextMarshaler:
+		text, err := val.MarshalText()
+		if err != nil {
+			return err.Error()
+		}
+		return string(text)
+	}
+	return fmt.Sprint(data)
+}
+
+// checkXMLName 函数检查名称是否为合法的xml名称，允许命名空间前缀。
+func checkXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_' || c == ':' || unicode.IsLetter(c):
+		case i > 0 && (c == '-' || c == '.' || unicode.IsDigit(c)):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // NewRenderHTML 函数使用模板创建一个模板Renderer
 func NewRenderHTML(temp *template.Template) Renderer {
 	if temp == nil {
@@ -102,3 +603,470 @@ func NewRenderHTMLWithTemplate(r Renderer, temp *template.Template) Renderer {
 		return r(ctx, data)
 	}
 }
+
+// NewRenderFilter 函数创建按调用者角色过滤结构体字段的Renderer，渲染时将包含render tag的结构体转换为map后使用r渲染。
+//
+// 字段tag render指定允许访问的角色，多个角色使用|分隔，例如`render:"admin|ops"`；调用者没有任一角色时省略字段，
+// 追加mask选项时字符串字段使用DefaultRenderFilterMask代替，其他类型字段为null，例如`render:"admin,mask"`。
+//
+// roles函数返回调用者的角色或scope，为空时使用路由参数ROLE的逗号分隔值；
+// map的key使用json tag名称，支持omitempty和匿名结构体展开，不包含render tag的数据直接使用r渲染。
+func NewRenderFilter(r Renderer, roles func(Context) []string) Renderer {
+	if roles == nil {
+		roles = func(ctx Context) []string {
+			return strings.Split(ctx.GetParam(ParamRole), ",")
+		}
+	}
+	return func(ctx Context, data interface{}) error {
+		if data != nil && hasRenderFilter(reflect.TypeOf(data), nil) {
+			data = renderFilterValue(reflect.ValueOf(data), roles(ctx))
+		}
+		return r(ctx, data)
+	}
+}
+
+// renderFilterField 定义结构体字段的渲染信息。
+type renderFilterField struct {
+	Index     int
+	Name      string
+	OmitEmpty bool
+	Embedded  bool
+	Roles     []string
+	Mask      bool
+}
+
+var (
+	renderFilterTypes  sync.Map
+	renderFilterFields sync.Map
+)
+
+// hasRenderFilter 函数检查类型是否包含render tag，递归类型使用visited避免循环，仅缓存顶层调用的结果。
+//
+// 接口类型在渲染时才能确定动态类型，总是返回true，由renderFilterValue检查动态值。
+func hasRenderFilter(iType reflect.Type, visited map[reflect.Type]bool) bool {
+	if has, ok := renderFilterTypes.Load(iType); ok {
+		return has.(bool)
+	}
+	if visited[iType] || iType.Implements(typeJSONMarshaler) || reflect.PtrTo(iType).Implements(typeJSONMarshaler) {
+		return false
+	}
+	top := visited == nil
+	if top {
+		visited = make(map[reflect.Type]bool)
+	}
+	visited[iType] = true
+
+	var has bool
+	switch iType.Kind() {
+	case reflect.Interface:
+		has = true
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		has = hasRenderFilter(iType.Elem(), visited)
+	case reflect.Struct:
+		for _, field := range getRenderFilterFields(iType) {
+			if len(field.Roles) > 0 || hasRenderFilter(iType.Field(field.Index).Type, visited) {
+				has = true
+				break
+			}
+		}
+	}
+	if top {
+		renderFilterTypes.Store(iType, has)
+	}
+	return has
+}
+
+// getRenderFilterFields 函数解析结构体可导出字段的json名称和render tag。
+func getRenderFilterFields(iType reflect.Type) []renderFilterField {
+	if fields, ok := renderFilterFields.Load(iType); ok {
+		return fields.([]renderFilterField)
+	}
+	var fields []renderFilterField
+	for i := 0; i < iType.NumField(); i++ {
+		field := iType.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		tags := strings.Split(field.Tag.Get("json"), ",")
+		if tags[0] == "-" {
+			continue
+		}
+		info := renderFilterField{Index: i, Name: tags[0]}
+		for _, tag := range tags[1:] {
+			info.OmitEmpty = info.OmitEmpty || tag == "omitempty"
+		}
+		if info.Name == "" {
+			info.Name = field.Name
+			info.Embedded = field.Anonymous && reflect.Indirect(reflect.New(field.Type)).Kind() == reflect.Struct
+		}
+		// 未导出的匿名字段只展开结构体
+		if field.PkgPath != "" && !info.Embedded {
+			continue
+		}
+		if render := field.Tag.Get("render"); render != "" {
+			tags = strings.Split(render, ",")
+			info.Roles = strings.Split(tags[0], "|")
+			for _, tag := range tags[1:] {
+				info.Mask = info.Mask || tag == "mask"
+			}
+		}
+		fields = append(fields, info)
+	}
+	renderFilterFields.Store(iType, fields)
+	return fields
+}
+
+// renderFilterValue 函数返回按角色过滤后的数据，包含render tag的结构体转换为map。
+func renderFilterValue(iValue reflect.Value, roles []string) interface{} {
+	switch iValue.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Ptr, reflect.Interface:
+		if iValue.IsNil() {
+			return nil
+		}
+		return renderFilterValue(iValue.Elem(), roles)
+	}
+	if !hasRenderFilter(iValue.Type(), nil) {
+		return iValue.Interface()
+	}
+	switch iValue.Kind() {
+	case reflect.Struct:
+		data := make(map[string]interface{})
+		renderFilterStruct(iValue, roles, data)
+		return data
+	case reflect.Slice, reflect.Array:
+		if iValue.Kind() == reflect.Slice && iValue.IsNil() {
+			return nil
+		}
+		data := make([]interface{}, iValue.Len())
+		for i := range data {
+			data[i] = renderFilterValue(iValue.Index(i), roles)
+		}
+		return data
+	case reflect.Map:
+		if iValue.IsNil() {
+			return nil
+		}
+		data := make(map[string]interface{}, iValue.Len())
+		for _, key := range iValue.MapKeys() {
+			data[fmt.Sprint(key.Interface())] = renderFilterValue(iValue.MapIndex(key), roles)
+		}
+		return data
+	}
+	return iValue.Interface()
+}
+
+func renderFilterStruct(iValue reflect.Value, roles []string, data map[string]interface{}) {
+	for _, field := range getRenderFilterFields(iValue.Type()) {
+		fieldValue := iValue.Field(field.Index)
+		if field.Embedded && len(field.Roles) == 0 {
+			fieldValue = reflect.Indirect(fieldValue)
+			if fieldValue.IsValid() {
+				renderFilterStruct(fieldValue, roles, data)
+			}
+			continue
+		}
+		if field.OmitEmpty && isRenderFilterEmpty(fieldValue) {
+			continue
+		}
+		switch {
+		case len(field.Roles) == 0 || hasRenderFilterRole(field.Roles, roles):
+			data[field.Name] = renderFilterValue(fieldValue, roles)
+		case field.Mask && fieldValue.Kind() == reflect.String:
+			data[field.Name] = DefaultRenderFilterMask
+		case field.Mask:
+			data[field.Name] = nil
+		}
+	}
+}
+
+// isRenderFilterEmpty 函数与encoding/json相同判断omitempty字段是否为空。
+func isRenderFilterEmpty(iValue reflect.Value) bool {
+	switch iValue.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return iValue.Len() == 0
+	case reflect.Interface, reflect.Ptr:
+		return iValue.IsNil()
+	case reflect.Struct:
+		return false
+	}
+	return checkValueIsZero(iValue)
+}
+
+func hasRenderFilterRole(allows, roles []string) bool {
+	for _, allow := range allows {
+		for _, role := range roles {
+			if allow == strings.TrimSpace(role) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewRenderExport 函数创建导出报表的Renderer，请求路径后缀为.csv或Accept为text/csv时使用RenderCSV，
+// 路径后缀为.xlsx或Accept为xlsx类型时使用RenderXLSX，否则使用r渲染。
+//
+// 可以注册"/report"和"/report.csv"两个路由使用同一个处理函数，分别返回json和csv。
+func NewRenderExport(r Renderer) Renderer {
+	return func(ctx Context, data interface{}) error {
+		switch strings.ToLower(filepath.Ext(ctx.Path())) {
+		case ".csv":
+			return RenderCSV(ctx, data)
+		case ".xlsx":
+			return RenderXLSX(ctx, data)
+		}
+		for _, accept := range strings.Split(ctx.GetHeader(HeaderAccept), ",") {
+			switch strings.TrimSpace(strings.SplitN(accept, ";", 2)[0]) {
+			case MimeTextCSV:
+				return RenderCSV(ctx, data)
+			case MimeApplicationXLSX:
+				return RenderXLSX(ctx, data)
+			}
+		}
+		return r(ctx, data)
+	}
+}
+
+// RenderCSV 函数Render csv，数据为切片、数组或chan，逐行写入响应。
+//
+// 元素为结构体时第一行为字段名称，使用tag csv、json名称或字段名称，tag为-时忽略字段，匿名结构体字段展开；
+// 元素为map时第一行为第一个元素排序后的key；元素为切片时没有标题行；chan在关闭或请求结束时停止读取。
+func RenderCSV(ctx Context, data interface{}) error {
+	header := ctx.Response().Header()
+	if val := header.Get(HeaderContentType); len(val) == 0 {
+		header.Add(HeaderContentType, MimeTextCSVUtf8)
+	}
+	w := csv.NewWriter(ctx)
+	err := renderTable(ctx, data, func(row []interface{}) error {
+		record := make([]string, len(row))
+		for i := range row {
+			record[i] = getRenderText(row[i])
+		}
+		return w.Write(record)
+	})
+	w.Flush()
+	if err != nil {
+		return err
+	}
+	return w.Error()
+}
+
+// RenderXLSX 函数Render xlsx，数据格式与RenderCSV相同，生成只有一个工作表的xlsx文件，逐行写入响应。
+//
+// 数字和bool类型使用对应的单元格类型，其他类型使用字符串。
+func RenderXLSX(ctx Context, data interface{}) error {
+	header := ctx.Response().Header()
+	if val := header.Get(HeaderContentType); len(val) == 0 {
+		header.Add(HeaderContentType, MimeApplicationXLSX)
+	}
+	w := zip.NewWriter(ctx)
+	for _, file := range renderXLSXFiles {
+		f, err := w.Create(file[0])
+		if err != nil {
+			return err
+		}
+		io.WriteString(f, xml.Header+file[1])
+	}
+	f, err := w.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	sheet := bufio.NewWriter(f)
+	sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	err = renderTable(ctx, data, func(row []interface{}) error {
+		sheet.WriteString("<row>")
+		for _, val := range row {
+			renderXLSXCell(sheet, val)
+		}
+		_, err := sheet.WriteString("</row>")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	sheet.WriteString("</sheetData></worksheet>")
+	if err = sheet.Flush(); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+var renderXLSXFiles = [][2]string{
+	{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+func renderXLSXCell(w *bufio.Writer, data interface{}) {
+	iValue := reflect.ValueOf(data)
+	for iValue.Kind() == reflect.Ptr || iValue.Kind() == reflect.Interface {
+		iValue = iValue.Elem()
+	}
+	switch iValue.Kind() {
+	case reflect.Invalid:
+		w.WriteString("<c/>")
+		return
+	case reflect.Bool:
+		if iValue.Bool() {
+			w.WriteString(`<c t="b"><v>1</v></c>`)
+		} else {
+			w.WriteString(`<c t="b"><v>0</v></c>`)
+		}
+		return
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		// time.Duration等实现TextMarshaler或Stringer的数字类型使用字符串
+		if _, ok := data.(encoding.TextMarshaler); !ok {
+			if _, ok := data.(fmt.Stringer); !ok {
+				w.WriteString(`<c><v>` + fmt.Sprint(iValue.Interface()) + `</v></c>`)
+				return
+			}
+		}
+	}
+	w.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+	xml.EscapeText(w, []byte(getRenderText(iValue.Interface())))
+	w.WriteString(`</t></is></c>`)
+}
+
+// renderTableField 定义结构体字段的表格列信息。
+type renderTableField struct {
+	Name  string
+	Index []int
+}
+
+// renderTable 函数遍历切片、数组或chan，结构体和map元素先调用一次fn写入标题行。
+func renderTable(ctx Context, data interface{}, fn func([]interface{}) error) error {
+	iValue := reflect.ValueOf(data)
+	for iValue.Kind() == reflect.Ptr || iValue.Kind() == reflect.Interface {
+		iValue = iValue.Elem()
+	}
+	var next func() (reflect.Value, bool)
+	switch iValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		var i int
+		next = func() (reflect.Value, bool) {
+			if i >= iValue.Len() {
+				return reflect.Value{}, false
+			}
+			i++
+			return iValue.Index(i - 1), true
+		}
+	case reflect.Chan:
+		cases := []reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: iValue},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.GetContext().Done())},
+		}
+		next = func() (reflect.Value, bool) {
+			chosen, val, ok := reflect.Select(cases)
+			return val, chosen == 0 && ok
+		}
+	default:
+		return fmt.Errorf(ErrFormatRenderTableType, iValue.Type())
+	}
+
+	elemType := iValue.Type().Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	var fields []renderTableField
+	var keys []reflect.Value
+	var isMap, hasKeys bool
+	switch elemType.Kind() {
+	case reflect.Struct:
+		fields = getRenderTableFields(elemType, nil)
+		row := make([]interface{}, len(fields))
+		for i := range fields {
+			row[i] = fields[i].Name
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	case reflect.Map:
+		isMap = true
+	}
+
+	for val, ok := next(); ok; val, ok = next() {
+		for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+			val = val.Elem()
+		}
+		var row []interface{}
+		switch {
+		case fields != nil:
+			row = make([]interface{}, len(fields))
+			for i := 0; i < len(fields) && val.IsValid(); i++ {
+				row[i] = val.FieldByIndex(fields[i].Index).Interface()
+			}
+		case isMap:
+			// 标题行使用第一个元素的key
+			if !hasKeys && val.IsValid() {
+				hasKeys = true
+				keys = val.MapKeys()
+				sort.Slice(keys, func(i, j int) bool {
+					return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+				})
+				head := make([]interface{}, len(keys))
+				for i := range keys {
+					head[i] = keys[i].Interface()
+				}
+				if err := fn(head); err != nil {
+					return err
+				}
+			}
+			row = make([]interface{}, len(keys))
+			for i := 0; i < len(keys) && val.IsValid(); i++ {
+				if v := val.MapIndex(keys[i]); v.IsValid() {
+					row[i] = v.Interface()
+				}
+			}
+		case val.Kind() == reflect.Slice || val.Kind() == reflect.Array:
+			row = make([]interface{}, val.Len())
+			for i := range row {
+				row[i] = val.Index(i).Interface()
+			}
+		case val.IsValid():
+			row = []interface{}{val.Interface()}
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getRenderTableFields 函数解析结构体可导出字段的列名称，列名称使用tag csv、json名称或字段名称。
+func getRenderTableFields(iType reflect.Type, index []int) []renderTableField {
+	var fields []renderTableField
+	for i := 0; i < iType.NumField(); i++ {
+		field := iType.Field(i)
+		name := strings.SplitN(field.Tag.Get("csv"), ",", 2)[0]
+		if name == "" {
+			name = strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		}
+		if name == "-" {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && name == "" {
+			fields = append(fields, getRenderTableFields(field.Type, fieldIndex)...)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, renderTableField{Name: name, Index: fieldIndex})
+	}
+	return fields
+}
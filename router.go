@@ -109,6 +109,46 @@ type RouterStd struct {
 	Middlewares     *middlewareTree      `alias:"middlewares"`
 	Print           func(...interface{}) `alias:"print"`
 	params          *Params              `alias:"params"`
+	routes          *[]routerRoute       `alias:"routes"`
+}
+
+// RouterRoute 定义路由编译后的路由信息，Handlers为合并中间件后的完整处理链。
+type RouterRoute struct {
+	Method       string       `json:"method"`
+	Path         string       `json:"path"`
+	Handlers     HandlerFuncs `json:"-"`
+	HandlerNames []string     `json:"handlers"`
+	Doc          *RouterDoc   `json:"doc,omitempty"`
+}
+
+// RouterDoc 定义路由文档信息，注册路由时和处理函数一起传入，不会创建处理函数，用于生成api文档页面。
+//
+// Request和Response为请求和响应示例，字符串直接显示，其他类型使用json格式化显示。
+type RouterDoc struct {
+	Summary     string      `json:"summary,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Tags        []string    `json:"tags,omitempty"`
+	Request     interface{} `json:"request,omitempty"`
+	Response    interface{} `json:"response,omitempty"`
+}
+
+// RouterAudit 定义路由审计发现的问题，Kind为RouterAudit*常量，Route为遮蔽当前路由的路由或重复的中间件名称。
+type RouterAudit struct {
+	Kind    string `json:"kind"`
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Route   string `json:"route,omitempty"`
+	Message string `json:"message"`
+}
+
+// routerRoute 定义RouterStd记录的注册路由，编译时使用注册路由器的中间件重新合并处理链。
+type routerRoute struct {
+	method      string
+	path        string
+	route       string
+	handlers    HandlerFuncs
+	middlewares *middlewareTree
+	doc         *RouterDoc
 }
 
 // HandlerRouter405 函数定义默认405处理
@@ -138,6 +178,7 @@ func NewRouterStd(core RouterCore) Router {
 		},
 		HandlerExtender: NewHandlerExtendWarp(NewHandlerExtendTree(), DefaultHandlerExtend),
 		Middlewares:     newMiddlewareTree(),
+		routes:          new([]routerRoute),
 		Print:           printEmpty,
 	}
 }
@@ -179,6 +220,7 @@ func (m *RouterStd) Group(path string) Router {
 		HandlerExtender: NewHandlerExtendWarp(NewHandlerExtendTree(), m.HandlerExtender),
 		Middlewares:     m.Middlewares.clone(),
 		Print:           m.Print,
+		routes:          m.routes,
 	}
 }
 
@@ -191,17 +233,17 @@ func (m *RouterStd) Params() *Params {
 
 // paramsCombine method parses a string path and merges it into a copy of the current routing parameters.
 //
-// For example, the path format is: /user action=user
+// For example, the path format is: /user action=user, the parameter of the route overrides the parameter of the same name of the group.
 //
 // paramsCombine 方法解析一个字符串路径，并合并到一个当前路由参数的副本中。
 //
-// 例如路径格式为：/user action=user
+// 例如路径格式为：/user action=user，路由参数会覆盖组路由的同名参数。
 func (m *RouterStd) paramsCombine(path string) *Params {
 	newparams := m.params.Clone()
 	params := NewParamsRoute(path)
 	newparams.Vals[0] = newparams.Vals[0] + params.Vals[0]
 	for i := range params.Keys[1:] {
-		newparams.Add(params.Keys[i+1], params.Vals[i+1])
+		newparams.Set(params.Keys[i+1], params.Vals[i+1])
 	}
 	return newparams
 }
@@ -260,11 +302,13 @@ func getRouteParam(path, key string) string {
 //
 // You can register 9 methods defined by http (three of the Router interfaces do not provide direct registration),
 // or you can register the method as: ANY TEST 404 405 NotFound MethodNotAllowed, register Any, TEST, 404, 405 routing rules.
+// Custom methods such as PURGE and MKCOL can be registered after being defined in the global variable RouterCustomMethods, other methods return an error.
 // the registration method is ANY to register all methods, the ANY method route will be covered by the same path non-ANY method,
 // and vice versa; the registration method is TEST will output the debug information related to the route registration,
 // but the registration behavior will not be performed;
 //
-// The handler parameter is processed using the HandlerExtender.NewHandlerFuncs() method of the current RouterStd to generate the corresponding HandlerFuncs.
+// The handler parameter is processed using the HandlerExtender.NewHandlerFuncs() method of the current RouterStd to generate the corresponding HandlerFuncs,
+// and the parameter of type RouterDoc is recorded as route documentation.
 //
 // If the current Router cannot be processed, call the HandlerExtender or defaultHandlerExtend of the upper-level group for processing,
 // and output the error log if all of them cannot be processed.
@@ -274,8 +318,9 @@ func getRouteParam(path, key string) string {
 // AddHandler 方法添加一条新路由, 允许添加多个请求方法使用','分开。
 //
 // 可以注册http定义的9种方法(其中三种Router接口未提供直接注册),也可以注册方法为：ANY TEST 404 405 NotFound MethodNotAllowed，注册Any、TEST、404、405路由规则。注册方法为ANY注册全部方法，ANY方法路由会被同路径非ANY方法覆盖，反之不行；注册方法为TEST会输出路由注册相关debug信息，但不执行注册行为;
+// 自定义方法例如PURGE、REPORT、MKCOL需要先定义在全局变量RouterCustomMethods中才能注册，其他方法返回错误。
 //
-// handler参数使用当前RouterStd的HandlerExtender.NewHandlerFuncs()方法处理，生成对应的HandlerFuncs。
+// handler参数使用当前RouterStd的HandlerExtender.NewHandlerFuncs()方法处理，生成对应的HandlerFuncs；类型为RouterDoc的参数记录为路由文档。
 //
 // 如果当前Router无法处理，则调用上一级group的HandlerExtender或defaultHandlerExtend处理，全部无法处理则输出error日志。
 //
@@ -304,6 +349,7 @@ func (m *RouterStd) registerHandlers(method, path string, hs ...interface{}) (er
 	}
 	method = strings.ToUpper(method)
 
+	hs, doc := getRouterDoc(hs)
 	handlers, err := m.newHandlerFuncs(path, hs)
 	if err != nil {
 		m.printError(1, err)
@@ -315,14 +361,25 @@ func (m *RouterStd) registerHandlers(method, path string, hs ...interface{}) (er
 		return
 	}
 	m.Print("Register handler:", method, fullpath, handlers)
+	route := routerRoute{route: path, path: fullpath, handlers: handlers, middlewares: m.Middlewares, doc: doc}
 	handlers = HandlerFuncsCombine(m.Middlewares.Lookup(path), handlers)
 
 	// 处理多方法
 	var errs muliterror
 	for _, i := range strings.Split(method, ",") {
 		i = strings.TrimSpace(i)
+		switch i {
+		case "NOTFOUND":
+			i = "404"
+		case "METHODNOTALLOWED":
+			i = "405"
+		}
 		if checkMethod(i) {
 			m.RouterCore.HandleFunc(i, fullpath, handlers)
+			if m.routes != nil {
+				route.method = i
+				*m.routes = append(*m.routes, route)
+			}
 		} else {
 			err := fmt.Errorf(ErrFormatRouterStdRegisterHandlersMethodInvalid, i, method, fullpath)
 			errs.HandleError(err)
@@ -332,6 +389,23 @@ func (m *RouterStd) registerHandlers(method, path string, hs ...interface{}) (er
 	return errs.GetError()
 }
 
+// getRouterDoc 函数从注册参数中取出RouterDoc，存在多个时使用最后一个。
+func getRouterDoc(hs []interface{}) ([]interface{}, *RouterDoc) {
+	var doc *RouterDoc
+	handlers := hs[:0:0]
+	for _, h := range hs {
+		switch val := h.(type) {
+		case RouterDoc:
+			doc = &val
+		case *RouterDoc:
+			doc = val
+		default:
+			handlers = append(handlers, h)
+		}
+	}
+	return handlers, doc
+}
+
 // The newHandlerFuncs method creates HandlerFuncs based on the path and multiple parameters.
 //
 // RouterStd first calls the current HandlerExtender.NewHandlerFuncs to create multiple function handlers. If it returns null, it will be created from the superior HandlerExtender.
@@ -353,6 +427,11 @@ func (m *RouterStd) newHandlerFuncs(path string, hs []interface{}) (HandlerFuncs
 			if ok {
 				fname = "Controller " + reflect.ValueOf(cf.Controller).Method(cf.Index).Type().String()
 			}
+			if str, ok := h.(string); ok {
+				_, err := NewHandlerName(str)
+				errs.HandleError(fmt.Errorf(ErrFormatRouterStdNewHandlerFuncsNameError, path, i, err))
+				continue
+			}
 			errs.HandleError(fmt.Errorf(ErrFormatRouterStdNewHandlerFuncsUnregisterType, path, i, fname))
 		}
 	}
@@ -364,6 +443,11 @@ func checkMethod(method string) bool {
 	case "ANY", "404", "405", "NotFound", "MethodNotAllowed", MethodOptions, MethodConnect, MethodTrace:
 		return true
 	}
+	return checkAllMethod(method) || checkCustomMethod(method)
+}
+
+// checkAllMethod 函数检查方法是否是RouterAllMethod定义的Any注册方法。
+func checkAllMethod(method string) bool {
 	for _, i := range RouterAllMethod {
 		if i == method {
 			return true
@@ -372,6 +456,16 @@ func checkMethod(method string) bool {
 	return false
 }
 
+// checkCustomMethod 函数检查方法是否是RouterCustomMethods定义的自定义方法。
+func checkCustomMethod(method string) bool {
+	for _, i := range RouterCustomMethods {
+		if i == method {
+			return true
+		}
+	}
+	return false
+}
+
 // AddController method uses the built-in controller parsing function to resolve the controller to obtain the routing configuration.
 //
 // If the controller implements the RoutesInjecter interface, call the controller to inject the route itself.
@@ -408,9 +502,14 @@ func getConrtrollerName(ctl Controller) string {
 //
 // If the number of parameters is greater than 1 and the first parameter is a string type, the first string type parameter is used as the path to add the middleware.
 //
+// If the next parameter is an int type, it is used as the priority, middleware with higher priority is executed first,
+// and middleware with the same priority is executed in the order of addition, the default priority is 0.
+//
 // AddMiddleware 给路由器添加多个中间件函数，会使用HandlerExtender转换参数。
 //
 // 如果参数数量大于1且第一个参数为字符串类型，会将第一个字符串类型参数作为添加中间件的路径。
+//
+// 如果下一个参数为int类型，会作为中间件优先级，优先级高的中间件先执行，相同优先级按添加顺序执行，默认优先级为0。
 func (m *RouterStd) AddMiddleware(hs ...interface{}) error {
 	if len(hs) == 0 {
 		return nil
@@ -424,6 +523,14 @@ func (m *RouterStd) AddMiddleware(hs ...interface{}) error {
 			hs = hs[1:]
 		}
 	}
+	var priority int
+	if len(hs) > 1 {
+		val, ok := hs[0].(int)
+		if ok {
+			priority = val
+			hs = hs[1:]
+		}
+	}
 
 	handlers, err := m.newHandlerFuncs(path, hs)
 	if err != nil {
@@ -431,12 +538,203 @@ func (m *RouterStd) AddMiddleware(hs ...interface{}) error {
 		return err
 	}
 
-	m.Middlewares.Insert(path, handlers)
+	m.Middlewares.Insert(path, priority, handlers)
 	m.RouterCore.HandleFunc("Middlewares", path, handlers)
-	m.Print("Register middleware:", path, handlers)
+	if priority != 0 {
+		m.Print("Register middleware:", path, "priority", priority, handlers)
+	} else {
+		m.Print("Register middleware:", path, handlers)
+	}
 	return nil
 }
 
+// GetMiddlewares method returns the middleware matched by the path in the order of execution, used to view the effective middleware chain of a route.
+//
+// GetMiddlewares 方法返回路径匹配的中间件，按执行顺序排序，用于查看路由实际生效的中间件链。
+func (m *RouterStd) GetMiddlewares(path string) HandlerFuncs {
+	return m.Middlewares.Lookup(m.paramsCombine(path).Get("route"))
+}
+
+// Compile method flattens the middleware of the registered router into the handler chain of each route and re-registers it in the order of registration,
+// so that the middleware added after the route is registered also takes effect, and returns the complete handler chain of all routes.
+//
+// The middleware of the parent router added after Group is not inherited by the group router; Compile should be called before the app starts serving,
+// and RouterCoreLock is required when compiling at runtime.
+//
+// Compile 方法将注册路由器的中间件展开合并到每个路由的处理链，并按注册顺序重新注册，
+// 使路由注册后添加的中间件也会生效，返回全部路由的完整处理链用于查看。
+//
+// Group后上级路由器添加的中间件不会被组路由器继承；需要在app开始处理请求前调用，运行时编译需要使用RouterCoreLock。
+func (m *RouterStd) Compile() []RouterRoute {
+	routes := m.Routes()
+	for _, route := range routes {
+		m.RouterCore.HandleFunc(route.Method, route.Path, route.Handlers)
+	}
+	if routes != nil {
+		m.Print("Compile router routes:", len(routes))
+	}
+	return routes
+}
+
+// Routes method returns all registered routes in the order of registration, including the complete handler chain and route documentation, without re-registering.
+//
+// Routes 方法按注册顺序返回全部注册的路由，包含合并中间件后的完整处理链和路由文档，不会重新注册路由。
+func (m *RouterStd) Routes() []RouterRoute {
+	if m.routes == nil {
+		return nil
+	}
+	routes := make([]RouterRoute, len(*m.routes))
+	for i, route := range *m.routes {
+		handlers := HandlerFuncsCombine(route.middlewares.Lookup(route.route), route.handlers)
+		names := make([]string, len(handlers))
+		for j := range handlers {
+			names[j] = fmt.Sprint(handlers[j])
+		}
+		routes[i] = RouterRoute{
+			Method:       route.method,
+			Path:         route.path,
+			Handlers:     handlers,
+			HandlerNames: names,
+			Doc:          route.doc,
+		}
+	}
+	return routes
+}
+
+// Audit method checks the registered routes and returns the problems found: middleware registered repeatedly in the handler chain,
+// routes registered repeatedly with the same method and path, static routes shadowed by wildcard routes of other methods, and routes that cannot be matched.
+//
+// Audit 方法检查注册的路由并返回发现的问题：处理链中重复注册的中间件、相同方法和路径重复注册的路由、
+// 被其他方法通配符路由遮蔽的常量路由和无法匹配的路由；常量路由使用RouterCore.Match匹配路由路径检查实际匹配的路由。
+func (m *RouterStd) Audit() []RouterAudit {
+	if m.routes == nil {
+		return nil
+	}
+	var audits []RouterAudit
+	registered := make(map[string]bool)
+	middlewares := make(map[string]bool)
+	for _, route := range *m.routes {
+		key := route.method + " " + route.path
+		if registered[key] {
+			audits = append(audits, RouterAudit{
+				Kind:    RouterAuditDuplicateRoute,
+				Method:  route.method,
+				Path:    route.path,
+				Message: "route is registered repeatedly, the last registered handlers are used",
+			})
+		}
+		registered[key] = true
+
+		// 相同中间件只输出第一个受影响的路由。
+		for _, audit := range auditDuplicateHandlers(route.middlewares.Lookup(route.route)) {
+			if !middlewares[audit.Route] {
+				middlewares[audit.Route] = true
+				audit.Method, audit.Path = route.method, route.path
+				audits = append(audits, audit)
+			}
+		}
+
+		if audit, ok := m.auditMatch(route); ok {
+			audits = append(audits, audit)
+		}
+	}
+	return audits
+}
+
+// auditDuplicateHandlers 函数按处理函数对象检查重复的处理函数，按第一次出现的顺序返回。
+//
+// 相同构造函数创建的不同中间件名称相同，例如不同配置的NewLoggerFunc，不视为重复。
+func auditDuplicateHandlers(hs HandlerFuncs) []RouterAudit {
+	counts := make(map[uintptr]int)
+	for _, h := range hs {
+		counts[getFuncPointer(reflect.ValueOf(h))]++
+	}
+	var audits []RouterAudit
+	for _, h := range hs {
+		ptr := getFuncPointer(reflect.ValueOf(h))
+		if counts[ptr] > 1 {
+			name := h.String()
+			audits = append(audits, RouterAudit{
+				Kind:    RouterAuditDuplicateMiddleware,
+				Route:   name,
+				Message: fmt.Sprintf("middleware %s is registered %d times in the handler chain", name, counts[ptr]),
+			})
+			counts[ptr] = 0
+		}
+	}
+	return audits
+}
+
+// auditMatch 方法使用路由生成的探测路径进行匹配，检查路由是否被遮蔽或无法匹配，Any方法路由依次检查RouterAllMethod。
+func (m *RouterStd) auditMatch(route routerRoute) (RouterAudit, bool) {
+	path, ok := getRouteAuditPath(route.route)
+	if route.method == "404" || route.method == "405" || !ok {
+		return RouterAudit{}, false
+	}
+	methods := []string{route.method}
+	if route.method == MethodAny {
+		methods = RouterAllMethod
+	}
+	var shadows []string
+	var shadow string
+	for _, method := range methods {
+		params := &Params{}
+		m.RouterCore.Match(method, path, params)
+		match := params.Get(ParamRoute)
+		switch match {
+		case route.route, "":
+			// 路由核心延迟匹配时不返回route参数，例如routerCoreHost。
+		case "404", "405":
+			if route.method != MethodAny {
+				return RouterAudit{
+					Kind:    RouterAuditUnreachableRoute,
+					Method:  route.method,
+					Path:    route.path,
+					Route:   match,
+					Message: "route path does not match the route itself",
+				}, true
+			}
+		default:
+			shadows = append(shadows, method)
+			shadow = match
+		}
+	}
+	if len(shadows) == 0 {
+		return RouterAudit{}, false
+	}
+	return RouterAudit{
+		Kind:    RouterAuditShadowedRoute,
+		Method:  route.method,
+		Path:    route.path,
+		Route:   shadow,
+		Message: fmt.Sprintf("route is shadowed by route %s in method %s", shadow, strings.Join(shadows, ",")),
+	}, true
+}
+
+// getRouteAuditPath 函数将路由路径的变量和通配符替换为探测值，生成一个应该匹配该路由的请求路径，
+// 通配符使用两段路径避免匹配同位置的变量，存在校验规则时无法生成。
+func getRouteAuditPath(path string) (string, bool) {
+	if strings.Contains(path, "|") {
+		return "", false
+	}
+	const probe = "eudore-audit"
+	var probepath []byte
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case ':':
+			for i+1 < len(path) && path[i+1] != '/' {
+				i++
+			}
+			probepath = append(probepath, probe...)
+		case '*':
+			return string(probepath) + probe + "/" + probe, true
+		default:
+			probepath = append(probepath, path[i])
+		}
+	}
+	return string(probepath), true
+}
+
 // AddHandlerExtend method adds an extension function to the current Router.
 //
 // If the number of parameters is greater than 1 and the first parameter is a string type, the first string type parameter is used as the path to add the extension function.
@@ -479,12 +777,12 @@ func (m *RouterStd) AddHandlerExtend(hs ...interface{}) error {
 //
 // The routing rules registered by the Any method will be overwritten by the specified method registration, and vice versa.
 // Any default registration method includes six types of Get Post Put Delete Head Patch,
-// which are defined in the global variable RouterAllMethod.
+// which are defined in the global variable RouterAllMethod; after enabling RouterAnyMethod, the Any route also matches other request methods.
 //
 // AnyFunc 方法实现注册一个Any方法的http请求处理函数。
 //
 // Any方法注册的路由规则会被指定方法注册覆盖，反之不行。
-// Any默认注册方法包含Get Post Put Delete Head Patch六种，定义在全局变量RouterAllMethod。
+// Any默认注册方法包含Get Post Put Delete Head Patch六种，定义在全局变量RouterAllMethod；开启RouterAnyMethod后Any路由也会匹配其他请求方法。
 func (m *RouterStd) AnyFunc(path string, h ...interface{}) {
 	m.registerHandlers(MethodAny, path, h...)
 }
@@ -521,16 +819,18 @@ func (m *RouterStd) PatchFunc(path string, h ...interface{}) {
 
 // middlewareTree 定义中间件信息存储树
 type middlewareTree struct {
-	index int
-	node  *middlewareNode
+	index     int
+	prioritys []int
+	node      *middlewareNode
 }
 
 func newMiddlewareTree() *middlewareTree {
-	return &middlewareTree{node: new(middlewareNode)}
+	return &middlewareTree{prioritys: []int{0}, node: new(middlewareNode)}
 }
 
-func (t *middlewareTree) Insert(path string, val HandlerFuncs) {
+func (t *middlewareTree) Insert(path string, priority int, val HandlerFuncs) {
 	t.index++
+	t.prioritys = append(t.prioritys, priority)
 	indexs := make([]int, len(val))
 	for i := range indexs {
 		indexs[i] = t.index
@@ -538,13 +838,15 @@ func (t *middlewareTree) Insert(path string, val HandlerFuncs) {
 	t.node.Insert(path, indexs, val)
 }
 
-// Lookup 方法查找路径对应的处理函数，并安装索引进行排序。
+// Lookup 方法查找路径对应的处理函数，并按照优先级和索引进行排序。
 func (t *middlewareTree) Lookup(path string) HandlerFuncs {
 	indexs, vals := t.node.Lookup(path)
+	indexs = append([]int(nil), indexs...)
+	vals = append(HandlerFuncs(nil), vals...)
 	length := len(vals)
 	for i := 0; i < length; i++ {
 		for j := i; j < length; j++ {
-			if indexs[i] > indexs[j] {
+			if t.less(indexs[j], indexs[i]) {
 				indexs[i], indexs[j] = indexs[j], indexs[i]
 				vals[i], vals[j] = vals[j], vals[i]
 			}
@@ -553,10 +855,19 @@ func (t *middlewareTree) Lookup(path string) HandlerFuncs {
 	return vals
 }
 
+// less 方法比较两个中间件索引的执行顺序，优先级高的先执行，相同优先级先添加的先执行。
+func (t *middlewareTree) less(i, j int) bool {
+	if t.prioritys[i] != t.prioritys[j] {
+		return t.prioritys[i] > t.prioritys[j]
+	}
+	return i < j
+}
+
 func (t *middlewareTree) clone() *middlewareTree {
 	return &middlewareTree{
-		index: t.index,
-		node:  t.node.clone(),
+		index:     t.index,
+		prioritys: append([]int(nil), t.prioritys...),
+		node:      t.node.clone(),
 	}
 }
 
@@ -626,6 +937,44 @@ func indexsCombine(hs1, hs2 []int) []int {
 	return hs
 }
 
+// routerFallback 定义组路由注册的404和405处理，按路径前缀匹配，未匹配的请求使用上级组路由或全局处理。
+type routerFallback struct {
+	prefixs  []string
+	params   []*Params
+	handlers []HandlerFuncs
+}
+
+// insert 方法添加一个路径前缀的处理，前缀按长度倒序保存使最长前缀优先匹配。
+func (f *routerFallback) insert(path, route string, hs HandlerFuncs) {
+	params := NewParamsRoute(path)
+	prefix := params.Get(ParamRoute)
+	params.Set(ParamRoute, route)
+	for i := range f.prefixs {
+		if f.prefixs[i] == prefix {
+			f.params[i], f.handlers[i] = params, hs
+			return
+		}
+	}
+	i := len(f.prefixs)
+	for i > 0 && len(f.prefixs[i-1]) < len(prefix) {
+		i--
+	}
+	f.prefixs = append(f.prefixs[:i], append([]string{prefix}, f.prefixs[i:]...)...)
+	f.params = append(f.params[:i], append([]*Params{params}, f.params[i:]...)...)
+	f.handlers = append(f.handlers[:i], append([]HandlerFuncs{hs}, f.handlers[i:]...)...)
+}
+
+// lookup 方法返回匹配路径的最长前缀处理，前缀需要按路径段匹配。
+func (f *routerFallback) lookup(path string) (*Params, HandlerFuncs) {
+	for i, prefix := range f.prefixs {
+		if strings.HasPrefix(path, prefix) && (len(path) == len(prefix) ||
+			prefix[len(prefix)-1] == '/' || path[len(prefix)] == '/') {
+			return f.params[i], f.handlers[i]
+		}
+	}
+	return nil, nil
+}
+
 // routerCoreLock 允许对RouterCore读写进行加锁，用于运行时动态增删路由规则。
 type routerCoreLock struct {
 	sync.RWMutex
@@ -27,6 +27,12 @@ const (
 type routerCoreFull struct {
 	node404 fullNode
 	node405 fullNode
+	// 组路由注册的异常处理方法
+	fallback404 routerFallback
+	fallback405 routerFallback
+	// 自定义方法路由树和Any方法注册的任意方法路由树
+	methods   map[string]*fullNode
+	anymethod fullNode
 	get     fullNode
 	post    fullNode
 	put     fullNode
@@ -82,13 +88,22 @@ func NewRouterCoreFull() RouterCore {
 func (r *routerCoreFull) HandleFunc(method string, path string, handler HandlerFuncs) {
 	switch method {
 	case "NotFound", "404":
+		if getRoutePath(path) != "" {
+			r.fallback404.insert(path, "404", handler)
+			return
+		}
 		r.node404.handlers = handler
 	case "MethodNotAllowed", "405":
+		if getRoutePath(path) != "" {
+			r.fallback405.insert(path, "405", handler)
+			return
+		}
 		r.node405.Wchildren.handlers = handler
 	case MethodAny:
 		for _, method := range RouterAllMethod {
 			r.insertRoute(method, path, true, handler)
 		}
+		r.insertRoute(MethodAny, path, true, handler)
 	default:
 		r.insertRoute(method, path, false, handler)
 	}
@@ -100,11 +115,31 @@ func (r *routerCoreFull) HandleFunc(method string, path string, handler HandlerF
 //
 // 匹配一个请求，如果方法不不允许直接返回node405，未匹配返回node404。
 func (r *routerCoreFull) Match(method, path string, params *Params) HandlerFuncs {
-	if n := r.getTree(method).lookNode(path, params); n != nil {
-		return n
+	tree := r.getTree(method)
+	if tree != &r.node405 {
+		if n := tree.lookNode(path, params); n != nil {
+			return n
+		}
+	}
+	// 开启RouterAnyMethod后，RouterAllMethod以外的方法使用Any方法注册的路由匹配。
+	if RouterAnyMethod && !checkAllMethod(method) {
+		if n := r.anymethod.lookNode(path, params); n != nil {
+			return n
+		}
+	}
+	if tree == &r.node405 {
+		if fparams, hs := r.fallback405.lookup(path); hs != nil {
+			params.Combine(fparams)
+			return hs
+		}
+		return tree.lookNode(path, params)
 	}
 
-	// 处理404
+	// 处理404，优先使用组路由注册的404处理。
+	if fparams, hs := r.fallback404.lookup(path); hs != nil {
+		params.Combine(fparams)
+		return hs
+	}
 	params.Combine(r.node404.params)
 	return r.node404.handlers
 }
@@ -137,6 +172,9 @@ func (r *routerCoreFull) getTree(method string) *fullNode {
 	case MethodTrace:
 		return &r.trace
 	default:
+		if tree, ok := r.methods[method]; ok {
+			return tree
+		}
 		return &r.node405
 	}
 }
@@ -150,8 +188,19 @@ func (r *routerCoreFull) getTree(method string) *fullNode {
 // 如果方法不支持则不会添加，请求改路径会响应405
 func (r *routerCoreFull) insertRoute(method, key string, isany bool, val HandlerFuncs) {
 	var currentNode = r.getTree(method)
-	if currentNode == &r.node405 {
-		return
+	switch {
+	case method == MethodAny:
+		currentNode = &r.anymethod
+	case currentNode == &r.node405:
+		// 注册RouterCustomMethods定义的自定义方法时创建方法路由树。
+		if !checkCustomMethod(method) {
+			return
+		}
+		if r.methods == nil {
+			r.methods = make(map[string]*fullNode)
+		}
+		currentNode = new(fullNode)
+		r.methods[method] = currentNode
 	}
 
 	params := NewParamsRoute(key)
@@ -25,6 +25,12 @@ type routerCoreRadix struct {
 	// 异常处理方法
 	node404 radixNode
 	node405 radixNode
+	// 组路由注册的异常处理方法
+	fallback404 routerFallback
+	fallback405 routerFallback
+	// 自定义方法路由树和Any方法注册的任意方法路由树
+	methods   map[string]*radixNode
+	anymethod radixNode
 	// various methods routing tree
 	// 各种方法路由树
 	get     radixNode
@@ -79,13 +85,22 @@ func NewRouterCoreRadix() RouterCore {
 func (r *routerCoreRadix) HandleFunc(method string, path string, handler HandlerFuncs) {
 	switch method {
 	case "NotFound", "404":
+		if getRoutePath(path) != "" {
+			r.fallback404.insert(path, "404", handler)
+			return
+		}
 		r.node404.handlers = handler
 	case "MethodNotAllowed", "405":
+		if getRoutePath(path) != "" {
+			r.fallback405.insert(path, "405", handler)
+			return
+		}
 		r.node405.Wchildren.handlers = handler
 	case MethodAny:
 		for _, method := range RouterAllMethod {
 			r.insertRoute(method, path, true, handler)
 		}
+		r.insertRoute(MethodAny, path, true, handler)
 	default:
 		r.insertRoute(method, path, false, handler)
 	}
@@ -97,11 +112,31 @@ func (r *routerCoreRadix) HandleFunc(method string, path string, handler Handler
 //
 // 匹配一个请求，如果方法不不允许直接返回node405，未匹配返回node404。
 func (r *routerCoreRadix) Match(method, path string, params *Params) HandlerFuncs {
-	if n := r.getTree(method).lookNode(path, params); n != nil {
-		return n
+	tree := r.getTree(method)
+	if tree != &r.node405 {
+		if n := tree.lookNode(path, params); n != nil {
+			return n
+		}
+	}
+	// 开启RouterAnyMethod后，RouterAllMethod以外的方法使用Any方法注册的路由匹配。
+	if RouterAnyMethod && !checkAllMethod(method) {
+		if n := r.anymethod.lookNode(path, params); n != nil {
+			return n
+		}
+	}
+	if tree == &r.node405 {
+		if fparams, hs := r.fallback405.lookup(path); hs != nil {
+			params.Combine(fparams)
+			return hs
+		}
+		return tree.lookNode(path, params)
 	}
 
-	// 处理404
+	// 处理404，优先使用组路由注册的404处理。
+	if fparams, hs := r.fallback404.lookup(path); hs != nil {
+		params.Combine(fparams)
+		return hs
+	}
 	params.Combine(r.node404.params)
 	return r.node404.handlers
 }
@@ -134,6 +169,9 @@ func (r *routerCoreRadix) getTree(method string) *radixNode {
 	case MethodTrace:
 		return &r.trace
 	default:
+		if tree, ok := r.methods[method]; ok {
+			return tree
+		}
 		return &r.node405
 	}
 }
@@ -155,8 +193,19 @@ func (r *routerCoreRadix) getTree(method string) *radixNode {
 // insertRoute 路径切割见getSpiltPath函数，当前未完善，处理正则可能异常。
 func (r *routerCoreRadix) insertRoute(method, key string, isany bool, val HandlerFuncs) {
 	var currentNode = r.getTree(method)
-	if currentNode == &r.node405 {
-		return
+	switch {
+	case method == MethodAny:
+		currentNode = &r.anymethod
+	case currentNode == &r.node405:
+		// 注册RouterCustomMethods定义的自定义方法时创建方法路由树。
+		if !checkCustomMethod(method) {
+			return
+		}
+		if r.methods == nil {
+			r.methods = make(map[string]*radixNode)
+		}
+		currentNode = new(radixNode)
+		r.methods[method] = currentNode
 	}
 
 	params := NewParamsRoute(key)
@@ -0,0 +1,358 @@
+package eudore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scheduler defines a background job scheduler, which runs jobs by cron expression or fixed interval until the context is done.
+//
+// Scheduler 定义后台任务调度器，使用cron表达式或固定间隔执行任务，直到context结束。
+type Scheduler struct {
+	sync.Mutex
+	Context context.Context
+	Logger  Logout
+	jobs    []*SchedulerJob
+	wg      sync.WaitGroup
+	closed  bool
+}
+
+// SchedulerOverlap 定义任务上一次执行未结束时的处理策略。
+type SchedulerOverlap int
+
+// 定义任务重叠执行策略。
+const (
+	// SchedulerOverlapSkip 跳过本次执行，默认策略。
+	SchedulerOverlapSkip SchedulerOverlap = iota
+	// SchedulerOverlapAllow 允许并发执行。
+	SchedulerOverlapAllow
+	// SchedulerOverlapQueue 等待上一次执行结束后执行。
+	SchedulerOverlapQueue
+)
+
+// SchedulerJob 定义一个调度任务和任务状态。
+type SchedulerJob struct {
+	Name         string           `json:"name"`
+	Spec         string           `json:"spec"`
+	Overlap      SchedulerOverlap `json:"overlap"`
+	Running      int              `json:"running"`
+	Runs         int64            `json:"runs"`
+	Failures     int64            `json:"failures"`
+	Skips        int64            `json:"skips"`
+	LastRun      time.Time        `json:"lastrun"`
+	LastDuration time.Duration    `json:"lastduration"`
+	LastError    string           `json:"lasterror,omitempty"`
+	NextRun      time.Time        `json:"nextrun"`
+	mu           sync.Mutex
+	queue        sync.Mutex
+	next         func(time.Time) time.Time
+	fn           func(context.Context) error
+	log          Logout
+}
+
+// NewScheduler function creates a scheduler, the job is stopped when ctx is done.
+//
+// NewScheduler 函数创建一个调度器，ctx结束时停止调度任务。
+func NewScheduler(ctx context.Context, log Logout) *Scheduler {
+	return &Scheduler{Context: ctx, Logger: log}
+}
+
+// Cron method adds a job using a 5-field cron expression (minute hour day month week) or @hourly @daily @weekly @monthly @yearly.
+//
+// Cron 方法使用5段cron表达式(分 时 日 月 周)或@hourly @daily @weekly @monthly @yearly添加一个任务。
+//
+// options类型为string时设置任务名称，类型为SchedulerOverlap时设置重叠执行策略。
+func (s *Scheduler) Cron(spec string, fn func(context.Context) error, options ...interface{}) error {
+	schedule, err := parseCronSchedule(spec)
+	if err != nil {
+		return err
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		return fmt.Errorf("scheduler cron spec '%s' never matches", spec)
+	}
+	s.addJob(spec, schedule.Next, fn, options)
+	return nil
+}
+
+// Every method adds a job that runs every interval.
+//
+// Every 方法添加一个固定间隔执行的任务。
+func (s *Scheduler) Every(interval time.Duration, fn func(context.Context) error, options ...interface{}) error {
+	if interval <= 0 {
+		return fmt.Errorf("scheduler every interval must be positive: %s", interval)
+	}
+	s.addJob("@every "+interval.String(), func(t time.Time) time.Time {
+		return t.Add(interval)
+	}, fn, options)
+	return nil
+}
+
+func (s *Scheduler) addJob(spec string, next func(time.Time) time.Time, fn func(context.Context) error, options []interface{}) {
+	job := &SchedulerJob{
+		Name: spec,
+		Spec: spec,
+		next: next,
+		fn:   fn,
+	}
+	for _, i := range options {
+		switch val := i.(type) {
+		case string:
+			job.Name = val
+		case SchedulerOverlap:
+			job.Overlap = val
+		}
+	}
+	job.log = s.Logger.WithField("job", job.Name).WithFields(nil)
+	job.NextRun = next(time.Now())
+
+	s.Lock()
+	s.jobs = append(s.jobs, job)
+	s.Unlock()
+	go s.schedule(job)
+}
+
+// schedule 方法循环等待任务下一次执行时间。
+func (s *Scheduler) schedule(job *SchedulerJob) {
+	for {
+		job.mu.Lock()
+		next := job.NextRun
+		job.mu.Unlock()
+		if next.IsZero() {
+			job.log.Error("scheduler job has no next run time")
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.Context.Done():
+			timer.Stop()
+			return
+		case now := <-timer.C:
+			job.mu.Lock()
+			job.NextRun = job.next(now)
+			job.mu.Unlock()
+			s.trigger(job)
+		}
+	}
+}
+
+// trigger 方法根据重叠策略执行一次任务，Wait后不再执行任务。
+func (s *Scheduler) trigger(job *SchedulerJob) {
+	// 在锁内检查closed并Add，避免与Wait并发。
+	s.Lock()
+	defer s.Unlock()
+	if s.closed || s.Context.Err() != nil {
+		return
+	}
+	job.mu.Lock()
+	if job.Overlap == SchedulerOverlapSkip && job.Running > 0 {
+		job.Skips++
+		job.mu.Unlock()
+		job.log.Warning("scheduler skip job, because the last run is not finished")
+		return
+	}
+	job.Running++
+	job.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if job.Overlap == SchedulerOverlapQueue {
+			job.queue.Lock()
+			defer job.queue.Unlock()
+		}
+		s.run(job)
+	}()
+}
+
+// run 方法执行任务，捕捉panic并记录执行结果。
+func (s *Scheduler) run(job *SchedulerJob) {
+	start := time.Now()
+	var err error
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = fmt.Errorf("scheduler job panic: %v", rerr)
+		}
+		job.mu.Lock()
+		job.Running--
+		job.Runs++
+		job.LastRun = start
+		job.LastDuration = time.Since(start)
+		job.LastError = ""
+		if err != nil {
+			job.Failures++
+			job.LastError = err.Error()
+		}
+		job.mu.Unlock()
+		if err != nil {
+			job.log.WithField("duration", time.Since(start).String()).Error(err)
+		} else {
+			job.log.WithField("duration", time.Since(start).String()).Debug("scheduler job finished")
+		}
+	}()
+	err = job.fn(s.Context)
+}
+
+// Jobs method returns a snapshot of all job status.
+//
+// Jobs 方法返回全部任务状态的快照。
+func (s *Scheduler) Jobs() []*SchedulerJob {
+	s.Lock()
+	defer s.Unlock()
+	jobs := make([]*SchedulerJob, len(s.jobs))
+	for i, job := range s.jobs {
+		job.mu.Lock()
+		jobs[i] = &SchedulerJob{
+			Name:         job.Name,
+			Spec:         job.Spec,
+			Overlap:      job.Overlap,
+			Running:      job.Running,
+			Runs:         job.Runs,
+			Failures:     job.Failures,
+			Skips:        job.Skips,
+			LastRun:      job.LastRun,
+			LastDuration: job.LastDuration,
+			LastError:    job.LastError,
+			NextRun:      job.NextRun,
+		}
+		job.mu.Unlock()
+	}
+	return jobs
+}
+
+// Wait method stops triggering jobs and waits for all running jobs to finish.
+//
+// Wait 方法停止触发任务并等待全部正在执行的任务结束。
+func (s *Scheduler) Wait() {
+	s.Lock()
+	s.closed = true
+	s.Unlock()
+	s.wg.Wait()
+}
+
+// HandleHTTP method renders all job status, used for admin view.
+//
+// HandleHTTP 方法渲染全部任务状态，用于管理后台查看。
+func (s *Scheduler) HandleHTTP(ctx Context) {
+	ctx.Render(s.Jobs())
+}
+
+// cronSchedule 定义解析后的cron表达式，每个字段使用位图保存允许的值。
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCronSchedule 函数解析5段cron表达式。
+func parseCronSchedule(spec string) (*cronSchedule, error) {
+	if val, ok := cronDescriptors[spec]; ok {
+		spec = val
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("scheduler cron spec '%s' must have 5 fields", spec)
+	}
+	var err error
+	s := &cronSchedule{
+		domStar: fields[2] == "*" || fields[2] == "?",
+		dowStar: fields[4] == "*" || fields[4] == "?",
+	}
+	bounds := [5][2]uint{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	dsts := [5]*uint64{&s.minute, &s.hour, &s.dom, &s.month, &s.dow}
+	for i, field := range fields {
+		*dsts[i], err = parseCronField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("scheduler cron spec '%s' error: %v", spec, err)
+		}
+	}
+	// 星期7等同星期0
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parseCronField 函数解析一个cron字段，支持* ? a a-b */n a-b/n和逗号分隔的列表。
+func parseCronField(field string, min, max uint) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := uint(1)
+		if pos := strings.IndexByte(part, '/'); pos != -1 {
+			n, err := strconv.ParseUint(part[pos+1:], 10, 8)
+			if err != nil || n == 0 {
+				return 0, fmt.Errorf("invalid step '%s'", part)
+			}
+			step = uint(n)
+			part = part[:pos]
+		}
+		start, end := min, max
+		if part != "*" && part != "?" {
+			bounds := strings.SplitN(part, "-", 2)
+			n, err := strconv.ParseUint(bounds[0], 10, 8)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value '%s'", part)
+			}
+			start, end = uint(n), uint(n)
+			if len(bounds) == 2 {
+				n, err = strconv.ParseUint(bounds[1], 10, 8)
+				if err != nil {
+					return 0, fmt.Errorf("invalid range '%s'", part)
+				}
+				end = uint(n)
+			} else if step > 1 {
+				end = max
+			}
+		}
+		if start < min || end > max || start > end {
+			return 0, fmt.Errorf("value '%s' out of range [%d, %d]", field, min, max)
+		}
+		for i := start; i <= end; i += step {
+			bits |= 1 << i
+		}
+	}
+	return bits, nil
+}
+
+// Next 方法返回t之后的下一次执行时间，5年内没有匹配时间返回零值。
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + 5
+	for t.Year() <= limit {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// matchDay 方法匹配日和星期，如果日和星期都有限制满足任意一个即可。
+func (s *cronSchedule) matchDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
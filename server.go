@@ -15,6 +15,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/fcgi"
+	"reflect"
 	"strconv"
 	"sync"
 	"time"
@@ -52,6 +53,10 @@ type ServerStdConfig struct {
 	// It does not limit the size of the request body. If zero, DefaultMaxHeaderBytes is used.
 	MaxHeaderBytes int `alias:"maxheaderbytes" json:"maxheaderbytes"`
 
+	// DisableKeepAlives disables HTTP keep-alives, each connection only handles one request.
+	// The zero value does not change the server setting.
+	DisableKeepAlives bool `alias:"disablekeepalives" json:"disablekeepalives"`
+
 	// BaseContext optionally specifies a function that returns the base context for incoming requests on this server.
 	// The provided Listener is the specific Listener that's about to start accepting requests.
 	// If BaseContext is nil, the default is context.Background(). If non-nil, it must return a non-nil context.
@@ -60,12 +65,18 @@ type ServerStdConfig struct {
 	// ConnContext optionally specifies a function that modifies the context used for a new connection c.
 	// The provided ctx is derived from the base context and has a ServerContextKey value.
 	ConnContext func(context.Context, net.Conn) context.Context `alias:"conncontext" json:"-"` // Go 1.13
+
+	// ConnState specifies an optional callback function that is called when a client connection changes state,
+	// ServerConnState.ConnState records connection state counters.
+	ConnState func(net.Conn, http.ConnState) `alias:"connstate" json:"-"`
 }
 
 // serverStd 定义使用net/http启动http server。
 type serverStd struct {
 	*http.Server
 	Print func(...interface{}) `alias:"print"`
+	sync.Mutex
+	servers []*http.Server
 }
 
 // netHTTPLog 实现一个函数处理log.Logger的内容，用于捕捉net/http.Server输出的error内容。
@@ -91,6 +102,24 @@ type ServerListenConfig struct {
 	Keyfile     string                                     `alias:"keyfile" json:"keyfile" description:"Http server key file."`
 	Trustfile   string                                     `alias:"trustfile" json:"trustfile" description:"Http client ca file."`
 	Certificate *x509.Certificate                          `alias:"certificate" json:"certificate" description:"https use tls certificate."`
+	// KeepAlive 设置tcp连接keep-alive周期，负数关闭tcp keep-alive，0使用net.Listen默认值。
+	KeepAlive      TimeDuration `alias:"keepalive" json:"keepalive" description:"Tcp keep-alive period, negative disables."`
+	DisableNoDelay bool         `alias:"disablenodelay" json:"disablenodelay" description:"Disable tcp nodelay."`
+	// Server 设置当前监听使用的server配置，覆盖serverStd的非零配置。
+	Server *ServerStdConfig `alias:"server" json:"server" description:"Listener server config."`
+}
+
+// listenerTCP 定义设置tcp连接参数的监听。
+type listenerTCP struct {
+	net.Listener
+	keepalive time.Duration
+	nodelay   bool
+}
+
+// listenerServer 定义携带server配置的监听，serverStd使用配置创建独立的http.Server。
+type listenerServer struct {
+	net.Listener
+	config *ServerStdConfig
 }
 
 // NewServerStd 创建一个标准server。
@@ -104,9 +133,58 @@ func NewServerStd(arg interface{}) Server {
 		},
 	}
 	ConvertTo(arg, srv.Server)
+	srv.setKeepAlives(arg)
 	return srv
 }
 
+// Serve 方法启动监听，如果监听设置了server配置，使用独立的http.Server处理监听。
+func (srv *serverStd) Serve(ln net.Listener) error {
+	sl, ok := ln.(*listenerServer)
+	if !ok {
+		return srv.Server.Serve(ln)
+	}
+
+	// 浅复制http.Server的导出属性，再使用监听配置覆盖非零值。
+	server := &http.Server{}
+	src, dst := reflect.ValueOf(srv.Server).Elem(), reflect.ValueOf(server).Elem()
+	for i := 0; i < dst.NumField(); i++ {
+		if dst.Field(i).CanSet() {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+	ConvertTo(sl.config, server)
+	server.SetKeepAlivesEnabled(!sl.config.DisableKeepAlives)
+	srv.Lock()
+	srv.servers = append(srv.servers, server)
+	srv.Unlock()
+	return server.Serve(ln)
+}
+
+// Shutdown 方法关闭server和全部监听独立使用的http.Server。
+func (srv *serverStd) Shutdown(ctx context.Context) error {
+	srv.Lock()
+	servers := srv.servers
+	srv.Unlock()
+	var errs muliterror
+	errs.HandleError(srv.Server.Shutdown(ctx))
+	for _, server := range servers {
+		errs.HandleError(server.Shutdown(ctx))
+	}
+	return errs.GetError()
+}
+
+// setKeepAlives 方法在配置DisableKeepAlives时关闭http keep-alive。
+func (srv *serverStd) setKeepAlives(arg interface{}) {
+	switch val := arg.(type) {
+	case ServerStdConfig:
+		srv.setKeepAlives(&val)
+	case *ServerStdConfig:
+		if val != nil && val.DisableKeepAlives {
+			srv.Server.SetKeepAlivesEnabled(false)
+		}
+	}
+}
+
 // SetHandler 方法设置server的http处理者。
 func (srv *serverStd) SetHandler(h http.Handler) {
 	srv.Server.Handler = h
@@ -120,10 +198,12 @@ func (srv *serverStd) Set(key string, value interface{}) error {
 		srv.Server.ErrorLog = newNetHTTPLogger(srv.Print)
 	case ServerStdConfig, *ServerStdConfig:
 		ConvertTo(value, srv.Server)
+		srv.setKeepAlives(value)
 	default:
 		cf := new(ServerStdConfig)
 		Set(cf, key, value)
 		ConvertTo(cf, srv.Server)
+		srv.setKeepAlives(cf)
 	}
 	return nil
 }
@@ -185,7 +265,11 @@ func (slc *ServerListenConfig) Listen() (net.Listener, error) {
 		}
 	}
 	if !slc.HTTPS {
-		return slc.NewListen("tcp", slc.Addr)
+		ln, err := slc.NewListen("tcp", slc.Addr)
+		if err != nil {
+			return nil, err
+		}
+		return slc.wrapListener(ln, nil), nil
 	}
 
 	// set tls
@@ -219,7 +303,44 @@ func (slc *ServerListenConfig) Listen() (net.Listener, error) {
 	if err != nil {
 		return nil, err
 	}
-	return tls.NewListener(ln, config), nil
+	return slc.wrapListener(ln, config), nil
+}
+
+// wrapListener 方法按照配置封装监听，依次设置tcp连接参数、tls和server配置。
+func (slc *ServerListenConfig) wrapListener(ln net.Listener, config *tls.Config) net.Listener {
+	if slc.KeepAlive != 0 || slc.DisableNoDelay {
+		ln = &listenerTCP{
+			Listener:  ln,
+			keepalive: time.Duration(slc.KeepAlive),
+			nodelay:   !slc.DisableNoDelay,
+		}
+	}
+	if config != nil {
+		ln = tls.NewListener(ln, config)
+	}
+	if slc.Server != nil {
+		ln = &listenerServer{Listener: ln, config: slc.Server}
+	}
+	return ln
+}
+
+// Accept 方法接收一个连接并设置tcp keep-alive和nodelay。
+func (ln *listenerTCP) Accept() (net.Conn, error) {
+	conn, err := ln.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	tc, ok := conn.(*net.TCPConn)
+	if ok {
+		if ln.keepalive < 0 {
+			tc.SetKeepAlive(false)
+		} else if ln.keepalive > 0 {
+			tc.SetKeepAlive(true)
+			tc.SetKeepAlivePeriod(ln.keepalive)
+		}
+		tc.SetNoDelay(ln.nodelay)
+	}
+	return conn, nil
 }
 
 // loadCertificate 实现加载证书，如果证书配置文件为空，则自动创建一个私有证书。
@@ -0,0 +1,110 @@
+package eudore
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// ServerConnState defines the connection state counters of the http server, which are updated by the http.Server.ConnState hook.
+//
+// New, Active, Idle, Hijacked and Closed are the cumulative number of transitions to each state,
+// the current number of connections in each state is returned by the Current method, used to diagnose connection leaks and keep-alive behavior.
+//
+// ServerConnState 定义http server连接状态计数，使用http.Server.ConnState钩子更新。
+//
+// New、Active、Idle、Hijacked、Closed为进入对应状态的累计次数，Current方法返回当前处于各状态的连接数量，
+// 用于诊断连接泄漏和keep-alive行为。
+type ServerConnState struct {
+	New      int64 `json:"new"`
+	Active   int64 `json:"active"`
+	Idle     int64 `json:"idle"`
+	Hijacked int64 `json:"hijacked"`
+	Closed   int64 `json:"closed"`
+	sync.RWMutex
+	conns map[net.Conn]http.ConnState
+	hooks []func(net.Conn, http.ConnState)
+}
+
+// NewServerConnState function creates connection state counters, and sets the ConnState method to ServerStdConfig.ConnState.
+//
+// NewServerConnState 函数创建连接状态计数，将ConnState方法设置到ServerStdConfig.ConnState。
+func NewServerConnState(hooks ...func(net.Conn, http.ConnState)) *ServerConnState {
+	return &ServerConnState{
+		conns: make(map[net.Conn]http.ConnState),
+		hooks: hooks,
+	}
+}
+
+// AddHook method adds a connection state hook, which is called after the counters are updated.
+//
+// AddHook 方法添加一个连接状态钩子，在更新计数后调用。
+func (s *ServerConnState) AddHook(fn func(net.Conn, http.ConnState)) {
+	s.Lock()
+	s.hooks = append(s.hooks, fn)
+	s.Unlock()
+}
+
+// ConnState method implements the http.Server.ConnState hook.
+//
+// ConnState 方法实现http.Server.ConnState钩子。
+func (s *ServerConnState) ConnState(conn net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		atomic.AddInt64(&s.New, 1)
+	case http.StateActive:
+		atomic.AddInt64(&s.Active, 1)
+	case http.StateIdle:
+		atomic.AddInt64(&s.Idle, 1)
+	case http.StateHijacked:
+		atomic.AddInt64(&s.Hijacked, 1)
+	case http.StateClosed:
+		atomic.AddInt64(&s.Closed, 1)
+	}
+
+	s.Lock()
+	if state == http.StateHijacked || state == http.StateClosed {
+		delete(s.conns, conn)
+	} else {
+		s.conns[conn] = state
+	}
+	hooks := s.hooks
+	s.Unlock()
+	for _, fn := range hooks {
+		fn(conn, state)
+	}
+}
+
+// Current method returns the number of connections currently in the new, active and idle states.
+//
+// Current 方法返回当前处于new、active、idle状态的连接数量。
+func (s *ServerConnState) Current() map[string]int {
+	current := map[string]int{
+		http.StateNew.String():    0,
+		http.StateActive.String(): 0,
+		http.StateIdle.String():   0,
+	}
+	s.RLock()
+	for _, state := range s.conns {
+		current[state.String()]++
+	}
+	s.RUnlock()
+	return current
+}
+
+// HandleHTTP method implements the admin endpoint and returns the cumulative and current connection state counters.
+//
+// HandleHTTP 方法实现管理接口，返回连接状态累计和当前计数。
+func (s *ServerConnState) HandleHTTP(ctx Context) {
+	ctx.Render(map[string]interface{}{
+		"total": map[string]int64{
+			"new":      atomic.LoadInt64(&s.New),
+			"active":   atomic.LoadInt64(&s.Active),
+			"idle":     atomic.LoadInt64(&s.Idle),
+			"hijacked": atomic.LoadInt64(&s.Hijacked),
+			"closed":   atomic.LoadInt64(&s.Closed),
+		},
+		"current": s.Current(),
+	})
+}
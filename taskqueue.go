@@ -0,0 +1,247 @@
+package eudore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TaskQueue defines an in-process task queue, uses the worker pool to execute tasks, retries failed tasks with exponential backoff,
+// and the failed task will be handed over to DeadLetter after the maximum number of retries.
+//
+// TaskQueue 定义进程内任务队列，使用工作池执行任务，失败任务使用指数退避重试，超过最大重试次数的任务交给DeadLetter处理。
+//
+// Backend默认使用内存存储，可以替换为Redis等持久化存储，Backend实现TaskDelayBackend接口时重试任务由Backend延迟投递；
+// Jitter大于0时在重试延迟上增加[0, delay*Jitter]的随机时间，避免大量任务同时重试。
+type TaskQueue struct {
+	sync.Mutex
+	Context    context.Context
+	Logger     Logout
+	Backend    TaskBackend
+	Workers    int
+	MaxRetry   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Jitter     float64
+	DeadLetter func(*Task)
+	handlers   map[string]TaskHandler
+	running    bool
+	wg         sync.WaitGroup
+}
+
+// Task 定义一个任务，Func非空时直接执行Func，否则执行Name对应的TaskHandler。
+type Task struct {
+	ID       string      `json:"id"`
+	Name     string      `json:"name"`
+	Payload  interface{} `json:"payload"`
+	Attempts int         `json:"attempts"`
+	Error    string      `json:"error,omitempty"`
+	Func     TaskHandler `json:"-"`
+}
+
+// TaskHandler 定义任务处理函数。
+type TaskHandler func(context.Context, *Task) error
+
+// TaskBackend 定义任务存储，Pop方法阻塞直到获得任务或ctx结束。
+type TaskBackend interface {
+	Push(*Task) error
+	Pop(context.Context) (*Task, error)
+}
+
+// TaskDelayBackend 定义支持延迟投递的任务存储。
+type TaskDelayBackend interface {
+	PushDelay(*Task, time.Duration) error
+}
+
+// 定义任务队列错误。
+var (
+	ErrTaskHandlerNotFound = errors.New("task handler not found")
+	ErrTaskQueueFull       = errors.New("task queue is full")
+)
+
+var taskid uint64
+
+// NewTaskQueue function creates a task queue, the workers are stopped when ctx is done.
+//
+// NewTaskQueue 函数创建一个任务队列，ctx结束时停止工作协程。
+func NewTaskQueue(ctx context.Context, log Logout) *TaskQueue {
+	return &TaskQueue{
+		Context:    ctx,
+		Logger:     log,
+		Backend:    NewTaskBackendMemory(),
+		Workers:    4,
+		MaxRetry:   3,
+		Backoff:    time.Second,
+		MaxBackoff: time.Minute,
+		handlers:   make(map[string]TaskHandler),
+	}
+}
+
+// AddHandler method registers a task handler by name.
+//
+// AddHandler 方法注册一个名称对应的任务处理函数。
+func (q *TaskQueue) AddHandler(name string, fn TaskHandler) {
+	q.Lock()
+	q.handlers[name] = fn
+	q.Unlock()
+}
+
+// Enqueue method pushes a task into Backend and starts the workers for the first time.
+//
+// Enqueue 方法将任务放入Backend，第一次调用时启动工作协程。
+func (q *TaskQueue) Enqueue(task *Task) error {
+	if task.ID == "" {
+		task.ID = fmt.Sprintf("%x-%x", time.Now().UnixNano(), atomic.AddUint64(&taskid, 1))
+	}
+	q.Start()
+	return q.Backend.Push(task)
+}
+
+// Start method starts the worker pool, repeated calls are ignored.
+//
+// Start 方法启动工作池，重复调用会被忽略。
+func (q *TaskQueue) Start() {
+	q.Lock()
+	defer q.Unlock()
+	if q.running {
+		return
+	}
+	q.running = true
+	// 启动前设置工作协程数量，避免Add与Wait并发。
+	q.wg.Add(q.Workers)
+	for i := 0; i < q.Workers; i++ {
+		go q.work()
+	}
+}
+
+// Wait method waits for the workers to exit after Context is done, including the running tasks.
+//
+// Wait 方法等待Context结束后全部工作协程退出，包含正在执行的任务。
+func (q *TaskQueue) Wait() {
+	q.wg.Wait()
+}
+
+// work 方法循环从Backend获取任务执行，Context结束后获取的任务放回Backend不再执行。
+func (q *TaskQueue) work() {
+	defer q.wg.Done()
+	for {
+		task, err := q.Backend.Pop(q.Context)
+		if err != nil {
+			if q.Context.Err() != nil {
+				return
+			}
+			q.Logger.Error("task queue pop error:", err)
+			select {
+			case <-q.Context.Done():
+				return
+			case <-time.After(q.Backoff):
+			}
+			continue
+		}
+		if q.Context.Err() != nil {
+			if err := q.Backend.Push(task); err != nil {
+				q.Logger.WithField("taskid", task.ID).Error("task queue push back error:", err)
+			}
+			return
+		}
+		q.run(task)
+	}
+}
+
+// run 方法执行任务，捕捉panic，失败时重试或交给DeadLetter处理。
+func (q *TaskQueue) run(task *Task) {
+	log := q.Logger.WithFields(Fields{"task": task.Name, "taskid": task.ID}).WithFields(nil)
+	start := time.Now()
+	err := q.call(task)
+	task.Attempts++
+	if err == nil {
+		log.WithField("duration", time.Since(start).String()).Debug("task finished")
+		return
+	}
+
+	task.Error = err.Error()
+	// 任务处理函数不存在时重试没有意义，直接交给DeadLetter处理。
+	if task.Attempts > q.MaxRetry || err == ErrTaskHandlerNotFound {
+		log.WithField("attempts", task.Attempts).Error("task dead letter:", err)
+		if q.DeadLetter != nil {
+			q.DeadLetter(task)
+		}
+		return
+	}
+
+	delay := q.Backoff << uint(task.Attempts-1)
+	if delay > q.MaxBackoff || delay <= 0 {
+		delay = q.MaxBackoff
+	}
+	if q.Jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(float64(delay)*q.Jitter) + 1))
+	}
+	log.WithFields(Fields{"attempts": task.Attempts, "delay": delay.String()}).Warning("task retry:", err)
+	if backend, ok := q.Backend.(TaskDelayBackend); ok {
+		err = backend.PushDelay(task, delay)
+		if err != nil {
+			log.Error("task retry push error:", err)
+		}
+		return
+	}
+	time.AfterFunc(delay, func() {
+		if q.Context.Err() != nil {
+			return
+		}
+		if err := q.Backend.Push(task); err != nil {
+			log.Error("task retry push error:", err)
+		}
+	})
+}
+
+func (q *TaskQueue) call(task *Task) (err error) {
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = fmt.Errorf("task panic: %v", rerr)
+		}
+	}()
+	fn := task.Func
+	if fn == nil {
+		q.Lock()
+		fn = q.handlers[task.Name]
+		q.Unlock()
+	}
+	if fn == nil {
+		return ErrTaskHandlerNotFound
+	}
+	return fn(q.Context, task)
+}
+
+// taskBackendMemory 定义基于channel的内存任务存储。
+type taskBackendMemory struct {
+	tasks chan *Task
+}
+
+// NewTaskBackendMemory 函数创建一个内存任务存储，最多缓存1024个任务，队列满时Push返回ErrTaskQueueFull。
+func NewTaskBackendMemory() TaskBackend {
+	return &taskBackendMemory{tasks: make(chan *Task, 1024)}
+}
+
+// Push 方法放入一个任务。
+func (b *taskBackendMemory) Push(task *Task) error {
+	select {
+	case b.tasks <- task:
+		return nil
+	default:
+		return ErrTaskQueueFull
+	}
+}
+
+// Pop 方法获取一个任务。
+func (b *taskBackendMemory) Pop(ctx context.Context) (*Task, error) {
+	select {
+	case task := <-b.tasks:
+		return task, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // split2byte internal function, splits two strings into two segments using the first specified byte, and returns "", str if there is no split symbol.
@@ -374,6 +375,22 @@ func GetStringFloat64(str string, nums ...float64) float64 {
 	return 0
 }
 
+// GetStringDuration 使用time.ParseDuration解析数据，纯数字按秒解析，如果解析返回错误使用第一个非零值。
+func GetStringDuration(str string, nums ...time.Duration) time.Duration {
+	if v, err := time.ParseDuration(str); err == nil {
+		return v
+	}
+	if v, err := strconv.ParseInt(str, 10, 64); err == nil {
+		return time.Duration(v) * time.Second
+	}
+	for _, i := range nums {
+		if i != 0 {
+			return i
+		}
+	}
+	return 0
+}
+
 // GetWarp 对象封装Get函数提供类型转换功能。
 type GetWarp func(string) interface{}
 
@@ -32,6 +32,8 @@ type validaterBase struct {
 type validateBaseFields []validateBaseField
 type validateBaseField struct {
 	Index   int
+	Name    string
+	Rule    string
 	Value   reflect.Value
 	IsImple bool
 	Format  string
@@ -133,23 +135,34 @@ func (v *validaterBase) Validate(i interface{}) error {
 		// 调用Validater接口
 		if i.IsImple {
 			if field.IsNil() {
-				return fmt.Errorf(i.Format, "field is nil")
+				return i.newError(field, "field is nil")
 			}
 			err := field.Interface().(validateInterface).Validate()
 			if err != nil {
-				return fmt.Errorf(i.Format, err)
+				return i.newError(field, err)
 			}
 			continue
 		}
 		// 反射调用Validater检测函数
 		out := i.Value.Call([]reflect.Value{field})
 		if !out[0].Bool() {
-			return fmt.Errorf(i.Format, field.Interface())
+			return i.newError(field, field.Interface())
 		}
 	}
 	return nil
 }
 
+// newError 方法创建字段校验错误，使用FieldErrors与Bind类型转换错误结构相同。
+func (i validateBaseField) newError(field reflect.Value, arg interface{}) error {
+	return FieldErrors{{
+		Field:   i.Name,
+		Type:    field.Type().String(),
+		Value:   fmt.Sprint(field.Interface()),
+		Rule:    i.Rule,
+		Message: fmt.Sprintf(i.Format, arg),
+	}}
+}
+
 func (v *validaterBase) ParseValidateFields(iType reflect.Type) (validateBaseFields, error) {
 	data, ok := v.Load(iType)
 	if ok {
@@ -163,6 +176,7 @@ func (v *validaterBase) ParseValidateFields(iType reflect.Type) (validateBaseFie
 		if field.Type.Implements(typeValidateInterface) && tags == "" {
 			vfs = append(vfs, validateBaseField{
 				Index:   i,
+				Name:    field.Name,
 				IsImple: true,
 				Format:  fmt.Sprintf("validate %s.%s field '%s' type is '%s', check Validate method error: %%v", iType.PkgPath(), iType.Name(), field.Name, field.Type),
 			})
@@ -179,6 +193,8 @@ func (v *validaterBase) ParseValidateFields(iType reflect.Type) (validateBaseFie
 			}
 			vfs = append(vfs, validateBaseField{
 				Index:  i,
+				Name:   field.Name,
+				Rule:   tag,
 				Value:  fn,
 				Format: fmt.Sprintf("validate %s.%s field %s check %%#v rule %s fatal", iType.PkgPath(), iType.Name(), field.Name, tag),
 			})